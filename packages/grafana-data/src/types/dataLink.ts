@@ -50,6 +50,10 @@ export interface DataLink<T extends DataQuery = any> {
   // @internal and subject to change in future releases
   internal?: InternalDataLink<T>;
 
+  // Transformations extracting the variables used in the URL of external links, internal links have their own.
+  // @internal and subject to change in future releases
+  transformations?: DataLinkTransformationConfig[];
+
   origin?: DataLinkConfigOrigin;
 }
 
@@ -62,14 +66,22 @@ export interface DataLink<T extends DataQuery = any> {
 export enum SupportedTransformationType {
   Regex = 'regex',
   Logfmt = 'logfmt',
+  JSONPath = 'jsonpath',
+  KeyValue = 'keyvalue',
 }
 
 /** @internal */
 export interface DataLinkTransformationConfig {
   type: SupportedTransformationType;
   field?: string;
+  /** Variable extracted by a previous transformation, used as the input instead of a field */
+  input?: string;
   expression?: string;
   mapValue?: string;
+  /** Delimiter of the pairs of keyvalue transformations, a space by default */
+  delimiter?: string;
+  /** Separator of the keys and values of keyvalue transformations, `=` by default */
+  separator?: string;
 }
 
 /** @internal */
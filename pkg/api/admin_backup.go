@@ -0,0 +1,71 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+)
+
+// swagger:route POST /admin/backup admin adminCreateBackup
+//
+// Create a backup.
+//
+// Creates a point-in-time archive of the database contents, the data key metadata,
+// the provisioned file references and the installed plugins.
+// The archive can be restored with `grafana cli admin backup restore`.
+//
+// Produces:
+// - application/tar+gzip
+//
+// Responses:
+// 200: adminCreateBackupResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) AdminCreateBackup(c *contextmodel.ReqContext) {
+	// The archive is written to a temporary file first, so that errors can
+	// still be reported before anything has been sent to the client.
+	f, err := os.CreateTemp("", "grafana-backup-*.tar.gz")
+	if err != nil {
+		response.Error(http.StatusInternalServerError, "Failed to create backup", err).WriteTo(c)
+		return
+	}
+	defer func() {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+	}()
+
+	manifest, err := hs.backupService.Backup(c.Req.Context(), f)
+	if err != nil {
+		response.ErrOrFallback(http.StatusInternalServerError, "Failed to create backup", err).WriteTo(c)
+		return
+	}
+
+	info, err := f.Stat()
+	if err == nil {
+		_, err = f.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		response.Error(http.StatusInternalServerError, "Failed to create backup", err).WriteTo(c)
+		return
+	}
+
+	filename := fmt.Sprintf("grafana-backup-%s.tar.gz", manifest.CreatedAt.Format("20060102T150405Z"))
+	c.Resp.Header().Set("Content-Type", "application/tar+gzip")
+	c.Resp.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
+	c.Resp.Header().Set("Content-Length", fmt.Sprintf("%d", info.Size()))
+	c.Resp.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(c.Resp, f); err != nil {
+		hs.log.Error("Failed to send backup archive", "error", err)
+	}
+}
+
+// swagger:response adminCreateBackupResponse
+type AdminCreateBackupResponse struct {
+	// in: body
+	Body []byte `json:"body"`
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/api/response"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/provisioning/gitsync"
 )
 
 // swagger:route POST /admin/provisioning/dashboards/reload admin_provisioning adminProvisioningReloadDashboards
@@ -85,3 +86,27 @@ func (hs *HTTPServer) AdminProvisioningReloadAlerting(c *contextmodel.ReqContext
 	}
 	return response.Success("Alerting config reloaded")
 }
+
+// swagger:route GET /admin/provisioning/dashboards/git/status admin_provisioning adminProvisioningGetGitSyncStatus
+//
+// Get the sync status of git provisioned dashboards.
+//
+// Returns the branch and commit of every dashboard provider of type `git`, when it was last synced, the last sync error and the changes made in the UI that could not be committed because the file was changed in the repository.
+// If you are running Grafana Enterprise and have Fine-grained access control enabled, you need to have a permission with action `provisioning:reload` and scope `provisioners:dashboards`.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: getGitSyncStatusResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+func (hs *HTTPServer) AdminProvisioningGetGitSyncStatus(c *contextmodel.ReqContext) response.Response {
+	return response.JSON(http.StatusOK, hs.ProvisioningService.GetGitSyncStatus())
+}
+
+// swagger:response getGitSyncStatusResponse
+type GetGitSyncStatusResponse struct {
+	// in: body
+	Body []gitsync.Status `json:"body"`
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	testdatasource "github.com/grafana/grafana/pkg/tsdb/grafana-testdata-datasource"
+	"github.com/grafana/grafana/pkg/tsdb/legacydata"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// swagger:route POST /admin/recordings admin adminCaptureRecordings
+//
+// Capture the response of a query as recordings.
+//
+// Runs the queries of the request, like `/api/ds/query`, and returns the recording of the response of each query.
+// The recordings can be replayed with the Replay scenario of the TestData data source, which shifts the recorded
+// timestamps so that they line up with the time range of the panel.
+//
+// Responses:
+// 200: adminCaptureRecordingsResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) AdminCaptureRecordings(c *contextmodel.ReqContext) response.Response {
+	reqDTO := dtos.MetricRequest{}
+	if err := web.Bind(c.Req, &reqDTO); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	resp, err := hs.queryDataService.QueryData(c.Req.Context(), c.SignedInUser, true, reqDTO)
+	if err != nil {
+		return hs.handleQueryMetricsError(err)
+	}
+
+	refIDs := make([]string, 0, len(resp.Responses))
+	for refID := range resp.Responses {
+		refIDs = append(refIDs, refID)
+	}
+	sort.Strings(refIDs)
+
+	to := legacydata.NewDataTimeRange(reqDTO.From, reqDTO.To).GetToAsTimeUTC()
+	recordings := make(map[string]testdatasource.Recording, len(refIDs))
+	for _, refID := range refIDs {
+		res := resp.Responses[refID]
+		if res.Error != nil {
+			return response.Error(http.StatusBadRequest, fmt.Sprintf("Query %s failed: %s", refID, res.Error), res.Error)
+		}
+		recordings[refID] = testdatasource.NewRecording(to, res.Frames)
+	}
+
+	return response.JSON(http.StatusOK, AdminCaptureRecordingsResult{Recordings: recordings})
+}
+
+// AdminCaptureRecordingsResult is the recording of the response of each query, by ref ID.
+type AdminCaptureRecordingsResult struct {
+	Recordings map[string]testdatasource.Recording `json:"recordings"`
+}
+
+// swagger:parameters adminCaptureRecordings
+type AdminCaptureRecordingsParams struct {
+	// in:body
+	// required:true
+	Body dtos.MetricRequest `json:"body"`
+}
+
+// swagger:response adminCaptureRecordingsResponse
+type AdminCaptureRecordingsResponse struct {
+	// in: body
+	Body AdminCaptureRecordingsResult `json:"body"`
+}
@@ -0,0 +1,95 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	"github.com/grafana/grafana/pkg/services/user"
+	testdatasource "github.com/grafana/grafana/pkg/tsdb/grafana-testdata-datasource"
+	"github.com/grafana/grafana/pkg/web/webtest"
+)
+
+type fakeRecordingsQueryService struct {
+	resp *backend.QueryDataResponse
+}
+
+func (s *fakeRecordingsQueryService) Run(context.Context) error {
+	return nil
+}
+
+func (s *fakeRecordingsQueryService) QueryData(context.Context, identity.Requester, bool, dtos.MetricRequest) (*backend.QueryDataResponse, error) {
+	return s.resp, nil
+}
+
+func TestAdminCaptureRecordings(t *testing.T) {
+	to := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	body := `{"from": "1704106800000", "to": "1704110400000", "queries": [{"refId": "A", "datasource": {"uid": "prom"}}]}`
+
+	newServer := func(resp *backend.QueryDataResponse) *webtest.Server {
+		return SetupAPITestServer(t, func(hs *HTTPServer) {
+			hs.queryDataService = &fakeRecordingsQueryService{resp: resp}
+		})
+	}
+
+	t.Run("returns the recording of each query", func(t *testing.T) {
+		server := newServer(&backend.QueryDataResponse{Responses: backend.Responses{
+			"A": backend.DataResponse{Frames: data.Frames{
+				data.NewFrame("up", data.NewField("time", nil, []time.Time{to}), data.NewField("value", nil, []float64{1})),
+			}},
+		}})
+
+		req := server.NewPostRequest("/api/admin/recordings", strings.NewReader(body))
+		webtest.RequestWithSignedInUser(req, &user.SignedInUser{UserID: 1, OrgID: 1, IsGrafanaAdmin: true})
+		res, err := server.SendJSON(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { require.NoError(t, res.Body.Close()) })
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		var result struct {
+			Recordings map[string]json.RawMessage `json:"recordings"`
+		}
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&result))
+		require.Len(t, result.Recordings, 1)
+
+		recording, err := testdatasource.ParseRecording(string(result.Recordings["A"]))
+		require.NoError(t, err)
+		require.Equal(t, to.UnixMilli(), recording.RecordedAt)
+		require.Len(t, recording.Frames, 1)
+		require.Equal(t, "up", recording.Frames[0].Name)
+	})
+
+	t.Run("fails when a query fails", func(t *testing.T) {
+		server := newServer(&backend.QueryDataResponse{Responses: backend.Responses{
+			"A": backend.DataResponse{Error: errors.New("query failed")},
+		}})
+
+		req := server.NewPostRequest("/api/admin/recordings", strings.NewReader(body))
+		webtest.RequestWithSignedInUser(req, &user.SignedInUser{UserID: 1, OrgID: 1, IsGrafanaAdmin: true})
+		res, err := server.SendJSON(req)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		require.Equal(t, http.StatusBadRequest, res.StatusCode)
+	})
+
+	t.Run("requires a server admin", func(t *testing.T) {
+		server := newServer(&backend.QueryDataResponse{})
+
+		req := server.NewPostRequest("/api/admin/recordings", strings.NewReader(body))
+		webtest.RequestWithSignedInUser(req, &user.SignedInUser{UserID: 1, OrgID: 1})
+		res, err := server.SendJSON(req)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		require.Equal(t, http.StatusForbidden, res.StatusCode)
+	})
+}
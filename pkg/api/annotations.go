@@ -1,8 +1,12 @@
 package api
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
@@ -17,16 +21,31 @@ import (
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/folder"
 	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/services/tag"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/util"
 	"github.com/grafana/grafana/pkg/web"
 )
 
+const (
+	// defaultAnnotationsLimit is the number of annotations returned when the limit is not set
+	defaultAnnotationsLimit = 100
+	// nextCursorHeader is the header of the cursor of the next page of annotations
+	nextCursorHeader = "X-Next-Cursor"
+	// maxImportedAnnotations is the maximum number of annotations of an import
+	maxImportedAnnotations = 10000
+	// maxImportLineSize is the maximum size of an annotation of an import
+	maxImportLineSize = 1024 * 1024
+	// maxIdempotencyKeyLength is the length of the idempotency key column
+	maxIdempotencyKeyLength = 190
+)
+
 // swagger:route GET /annotations annotations getAnnotations
 //
 // Find Annotations.
 //
 // Starting in Grafana v6.4 regions annotations are now returned in one entity that now includes the timeEnd property.
+// When more annotations than the limit match the query, the `X-Next-Cursor` response header is the cursor of the next page.
 //
 // Responses:
 // 200: getAnnotationsResponse
@@ -46,9 +65,22 @@ func (hs *HTTPServer) GetAnnotations(c *contextmodel.ReqContext) response.Respon
 		Tags:         c.QueryStrings("tags"),
 		Type:         c.Query("type"),
 		MatchAny:     c.QueryBool("matchAny"),
+		Text:         c.Query("text"),
 		SignedInUser: c.SignedInUser,
 	}
 
+	if cursor := c.Query("cursor"); cursor != "" {
+		var err error
+		if query.Cursor, err = annotations.ParseCursor(cursor); err != nil {
+			return response.Err(err)
+		}
+	}
+
+	limit := query.Limit
+	if limit == 0 {
+		limit = defaultAnnotationsLimit
+	}
+
 	// When dashboard UID present in the request, we ignore dashboard ID
 	if query.DashboardUID != "" {
 		dq := dashboards.GetDashboardQuery{UID: query.DashboardUID, OrgID: c.SignedInUser.GetOrgID()}
@@ -86,7 +118,12 @@ func (hs *HTTPServer) GetAnnotations(c *contextmodel.ReqContext) response.Respon
 		}
 	}
 
-	return response.JSON(http.StatusOK, items)
+	resp := response.JSON(http.StatusOK, items)
+	// a full page may be followed by other annotations, which come after its last annotation
+	if len(items) > 0 && int64(len(items)) >= limit {
+		resp.SetHeader(nextCursorHeader, annotations.NewCursor(items[len(items)-1]).String())
+	}
+	return resp
 }
 
 type AnnotationError struct {
@@ -251,6 +288,137 @@ func (hs *HTTPServer) PostGraphiteAnnotation(c *contextmodel.ReqContext) respons
 	})
 }
 
+// swagger:route POST /annotations/import annotations importAnnotations
+//
+// Import Annotations.
+//
+// Creates the annotations of a newline delimited JSON body, with one annotation per line in the format of the Create Annotation operation.
+// An annotation with an `idempotencyKey` is only created once, so that a failed import can be retried.
+// Lines that are not valid are returned in the errors of the response, and the other annotations are created.
+//
+// Responses:
+// 200: importAnnotationsResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) ImportAnnotations(c *contextmodel.ReqContext) response.Response {
+	userID, err := identity.UserIdentifier(c.SignedInUser.GetNamespacedID())
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to import annotations", err)
+	}
+
+	items := make([]annotations.Item, 0)
+	importErrors := make([]dtos.ImportAnnotationsError, 0)
+	// the dashboards of the annotations of an import are usually the same
+	dashboardIDs := make(map[string]int64)
+	canCreate := make(map[int64]bool)
+
+	scanner := bufio.NewScanner(c.Req.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxImportLineSize)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		if len(items)+len(importErrors) >= maxImportedAnnotations {
+			return response.Error(http.StatusBadRequest, fmt.Sprintf("Failed to import annotations: an import has at most %d annotations", maxImportedAnnotations), nil)
+		}
+
+		item, err := hs.importedAnnotation(c, userID, scanner.Bytes(), dashboardIDs, canCreate)
+		if err != nil {
+			importErrors = append(importErrors, dtos.ImportAnnotationsError{Line: line, Message: err.Error()})
+			continue
+		}
+		items = append(items, item)
+	}
+	if err := scanner.Err(); err != nil {
+		return response.Error(http.StatusBadRequest, "Failed to read annotations", err)
+	}
+
+	imported, err := hs.annotationsRepo.Import(c.Req.Context(), items)
+	if err != nil {
+		if errors.Is(err, annotations.ErrTimerangeMissing) {
+			return response.Error(http.StatusBadRequest, "Failed to import annotations", err)
+		}
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to import annotations", err)
+	}
+
+	return response.JSON(http.StatusOK, util.DynMap{
+		"message":  "Annotations imported",
+		"imported": imported,
+		"skipped":  int64(len(items)) - imported,
+		"errors":   importErrors,
+	})
+}
+
+// importedAnnotation returns the annotation of a line of an import, when it is valid and the user can create it
+func (hs *HTTPServer) importedAnnotation(c *contextmodel.ReqContext, userID int64, line []byte, dashboardIDs map[string]int64, canCreate map[int64]bool) (annotations.Item, error) {
+	cmd := dtos.ImportAnnotationsCmd{}
+	if err := json.Unmarshal(line, &cmd); err != nil {
+		return annotations.Item{}, fmt.Errorf("invalid annotation: %w", err)
+	}
+
+	if cmd.Text == "" {
+		return annotations.Item{}, &AnnotationError{"text field should not be empty"}
+	}
+
+	if len(cmd.IdempotencyKey) > maxIdempotencyKeyLength {
+		return annotations.Item{}, &AnnotationError{fmt.Sprintf("idempotencyKey field should not be longer than %d characters", maxIdempotencyKeyLength)}
+	}
+
+	// the tags are validated here, as tags that are too long would fail the whole import when it is saved
+	tags := tag.JoinTagPairs(tag.ParseTagPairs(cmd.Tags))
+	if tagsLength := annotations.TagsLength(tags); tagsLength > int(hs.Cfg.AnnotationMaximumTagsLength) {
+		return annotations.Item{}, &AnnotationError{fmt.Sprintf("tags length (%d) exceeds the maximum allowed (%d)", tagsLength, hs.Cfg.AnnotationMaximumTagsLength)}
+	}
+
+	// overwrite dashboardId when dashboardUID is not empty
+	if cmd.DashboardUID != "" {
+		dashboardID, ok := dashboardIDs[cmd.DashboardUID]
+		if !ok {
+			query := dashboards.GetDashboardQuery{OrgID: c.SignedInUser.GetOrgID(), UID: cmd.DashboardUID}
+			queryResult, err := hs.DashboardService.GetDashboard(c.Req.Context(), &query)
+			if err != nil {
+				return annotations.Item{}, fmt.Errorf("dashboard %s not found", cmd.DashboardUID)
+			}
+			dashboardID = queryResult.ID
+			dashboardIDs[cmd.DashboardUID] = dashboardID
+		}
+		cmd.DashboardId = dashboardID
+	}
+
+	canSave, ok := canCreate[cmd.DashboardId]
+	if !ok {
+		var err error
+		if canSave, err = hs.canCreateAnnotation(c, cmd.DashboardId); err != nil {
+			return annotations.Item{}, fmt.Errorf("failed to check annotation permissions: %w", err)
+		}
+		canCreate[cmd.DashboardId] = canSave
+	}
+	if !canSave {
+		return annotations.Item{}, &AnnotationError{"access denied to save the annotation"}
+	}
+
+	item := annotations.Item{
+		OrgID:       c.SignedInUser.GetOrgID(),
+		UserID:      userID,
+		DashboardID: cmd.DashboardId,
+		PanelID:     cmd.PanelId,
+		Epoch:       cmd.Time,
+		EpochEnd:    cmd.TimeEnd,
+		Text:        cmd.Text,
+		Data:        cmd.Data,
+		Tags:        tags,
+	}
+	if cmd.IdempotencyKey != "" {
+		item.IdempotencyKey = &cmd.IdempotencyKey
+	}
+
+	return item, nil
+}
+
 // swagger:route PUT /annotations/{annotation_id} annotations updateAnnotation
 //
 // Update Annotation.
@@ -795,6 +963,14 @@ type GetAnnotationsParams struct {
 	// in:query
 	// required:false
 	MatchAny bool `json:"matchAny"`
+	// Find annotations whose text contains this text.
+	// in:query
+	// required:false
+	Text string `json:"text"`
+	// Find the annotations of the next page, returned in the `X-Next-Cursor` header of the previous page.
+	// in:query
+	// required:false
+	Cursor string `json:"cursor"`
 }
 
 // swagger:parameters getAnnotationTags
@@ -824,6 +1000,14 @@ type PostAnnotationParams struct {
 	Body dtos.PostAnnotationsCmd `json:"body"`
 }
 
+// swagger:parameters importAnnotations
+type ImportAnnotationsParams struct {
+	// Newline delimited JSON, with one annotation per line.
+	// in:body
+	// required:true
+	Body []dtos.ImportAnnotationsCmd `json:"body"`
+}
+
 // swagger:parameters postGraphiteAnnotation
 type PostGraphiteAnnotationParams struct {
 	// in:body
@@ -881,6 +1065,29 @@ type PostAnnotationResponse struct {
 	} `json:"body"`
 }
 
+// swagger:response importAnnotationsResponse
+type ImportAnnotationsResponse struct {
+	// The response message
+	// in: body
+	Body struct {
+		// Imported Number of created annotations.
+		// required: true
+		Imported int64 `json:"imported"`
+
+		// Skipped Number of annotations that were already imported with the same idempotency key.
+		// required: true
+		Skipped int64 `json:"skipped"`
+
+		// Errors Errors of the lines that are not valid.
+		// required: true
+		Errors []dtos.ImportAnnotationsError `json:"errors"`
+
+		// Message Message of the import.
+		// required: true
+		Message string `json:"message"`
+	} `json:"body"`
+}
+
 // swagger:response getAnnotationTagsResponse
 type GetAnnotationTagsResponse struct {
 	// The response message
@@ -2,6 +2,7 @@ package api
 
 import (
 	"context"
+	"encoding/json"
 	"io"
 	"net/http"
 	"strings"
@@ -11,6 +12,7 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
 	"github.com/grafana/grafana/pkg/services/annotations"
@@ -419,6 +421,84 @@ func TestAPI_Annotations(t *testing.T) {
 	}
 }
 
+func TestAPI_AnnotationsPagination(t *testing.T) {
+	server := SetupAPITestServer(t, func(hs *HTTPServer) {
+		hs.Cfg = setting.NewCfg()
+		hs.annotationsRepo = annotationstest.NewFakeAnnotationsRepo()
+		hs.Features = featuremgmt.WithFeatures()
+	})
+	permissions := []accesscontrol.Permission{{Action: accesscontrol.ActionAnnotationsRead, Scope: accesscontrol.ScopeAnnotationsAll}}
+
+	t.Run("should return the cursor of the next page when the page is full", func(t *testing.T) {
+		req := webtest.RequestWithSignedInUser(server.NewGetRequest("/api/annotations?limit=1"), authedUserWithPermissions(1, 1, permissions))
+		res, err := server.Send(req)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, annotations.NewCursor(&annotations.ItemDTO{ID: 1}).String(), res.Header.Get(nextCursorHeader))
+		require.NoError(t, res.Body.Close())
+	})
+
+	t.Run("should not return a cursor after the last page", func(t *testing.T) {
+		req := webtest.RequestWithSignedInUser(server.NewGetRequest("/api/annotations?limit=10"), authedUserWithPermissions(1, 1, permissions))
+		res, err := server.Send(req)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Empty(t, res.Header.Get(nextCursorHeader))
+		require.NoError(t, res.Body.Close())
+	})
+
+	t.Run("should fail with an invalid cursor", func(t *testing.T) {
+		req := webtest.RequestWithSignedInUser(server.NewGetRequest("/api/annotations?cursor=invalid!"), authedUserWithPermissions(1, 1, permissions))
+		res, err := server.Send(req)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+	})
+}
+
+func TestAPI_ImportAnnotations(t *testing.T) {
+	repo := annotationstest.NewFakeAnnotationsRepo()
+	server := SetupAPITestServer(t, func(hs *HTTPServer) {
+		hs.Cfg = setting.NewCfg()
+		hs.Cfg.AnnotationMaximumTagsLength = 500
+		hs.annotationsRepo = repo
+		hs.Features = featuremgmt.WithFeatures(featuremgmt.FlagAnnotationPermissionUpdate)
+		hs.AccessControl = acimpl.ProvideAccessControl(hs.Cfg)
+	})
+	permissions := []accesscontrol.Permission{{Action: accesscontrol.ActionAnnotationsCreate, Scope: accesscontrol.ScopeAnnotationsTypeOrganization}}
+
+	body := strings.Join([]string{
+		`{"text": "deploy 1", "time": 1000, "tags": ["deploy"], "idempotencyKey": "deploy-1"}`,
+		`{"text": "", "time": 2000}`,
+		``,
+		`{"text": "dashboard annotation", "time": 3000, "dashboardId": 1}`,
+		`{"text": "deploy 1", "time": 1000, "idempotencyKey": "deploy-1"}`,
+		`not json`,
+		`{"text": "deploy 2", "time": 4000, "timeEnd": 5000}`,
+		`{"text": "long tags", "time": 6000, "tags": ["` + strings.Repeat("a", 500) + `"]}`,
+	}, "\n")
+
+	req := webtest.RequestWithSignedInUser(server.NewPostRequest("/api/annotations/import", strings.NewReader(body)), authedUserWithPermissions(1, 1, permissions))
+	res, err := server.Send(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	var result struct {
+		Imported int64                         `json:"imported"`
+		Skipped  int64                         `json:"skipped"`
+		Errors   []dtos.ImportAnnotationsError `json:"errors"`
+	}
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&result))
+	require.NoError(t, res.Body.Close())
+
+	assert.Equal(t, int64(2), result.Imported)
+	assert.Equal(t, int64(1), result.Skipped)
+	require.Len(t, result.Errors, 4)
+	assert.Equal(t, []int{2, 4, 6, 8}, []int{result.Errors[0].Line, result.Errors[1].Line, result.Errors[2].Line, result.Errors[3].Line})
+	assert.Equal(t, "tags length (504) exceeds the maximum allowed (500)", result.Errors[3].Message)
+	assert.Equal(t, 2, repo.Len())
+}
+
 func TestService_AnnotationTypeScopeResolver(t *testing.T) {
 	rootDashUID := "root-dashboard"
 	folderDashUID := "folder-dashboard"
@@ -27,6 +27,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/guardian"
 	"github.com/grafana/grafana/pkg/services/org"
 	pref "github.com/grafana/grafana/pkg/services/preference"
+	"github.com/grafana/grafana/pkg/services/provisioning/gitsync"
 	publicdashboardModels "github.com/grafana/grafana/pkg/services/publicdashboards/models"
 	"github.com/grafana/grafana/pkg/services/star"
 	"github.com/grafana/grafana/pkg/services/user"
@@ -123,6 +124,8 @@ func (hs *HTTPServer) GetDashboard(c *contextmodel.ReqContext) response.Response
 	if canView, err := guardian.CanView(); err != nil || !canView {
 		return dashboardGuardianResponse(err)
 	}
+	hs.dashboardUsageService.RecordView(c.Req.Context(), c.SignedInUser.GetOrgID(), dash.UID, c.SignedInUser)
+
 	canEdit, _ := guardian.CanEdit()
 	canSave, _ := guardian.CanSave()
 	canAdmin, _ := guardian.CanAdmin()
@@ -487,6 +490,18 @@ func (hs *HTTPServer) postDashboard(c *contextmodel.ReqContext, cmd dashboards.S
 		return response.Error(http.StatusInternalServerError, "Error while connecting library panels", err)
 	}
 
+	// commit the change to the git repository the dashboard was provisioned from in the background, the
+	// changes which can't be committed are reported in the git sync status and don't fail the save
+	if provisioningData != nil && allowUiUpdate {
+		author := gitsync.Author{Name: c.SignedInUser.GetDisplayName(), Email: c.SignedInUser.GetEmail()}
+		if author.Name == "" {
+			author.Name = c.SignedInUser.GetLogin()
+		}
+		if err := hs.ProvisioningService.QueueProvisionedDashboardCommit(provisioningData, dashboard.Data, cmd.Message, author); err != nil {
+			hs.log.Warn("Failed to queue the commit of provisioned dashboard", "uid", dashboard.UID, "provisioner", provisioningData.Name, "error", err)
+		}
+	}
+
 	c.TimeRequest(metrics.MApiDashboardSave)
 	return response.JSON(http.StatusOK, util.DynMap{
 		"status":    "success",
@@ -667,6 +682,11 @@ func (hs *HTTPServer) GetDashboardVersions(c *contextmodel.ReqContext) response.
 		return response.Error(http.StatusNotFound, fmt.Sprintf("No versions found for dashboardId %d", dash.ID), err)
 	}
 
+	return response.JSON(http.StatusOK, hs.toDashboardVersionMetas(c.Req.Context(), dash.UID, versions))
+}
+
+// toDashboardVersionMetas converts the versions of a dashboard, or of a folder, to their API representation.
+func (hs *HTTPServer) toDashboardVersionMetas(ctx context.Context, uid string, versions []*dashver.DashboardVersionDTO) []dashver.DashboardVersionMeta {
 	loginMem := make(map[int64]string, len(versions))
 	res := make([]dashver.DashboardVersionMeta, 0, len(versions))
 	for _, version := range versions {
@@ -689,7 +709,7 @@ func (hs *HTTPServer) GetDashboardVersions(c *contextmodel.ReqContext) response.
 			if found {
 				creator = login
 			} else {
-				creator = hs.getUserLogin(c.Req.Context(), version.CreatedBy)
+				creator = hs.getUserLogin(ctx, version.CreatedBy)
 				if creator != anonString {
 					loginMem[version.CreatedBy] = creator
 				}
@@ -699,7 +719,7 @@ func (hs *HTTPServer) GetDashboardVersions(c *contextmodel.ReqContext) response.
 		res = append(res, dashver.DashboardVersionMeta{
 			ID:            version.ID,
 			DashboardID:   version.DashboardID,
-			DashboardUID:  dash.UID,
+			DashboardUID:  uid,
 			Data:          version.Data,
 			ParentVersion: version.ParentVersion,
 			RestoredFrom:  version.RestoredFrom,
@@ -710,7 +730,7 @@ func (hs *HTTPServer) GetDashboardVersions(c *contextmodel.ReqContext) response.
 		})
 	}
 
-	return response.JSON(http.StatusOK, res)
+	return res
 }
 
 // swagger:route GET /dashboards/id/{DashboardID}/versions/{DashboardVersionID} dashboard_versions getDashboardVersionByID
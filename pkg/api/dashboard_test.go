@@ -34,6 +34,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/dashboards/database"
 	"github.com/grafana/grafana/pkg/services/dashboards/service"
+	"github.com/grafana/grafana/pkg/services/dashboardusage/dashboardusagetest"
 	dashver "github.com/grafana/grafana/pkg/services/dashboardversion"
 	"github.com/grafana/grafana/pkg/services/dashboardversion/dashvertest"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
@@ -148,6 +149,7 @@ func TestHTTPServer_GetDashboard_AccessControl(t *testing.T) {
 			hs.Cfg = setting.NewCfg()
 			hs.AccessControl = acimpl.ProvideAccessControl(hs.Cfg)
 			hs.starService = startest.NewStarServiceFake()
+			hs.dashboardUsageService = dashboardusagetest.NewDashboardUsageServiceFake()
 			hs.dashboardProvisioningService = mockDashboardProvisioningService{}
 
 			guardian.InitAccessControlGuardian(hs.Cfg, hs.AccessControl, hs.DashboardService)
@@ -276,7 +278,7 @@ func TestHTTPServer_DeleteDashboardByUID_AccessControl(t *testing.T) {
 			middleware := publicdashboards.NewFakePublicDashboardMiddleware(t)
 			license := licensingtest.NewFakeLicensing()
 			license.On("FeatureEnabled", publicdashboardModels.FeaturePublicDashboardsEmailSharing).Return(false)
-			hs.PublicDashboardsApi = api.ProvideApi(pubDashService, nil, hs.AccessControl, featuremgmt.WithFeatures(), middleware, hs.Cfg, license)
+			hs.PublicDashboardsApi = api.ProvideApi(pubDashService, nil, hs.AccessControl, featuremgmt.WithFeatures(), middleware, hs.Cfg, license, nil)
 
 			guardian.InitAccessControlGuardian(hs.Cfg, hs.AccessControl, hs.DashboardService)
 		})
@@ -680,6 +682,7 @@ func TestDashboardAPIEndpoint(t *testing.T) {
 				DashboardService:             dashboardService,
 				Features:                     featuremgmt.WithFeatures(),
 				starService:                  startest.NewStarServiceFake(),
+				dashboardUsageService:        dashboardusagetest.NewDashboardUsageServiceFake(),
 			}
 			hs.callGetDashboard(sc)
 
@@ -855,6 +858,7 @@ func getDashboardShouldReturn200WithConfig(t *testing.T, sc *scenarioContext, pr
 		DashboardService:             dashboardService,
 		Features:                     featuremgmt.WithFeatures(),
 		starService:                  startest.NewStarServiceFake(),
+		dashboardUsageService:        dashboardusagetest.NewDashboardUsageServiceFake(),
 	}
 
 	hs.callGetDashboard(sc)
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/dashboardusage"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+const defaultDashboardUsageDays = 30
+
+// swagger:route GET /dashboards/uid/{uid}/usage dashboards getDashboardUsageByUID
+//
+// Get the number of views and query errors of a dashboard per day.
+//
+// The users who viewed the dashboard are only returned when `track_users` is enabled in the `dashboard_usage` section of the configuration.
+//
+// Responses:
+// 200: getDashboardUsageResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) GetDashboardUsage(c *contextmodel.ReqContext) response.Response {
+	dash, rsp := hs.getDashboardHelper(c.Req.Context(), c.SignedInUser.GetOrgID(), 0, web.Params(c.Req)[":uid"])
+	if rsp != nil {
+		return rsp
+	}
+
+	guardian, err := guardian.NewByDashboard(c.Req.Context(), dash, c.SignedInUser.GetOrgID(), c.SignedInUser)
+	if err != nil {
+		return response.Err(err)
+	}
+	if canView, err := guardian.CanView(); err != nil || !canView {
+		return dashboardGuardianResponse(err)
+	}
+
+	days := c.QueryInt("days")
+	if days == 0 {
+		days = defaultDashboardUsageDays
+	}
+	if days < 0 {
+		return response.Error(http.StatusBadRequest, "days must be positive", nil)
+	}
+
+	usage, err := hs.dashboardUsageService.GetUsage(c.Req.Context(), &dashboardusage.GetUsageQuery{
+		OrgID:        c.SignedInUser.GetOrgID(),
+		DashboardUID: dash.UID,
+		From:         time.Now().AddDate(0, 0, -days),
+	})
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get dashboard usage", err)
+	}
+
+	for i := range usage.Viewers {
+		usage.Viewers[i].Login = hs.getUserLogin(c.Req.Context(), usage.Viewers[i].UserID)
+	}
+
+	return response.JSON(http.StatusOK, usage)
+}
+
+// swagger:parameters getDashboardUsageByUID
+type GetDashboardUsageByUIDParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+	// Number of days of usage to return, including today.
+	// in:query
+	// required:false
+	// default:30
+	Days int64 `json:"days"`
+}
+
+// swagger:response getDashboardUsageResponse
+type GetDashboardUsageResponse struct {
+	// in: body
+	Body dashboardusage.DashboardUsage `json:"body"`
+}
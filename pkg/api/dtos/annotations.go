@@ -14,6 +14,19 @@ type PostAnnotationsCmd struct {
 	Data *simplejson.Json `json:"data"`
 }
 
+// ImportAnnotationsCmd is an annotation of the NDJSON body of an annotations import
+type ImportAnnotationsCmd struct {
+	PostAnnotationsCmd
+	// IdempotencyKey identifies the annotation, so that it is imported only once when the import is retried
+	IdempotencyKey string `json:"idempotencyKey,omitempty"`
+}
+
+// ImportAnnotationsError is the error of a line of an annotations import
+type ImportAnnotationsError struct {
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
 type UpdateAnnotationsCmd struct {
 	Id      int64            `json:"id"`
 	Time    int64            `json:"time"`
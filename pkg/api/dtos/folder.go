@@ -36,3 +36,9 @@ type FolderSearchHit struct {
 	Title     string `json:"title"`
 	ParentUID string `json:"parentUid,omitempty"`
 }
+
+type CalculateFolderDiffOptions struct {
+	BaseVersion int    `json:"baseVersion" binding:"Required"`
+	NewVersion  int    `json:"newVersion" binding:"Required"`
+	DiffType    string `json:"diffType" binding:"Required"`
+}
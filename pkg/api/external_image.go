@@ -0,0 +1,41 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/components/imguploader"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// GetExternalImage serves the images stored by the database image uploader. They're
+// available to the signed in users of the organization of the image, and to anyone
+// with a signed URL which hasn't expired, such as the receivers of alert notifications.
+func (hs *HTTPServer) GetExternalImage(c *contextmodel.ReqContext) response.Response {
+	uid := strings.TrimSuffix(web.Params(c.Req)[":file"], ".png")
+	now := time.Now()
+
+	signed := imguploader.VerifyDatabaseImageSignature(hs.Cfg.SecretKey, uid, c.QueryInt64("expires"), c.Query("signature"), now)
+	if !signed && !c.IsSignedIn {
+		return response.Error(http.StatusUnauthorized, "Invalid or expired image URL", nil)
+	}
+
+	image, err := imguploader.GetDatabaseImage(c.Req.Context(), hs.SQLStore, uid, now)
+	if err != nil {
+		if errors.Is(err, imguploader.ErrDatabaseImageNotFound) {
+			return response.Error(http.StatusNotFound, "Image not found", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to get image", err)
+	}
+	if !signed && image.OrgID != c.SignedInUser.GetOrgID() {
+		return response.Error(http.StatusNotFound, "Image not found", nil)
+	}
+
+	return response.Respond(http.StatusOK, image.Content).
+		SetHeader("Content-Type", "image/png").
+		SetHeader("Cache-Control", "private, max-age=3600")
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/imguploader"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web/webtest"
+)
+
+func TestIntegrationGetExternalImage(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	store := db.InitTestDB(t)
+	uploader, err := imguploader.NewDatabaseUploader(store, "secret", time.Hour)
+	require.NoError(t, err)
+	imageURL, err := uploader.Upload(imguploader.ContextWithOrgID(context.Background(), 1), "../../public/img/logo_transparent_400x.png")
+	require.NoError(t, err)
+	parsed, err := url.Parse(imageURL)
+	require.NoError(t, err)
+	unsignedPath := "/" + imguploader.DatabaseImagesPath + parsed.Path[strings.LastIndex(parsed.Path, "/")+1:]
+	signedPath := unsignedPath + "?" + parsed.RawQuery
+
+	server := SetupAPITestServer(t, func(hs *HTTPServer) {
+		hs.Cfg = setting.NewCfg()
+		hs.Cfg.SecretKey = "secret"
+		hs.SQLStore = store
+	})
+
+	send := func(req *http.Request) int {
+		t.Helper()
+		resp, err := server.Send(req)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		return resp.StatusCode
+	}
+
+	t.Run("serves the image to the users of its organization", func(t *testing.T) {
+		req := webtest.RequestWithSignedInUser(server.NewGetRequest(unsignedPath), userWithPermissions(1, nil))
+		assert.Equal(t, http.StatusOK, send(req))
+	})
+
+	t.Run("does not serve the image to the users of other organizations", func(t *testing.T) {
+		req := webtest.RequestWithSignedInUser(server.NewGetRequest(unsignedPath), userWithPermissions(2, nil))
+		assert.Equal(t, http.StatusNotFound, send(req))
+	})
+
+	t.Run("serves the image to anyone with a signed URL", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, send(server.NewGetRequest(signedPath)))
+		req := webtest.RequestWithSignedInUser(server.NewGetRequest(signedPath), userWithPermissions(2, nil))
+		assert.Equal(t, http.StatusOK, send(req))
+	})
+
+	t.Run("requires a signed URL when not signed in", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, send(server.NewGetRequest(unsignedPath)))
+	})
+}
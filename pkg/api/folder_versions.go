@@ -0,0 +1,262 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/api/apierrors"
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/components/dashdiffs"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	dashver "github.com/grafana/grafana/pkg/services/dashboardversion"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// The folders are stored as dashboards, the versions of their title and description are then saved as
+// dashboard versions and share their API representation.
+
+// swagger:route GET /folders/{folder_uid}/versions folders getFolderVersions
+//
+// Gets all existing versions for the folder.
+//
+// Responses:
+// 200: dashboardVersionsResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) GetFolderVersions(c *contextmodel.ReqContext) response.Response {
+	f, err := hs.getFolderForVersions(c)
+	if err != nil {
+		return apierrors.ToFolderErrorResponse(err)
+	}
+
+	query := dashver.ListDashboardVersionsQuery{
+		OrgID:        c.SignedInUser.GetOrgID(),
+		DashboardID:  f.ID, // nolint:staticcheck
+		DashboardUID: f.UID,
+		Limit:        c.QueryInt("limit"),
+		Start:        c.QueryInt("start"),
+	}
+
+	versions, err := hs.dashboardVersionService.List(c.Req.Context(), &query)
+	if err != nil {
+		return response.Error(http.StatusNotFound, fmt.Sprintf("No versions found for folder %s", f.UID), err)
+	}
+
+	return response.JSON(http.StatusOK, hs.toDashboardVersionMetas(c.Req.Context(), f.UID, versions))
+}
+
+// swagger:route GET /folders/{folder_uid}/versions/{FolderVersionID} folders getFolderVersion
+//
+// Get a specific folder version.
+//
+// Responses:
+// 200: dashboardVersionResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) GetFolderVersion(c *contextmodel.ReqContext) response.Response {
+	f, err := hs.getFolderForVersions(c)
+	if err != nil {
+		return apierrors.ToFolderErrorResponse(err)
+	}
+
+	version, err := strconv.Atoi(web.Params(c.Req)[":id"])
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "version is invalid", err)
+	}
+
+	res, rsp := hs.getFolderVersion(c, f, version)
+	if rsp != nil {
+		return rsp
+	}
+
+	metas := hs.toDashboardVersionMetas(c.Req.Context(), f.UID, []*dashver.DashboardVersionDTO{res})
+	return response.JSON(http.StatusOK, metas[0])
+}
+
+// swagger:route POST /folders/{folder_uid}/restore folders restoreFolderVersion
+//
+// Restore the title and the description of a folder to a given folder version.
+//
+// The folder stays in its current parent folder.
+//
+// Responses:
+// 200: folderResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 409: conflictError
+// 500: internalServerError
+func (hs *HTTPServer) RestoreFolderVersion(c *contextmodel.ReqContext) response.Response {
+	apiCmd := dtos.RestoreDashboardVersionCommand{}
+	if err := web.Bind(c.Req, &apiCmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	f, err := hs.getFolderForVersions(c)
+	if err != nil {
+		return apierrors.ToFolderErrorResponse(err)
+	}
+
+	version, rsp := hs.getFolderVersion(c, f, apiCmd.Version)
+	if rsp != nil {
+		return rsp
+	}
+
+	title := version.Data.Get("title").MustString()
+	cmd := folder.UpdateFolderCommand{
+		UID:      f.UID,
+		OrgID:    c.SignedInUser.GetOrgID(),
+		NewTitle: &title,
+		// like for dashboards, the restored version replaces the current one
+		Overwrite:    true,
+		RestoredFrom: version.Version,
+		SignedInUser: c.SignedInUser,
+	}
+	// the versions saved before the descriptions were versioned keep the current description
+	if description, ok := version.Data.CheckGet("description"); ok {
+		value := description.MustString()
+		cmd.NewDescription = &value
+	}
+
+	result, err := hs.folderService.Update(c.Req.Context(), &cmd)
+	if err != nil {
+		return apierrors.ToFolderErrorResponse(err)
+	}
+	folderDTO, err := hs.newToFolderDto(c, result)
+	if err != nil {
+		return response.Err(err)
+	}
+
+	return response.JSON(http.StatusOK, folderDTO)
+}
+
+// swagger:route POST /folders/{folder_uid}/calculate-diff folders calculateFolderDiff
+//
+// Perform diff on two versions of a folder.
+//
+// Produces:
+// - application/json
+// - text/html
+//
+// Responses:
+// 200: calculateDashboardDiffResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) CalculateFolderDiff(c *contextmodel.ReqContext) response.Response {
+	apiOptions := dtos.CalculateFolderDiffOptions{}
+	if err := web.Bind(c.Req, &apiOptions); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	f, err := hs.getFolderForVersions(c)
+	if err != nil {
+		return apierrors.ToFolderErrorResponse(err)
+	}
+
+	base, rsp := hs.getFolderVersion(c, f, apiOptions.BaseVersion)
+	if rsp != nil {
+		return rsp
+	}
+	newVersion, rsp := hs.getFolderVersion(c, f, apiOptions.NewVersion)
+	if rsp != nil {
+		return rsp
+	}
+
+	options := dashdiffs.Options{
+		OrgId:    c.SignedInUser.GetOrgID(),
+		DiffType: dashdiffs.ParseDiffType(apiOptions.DiffType),
+	}
+	result, err := dashdiffs.CalculateDiff(c.Req.Context(), &options, base.Data, newVersion.Data)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Unable to compute diff", err)
+	}
+
+	if options.DiffType == dashdiffs.DiffDelta {
+		return response.Respond(http.StatusOK, result.Delta).SetHeader("Content-Type", "application/json")
+	}
+
+	return response.Respond(http.StatusOK, result.Delta).SetHeader("Content-Type", "text/html")
+}
+
+// getFolderForVersions gets the folder of the request, which the user must be allowed to read.
+func (hs *HTTPServer) getFolderForVersions(c *contextmodel.ReqContext) (*folder.Folder, error) {
+	uid := web.Params(c.Req)[":uid"]
+	return hs.folderService.Get(c.Req.Context(), &folder.GetFolderQuery{OrgID: c.SignedInUser.GetOrgID(), UID: &uid, SignedInUser: c.SignedInUser})
+}
+
+func (hs *HTTPServer) getFolderVersion(c *contextmodel.ReqContext, f *folder.Folder, version int) (*dashver.DashboardVersionDTO, response.Response) {
+	query := dashver.GetDashboardVersionQuery{
+		OrgID:        c.SignedInUser.GetOrgID(),
+		DashboardID:  f.ID, // nolint:staticcheck
+		DashboardUID: f.UID,
+		Version:      version,
+	}
+
+	res, err := hs.dashboardVersionService.Get(c.Req.Context(), &query)
+	if err != nil {
+		if errors.Is(err, dashver.ErrDashboardVersionNotFound) {
+			return nil, response.Error(http.StatusNotFound, fmt.Sprintf("Folder version %d not found", version), err)
+		}
+		return nil, response.Error(http.StatusInternalServerError, "Failed to get the folder version", err)
+	}
+	return res, nil
+}
+
+// swagger:parameters getFolderVersions
+type GetFolderVersionsParams struct {
+	// in:path
+	// required:true
+	FolderUID string `json:"folder_uid"`
+	// Maximum number of results to return
+	// in:query
+	// required:false
+	// default:0
+	Limit int `json:"limit"`
+	// Version to start from when returning queries
+	// in:query
+	// required:false
+	// default:0
+	Start int `json:"start"`
+}
+
+// swagger:parameters getFolderVersion
+type GetFolderVersionParams struct {
+	// in:path
+	// required:true
+	FolderUID string `json:"folder_uid"`
+	// in:path
+	// required:true
+	FolderVersionID int
+}
+
+// swagger:parameters restoreFolderVersion
+type RestoreFolderVersionParams struct {
+	// in:path
+	// required:true
+	FolderUID string `json:"folder_uid"`
+	// in:body
+	// required:true
+	Body dtos.RestoreDashboardVersionCommand
+}
+
+// swagger:parameters calculateFolderDiff
+type CalculateFolderDiffParams struct {
+	// in:path
+	// required:true
+	FolderUID string `json:"folder_uid"`
+	// in:body
+	// required:true
+	Body dtos.CalculateFolderDiffOptions
+}
@@ -0,0 +1,154 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	dashver "github.com/grafana/grafana/pkg/services/dashboardversion"
+	"github.com/grafana/grafana/pkg/services/dashboardversion/dashvertest"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/folder/foldertest"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web/webtest"
+)
+
+// updateRecordingFolderService records the update commands of the folder service.
+type updateRecordingFolderService struct {
+	*foldertest.FakeService
+	updated *folder.UpdateFolderCommand
+}
+
+func (s *updateRecordingFolderService) Update(ctx context.Context, cmd *folder.UpdateFolderCommand) (*folder.Folder, error) {
+	s.updated = cmd
+	return s.FakeService.Update(ctx, cmd)
+}
+
+func TestFolderVersionsAPIEndpoint(t *testing.T) {
+	writePermissions := []accesscontrol.Permission{{Action: dashboards.ActionFoldersWrite, Scope: dashboards.ScopeFoldersProvider.GetResourceScopeUID("uid")}}
+	readPermissions := []accesscontrol.Permission{{Action: dashboards.ActionFoldersRead, Scope: dashboards.ScopeFoldersProvider.GetResourceScopeUID("uid")}}
+
+	versionData := func(title string, description string) *simplejson.Json {
+		data := simplejson.New()
+		data.Set("title", title)
+		if description != "" {
+			data.Set("description", description)
+		}
+		return data
+	}
+
+	setup := func(t *testing.T, versions ...*dashver.DashboardVersionDTO) (*webtest.Server, *updateRecordingFolderService) {
+		t.Helper()
+		folderService := &updateRecordingFolderService{FakeService: &foldertest.FakeService{
+			ExpectedFolder: &folder.Folder{ID: 1, UID: "uid", Title: "Current"},
+		}}
+		server := SetupAPITestServer(t, func(hs *HTTPServer) {
+			hs.Cfg = setting.NewCfg()
+			hs.folderService = folderService
+			hs.dashboardVersionService = &dashvertest.FakeDashboardVersionService{
+				ExpectedListDashboarVersions: versions,
+				ExpectedDashboardVersions:    versions,
+			}
+		})
+		return server, folderService
+	}
+
+	t.Run("lists the versions of the folder", func(t *testing.T) {
+		server, _ := setup(t,
+			&dashver.DashboardVersionDTO{ID: 2, DashboardID: 1, Version: 2, ParentVersion: 1, Message: "renamed", Data: versionData("Current", "")},
+			&dashver.DashboardVersionDTO{ID: 1, DashboardID: 1, Version: 1, Data: versionData("Previous", "")},
+		)
+
+		resp, err := server.Send(webtest.RequestWithSignedInUser(server.NewGetRequest("/api/folders/uid/versions"), userWithPermissions(1, writePermissions)))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var versions []dashver.DashboardVersionMeta
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&versions))
+		require.NoError(t, resp.Body.Close())
+		require.Len(t, versions, 2)
+		assert.Equal(t, "uid", versions[0].DashboardUID)
+		assert.Equal(t, "renamed", versions[0].Message)
+		assert.Equal(t, "Initial save", versions[1].Message)
+	})
+
+	t.Run("requires the permission to write the folder", func(t *testing.T) {
+		server, _ := setup(t)
+
+		for _, req := range []*http.Request{
+			server.NewGetRequest("/api/folders/uid/versions"),
+			server.NewGetRequest("/api/folders/uid/versions/1"),
+			server.NewPostRequest("/api/folders/uid/restore", strings.NewReader(`{"version": 1}`)),
+			server.NewPostRequest("/api/folders/uid/calculate-diff", strings.NewReader(`{"baseVersion": 1, "newVersion": 2, "diffType": "json"}`)),
+		} {
+			resp, err := server.Send(webtest.RequestWithSignedInUser(req, userWithPermissions(1, readPermissions)))
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusForbidden, resp.StatusCode, req.URL.Path)
+			require.NoError(t, resp.Body.Close())
+		}
+	})
+
+	t.Run("restores the title and the description of a version", func(t *testing.T) {
+		server, folderService := setup(t,
+			&dashver.DashboardVersionDTO{ID: 1, DashboardID: 1, Version: 1, Data: versionData("Previous", "The previous description")},
+		)
+
+		req := server.NewPostRequest("/api/folders/uid/restore", strings.NewReader(`{"version": 1}`))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := server.Send(webtest.RequestWithSignedInUser(req, userWithPermissions(1, writePermissions)))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.NoError(t, resp.Body.Close())
+
+		require.NotNil(t, folderService.updated)
+		assert.Equal(t, "uid", folderService.updated.UID)
+		assert.Equal(t, "Previous", *folderService.updated.NewTitle)
+		assert.Equal(t, "The previous description", *folderService.updated.NewDescription)
+		assert.Equal(t, 1, folderService.updated.RestoredFrom)
+	})
+
+	t.Run("keeps the description when the version does not have one", func(t *testing.T) {
+		server, folderService := setup(t,
+			&dashver.DashboardVersionDTO{ID: 1, DashboardID: 1, Version: 1, Data: versionData("Previous", "")},
+		)
+
+		req := server.NewPostRequest("/api/folders/uid/restore", strings.NewReader(`{"version": 1}`))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := server.Send(webtest.RequestWithSignedInUser(req, userWithPermissions(1, writePermissions)))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.NoError(t, resp.Body.Close())
+
+		require.NotNil(t, folderService.updated)
+		assert.Nil(t, folderService.updated.NewDescription)
+	})
+
+	t.Run("calculates the diff of two versions", func(t *testing.T) {
+		server, _ := setup(t,
+			&dashver.DashboardVersionDTO{ID: 1, DashboardID: 1, Version: 1, Data: versionData("Previous", "")},
+			&dashver.DashboardVersionDTO{ID: 2, DashboardID: 1, Version: 2, ParentVersion: 1, Data: versionData("Current", "")},
+		)
+
+		req := server.NewPostRequest("/api/folders/uid/calculate-diff", strings.NewReader(`{"baseVersion": 1, "newVersion": 2, "diffType": "json"}`))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := server.Send(webtest.RequestWithSignedInUser(req, userWithPermissions(1, writePermissions)))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "text/html", resp.Header.Get("Content-Type"))
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		assert.Contains(t, string(body), "Previous")
+		assert.Contains(t, string(body), "Current")
+	})
+}
@@ -17,6 +17,7 @@ import (
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/query"
 	"github.com/grafana/grafana/pkg/util/errutil/errhttp"
 	"github.com/grafana/grafana/pkg/web"
 )
@@ -80,11 +81,25 @@ func (hs *HTTPServer) QueryMetricsV2(c *contextmodel.ReqContext) response.Respon
 
 	resp, err := hs.queryDataService.QueryData(c.Req.Context(), c.SignedInUser, c.SkipDSCache, reqDTO)
 	if err != nil {
+		hs.recordDashboardQueryError(c)
 		return hs.handleQueryMetricsError(err)
 	}
+	for _, res := range resp.Responses {
+		if res.Error != nil {
+			hs.recordDashboardQueryError(c)
+			break
+		}
+	}
 	return hs.toJsonStreamingResponse(c.Req.Context(), resp)
 }
 
+// recordDashboardQueryError records a failed query for the dashboard the query was sent from, if any.
+func (hs *HTTPServer) recordDashboardQueryError(c *contextmodel.ReqContext) {
+	if dashboardUID := c.Req.Header.Get(query.HeaderDashboardUID); dashboardUID != "" {
+		hs.dashboardUsageService.RecordQueryError(c.Req.Context(), c.SignedInUser.GetOrgID(), dashboardUID)
+	}
+}
+
 func (hs *HTTPServer) toJsonStreamingResponse(ctx context.Context, qdr *backend.QueryDataResponse) response.Response {
 	statusWhenError := http.StatusBadRequest
 	if hs.Features.IsEnabled(ctx, featuremgmt.FlagDatasourceQueryMultiStatus) {
@@ -0,0 +1,61 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	"github.com/grafana/grafana/pkg/server"
+)
+
+var errMissingBackupPath = errors.New("missing backup archive path")
+
+func createBackupCommand(c utils.CommandLine, runner server.Runner) error {
+	path := c.Args().First()
+	if path == "" {
+		return errMissingBackupPath
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create backup archive: %w", err)
+	}
+
+	manifest, err := runner.BackupService.Backup(context.Background(), f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(path)
+		return fmt.Errorf("failed to create backup: %w", err)
+	}
+
+	logger.Infof("Backup of %d tables written to %s %s\n", len(manifest.Tables), path, color.GreenString("✔"))
+	return nil
+}
+
+func restoreBackupCommand(c utils.CommandLine, runner server.Runner) error {
+	path := c.Args().First()
+	if path == "" {
+		return errMissingBackupPath
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open backup archive: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	manifest, err := runner.BackupService.Restore(context.Background(), f)
+	if err != nil {
+		return fmt.Errorf("failed to restore backup: %w", err)
+	}
+
+	logger.Infof("Restored backup of Grafana %s created at %s %s\n", manifest.GrafanaVersion, manifest.CreatedAt, color.GreenString("✔"))
+	return nil
+}
@@ -167,6 +167,22 @@ var adminCommands = []*cli.Command{
 			},
 		},
 	},
+	{
+		Name:  "backup",
+		Usage: "Creates and restores backups of the Grafana database",
+		Subcommands: []*cli.Command{
+			{
+				Name:   "create",
+				Usage:  "create <archive path>. Writes a consistent backup of the database, data key metadata, provisioned file references and plugin list. Safe to execute while Grafana is running.",
+				Action: runRunnerCommand(createBackupCommand),
+			},
+			{
+				Name:   "restore",
+				Usage:  "restore <archive path>. Replaces the database contents with the backup and runs the database migrations. > Note: Grafana must be stopped, this is irreversible.",
+				Action: runRunnerCommand(restoreBackupCommand),
+			},
+		},
+	},
 	{
 		Name:  "user-manager",
 		Usage: "Runs different helpful user commands",
@@ -0,0 +1,160 @@
+package imguploader
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	defaultDatabaseImageExpiration = 7 * 24 * time.Hour // 7 days
+
+	// DatabaseImagesPath is the path of the route serving the images stored by the database uploader.
+	DatabaseImagesPath = "api/images/"
+)
+
+var ErrDatabaseImageNotFound = errors.New("image not found")
+
+// DatabaseImage is an image stored in the Grafana database. It's deleted once expired.
+type DatabaseImage struct {
+	ID      int64  `xorm:"pk autoincr 'id'"`
+	UID     string `xorm:"uid"`
+	OrgID   int64  `xorm:"org_id"`
+	Content []byte `xorm:"content"`
+	Created int64  `xorm:"created"`
+	Expires int64  `xorm:"expires"`
+}
+
+func (DatabaseImage) TableName() string { return "external_image" }
+
+type orgIDContextKey struct{}
+
+// ContextWithOrgID returns a context for uploading the images of the organization
+// to the database, so that only its users can get them without a signed URL.
+func ContextWithOrgID(ctx context.Context, orgID int64) context.Context {
+	return context.WithValue(ctx, orgIDContextKey{}, orgID)
+}
+
+func orgIDFromContext(ctx context.Context) int64 {
+	orgID, _ := ctx.Value(orgIDContextKey{}).(int64)
+	return orgID
+}
+
+// DatabaseUploader stores the images in the Grafana database, so that they can be
+// served by Grafana itself when no external storage is available. The returned URLs
+// are signed, which lets notification receivers that aren't signed in to Grafana
+// download the images until they expire.
+type DatabaseUploader struct {
+	store      db.DB
+	secretKey  string
+	expiration time.Duration
+	now        func() time.Time
+}
+
+func NewDatabaseUploader(store db.DB, secretKey string, expiration time.Duration) (*DatabaseUploader, error) {
+	if expiration <= 0 {
+		return nil, fmt.Errorf("invalid image expiration: %q", expiration)
+	}
+	if secretKey == "" {
+		return nil, fmt.Errorf("a secret key is required to sign the image URLs")
+	}
+	return &DatabaseUploader{
+		store:      store,
+		secretKey:  secretKey,
+		expiration: expiration,
+		now:        time.Now,
+	}, nil
+}
+
+func (u *DatabaseUploader) Upload(ctx context.Context, imageDiskPath string) (string, error) {
+	// We can ignore the gosec G304 warning on this one because `imageDiskPath` comes
+	// from alert notifiers and is only used to upload images generated by alerting.
+	// nolint:gosec
+	content, err := os.ReadFile(imageDiskPath)
+	if err != nil {
+		return "", err
+	}
+
+	now := u.now()
+	image := DatabaseImage{
+		UID:     util.GenerateShortUID(),
+		OrgID:   orgIDFromContext(ctx),
+		Content: content,
+		Created: now.Unix(),
+		Expires: now.Add(u.expiration).Unix(),
+	}
+	err = u.store.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Insert(&image)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return setting.ToAbsUrl(u.imagePath(image.UID, image.Expires)), nil
+}
+
+func (u *DatabaseUploader) imagePath(uid string, expires int64) string {
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expires, 10))
+	query.Set("signature", SignDatabaseImage(u.secretKey, uid, expires))
+	return DatabaseImagesPath + uid + pngExt + "?" + query.Encode()
+}
+
+// SignDatabaseImage returns the signature of the URL of an image stored in the database.
+func SignDatabaseImage(secretKey, uid string, expires int64) string {
+	mac := hmac.New(sha256.New, []byte(secretKey))
+	mac.Write([]byte(uid))
+	mac.Write([]byte("."))
+	mac.Write([]byte(strconv.FormatInt(expires, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyDatabaseImageSignature returns true if the signature of the URL is valid and the URL hasn't expired.
+func VerifyDatabaseImageSignature(secretKey, uid string, expires int64, signature string, now time.Time) bool {
+	if now.Unix() > expires {
+		return false
+	}
+	return hmac.Equal([]byte(SignDatabaseImage(secretKey, uid, expires)), []byte(signature))
+}
+
+// GetDatabaseImage returns an image stored in the database, unless it has expired.
+func GetDatabaseImage(ctx context.Context, store db.DB, uid string, now time.Time) (*DatabaseImage, error) {
+	image := &DatabaseImage{}
+	err := store.WithDbSession(ctx, func(sess *db.Session) error {
+		has, err := sess.Where("uid = ? AND expires >= ?", uid, now.Unix()).Get(image)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return ErrDatabaseImageNotFound
+		}
+		return nil
+	})
+	return image, err
+}
+
+// DeleteExpiredDatabaseImages deletes the images stored in the database which have expired.
+func DeleteExpiredDatabaseImages(ctx context.Context, store db.DB, now time.Time) (int64, error) {
+	var affected int64
+	err := store.WithDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec("DELETE FROM external_image WHERE expires < ?", now.Unix())
+		if err != nil {
+			return err
+		}
+		affected, err = res.RowsAffected()
+		return err
+	})
+	return affected, err
+}
@@ -0,0 +1,80 @@
+package imguploader
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestDatabaseImageSignature(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	expires := now.Add(time.Hour).Unix()
+	signature := SignDatabaseImage("secret", "abc", expires)
+
+	assert.True(t, VerifyDatabaseImageSignature("secret", "abc", expires, signature, now))
+	assert.False(t, VerifyDatabaseImageSignature("other", "abc", expires, signature, now))
+	assert.False(t, VerifyDatabaseImageSignature("secret", "abd", expires, signature, now))
+	assert.False(t, VerifyDatabaseImageSignature("secret", "abc", expires+1, signature, now))
+	assert.False(t, VerifyDatabaseImageSignature("secret", "abc", expires, signature, now.Add(2*time.Hour)))
+}
+
+func TestIntegrationUploadToDatabase(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	store := db.InitTestDB(t)
+	ctx := context.Background()
+
+	_, err := NewDatabaseUploader(store, "secret", 0)
+	require.Error(t, err)
+	_, err = NewDatabaseUploader(store, "", time.Hour)
+	require.Error(t, err)
+
+	uploader, err := NewDatabaseUploader(store, "secret", time.Hour)
+	require.NoError(t, err)
+	now := time.Now()
+	uploader.now = func() time.Time { return now }
+
+	imageURL, err := uploader.Upload(ContextWithOrgID(ctx, 2), "../../../public/img/logo_transparent_400x.png")
+	require.NoError(t, err)
+	require.Contains(t, imageURL, "/"+DatabaseImagesPath)
+
+	parsed, err := url.Parse(imageURL)
+	require.NoError(t, err)
+	uid := strings.TrimSuffix(parsed.Path[strings.LastIndex(parsed.Path, "/")+1:], pngExt)
+	expires, err := strconv.ParseInt(parsed.Query().Get("expires"), 10, 64)
+	require.NoError(t, err)
+	require.Equal(t, now.Add(time.Hour).Unix(), expires)
+	require.True(t, VerifyDatabaseImageSignature("secret", uid, expires, parsed.Query().Get("signature"), now))
+
+	image, err := GetDatabaseImage(ctx, store, uid, now)
+	require.NoError(t, err)
+	require.NotEmpty(t, image.Content)
+	require.Equal(t, int64(2), image.OrgID)
+
+	t.Run("expired images aren't returned and are deleted", func(t *testing.T) {
+		later := now.Add(2 * time.Hour)
+		_, err := GetDatabaseImage(ctx, store, uid, later)
+		require.ErrorIs(t, err, ErrDatabaseImageNotFound)
+
+		deleted, err := DeleteExpiredDatabaseImages(ctx, store, later)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), deleted)
+
+		_, err = GetDatabaseImage(ctx, store, uid, now)
+		require.ErrorIs(t, err, ErrDatabaseImageNotFound)
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/grafana/grafana/pkg/components/imguploader/gcs"
+	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/setting"
 )
@@ -14,6 +15,9 @@ import (
 const (
 	pngExt                        = ".png"
 	defaultGCSSignedURLExpiration = 7 * 24 * time.Hour // 7 days
+	defaultS3SignedURLExpiration  = 7 * 24 * time.Hour // 7 days
+	// maxS3SignedURLExpiration is the longest expiration allowed by signature version 4
+	maxS3SignedURLExpiration = 7 * 24 * time.Hour
 )
 
 //go:generate mockgen -destination=mock.go -package=imguploader github.com/grafana/grafana/pkg/components/imguploader ImageUploader
@@ -32,7 +36,7 @@ var (
 	logger = log.New("imguploader")
 )
 
-func NewImageUploader(cfg *setting.Cfg) (ImageUploader, error) {
+func NewImageUploader(cfg *setting.Cfg, sqlStore db.DB) (ImageUploader, error) {
 	switch cfg.ImageUploadProvider {
 	case "s3":
 		s3sec, err := cfg.Raw.GetSection("external_image_storage.s3")
@@ -48,6 +52,14 @@ func NewImageUploader(cfg *setting.Cfg) (ImageUploader, error) {
 		bucketUrl := s3sec.Key("bucket_url").MustString("")
 		accessKey := s3sec.Key("access_key").MustString("")
 		secretKey := s3sec.Key("secret_key").MustString("")
+		enableSignedURLs := s3sec.Key("enable_signed_urls").MustBool(false)
+		suExp := defaultS3SignedURLExpiration
+		if exp := s3sec.Key("signed_url_expiration").MustString(""); exp != "" {
+			suExp, err = time.ParseDuration(exp)
+			if err != nil {
+				return nil, err
+			}
+		}
 
 		if path != "" && path[len(path)-1:] != "/" {
 			path += "/"
@@ -62,7 +74,7 @@ func NewImageUploader(cfg *setting.Cfg) (ImageUploader, error) {
 			region = info.region
 		}
 
-		return NewS3Uploader(endpoint, region, bucket, path, "public-read", accessKey, secretKey, pathStyleAccess), nil
+		return NewS3Uploader(endpoint, region, bucket, path, "public-read", accessKey, secretKey, pathStyleAccess, enableSignedURLs, suExp)
 	case "webdav":
 		webdavSec, err := cfg.Raw.GetSection("external_image_storage.webdav")
 		if err != nil {
@@ -114,6 +126,38 @@ func NewImageUploader(cfg *setting.Cfg) (ImageUploader, error) {
 
 		return NewAzureBlobUploader(account_name, account_key, container_name, sas_token_expiration_days), nil
 
+	case "sftp":
+		sftpSec, err := cfg.Raw.GetSection("external_image_storage.sftp")
+		if err != nil {
+			return nil, err
+		}
+
+		host := sftpSec.Key("host").MustString("")
+		port := sftpSec.Key("port").MustInt(22)
+		username := sftpSec.Key("username").MustString("")
+		password := sftpSec.Key("password").MustString("")
+		privateKeyFile := sftpSec.Key("private_key_file").MustString("")
+		knownHostsFile := sftpSec.Key("known_hosts_file").MustString("")
+		insecureIgnoreHostKey := sftpSec.Key("insecure_ignore_host_key").MustBool(false)
+		path := sftpSec.Key("path").MustString("")
+		publicURL := sftpSec.Key("public_url").MustString("")
+
+		return NewSFTPUploader(host, port, username, password, privateKeyFile, knownHostsFile, insecureIgnoreHostKey, path, publicURL)
+	case "database":
+		databaseSec, err := cfg.Raw.GetSection("external_image_storage.database")
+		if err != nil {
+			return nil, err
+		}
+
+		expiration := defaultDatabaseImageExpiration
+		if exp := databaseSec.Key("expiration").MustString(""); exp != "" {
+			expiration, err = time.ParseDuration(exp)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return NewDatabaseUploader(sqlStore, cfg.SecretKey, expiration)
 	case "local":
 		return NewLocalImageUploader()
 	}
@@ -2,6 +2,7 @@ package imguploader
 
 import (
 	"testing"
+	"time"
 
 	"github.com/grafana/grafana/pkg/components/imguploader/gcs"
 	"github.com/grafana/grafana/pkg/setting"
@@ -29,7 +30,7 @@ func TestImageUploaderFactory(t *testing.T) {
 				_, err = s3sec.NewKey("secret_key", "secret_key")
 				require.NoError(t, err)
 
-				uploader, err := NewImageUploader(cfg)
+				uploader, err := NewImageUploader(cfg, nil)
 				require.NoError(t, err)
 
 				original, ok := uploader.(*S3Uploader)
@@ -50,7 +51,7 @@ func TestImageUploaderFactory(t *testing.T) {
 				_, err = s3sec.NewKey("secret_key", "secret_key")
 				require.NoError(t, err)
 
-				uploader, err := NewImageUploader(cfg)
+				uploader, err := NewImageUploader(cfg, nil)
 				require.NoError(t, err)
 
 				original, ok := uploader.(*S3Uploader)
@@ -71,7 +72,7 @@ func TestImageUploaderFactory(t *testing.T) {
 				_, err = s3sec.NewKey("secret_key", "secret_key")
 				require.NoError(t, err)
 
-				uploader, err := NewImageUploader(cfg)
+				uploader, err := NewImageUploader(cfg, nil)
 				require.NoError(t, err)
 
 				original, ok := uploader.(*S3Uploader)
@@ -81,6 +82,38 @@ func TestImageUploaderFactory(t *testing.T) {
 				require.Equal(t, "access_key", original.accessKey)
 				require.Equal(t, "secret_key", original.secretKey)
 			})
+
+			t.Run("with signed URLs and a custom endpoint", func(t *testing.T) {
+				s3sec, err := cfg.Raw.GetSection("external_image_storage.s3")
+				require.NoError(t, err)
+				_, err = s3sec.NewKey("endpoint", "http://minio:9000")
+				require.NoError(t, err)
+				_, err = s3sec.NewKey("path_style_access", "true")
+				require.NoError(t, err)
+				_, err = s3sec.NewKey("bucket", "grafana")
+				require.NoError(t, err)
+				_, err = s3sec.NewKey("region", "us-east-1")
+				require.NoError(t, err)
+				_, err = s3sec.NewKey("enable_signed_urls", "true")
+				require.NoError(t, err)
+				_, err = s3sec.NewKey("signed_url_expiration", "24h")
+				require.NoError(t, err)
+
+				uploader, err := NewImageUploader(cfg, nil)
+				require.NoError(t, err)
+
+				original, ok := uploader.(*S3Uploader)
+				require.True(t, ok)
+				require.Equal(t, "http://minio:9000", original.endpoint)
+				require.True(t, original.pathStyleAccess)
+				require.True(t, original.enableSignedURLs)
+				require.Equal(t, 24*time.Hour, original.signedURLExpiration)
+
+				_, err = s3sec.NewKey("signed_url_expiration", "720h")
+				require.NoError(t, err)
+				_, err = NewImageUploader(cfg, nil)
+				require.Error(t, err)
+			})
 		})
 
 		t.Run("Webdav uploader", func(t *testing.T) {
@@ -101,7 +134,7 @@ func TestImageUploaderFactory(t *testing.T) {
 			_, err = webdavSec.NewKey("password", "password")
 			require.NoError(t, err)
 
-			uploader, err := NewImageUploader(cfg)
+			uploader, err := NewImageUploader(cfg, nil)
 			require.NoError(t, err)
 			original, ok := uploader.(*WebdavUploader)
 
@@ -127,7 +160,7 @@ func TestImageUploaderFactory(t *testing.T) {
 			_, err = gcpSec.NewKey("bucket", "project-grafana-east")
 			require.NoError(t, err)
 
-			uploader, err := NewImageUploader(cfg)
+			uploader, err := NewImageUploader(cfg, nil)
 			require.NoError(t, err)
 
 			original, ok := uploader.(*gcs.Uploader)
@@ -157,7 +190,7 @@ func TestImageUploaderFactory(t *testing.T) {
 				_, err = azureBlobSec.NewKey("sas_token_expiration_days", "sas_token_expiration_days")
 				require.NoError(t, err)
 
-				uploader, err := NewImageUploader(cfg)
+				uploader, err := NewImageUploader(cfg, nil)
 				require.NoError(t, err)
 
 				original, ok := uploader.(*AzureBlobUploader)
@@ -169,6 +202,63 @@ func TestImageUploaderFactory(t *testing.T) {
 			})
 		})
 
+		t.Run("SFTP uploader", func(t *testing.T) {
+			cfg := setting.NewCfg()
+			err := cfg.Load(setting.CommandLineArgs{
+				HomePath: "../../../",
+			})
+			require.NoError(t, err)
+
+			cfg.ImageUploadProvider = "sftp"
+
+			sftpSec, err := cfg.Raw.GetSection("external_image_storage.sftp")
+			require.NoError(t, err)
+			_, err = sftpSec.NewKey("host", "sftp.example.com")
+			require.NoError(t, err)
+			_, err = sftpSec.NewKey("port", "2222")
+			require.NoError(t, err)
+			_, err = sftpSec.NewKey("username", "username")
+			require.NoError(t, err)
+			_, err = sftpSec.NewKey("known_hosts_file", "/etc/ssh/ssh_known_hosts")
+			require.NoError(t, err)
+			_, err = sftpSec.NewKey("public_url", "https://images.example.com")
+			require.NoError(t, err)
+
+			uploader, err := NewImageUploader(cfg, nil)
+			require.NoError(t, err)
+
+			original, ok := uploader.(*SFTPUploader)
+			require.True(t, ok)
+			require.Equal(t, "sftp.example.com", original.host)
+			require.Equal(t, 2222, original.port)
+			require.Equal(t, "username", original.username)
+			require.Equal(t, "/etc/ssh/ssh_known_hosts", original.knownHostsFile)
+			require.Equal(t, "https://images.example.com", original.publicURL.String())
+		})
+
+		t.Run("Database uploader", func(t *testing.T) {
+			cfg := setting.NewCfg()
+			err := cfg.Load(setting.CommandLineArgs{
+				HomePath: "../../../",
+			})
+			require.NoError(t, err)
+
+			cfg.ImageUploadProvider = "database"
+
+			databaseSec, err := cfg.Raw.GetSection("external_image_storage.database")
+			require.NoError(t, err)
+			_, err = databaseSec.NewKey("expiration", "48h")
+			require.NoError(t, err)
+
+			uploader, err := NewImageUploader(cfg, nil)
+			require.NoError(t, err)
+
+			original, ok := uploader.(*DatabaseUploader)
+			require.True(t, ok)
+			require.Equal(t, 48*time.Hour, original.expiration)
+			require.Equal(t, cfg.SecretKey, original.secretKey)
+		})
+
 		t.Run("Local uploader", func(t *testing.T) {
 			cfg := setting.NewCfg()
 			err := cfg.Load(setting.CommandLineArgs{
@@ -178,7 +268,7 @@ func TestImageUploaderFactory(t *testing.T) {
 
 			cfg.ImageUploadProvider = "local"
 
-			uploader, err := NewImageUploader(cfg)
+			uploader, err := NewImageUploader(cfg, nil)
 			require.NoError(t, err)
 
 			original, ok := uploader.(*LocalUploader)
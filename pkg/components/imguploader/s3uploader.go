@@ -15,6 +15,7 @@ import (
 	"github.com/aws/aws-sdk-go/aws/defaults"
 	"github.com/aws/aws-sdk-go/aws/ec2metadata"
 	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/s3/s3manager"
 	"github.com/aws/aws-sdk-go/service/sts"
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -30,21 +31,32 @@ type S3Uploader struct {
 	secretKey       string
 	accessKey       string
 	pathStyleAccess bool
-	log             log.Logger
+	// enableSignedURLs uploads private objects and returns presigned URLs to download them,
+	// for buckets which can't be public, such as the ones of S3-compatible on-prem storages.
+	enableSignedURLs    bool
+	signedURLExpiration time.Duration
+	log                 log.Logger
 }
 
-func NewS3Uploader(endpoint, region, bucket, path, acl, accessKey, secretKey string, pathStyleAccess bool) *S3Uploader {
-	return &S3Uploader{
-		endpoint:        endpoint,
-		region:          region,
-		bucket:          bucket,
-		path:            path,
-		acl:             acl,
-		accessKey:       accessKey,
-		secretKey:       secretKey,
-		pathStyleAccess: pathStyleAccess,
-		log:             log.New("s3uploader"),
+func NewS3Uploader(endpoint, region, bucket, path, acl, accessKey, secretKey string, pathStyleAccess bool,
+	enableSignedURLs bool, signedURLExpiration time.Duration) (*S3Uploader, error) {
+	if enableSignedURLs && (signedURLExpiration <= 0 || signedURLExpiration > maxS3SignedURLExpiration) {
+		return nil, fmt.Errorf("invalid signed URL expiration: %q", signedURLExpiration)
 	}
+
+	return &S3Uploader{
+		endpoint:            endpoint,
+		region:              region,
+		bucket:              bucket,
+		path:                path,
+		acl:                 acl,
+		accessKey:           accessKey,
+		secretKey:           secretKey,
+		pathStyleAccess:     pathStyleAccess,
+		enableSignedURLs:    enableSignedURLs,
+		signedURLExpiration: signedURLExpiration,
+		log:                 log.New("s3uploader"),
+	}, nil
 }
 
 func (u *S3Uploader) Upload(ctx context.Context, imageDiskPath string) (string, error) {
@@ -94,18 +106,36 @@ func (u *S3Uploader) Upload(ctx context.Context, imageDiskPath string) (string,
 		return "", err
 	}
 
+	acl := u.acl
+	if u.enableSignedURLs {
+		acl = s3.ObjectCannedACLPrivate
+	}
+
 	uploader := s3manager.NewUploader(sess)
 	result, err := uploader.UploadWithContext(ctx, &s3manager.UploadInput{
 		Bucket:      aws.String(u.bucket),
 		Key:         aws.String(key),
-		ACL:         aws.String(u.acl),
+		ACL:         aws.String(acl),
 		Body:        file,
 		ContentType: aws.String("image/png"),
 	})
 	if err != nil {
 		return "", err
 	}
-	return result.Location, nil
+
+	if !u.enableSignedURLs {
+		return result.Location, nil
+	}
+
+	req, _ := s3.New(sess).GetObjectRequest(&s3.GetObjectInput{
+		Bucket: aws.String(u.bucket),
+		Key:    aws.String(key),
+	})
+	signedURL, err := req.Presign(u.signedURLExpiration)
+	if err != nil {
+		return "", fmt.Errorf("failed to presign the image URL: %w", err)
+	}
+	return signedURL, nil
 }
 
 func webIdentityProvider(sess client.ConfigProvider) credentials.Provider {
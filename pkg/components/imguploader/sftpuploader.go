@@ -0,0 +1,186 @@
+package imguploader
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const sftpDialTimeout = 30 * time.Second
+
+type SFTPUploader struct {
+	host                  string
+	port                  int
+	username              string
+	password              string
+	privateKeyFile        string
+	knownHostsFile        string
+	insecureIgnoreHostKey bool
+	path                  string
+	publicURL             *url.URL
+	log                   log.Logger
+}
+
+func NewSFTPUploader(host string, port int, username, password, privateKeyFile, knownHostsFile string,
+	insecureIgnoreHostKey bool, path, publicURL string) (*SFTPUploader, error) {
+	if host == "" {
+		return nil, fmt.Errorf("could not find host key for image.uploader.sftp")
+	}
+	if publicURL == "" {
+		return nil, fmt.Errorf("could not find public_url key for image.uploader.sftp")
+	}
+	if knownHostsFile == "" && !insecureIgnoreHostKey {
+		return nil, fmt.Errorf("either known_hosts_file or insecure_ignore_host_key is required for image.uploader.sftp")
+	}
+	if port == 0 {
+		port = 22
+	}
+	parsedPublicURL, err := url.Parse(publicURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public_url for image.uploader.sftp: %w", err)
+	}
+	if parsedPublicURL.Scheme == "" || parsedPublicURL.Host == "" {
+		return nil, fmt.Errorf("public_url for image.uploader.sftp should be an absolute URL")
+	}
+
+	return &SFTPUploader{
+		host:                  host,
+		port:                  port,
+		username:              username,
+		password:              password,
+		privateKeyFile:        privateKeyFile,
+		knownHostsFile:        knownHostsFile,
+		insecureIgnoreHostKey: insecureIgnoreHostKey,
+		path:                  path,
+		publicURL:             parsedPublicURL,
+		log:                   log.New("sftpuploader"),
+	}, nil
+}
+
+func (u *SFTPUploader) Upload(ctx context.Context, imageDiskPath string) (string, error) {
+	clientConfig, err := u.clientConfig()
+	if err != nil {
+		return "", err
+	}
+
+	filename, err := util.GetRandomString(20)
+	if err != nil {
+		return "", err
+	}
+	filename += pngExt
+
+	// We can ignore the gosec G304 warning on this one because `imageDiskPath` comes
+	// from alert notifiers and is only used to upload images generated by alerting.
+	// nolint:gosec
+	file, err := os.Open(imageDiskPath)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if err := file.Close(); err != nil {
+			u.log.Warn("Failed to close file", "path", imageDiskPath, "err", err)
+		}
+	}()
+
+	addr := net.JoinHostPort(u.host, strconv.Itoa(u.port))
+	dialer := net.Dialer{Timeout: sftpDialTimeout}
+	netConn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return "", err
+	}
+	sshConn, chans, reqs, err := ssh.NewClientConn(netConn, addr, clientConfig)
+	if err != nil {
+		_ = netConn.Close()
+		return "", err
+	}
+	sshClient := ssh.NewClient(sshConn, chans, reqs)
+	defer func() {
+		if err := sshClient.Close(); err != nil {
+			u.log.Warn("Failed to close SSH connection", "host", u.host, "err", err)
+		}
+	}()
+
+	client, err := sftp.NewClient(sshClient)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if err := client.Close(); err != nil {
+			u.log.Warn("Failed to close SFTP client", "host", u.host, "err", err)
+		}
+	}()
+
+	if u.path != "" {
+		if err := client.MkdirAll(u.path); err != nil {
+			return "", fmt.Errorf("failed to create directory %s: %w", u.path, err)
+		}
+	}
+
+	remotePath := path.Join(u.path, filename)
+	u.log.Debug("Uploading image to SFTP server", "host", u.host, "path", remotePath)
+	remoteFile, err := client.Create(remotePath)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(remoteFile, file); err != nil {
+		_ = remoteFile.Close()
+		return "", err
+	}
+	if err := remoteFile.Close(); err != nil {
+		return "", err
+	}
+
+	return u.PublicURL(filename), nil
+}
+
+func (u *SFTPUploader) PublicURL(filename string) string {
+	publicURL := *u.publicURL
+	publicURL.Path = path.Join(publicURL.Path, filename)
+	return publicURL.String()
+}
+
+func (u *SFTPUploader) clientConfig() (*ssh.ClientConfig, error) {
+	var auth []ssh.AuthMethod
+	if u.privateKeyFile != "" {
+		key, err := os.ReadFile(u.privateKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read private key file: %w", err)
+		}
+		signer, err := ssh.ParsePrivateKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse private key: %w", err)
+		}
+		auth = append(auth, ssh.PublicKeys(signer))
+	}
+	if u.password != "" {
+		auth = append(auth, ssh.Password(u.password))
+	}
+
+	hostKeyCallback := ssh.InsecureIgnoreHostKey() // nolint:gosec
+	if !u.insecureIgnoreHostKey {
+		var err error
+		if hostKeyCallback, err = knownhosts.New(u.knownHostsFile); err != nil {
+			return nil, fmt.Errorf("failed to read known hosts file: %w", err)
+		}
+	}
+
+	return &ssh.ClientConfig{
+		User:            u.username,
+		Auth:            auth,
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         sftpDialTimeout,
+	}, nil
+}
@@ -0,0 +1,53 @@
+package imguploader
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUploadToSFTP(t *testing.T) {
+	// Can be tested with this docker container: https://hub.docker.com/r/atmoz/sftp/
+	t.Run("[Integration test] for external_image_store.sftp", func(t *testing.T) {
+		t.Skip("Skip test [Integration test] for external_image_store.sftp")
+		sftpUploader, err := NewSFTPUploader("localhost", 2222, "foo", "pass", "", "", true, "upload", "http://publicurl:8888/images")
+		require.NoError(t, err)
+		path, err := sftpUploader.Upload(context.Background(), "../../../public/img/logo_transparent_400x.png")
+
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(path, "http://publicurl:8888/images/"))
+	})
+}
+
+func TestNewSFTPUploader(t *testing.T) {
+	t.Run("requires a host and a public URL", func(t *testing.T) {
+		_, err := NewSFTPUploader("", 22, "foo", "pass", "", "", true, "", "http://publicurl/images")
+		require.Error(t, err)
+		_, err = NewSFTPUploader("localhost", 22, "foo", "pass", "", "", true, "", "")
+		require.Error(t, err)
+	})
+
+	t.Run("requires an absolute public URL", func(t *testing.T) {
+		_, err := NewSFTPUploader("localhost", 22, "foo", "pass", "", "", true, "", "http://public url/images")
+		require.Error(t, err)
+		_, err = NewSFTPUploader("localhost", 22, "foo", "pass", "", "", true, "", "/images")
+		require.Error(t, err)
+	})
+
+	t.Run("requires a known hosts file unless host keys are ignored", func(t *testing.T) {
+		_, err := NewSFTPUploader("localhost", 22, "foo", "pass", "", "", false, "", "http://publicurl/images")
+		require.Error(t, err)
+		_, err = NewSFTPUploader("localhost", 22, "foo", "pass", "", "/home/grafana/.ssh/known_hosts", false, "", "http://publicurl/images")
+		require.NoError(t, err)
+	})
+
+	t.Run("builds the public URL of the image", func(t *testing.T) {
+		sftpUploader, err := NewSFTPUploader("localhost", 0, "foo", "pass", "", "", true, "", "http://publicurl/images?token=abc")
+		require.NoError(t, err)
+		assert.Equal(t, 22, sftpUploader.port)
+		assert.Equal(t, "http://publicurl/images/file.png?token=abc", sftpUploader.PublicURL("file.png"))
+	})
+}
@@ -47,6 +47,14 @@ type UserUpdated struct {
 	Email     string    `json:"email"`
 }
 
+type UserDeleted struct {
+	Timestamp time.Time `json:"timestamp"`
+	Id        int64     `json:"id"`
+	Name      string    `json:"name"`
+	Login     string    `json:"login"`
+	Email     string    `json:"email"`
+}
+
 type DataSourceDeleted struct {
 	Timestamp time.Time `json:"timestamp"`
 	Name      string    `json:"name"`
@@ -71,6 +79,14 @@ type DataSourceCreated struct {
 	OrgID     int64     `json:"org_id"`
 }
 
+type DataSourceUpdated struct {
+	Timestamp time.Time `json:"timestamp"`
+	Name      string    `json:"name"`
+	ID        int64     `json:"id"`
+	UID       string    `json:"uid"`
+	OrgID     int64     `json:"org_id"`
+}
+
 type FolderTitleUpdated struct {
 	Timestamp time.Time `json:"timestamp"`
 	Title     string    `json:"name"`
@@ -78,3 +94,102 @@ type FolderTitleUpdated struct {
 	UID       string    `json:"uid"`
 	OrgID     int64     `json:"org_id"`
 }
+
+type DashboardCreated struct {
+	Timestamp time.Time `json:"timestamp"`
+	Title     string    `json:"title"`
+	ID        int64     `json:"id"`
+	UID       string    `json:"uid"`
+	OrgID     int64     `json:"org_id"`
+	FolderUID string    `json:"folder_uid"`
+}
+
+type DashboardUpdated struct {
+	Timestamp time.Time `json:"timestamp"`
+	Title     string    `json:"title"`
+	ID        int64     `json:"id"`
+	UID       string    `json:"uid"`
+	OrgID     int64     `json:"org_id"`
+	FolderUID string    `json:"folder_uid"`
+	Version   int       `json:"version"`
+}
+
+type DashboardDeleted struct {
+	Timestamp time.Time `json:"timestamp"`
+	Title     string    `json:"title"`
+	ID        int64     `json:"id"`
+	UID       string    `json:"uid"`
+	OrgID     int64     `json:"org_id"`
+	FolderUID string    `json:"folder_uid"`
+}
+
+type FolderCreated struct {
+	Timestamp time.Time `json:"timestamp"`
+	Title     string    `json:"title"`
+	ID        int64     `json:"id"`
+	UID       string    `json:"uid"`
+	OrgID     int64     `json:"org_id"`
+}
+
+type FolderUpdated struct {
+	Timestamp time.Time `json:"timestamp"`
+	Title     string    `json:"title"`
+	ID        int64     `json:"id"`
+	UID       string    `json:"uid"`
+	OrgID     int64     `json:"org_id"`
+}
+
+type FolderDeleted struct {
+	Timestamp time.Time `json:"timestamp"`
+	Title     string    `json:"title"`
+	ID        int64     `json:"id"`
+	UID       string    `json:"uid"`
+	OrgID     int64     `json:"org_id"`
+}
+
+type AlertRuleCreated struct {
+	Timestamp    time.Time `json:"timestamp"`
+	Title        string    `json:"title"`
+	UID          string    `json:"uid"`
+	OrgID        int64     `json:"org_id"`
+	NamespaceUID string    `json:"namespace_uid"`
+	RuleGroup    string    `json:"rule_group"`
+}
+
+type AlertRuleUpdated struct {
+	Timestamp    time.Time `json:"timestamp"`
+	Title        string    `json:"title"`
+	UID          string    `json:"uid"`
+	OrgID        int64     `json:"org_id"`
+	NamespaceUID string    `json:"namespace_uid"`
+	RuleGroup    string    `json:"rule_group"`
+}
+
+type AlertRuleDeleted struct {
+	Timestamp time.Time `json:"timestamp"`
+	UID       string    `json:"uid"`
+	OrgID     int64     `json:"org_id"`
+}
+
+type TeamCreated struct {
+	Timestamp time.Time `json:"timestamp"`
+	Name      string    `json:"name"`
+	ID        int64     `json:"id"`
+	UID       string    `json:"uid"`
+	OrgID     int64     `json:"org_id"`
+}
+
+type TeamUpdated struct {
+	Timestamp time.Time `json:"timestamp"`
+	Name      string    `json:"name"`
+	ID        int64     `json:"id"`
+	OrgID     int64     `json:"org_id"`
+}
+
+type TeamDeleted struct {
+	Timestamp time.Time `json:"timestamp"`
+	Name      string    `json:"name"`
+	ID        int64     `json:"id"`
+	UID       string    `json:"uid"`
+	OrgID     int64     `json:"org_id"`
+}
@@ -34,7 +34,12 @@ func NewClient(d doer, method, baseUrl string) *Client {
 }
 
 func (c *Client) QueryRange(ctx context.Context, q *models.Query) (*http.Response, error) {
-	tr := q.TimeRange()
+	return c.QueryRangeWithTimeRange(ctx, q, q.TimeRange())
+}
+
+// QueryRangeWithTimeRange runs the range query over a time range that is already aligned to the step, such as a
+// sub-range of the time range of the query.
+func (c *Client) QueryRangeWithTimeRange(ctx context.Context, q *models.Query, tr models.TimeRange) (*http.Response, error) {
 	qv := map[string]string{
 		"query": q.Expr,
 		"start": formatTime(tr.Start),
@@ -0,0 +1,259 @@
+package querydata
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/grafana/grafana-plugin-sdk-go/data/utils/maputil"
+	"github.com/patrickmn/go-cache"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql/parser"
+
+	"github.com/grafana/grafana/pkg/promlib/client"
+	"github.com/grafana/grafana/pkg/promlib/models"
+)
+
+const (
+	// seriesCountCacheTTL is how long the number of the series of a selector is cached.
+	seriesCountCacheTTL = 5 * time.Minute
+	// anyLabelValue is a label value that only the regular expressions that match any value match.
+	anyLabelValue = "\x00grafana\x00"
+)
+
+// costGuard rejects the queries that exceed the limits of the data source before they are sent to Prometheus.
+type costGuard struct {
+	minStep                        time.Duration
+	maxLookback                    time.Duration
+	maxPoints                      int64
+	rejectSelectorsWithoutMatchers bool
+	seriesCounts                   *cache.Cache
+}
+
+// newCostGuard returns the cost guard of the limits of the queryCostGuard object of the settings, or nil when it is
+// not set.
+func newCostGuard(jsonData map[string]any) (*costGuard, error) {
+	limits, err := maputil.GetMapOptional(jsonData, "queryCostGuard")
+	if err != nil {
+		return nil, err
+	}
+	if limits == nil {
+		return nil, nil
+	}
+
+	g := &costGuard{
+		seriesCounts: cache.New(seriesCountCacheTTL, seriesCountCacheTTL),
+	}
+	if g.minStep, err = getDurationOptional(limits, "minStep"); err != nil {
+		return nil, err
+	}
+	if g.maxLookback, err = getDurationOptional(limits, "maxLookback"); err != nil {
+		return nil, err
+	}
+	if g.rejectSelectorsWithoutMatchers, err = maputil.GetBoolOptional(limits, "rejectSelectorsWithoutMatchers"); err != nil {
+		return nil, err
+	}
+	if v, ok := limits["maxPoints"]; ok {
+		maxPoints, ok := v.(float64)
+		if !ok {
+			return nil, fmt.Errorf("field 'maxPoints' should be a number")
+		}
+		g.maxPoints = int64(maxPoints)
+	}
+	return g, nil
+}
+
+func getDurationOptional(obj map[string]any, key string) (time.Duration, error) {
+	s, err := maputil.GetStringOptional(obj, key)
+	if err != nil || s == "" {
+		return 0, err
+	}
+	d, err := gtime.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration of field '%s': %w", key, err)
+	}
+	return d, nil
+}
+
+// check returns the error response of the query when it exceeds a limit, or nil. Queries that can not be parsed are
+// not checked, so that Prometheus reports the error.
+func (g *costGuard) check(ctx context.Context, c *client.Client, q *models.Query, headers http.Header, logger log.Logger) *backend.DataResponse {
+	expr, err := parser.ParseExpr(q.Expr)
+	if err != nil {
+		return nil
+	}
+
+	tr := q.TimeRange()
+	if q.RangeQuery && g.minStep > 0 && tr.Step < g.minStep {
+		return costGuardError("the step %s is shorter than the minimum step %s", model.Duration(tr.Step), model.Duration(g.minStep))
+	}
+
+	if g.maxLookback > 0 {
+		lookback := exprLookback(expr)
+		if q.RangeQuery {
+			lookback += tr.End.Sub(tr.Start)
+		}
+		if lookback > g.maxLookback {
+			return costGuardError("the query looks back %s, more than the maximum lookback %s", model.Duration(lookback), model.Duration(g.maxLookback))
+		}
+	}
+
+	selectors := parser.ExtractSelectors(expr)
+	if g.rejectSelectorsWithoutMatchers {
+		for _, matchers := range selectors {
+			if !anySelectsSeries(matchers) {
+				return costGuardError("the selector %s does not have a matcher that selects series", selectorString(matchers))
+			}
+		}
+	}
+
+	if g.maxPoints > 0 {
+		// counting the series of these selectors would be as expensive as running the query
+		for _, matchers := range selectors {
+			if !anySelectsSeries(matchers) {
+				return costGuardError("the series of the selector %s can not be counted, as it does not have a matcher that selects series", selectorString(matchers))
+			}
+		}
+
+		var series int64
+		for _, matchers := range selectors {
+			count, err := g.seriesCount(ctx, c, matchers, headers, q.End)
+			if err != nil {
+				// the query is not rejected when its cost can not be estimated
+				logger.Warn("Failed to count the series of the query", "query", q.Expr, "err", err)
+				return nil
+			}
+			series += count
+		}
+		steps := int64(1)
+		if q.RangeQuery && tr.Step > 0 {
+			steps = int64(tr.End.Sub(tr.Start)/tr.Step) + 1
+		}
+		if points := series * steps; points > g.maxPoints {
+			return costGuardError("the query would return about %d points of %d series, more than the maximum %d points", points, series, g.maxPoints)
+		}
+	}
+
+	return nil
+}
+
+func costGuardError(format string, args ...any) *backend.DataResponse {
+	return &backend.DataResponse{
+		Error:  fmt.Errorf("query exceeds the limits of the data source: "+format, args...),
+		Status: backend.StatusBadRequest,
+	}
+}
+
+// exprLookback returns how far before the evaluation time the expression reads samples, from its range selectors,
+// subqueries and offsets.
+func exprLookback(node parser.Node) time.Duration {
+	switch n := node.(type) {
+	case *parser.VectorSelector:
+		return n.OriginalOffset
+	case *parser.MatrixSelector:
+		return n.Range + exprLookback(n.VectorSelector)
+	case *parser.SubqueryExpr:
+		return n.Range + n.OriginalOffset + exprLookback(n.Expr)
+	}
+
+	var lookback time.Duration
+	for _, child := range parser.Children(node) {
+		if l := exprLookback(child); l > lookback {
+			lookback = l
+		}
+	}
+	return lookback
+}
+
+// anySelectsSeries returns true when one of the matchers restricts the series to the ones with some label values,
+// unlike negative matchers and regular expressions that match any value, such as {__name__=~".+"}.
+func anySelectsSeries(matchers []*labels.Matcher) bool {
+	for _, m := range matchers {
+		switch m.Type {
+		case labels.MatchEqual:
+			if m.Value != "" {
+				return true
+			}
+		case labels.MatchRegexp:
+			if !m.Matches("") && !m.Matches(anyLabelValue) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func selectorString(matchers []*labels.Matcher) string {
+	s := make([]string, 0, len(matchers))
+	for _, m := range matchers {
+		s = append(s, m.String())
+	}
+	return "{" + strings.Join(s, ", ") + "}"
+}
+
+// seriesCount returns the number of the series of the selector at the time, which is cached.
+func (g *costGuard) seriesCount(ctx context.Context, c *client.Client, matchers []*labels.Matcher, headers http.Header, t time.Time) (int64, error) {
+	selector := selectorString(matchers)
+	key := seriesCountKey(selector, headers)
+	if count, ok := g.seriesCounts.Get(key); ok {
+		return count.(int64), nil
+	}
+
+	res, err := c.QueryInstant(ctx, &models.Query{Expr: "count(" + selector + ")", End: t})
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+	if res.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("series count query failed: %s", res.Status)
+	}
+
+	var body struct {
+		Data struct {
+			Result []struct {
+				Value []any `json:"value"`
+			} `json:"result"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return 0, err
+	}
+
+	var count int64
+	if len(body.Data.Result) > 0 && len(body.Data.Result[0].Value) == 2 {
+		v, _ := body.Data.Result[0].Value[1].(string)
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return 0, err
+		}
+		count = int64(f)
+	}
+	g.seriesCounts.SetDefault(key, count)
+	return count, nil
+}
+
+// seriesCountKey returns the cache key of the number of the series of a selector. The headers of the request are
+// part of it for the same reason as they are part of the key of the incremental query cache: users can be allowed to
+// query different series.
+func seriesCountKey(selector string, headers http.Header) string {
+	h := sha256.New()
+	write := func(v string) {
+		_, _ = h.Write([]byte(v))
+		_, _ = h.Write([]byte{0})
+	}
+	write(selector)
+	writeHeaders(write, headers)
+	return hex.EncodeToString(h.Sum(nil))
+}
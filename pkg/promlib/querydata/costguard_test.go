@@ -0,0 +1,144 @@
+package querydata_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/promlib/models"
+	"github.com/grafana/grafana/pkg/promlib/querydata"
+)
+
+func TestQueryCostGuard(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// the server returns 100 series for the series count queries, and no samples for the other queries
+	var queries []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		queries = append(queries, r.URL.Path+" "+r.Form.Get("query"))
+		if r.URL.Path == "/api/v1/query" {
+			_, _ = fmt.Fprintf(w, `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[%d,"100"]}]}}`, now.Unix())
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"matrix","result":[]}}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	newQueryData := func(t *testing.T, limits string) *querydata.QueryData {
+		t.Helper()
+		qd, err := querydata.New(srv.Client(), backend.DataSourceInstanceSettings{
+			URL:      srv.URL,
+			JSONData: []byte(`{"queryCostGuard": ` + limits + `}`),
+		}, log.New())
+		require.NoError(t, err)
+		return qd
+	}
+
+	execute := func(t *testing.T, qd *querydata.QueryData, expr string, interval string, from time.Time, headers map[string]string) backend.DataResponse {
+		t.Helper()
+		b, err := json.Marshal(&models.QueryModel{
+			PrometheusQueryProperties: models.PrometheusQueryProperties{Expr: expr, Range: true},
+			Interval:                  interval,
+		})
+		require.NoError(t, err)
+		res, err := qd.Execute(context.Background(), &backend.QueryDataRequest{
+			Headers: headers,
+			Queries: []backend.DataQuery{{
+				RefID:         "A",
+				JSON:          b,
+				MaxDataPoints: 100000,
+				TimeRange:     backend.TimeRange{From: from, To: now},
+			}},
+		})
+		require.NoError(t, err)
+		return res.Responses["A"]
+	}
+
+	run := func(t *testing.T, limits string, expr string, interval string, from time.Time) backend.DataResponse {
+		t.Helper()
+		queries = nil
+		return execute(t, newQueryData(t, limits), expr, interval, from, nil)
+	}
+
+	t.Run("rejects steps shorter than the minimum step", func(t *testing.T) {
+		res := run(t, `{"minStep": "1m"}`, "up", "15s", now.Add(-time.Hour))
+		require.EqualError(t, res.Error, "query exceeds the limits of the data source: the step 15s is shorter than the minimum step 1m")
+		require.Equal(t, backend.StatusBadRequest, res.Status)
+		require.Empty(t, queries)
+
+		res = run(t, `{"minStep": "1m"}`, "up", "1m", now.Add(-time.Hour))
+		require.NoError(t, res.Error)
+	})
+
+	t.Run("rejects queries that look back more than the maximum lookback", func(t *testing.T) {
+		res := run(t, `{"maxLookback": "30d"}`, "rate(up[1d])", "1h", now.Add(-30*24*time.Hour))
+		require.EqualError(t, res.Error, "query exceeds the limits of the data source: the query looks back 31d, more than the maximum lookback 30d")
+
+		res = run(t, `{"maxLookback": "30d"}`, "max_over_time(rate(up[5m])[2d:1m] offset 1d)", "1h", now.Add(-28*24*time.Hour))
+		require.EqualError(t, res.Error, "query exceeds the limits of the data source: the query looks back 31d5m, more than the maximum lookback 30d")
+
+		res = run(t, `{"maxLookback": "30d"}`, "rate(up[1d])", "1h", now.Add(-7*24*time.Hour))
+		require.NoError(t, res.Error)
+	})
+
+	t.Run("rejects selectors without matchers that select series", func(t *testing.T) {
+		for _, expr := range []string{`{__name__=~".+"}`, `sum(rate({job!="a", instance=~".*", __name__=~".+"}[5m]))`} {
+			res := run(t, `{"rejectSelectorsWithoutMatchers": true}`, expr, "1m", now.Add(-time.Hour))
+			require.ErrorContains(t, res.Error, "does not have a matcher that selects series")
+		}
+
+		for _, expr := range []string{`up`, `{job=~"a|b"}`, `{__name__=~"node_.+"}`, `up / on(job) {job="a"}`} {
+			res := run(t, `{"rejectSelectorsWithoutMatchers": true}`, expr, "1m", now.Add(-time.Hour))
+			require.NoError(t, res.Error, expr)
+		}
+	})
+
+	t.Run("rejects queries that would return more than the maximum points", func(t *testing.T) {
+		res := run(t, `{"maxPoints": 5000}`, `up`, "1m", now.Add(-time.Hour))
+		require.EqualError(t, res.Error, "query exceeds the limits of the data source: the query would return about 6100 points of 100 series, more than the maximum 5000 points")
+		require.Equal(t, []string{`/api/v1/query count({__name__="up"})`}, queries)
+
+		res = run(t, `{"maxPoints": 5000}`, `up`, "1m", now.Add(-30*time.Minute))
+		require.NoError(t, res.Error)
+	})
+
+	t.Run("caches the number of series of each user", func(t *testing.T) {
+		queries = nil
+		qd := newQueryData(t, `{"maxPoints": 5000}`)
+		for _, user := range []string{"alice", "bob", "alice"} {
+			res := execute(t, qd, `up`, "1m", now.Add(-30*time.Minute), map[string]string{"http_X-Grafana-User": user})
+			require.NoError(t, res.Error)
+		}
+		require.Equal(t, []string{
+			`/api/v1/query count({__name__="up"})`,
+			`/api/v1/query_range up`,
+			`/api/v1/query count({__name__="up"})`,
+			`/api/v1/query_range up`,
+			`/api/v1/query_range up`,
+		}, queries)
+	})
+
+	t.Run("rejects selectors whose series can not be counted with the maximum points", func(t *testing.T) {
+		res := run(t, `{"maxPoints": 5000}`, `up / {__name__=~".+"}`, "1m", now.Add(-30*time.Minute))
+		require.EqualError(t, res.Error, `query exceeds the limits of the data source: the series of the selector {__name__=~".+"} can not be counted, as it does not have a matcher that selects series`)
+		require.Empty(t, queries)
+	})
+
+	t.Run("fails with invalid limits", func(t *testing.T) {
+		for _, limits := range []string{`{"minStep": "soon"}`, `{"maxPoints": "many"}`, `{"rejectSelectorsWithoutMatchers": "yes"}`} {
+			_, err := querydata.New(srv.Client(), backend.DataSourceInstanceSettings{
+				JSONData: []byte(`{"queryCostGuard": ` + limits + `}`),
+			}, log.New())
+			require.Error(t, err, limits)
+		}
+	})
+}
@@ -0,0 +1,205 @@
+package querydata
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana-plugin-sdk-go/data/utils/maputil"
+	"github.com/patrickmn/go-cache"
+
+	"github.com/grafana/grafana/pkg/promlib/client"
+	"github.com/grafana/grafana/pkg/promlib/models"
+)
+
+const (
+	// defaultIncrementalQueryOverlapWindow is the most recent part of the cached samples that is fetched again, as
+	// these samples can still change. It is the same default as the one of the incremental querying of the frontend.
+	defaultIncrementalQueryOverlapWindow = 10 * time.Minute
+	// incrementalCacheTTL is how long the samples of a query are cached after it last ran.
+	incrementalCacheTTL = 10 * time.Minute
+	// maxIncrementalCacheEntries is the maximum number of the cached queries of a data source. The oldest query is
+	// removed to cache a new one when the cache is full.
+	maxIncrementalCacheEntries = 1000
+)
+
+// incrementalCache caches the samples of the range queries, so that running a query again over a time range that
+// moved forward, such as when a dashboard refreshes, only fetches the samples after the cached ones.
+type incrementalCache struct {
+	cache   *cache.Cache
+	overlap time.Duration
+	// mu serializes the eviction of the oldest query with the caching of a new one
+	mu         sync.Mutex
+	maxEntries int
+}
+
+// cachedRange is the samples of the series of a range query, over its aligned time range. The frames of the queries
+// which can not be merged are not cached, and such queries always fetch their whole time range.
+type cachedRange struct {
+	start       time.Time
+	end         time.Time
+	frames      data.Frames
+	unmergeable bool
+}
+
+// newIncrementalCache returns the cache of the range queries, or nil when incremental querying is disabled.
+func newIncrementalCache(jsonData map[string]any) (*incrementalCache, error) {
+	enabled, err := maputil.GetBoolOptional(jsonData, "serverSideIncrementalQuerying")
+	if err != nil {
+		return nil, err
+	}
+	if !enabled {
+		return nil, nil
+	}
+
+	overlapWindow, err := maputil.GetStringOptional(jsonData, "incrementalQueryOverlapWindow")
+	if err != nil {
+		return nil, err
+	}
+	overlap := defaultIncrementalQueryOverlapWindow
+	if overlapWindow != "" {
+		overlap, err = gtime.ParseDuration(overlapWindow)
+		if err != nil {
+			return nil, fmt.Errorf("invalid incremental query overlap window: %w", err)
+		}
+	}
+
+	return &incrementalCache{
+		cache:      cache.New(incrementalCacheTTL, incrementalCacheTTL),
+		overlap:    overlap,
+		maxEntries: maxIncrementalCacheEntries,
+	}, nil
+}
+
+// set caches the range of a query. When the cache is full, the query cached first is removed, as all the queries
+// are cached with the same TTL and expire in the order they were cached.
+func (c *incrementalCache) set(key string, cached cachedRange) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.cache.Get(key); !ok && c.cache.ItemCount() >= c.maxEntries {
+		c.cache.DeleteExpired()
+		if c.cache.ItemCount() >= c.maxEntries {
+			oldestKey, oldest := "", int64(0)
+			for k, item := range c.cache.Items() {
+				if oldestKey == "" || item.Expiration < oldest {
+					oldestKey, oldest = k, item.Expiration
+				}
+			}
+			c.cache.Delete(oldestKey)
+		}
+	}
+	c.cache.SetDefault(key, cached)
+}
+
+// fetchStart returns the start of the samples to fetch to complete the cached ones over the time range, which is the
+// end of the cached samples minus the overlap window aligned to the step. It returns false when the cached samples
+// do not cover the start of the time range, or when the time range ends before them.
+func (c *incrementalCache) fetchStart(cached cachedRange, tr models.TimeRange) (time.Time, bool) {
+	if tr.Start.Before(cached.start) || tr.End.Before(cached.end) || tr.Step <= 0 {
+		return time.Time{}, false
+	}
+	steps := cached.end.Add(-c.overlap).Sub(tr.Start) / tr.Step
+	if steps <= 0 {
+		return time.Time{}, false
+	}
+	return tr.Start.Add(steps * tr.Step), true
+}
+
+// incrementalQueryKey returns the fingerprint of a range query, without its time range. All the headers of the
+// request are part of it, as they can identify the user, such as the forwarded OAuth tokens, cookies or the
+// X-Grafana-User header, and users can then be allowed to query different series.
+func incrementalQueryKey(q *models.Query, headers http.Header, enablePrometheusDataplaneFlag bool) string {
+	h := sha256.New()
+	write := func(v string) {
+		_, _ = h.Write([]byte(v))
+		_, _ = h.Write([]byte{0})
+	}
+	for _, v := range []string{
+		q.Expr,
+		q.Step.String(),
+		strconv.FormatInt(q.UtcOffsetSec, 10),
+		q.LegendFormat,
+		strconv.FormatBool(enablePrometheusDataplaneFlag),
+	} {
+		write(v)
+	}
+	writeHeaders(write, headers)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// writeHeaders writes the names and the values of the headers in the order of their names.
+func writeHeaders(write func(string), headers http.Header) {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, http.CanonicalHeaderKey(name))
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		write(name)
+		for _, v := range headers.Values(name) {
+			write(v)
+		}
+	}
+}
+
+// incrementalRangeQuery runs a range query. When incremental querying is enabled, it only fetches the samples after
+// the cached ones of the query, and merges them.
+func (s *QueryData) incrementalRangeQuery(ctx context.Context, c *client.Client, q *models.Query, headers http.Header, enablePrometheusDataplaneFlag bool) backend.DataResponse {
+	tr := q.TimeRange()
+	if s.incremental == nil {
+		return s.splitRangeQuery(ctx, c, q, tr, enablePrometheusDataplaneFlag)
+	}
+
+	key := incrementalQueryKey(q, headers, enablePrometheusDataplaneFlag)
+	fetch := tr
+	var parts []rangePart
+	if v, ok := s.incremental.cache.Get(key); ok {
+		cached := v.(cachedRange)
+		if cached.unmergeable {
+			// the query is neither sent for the tail of the range nor for the sub-ranges before the whole range, until
+			// it is no longer remembered
+			return s.rangeQuery(ctx, c, q, tr, enablePrometheusDataplaneFlag)
+		}
+		if start, ok := s.incremental.fetchStart(cached, tr); ok {
+			fetch.Start = start
+			parts = append(parts, rangePart{start: cached.start, frames: cached.frames})
+		}
+	}
+
+	res := s.splitRangeQuery(ctx, c, q, fetch, enablePrometheusDataplaneFlag)
+	if res.Error != nil {
+		return res
+	}
+
+	parts = append(parts, rangePart{start: fetch.Start, frames: res.Frames})
+	frames, ok := mergeRangeParts(parts, tr)
+	if !ok {
+		// the frames of the query can not be merged, such as the ones of native histograms, so the later runs of the
+		// query fetch the whole range at once. The frames fetched over the whole range are the result, and they are
+		// only fetched again when the query used to have frames that could be merged.
+		s.incremental.set(key, cachedRange{unmergeable: true})
+		if fetch.Start.Equal(tr.Start) {
+			return res
+		}
+		return s.rangeQuery(ctx, c, q, tr, enablePrometheusDataplaneFlag)
+	}
+	logger := s.log.FromContext(ctx)
+	logger.Debug("Merged incremental range query", "query", q.Expr, "cachedParts", len(parts)-1, "fetchStart", fetch.Start)
+
+	// the merged frames are copied, as the ones of the response can be changed by the caller
+	cached, _ := mergeRangeParts([]rangePart{{start: tr.Start, frames: frames}}, tr)
+	s.incremental.set(key, cachedRange{start: tr.Start, end: tr.End, frames: cached})
+
+	res.Frames = finishRangeFrames(q, frames, res.Frames)
+	return res
+}
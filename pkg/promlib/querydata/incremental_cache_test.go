@@ -0,0 +1,27 @@
+package querydata
+
+import (
+	"testing"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIncrementalCacheSet(t *testing.T) {
+	c := &incrementalCache{cache: cache.New(incrementalCacheTTL, incrementalCacheTTL), maxEntries: 2}
+
+	for _, key := range []string{"a", "b", "a", "c"} {
+		c.set(key, cachedRange{})
+		// the expirations of the keys are different
+		time.Sleep(time.Millisecond)
+	}
+
+	require.Equal(t, 2, c.cache.ItemCount())
+	_, ok := c.cache.Get("a")
+	require.True(t, ok, "a is cached again after b")
+	_, ok = c.cache.Get("b")
+	require.False(t, ok, "b is the oldest query")
+	_, ok = c.cache.Get("c")
+	require.True(t, ok)
+}
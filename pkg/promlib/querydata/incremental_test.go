@@ -0,0 +1,235 @@
+package querydata_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/promlib/models"
+	"github.com/grafana/grafana/pkg/promlib/querydata"
+)
+
+type rangeRequest struct {
+	start, end time.Time
+}
+
+// fakeRangeServer is a Prometheus that returns the samples of a series of each job, whose values are their timestamps
+// in seconds. The series of the jobs after the end are not returned.
+type fakeRangeServer struct {
+	*httptest.Server
+	jobs     map[string]time.Time
+	mu       sync.Mutex
+	requests []rangeRequest
+}
+
+func newFakeRangeServer(t *testing.T, jobs map[string]time.Time) *fakeRangeServer {
+	s := &fakeRangeServer{jobs: jobs}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		start, _ := strconv.ParseFloat(r.Form.Get("start"), 64)
+		end, _ := strconv.ParseFloat(r.Form.Get("end"), 64)
+		step, _ := strconv.ParseFloat(r.Form.Get("step"), 64)
+
+		s.mu.Lock()
+		s.requests = append(s.requests, rangeRequest{start: time.Unix(int64(start), 0).UTC(), end: time.Unix(int64(end), 0).UTC()})
+		s.mu.Unlock()
+
+		var result []string
+		for job, until := range s.jobs {
+			var values []string
+			for ts := start; ts <= end && ts <= float64(until.Unix()); ts += step {
+				values = append(values, fmt.Sprintf(`[%g,"%g"]`, ts, ts))
+			}
+			if len(values) > 0 {
+				result = append(result, fmt.Sprintf(`{"metric":{"job":%q},"values":[%s]}`, job, strings.Join(values, ",")))
+			}
+		}
+		_, _ = fmt.Fprintf(w, `{"status":"success","data":{"resultType":"matrix","result":[%s]}}`, strings.Join(result, ","))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *fakeRangeServer) takeRequests() []rangeRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	requests := s.requests
+	s.requests = nil
+	return requests
+}
+
+func newRangeQueryData(t *testing.T, s *fakeRangeServer, jsonData string) *querydata.QueryData {
+	qd, err := querydata.New(s.Client(), backend.DataSourceInstanceSettings{URL: s.URL, JSONData: []byte(jsonData)}, log.New())
+	require.NoError(t, err)
+	return qd
+}
+
+func runRangeQuery(t *testing.T, qd *querydata.QueryData, from, to time.Time) data.Frames {
+	return runRangeQueryWithHeaders(t, qd, from, to, nil)
+}
+
+func runRangeQueryWithHeaders(t *testing.T, qd *querydata.QueryData, from, to time.Time, headers map[string]string) data.Frames {
+	qm := models.QueryModel{
+		PrometheusQueryProperties: models.PrometheusQueryProperties{
+			Expr:  "up",
+			Range: true,
+		},
+		Interval: "1m",
+	}
+	b, err := json.Marshal(&qm)
+	require.NoError(t, err)
+
+	res, err := qd.Execute(context.Background(), &backend.QueryDataRequest{
+		Headers: headers,
+		Queries: []backend.DataQuery{{
+			RefID:         "A",
+			JSON:          b,
+			MaxDataPoints: 10000,
+			TimeRange:     backend.TimeRange{From: from, To: to},
+		}},
+	})
+	require.NoError(t, err)
+	require.NoError(t, res.Responses["A"].Error)
+	return res.Responses["A"].Frames
+}
+
+// seriesSamples returns the timestamps of the samples of each job.
+func seriesSamples(t *testing.T, frames data.Frames) map[string][]time.Time {
+	samples := map[string][]time.Time{}
+	for _, frame := range frames {
+		if len(frame.Fields) == 0 {
+			continue
+		}
+		job := frame.Fields[1].Labels["job"]
+		for i := 0; i < frame.Rows(); i++ {
+			ts := frame.Fields[0].At(i).(time.Time)
+			require.Equal(t, float64(ts.Unix()), frame.Fields[1].At(i))
+			samples[job] = append(samples[job], ts)
+		}
+	}
+	return samples
+}
+
+func expectedSamples(from, to time.Time) []time.Time {
+	var samples []time.Time
+	for ts := from; !ts.After(to); ts = ts.Add(time.Minute) {
+		samples = append(samples, ts)
+	}
+	return samples
+}
+
+func TestIncrementalRangeQuery(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("fetches only the tail of the range and the overlap window", func(t *testing.T) {
+		s := newFakeRangeServer(t, map[string]time.Time{"a": now.Add(time.Hour)})
+		qd := newRangeQueryData(t, s, `{"serverSideIncrementalQuerying": true, "incrementalQueryOverlapWindow": "5m"}`)
+
+		frames := runRangeQuery(t, qd, now.Add(-time.Hour), now)
+		require.Equal(t, []rangeRequest{{start: now.Add(-time.Hour), end: now}}, s.takeRequests())
+		require.Equal(t, expectedSamples(now.Add(-time.Hour), now), seriesSamples(t, frames)["a"])
+
+		later := now.Add(2 * time.Minute)
+		frames = runRangeQuery(t, qd, later.Add(-time.Hour), later)
+		require.Equal(t, []rangeRequest{{start: now.Add(-5 * time.Minute), end: later}}, s.takeRequests())
+		require.Equal(t, expectedSamples(later.Add(-time.Hour), later), seriesSamples(t, frames)["a"])
+		require.Equal(t, "Expr: up\nStep: 1m0s", frames[0].Meta.ExecutedQueryString)
+	})
+
+	t.Run("keeps the cached samples of the series that ended", func(t *testing.T) {
+		s := newFakeRangeServer(t, map[string]time.Time{"a": now.Add(time.Hour), "b": now.Add(-30 * time.Minute)})
+		qd := newRangeQueryData(t, s, `{"serverSideIncrementalQuerying": true}`)
+
+		runRangeQuery(t, qd, now.Add(-time.Hour), now)
+		s.takeRequests()
+
+		later := now.Add(time.Minute)
+		samples := seriesSamples(t, runRangeQuery(t, qd, later.Add(-time.Hour), later))
+		require.Equal(t, []rangeRequest{{start: now.Add(-10 * time.Minute), end: later}}, s.takeRequests())
+		require.Equal(t, expectedSamples(later.Add(-time.Hour), later), samples["a"])
+		require.Equal(t, expectedSamples(later.Add(-time.Hour), now.Add(-30*time.Minute)), samples["b"])
+	})
+
+	t.Run("fetches the whole range when it is not covered by the cache", func(t *testing.T) {
+		s := newFakeRangeServer(t, map[string]time.Time{"a": now.Add(time.Hour)})
+		qd := newRangeQueryData(t, s, `{"serverSideIncrementalQuerying": true}`)
+
+		runRangeQuery(t, qd, now.Add(-time.Hour), now)
+		s.takeRequests()
+
+		runRangeQuery(t, qd, now.Add(-2*time.Hour), now)
+		require.Equal(t, []rangeRequest{{start: now.Add(-2 * time.Hour), end: now}}, s.takeRequests())
+
+		runRangeQuery(t, qd, now.Add(-3*time.Hour), now.Add(-2*time.Hour))
+		require.Equal(t, []rangeRequest{{start: now.Add(-3 * time.Hour), end: now.Add(-2 * time.Hour)}}, s.takeRequests())
+	})
+
+	t.Run("does not share the cached samples between users", func(t *testing.T) {
+		s := newFakeRangeServer(t, map[string]time.Time{"a": now.Add(time.Hour)})
+		qd := newRangeQueryData(t, s, `{"serverSideIncrementalQuerying": true}`)
+
+		runRangeQueryWithHeaders(t, qd, now.Add(-time.Hour), now, map[string]string{"http_X-Grafana-User": "alice"})
+		s.takeRequests()
+
+		runRangeQueryWithHeaders(t, qd, now.Add(-time.Hour), now, map[string]string{"http_X-Grafana-User": "bob"})
+		require.Equal(t, []rangeRequest{{start: now.Add(-time.Hour), end: now}}, s.takeRequests())
+
+		runRangeQueryWithHeaders(t, qd, now.Add(-time.Hour), now, map[string]string{"http_X-Grafana-User": "alice"})
+		require.Equal(t, []rangeRequest{{start: now.Add(-10 * time.Minute), end: now}}, s.takeRequests())
+	})
+
+	t.Run("does not cache when disabled", func(t *testing.T) {
+		s := newFakeRangeServer(t, map[string]time.Time{"a": now.Add(time.Hour)})
+		qd := newRangeQueryData(t, s, `{}`)
+
+		runRangeQuery(t, qd, now.Add(-time.Hour), now)
+		runRangeQuery(t, qd, now.Add(-time.Hour), now)
+		require.Len(t, s.takeRequests(), 2)
+	})
+
+	t.Run("fails with an invalid overlap window", func(t *testing.T) {
+		_, err := querydata.New(http.DefaultClient, backend.DataSourceInstanceSettings{
+			JSONData: []byte(`{"serverSideIncrementalQuerying": true, "incrementalQueryOverlapWindow": "soon"}`),
+		}, log.New())
+		require.Error(t, err)
+	})
+}
+
+func TestSplitRangeQuery(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("splits long ranges into sub-ranges", func(t *testing.T) {
+		s := newFakeRangeServer(t, map[string]time.Time{"a": now, "b": now.Add(-150 * time.Minute)})
+		qd := newRangeQueryData(t, s, `{"rangeQuerySplitInterval": "1h"}`)
+
+		samples := seriesSamples(t, runRangeQuery(t, qd, now.Add(-210*time.Minute), now))
+		require.ElementsMatch(t, []rangeRequest{
+			{start: now.Add(-210 * time.Minute), end: now.Add(-151 * time.Minute)},
+			{start: now.Add(-150 * time.Minute), end: now.Add(-91 * time.Minute)},
+			{start: now.Add(-90 * time.Minute), end: now.Add(-31 * time.Minute)},
+			{start: now.Add(-30 * time.Minute), end: now},
+		}, s.takeRequests())
+		require.Equal(t, expectedSamples(now.Add(-210*time.Minute), now), samples["a"])
+		require.Equal(t, expectedSamples(now.Add(-210*time.Minute), now.Add(-150*time.Minute)), samples["b"])
+	})
+
+	t.Run("does not split short ranges", func(t *testing.T) {
+		s := newFakeRangeServer(t, map[string]time.Time{"a": now})
+		qd := newRangeQueryData(t, s, `{"rangeQuerySplitInterval": "1h"}`)
+
+		runRangeQuery(t, qd, now.Add(-time.Hour), now)
+		require.Len(t, s.takeRequests(), 1)
+	})
+}
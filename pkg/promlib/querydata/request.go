@@ -44,6 +44,10 @@ type QueryData struct {
 	URL                string
 	TimeInterval       string
 	exemplarSampler    func() exemplar.Sampler
+
+	incremental             *incrementalCache
+	rangeQuerySplitInterval time.Duration
+	costGuard               *costGuard
 }
 
 func New(
@@ -66,6 +70,21 @@ func New(
 		httpMethod = http.MethodPost
 	}
 
+	incremental, err := newIncrementalCache(jsonData)
+	if err != nil {
+		return nil, err
+	}
+
+	rangeQuerySplitInterval, err := getRangeQuerySplitInterval(jsonData)
+	if err != nil {
+		return nil, err
+	}
+
+	costGuard, err := newCostGuard(jsonData)
+	if err != nil {
+		return nil, err
+	}
+
 	promClient := client.NewClient(httpClient, httpMethod, settings.URL)
 
 	// standard deviation sampler is the default for backwards compatibility
@@ -80,6 +99,10 @@ func New(
 		ID:                 settings.ID,
 		URL:                settings.URL,
 		exemplarSampler:    exemplarSampler,
+
+		incremental:             incremental,
+		rangeQuerySplitInterval: rangeQuerySplitInterval,
+		costGuard:               costGuard,
 	}, nil
 }
 
@@ -99,7 +122,14 @@ func (s *QueryData) Execute(ctx context.Context, req *backend.QueryDataRequest)
 			return &result, err
 		}
 
-		r := s.fetch(ctx, s.client, query, hasPrometheusDataplaneFeatureFlag)
+		if s.costGuard != nil {
+			if r := s.costGuard.check(ctx, s.client, query, req.GetHTTPHeaders(), s.log.FromContext(ctx)); r != nil {
+				result.Responses[q.RefID] = *r
+				continue
+			}
+		}
+
+		r := s.fetch(ctx, s.client, query, req.GetHTTPHeaders(), hasPrometheusDataplaneFeatureFlag)
 		if r == nil {
 			s.log.FromContext(ctx).Debug("Received nil response from runQuery", "query", query.Expr)
 			continue
@@ -110,7 +140,7 @@ func (s *QueryData) Execute(ctx context.Context, req *backend.QueryDataRequest)
 	return &result, nil
 }
 
-func (s *QueryData) fetch(ctx context.Context, client *client.Client, q *models.Query, enablePrometheusDataplane bool) *backend.DataResponse {
+func (s *QueryData) fetch(ctx context.Context, client *client.Client, q *models.Query, headers http.Header, enablePrometheusDataplane bool) *backend.DataResponse {
 	traceCtx, end := s.trace(ctx, q)
 	defer end()
 
@@ -130,7 +160,7 @@ func (s *QueryData) fetch(ctx context.Context, client *client.Client, q *models.
 	}
 
 	if q.RangeQuery {
-		res := s.rangeQuery(traceCtx, client, q, enablePrometheusDataplane)
+		res := s.incrementalRangeQuery(traceCtx, client, q, headers, enablePrometheusDataplane)
 		if res.Error != nil {
 			if dr.Error == nil {
 				dr.Error = res.Error
@@ -157,8 +187,8 @@ func (s *QueryData) fetch(ctx context.Context, client *client.Client, q *models.
 	return dr
 }
 
-func (s *QueryData) rangeQuery(ctx context.Context, c *client.Client, q *models.Query, enablePrometheusDataplaneFlag bool) backend.DataResponse {
-	res, err := c.QueryRange(ctx, q)
+func (s *QueryData) rangeQuery(ctx context.Context, c *client.Client, q *models.Query, tr models.TimeRange, enablePrometheusDataplaneFlag bool) backend.DataResponse {
+	res, err := c.QueryRangeWithTimeRange(ctx, q, tr)
 	if err != nil {
 		return backend.DataResponse{
 			Error:  err,
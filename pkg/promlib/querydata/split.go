@@ -0,0 +1,194 @@
+package querydata
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana-plugin-sdk-go/data/utils/maputil"
+
+	"github.com/grafana/grafana/pkg/promlib/client"
+	"github.com/grafana/grafana/pkg/promlib/models"
+)
+
+// rangeQuerySplitConcurrency is the number of the sub-range requests of a split range query that run at once.
+const rangeQuerySplitConcurrency = 4
+
+// rangePart is the frames of a range query over a part of its time range.
+type rangePart struct {
+	start  time.Time
+	frames data.Frames
+}
+
+// getRangeQuerySplitInterval returns the interval that the time ranges of the range queries are split by, or 0 when
+// they are not split.
+func getRangeQuerySplitInterval(jsonData map[string]any) (time.Duration, error) {
+	splitInterval, err := maputil.GetStringOptional(jsonData, "rangeQuerySplitInterval")
+	if err != nil {
+		return 0, err
+	}
+	if splitInterval == "" {
+		return 0, nil
+	}
+	interval, err := gtime.ParseDuration(splitInterval)
+	if err != nil {
+		return 0, fmt.Errorf("invalid range query split interval: %w", err)
+	}
+	return interval, nil
+}
+
+// splitTimeRange splits an aligned time range into sub-ranges of about the interval, which are aligned to the step
+// and do not share samples.
+func splitTimeRange(tr models.TimeRange, interval time.Duration) []models.TimeRange {
+	if interval <= 0 || tr.Step <= 0 || tr.End.Sub(tr.Start) <= interval {
+		return []models.TimeRange{tr}
+	}
+	size := interval / tr.Step * tr.Step
+	if size < tr.Step {
+		size = tr.Step
+	}
+
+	var ranges []models.TimeRange
+	for start := tr.Start; !start.After(tr.End); start = start.Add(size) {
+		end := start.Add(size - tr.Step)
+		if end.After(tr.End) {
+			end = tr.End
+		}
+		ranges = append(ranges, models.TimeRange{Start: start, End: end, Step: tr.Step})
+	}
+	return ranges
+}
+
+// splitRangeQuery runs a range query over the time range. Time ranges longer than the split interval are split into
+// sub-ranges that are requested in parallel, and whose frames are merged.
+func (s *QueryData) splitRangeQuery(ctx context.Context, c *client.Client, q *models.Query, tr models.TimeRange, enablePrometheusDataplaneFlag bool) backend.DataResponse {
+	ranges := splitTimeRange(tr, s.rangeQuerySplitInterval)
+	if len(ranges) == 1 {
+		return s.rangeQuery(ctx, c, q, tr, enablePrometheusDataplaneFlag)
+	}
+
+	responses := make([]backend.DataResponse, len(ranges))
+	limit := make(chan struct{}, rangeQuerySplitConcurrency)
+	var wg sync.WaitGroup
+	for i, r := range ranges {
+		wg.Add(1)
+		go func(i int, r models.TimeRange) {
+			defer wg.Done()
+			limit <- struct{}{}
+			defer func() { <-limit }()
+			responses[i] = s.rangeQuery(ctx, c, q, r, enablePrometheusDataplaneFlag)
+		}(i, r)
+	}
+	wg.Wait()
+
+	parts := make([]rangePart, 0, len(ranges))
+	for i, res := range responses {
+		if res.Error != nil {
+			return res
+		}
+		parts = append(parts, rangePart{start: ranges[i].Start, frames: res.Frames})
+	}
+
+	frames, ok := mergeRangeParts(parts, tr)
+	if !ok {
+		// the frames of the query can not be merged, such as the ones of native histograms
+		return s.rangeQuery(ctx, c, q, tr, enablePrometheusDataplaneFlag)
+	}
+
+	res := responses[len(responses)-1]
+	res.Frames = finishRangeFrames(q, frames, res.Frames)
+	return res
+}
+
+// mergeRangeParts merges the frames of the parts of a range query, in chronological order, into a frame per series
+// with its samples over the time range. The samples of a part replace the ones of the earlier parts from its start.
+// It returns false when a frame is not the samples of a series.
+func mergeRangeParts(parts []rangePart, tr models.TimeRange) (data.Frames, bool) {
+	merged := map[string]*data.Frame{}
+	var keys []string
+	for i, part := range parts {
+		from, to := part.start, tr.End
+		if from.Before(tr.Start) {
+			from = tr.Start
+		}
+		if i+1 < len(parts) {
+			to = parts[i+1].start.Add(-time.Nanosecond)
+		}
+
+		for _, frame := range part.frames {
+			if len(frame.Fields) == 0 {
+				// frame added to attach the metadata to an empty response
+				continue
+			}
+			if !isSeriesFrame(frame) {
+				return nil, false
+			}
+
+			timeField, valueField := frame.Fields[0], frame.Fields[1]
+			key := frame.Name + valueField.Name + valueField.Labels.String()
+			m, ok := merged[key]
+			if !ok {
+				m = data.NewFrame("",
+					data.NewFieldFromFieldType(timeField.Type(), 0),
+					data.NewFieldFromFieldType(valueField.Type(), 0),
+				)
+				merged[key] = m
+				keys = append(keys, key)
+			}
+
+			// the name, metadata and config of the latest part are kept
+			m.Name, m.RefID = frame.Name, frame.RefID
+			if frame.Meta != nil {
+				meta := *frame.Meta
+				meta.ExecutedQueryString = ""
+				m.Meta = &meta
+			}
+			for j, field := range frame.Fields {
+				m.Fields[j].Name = field.Name
+				m.Fields[j].Labels = field.Labels
+				m.Fields[j].Config = field.Config
+			}
+
+			for row := 0; row < timeField.Len(); row++ {
+				t := timeField.At(row).(time.Time)
+				if t.Before(from) || t.After(to) {
+					continue
+				}
+				m.Fields[0].Append(t)
+				m.Fields[1].Append(valueField.At(row))
+			}
+		}
+	}
+
+	frames := make(data.Frames, 0, len(keys))
+	for _, key := range keys {
+		if merged[key].Rows() > 0 {
+			frames = append(frames, merged[key])
+		}
+	}
+	return frames, true
+}
+
+// finishRangeFrames returns the merged frames of a range query, with the executed query string on the first one, or
+// the frames of the latest part when there are no samples.
+func finishRangeFrames(q *models.Query, frames data.Frames, latest data.Frames) data.Frames {
+	if len(frames) == 0 {
+		return latest
+	}
+	if frames[0].Meta == nil {
+		frames[0].Meta = &data.FrameMeta{}
+	}
+	frames[0].Meta.ExecutedQueryString = executedQueryString(q)
+	return frames
+}
+
+// isSeriesFrame returns true when the frame is the samples of a series, with a time and a value field.
+func isSeriesFrame(frame *data.Frame) bool {
+	return len(frame.Fields) == 2 &&
+		frame.Fields[0].Type() == data.FieldTypeTime &&
+		frame.Fields[1].Type() == data.FieldTypeFloat64
+}
@@ -11,12 +11,29 @@ import (
 var (
 	ErrTimerangeMissing     = errors.New("missing timerange")
 	ErrBaseTagLimitExceeded = errutil.BadRequest("annotations.tag-limit-exceeded", errutil.WithPublicMessage("Tags length exceeds the maximum allowed."))
+	ErrInvalidCursor        = errutil.BadRequest("annotations.invalid-cursor", errutil.WithPublicMessage("Invalid cursor."))
 )
 
+// TagsLength returns the estimated length of the tags once they are stored as a JSON array
+func TagsLength(tags []string) int {
+	length := 1 // leading: [
+	for i, t := range tags {
+		if i == 0 {
+			length += len(t) + 2 // quotes
+		} else {
+			length += len(t) + 3 // leading comma and quotes
+		}
+	}
+	length += 1 // trailing: ]
+	return length
+}
+
 //go:generate mockery --name Repository --structname FakeAnnotationsRepo --inpackage --filename annotations_repository_mock.go
 type Repository interface {
 	Save(ctx context.Context, item *Item) error
 	SaveMany(ctx context.Context, items []Item) error
+	// Import saves the annotations that were not saved with the same idempotency key yet, and returns how many were saved
+	Import(ctx context.Context, items []Item) (int64, error)
 	Update(ctx context.Context, item *Item) error
 	Find(ctx context.Context, query *ItemQuery) ([]*ItemDTO, error)
 	Delete(ctx context.Context, params *DeleteParams) error
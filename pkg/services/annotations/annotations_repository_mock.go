@@ -72,6 +72,27 @@ func (_m *FakeAnnotationsRepo) FindTags(ctx context.Context, query *TagsQuery) (
 	return r0, r1
 }
 
+// Import provides a mock function with given fields: ctx, items
+func (_m *FakeAnnotationsRepo) Import(ctx context.Context, items []Item) (int64, error) {
+	ret := _m.Called(ctx, items)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, []Item) int64); ok {
+		r0 = rf(ctx, items)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, []Item) error); ok {
+		r1 = rf(ctx, items)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Save provides a mock function with given fields: ctx, item
 func (_m *FakeAnnotationsRepo) Save(ctx context.Context, item *Item) error {
 	ret := _m.Called(ctx, item)
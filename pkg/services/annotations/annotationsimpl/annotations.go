@@ -63,6 +63,10 @@ func (r *RepositoryImpl) SaveMany(ctx context.Context, items []annotations.Item)
 	return r.writer.AddMany(ctx, items)
 }
 
+func (r *RepositoryImpl) Import(ctx context.Context, items []annotations.Item) (int64, error) {
+	return r.writer.Import(ctx, items)
+}
+
 func (r *RepositoryImpl) Update(ctx context.Context, item *annotations.Item) error {
 	return r.writer.Update(ctx, item)
 }
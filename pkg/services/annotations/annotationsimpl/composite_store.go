@@ -52,6 +52,11 @@ func (c *CompositeStore) Get(ctx context.Context, query *annotations.ItemQuery,
 	}
 	sort.Sort(annotations.SortedItems(res))
 
+	// each store returns up to the limit, so only the first ones of the combined results come before the others
+	if query != nil && query.Limit > 0 && int64(len(res)) > query.Limit {
+		res = res[:query.Limit]
+	}
+
 	return res, nil
 }
 
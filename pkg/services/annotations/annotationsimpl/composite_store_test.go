@@ -97,6 +97,30 @@ func TestCompositeStore(t *testing.T) {
 		require.Equal(t, expected, items)
 	})
 
+	t.Run("should return the first results up to the limit", func(t *testing.T) {
+		r1 := newFakeReader(withItems([]*annotations.ItemDTO{
+			{TimeEnd: 4, Time: 4},
+			{TimeEnd: 1, Time: 1},
+		}))
+		r2 := newFakeReader(withItems([]*annotations.ItemDTO{
+			{TimeEnd: 3, Time: 3},
+			{TimeEnd: 2, Time: 2},
+		}))
+
+		store := &CompositeStore{
+			log.NewNopLogger(),
+			[]readStore{r1, r2},
+		}
+
+		expected := []*annotations.ItemDTO{
+			{TimeEnd: 4, Time: 4},
+			{TimeEnd: 3, Time: 3},
+		}
+
+		items, _ := store.Get(context.Background(), &annotations.ItemQuery{Limit: 2}, nil)
+		require.Equal(t, expected, items)
+	})
+
 	t.Run("should combine and sort results from GetTags", func(t *testing.T) {
 		tags1 := []*annotations.TagsDTO{
 			{Tag: "key1:val1"},
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
@@ -113,18 +114,47 @@ func (r *LokiHistorianStore) Get(ctx context.Context, query *annotations.ItemQue
 	from := query.From * 1e6
 	to := query.To * 1e6
 
-	res, err := r.client.RangeQuery(ctx, logQL, from, to, query.Limit)
-	if err != nil {
-		return make([]*annotations.ItemDTO, 0), ErrLokiStoreInternal.Errorf("failed to query loki: %w", err)
+	// state history annotations have no end time, so the annotations after a cursor of another state history annotation
+	// are the older ones
+	if query.Cursor != nil && query.Cursor.TimeEnd == 0 && query.Cursor.Time*1e6 < to {
+		to = query.Cursor.Time * 1e6
 	}
 
+	// the entries are filtered after they are queried, so loki is queried for older entries until the limit of
+	// annotations is reached or there are no more entries
 	items := make([]*annotations.ItemDTO, 0)
-	for _, stream := range res.Data.Result {
-		items = append(items, r.annotationsFromStream(stream, *accessResources)...)
+	for from < to {
+		res, err := r.client.RangeQuery(ctx, logQL, from, to, query.Limit)
+		if err != nil {
+			return make([]*annotations.ItemDTO, 0), ErrLokiStoreInternal.Errorf("failed to query loki: %w", err)
+		}
+
+		entries := int64(0)
+		oldest := to
+		for _, stream := range res.Data.Result {
+			items = append(items, filterItems(r.annotationsFromStream(stream, *accessResources), query)...)
+			for _, sample := range stream.Values {
+				entries++
+				if t := sample.T.UnixNano(); t < oldest {
+					oldest = t
+				}
+			}
+		}
+
+		if query.Limit <= 0 || entries < query.Limit || int64(len(items)) >= query.Limit {
+			break
+		}
+		// the end of loki queries is exclusive, so the next query returns the entries before the oldest one
+		to = oldest
 	}
 	sort.Sort(annotations.SortedItems(items))
 
-	return items, err
+	// the last query can return more annotations than the limit, the first ones are the newest
+	if query.Limit > 0 && int64(len(items)) > query.Limit {
+		items = items[:query.Limit]
+	}
+
+	return items, nil
 }
 
 func (r *LokiHistorianStore) annotationsFromStream(stream historian.Stream, ac accesscontrol.AccessResources) []*annotations.ItemDTO {
@@ -184,6 +214,27 @@ func (r *LokiHistorianStore) GetTags(ctx context.Context, query *annotations.Tag
 
 // util
 
+// filterItems returns the annotations that contain the text of the query and come after its cursor. The text of the
+// annotations is built from the entries, so Loki cannot filter on it.
+func filterItems(items []*annotations.ItemDTO, query *annotations.ItemQuery) []*annotations.ItemDTO {
+	if query.Text == "" && query.Cursor == nil {
+		return items
+	}
+
+	text := strings.ToLower(query.Text)
+	filtered := make([]*annotations.ItemDTO, 0, len(items))
+	for _, item := range items {
+		if text != "" && !strings.Contains(strings.ToLower(item.Text), text) {
+			continue
+		}
+		if query.Cursor != nil && !query.Cursor.Precedes(item) {
+			continue
+		}
+		filtered = append(filtered, item)
+	}
+	return filtered
+}
+
 func getRule(ctx context.Context, sql db.DB, orgID int64, ruleID int64) (*ngmodels.AlertRule, error) {
 	rule := &ngmodels.AlertRule{OrgID: orgID, ID: ruleID}
 	err := sql.WithDbSession(ctx, func(sess *db.Session) error {
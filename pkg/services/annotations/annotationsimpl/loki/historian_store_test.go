@@ -6,6 +6,7 @@ import (
 	"errors"
 	"math/rand"
 	"net/url"
+	"sort"
 	"strconv"
 	"sync"
 	"testing"
@@ -283,6 +284,45 @@ func TestIntegrationAlertStateHistoryStore(t *testing.T) {
 		})
 	})
 
+	t.Run("Testing Loki state history read with a text filter", func(t *testing.T) {
+		start := time.Now()
+		fakeLokiClient := NewFakeLokiClient()
+		fakeLokiClient.keepRangeQueryRes = true
+		store := createTestLokiStore(t, sql, fakeLokiClient)
+
+		t.Run("queries older entries until the limit of annotations is reached", func(t *testing.T) {
+			// the entries of the second rule are newer, and don't contain the text
+			fakeLokiClient.rangeQueryRes = []historian.Stream{
+				historian.StatesToStream(ruleMetaFromRule(t, dashboardRules[dashboard1.UID][0]), genStateTransitions(t, 3, start), map[string]string{}, log.NewNopLogger()),
+				historian.StatesToStream(ruleMetaFromRule(t, dashboardRules[dashboard1.UID][1]), genStateTransitions(t, 3, start.Add(10*time.Second)), map[string]string{}, log.NewNopLogger()),
+			}
+
+			query := annotations.ItemQuery{
+				OrgID:       1,
+				DashboardID: dashboard1.ID,
+				From:        start.UnixMilli(),
+				To:          start.Add(20 * time.Second).UnixMilli(),
+				Text:        "Test Rule 1",
+				Limit:       2,
+			}
+			res, err := store.Get(
+				context.Background(),
+				&query,
+				&annotation_ac.AccessResources{
+					Dashboards: map[string]int64{
+						dashboard1.UID: dashboard1.ID,
+					},
+					CanAccessDashAnnotations: true,
+				},
+			)
+			require.NoError(t, err)
+			require.Len(t, res, 2)
+			require.Equal(t, start.Add(3*time.Second).UnixMilli(), res[0].Time)
+			require.Equal(t, start.Add(time.Second).UnixMilli(), res[1].Time)
+			require.Equal(t, 3, fakeLokiClient.rangeQueries)
+		})
+	})
+
 	t.Run("Testing items from Loki stream", func(t *testing.T) {
 		fakeLokiClient := NewFakeLokiClient()
 		store := createTestLokiStore(t, sql, fakeLokiClient)
@@ -516,6 +556,29 @@ func TestBuildHistoryQuery(t *testing.T) {
 	})
 }
 
+func TestFilterItems(t *testing.T) {
+	items := []*annotations.ItemDTO{
+		{Time: 3, Text: "Rule A {alertname=A} - Alerting"},
+		{Time: 2, Text: "Rule B {alertname=B} - Normal"},
+		{Time: 1, Text: "Rule A {alertname=A} - Normal"},
+	}
+
+	t.Run("should return all items without text and cursor", func(t *testing.T) {
+		require.Equal(t, items, filterItems(items, &annotations.ItemQuery{}))
+	})
+
+	t.Run("should return items containing the text", func(t *testing.T) {
+		require.Equal(t, []*annotations.ItemDTO{items[1], items[2]}, filterItems(items, &annotations.ItemQuery{Text: "normal"}))
+	})
+
+	t.Run("should return items after the cursor", func(t *testing.T) {
+		require.Equal(t, []*annotations.ItemDTO{items[2]}, filterItems(items, &annotations.ItemQuery{
+			Text:   "rule a",
+			Cursor: annotations.NewCursor(items[0]),
+		}))
+	})
+}
+
 func TestBuildTransition(t *testing.T) {
 	t.Run("should return error when entry contains invalid state strings", func(t *testing.T) {
 		_, err := buildTransition(historian.LokiEntry{
@@ -781,6 +844,9 @@ type FakeLokiClient struct {
 	metrics       *metrics.Historian
 	log           log.Logger
 	rangeQueryRes []historian.Stream
+	// keepRangeQueryRes keeps the expected streams on read, and returns the newest entries up to the limit
+	keepRangeQueryRes bool
+	rangeQueries      int
 }
 
 func NewFakeLokiClient() *FakeLokiClient {
@@ -817,6 +883,30 @@ func (c *FakeLokiClient) RangeQuery(ctx context.Context, query string, from, to,
 			streams[n].Values = append(streams[n].Values, sample)
 		}
 	}
+	c.rangeQueries++
+
+	if c.keepRangeQueryRes {
+		// like loki, return the newest entries of all the streams up to the limit
+		times := make([]int64, 0)
+		for _, stream := range streams {
+			for _, sample := range stream.Values {
+				times = append(times, sample.T.UnixNano())
+			}
+		}
+		if limit > 0 && int64(len(times)) > limit {
+			sort.Slice(times, func(i, j int) bool { return times[i] > times[j] })
+			for n := range streams {
+				values := []historian.Sample{}
+				for _, sample := range streams[n].Values {
+					if sample.T.UnixNano() >= times[limit-1] {
+						values = append(values, sample)
+					}
+				}
+				streams[n].Values = values
+			}
+		}
+		return historian.QueryRes{Data: historian.QueryData{Result: streams}}, nil
+	}
 
 	res := historian.QueryRes{
 		Data: historian.QueryData{
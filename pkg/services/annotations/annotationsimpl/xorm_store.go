@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -21,6 +22,16 @@ import (
 	"github.com/grafana/grafana/pkg/setting"
 )
 
+// sqlLikeEscape escapes the wildcards of the text filter, which matches the text
+// literally.
+const sqlLikeEscape = "#"
+
+var sqlLikeEscapeReplacer = strings.NewReplacer(
+	sqlLikeEscape, sqlLikeEscape+sqlLikeEscape,
+	"%", sqlLikeEscape+"%",
+	"_", sqlLikeEscape+"_",
+)
+
 // Update the item so that EpochEnd >= Epoch
 func validateTimeRange(item *annotations.Item) error {
 	if item.EpochEnd == 0 {
@@ -133,6 +144,83 @@ func (r *xormRepositoryImpl) AddMany(ctx context.Context, items []annotations.It
 	})
 }
 
+// maxImportAttempts is how many times an import is attempted when concurrent imports insert the same idempotency keys
+const maxImportAttempts = 3
+
+// Import inserts the annotations that were not inserted with the same idempotency key in their organization yet, and
+// returns the number of inserted annotations. Annotations without an idempotency key are always inserted.
+func (r *xormRepositoryImpl) Import(ctx context.Context, items []annotations.Item) (int64, error) {
+	var imported int64
+	var err error
+	for attempt := 0; attempt < maxImportAttempts; attempt++ {
+		err = r.db.InTransaction(ctx, func(ctx context.Context) error {
+			newItems, err := r.withoutExistingIdempotencyKeys(ctx, items)
+			if err != nil {
+				return err
+			}
+
+			imported = int64(len(newItems))
+			return r.AddMany(ctx, newItems)
+		})
+		// a concurrent import inserted some of the idempotency keys after they were checked, the annotations of
+		// these keys are skipped in the next attempt
+		if err == nil || !r.db.GetDialect().IsUniqueConstraintViolation(err) {
+			break
+		}
+	}
+
+	return imported, err
+}
+
+// withoutExistingIdempotencyKeys returns the items whose idempotency key is not used by an annotation of their
+// organization, nor by a previous item
+func (r *xormRepositoryImpl) withoutExistingIdempotencyKeys(ctx context.Context, items []annotations.Item) ([]annotations.Item, error) {
+	keysByOrg := make(map[int64][]string)
+	for _, item := range items {
+		if item.IdempotencyKey != nil {
+			keysByOrg[item.OrgID] = append(keysByOrg[item.OrgID], *item.IdempotencyKey)
+		}
+	}
+
+	existing := make(map[int64]map[string]struct{}, len(keysByOrg))
+	err := r.db.WithDbSession(ctx, func(sess *db.Session) error {
+		opts := sqlstore.NativeSettingsForDialect(r.db.GetDialect())
+		for orgID, keys := range keysByOrg {
+			existing[orgID] = make(map[string]struct{}, len(keys))
+			err := sqlstore.InBatches(keys, opts, func(batch any) error {
+				var existingKeys []string
+				if err := sess.Table("annotation").Cols("idempotency_key").Where("org_id = ?", orgID).In("idempotency_key", batch).Find(&existingKeys); err != nil {
+					return err
+				}
+				for _, key := range existingKeys {
+					existing[orgID][key] = struct{}{}
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	newItems := make([]annotations.Item, 0, len(items))
+	for _, item := range items {
+		if item.IdempotencyKey != nil {
+			if _, ok := existing[item.OrgID][*item.IdempotencyKey]; ok {
+				continue
+			}
+			existing[item.OrgID][*item.IdempotencyKey] = struct{}{}
+		}
+		newItems = append(newItems, item)
+	}
+
+	return newItems, nil
+}
+
 func (r *xormRepositoryImpl) Update(ctx context.Context, item *annotations.Item) error {
 	return r.db.InTransaction(ctx, func(ctx context.Context) error {
 		return r.update(ctx, item)
@@ -315,6 +403,16 @@ func (r *xormRepositoryImpl) Get(ctx context.Context, query *annotations.ItemQue
 			sql.WriteString(` AND a.alert_id = 0`)
 		}
 
+		if query.Text != "" {
+			sql.WriteString(` AND a.text ` + r.db.GetDialect().LikeStr() + ` ? ESCAPE ?`)
+			params = append(params, "%"+sqlLikeEscapeReplacer.Replace(query.Text)+"%", sqlLikeEscape)
+		}
+
+		if query.Cursor != nil {
+			sql.WriteString(` AND (a.epoch_end < ? OR (a.epoch_end = ? AND (a.epoch < ? OR (a.epoch = ? AND a.id < ?))))`)
+			params = append(params, query.Cursor.TimeEnd, query.Cursor.TimeEnd, query.Cursor.Time, query.Cursor.Time, query.Cursor.ID)
+		}
+
 		if len(query.Tags) > 0 {
 			keyValueFilters := []string{}
 
@@ -358,12 +456,14 @@ func (r *xormRepositoryImpl) Get(ctx context.Context, query *annotations.ItemQue
 		}
 
 		// order of ORDER BY arguments match the order of a sql index for performance
-		sql.WriteString(" ORDER BY a.org_id, a.epoch_end DESC, a.epoch DESC" + r.db.GetDialect().Limit(query.Limit) + " ) dt on dt.id = annotation.id")
+		sql.WriteString(" ORDER BY a.org_id, a.epoch_end DESC, a.epoch DESC, a.id DESC" + r.db.GetDialect().Limit(query.Limit) + " ) dt on dt.id = annotation.id")
 
 		if err := sess.SQL(sql.String(), params...).Find(&items); err != nil {
 			items = nil
 			return err
 		}
+		// the order of the subquery is not kept by the join
+		sort.Sort(annotations.SortedItems(items))
 		return nil
 	},
 	)
@@ -505,15 +605,7 @@ func (r *xormRepositoryImpl) validateItem(item *annotations.Item) error {
 }
 
 func (r *xormRepositoryImpl) validateTagsLength(item *annotations.Item) error {
-	estimatedTagsLength := 1 // leading: [
-	for i, t := range item.Tags {
-		if i == 0 {
-			estimatedTagsLength += len(t) + 2 // quotes
-		} else {
-			estimatedTagsLength += len(t) + 3 // leading comma and quotes
-		}
-	}
-	estimatedTagsLength += 1 // trailing: ]
+	estimatedTagsLength := annotations.TagsLength(item.Tags)
 	if estimatedTagsLength > int(r.cfg.AnnotationMaximumTagsLength) {
 		return annotations.ErrBaseTagLimitExceeded.Errorf("tags length (%d) exceeds the maximum allowed (%d): modify the configuration to increase it", estimatedTagsLength, r.cfg.AnnotationMaximumTagsLength)
 	}
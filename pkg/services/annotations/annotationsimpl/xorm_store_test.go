@@ -313,6 +313,87 @@ func TestIntegrationAnnotations(t *testing.T) {
 			assert.Len(t, items, 2)
 		})
 
+		t.Run("Should find annotations by text", func(t *testing.T) {
+			accRes := &annotation_ac.AccessResources{CanAccessOrgAnnotations: true}
+			items, err := store.Get(context.Background(), &annotations.ItemQuery{
+				OrgID:        1,
+				From:         1,
+				To:           25,
+				Text:         "roll",
+				SignedInUser: testUser,
+			}, accRes)
+			require.NoError(t, err)
+			require.Len(t, items, 1)
+			assert.Equal(t, organizationAnnotation2.ID, items[0].ID)
+		})
+
+		t.Run("Should match the wildcards in the text literally", func(t *testing.T) {
+			accRes := &annotation_ac.AccessResources{CanAccessOrgAnnotations: true}
+			for _, text := range []string{"r_ll", "ro%back", "#"} {
+				items, err := store.Get(context.Background(), &annotations.ItemQuery{
+					OrgID:        1,
+					From:         1,
+					To:           25,
+					Text:         text,
+					SignedInUser: testUser,
+				}, accRes)
+				require.NoError(t, err)
+				assert.Empty(t, items, text)
+			}
+		})
+
+		t.Run("Can page through annotations with a cursor", func(t *testing.T) {
+			accRes := &annotation_ac.AccessResources{CanAccessOrgAnnotations: true}
+			query := &annotations.ItemQuery{OrgID: 100, Limit: 4, SignedInUser: testUser}
+
+			var ids []int64
+			for page := 0; page < 3; page++ {
+				items, err := store.Get(context.Background(), query, accRes)
+				require.NoError(t, err)
+				for _, item := range items {
+					ids = append(ids, item.ID)
+				}
+				if len(items) < int(query.Limit) {
+					break
+				}
+				query.Cursor = annotations.NewCursor(items[len(items)-1])
+			}
+
+			// the annotations of the batch have the same time, so they are sorted by ID
+			require.Len(t, ids, 10)
+			assert.IsDecreasing(t, ids)
+		})
+
+		t.Run("Can import annotations once", func(t *testing.T) {
+			key := func(k string) *string { return &k }
+			items := []annotations.Item{
+				{OrgID: 102, Text: "deploy 1", Epoch: 12, IdempotencyKey: key("deploy-1")},
+				{OrgID: 102, Text: "deploy 2", Epoch: 13, IdempotencyKey: key("deploy-2"), Tags: []string{"deploy"}},
+				{OrgID: 102, Text: "deploy 1 again", Epoch: 12, IdempotencyKey: key("deploy-1")},
+				{OrgID: 102, Text: "no key", Epoch: 14},
+			}
+
+			imported, err := store.Import(context.Background(), items)
+			require.NoError(t, err)
+			assert.Equal(t, int64(3), imported)
+
+			imported, err = store.Import(context.Background(), items)
+			require.NoError(t, err)
+			assert.Equal(t, int64(1), imported)
+
+			// the same key can be used in other organizations
+			imported, err = store.Import(context.Background(), []annotations.Item{
+				{OrgID: 103, Text: "deploy 1", Epoch: 12, IdempotencyKey: key("deploy-1")},
+			})
+			require.NoError(t, err)
+			assert.Equal(t, int64(1), imported)
+
+			accRes := &annotation_ac.AccessResources{CanAccessOrgAnnotations: true}
+			inserted, err := store.Get(context.Background(), &annotations.ItemQuery{OrgID: 102, SignedInUser: testUser}, accRes)
+			require.NoError(t, err)
+			assert.Len(t, inserted, 4)
+		})
+
 		t.Run("Should find one when all key value tag filters does match", func(t *testing.T) {
 			accRes := &annotation_ac.AccessResources{
 				Dashboards:               map[string]int64{"foo": 1},
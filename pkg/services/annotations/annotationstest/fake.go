@@ -61,6 +61,34 @@ func (repo *fakeAnnotationsRepo) SaveMany(ctx context.Context, items []annotatio
 	return nil
 }
 
+func (repo *fakeAnnotationsRepo) Import(ctx context.Context, items []annotations.Item) (int64, error) {
+	repo.mtx.Lock()
+	defer repo.mtx.Unlock()
+
+	var imported int64
+	for _, i := range items {
+		if i.IdempotencyKey != nil && repo.hasIdempotencyKey(i.OrgID, *i.IdempotencyKey) {
+			continue
+		}
+		if i.ID == 0 {
+			i.ID = int64(len(repo.annotations) + 1)
+		}
+		repo.annotations[i.ID] = i
+		imported++
+	}
+
+	return imported, nil
+}
+
+func (repo *fakeAnnotationsRepo) hasIdempotencyKey(orgID int64, key string) bool {
+	for _, a := range repo.annotations {
+		if a.OrgID == orgID && a.IdempotencyKey != nil && *a.IdempotencyKey == key {
+			return true
+		}
+	}
+	return false
+}
+
 func (repo *fakeAnnotationsRepo) Update(_ context.Context, item *annotations.Item) error {
 	return nil
 }
@@ -0,0 +1,50 @@
+package annotations
+
+import (
+	"encoding/base64"
+	"fmt"
+)
+
+// Cursor is the position of an annotation in the results of a query, which are sorted in descending order by end time,
+// then by start time, then by ID.
+type Cursor struct {
+	TimeEnd int64
+	Time    int64
+	ID      int64
+}
+
+// NewCursor returns the position of the annotation
+func NewCursor(item *ItemDTO) *Cursor {
+	return &Cursor{TimeEnd: item.TimeEnd, Time: item.Time, ID: item.ID}
+}
+
+// ParseCursor parses a cursor returned by Cursor.String
+func ParseCursor(s string) (*Cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor.Errorf("failed to decode cursor: %w", err)
+	}
+
+	c := &Cursor{}
+	if _, err := fmt.Sscanf(string(b), "%d:%d:%d", &c.TimeEnd, &c.Time, &c.ID); err != nil {
+		return nil, ErrInvalidCursor.Errorf("failed to parse cursor: %w", err)
+	}
+	return c, nil
+}
+
+// String returns the cursor as an opaque string
+func (c *Cursor) String() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d:%d:%d", c.TimeEnd, c.Time, c.ID)))
+}
+
+// Precedes returns true when the cursor precedes the annotation in the results, which
+// means the annotation is on a page after the cursor
+func (c *Cursor) Precedes(item *ItemDTO) bool {
+	if item.TimeEnd != c.TimeEnd {
+		return item.TimeEnd < c.TimeEnd
+	}
+	if item.Time != c.Time {
+		return item.Time < c.Time
+	}
+	return item.ID < c.ID
+}
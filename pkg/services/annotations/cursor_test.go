@@ -0,0 +1,35 @@
+package annotations
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursor(t *testing.T) {
+	t.Run("can be parsed from its string", func(t *testing.T) {
+		cursor := NewCursor(&ItemDTO{ID: 3, Time: 1700000000000, TimeEnd: 1700000060000})
+
+		parsed, err := ParseCursor(cursor.String())
+		require.NoError(t, err)
+		assert.Equal(t, cursor, parsed)
+	})
+
+	t.Run("fails to parse invalid cursors", func(t *testing.T) {
+		for _, s := range []string{"not base64!", "bm90IGEgY3Vyc29y"} {
+			_, err := ParseCursor(s)
+			require.ErrorIs(t, err, ErrInvalidCursor, s)
+		}
+	})
+
+	t.Run("precedes the annotations that come after it", func(t *testing.T) {
+		cursor := &Cursor{TimeEnd: 20, Time: 10, ID: 5}
+
+		assert.True(t, cursor.Precedes(&ItemDTO{TimeEnd: 19, Time: 15, ID: 9}))
+		assert.True(t, cursor.Precedes(&ItemDTO{TimeEnd: 20, Time: 9, ID: 9}))
+		assert.True(t, cursor.Precedes(&ItemDTO{TimeEnd: 20, Time: 10, ID: 4}))
+		assert.False(t, cursor.Precedes(&ItemDTO{TimeEnd: 20, Time: 10, ID: 5}))
+		assert.False(t, cursor.Precedes(&ItemDTO{TimeEnd: 21, Time: 1, ID: 1}))
+	})
+}
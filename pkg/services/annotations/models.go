@@ -18,9 +18,11 @@ type ItemQuery struct {
 	Tags         []string `json:"tags"`
 	Type         string   `json:"type"`
 	MatchAny     bool     `json:"matchAny"`
+	Text         string   `json:"text"`
 	SignedInUser identity.Requester
 
-	Limit int64 `json:"limit"`
+	Limit  int64   `json:"limit"`
+	Cursor *Cursor `json:"cursor"`
 }
 
 // TagsQuery is the query for a tags search.
@@ -93,6 +95,9 @@ type Item struct {
 	Tags        []string         `json:"tags"`
 	Data        *simplejson.Json `json:"data"`
 
+	// IdempotencyKey identifies an imported annotation, so that it is imported only once
+	IdempotencyKey *string `json:"idempotencyKey,omitempty" xorm:"idempotency_key"`
+
 	// needed until we remove it from db
 	Type  string
 	Title string
@@ -127,7 +132,7 @@ type ItemDTO struct {
 
 type SortedItems []*ItemDTO
 
-// sort annotations in descending order by end time, then by start time
+// sort annotations in descending order by end time, then by start time, then by ID
 func (s SortedItems) Len() int {
 	return len(s)
 }
@@ -136,7 +141,10 @@ func (s SortedItems) Less(i, j int) bool {
 	if s[i].TimeEnd != s[j].TimeEnd {
 		return s[i].TimeEnd > s[j].TimeEnd
 	}
-	return s[i].Time > s[j].Time
+	if s[i].Time != s[j].Time {
+		return s[i].Time > s[j].Time
+	}
+	return s[i].ID > s[j].ID
 }
 
 func (s SortedItems) Swap(i, j int) {
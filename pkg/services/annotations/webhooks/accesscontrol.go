@@ -0,0 +1,49 @@
+package webhooks
+
+import (
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/org"
+)
+
+const (
+	// ActionRead allows listing the annotation webhooks of the organization.
+	ActionRead = "annotations.webhooks:read"
+	// ActionWrite allows creating, updating and deleting the annotation webhooks of the organization.
+	ActionWrite = "annotations.webhooks:write"
+)
+
+var (
+	webhooksReaderRole = accesscontrol.RoleDTO{
+		Name:        "fixed:annotations.webhooks:reader",
+		DisplayName: "Annotation webhooks reader",
+		Description: "List the annotation webhooks of the organization",
+		Group:       "Annotations",
+		Permissions: []accesscontrol.Permission{
+			{Action: ActionRead},
+		},
+	}
+
+	webhooksWriterRole = accesscontrol.RoleDTO{
+		Name:        "fixed:annotations.webhooks:writer",
+		DisplayName: "Annotation webhooks writer",
+		Description: "List, create, update and delete the annotation webhooks of the organization",
+		Group:       "Annotations",
+		Permissions: []accesscontrol.Permission{
+			{Action: ActionRead},
+			{Action: ActionWrite},
+		},
+	}
+)
+
+func declareFixedRoles(ac accesscontrol.Service) error {
+	webhooksReader := accesscontrol.RoleRegistration{
+		Role:   webhooksReaderRole,
+		Grants: []string{string(org.RoleAdmin)},
+	}
+	webhooksWriter := accesscontrol.RoleRegistration{
+		Role:   webhooksWriterRole,
+		Grants: []string{string(org.RoleAdmin)},
+	}
+
+	return ac.DeclareFixedRoles(webhooksReader, webhooksWriter)
+}
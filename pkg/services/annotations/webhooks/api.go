@@ -0,0 +1,248 @@
+package webhooks
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// maxPayloadSize is the maximum size of the payloads received by webhooks.
+const maxPayloadSize = 1 << 20
+
+func (s *WebhooksService) registerAPIEndpoints() {
+	authorize := ac.Middleware(s.accessControl)
+
+	s.routeRegister.Group("/api/annotations/webhooks", func(webhooks routing.RouteRegister) {
+		webhooks.Get("/", authorize(ac.EvalPermission(ActionRead)), routing.Wrap(s.listHandler))
+		webhooks.Post("/", authorize(ac.EvalPermission(ActionWrite)), routing.Wrap(s.createHandler))
+
+		webhooks.Group("/:uid", func(webhook routing.RouteRegister) {
+			webhook.Get("/", authorize(ac.EvalPermission(ActionRead)), routing.Wrap(s.getHandler))
+			webhook.Put("/", authorize(ac.EvalPermission(ActionWrite)), routing.Wrap(s.updateHandler))
+			webhook.Delete("/", authorize(ac.EvalPermission(ActionWrite)), routing.Wrap(s.deleteHandler))
+		})
+	}, middleware.ReqSignedIn)
+
+	// the events are sent by external services, which are authenticated by the signature of the requests
+	s.routeRegister.Post("/api/annotations/webhooks/:uid/events", routing.Wrap(s.receiveEventHandler))
+}
+
+// swagger:route GET /annotations/webhooks annotations listAnnotationWebhooks
+//
+// Get the annotation webhooks of the organization.
+//
+// Requires the annotations.webhooks:read permission, which organization admins have by default.
+//
+// Responses:
+// 200: listAnnotationWebhooksResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *WebhooksService) listHandler(c *contextmodel.ReqContext) response.Response {
+	webhooks, err := s.ListWebhooks(c.Req.Context(), c.SignedInUser.GetOrgID())
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get webhooks", err)
+	}
+	return response.JSON(http.StatusOK, webhooks)
+}
+
+// swagger:route POST /annotations/webhooks annotations createAnnotationWebhook
+//
+// Create an annotation webhook.
+//
+// The secret used to verify the requests is only returned in this response.
+//
+// Responses:
+// 200: createAnnotationWebhookResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *WebhooksService) createHandler(c *contextmodel.ReqContext) response.Response {
+	cmd := CreateWebhookCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.OrgID = c.SignedInUser.GetOrgID()
+
+	webhook, secret, err := s.CreateWebhook(c.Req.Context(), cmd)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to create webhook", err)
+	}
+	return response.JSON(http.StatusOK, CreateWebhookResponseBody{Webhook: webhook, Secret: secret})
+}
+
+// swagger:route GET /annotations/webhooks/{uid} annotations getAnnotationWebhook
+//
+// Get an annotation webhook.
+//
+// Responses:
+// 200: getAnnotationWebhookResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *WebhooksService) getHandler(c *contextmodel.ReqContext) response.Response {
+	webhook, err := s.GetWebhook(c.Req.Context(), c.SignedInUser.GetOrgID(), web.Params(c.Req)[":uid"])
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get webhook", err)
+	}
+	return response.JSON(http.StatusOK, webhook)
+}
+
+// swagger:route PUT /annotations/webhooks/{uid} annotations updateAnnotationWebhook
+//
+// Update an annotation webhook.
+//
+// The secret is kept when it's empty.
+//
+// Responses:
+// 200: getAnnotationWebhookResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *WebhooksService) updateHandler(c *contextmodel.ReqContext) response.Response {
+	cmd := UpdateWebhookCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.UID = web.Params(c.Req)[":uid"]
+	cmd.OrgID = c.SignedInUser.GetOrgID()
+
+	webhook, err := s.UpdateWebhook(c.Req.Context(), cmd)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to update webhook", err)
+	}
+	return response.JSON(http.StatusOK, webhook)
+}
+
+// swagger:route DELETE /annotations/webhooks/{uid} annotations deleteAnnotationWebhook
+//
+// Delete an annotation webhook.
+//
+// The annotations added by the webhook are kept.
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *WebhooksService) deleteHandler(c *contextmodel.ReqContext) response.Response {
+	if err := s.DeleteWebhook(c.Req.Context(), c.SignedInUser.GetOrgID(), web.Params(c.Req)[":uid"]); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to delete webhook", err)
+	}
+	return response.Success("Webhook deleted")
+}
+
+// swagger:route POST /annotations/webhooks/{uid}/events annotations receiveAnnotationWebhookEvent
+//
+// Receive an event sent to an annotation webhook.
+//
+// The requests are authenticated by their signature instead of the credentials of
+// a user. The annotation mapped from the payload is added once per delivery of the
+// event, and events whose mapped text is empty are ignored.
+//
+// Responses:
+// 200: receiveAnnotationWebhookEventResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 404: notFoundError
+// 500: internalServerError
+func (s *WebhooksService) receiveEventHandler(c *contextmodel.ReqContext) response.Response {
+	body, err := io.ReadAll(http.MaxBytesReader(c.Resp, c.Req.Body, maxPayloadSize))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return response.Error(http.StatusRequestEntityTooLarge, "Payload too large", err)
+		}
+		return response.Error(http.StatusBadRequest, "Failed to read payload", err)
+	}
+
+	status, err := s.ReceiveEvent(c.Req.Context(), web.Params(c.Req)[":uid"], c.Req.Header, body)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to receive event", err)
+	}
+	return response.JSON(http.StatusOK, ReceiveEventResponseBody{Status: status})
+}
+
+// swagger:model
+type CreateWebhookResponseBody struct {
+	Webhook
+	// The secret used to verify the requests, only returned when the webhook is created.
+	Secret string `json:"secret"`
+}
+
+// swagger:model
+type ReceiveEventResponseBody struct {
+	// Enum: added,duplicate,ignored
+	Status EventStatus `json:"status"`
+}
+
+// swagger:parameters createAnnotationWebhook
+type CreateAnnotationWebhookParams struct {
+	// in:body
+	// required:true
+	Body CreateWebhookCommand `json:"body"`
+}
+
+// swagger:parameters getAnnotationWebhook deleteAnnotationWebhook
+type AnnotationWebhookUIDParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+}
+
+// swagger:parameters updateAnnotationWebhook
+type UpdateAnnotationWebhookParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+	// in:body
+	// required:true
+	Body UpdateWebhookCommand `json:"body"`
+}
+
+// swagger:parameters receiveAnnotationWebhookEvent
+type ReceiveAnnotationWebhookEventParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+	// The payload of the event, mapped to an annotation by the templates of the webhook.
+	// in:body
+	// required:true
+	Body any `json:"body"`
+}
+
+// swagger:response listAnnotationWebhooksResponse
+type ListAnnotationWebhooksResponse struct {
+	// in: body
+	Body []Webhook `json:"body"`
+}
+
+// swagger:response createAnnotationWebhookResponse
+type CreateAnnotationWebhookResponse struct {
+	// in: body
+	Body CreateWebhookResponseBody `json:"body"`
+}
+
+// swagger:response getAnnotationWebhookResponse
+type GetAnnotationWebhookResponse struct {
+	// in: body
+	Body Webhook `json:"body"`
+}
+
+// swagger:response receiveAnnotationWebhookEventResponse
+type ReceiveAnnotationWebhookEventResponse struct {
+	// in: body
+	Body ReceiveEventResponseBody `json:"body"`
+}
@@ -0,0 +1,166 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/webhooks/signing"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+type webhookRow struct {
+	ID           int64     `xorm:"pk autoincr 'id'"`
+	UID          string    `xorm:"uid"`
+	OrgID        int64     `xorm:"org_id"`
+	Name         string    `xorm:"name"`
+	Source       Source    `xorm:"source"`
+	Secret       string    `xorm:"secret"`
+	DashboardUID *string   `xorm:"dashboard_uid"`
+	PanelID      int64     `xorm:"panel_id"`
+	Mapping      string    `xorm:"mapping"`
+	Created      time.Time `xorm:"created"`
+	Updated      time.Time `xorm:"updated"`
+}
+
+func (webhookRow) TableName() string { return "annotation_webhook" }
+
+func (r webhookRow) toWebhook() Webhook {
+	var mapping Mapping
+	// the mappings are validated before they are stored
+	_ = json.Unmarshal([]byte(r.Mapping), &mapping)
+	w := Webhook{
+		UID:     r.UID,
+		OrgID:   r.OrgID,
+		Name:    r.Name,
+		Source:  r.Source,
+		PanelID: r.PanelID,
+		Mapping: mapping,
+		Created: r.Created,
+		Updated: r.Updated,
+	}
+	if r.DashboardUID != nil {
+		w.DashboardUID = *r.DashboardUID
+	}
+	return w
+}
+
+func (s *WebhooksService) createWebhook(ctx context.Context, cmd CreateWebhookCommand) (Webhook, error) {
+	secret, err := signing.EncryptSecret(ctx, s.secrets, cmd.Secret)
+	if err != nil {
+		return Webhook{}, err
+	}
+	mapping, err := json.Marshal(cmd.Mapping)
+	if err != nil {
+		return Webhook{}, err
+	}
+
+	now := s.now()
+	row := webhookRow{
+		UID:          util.GenerateShortUID(),
+		OrgID:        cmd.OrgID,
+		Name:         cmd.Name,
+		Source:       cmd.Source,
+		Secret:       secret,
+		DashboardUID: nullableString(cmd.DashboardUID),
+		PanelID:      cmd.PanelID,
+		Mapping:      string(mapping),
+		Created:      now,
+		Updated:      now,
+	}
+	err = s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Insert(&row)
+		return err
+	})
+	return row.toWebhook(), err
+}
+
+func (s *WebhooksService) updateWebhook(ctx context.Context, cmd UpdateWebhookCommand) (Webhook, error) {
+	var secret string
+	if cmd.Secret != "" {
+		var err error
+		if secret, err = signing.EncryptSecret(ctx, s.secrets, cmd.Secret); err != nil {
+			return Webhook{}, err
+		}
+	}
+	mapping, err := json.Marshal(cmd.Mapping)
+	if err != nil {
+		return Webhook{}, err
+	}
+
+	var row webhookRow
+	err = s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		has, err := sess.Where("org_id = ? AND uid = ?", cmd.OrgID, cmd.UID).Get(&row)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return ErrWebhookNotFound.Errorf("webhook %s not found", cmd.UID)
+		}
+
+		row.Name = cmd.Name
+		row.Source = cmd.Source
+		row.DashboardUID = nullableString(cmd.DashboardUID)
+		row.PanelID = cmd.PanelID
+		row.Mapping = string(mapping)
+		row.Updated = s.now()
+		if secret != "" {
+			row.Secret = secret
+		}
+		_, err = sess.ID(row.ID).AllCols().Update(&row)
+		return err
+	})
+	return row.toWebhook(), err
+}
+
+func (s *WebhooksService) getWebhookRow(ctx context.Context, uid string) (webhookRow, error) {
+	var row webhookRow
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		has, err := sess.Where("uid = ?", uid).Get(&row)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return ErrWebhookNotFound.Errorf("webhook %s not found", uid)
+		}
+		return nil
+	})
+	return row, err
+}
+
+func (s *WebhooksService) listWebhooks(ctx context.Context, orgID int64) ([]Webhook, error) {
+	rows := make([]webhookRow, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ?", orgID).OrderBy("name ASC").Find(&rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	webhooks := make([]Webhook, 0, len(rows))
+	for _, row := range rows {
+		webhooks = append(webhooks, row.toWebhook())
+	}
+	return webhooks, nil
+}
+
+func (s *WebhooksService) deleteWebhook(ctx context.Context, orgID int64, uid string) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		affected, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Delete(&webhookRow{})
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return ErrWebhookNotFound.Errorf("webhook %s not found", uid)
+		}
+		return nil
+	})
+}
+
+func nullableString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,197 @@
+package webhooks
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// placeholderFuncs declare the functions of the mapping templates when they are parsed,
+// they are replaced by the functions bound to the event when they are executed.
+var placeholderFuncs = template.FuncMap{
+	"get":   func(string) string { return "" },
+	"event": func() string { return "" },
+}
+
+// timeLayouts are the layouts of the times which aren't epoch times.
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05 MST",
+	"2006-01-02 15:04:05 -0700",
+}
+
+// epochMillisecondsThreshold is the smallest epoch time parsed as milliseconds, smaller
+// ones are parsed as seconds.
+const epochMillisecondsThreshold = 100_000_000_000
+
+type mappingTemplates struct {
+	time    *template.Template
+	timeEnd *template.Template
+	text    *template.Template
+	tags    []*template.Template
+}
+
+func parseMapping(m Mapping) (*mappingTemplates, error) {
+	var err error
+	t := &mappingTemplates{}
+	if t.time, err = parseTemplate("time", m.Time); err != nil {
+		return nil, err
+	}
+	if t.timeEnd, err = parseTemplate("timeEnd", m.TimeEnd); err != nil {
+		return nil, err
+	}
+	if t.text, err = parseTemplate("text", m.Text); err != nil {
+		return nil, err
+	}
+	for i, tag := range m.Tags {
+		tmpl, err := parseTemplate("tags["+strconv.Itoa(i)+"]", tag)
+		if err != nil {
+			return nil, err
+		}
+		t.tags = append(t.tags, tmpl)
+	}
+	return t, nil
+}
+
+func parseTemplate(name, text string) (*template.Template, error) {
+	tmpl, err := template.New(name).Funcs(placeholderFuncs).Parse(text)
+	if err != nil {
+		return nil, ErrInvalidMapping.Errorf("invalid %s template: %w", name, err)
+	}
+	return tmpl, nil
+}
+
+// mappedEvent is the fields of an annotation extracted from an event.
+type mappedEvent struct {
+	// Time and TimeEnd are in epoch milliseconds, 0 when the templates render empty strings.
+	Time    int64
+	TimeEnd int64
+	Text    string
+	Tags    []string
+}
+
+// apply extracts the fields of an annotation from the event.
+func (t *mappingTemplates) apply(event Event) (mappedEvent, error) {
+	decoder := json.NewDecoder(bytes.NewReader(event.Payload))
+	decoder.UseNumber()
+	var payload any
+	if err := decoder.Decode(&payload); err != nil {
+		return mappedEvent{}, ErrInvalidPayload.Errorf("failed to decode the payload: %w", err)
+	}
+
+	funcs := template.FuncMap{
+		"get":   func(path string) string { return get(payload, path) },
+		"event": func() string { return event.Name },
+	}
+	execute := func(tmpl *template.Template) (string, error) {
+		clone, err := tmpl.Clone()
+		if err != nil {
+			return "", err
+		}
+		var buf strings.Builder
+		if err := clone.Funcs(funcs).Execute(&buf, payload); err != nil {
+			return "", ErrInvalidPayload.Errorf("failed to execute the %s template: %w", tmpl.Name(), err)
+		}
+		return strings.TrimSpace(buf.String()), nil
+	}
+
+	var mapped mappedEvent
+	s, err := execute(t.time)
+	if err != nil {
+		return mappedEvent{}, err
+	}
+	if mapped.Time, err = parseTime(s); err != nil {
+		return mappedEvent{}, err
+	}
+	if s, err = execute(t.timeEnd); err != nil {
+		return mappedEvent{}, err
+	}
+	if mapped.TimeEnd, err = parseTime(s); err != nil {
+		return mappedEvent{}, err
+	}
+	if mapped.Text, err = execute(t.text); err != nil {
+		return mappedEvent{}, err
+	}
+
+	seen := map[string]bool{}
+	for _, tmpl := range t.tags {
+		s, err := execute(tmpl)
+		if err != nil {
+			return mappedEvent{}, err
+		}
+		for _, tag := range strings.Split(s, ",") {
+			tag = strings.TrimSpace(tag)
+			if tag != "" && !seen[tag] {
+				seen[tag] = true
+				mapped.Tags = append(mapped.Tags, tag)
+			}
+		}
+	}
+	return mapped, nil
+}
+
+// get returns the field of the payload at the path, whose elements are separated by dots
+// and are the keys of objects or the indexes of arrays. Arrays are returned as comma
+// separated values, objects as JSON, and missing fields as an empty string.
+func get(payload any, path string) string {
+	value := payload
+	for _, key := range strings.Split(path, ".") {
+		switch v := value.(type) {
+		case map[string]any:
+			value = v[key]
+		case []any:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(v) {
+				return ""
+			}
+			value = v[i]
+		default:
+			return ""
+		}
+	}
+	return format(value)
+}
+
+func format(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	case []any:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			values = append(values, format(item))
+		}
+		return strings.Join(values, ",")
+	default:
+		b, _ := json.Marshal(v)
+		return string(b)
+	}
+}
+
+// parseTime returns the time in epoch milliseconds, or 0 when it's empty.
+func parseTime(s string) (int64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	if epoch, err := strconv.ParseInt(s, 10, 64); err == nil {
+		if epoch < epochMillisecondsThreshold {
+			return epoch * 1000, nil
+		}
+		return epoch, nil
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UnixMilli(), nil
+		}
+	}
+	return 0, ErrInvalidPayload.Errorf("invalid time %q", s)
+}
@@ -0,0 +1,180 @@
+package webhooks
+
+import (
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/util/errutil"
+)
+
+var (
+	ErrWebhookNotFound   = errutil.NotFound("annotationwebhooks.notFound", errutil.WithPublicMessage("Webhook not found"))
+	ErrInvalidName       = errutil.BadRequest("annotationwebhooks.invalidName", errutil.WithPublicMessage("Webhook name is required"))
+	ErrInvalidSource     = errutil.BadRequest("annotationwebhooks.invalidSource", errutil.WithPublicMessage("Webhook source must be github, gitlab or generic"))
+	ErrInvalidMapping    = errutil.BadRequest("annotationwebhooks.invalidMapping", errutil.WithPublicMessage("Invalid webhook mapping"))
+	ErrInvalidScope      = errutil.BadRequest("annotationwebhooks.invalidScope", errutil.WithPublicMessage("A panel can only be set with a dashboard"))
+	ErrDashboardNotFound = errutil.BadRequest("annotationwebhooks.dashboardNotFound", errutil.WithPublicMessage("Dashboard not found"))
+	ErrInvalidSignature  = errutil.Unauthorized("annotationwebhooks.invalidSignature", errutil.WithPublicMessage("Invalid webhook signature"))
+	ErrInvalidPayload    = errutil.BadRequest("annotationwebhooks.invalidPayload", errutil.WithPublicMessage("Invalid webhook payload"))
+)
+
+// Source is the kind of service sending the events of a webhook, which defines how
+// the requests are signed and the default mapping of the payloads.
+type Source string
+
+const (
+	SourceGitHub  Source = "github"
+	SourceGitLab  Source = "gitlab"
+	SourceGeneric Source = "generic"
+)
+
+func (s Source) Validate() error {
+	switch s {
+	case SourceGitHub, SourceGitLab, SourceGeneric:
+		return nil
+	}
+	return ErrInvalidSource.Errorf("unknown webhook source %q", s)
+}
+
+// Mapping is the Go templates extracting the fields of an annotation from the payload
+// of an event. The templates are executed with the payload as data, and can use the
+// functions `get "path.to.field"`, which returns a field of the payload or an empty
+// string when it's missing, and `event`, which returns the name of the event sent by
+// GitHub and GitLab. The fields which are empty are mapped with the default template
+// of the source.
+type Mapping struct {
+	// Time is the time of the annotation, in epoch seconds or milliseconds, RFC 3339
+	// or the "2006-01-02 15:04:05 MST" format. The time of the event is used when it
+	// renders an empty string.
+	Time string `json:"time,omitempty"`
+	// TimeEnd is the end time of region annotations, in the formats of Time.
+	TimeEnd string `json:"timeEnd,omitempty"`
+	// Text is the text of the annotation. Events are ignored when it renders an
+	// empty string.
+	Text string `json:"text,omitempty"`
+	// Tags are the tags of the annotation. A tag template can render several tags
+	// separated by commas, and empty tags are skipped.
+	Tags []string `json:"tags,omitempty"`
+}
+
+// defaultMappings are the mappings of the payloads of each source.
+var defaultMappings = map[Source]Mapping{
+	SourceGitHub: {
+		Text: `GitHub {{ event }}{{ with get "action" }} {{ . }}{{ end }} in {{ get "repository.full_name" }}` +
+			`{{ with get "release.name" }}: {{ . }}{{ end }}{{ with get "pull_request.title" }}: {{ . }}{{ end }}` +
+			`{{ with get "head_commit.message" }}: {{ . }}{{ end }}`,
+		Tags: []string{"github", "{{ event }}", `{{ get "repository.full_name" }}`},
+	},
+	SourceGitLab: {
+		Text: `GitLab {{ get "object_kind" }}{{ with get "object_attributes.action" }} {{ . }}{{ end }} in {{ get "project.path_with_namespace" }}` +
+			`{{ with get "object_attributes.title" }}: {{ . }}{{ end }}{{ with get "object_attributes.status" }}: {{ . }}{{ end }}`,
+		Tags: []string{"gitlab", `{{ get "object_kind" }}`, `{{ get "project.path_with_namespace" }}`},
+	},
+	SourceGeneric: {
+		Time:    `{{ get "time" }}`,
+		TimeEnd: `{{ get "timeEnd" }}`,
+		Text:    `{{ get "text" }}`,
+		Tags:    []string{`{{ get "tags" }}`},
+	},
+}
+
+// withDefaults returns the mapping with the empty fields set to the default mapping of
+// the source.
+func (m Mapping) withDefaults(source Source) Mapping {
+	defaults := defaultMappings[source]
+	if m.Time == "" {
+		m.Time = defaults.Time
+	}
+	if m.TimeEnd == "" {
+		m.TimeEnd = defaults.TimeEnd
+	}
+	if m.Text == "" {
+		m.Text = defaults.Text
+	}
+	if len(m.Tags) == 0 {
+		m.Tags = defaults.Tags
+	}
+	return m
+}
+
+// Webhook receives the events of an external service and turns them into annotations
+// of its organization, or of a dashboard or panel when it's scoped to one.
+type Webhook struct {
+	UID    string `json:"uid"`
+	OrgID  int64  `json:"orgId"`
+	Name   string `json:"name"`
+	Source Source `json:"source"`
+	// DashboardUID is the dashboard the annotations are added to, organization
+	// annotations are added when empty.
+	DashboardUID string `json:"dashboardUid,omitempty"`
+	// PanelID is the panel of the dashboard the annotations are added to.
+	PanelID int64     `json:"panelId,omitempty"`
+	Mapping Mapping   `json:"mapping"`
+	Created time.Time `json:"created"`
+	Updated time.Time `json:"updated"`
+}
+
+// CreateWebhookCommand is the command for creating a webhook.
+// swagger:model
+type CreateWebhookCommand struct {
+	OrgID int64 `json:"-"`
+	// required:true
+	Name string `json:"name"`
+	// required:true
+	// Enum: github,gitlab,generic
+	Source       Source  `json:"source"`
+	DashboardUID string  `json:"dashboardUid"`
+	PanelID      int64   `json:"panelId"`
+	Mapping      Mapping `json:"mapping"`
+	// Secret used to verify the requests, generated when empty.
+	Secret string `json:"secret"`
+}
+
+func (cmd CreateWebhookCommand) Validate() error {
+	return validate(cmd.Name, cmd.Source, cmd.DashboardUID, cmd.PanelID, cmd.Mapping)
+}
+
+// UpdateWebhookCommand is the command for updating a webhook.
+// swagger:model
+type UpdateWebhookCommand struct {
+	UID   string `json:"-"`
+	OrgID int64  `json:"-"`
+	// required:true
+	Name string `json:"name"`
+	// required:true
+	// Enum: github,gitlab,generic
+	Source       Source  `json:"source"`
+	DashboardUID string  `json:"dashboardUid"`
+	PanelID      int64   `json:"panelId"`
+	Mapping      Mapping `json:"mapping"`
+	// Secret used to verify the requests, the current secret is kept when empty.
+	Secret string `json:"secret"`
+}
+
+func (cmd UpdateWebhookCommand) Validate() error {
+	return validate(cmd.Name, cmd.Source, cmd.DashboardUID, cmd.PanelID, cmd.Mapping)
+}
+
+func validate(name string, source Source, dashboardUID string, panelID int64, mapping Mapping) error {
+	if strings.TrimSpace(name) == "" {
+		return ErrInvalidName.Errorf("empty webhook name")
+	}
+	if err := source.Validate(); err != nil {
+		return err
+	}
+	if panelID != 0 && dashboardUID == "" {
+		return ErrInvalidScope.Errorf("panel %d without a dashboard", panelID)
+	}
+	_, err := parseMapping(mapping.withDefaults(source))
+	return err
+}
+
+// Event is an event received by a webhook.
+type Event struct {
+	// Name is the name of the event sent by GitHub and GitLab.
+	Name string
+	// DeliveryID identifies the deliveries of the event, so that redeliveries don't
+	// add the annotation again.
+	DeliveryID string
+	Payload    []byte
+}
@@ -0,0 +1,69 @@
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/webhooks/signing"
+)
+
+const (
+	headerGitHubEvent     = "X-GitHub-Event"
+	headerGitHubDelivery  = "X-GitHub-Delivery"
+	headerGitHubSignature = "X-Hub-Signature-256"
+
+	headerGitLabEvent = "X-Gitlab-Event"
+	headerGitLabUUID  = "X-Gitlab-Event-UUID"
+	headerGitLabToken = "X-Gitlab-Token"
+
+	// maxSignatureAge is how old the signature timestamps of generic webhooks can be,
+	// so that captured requests can't be replayed later.
+	maxSignatureAge = 5 * time.Minute
+)
+
+// verify checks that the request was sent by the source with the secret of the webhook.
+func verify(source Source, secret string, header http.Header, body []byte, now time.Time) error {
+	switch source {
+	case SourceGitHub:
+		if !hmac.Equal([]byte(header.Get(headerGitHubSignature)), []byte(signing.HMACSHA256(secret, body))) {
+			return ErrInvalidSignature.Errorf("invalid %s header", headerGitHubSignature)
+		}
+	case SourceGitLab:
+		if subtle.ConstantTimeCompare([]byte(header.Get(headerGitLabToken)), []byte(secret)) != 1 {
+			return ErrInvalidSignature.Errorf("invalid %s header", headerGitLabToken)
+		}
+	default:
+		// the generic webhooks are signed like the outgoing webhooks of Grafana
+		timestamp, err := strconv.ParseInt(header.Get(signing.HeaderSignatureTimestamp), 10, 64)
+		if err != nil {
+			return ErrInvalidSignature.Errorf("invalid %s header: %w", signing.HeaderSignatureTimestamp, err)
+		}
+		if age := now.Sub(time.Unix(timestamp, 0)); age > maxSignatureAge || age < -maxSignatureAge {
+			return ErrInvalidSignature.Errorf("signature timestamp %d is too old", timestamp)
+		}
+		if !hmac.Equal([]byte(header.Get(signing.HeaderSignature)), []byte(signing.Sign(secret, timestamp, body))) {
+			return ErrInvalidSignature.Errorf("invalid %s header", signing.HeaderSignature)
+		}
+	}
+	return nil
+}
+
+// eventFromRequest returns the event sent in the request by the source.
+func eventFromRequest(source Source, header http.Header, body []byte) Event {
+	event := Event{Payload: body}
+	switch source {
+	case SourceGitHub:
+		event.Name = header.Get(headerGitHubEvent)
+		event.DeliveryID = header.Get(headerGitHubDelivery)
+	case SourceGitLab:
+		// the name of GitLab events is like "Push Hook", the payloads have the shorter object_kind
+		event.Name = header.Get(headerGitLabEvent)
+		event.DeliveryID = header.Get(headerGitLabUUID)
+	default:
+		event.DeliveryID = header.Get(signing.HeaderDelivery)
+	}
+	return event
+}
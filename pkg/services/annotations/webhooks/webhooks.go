@@ -0,0 +1,229 @@
+package webhooks
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/annotations"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/services/webhooks/signing"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	secretLength = 32
+	// maxIdempotencyKeyLength is the length of the idempotency_key column of annotations.
+	maxIdempotencyKeyLength = 190
+	githubPingEvent         = "ping"
+)
+
+// EventStatus is what was done with an event received by a webhook.
+type EventStatus string
+
+const (
+	EventStatusAdded EventStatus = "added"
+	// EventStatusDuplicate is the status of the events which were already received.
+	EventStatusDuplicate EventStatus = "duplicate"
+	// EventStatusIgnored is the status of the events whose mapped text is empty.
+	EventStatusIgnored EventStatus = "ignored"
+)
+
+type Service interface {
+	CreateWebhook(ctx context.Context, cmd CreateWebhookCommand) (Webhook, string, error)
+	UpdateWebhook(ctx context.Context, cmd UpdateWebhookCommand) (Webhook, error)
+	GetWebhook(ctx context.Context, orgID int64, uid string) (Webhook, error)
+	ListWebhooks(ctx context.Context, orgID int64) ([]Webhook, error)
+	DeleteWebhook(ctx context.Context, orgID int64, uid string) error
+	ReceiveEvent(ctx context.Context, uid string, header http.Header, body []byte) (EventStatus, error)
+}
+
+// WebhooksService receives the events of external services, such as GitHub or GitLab,
+// and adds them as annotations. Each webhook is configured by a user with the
+// permission to write the webhooks, such as an organization admin, with a mapping
+// of the payloads to annotations and a secret the requests are verified with.
+type WebhooksService struct {
+	db               db.DB
+	annotationsRepo  annotations.Repository
+	dashboardService dashboards.DashboardService
+	secrets          secrets.Service
+	routeRegister    routing.RouteRegister
+	accessControl    ac.AccessControl
+	log              log.Logger
+	now              func() time.Time
+}
+
+var _ Service = (*WebhooksService)(nil)
+
+func ProvideService(sqlStore db.DB, routeRegister routing.RouteRegister, annotationsRepo annotations.Repository,
+	dashboardService dashboards.DashboardService, secretsService secrets.Service, accessControl ac.AccessControl,
+	accesscontrolService ac.Service) (*WebhooksService, error) {
+	s := &WebhooksService{
+		db:               sqlStore,
+		annotationsRepo:  annotationsRepo,
+		dashboardService: dashboardService,
+		secrets:          secretsService,
+		routeRegister:    routeRegister,
+		accessControl:    accessControl,
+		log:              log.New("annotations.webhooks"),
+		now:              time.Now,
+	}
+
+	if err := declareFixedRoles(accesscontrolService); err != nil {
+		return nil, err
+	}
+	s.registerAPIEndpoints()
+
+	return s, nil
+}
+
+func (s *WebhooksService) CreateWebhook(ctx context.Context, cmd CreateWebhookCommand) (Webhook, string, error) {
+	if err := cmd.Validate(); err != nil {
+		return Webhook{}, "", err
+	}
+	if _, err := s.getDashboardID(ctx, cmd.OrgID, cmd.DashboardUID); err != nil {
+		return Webhook{}, "", err
+	}
+	if cmd.Secret == "" {
+		secret, err := util.GetRandomString(secretLength)
+		if err != nil {
+			return Webhook{}, "", err
+		}
+		cmd.Secret = secret
+	}
+
+	webhook, err := s.createWebhook(ctx, cmd)
+	return webhook, cmd.Secret, err
+}
+
+func (s *WebhooksService) UpdateWebhook(ctx context.Context, cmd UpdateWebhookCommand) (Webhook, error) {
+	if err := cmd.Validate(); err != nil {
+		return Webhook{}, err
+	}
+	if _, err := s.getDashboardID(ctx, cmd.OrgID, cmd.DashboardUID); err != nil {
+		return Webhook{}, err
+	}
+	return s.updateWebhook(ctx, cmd)
+}
+
+func (s *WebhooksService) GetWebhook(ctx context.Context, orgID int64, uid string) (Webhook, error) {
+	row, err := s.getWebhookRow(ctx, uid)
+	if err != nil {
+		return Webhook{}, err
+	}
+	if row.OrgID != orgID {
+		return Webhook{}, ErrWebhookNotFound.Errorf("webhook %s not found", uid)
+	}
+	return row.toWebhook(), nil
+}
+
+func (s *WebhooksService) ListWebhooks(ctx context.Context, orgID int64) ([]Webhook, error) {
+	return s.listWebhooks(ctx, orgID)
+}
+
+func (s *WebhooksService) DeleteWebhook(ctx context.Context, orgID int64, uid string) error {
+	return s.deleteWebhook(ctx, orgID, uid)
+}
+
+// ReceiveEvent verifies the request sent to the webhook and adds the annotation mapped
+// from its payload.
+func (s *WebhooksService) ReceiveEvent(ctx context.Context, uid string, header http.Header, body []byte) (EventStatus, error) {
+	row, err := s.getWebhookRow(ctx, uid)
+	if err != nil {
+		return "", err
+	}
+	webhook := row.toWebhook()
+
+	secret, err := signing.DecryptSecret(ctx, s.secrets, row.Secret)
+	if err != nil {
+		return "", err
+	}
+	if err := verify(webhook.Source, secret, header, body, s.now()); err != nil {
+		return "", err
+	}
+
+	event := eventFromRequest(webhook.Source, header, body)
+	if webhook.Source == SourceGitHub && event.Name == githubPingEvent {
+		return EventStatusIgnored, nil
+	}
+
+	templates, err := parseMapping(webhook.Mapping.withDefaults(webhook.Source))
+	if err != nil {
+		return "", err
+	}
+	mapped, err := templates.apply(event)
+	if err != nil {
+		return "", err
+	}
+	if mapped.Text == "" {
+		return EventStatusIgnored, nil
+	}
+
+	dashboardID, err := s.getDashboardID(ctx, webhook.OrgID, webhook.DashboardUID)
+	if err != nil {
+		return "", err
+	}
+
+	item := annotations.Item{
+		OrgID:       webhook.OrgID,
+		DashboardID: dashboardID,
+		PanelID:     webhook.PanelID,
+		Text:        mapped.Text,
+		Epoch:       mapped.Time,
+		EpochEnd:    mapped.TimeEnd,
+		Tags:        mapped.Tags,
+		Data:        simplejson.NewFromAny(map[string]any{"webhookUid": webhook.UID}),
+	}
+	if item.Epoch == 0 {
+		item.Epoch = s.now().UnixMilli()
+	}
+	if event.DeliveryID != "" {
+		key := idempotencyKey(webhook.UID, event.DeliveryID)
+		item.IdempotencyKey = &key
+	}
+
+	// the import skips the events whose delivery was already received
+	imported, err := s.annotationsRepo.Import(ctx, []annotations.Item{item})
+	if err != nil {
+		return "", err
+	}
+	if imported == 0 {
+		return EventStatusDuplicate, nil
+	}
+	return EventStatusAdded, nil
+}
+
+// getDashboardID returns the ID of the dashboard the annotations are added to, 0 for
+// organization annotations.
+func (s *WebhooksService) getDashboardID(ctx context.Context, orgID int64, dashboardUID string) (int64, error) {
+	if dashboardUID == "" {
+		return 0, nil
+	}
+	dashboard, err := s.dashboardService.GetDashboard(ctx, &dashboards.GetDashboardQuery{UID: dashboardUID, OrgID: orgID})
+	if err != nil {
+		if errors.Is(err, dashboards.ErrDashboardNotFound) {
+			return 0, ErrDashboardNotFound.Errorf("dashboard %s not found", dashboardUID)
+		}
+		return 0, err
+	}
+	return dashboard.ID, nil
+}
+
+// idempotencyKey returns the key of the annotations added by the deliveries of an event.
+func idempotencyKey(webhookUID, deliveryID string) string {
+	key := "webhook:" + webhookUID + ":" + deliveryID
+	if len(key) > maxIdempotencyKeyLength {
+		hash := sha256.Sum256([]byte(deliveryID))
+		key = "webhook:" + webhookUID + ":" + hex.EncodeToString(hash[:])
+	}
+	return key
+}
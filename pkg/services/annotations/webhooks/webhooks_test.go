@@ -0,0 +1,364 @@
+package webhooks
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	"github.com/grafana/grafana/pkg/services/annotations/annotationstest"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/webhooks/signing"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+	"github.com/grafana/grafana/pkg/web/webtest"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+const (
+	githubReleasePayload = `{
+  "action": "published",
+  "release": {"name": "v1.2.0", "published_at": "2024-01-02T10:00:00Z"},
+  "repository": {"full_name": "grafana/app"}
+}`
+	gitlabPipelinePayload = `{
+  "object_kind": "pipeline",
+  "object_attributes": {"status": "success", "created_at": "2024-01-02 10:00:00 UTC", "finished_at": "2024-01-02 10:05:00 UTC"},
+  "project": {"path_with_namespace": "grafana/app"}
+}`
+	genericPayload = `{"time": 1704189600000, "timeEnd": 1704189900, "text": "Deployed app", "tags": ["deploy", "prod"]}`
+)
+
+func TestMappingApply(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		source   Source
+		mapping  Mapping
+		event    Event
+		expected mappedEvent
+	}{
+		{
+			desc:   "default github mapping",
+			source: SourceGitHub,
+			event:  Event{Name: "release", Payload: []byte(githubReleasePayload)},
+			expected: mappedEvent{
+				Text: "GitHub release published in grafana/app: v1.2.0",
+				Tags: []string{"github", "release", "grafana/app"},
+			},
+		},
+		{
+			desc:   "default gitlab mapping",
+			source: SourceGitLab,
+			event:  Event{Name: "Pipeline Hook", Payload: []byte(gitlabPipelinePayload)},
+			expected: mappedEvent{
+				Text: "GitLab pipeline in grafana/app: success",
+				Tags: []string{"gitlab", "pipeline", "grafana/app"},
+			},
+		},
+		{
+			desc:   "default generic mapping",
+			source: SourceGeneric,
+			event:  Event{Payload: []byte(genericPayload)},
+			expected: mappedEvent{
+				Time:    1704189600000,
+				TimeEnd: 1704189900000,
+				Text:    "Deployed app",
+				Tags:    []string{"deploy", "prod"},
+			},
+		},
+		{
+			desc:   "custom mapping",
+			source: SourceGitLab,
+			mapping: Mapping{
+				Time:    `{{ get "object_attributes.created_at" }}`,
+				TimeEnd: `{{ get "object_attributes.finished_at" }}`,
+				Tags:    []string{"ci", `{{ .project.path_with_namespace }}, {{ get "object_attributes.status" }},ci`, `{{ get "missing" }}`},
+			},
+			event: Event{Name: "Pipeline Hook", Payload: []byte(gitlabPipelinePayload)},
+			expected: mappedEvent{
+				Time:    1704189600000,
+				TimeEnd: 1704189900000,
+				Text:    "GitLab pipeline in grafana/app: success",
+				Tags:    []string{"ci", "grafana/app", "success"},
+			},
+		},
+		{
+			desc:     "text filtering the events",
+			source:   SourceGitHub,
+			mapping:  Mapping{Text: `{{ if eq event "deployment" }}Deployment{{ end }}`},
+			event:    Event{Name: "release", Payload: []byte(githubReleasePayload)},
+			expected: mappedEvent{Tags: []string{"github", "release", "grafana/app"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			templates, err := parseMapping(tc.mapping.withDefaults(tc.source))
+			require.NoError(t, err)
+
+			mapped, err := templates.apply(tc.event)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, mapped)
+		})
+	}
+
+	t.Run("fails with invalid payloads and times", func(t *testing.T) {
+		templates, err := parseMapping(defaultMappings[SourceGeneric])
+		require.NoError(t, err)
+
+		_, err = templates.apply(Event{Payload: []byte(`{"text": `)})
+		require.ErrorIs(t, err, ErrInvalidPayload)
+
+		_, err = templates.apply(Event{Payload: []byte(`{"text": "Deployed", "time": "yesterday"}`)})
+		require.ErrorIs(t, err, ErrInvalidPayload)
+	})
+}
+
+func TestGet(t *testing.T) {
+	payload := map[string]any{
+		"commits": []any{map[string]any{"id": "a1"}, map[string]any{"id": "b2"}},
+		"labels":  []any{"bug", "ui"},
+		"user":    map[string]any{"name": "grot"},
+		"draft":   false,
+	}
+
+	assert.Equal(t, "b2", get(payload, "commits.1.id"))
+	assert.Equal(t, "bug,ui", get(payload, "labels"))
+	assert.Equal(t, `{"name":"grot"}`, get(payload, "user"))
+	assert.Equal(t, "false", get(payload, "draft"))
+	assert.Equal(t, "", get(payload, "commits.2.id"))
+	assert.Equal(t, "", get(payload, "user.name.first"))
+	assert.Equal(t, "", get(payload, "missing"))
+}
+
+func TestParseTime(t *testing.T) {
+	expected := time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC).UnixMilli()
+	for _, s := range []string{"1704189600", "1704189600000", "2024-01-02T10:00:00Z", "2024-01-02T11:00:00+01:00", "2024-01-02 10:00:00 UTC", "2024-01-02 12:00:00 +0200"} {
+		epoch, err := parseTime(s)
+		require.NoError(t, err, s)
+		assert.Equal(t, expected, epoch, s)
+	}
+
+	epoch, err := parseTime("")
+	require.NoError(t, err)
+	assert.Zero(t, epoch)
+
+	_, err = parseTime("02/01/2024")
+	require.ErrorIs(t, err, ErrInvalidPayload)
+}
+
+func TestVerify(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	body := []byte("Hello, World!")
+
+	t.Run("github", func(t *testing.T) {
+		// example of the GitHub documentation
+		header := http.Header{}
+		header.Set(headerGitHubSignature, "sha256=757107ea0eb2509fc211221cce984b8a37570b6d7586c22c46f4379c8b043e17")
+		require.NoError(t, verify(SourceGitHub, "It's a Secret to Everybody", header, body, now))
+		require.ErrorIs(t, verify(SourceGitHub, "other secret", header, body, now), ErrInvalidSignature)
+		require.ErrorIs(t, verify(SourceGitHub, "It's a Secret to Everybody", http.Header{}, body, now), ErrInvalidSignature)
+	})
+
+	t.Run("gitlab", func(t *testing.T) {
+		header := http.Header{}
+		header.Set(headerGitLabToken, "s3cr3t")
+		require.NoError(t, verify(SourceGitLab, "s3cr3t", header, body, now))
+		require.ErrorIs(t, verify(SourceGitLab, "other secret", header, body, now), ErrInvalidSignature)
+	})
+
+	t.Run("generic", func(t *testing.T) {
+		header := http.Header{}
+		header.Set(signing.HeaderSignatureTimestamp, strconv.FormatInt(now.Unix(), 10))
+		header.Set(signing.HeaderSignature, signing.Sign("s3cr3t", now.Unix(), body))
+		require.NoError(t, verify(SourceGeneric, "s3cr3t", header, body, now))
+		require.NoError(t, verify(SourceGeneric, "s3cr3t", header, body, now.Add(time.Minute)))
+		require.ErrorIs(t, verify(SourceGeneric, "s3cr3t", header, []byte("Hello"), now), ErrInvalidSignature)
+		require.ErrorIs(t, verify(SourceGeneric, "s3cr3t", header, body, now.Add(time.Hour)), ErrInvalidSignature)
+	})
+}
+
+func TestValidateWebhook(t *testing.T) {
+	valid := CreateWebhookCommand{Name: "releases", Source: SourceGitHub, DashboardUID: "app", PanelID: 2, Mapping: Mapping{Text: `{{ get "release.name" }}`}}
+	require.NoError(t, valid.Validate())
+
+	invalid := valid
+	invalid.Name = " "
+	require.ErrorIs(t, invalid.Validate(), ErrInvalidName)
+
+	invalid = valid
+	invalid.Source = "bitbucket"
+	require.ErrorIs(t, invalid.Validate(), ErrInvalidSource)
+
+	invalid = valid
+	invalid.DashboardUID = ""
+	require.ErrorIs(t, invalid.Validate(), ErrInvalidScope)
+
+	invalid = valid
+	invalid.Mapping.Tags = []string{`{{ get "release.name" }`}
+	require.ErrorIs(t, invalid.Validate(), ErrInvalidMapping)
+
+	invalid = valid
+	invalid.Mapping.Text = `{{ unknown }}`
+	require.ErrorIs(t, invalid.Validate(), ErrInvalidMapping)
+}
+
+func TestIntegrationAnnotationWebhooks(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	now := time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC)
+
+	dashboardService := dashboards.NewFakeDashboardService(t)
+	dashboardService.On("GetDashboard", mock.Anything, &dashboards.GetDashboardQuery{UID: "app", OrgID: 1}).Return(&dashboards.Dashboard{ID: 7, UID: "app", OrgID: 1}, nil).Maybe()
+	dashboardService.On("GetDashboard", mock.Anything, mock.Anything).Return(nil, dashboards.ErrDashboardNotFound).Maybe()
+	annotationsRepo := annotationstest.NewFakeAnnotationsRepo()
+	s := &WebhooksService{
+		db:               db.InitTestDB(t),
+		annotationsRepo:  annotationsRepo,
+		dashboardService: dashboardService,
+		secrets:          fakes.NewFakeSecretsService(),
+		log:              log.New("test-logger"),
+		now:              func() time.Time { return now },
+	}
+
+	t.Run("should add the annotations of the signed events to the dashboard", func(t *testing.T) {
+		webhook, secret, err := s.CreateWebhook(ctx, CreateWebhookCommand{OrgID: 1, Name: "releases", Source: SourceGitHub, DashboardUID: "app", PanelID: 2})
+		require.NoError(t, err)
+		require.NotEmpty(t, secret)
+
+		header := http.Header{}
+		header.Set(headerGitHubEvent, "release")
+		header.Set(headerGitHubDelivery, "72d3162e-cc78-11e3-81ab-4c9367dc0958")
+		header.Set(headerGitHubSignature, signing.HMACSHA256(secret, []byte(githubReleasePayload)))
+
+		status, err := s.ReceiveEvent(ctx, webhook.UID, header, []byte(githubReleasePayload))
+		require.NoError(t, err)
+		require.Equal(t, EventStatusAdded, status)
+
+		require.Equal(t, 1, annotationsRepo.Len())
+		for _, item := range annotationsRepo.Items() {
+			assert.Equal(t, int64(1), item.OrgID)
+			assert.Equal(t, int64(7), item.DashboardID)
+			assert.Equal(t, int64(2), item.PanelID)
+			assert.Equal(t, "GitHub release published in grafana/app: v1.2.0", item.Text)
+			assert.Equal(t, []string{"github", "release", "grafana/app"}, item.Tags)
+			assert.Equal(t, now.UnixMilli(), item.Epoch)
+			assert.Equal(t, webhook.UID, item.Data.Get("webhookUid").MustString())
+		}
+
+		t.Run("once per delivery", func(t *testing.T) {
+			status, err := s.ReceiveEvent(ctx, webhook.UID, header, []byte(githubReleasePayload))
+			require.NoError(t, err)
+			require.Equal(t, EventStatusDuplicate, status)
+			require.Equal(t, 1, annotationsRepo.Len())
+		})
+
+		t.Run("and ignore the ping events", func(t *testing.T) {
+			header.Set(headerGitHubEvent, "ping")
+			header.Set(headerGitHubDelivery, "ping-delivery")
+			status, err := s.ReceiveEvent(ctx, webhook.UID, header, []byte(githubReleasePayload))
+			require.NoError(t, err)
+			require.Equal(t, EventStatusIgnored, status)
+			require.Equal(t, 1, annotationsRepo.Len())
+		})
+
+		t.Run("and reject the events which aren't signed with the secret", func(t *testing.T) {
+			header.Set(headerGitHubEvent, "release")
+			header.Set(headerGitHubSignature, signing.HMACSHA256("other secret", []byte(githubReleasePayload)))
+			_, err := s.ReceiveEvent(ctx, webhook.UID, header, []byte(githubReleasePayload))
+			require.ErrorIs(t, err, ErrInvalidSignature)
+		})
+	})
+
+	t.Run("should add organization annotations of generic events", func(t *testing.T) {
+		webhook, _, err := s.CreateWebhook(ctx, CreateWebhookCommand{OrgID: 1, Name: "deployments", Source: SourceGeneric, Secret: "s3cr3t"})
+		require.NoError(t, err)
+
+		header := http.Header{}
+		header.Set(signing.HeaderSignatureTimestamp, strconv.FormatInt(now.Unix(), 10))
+		header.Set(signing.HeaderSignature, signing.Sign("s3cr3t", now.Unix(), []byte(genericPayload)))
+		status, err := s.ReceiveEvent(ctx, webhook.UID, header, []byte(genericPayload))
+		require.NoError(t, err)
+		require.Equal(t, EventStatusAdded, status)
+
+		require.Equal(t, 2, annotationsRepo.Len())
+		item := annotationsRepo.Items()[2]
+		assert.Equal(t, int64(0), item.DashboardID)
+		assert.Equal(t, "Deployed app", item.Text)
+		assert.Equal(t, []string{"deploy", "prod"}, item.Tags)
+		assert.Equal(t, int64(1704189600000), item.Epoch)
+		assert.Equal(t, int64(1704189900000), item.EpochEnd)
+		assert.Nil(t, item.IdempotencyKey)
+	})
+
+	t.Run("should manage the webhooks of the organization", func(t *testing.T) {
+		_, _, err := s.CreateWebhook(ctx, CreateWebhookCommand{OrgID: 1, Name: "missing", Source: SourceGitHub, DashboardUID: "missing"})
+		require.ErrorIs(t, err, ErrDashboardNotFound)
+
+		webhook, _, err := s.CreateWebhook(ctx, CreateWebhookCommand{OrgID: 2, Name: "merges", Source: SourceGitLab, Secret: "s3cr3t"})
+		require.NoError(t, err)
+
+		_, err = s.GetWebhook(ctx, 1, webhook.UID)
+		require.ErrorIs(t, err, ErrWebhookNotFound)
+		webhooks, err := s.ListWebhooks(ctx, 2)
+		require.NoError(t, err)
+		require.Len(t, webhooks, 1)
+
+		updated, err := s.UpdateWebhook(ctx, UpdateWebhookCommand{UID: webhook.UID, OrgID: 2, Name: "merge requests", Source: SourceGitLab, Mapping: Mapping{Text: `{{ get "object_attributes.title" }}`}})
+		require.NoError(t, err)
+		require.Equal(t, "merge requests", updated.Name)
+		require.Equal(t, `{{ get "object_attributes.title" }}`, updated.Mapping.Text)
+
+		row, err := s.getWebhookRow(ctx, webhook.UID)
+		require.NoError(t, err)
+		secret, err := signing.DecryptSecret(ctx, s.secrets, row.Secret)
+		require.NoError(t, err)
+		require.Equal(t, "s3cr3t", secret)
+
+		require.ErrorIs(t, s.DeleteWebhook(ctx, 1, webhook.UID), ErrWebhookNotFound)
+		require.NoError(t, s.DeleteWebhook(ctx, 2, webhook.UID))
+		_, err = s.ReceiveEvent(ctx, webhook.UID, http.Header{}, []byte(gitlabPipelinePayload))
+		require.ErrorIs(t, err, ErrWebhookNotFound)
+	})
+}
+
+func TestWebhooksAPIAccessControl(t *testing.T) {
+	routeRegister := routing.NewRouteRegister()
+	s := &WebhooksService{
+		routeRegister: routeRegister,
+		accessControl: actest.FakeAccessControl{ExpectedEvaluate: false},
+		log:           log.New("test-logger"),
+	}
+	s.registerAPIEndpoints()
+	server := webtest.NewServer(t, routeRegister)
+
+	// the webhooks are only managed by the users with the permissions of the webhooks, not by a basic role
+	for _, req := range []*http.Request{
+		server.NewGetRequest("/api/annotations/webhooks"),
+		server.NewPostRequest("/api/annotations/webhooks", nil),
+		server.NewRequest(http.MethodDelete, "/api/annotations/webhooks/abc", nil),
+	} {
+		webtest.RequestWithSignedInUser(req, &user.SignedInUser{UserID: 1, OrgID: 1, OrgRole: org.RoleAdmin})
+		res, err := server.Send(req)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		assert.Equal(t, http.StatusForbidden, res.StatusCode, req.URL.Path)
+	}
+}
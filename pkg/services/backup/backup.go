@@ -0,0 +1,75 @@
+package backup
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/grafana/grafana/pkg/util/errutil"
+)
+
+// FormatVersion is the version of the archive layout written by Backup.
+// Restore refuses archives written with a different layout.
+const FormatVersion = 2
+
+var (
+	ErrUnsupportedFormat   = errutil.BadRequest("backup.unsupportedFormat", errutil.WithPublicMessage("Backup archive format is not supported"))
+	ErrIncompatibleVersion = errutil.BadRequest("backup.incompatibleVersion", errutil.WithPublicMessage("Backup archive was created by a newer version of Grafana"))
+	ErrMissingManifest     = errutil.BadRequest("backup.missingManifest", errutil.WithPublicMessage("Backup archive does not contain a manifest"))
+	ErrMissingProvider     = errutil.BadRequest("backup.missingProvider", errutil.WithPublicMessage("Backup archive uses an encryption provider that is not configured"))
+	ErrIncompatibleSchema  = errutil.BadRequest("backup.incompatibleSchema", errutil.WithPublicMessage("Backup archive was created with different database migrations"))
+)
+
+// Service creates and restores point-in-time archives of the Grafana state.
+type Service interface {
+	// Backup writes a gzipped tar archive with a consistent snapshot of the
+	// database, the data key metadata, the provisioned file references and the
+	// installed plugins to w.
+	Backup(ctx context.Context, w io.Writer) (*Manifest, error)
+	// Restore replaces the database contents with the ones found in the archive
+	// read from r. The archive must have been created with the migrations that
+	// were applied to the database. It must not be used while a Grafana server
+	// is running against the same database.
+	Restore(ctx context.Context, r io.Reader) (*Manifest, error)
+}
+
+// Manifest describes the contents of a backup archive.
+type Manifest struct {
+	FormatVersion  int       `json:"formatVersion"`
+	GrafanaVersion string    `json:"grafanaVersion"`
+	DatabaseType   string    `json:"databaseType"`
+	CreatedAt      time.Time `json:"createdAt"`
+	Tables         []string  `json:"tables"`
+	// Migrations are the IDs of the applied migrations of each migration log
+	// table, which are not part of the archived tables.
+	Migrations map[string][]string `json:"migrations"`
+}
+
+// DataKeyMetadata holds everything about a data encryption key except for
+// the encrypted key itself.
+type DataKeyMetadata struct {
+	ID       string    `json:"id"`
+	Label    string    `json:"label"`
+	Scope    string    `json:"scope"`
+	Provider string    `json:"provider"`
+	Active   bool      `json:"active"`
+	Created  time.Time `json:"created"`
+	Updated  time.Time `json:"updated"`
+}
+
+// ProvisionedFile references a dashboard that was provisioned from a file.
+type ProvisionedFile struct {
+	Name        string `json:"name"`
+	DashboardID int64  `json:"dashboardId"`
+	ExternalID  string `json:"externalId"`
+	CheckSum    string `json:"checkSum"`
+	Updated     int64  `json:"updated"`
+}
+
+// PluginInfo describes a plugin that was installed when the backup was taken.
+type PluginInfo struct {
+	ID      string `json:"id"`
+	Version string `json:"version"`
+	Type    string `json:"type"`
+	Class   string `json:"class"`
+}
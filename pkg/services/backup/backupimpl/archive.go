@@ -0,0 +1,284 @@
+package backupimpl
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"xorm.io/core"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+const (
+	kindBinary = "binary"
+	kindTime   = "time"
+	kindBool   = "bool"
+)
+
+// tableHeader is the first line of every table file in the archive. Every
+// following line is a JSON array with the values of one row, in the order
+// of the columns listed in the header.
+type tableHeader struct {
+	Table   string         `json:"table"`
+	Columns []columnHeader `json:"columns"`
+}
+
+type columnHeader struct {
+	Name string `json:"name"`
+	// Kind tells how values of the column were encoded when it can't be
+	// inferred from the JSON value alone.
+	Kind string `json:"kind,omitempty"`
+}
+
+func columnKind(col *core.Column) string {
+	switch {
+	case col.SQLType.IsBlob():
+		return kindBinary
+	case col.SQLType.IsTime():
+		return kindTime
+	case col.SQLType.Name == core.Bool || col.SQLType.Name == core.Boolean:
+		return kindBool
+	}
+	return ""
+}
+
+func writeJSON(tw *tar.Writer, name string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", name, err)
+	}
+
+	if err := tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    int64(0o600),
+		Size:    int64(len(data)),
+		ModTime: time.Now(),
+	}); err != nil {
+		return err
+	}
+
+	_, err = io.Copy(tw, bytes.NewReader(data))
+	return err
+}
+
+// writeTable dumps all rows of a table to a temporary file first, as tar
+// entries need to know their size up front.
+func (s *Service) writeTable(ctx context.Context, tw *tar.Writer, q queryer, tmpDir string, table *core.Table) error {
+	f, err := os.CreateTemp(tmpDir, "table-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+	}()
+
+	columns := table.Columns()
+	header := tableHeader{Table: table.Name, Columns: make([]columnHeader, 0, len(columns))}
+	quoted := make([]string, 0, len(columns))
+	for _, col := range columns {
+		header.Columns = append(header.Columns, columnHeader{Name: col.Name, Kind: columnKind(col)})
+		quoted = append(quoted, s.store.Quote(col.Name))
+	}
+
+	enc := json.NewEncoder(f)
+	if err := enc.Encode(header); err != nil {
+		return err
+	}
+
+	rows, err := q.QueryContext(ctx, fmt.Sprintf("SELECT %s FROM %s", strings.Join(quoted, ", "), s.store.Quote(table.Name)))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rows.Close() }()
+
+	values := make([]any, len(columns))
+	pointers := make([]any, len(columns))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+
+	for rows.Next() {
+		if err := rows.Scan(pointers...); err != nil {
+			return err
+		}
+
+		row := make([]any, len(values))
+		for i, v := range values {
+			row[i] = encodeValue(v, header.Columns[i].Kind)
+		}
+		if err := enc.Encode(row); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	if err := tw.WriteHeader(&tar.Header{
+		Name:    databaseDir + table.Name + tableFileSuffix,
+		Mode:    int64(0o600),
+		Size:    info.Size(),
+		ModTime: time.Now(),
+	}); err != nil {
+		return err
+	}
+
+	_, err = io.Copy(tw, f)
+	return err
+}
+
+// restoreTable replaces all rows of the target table with the ones read
+// from r. Columns that no longer exist in the target table are ignored.
+func (s *Service) restoreTable(sess *db.Session, r io.Reader, target *core.Table) error {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+
+	var header tableHeader
+	if err := dec.Decode(&header); err != nil {
+		return fmt.Errorf("failed to read table header: %w", err)
+	}
+
+	indexes := make([]int, 0, len(header.Columns))
+	targetColumns := make([]*core.Column, 0, len(header.Columns))
+	quoted := make([]string, 0, len(header.Columns))
+	for i, col := range header.Columns {
+		targetCol := target.GetColumn(col.Name)
+		if targetCol == nil {
+			s.log.Warn("Skipping column that does not exist in the target database", "table", target.Name, "column", col.Name)
+			continue
+		}
+		indexes = append(indexes, i)
+		targetColumns = append(targetColumns, targetCol)
+		quoted = append(quoted, s.store.Quote(col.Name))
+	}
+
+	if _, err := sess.Exec(fmt.Sprintf("DELETE FROM %s", s.store.Quote(target.Name))); err != nil {
+		return err
+	}
+
+	if len(indexes) == 0 {
+		return nil
+	}
+
+	placeholders := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(indexes)), ", ") + ")"
+	// keep the number of bind variables per statement well below the limits
+	// of all supported databases
+	batchSize := 500 / len(indexes)
+	if batchSize < 1 {
+		batchSize = 1
+	}
+
+	insert := func(args []any, count int) error {
+		if count == 0 {
+			return nil
+		}
+		rawSQL := fmt.Sprintf("INSERT INTO %s (%s) VALUES %s",
+			s.store.Quote(target.Name), strings.Join(quoted, ", "),
+			strings.TrimSuffix(strings.Repeat(placeholders+", ", count), ", "))
+		_, err := sess.Exec(append([]any{rawSQL}, args...)...)
+		return err
+	}
+
+	args := make([]any, 0, batchSize*len(indexes))
+	count := 0
+	for dec.More() {
+		var row []any
+		if err := dec.Decode(&row); err != nil {
+			return fmt.Errorf("failed to read row: %w", err)
+		}
+		if len(row) != len(header.Columns) {
+			return fmt.Errorf("row has %d values, expected %d", len(row), len(header.Columns))
+		}
+
+		for j, i := range indexes {
+			v, err := decodeValue(row[i], header.Columns[i].Kind, targetColumns[j])
+			if err != nil {
+				return fmt.Errorf("failed to decode column %q: %w", header.Columns[i].Name, err)
+			}
+			args = append(args, v)
+		}
+
+		count++
+		if count == batchSize {
+			if err := insert(args, count); err != nil {
+				return err
+			}
+			args = args[:0]
+			count = 0
+		}
+	}
+	if err := insert(args, count); err != nil {
+		return err
+	}
+
+	// Postgres doesn't advance sequences when rows are inserted with an
+	// explicit primary key.
+	if string(s.store.GetDBType()) == migrator.Postgres && target.AutoIncrement != "" {
+		if _, err := sess.Exec(fmt.Sprintf("SELECT setval(pg_get_serial_sequence('%s', '%s'), COALESCE(MAX(%s), 0) + 1, false) FROM %s",
+			target.Name, target.AutoIncrement, s.store.Quote(target.AutoIncrement), s.store.Quote(target.Name))); err != nil {
+			return fmt.Errorf("failed to sync primary key sequence: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func encodeValue(v any, kind string) any {
+	switch val := v.(type) {
+	case []byte:
+		if kind == kindBinary {
+			return base64.StdEncoding.EncodeToString(val)
+		}
+		return string(val)
+	case int64:
+		if kind == kindBool {
+			return val != 0
+		}
+	}
+	return v
+}
+
+func decodeValue(v any, kind string, target *core.Column) (any, error) {
+	targetIsBool := target.SQLType.Name == core.Bool || target.SQLType.Name == core.Boolean
+
+	switch val := v.(type) {
+	case string:
+		switch kind {
+		case kindBinary:
+			return base64.StdEncoding.DecodeString(val)
+		case kindTime:
+			if t, err := time.Parse(time.RFC3339Nano, val); err == nil {
+				return t, nil
+			}
+		}
+		return val, nil
+	case json.Number:
+		i, err := val.Int64()
+		if err != nil {
+			return val.Float64()
+		}
+		if targetIsBool {
+			return i != 0, nil
+		}
+		return i, nil
+	}
+	return v, nil
+}
@@ -0,0 +1,492 @@
+package backupimpl
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-version"
+	"xorm.io/core"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/backup"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/services/secrets/manager"
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	manifestFile     = "manifest.json"
+	dataKeysFile     = "data_keys.json"
+	provisioningFile = "provisioning.json"
+	pluginsFile      = "plugins.json"
+	databaseDir      = "database/"
+	tableFileSuffix  = ".ndjson"
+)
+
+// providerRegistry is implemented by the secrets service and lists the
+// encryption providers that are currently configured.
+type providerRegistry interface {
+	GetProviders() map[secrets.ProviderID]secrets.Provider
+}
+
+// queryer is implemented by both *sql.DB and *sql.Tx so that snapshots can be
+// read the same way regardless of how they were taken.
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+type Service struct {
+	cfg         *setting.Cfg
+	store       db.DB
+	pluginStore pluginstore.Store
+	providers   providerRegistry
+	log         log.Logger
+	now         func() time.Time
+}
+
+var _ backup.Service = (*Service)(nil)
+
+func ProvideService(cfg *setting.Cfg, store db.DB, pluginStore pluginstore.Store, secretsService *manager.SecretsService) *Service {
+	return &Service{
+		cfg:         cfg,
+		store:       store,
+		pluginStore: pluginStore,
+		providers:   secretsService,
+		log:         log.New("backup"),
+		now:         time.Now,
+	}
+}
+
+func (s *Service) Backup(ctx context.Context, w io.Writer) (*backup.Manifest, error) {
+	tables, err := s.tables()
+	if err != nil {
+		return nil, err
+	}
+
+	tmpDir, err := os.MkdirTemp("", "grafana-backup-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer func() {
+		if err := os.RemoveAll(tmpDir); err != nil {
+			s.log.Warn("Failed to remove temporary backup directory", "path", tmpDir, "error", err)
+		}
+	}()
+
+	manifest := &backup.Manifest{
+		FormatVersion:  backup.FormatVersion,
+		GrafanaVersion: s.cfg.BuildVersion,
+		DatabaseType:   string(s.store.GetDBType()),
+		CreatedAt:      s.now().UTC(),
+	}
+	for _, table := range tables {
+		manifest.Tables = append(manifest.Tables, table.Name)
+	}
+
+	zw := gzip.NewWriter(w)
+	tw := tar.NewWriter(zw)
+
+	logTables, err := s.migrationLogTables()
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.snapshot(ctx, tmpDir, func(q queryer) error {
+		migrations, err := readMigrations(ctx, q, logTables, s.store.GetDialect())
+		if err != nil {
+			return err
+		}
+		manifest.Migrations = migrations
+
+		dataKeys, err := readDataKeys(ctx, q)
+		if err != nil {
+			return err
+		}
+		provisioned, err := readProvisionedFiles(ctx, q)
+		if err != nil {
+			return err
+		}
+
+		// The manifest and the data key metadata go first, so that restoring
+		// can be aborted before any table has been touched.
+		files := []struct {
+			name  string
+			value any
+		}{
+			{name: manifestFile, value: manifest},
+			{name: dataKeysFile, value: dataKeys},
+			{name: provisioningFile, value: provisioned},
+			{name: pluginsFile, value: s.plugins(ctx)},
+		}
+		for _, f := range files {
+			if err := writeJSON(tw, f.name, f.value); err != nil {
+				return err
+			}
+		}
+
+		for _, table := range tables {
+			if err := s.writeTable(ctx, tw, q, tmpDir, table); err != nil {
+				return fmt.Errorf("failed to back up table %q: %w", table.Name, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Created backup", "tables", len(tables), "databaseType", manifest.DatabaseType)
+	return manifest, nil
+}
+
+func (s *Service) Restore(ctx context.Context, r io.Reader) (*backup.Manifest, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open backup archive: %w", err)
+	}
+	defer func() { _ = zr.Close() }()
+	tr := tar.NewReader(zr)
+
+	// The manifest is the first file of the archive, so that the archive can be
+	// rejected before the restore transaction is opened.
+	header, err := tr.Next()
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to read backup archive: %w", err)
+	}
+	if header == nil || header.Name != manifestFile {
+		return nil, backup.ErrMissingManifest.Errorf("backup archive does not start with %s", manifestFile)
+	}
+	manifest := &backup.Manifest{}
+	if err := json.NewDecoder(tr).Decode(manifest); err != nil {
+		return nil, fmt.Errorf("failed to read backup manifest: %w", err)
+	}
+	if err := s.validateManifest(manifest); err != nil {
+		return nil, err
+	}
+	if err := s.validateMigrations(ctx, manifest); err != nil {
+		return nil, err
+	}
+
+	tables, err := s.tables()
+	if err != nil {
+		return nil, err
+	}
+	targets := make(map[string]*core.Table, len(tables))
+	for _, table := range tables {
+		targets[table.Name] = table
+	}
+
+	err = s.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		for {
+			header, err := tr.Next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return fmt.Errorf("failed to read backup archive: %w", err)
+			}
+
+			switch {
+			case header.Name == dataKeysFile:
+				var dataKeys []backup.DataKeyMetadata
+				if err := json.NewDecoder(tr).Decode(&dataKeys); err != nil {
+					return fmt.Errorf("failed to read data key metadata: %w", err)
+				}
+				if err := s.validateDataKeys(dataKeys); err != nil {
+					return err
+				}
+			case strings.HasPrefix(header.Name, databaseDir):
+				name := strings.TrimSuffix(strings.TrimPrefix(header.Name, databaseDir), tableFileSuffix)
+				target, ok := targets[name]
+				if !ok {
+					s.log.Warn("Skipping table that does not exist in the target database", "table", name)
+					continue
+				}
+				if err := s.restoreTable(sess, tr, target); err != nil {
+					return fmt.Errorf("failed to restore table %q: %w", name, err)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Restored backup", "grafanaVersion", manifest.GrafanaVersion, "createdAt", manifest.CreatedAt)
+	return manifest, nil
+}
+
+func (s *Service) validateManifest(manifest *backup.Manifest) error {
+	if manifest.FormatVersion != backup.FormatVersion {
+		return backup.ErrUnsupportedFormat.Errorf("unsupported backup format version %d", manifest.FormatVersion)
+	}
+
+	archived, err := version.NewVersion(manifest.GrafanaVersion)
+	if err != nil {
+		return backup.ErrIncompatibleVersion.Errorf("invalid Grafana version %q in backup manifest: %w", manifest.GrafanaVersion, err)
+	}
+	current, err := version.NewVersion(s.cfg.BuildVersion)
+	if err != nil {
+		// development builds may not have a proper version
+		s.log.Warn("Unable to parse running Grafana version, skipping version check", "version", s.cfg.BuildVersion)
+		return nil
+	}
+
+	// The schema of a newer Grafana version can't be migrated backwards.
+	if archived.Core().GreaterThan(current.Core()) {
+		return backup.ErrIncompatibleVersion.Errorf("backup was created by Grafana %s and can't be restored into Grafana %s", manifest.GrafanaVersion, s.cfg.BuildVersion)
+	}
+	return nil
+}
+
+// validateMigrations checks that the archive was created with the migrations
+// that were applied to the database, since the restored tables must have the
+// same schema and the migration logs are not restored.
+func (s *Service) validateMigrations(ctx context.Context, manifest *backup.Manifest) error {
+	logTables, err := s.migrationLogTables()
+	if err != nil {
+		return err
+	}
+	current, err := readMigrations(ctx, s.store.GetEngine().DB().DB, logTables, s.store.GetDialect())
+	if err != nil {
+		return err
+	}
+
+	for _, logTable := range logTables {
+		archived := make(map[string]bool, len(manifest.Migrations[logTable]))
+		for _, id := range manifest.Migrations[logTable] {
+			archived[id] = true
+		}
+		for _, id := range current[logTable] {
+			if !archived[id] {
+				return backup.ErrIncompatibleSchema.Errorf("backup was created without the migration %q of the database", id)
+			}
+			delete(archived, id)
+		}
+		if len(archived) > 0 {
+			extra := make([]string, 0, len(archived))
+			for id := range archived {
+				extra = append(extra, id)
+			}
+			sort.Strings(extra)
+			return backup.ErrIncompatibleSchema.Errorf("backup was created with the migration %q which the database does not have", extra[0])
+		}
+	}
+	for logTable := range manifest.Migrations {
+		if _, ok := current[logTable]; !ok {
+			return backup.ErrIncompatibleSchema.Errorf("backup was created with the migrations of %q which the database does not have", logTable)
+		}
+	}
+	return nil
+}
+
+func (s *Service) validateDataKeys(dataKeys []backup.DataKeyMetadata) error {
+	if s.providers == nil {
+		return nil
+	}
+
+	providers := s.providers.GetProviders()
+	for _, dk := range dataKeys {
+		if _, ok := providers[secrets.ProviderID(dk.Provider)]; !ok {
+			return backup.ErrMissingProvider.Errorf("data key %q is encrypted with provider %q which is not configured", dk.ID, dk.Provider)
+		}
+	}
+	return nil
+}
+
+// tables returns the tables of the Grafana database sorted by name.
+func (s *Service) tables() ([]*core.Table, error) {
+	tables, err := s.store.GetEngine().DBMetas()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read database tables: %w", err)
+	}
+
+	result := make([]*core.Table, 0, len(tables))
+	for _, table := range tables {
+		if table.Name == "" || isInternalTable(table.Name) || isMigrationLogTable(table.Name) {
+			continue
+		}
+		result = append(result, table)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result, nil
+}
+
+// migrationLogTables returns the names of the tables which log the migrations
+// applied to the database, sorted by name.
+func (s *Service) migrationLogTables() ([]string, error) {
+	tables, err := s.store.GetEngine().DBMetas()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read database tables: %w", err)
+	}
+
+	result := []string{}
+	for _, table := range tables {
+		if isMigrationLogTable(table.Name) {
+			result = append(result, table.Name)
+		}
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+// snapshot calls fn with a consistent, read-only view of the database.
+// SQLite is copied with the online backup API, while MySQL and Postgres
+// are read within a single repeatable read transaction.
+func (s *Service) snapshot(ctx context.Context, tmpDir string, fn func(q queryer) error) error {
+	if string(s.store.GetDBType()) == migrator.SQLite {
+		path := filepath.Join(tmpDir, "grafana.db")
+		if err := s.sqliteSnapshot(ctx, path); err != nil {
+			return fmt.Errorf("failed to create SQLite snapshot: %w", err)
+		}
+
+		snapshot, err := sql.Open("sqlite3", fmt.Sprintf("file:%s?mode=ro", path))
+		if err != nil {
+			return err
+		}
+		defer func() { _ = snapshot.Close() }()
+
+		return fn(snapshot)
+	}
+
+	tx, err := s.store.GetEngine().DB().DB.BeginTx(ctx, &sql.TxOptions{
+		Isolation: sql.LevelRepeatableRead,
+		ReadOnly:  true,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start snapshot transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	return fn(tx)
+}
+
+func (s *Service) plugins(ctx context.Context) []backup.PluginInfo {
+	if s.pluginStore == nil {
+		return []backup.PluginInfo{}
+	}
+
+	result := []backup.PluginInfo{}
+	for _, p := range s.pluginStore.Plugins(ctx) {
+		if p.IsCorePlugin() {
+			continue
+		}
+		result = append(result, backup.PluginInfo{
+			ID:      p.ID,
+			Version: p.Info.Version,
+			Type:    string(p.Type),
+			Class:   string(p.Class),
+		})
+	}
+	return result
+}
+
+// readMigrations returns the IDs of the successful migrations of each migration
+// log table, sorted by ID.
+func readMigrations(ctx context.Context, q queryer, logTables []string, dialect migrator.Dialect) (map[string][]string, error) {
+	result := make(map[string][]string, len(logTables))
+	for _, logTable := range logTables {
+		ids, err := readMigrationIDs(ctx, q, logTable, dialect)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migrations of %q: %w", logTable, err)
+		}
+		result[logTable] = ids
+	}
+	return result, nil
+}
+
+func readMigrationIDs(ctx context.Context, q queryer, logTable string, dialect migrator.Dialect) ([]string, error) {
+	// the names of the migration log tables are read from the database, and the
+	// queries are not rewritten for the placeholders of each database
+	rows, err := q.QueryContext(ctx, "SELECT DISTINCT migration_id FROM "+dialect.Quote(logTable)+" WHERE success = "+dialect.BooleanStr(true))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids, rows.Err()
+}
+
+func readDataKeys(ctx context.Context, q queryer) ([]backup.DataKeyMetadata, error) {
+	rows, err := q.QueryContext(ctx, "SELECT name, label, scope, provider, active, created, updated FROM data_keys")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read data keys: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	result := []backup.DataKeyMetadata{}
+	for rows.Next() {
+		var dk backup.DataKeyMetadata
+		if err := rows.Scan(&dk.ID, &dk.Label, &dk.Scope, &dk.Provider, &dk.Active, &dk.Created, &dk.Updated); err != nil {
+			return nil, err
+		}
+		result = append(result, dk)
+	}
+	return result, rows.Err()
+}
+
+func readProvisionedFiles(ctx context.Context, q queryer) ([]backup.ProvisionedFile, error) {
+	rows, err := q.QueryContext(ctx, "SELECT name, dashboard_id, external_id, check_sum, updated FROM dashboard_provisioning")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read provisioned dashboards: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	result := []backup.ProvisionedFile{}
+	for rows.Next() {
+		var pf backup.ProvisionedFile
+		var checkSum sql.NullString
+		if err := rows.Scan(&pf.Name, &pf.DashboardID, &pf.ExternalID, &checkSum, &pf.Updated); err != nil {
+			return nil, err
+		}
+		pf.CheckSum = checkSum.String
+		result = append(result, pf)
+	}
+	return result, rows.Err()
+}
+
+// isInternalTable reports whether a table belongs to the database engine
+// rather than to Grafana.
+func isInternalTable(name string) bool {
+	return name == "sqlite_sequence" || name == "sqlite_stat1"
+}
+
+// isMigrationLogTable reports whether a table logs the migrations applied to
+// the database, which describe its schema rather than its contents.
+func isMigrationLogTable(name string) bool {
+	return name == "migration_log" || strings.HasSuffix(name, "_migration_log")
+}
@@ -0,0 +1,207 @@
+package backupimpl
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/backup"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+type fakeProviders map[secrets.ProviderID]secrets.Provider
+
+func (f fakeProviders) GetProviders() map[secrets.ProviderID]secrets.Provider {
+	return f
+}
+
+func TestIntegrationBackupAndRestore(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	store := db.InitTestDB(t)
+	store.Cfg.BuildVersion = "10.4.0"
+	createdAt := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	newService := func(providers fakeProviders) *Service {
+		return &Service{
+			cfg:       store.Cfg,
+			store:     store,
+			providers: providers,
+			log:       log.NewNopLogger(),
+			now:       func() time.Time { return createdAt },
+		}
+	}
+
+	ctx := context.Background()
+	dataKey := &secrets.DataKey{
+		Active:        true,
+		Id:            "key-1",
+		Label:         "2024-03-01/root@secretKey.v1",
+		Provider:      "secretKey.v1",
+		EncryptedData: []byte{0x00, 0xff, 0x10, 0x80},
+		Created:       createdAt,
+		Updated:       createdAt,
+	}
+	provisioned := &dashboards.DashboardProvisioning{
+		DashboardID: 10,
+		Name:        "default",
+		ExternalID:  "/etc/grafana/dashboards/home.json",
+		CheckSum:    "abc",
+		Updated:     createdAt.Unix(),
+	}
+	err := store.WithDbSession(ctx, func(sess *db.Session) error {
+		if _, err := sess.Table("data_keys").Insert(dataKey); err != nil {
+			return err
+		}
+		_, err := sess.Insert(provisioned)
+		return err
+	})
+	require.NoError(t, err)
+
+	svc := newService(fakeProviders{"secretKey.v1": nil})
+
+	var archive bytes.Buffer
+	manifest, err := svc.Backup(ctx, &archive)
+	require.NoError(t, err)
+	require.Equal(t, backup.FormatVersion, manifest.FormatVersion)
+	require.Equal(t, "10.4.0", manifest.GrafanaVersion)
+	require.Equal(t, createdAt, manifest.CreatedAt)
+	require.Contains(t, manifest.Tables, "data_keys")
+	require.Contains(t, manifest.Tables, "dashboard_provisioning")
+	require.NotContains(t, manifest.Tables, "migration_log")
+	require.NotEmpty(t, manifest.Migrations["migration_log"])
+
+	t.Run("restore replaces changes made after the backup", func(t *testing.T) {
+		err := store.WithDbSession(ctx, func(sess *db.Session) error {
+			if _, err := sess.Exec("DELETE FROM data_keys"); err != nil {
+				return err
+			}
+			_, err := sess.Insert(&dashboards.DashboardProvisioning{DashboardID: 11, Name: "other", ExternalID: "/tmp/other.json"})
+			return err
+		})
+		require.NoError(t, err)
+
+		restored, err := svc.Restore(ctx, bytes.NewReader(archive.Bytes()))
+		require.NoError(t, err)
+		require.Equal(t, manifest.Tables, restored.Tables)
+
+		var keys []*secrets.DataKey
+		var rows []*dashboards.DashboardProvisioning
+		err = store.WithDbSession(ctx, func(sess *db.Session) error {
+			if err := sess.Table("data_keys").Find(&keys); err != nil {
+				return err
+			}
+			return sess.Find(&rows)
+		})
+		require.NoError(t, err)
+
+		require.Len(t, keys, 1)
+		require.Equal(t, dataKey.Id, keys[0].Id)
+		require.Equal(t, dataKey.EncryptedData, keys[0].EncryptedData)
+		require.True(t, keys[0].Active)
+		require.Len(t, rows, 1)
+		require.Equal(t, provisioned.ExternalID, rows[0].ExternalID)
+		require.Equal(t, provisioned.DashboardID, rows[0].DashboardID)
+	})
+
+	t.Run("restore fails when an encryption provider is missing", func(t *testing.T) {
+		_, err := newService(fakeProviders{}).Restore(ctx, bytes.NewReader(archive.Bytes()))
+		require.ErrorIs(t, err, backup.ErrMissingProvider)
+	})
+
+	t.Run("restore fails before changing the database when the migrations differ", func(t *testing.T) {
+		err := store.WithDbSession(ctx, func(sess *db.Session) error {
+			if _, err := sess.Exec("DELETE FROM data_keys"); err != nil {
+				return err
+			}
+			_, err := sess.Exec("INSERT INTO migration_log (migration_id, sql, success, error, timestamp) VALUES (?, '', ?, '', ?)", "backup test migration", true, createdAt)
+			return err
+		})
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			err := store.WithDbSession(ctx, func(sess *db.Session) error {
+				_, err := sess.Exec("DELETE FROM migration_log WHERE migration_id = ?", "backup test migration")
+				return err
+			})
+			require.NoError(t, err)
+		})
+
+		_, err = svc.Restore(ctx, bytes.NewReader(archive.Bytes()))
+		require.ErrorIs(t, err, backup.ErrIncompatibleSchema)
+
+		var keys []*secrets.DataKey
+		err = store.WithDbSession(ctx, func(sess *db.Session) error {
+			return sess.Table("data_keys").Find(&keys)
+		})
+		require.NoError(t, err)
+		require.Empty(t, keys)
+	})
+
+	t.Run("restore fails for archives from newer versions", func(t *testing.T) {
+		store.Cfg.BuildVersion = "10.3.1"
+		t.Cleanup(func() { store.Cfg.BuildVersion = "10.4.0" })
+
+		_, err := svc.Restore(ctx, bytes.NewReader(archive.Bytes()))
+		require.ErrorIs(t, err, backup.ErrIncompatibleVersion)
+	})
+}
+
+func TestValidateManifest(t *testing.T) {
+	cfg := setting.NewCfg()
+	cfg.BuildVersion = "10.4.1"
+	svc := &Service{cfg: cfg, log: log.NewNopLogger()}
+
+	testCases := []struct {
+		desc     string
+		manifest backup.Manifest
+		err      error
+	}{
+		{
+			desc:     "same version",
+			manifest: backup.Manifest{FormatVersion: backup.FormatVersion, GrafanaVersion: "10.4.1"},
+		},
+		{
+			desc:     "older version",
+			manifest: backup.Manifest{FormatVersion: backup.FormatVersion, GrafanaVersion: "9.5.0"},
+		},
+		{
+			desc:     "pre-release of the same version",
+			manifest: backup.Manifest{FormatVersion: backup.FormatVersion, GrafanaVersion: "10.4.1-pre"},
+		},
+		{
+			desc:     "newer version",
+			manifest: backup.Manifest{FormatVersion: backup.FormatVersion, GrafanaVersion: "10.5.0"},
+			err:      backup.ErrIncompatibleVersion,
+		},
+		{
+			desc:     "unknown format",
+			manifest: backup.Manifest{FormatVersion: backup.FormatVersion + 1, GrafanaVersion: "10.4.1"},
+			err:      backup.ErrUnsupportedFormat,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			err := svc.validateManifest(&tc.manifest)
+			if tc.err == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, tc.err)
+		})
+	}
+}
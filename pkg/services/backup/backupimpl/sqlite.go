@@ -0,0 +1,80 @@
+package backupimpl
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// sqliteSnapshot copies the SQLite database to dest using the SQLite online
+// backup API, which produces a consistent copy even while Grafana keeps
+// writing to the database.
+func (s *Service) sqliteSnapshot(ctx context.Context, dest string) error {
+	srcConn, err := s.store.GetEngine().DB().DB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = srcConn.Close() }()
+
+	destDB, err := sql.Open("sqlite3", dest)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = destDB.Close() }()
+
+	destConn, err := destDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = destConn.Close() }()
+
+	supported := true
+	err = srcConn.Raw(func(srcRaw any) error {
+		src, ok := srcRaw.(*sqlite3.SQLiteConn)
+		if !ok {
+			supported = false
+			return nil
+		}
+
+		return destConn.Raw(func(destRaw any) error {
+			dst, ok := destRaw.(*sqlite3.SQLiteConn)
+			if !ok {
+				return fmt.Errorf("unexpected SQLite connection type %T", destRaw)
+			}
+
+			bk, err := dst.Backup("main", src, "main")
+			if err != nil {
+				return err
+			}
+
+			// copy all pages in a single step to get a consistent snapshot
+			if _, err := bk.Step(-1); err != nil {
+				_ = bk.Close()
+				return err
+			}
+			return bk.Finish()
+		})
+	})
+	if err != nil || supported {
+		return err
+	}
+
+	// The driver is wrapped when query instrumentation is enabled, which hides
+	// the SQLite connection. VACUUM INTO gives the same guarantees.
+	s.log.Debug("SQLite online backup API not available, falling back to VACUUM INTO")
+	if err := destConn.Close(); err != nil {
+		return err
+	}
+	if err := destDB.Close(); err != nil {
+		return err
+	}
+	// VACUUM INTO refuses to overwrite the file created by the connection above
+	if err := os.Remove(dest); err != nil {
+		return err
+	}
+	_, err = srcConn.ExecContext(ctx, "VACUUM INTO ?", dest)
+	return err
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"regexp"
 
 	"github.com/grafana/grafana/pkg/services/quota"
 )
@@ -18,6 +19,10 @@ var (
 	ErrInvalidTransformationType     = errors.New("invalid transformation type")
 	ErrTransformationNotNested       = errors.New("transformations must be nested under config")
 	ErrTransformationRegexReqExp     = errors.New("regex transformations require expression")
+	ErrTransformationJSONPathReqExp  = errors.New("jsonpath transformations require expression")
+	ErrTransformationInvalidInput    = errors.New("transformation input must be the mapValue or a named capture group of a previous transformation")
+	ErrExternalCorrelationReqURL     = errors.New("external correlations require a target url")
+	ErrInvalidCorrelationConfig      = errors.New("invalid correlation config")
 	ErrCorrelationsQuotaFailed       = errors.New("error getting correlations quota")
 	ErrCorrelationsQuotaReached      = errors.New("correlations quota reached")
 )
@@ -30,30 +35,74 @@ const (
 type CorrelationConfigType string
 
 type Transformation struct {
-	//Enum: regex,logfmt
+	//Enum: regex,logfmt,jsonpath,keyvalue
 	Type       string `json:"type"`
 	Expression string `json:"expression,omitempty"`
 	Field      string `json:"field,omitempty"`
-	MapValue   string `json:"mapValue,omitempty"`
+	// Variable extracted by a previous transformation used as the input instead of a field
+	Input    string `json:"input,omitempty"`
+	MapValue string `json:"mapValue,omitempty"`
+	// Delimiter of the pairs of keyvalue transformations, a space by default
+	Delimiter string `json:"delimiter,omitempty"`
+	// Separator of the keys and values of keyvalue transformations, "=" by default
+	Separator string `json:"separator,omitempty"`
 }
 
 const (
 	ConfigTypeQuery CorrelationConfigType = "query"
+	// ConfigTypeExternal is the type of the correlations linking to a URL outside of Grafana, such as
+	// a ticketing system. The URL is set in the "url" property of the target and can contain variables.
+	ConfigTypeExternal CorrelationConfigType = "external"
+)
+
+const (
+	TransformationTypeRegex    = "regex"
+	TransformationTypeLogfmt   = "logfmt"
+	TransformationTypeJSONPath = "jsonpath"
+	TransformationTypeKeyValue = "keyvalue"
 )
 
 func (t CorrelationConfigType) Validate() error {
-	if t != ConfigTypeQuery {
+	if t != ConfigTypeQuery && t != ConfigTypeExternal {
 		return fmt.Errorf("%s: \"%s\"", ErrInvalidConfigType, t)
 	}
 	return nil
 }
 
+// regexGroupName matches the named capture groups of the regular expressions of the regex transformations
+var regexGroupName = regexp.MustCompile(`\(\?P?<([A-Za-z_][A-Za-z0-9_]*)>`)
+
 func (t Transformations) Validate() error {
-	for _, v := range t {
-		if v.Type != "regex" && v.Type != "logfmt" {
+	// variables are only known when they are set by the mapValue of a transformation or the named capture groups of a
+	// regex transformation, the other ones depend on the results
+	variables := make(map[string]bool)
+	for i, v := range t {
+		switch v.Type {
+		case TransformationTypeRegex:
+			if len(v.Expression) == 0 {
+				return fmt.Errorf("%s: \"%s\"", ErrTransformationRegexReqExp, t)
+			}
+		case TransformationTypeJSONPath:
+			if len(v.Expression) == 0 {
+				return fmt.Errorf("%s: \"%s\"", ErrTransformationJSONPathReqExp, t)
+			}
+		case TransformationTypeLogfmt, TransformationTypeKeyValue:
+		default:
 			return fmt.Errorf("%s: \"%s\"", ErrInvalidTransformationType, t)
-		} else if v.Type == "regex" && len(v.Expression) == 0 {
-			return fmt.Errorf("%s: \"%s\"", ErrTransformationRegexReqExp, t)
+		}
+
+		// transformations are chained by using the variables extracted by the previous ones as their input
+		if v.Input != "" && (i == 0 || v.Field != "" || !variables[v.Input]) {
+			return fmt.Errorf("%s: \"%s\"", ErrTransformationInvalidInput, v.Input)
+		}
+
+		if v.MapValue != "" {
+			variables[v.MapValue] = true
+		}
+		if v.Type == TransformationTypeRegex {
+			for _, match := range regexGroupName.FindAllStringSubmatch(v.Expression, -1) {
+				variables[match[1]] = true
+			}
 		}
 	}
 	return nil
@@ -70,7 +119,7 @@ type CorrelationConfig struct {
 	// Target type
 	// required:true
 	Type CorrelationConfigType `json:"type" binding:"Required"`
-	// Target data query
+	// Target data query, or the url of external correlations
 	// required:true
 	// example: {"prop1":"value1","prop2":"value"}
 	Target map[string]any `json:"target" binding:"Required"`
@@ -80,19 +129,35 @@ type CorrelationConfig struct {
 	Transformations Transformations `json:"transformations,omitempty"`
 }
 
+func (c CorrelationConfig) Validate() error {
+	if err := c.Type.Validate(); err != nil {
+		return err
+	}
+	if c.Type == ConfigTypeExternal {
+		if url, ok := c.Target["url"].(string); !ok || url == "" {
+			return ErrExternalCorrelationReqURL
+		}
+	}
+	return c.Transformations.Validate()
+}
+
 func (c CorrelationConfig) MarshalJSON() ([]byte, error) {
 	target := c.Target
 	transformations := c.Transformations
 	if target == nil {
 		target = map[string]any{}
 	}
+	configType := c.Type
+	if configType == "" {
+		configType = ConfigTypeQuery
+	}
 	return json.Marshal(struct {
 		Type            CorrelationConfigType `json:"type"`
 		Field           string                `json:"field"`
 		Target          map[string]any        `json:"target"`
 		Transformations Transformations       `json:"transformations,omitempty"`
 	}{
-		Type:            ConfigTypeQuery,
+		Type:            configType,
 		Field:           c.Field,
 		Target:          target,
 		Transformations: transformations,
@@ -163,15 +228,20 @@ type CreateCorrelationCommand struct {
 }
 
 func (c CreateCorrelationCommand) Validate() error {
-	if err := c.Config.Type.Validate(); err != nil {
+	return validateCorrelation(c.TargetUID, c.Config)
+}
+
+// validateCorrelation checks the config of a correlation, and that only the correlations of type query
+// have a target data source.
+func validateCorrelation(targetUID *string, config CorrelationConfig) error {
+	if err := config.Validate(); err != nil {
 		return err
 	}
-	if c.TargetUID == nil && c.Config.Type == ConfigTypeQuery {
+	if targetUID == nil && config.Type == ConfigTypeQuery {
 		return fmt.Errorf("correlations of type \"%s\" must have a targetUID", ConfigTypeQuery)
 	}
-
-	if err := c.Config.Transformations.Validate(); err != nil {
-		return err
+	if targetUID != nil && config.Type == ConfigTypeExternal {
+		return fmt.Errorf("correlations of type \"%s\" can't have a targetUID", ConfigTypeExternal)
 	}
 	return nil
 }
@@ -204,7 +274,7 @@ type CorrelationConfigUpdateDTO struct {
 	Field *string `json:"field"`
 	// Target type
 	Type *CorrelationConfigType `json:"type"`
-	// Target data query
+	// Target data query, or the url of external correlations
 	// example: {"prop1":"value1","prop2":"value"}
 	Target *map[string]any `json:"target"`
 	// Source data transformations
@@ -218,6 +288,11 @@ func (c CorrelationConfigUpdateDTO) Validate() error {
 			return err
 		}
 	}
+	if c.Transformations != nil {
+		if err := Transformations(c.Transformations).Validate(); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
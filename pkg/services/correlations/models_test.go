@@ -41,6 +41,56 @@ func TestCorrelationModels(t *testing.T) {
 			require.Error(t, cmd.Validate())
 		})
 
+		t.Run("Successfully validates an external correlation", func(t *testing.T) {
+			config := &CorrelationConfig{
+				Field:  "field",
+				Target: map[string]any{"url": "https://tickets.example.com/browse/${ticket}"},
+				Type:   ConfigTypeExternal,
+				Transformations: Transformations{
+					{Type: "jsonpath", Field: "payload", Expression: "$.issue.key", MapValue: "ticket"},
+				},
+			}
+			cmd := &CreateCorrelationCommand{
+				SourceUID: "some-uid",
+				OrgId:     1,
+				Config:    *config,
+			}
+
+			require.NoError(t, cmd.Validate())
+		})
+
+		t.Run("Fails if the url of an external correlation is not set", func(t *testing.T) {
+			config := &CorrelationConfig{
+				Field:  "field",
+				Target: map[string]any{},
+				Type:   ConfigTypeExternal,
+			}
+			cmd := &CreateCorrelationCommand{
+				SourceUID: "some-uid",
+				OrgId:     1,
+				Config:    *config,
+			}
+
+			require.ErrorIs(t, cmd.Validate(), ErrExternalCorrelationReqURL)
+		})
+
+		t.Run("Fails if target UID is set and config type = external", func(t *testing.T) {
+			targetUid := "targetUid"
+			config := &CorrelationConfig{
+				Field:  "field",
+				Target: map[string]any{"url": "https://tickets.example.com"},
+				Type:   ConfigTypeExternal,
+			}
+			cmd := &CreateCorrelationCommand{
+				SourceUID: "some-uid",
+				OrgId:     1,
+				TargetUID: &targetUid,
+				Config:    *config,
+			}
+
+			require.Error(t, cmd.Validate())
+		})
+
 		t.Run("Fails if config type is unknown", func(t *testing.T) {
 			config := &CorrelationConfig{
 				Field:  "field",
@@ -66,6 +116,7 @@ func TestCorrelationModels(t *testing.T) {
 
 			tests := []test{
 				{input: "query", assertion: require.NoError},
+				{input: "external", assertion: require.NoError},
 				{input: "link", assertion: require.Error},
 			}
 
@@ -75,6 +126,71 @@ func TestCorrelationModels(t *testing.T) {
 		})
 	})
 
+	t.Run("Transformations Validate", func(t *testing.T) {
+		type test struct {
+			name      string
+			input     Transformations
+			assertion require.ErrorAssertionFunc
+		}
+
+		tests := []test{
+			{name: "logfmt", input: Transformations{{Type: "logfmt"}}, assertion: require.NoError},
+			{name: "regex", input: Transformations{{Type: "regex", Expression: `id=(\w+)`}}, assertion: require.NoError},
+			{name: "regex without expression", input: Transformations{{Type: "regex"}}, assertion: require.Error},
+			{name: "jsonpath", input: Transformations{{Type: "jsonpath", Expression: "$.user.id", MapValue: "user"}}, assertion: require.NoError},
+			{name: "jsonpath without expression", input: Transformations{{Type: "jsonpath"}}, assertion: require.Error},
+			{name: "keyvalue", input: Transformations{{Type: "keyvalue", Delimiter: ";", Separator: ":"}}, assertion: require.NoError},
+			{name: "unknown type", input: Transformations{{Type: "xml"}}, assertion: require.Error},
+			{
+				name: "chained",
+				input: Transformations{
+					{Type: "jsonpath", Field: "body", Expression: "$.labels", MapValue: "labels"},
+					{Type: "keyvalue", Input: "labels", Delimiter: ","},
+				},
+				assertion: require.NoError,
+			},
+			{
+				name: "chained with a named capture group",
+				input: Transformations{
+					{Type: "regex", Expression: `labels=(?P<labels>\S+)`},
+					{Type: "keyvalue", Input: "labels", Delimiter: ","},
+				},
+				assertion: require.NoError,
+			},
+			{
+				name: "input not set by a previous transformation",
+				input: Transformations{
+					{Type: "jsonpath", Expression: "$.labels", MapValue: "tags"},
+					{Type: "keyvalue", Input: "labels", Delimiter: ","},
+				},
+				assertion: require.Error,
+			},
+			{name: "input of the first transformation", input: Transformations{{Type: "logfmt", Input: "labels"}}, assertion: require.Error},
+			{
+				name: "input and field",
+				input: Transformations{
+					{Type: "logfmt"},
+					{Type: "keyvalue", Field: "body", Input: "labels"},
+				},
+				assertion: require.Error,
+			},
+		}
+
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				tc.assertion(t, tc.input.Validate())
+			})
+		}
+	})
+
+	t.Run("CorrelationConfigUpdateDTO Validate", func(t *testing.T) {
+		t.Run("Fails if transformations are invalid", func(t *testing.T) {
+			dto := CorrelationConfigUpdateDTO{Transformations: []Transformation{{Type: "jsonpath"}}}
+
+			require.Error(t, dto.Validate())
+		})
+	})
+
 	t.Run("CorrelationConfig JSON Marshaling", func(t *testing.T) {
 		t.Run("Applies a default empty object if target is not defined", func(t *testing.T) {
 			config := CorrelationConfig{
@@ -87,5 +203,18 @@ func TestCorrelationModels(t *testing.T) {
 
 			require.Equal(t, `{"type":"query","field":"field","target":{}}`, string(data))
 		})
+
+		t.Run("Keeps the config type", func(t *testing.T) {
+			config := CorrelationConfig{
+				Field:  "field",
+				Type:   ConfigTypeExternal,
+				Target: map[string]any{"url": "https://tickets.example.com"},
+			}
+
+			data, err := json.Marshal(config)
+			require.NoError(t, err)
+
+			require.Equal(t, `{"type":"external","field":"field","target":{"url":"https://tickets.example.com"}}`, string(data))
+		})
 	})
 }
@@ -9,6 +9,7 @@ import (
 
 	"xorm.io/xorm"
 
+	"github.com/grafana/grafana/pkg/events"
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/metrics"
@@ -405,10 +406,11 @@ func saveDashboard(sess *db.Session, cmd *dashboards.SaveDashboardCommand, emitE
 	}
 
 	parentVersion := dash.Version
+	isNew := dash.ID == 0
 	var affectedRows int64
 	var err error
 
-	if dash.ID == 0 {
+	if isNew {
 		dash.SetVersion(1)
 		dash.Created = time.Now()
 		dash.CreatedBy = userId
@@ -477,9 +479,51 @@ func saveDashboard(sess *db.Session, cmd *dashboards.SaveDashboardCommand, emitE
 			return dash, err
 		}
 	}
+
+	publishDashboardSaved(sess, dash, isNew)
 	return dash, nil
 }
 
+func publishDashboardSaved(sess *db.Session, dash *dashboards.Dashboard, isNew bool) {
+	switch {
+	case dash.IsFolder && isNew:
+		sess.PublishAfterCommit(&events.FolderCreated{
+			Timestamp: dash.Updated,
+			Title:     dash.Title,
+			ID:        dash.ID,
+			UID:       dash.UID,
+			OrgID:     dash.OrgID,
+		})
+	case dash.IsFolder:
+		sess.PublishAfterCommit(&events.FolderUpdated{
+			Timestamp: dash.Updated,
+			Title:     dash.Title,
+			ID:        dash.ID,
+			UID:       dash.UID,
+			OrgID:     dash.OrgID,
+		})
+	case isNew:
+		sess.PublishAfterCommit(&events.DashboardCreated{
+			Timestamp: dash.Updated,
+			Title:     dash.Title,
+			ID:        dash.ID,
+			UID:       dash.UID,
+			OrgID:     dash.OrgID,
+			FolderUID: dash.FolderUID,
+		})
+	default:
+		sess.PublishAfterCommit(&events.DashboardUpdated{
+			Timestamp: dash.Updated,
+			Title:     dash.Title,
+			ID:        dash.ID,
+			UID:       dash.UID,
+			OrgID:     dash.OrgID,
+			FolderUID: dash.FolderUID,
+			Version:   dash.Version,
+		})
+	}
+}
+
 func saveProvisionedData(sess *db.Session, provisioning *dashboards.DashboardProvisioning, dashboard *dashboards.Dashboard) error {
 	result := &dashboards.DashboardProvisioning{}
 
@@ -580,6 +624,25 @@ func (d *dashboardStore) deleteDashboard(cmd *dashboards.DeleteDashboardCommand,
 			return err
 		}
 	}
+
+	if dashboard.IsFolder {
+		sess.PublishAfterCommit(&events.FolderDeleted{
+			Timestamp: time.Now(),
+			Title:     dashboard.Title,
+			ID:        dashboard.ID,
+			UID:       dashboard.UID,
+			OrgID:     dashboard.OrgID,
+		})
+	} else {
+		sess.PublishAfterCommit(&events.DashboardDeleted{
+			Timestamp: time.Now(),
+			Title:     dashboard.Title,
+			ID:        dashboard.ID,
+			UID:       dashboard.UID,
+			OrgID:     dashboard.OrgID,
+			FolderUID: dashboard.FolderUID,
+		})
+	}
 	return nil
 }
 
@@ -0,0 +1,18 @@
+package dashboardusage
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+)
+
+// Service tracks how often dashboards are viewed and how many of their queries fail.
+type Service interface {
+	// RecordView records a view of a dashboard. The user is only stored when
+	// tracking of users is enabled.
+	RecordView(ctx context.Context, orgID int64, dashboardUID string, user identity.Requester)
+	// RecordQueryError records a failed query of a panel of a dashboard.
+	RecordQueryError(ctx context.Context, orgID int64, dashboardUID string)
+	// GetUsage returns the usage of a dashboard since the start of the query.
+	GetUsage(ctx context.Context, query *GetUsageQuery) (*DashboardUsage, error)
+}
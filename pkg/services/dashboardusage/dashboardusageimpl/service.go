@@ -0,0 +1,184 @@
+package dashboardusageimpl
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	"github.com/grafana/grafana/pkg/services/dashboardusage"
+	"github.com/grafana/grafana/pkg/services/search"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const cleanupInterval = time.Hour
+
+// Service records the views and query errors of dashboards in memory, and writes
+// them to the database every flush interval, so that viewing a dashboard doesn't
+// need a write to the database.
+type Service struct {
+	cfg        setting.DashboardUsageSettings
+	store      store
+	serverLock *serverlock.ServerLockService
+	log        log.Logger
+	now        func() time.Time
+
+	mu       sync.Mutex
+	counters map[counterKey]counter
+	viewers  map[viewerKey]viewer
+}
+
+var _ dashboardusage.Service = (*Service)(nil)
+
+func ProvideService(cfg *setting.Cfg, dbStore db.DB, searchService *search.SearchService, serverLock *serverlock.ServerLockService) *Service {
+	s := &Service{
+		cfg:        cfg.DashboardUsage,
+		store:      &sqlStore{db: dbStore},
+		serverLock: serverLock,
+		log:        log.New("dashboard-usage"),
+		now:        time.Now,
+		counters:   map[counterKey]counter{},
+		viewers:    map[viewerKey]viewer{},
+	}
+
+	if s.cfg.Enabled {
+		for _, option := range sortOptions(dbStore.GetDialect()) {
+			searchService.RegisterSortOption(option)
+		}
+	}
+
+	return s
+}
+
+func (s *Service) IsDisabled() bool {
+	return !s.cfg.Enabled
+}
+
+func (s *Service) RecordView(ctx context.Context, orgID int64, dashboardUID string, user identity.Requester) {
+	if !s.cfg.Enabled {
+		return
+	}
+
+	var userID int64
+	if user != nil && !user.IsNil() {
+		namespace, id := user.GetNamespacedID()
+		// images of the dashboard rendered for reports and alerts aren't views
+		if namespace == identity.NamespaceRenderService {
+			return
+		}
+		if s.cfg.TrackUsers && namespace == identity.NamespaceUser {
+			userID, _ = identity.IntIdentifier(namespace, id)
+		}
+	}
+
+	now := s.now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := counterKey{orgID: orgID, dashboardUID: dashboardUID, day: startOfDay(now)}
+	c := s.counters[key]
+	c.views++
+	s.counters[key] = c
+
+	if userID > 0 {
+		vKey := viewerKey{orgID: orgID, dashboardUID: dashboardUID, userID: userID}
+		v := s.viewers[vKey]
+		v.views++
+		v.lastViewed = now.Unix()
+		s.viewers[vKey] = v
+	}
+}
+
+func (s *Service) RecordQueryError(ctx context.Context, orgID int64, dashboardUID string) {
+	if !s.cfg.Enabled {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := counterKey{orgID: orgID, dashboardUID: dashboardUID, day: startOfDay(s.now())}
+	c := s.counters[key]
+	c.queryErrors++
+	s.counters[key] = c
+}
+
+func (s *Service) GetUsage(ctx context.Context, query *dashboardusage.GetUsageQuery) (*dashboardusage.DashboardUsage, error) {
+	if !s.cfg.Enabled {
+		return &dashboardusage.DashboardUsage{DashboardUID: query.DashboardUID, Days: []dashboardusage.DailyUsage{}}, nil
+	}
+	return s.store.Get(ctx, query, s.cfg.TrackUsers)
+}
+
+func (s *Service) Run(ctx context.Context) error {
+	flushTicker := time.NewTicker(s.cfg.FlushInterval)
+	defer flushTicker.Stop()
+	cleanupTicker := time.NewTicker(cleanupInterval)
+	defer cleanupTicker.Stop()
+
+	for {
+		select {
+		case <-flushTicker.C:
+			if err := s.flush(ctx); err != nil {
+				s.log.Error("Failed to write dashboard usage", "error", err)
+			}
+		case <-cleanupTicker.C:
+			err := s.serverLock.LockAndExecute(ctx, "cleanup dashboard usage", cleanupInterval, func(ctx context.Context) {
+				if err := s.store.DeleteOlderThan(ctx, s.now().Add(-s.cfg.Retention)); err != nil {
+					s.log.Error("Failed to delete old dashboard usage", "error", err)
+				}
+			})
+			if err != nil {
+				s.log.Error("Failed to lock and execute cleanup of dashboard usage", "error", err)
+			}
+		case <-ctx.Done():
+			// write the usage recorded since the last flush before shutting down
+			if err := s.flush(context.Background()); err != nil {
+				s.log.Error("Failed to write dashboard usage", "error", err)
+			}
+			return ctx.Err()
+		}
+	}
+}
+
+// flush writes the usage recorded since the last flush to the database. The usage
+// is kept in memory for the next flush when it can't be written.
+func (s *Service) flush(ctx context.Context) error {
+	s.mu.Lock()
+	counters, viewers := s.counters, s.viewers
+	s.counters, s.viewers = map[counterKey]counter{}, map[viewerKey]viewer{}
+	s.mu.Unlock()
+
+	if len(counters) == 0 && len(viewers) == 0 {
+		return nil
+	}
+
+	if err := s.store.Add(ctx, counters, viewers); err != nil {
+		s.restore(counters, viewers)
+		return err
+	}
+	return nil
+}
+
+func (s *Service) restore(counters map[counterKey]counter, viewers map[viewerKey]viewer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, c := range counters {
+		current := s.counters[key]
+		current.views += c.views
+		current.queryErrors += c.queryErrors
+		s.counters[key] = current
+	}
+	for key, v := range viewers {
+		current := s.viewers[key]
+		current.views += v.views
+		if v.lastViewed > current.lastViewed {
+			current.lastViewed = v.lastViewed
+		}
+		s.viewers[key] = current
+	}
+}
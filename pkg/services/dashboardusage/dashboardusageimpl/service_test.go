@@ -0,0 +1,232 @@
+package dashboardusageimpl
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboardusage"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/search/model"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/services/sqlstore/searchstore"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func setupService(t *testing.T, trackUsers bool) (*Service, db.DB) {
+	t.Helper()
+
+	testDB := db.InitTestDB(t)
+	return &Service{
+		cfg: setting.DashboardUsageSettings{
+			Enabled:       true,
+			TrackUsers:    trackUsers,
+			Retention:     365 * 24 * time.Hour,
+			FlushInterval: time.Minute,
+		},
+		store:    &sqlStore{db: testDB},
+		log:      log.New("test-logger"),
+		now:      time.Now,
+		counters: map[counterKey]counter{},
+		viewers:  map[viewerKey]viewer{},
+	}, testDB
+}
+
+func TestIntegrationDashboardUsage(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	signedInUser := &user.SignedInUser{UserID: 2, OrgID: 1}
+
+	t.Run("should add the views and query errors to the stored usage", func(t *testing.T) {
+		s, _ := setupService(t, false)
+
+		s.RecordView(ctx, 1, "dash", signedInUser)
+		s.RecordView(ctx, 1, "dash", signedInUser)
+		s.RecordQueryError(ctx, 1, "dash")
+		s.RecordView(ctx, 1, "other", signedInUser)
+		require.NoError(t, s.flush(ctx))
+
+		s.RecordView(ctx, 1, "dash", signedInUser)
+		require.NoError(t, s.flush(ctx))
+
+		usage, err := s.GetUsage(ctx, &dashboardusage.GetUsageQuery{OrgID: 1, DashboardUID: "dash", From: time.Now().AddDate(0, 0, -30)})
+		require.NoError(t, err)
+		require.Equal(t, int64(3), usage.Views)
+		require.Equal(t, int64(1), usage.QueryErrors)
+		require.Len(t, usage.Days, 1)
+		require.NotNil(t, usage.LastViewed)
+		require.Empty(t, usage.Viewers)
+	})
+
+	t.Run("should only store the viewers when tracking users", func(t *testing.T) {
+		s, _ := setupService(t, true)
+
+		s.RecordView(ctx, 1, "dash", signedInUser)
+		s.RecordView(ctx, 1, "dash", &user.SignedInUser{OrgID: 1, IsAnonymous: true})
+		require.NoError(t, s.flush(ctx))
+
+		usage, err := s.GetUsage(ctx, &dashboardusage.GetUsageQuery{OrgID: 1, DashboardUID: "dash", From: time.Now().AddDate(0, 0, -30)})
+		require.NoError(t, err)
+		require.Equal(t, int64(2), usage.Views)
+		require.Len(t, usage.Viewers, 1)
+		require.Equal(t, int64(2), usage.Viewers[0].UserID)
+	})
+
+	t.Run("should not count dashboards rendered as images as views", func(t *testing.T) {
+		s, _ := setupService(t, false)
+
+		s.RecordView(ctx, 1, "dash", &user.SignedInUser{OrgID: 1, AuthenticatedBy: "render"})
+		require.Empty(t, s.counters)
+	})
+
+	t.Run("should delete the usage older than the retention", func(t *testing.T) {
+		s, _ := setupService(t, false)
+
+		s.now = func() time.Time { return time.Now().AddDate(0, 0, -400) }
+		s.RecordView(ctx, 1, "dash", signedInUser)
+		s.now = time.Now
+		s.RecordView(ctx, 1, "dash", signedInUser)
+		require.NoError(t, s.flush(ctx))
+
+		require.NoError(t, s.store.DeleteOlderThan(ctx, time.Now().Add(-s.cfg.Retention)))
+
+		usage, err := s.GetUsage(ctx, &dashboardusage.GetUsageQuery{OrgID: 1, DashboardUID: "dash", From: time.Now().AddDate(0, 0, -500)})
+		require.NoError(t, err)
+		require.Equal(t, int64(1), usage.Views)
+	})
+
+	t.Run("should sort dashboards by views and find stale dashboards", func(t *testing.T) {
+		s, testDB := setupService(t, false)
+
+		for _, uid := range []string{"popular", "viewed", "stale"} {
+			dash := dashboards.NewDashboard(uid)
+			dash.OrgID = 1
+			dash.UID = uid
+			err := testDB.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+				_, err := sess.Nullable("folder_uid").Insert(dash)
+				return err
+			})
+			require.NoError(t, err)
+		}
+
+		s.RecordView(ctx, 1, "popular", signedInUser)
+		s.RecordView(ctx, 1, "popular", signedInUser)
+		s.RecordView(ctx, 1, "viewed", signedInUser)
+		s.now = func() time.Time { return time.Now().AddDate(0, 0, -100) }
+		s.RecordView(ctx, 1, "stale", signedInUser)
+		require.NoError(t, s.flush(ctx))
+
+		options := map[string]model.SortOption{}
+		for _, option := range sortOptions(testDB.GetDialect()) {
+			options[option.Name] = option
+		}
+
+		search := func(option model.SortOption) ([]string, []int64) {
+			filters := []any{searchstore.OrgFilter{OrgId: 1}}
+			for _, f := range option.Filter {
+				filters = append(filters, f)
+			}
+			builder := &searchstore.Builder{Filters: filters, Dialect: testDB.GetDialect(), Features: featuremgmt.WithFeatures()}
+			sql, params := builder.ToSQL(100, 1)
+
+			var res []dashboards.DashboardSearchProjection
+			err := testDB.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+				return sess.SQL(sql, params...).Find(&res)
+			})
+			require.NoError(t, err)
+
+			uids := make([]string, 0, len(res))
+			meta := make([]int64, 0, len(res))
+			for _, hit := range res {
+				uids = append(uids, hit.UID)
+				meta = append(meta, hit.SortMeta)
+			}
+			return uids, meta
+		}
+
+		uids, views := search(options["views-desc"])
+		require.Equal(t, []string{"popular", "viewed", "stale"}, uids)
+		require.Equal(t, []int64{2, 1, 0}, views)
+
+		uids, _ = search(options["views-asc"])
+		require.Equal(t, []string{"stale", "viewed", "popular"}, uids)
+
+		uids, _ = search(options["stale-90d"])
+		require.Equal(t, []string{"stale"}, uids)
+	})
+}
+
+func TestDashboardUsageFlush(t *testing.T) {
+	t.Run("should keep the usage in memory when it can't be written", func(t *testing.T) {
+		store := &fakeStore{err: errors.New("database is locked")}
+		s := &Service{
+			cfg:      setting.DashboardUsageSettings{Enabled: true},
+			store:    store,
+			log:      log.New("test-logger"),
+			now:      time.Now,
+			counters: map[counterKey]counter{},
+			viewers:  map[viewerKey]viewer{},
+		}
+
+		s.RecordView(context.Background(), 1, "dash", nil)
+		require.Error(t, s.flush(context.Background()))
+		s.RecordView(context.Background(), 1, "dash", nil)
+
+		store.err = nil
+		require.NoError(t, s.flush(context.Background()))
+		require.Len(t, store.counters, 1)
+		for _, c := range store.counters {
+			require.Equal(t, int64(2), c.views)
+		}
+		require.Empty(t, s.counters)
+	})
+
+	t.Run("should not record anything when disabled", func(t *testing.T) {
+		s := &Service{
+			cfg:      setting.DashboardUsageSettings{Enabled: false},
+			now:      time.Now,
+			counters: map[counterKey]counter{},
+			viewers:  map[viewerKey]viewer{},
+		}
+
+		s.RecordView(context.Background(), 1, "dash", nil)
+		s.RecordQueryError(context.Background(), 1, "dash")
+		require.Empty(t, s.counters)
+	})
+}
+
+type fakeStore struct {
+	err      error
+	counters map[counterKey]counter
+}
+
+func (f *fakeStore) Add(ctx context.Context, counters map[counterKey]counter, viewers map[viewerKey]viewer) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.counters = counters
+	return nil
+}
+
+func (f *fakeStore) Get(ctx context.Context, query *dashboardusage.GetUsageQuery, withViewers bool) (*dashboardusage.DashboardUsage, error) {
+	return nil, f.err
+}
+
+func (f *fakeStore) DeleteOlderThan(ctx context.Context, olderThan time.Time) error {
+	return f.err
+}
@@ -0,0 +1,105 @@
+package dashboardusageimpl
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/search/model"
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+	"github.com/grafana/grafana/pkg/services/sqlstore/searchstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	viewsPeriod = 30 * 24 * time.Hour
+	stalePeriod = setting.DashboardUsageStalePeriod
+)
+
+func sortOptions(dialect migrator.Dialect) []model.SortOption {
+	return []model.SortOption{
+		{
+			Name:        "views-desc",
+			DisplayName: "Most viewed",
+			Description: "Sort dashboards by their number of views in the last 30 days, most viewed first",
+			Index:       1,
+			MetaName:    "views",
+			Filter: []model.SortOptionFilter{
+				viewsSorter{Descending: true, Period: viewsPeriod},
+				searchstore.TitleSorter{},
+			},
+		},
+		{
+			Name:        "views-asc",
+			DisplayName: "Least viewed",
+			Description: "Sort dashboards by their number of views in the last 30 days, least viewed first",
+			Index:       1,
+			MetaName:    "views",
+			Filter: []model.SortOptionFilter{
+				viewsSorter{Period: viewsPeriod},
+				searchstore.TitleSorter{},
+			},
+		},
+		{
+			Name:        "stale-90d",
+			DisplayName: "Stale: not viewed in 90 days",
+			Description: "Only show the dashboards which haven't been viewed in the last 90 days, least recently viewed first",
+			Index:       2,
+			MetaName:    "last viewed",
+			Filter: []model.SortOptionFilter{
+				staleFilter{Dialect: dialect, Period: stalePeriod},
+				searchstore.TitleSorter{},
+			},
+		},
+	}
+}
+
+// viewsSorter orders dashboards by their number of views since the start of the period.
+type viewsSorter struct {
+	Descending bool
+	Period     time.Duration
+}
+
+func (s viewsSorter) LeftJoin() string {
+	// the join can't have parameters, the start of the period is an integer computed here
+	return fmt.Sprintf(`(SELECT org_id, dashboard_uid, SUM(views) AS views FROM dashboard_usage
+		WHERE day >= %d GROUP BY org_id, dashboard_uid) AS dashboard_usage_views
+		ON dashboard_usage_views.dashboard_uid = dashboard.uid AND dashboard_usage_views.org_id = dashboard.org_id`,
+		startOfDay(time.Now().Add(-s.Period)))
+}
+
+func (s viewsSorter) OrderBy() string {
+	if s.Descending {
+		return "COALESCE(dashboard_usage_views.views, 0) DESC"
+	}
+	return "COALESCE(dashboard_usage_views.views, 0) ASC"
+}
+
+func (s viewsSorter) Select() string {
+	return "COALESCE(dashboard_usage_views.views, 0) AS sort_meta"
+}
+
+// staleFilter limits the results to the dashboards which haven't been viewed since the
+// start of the period, and orders them by the last day they were viewed.
+type staleFilter struct {
+	Dialect migrator.Dialect
+	Period  time.Duration
+}
+
+func (f staleFilter) LeftJoin() string {
+	return `(SELECT org_id, dashboard_uid, MAX(day) AS last_viewed FROM dashboard_usage
+		WHERE views > 0 GROUP BY org_id, dashboard_uid) AS dashboard_usage_last_viewed
+		ON dashboard_usage_last_viewed.dashboard_uid = dashboard.uid AND dashboard_usage_last_viewed.org_id = dashboard.org_id`
+}
+
+func (f staleFilter) Where() (string, []any) {
+	return "(dashboard_usage_last_viewed.last_viewed IS NULL OR dashboard_usage_last_viewed.last_viewed < ?) AND dashboard.is_folder = " + f.Dialect.BooleanStr(false),
+		[]any{startOfDay(time.Now().Add(-f.Period))}
+}
+
+func (f staleFilter) OrderBy() string {
+	return "COALESCE(dashboard_usage_last_viewed.last_viewed, 0) ASC"
+}
+
+func (f staleFilter) Select() string {
+	return "COALESCE(dashboard_usage_last_viewed.last_viewed, 0) AS sort_meta"
+}
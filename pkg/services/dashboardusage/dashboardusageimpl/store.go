@@ -0,0 +1,156 @@
+package dashboardusageimpl
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/dashboardusage"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+const maxViewers = 100
+
+type counterKey struct {
+	orgID        int64
+	dashboardUID string
+	// day is the unix time of the start of the day in UTC
+	day int64
+}
+
+type counter struct {
+	views       int64
+	queryErrors int64
+}
+
+type viewerKey struct {
+	orgID        int64
+	dashboardUID string
+	userID       int64
+}
+
+type viewer struct {
+	views      int64
+	lastViewed int64
+}
+
+type store interface {
+	// Add adds the counters and viewers to the stored usage.
+	Add(ctx context.Context, counters map[counterKey]counter, viewers map[viewerKey]viewer) error
+	Get(ctx context.Context, query *dashboardusage.GetUsageQuery, withViewers bool) (*dashboardusage.DashboardUsage, error)
+	// DeleteOlderThan deletes the usage of the days before the given time.
+	DeleteOlderThan(ctx context.Context, olderThan time.Time) error
+}
+
+type sqlStore struct {
+	db db.DB
+}
+
+func (s *sqlStore) Add(ctx context.Context, counters map[counterKey]counter, viewers map[viewerKey]viewer) error {
+	dialect := s.db.GetDialect()
+	counterQuery := dialect.UpsertIncrementSQL("dashboard_usage",
+		[]string{"org_id", "dashboard_uid", "day"}, []string{"views", "query_errors"}, nil)
+	viewerQuery := dialect.UpsertIncrementSQL("dashboard_usage_viewer",
+		[]string{"org_id", "dashboard_uid", "user_id"}, []string{"views"}, []string{"last_viewed"})
+
+	return s.db.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		for key, c := range counters {
+			if _, err := sess.Exec(counterQuery, key.orgID, key.dashboardUID, key.day, c.views, c.queryErrors); err != nil {
+				return err
+			}
+		}
+		for key, v := range viewers {
+			if _, err := sess.Exec(viewerQuery, key.orgID, key.dashboardUID, key.userID, v.views, v.lastViewed); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+type usageRow struct {
+	Day         int64 `xorm:"day"`
+	Views       int64 `xorm:"views"`
+	QueryErrors int64 `xorm:"query_errors"`
+}
+
+type viewerRow struct {
+	UserID     int64 `xorm:"user_id"`
+	Views      int64 `xorm:"views"`
+	LastViewed int64 `xorm:"last_viewed"`
+}
+
+func (s *sqlStore) Get(ctx context.Context, query *dashboardusage.GetUsageQuery, withViewers bool) (*dashboardusage.DashboardUsage, error) {
+	usage := &dashboardusage.DashboardUsage{
+		DashboardUID: query.DashboardUID,
+		Days:         []dashboardusage.DailyUsage{},
+	}
+
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		rows := make([]usageRow, 0)
+		err := sess.SQL(`SELECT day, views, query_errors FROM dashboard_usage
+WHERE org_id = ? AND dashboard_uid = ? AND day >= ? ORDER BY day ASC`,
+			query.OrgID, query.DashboardUID, startOfDay(query.From)).Find(&rows)
+		if err != nil {
+			return err
+		}
+		for _, row := range rows {
+			usage.Views += row.Views
+			usage.QueryErrors += row.QueryErrors
+			usage.Days = append(usage.Days, dashboardusage.DailyUsage{
+				Day:         time.Unix(row.Day, 0).UTC(),
+				Views:       row.Views,
+				QueryErrors: row.QueryErrors,
+			})
+		}
+
+		// the dashboard might have been viewed before the start of the query
+		var lastViewed int64
+		_, err = sess.SQL(`SELECT COALESCE(MAX(day), 0) FROM dashboard_usage
+WHERE org_id = ? AND dashboard_uid = ? AND views > 0`, query.OrgID, query.DashboardUID).Get(&lastViewed)
+		if err != nil {
+			return err
+		}
+		if lastViewed > 0 {
+			t := time.Unix(lastViewed, 0).UTC()
+			usage.LastViewed = &t
+		}
+
+		if !withViewers {
+			return nil
+		}
+
+		viewers := make([]viewerRow, 0)
+		err = sess.SQL(`SELECT user_id, views, last_viewed FROM dashboard_usage_viewer
+WHERE org_id = ? AND dashboard_uid = ? ORDER BY last_viewed DESC`+s.db.GetDialect().Limit(maxViewers),
+			query.OrgID, query.DashboardUID).Find(&viewers)
+		if err != nil {
+			return err
+		}
+		for _, v := range viewers {
+			usage.Viewers = append(usage.Viewers, dashboardusage.Viewer{
+				UserID:     v.UserID,
+				Views:      v.Views,
+				LastViewed: time.Unix(v.LastViewed, 0).UTC(),
+			})
+		}
+		return nil
+	})
+
+	return usage, err
+}
+
+func (s *sqlStore) DeleteOlderThan(ctx context.Context, olderThan time.Time) error {
+	return s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		if _, err := sess.Exec("DELETE FROM dashboard_usage WHERE day < ?", startOfDay(olderThan)); err != nil {
+			return err
+		}
+		_, err := sess.Exec("DELETE FROM dashboard_usage_viewer WHERE last_viewed < ?", olderThan.Unix())
+		return err
+	})
+}
+
+// startOfDay returns the unix time of the start of the day of t in UTC.
+func startOfDay(t time.Time) int64 {
+	return t.UTC().Truncate(24 * time.Hour).Unix()
+}
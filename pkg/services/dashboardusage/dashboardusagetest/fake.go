@@ -0,0 +1,32 @@
+package dashboardusagetest
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	"github.com/grafana/grafana/pkg/services/dashboardusage"
+)
+
+type FakeDashboardUsageService struct {
+	ExpectedUsage *dashboardusage.DashboardUsage
+	ExpectedError error
+
+	Views       []string
+	QueryErrors []string
+}
+
+func NewDashboardUsageServiceFake() *FakeDashboardUsageService {
+	return &FakeDashboardUsageService{}
+}
+
+func (f *FakeDashboardUsageService) RecordView(ctx context.Context, orgID int64, dashboardUID string, user identity.Requester) {
+	f.Views = append(f.Views, dashboardUID)
+}
+
+func (f *FakeDashboardUsageService) RecordQueryError(ctx context.Context, orgID int64, dashboardUID string) {
+	f.QueryErrors = append(f.QueryErrors, dashboardUID)
+}
+
+func (f *FakeDashboardUsageService) GetUsage(ctx context.Context, query *dashboardusage.GetUsageQuery) (*dashboardusage.DashboardUsage, error) {
+	return f.ExpectedUsage, f.ExpectedError
+}
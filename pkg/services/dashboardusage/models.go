@@ -0,0 +1,36 @@
+package dashboardusage
+
+import (
+	"time"
+)
+
+type GetUsageQuery struct {
+	OrgID        int64
+	DashboardUID string
+	From         time.Time
+}
+
+// DashboardUsage is the usage of a dashboard over a period of time.
+type DashboardUsage struct {
+	DashboardUID string `json:"dashboardUid"`
+	Views        int64  `json:"views"`
+	QueryErrors  int64  `json:"queryErrors"`
+	// LastViewed is the last day the dashboard was viewed, within the retention period.
+	LastViewed *time.Time   `json:"lastViewed,omitempty"`
+	Days       []DailyUsage `json:"days"`
+	// Viewers is only set when tracking of users is enabled.
+	Viewers []Viewer `json:"viewers,omitempty"`
+}
+
+type DailyUsage struct {
+	Day         time.Time `json:"day"`
+	Views       int64     `json:"views"`
+	QueryErrors int64     `json:"queryErrors"`
+}
+
+type Viewer struct {
+	UserID     int64     `json:"userId"`
+	Login      string    `json:"login"`
+	Views      int64     `json:"views"`
+	LastViewed time.Time `json:"lastViewed"`
+}
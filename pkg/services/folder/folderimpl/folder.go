@@ -554,6 +554,10 @@ func (s *Service) Create(ctx context.Context, cmd *folder.CreateFolderCommand) (
 
 	dashFolder := dashboards.NewDashboardFolder(cmd.Title)
 	dashFolder.OrgID = cmd.OrgID
+	// the description is kept in the dashboard data too, so that it is part of the folder's versions
+	if cmd.Description != "" {
+		dashFolder.Data.Set("description", cmd.Description)
+	}
 
 	if s.features.IsEnabled(ctx, featuremgmt.FlagNestedFolders) && cmd.ParentUID != "" {
 		// Check that the user is allowed to create a subfolder in this folder
@@ -753,6 +757,10 @@ func (s *Service) legacyUpdate(ctx context.Context, cmd *folder.UpdateFolderComm
 	if err != nil {
 		return nil, toFolderError(err)
 	}
+	if cmd.RestoredFrom > 0 {
+		saveDashboardCmd.RestoredFrom = cmd.RestoredFrom
+		saveDashboardCmd.Message = fmt.Sprintf("Restored from version %d", cmd.RestoredFrom)
+	}
 
 	dash, err := s.dashboardStore.SaveDashboard(ctx, *saveDashboardCmd)
 	if err != nil {
@@ -778,6 +786,9 @@ func prepareForUpdate(dashFolder *dashboards.Dashboard, orgId int64, userId int6
 	}
 	dashFolder.Title = strings.TrimSpace(title)
 	dashFolder.Data.Set("title", dashFolder.Title)
+	if cmd.NewDescription != nil {
+		dashFolder.Data.Set("description", *cmd.NewDescription)
+	}
 
 	dashFolder.SetVersion(cmd.Version)
 	dashFolder.IsFolder = true
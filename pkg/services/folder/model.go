@@ -117,6 +117,8 @@ type UpdateFolderCommand struct {
 	Version int `json:"version"`
 	// Overwrite only used by the legacy folder implementation
 	Overwrite bool `json:"overwrite"`
+	// RestoredFrom is the version of the folder the update restores, if any
+	RestoredFrom int `json:"-"`
 
 	SignedInUser identity.Requester `json:"-"`
 }
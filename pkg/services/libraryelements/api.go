@@ -3,9 +3,11 @@ package libraryelements
 import (
 	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/components/dashdiffs"
 	"github.com/grafana/grafana/pkg/infra/metrics"
 	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
@@ -30,6 +32,10 @@ func (l *LibraryElementService) registerAPIEndpoints() {
 			entities.Get("/:uid/connections/", authorize(ac.EvalPermission(ActionLibraryPanelsRead, uidScope)), routing.Wrap(l.getConnectionsHandler))
 			entities.Get("/name/:name", routing.Wrap(l.getByNameHandler))
 			entities.Patch("/:uid", authorize(ac.EvalPermission(ActionLibraryPanelsWrite, uidScope)), routing.Wrap(l.patchHandler))
+			entities.Get("/:uid/versions", authorize(ac.EvalPermission(ActionLibraryPanelsWrite, uidScope)), routing.Wrap(l.getVersionsHandler))
+			entities.Get("/:uid/versions/:version", authorize(ac.EvalPermission(ActionLibraryPanelsWrite, uidScope)), routing.Wrap(l.getVersionHandler))
+			entities.Post("/:uid/restore", authorize(ac.EvalPermission(ActionLibraryPanelsWrite, uidScope)), routing.Wrap(l.restoreVersionHandler))
+			entities.Post("/calculate-diff", authorize(ac.EvalPermission(ActionLibraryPanelsWrite)), routing.Wrap(l.calculateDiffHandler))
 		} else {
 			entities.Post("/", routing.Wrap(l.createHandler))
 			entities.Delete("/:uid", routing.Wrap(l.deleteHandler))
@@ -38,6 +44,10 @@ func (l *LibraryElementService) registerAPIEndpoints() {
 			entities.Get("/:uid/connections/", routing.Wrap(l.getConnectionsHandler))
 			entities.Get("/name/:name", routing.Wrap(l.getByNameHandler))
 			entities.Patch("/:uid", routing.Wrap(l.patchHandler))
+			entities.Get("/:uid/versions", routing.Wrap(l.getVersionsHandler))
+			entities.Get("/:uid/versions/:version", routing.Wrap(l.getVersionHandler))
+			entities.Post("/:uid/restore", routing.Wrap(l.restoreVersionHandler))
+			entities.Post("/calculate-diff", routing.Wrap(l.calculateDiffHandler))
 		}
 	})
 }
@@ -311,6 +321,130 @@ func (l *LibraryElementService) getByNameHandler(c *contextmodel.ReqContext) res
 	}
 }
 
+// swagger:route GET /library-elements/{library_element_uid}/versions library_elements getLibraryElementVersions
+//
+// Get library element versions.
+//
+// Returns the saved versions of a library element, newest first, together with the dashboards affected by each change.
+//
+// Responses:
+// 200: getLibraryElementVersionsResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (l *LibraryElementService) getVersionsHandler(c *contextmodel.ReqContext) response.Response {
+	versions, err := l.getLibraryElementVersions(c.Req.Context(), c.SignedInUser, model.ListLibraryElementVersionsQuery{
+		UID:   web.Params(c.Req)[":uid"],
+		Limit: c.QueryInt("limit"),
+		Start: c.QueryInt("start"),
+	})
+	if err != nil {
+		return toLibraryElementError(err, "Failed to get library element versions")
+	}
+
+	return response.JSON(http.StatusOK, model.LibraryElementVersionsResponse{Result: versions})
+}
+
+// swagger:route GET /library-elements/{library_element_uid}/versions/{version} library_elements getLibraryElementVersion
+//
+// Get library element version.
+//
+// Returns a saved version of a library element, including its model.
+//
+// Responses:
+// 200: getLibraryElementVersionResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (l *LibraryElementService) getVersionHandler(c *contextmodel.ReqContext) response.Response {
+	version, err := strconv.ParseInt(web.Params(c.Req)[":version"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "version is invalid", err)
+	}
+
+	dto, err := l.getLibraryElementVersion(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":uid"], version)
+	if err != nil {
+		return toLibraryElementError(err, "Failed to get library element version")
+	}
+
+	return response.JSON(http.StatusOK, model.LibraryElementVersionResponse{Result: dto})
+}
+
+// swagger:route POST /library-elements/{library_element_uid}/restore library_elements restoreLibraryElementVersion
+//
+// Restore library element version.
+//
+// Restores the name and model of a previous version as a new version of the library element.
+// All dashboards connected to the library element are affected by the change.
+//
+// Responses:
+// 200: getLibraryElementResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 412: preconditionFailedError
+// 500: internalServerError
+func (l *LibraryElementService) restoreVersionHandler(c *contextmodel.ReqContext) response.Response {
+	cmd := model.RestoreLibraryElementVersionCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	uid := web.Params(c.Req)[":uid"]
+	if _, err := l.restoreLibraryElementVersion(c.Req.Context(), c.SignedInUser, uid, cmd); err != nil {
+		return toLibraryElementError(err, "Failed to restore library element version")
+	}
+
+	element, err := l.getLibraryElementByUid(c.Req.Context(), c.SignedInUser, model.GetLibraryElementCommand{
+		UID:        uid,
+		FolderName: dashboards.RootFolderName,
+	})
+	if err != nil {
+		return toLibraryElementError(err, "Failed to get library element")
+	}
+
+	return response.JSON(http.StatusOK, model.LibraryElementResponse{Result: element})
+}
+
+// swagger:route POST /library-elements/calculate-diff library_elements calculateLibraryElementDiff
+//
+// Compare library element versions.
+//
+// Compares the models of two library element versions using the same formatters as the dashboard version diff.
+//
+// Produces:
+// - application/json
+// - text/html
+//
+// Responses:
+// 200: calculateLibraryElementDiffResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (l *LibraryElementService) calculateDiffHandler(c *contextmodel.ReqContext) response.Response {
+	cmd := model.CalculateLibraryElementDiffCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	result, err := l.calculateLibraryElementDiff(c.Req.Context(), c.SignedInUser, cmd)
+	if err != nil {
+		return toLibraryElementError(err, "Unable to compute diff")
+	}
+
+	if dashdiffs.ParseDiffType(cmd.DiffType) == dashdiffs.DiffDelta {
+		return response.Respond(http.StatusOK, result.Delta).SetHeader("Content-Type", "application/json")
+	}
+
+	return response.Respond(http.StatusOK, result.Delta).SetHeader("Content-Type", "text/html")
+}
+
 func (l *LibraryElementService) filterLibraryPanelsByPermission(c *contextmodel.ReqContext, elements []model.LibraryElementDTO) ([]model.LibraryElementDTO, error) {
 	filteredPanels := make([]model.LibraryElementDTO, 0)
 	for _, p := range elements {
@@ -354,6 +488,12 @@ func toLibraryElementError(err error, message string) response.Response {
 	if errors.Is(err, model.ErrLibraryElementUIDTooLong) {
 		return response.Error(http.StatusBadRequest, model.ErrLibraryElementUIDTooLong.Error(), err)
 	}
+	if errors.Is(err, model.ErrLibraryElementVersionNotFound) {
+		return response.Error(http.StatusNotFound, model.ErrLibraryElementVersionNotFound.Error(), err)
+	}
+	if errors.Is(err, dashdiffs.ErrNilDiff) {
+		return response.Error(http.StatusBadRequest, "the library element versions are identical", err)
+	}
 	return response.ErrOrFallback(http.StatusInternalServerError, message, err)
 }
 
@@ -385,6 +525,50 @@ type DeleteLibraryElementByUIDParams struct {
 	UID string `json:"library_element_uid"`
 }
 
+// swagger:parameters getLibraryElementVersions
+type GetLibraryElementVersionsParams struct {
+	// in:path
+	// required:true
+	UID string `json:"library_element_uid"`
+	// Maximum number of results to return
+	// in:query
+	// required:false
+	// default:1000
+	Limit int `json:"limit"`
+	// Version to start from when returning queries
+	// in:query
+	// required:false
+	// default:0
+	Start int `json:"start"`
+}
+
+// swagger:parameters getLibraryElementVersion
+type GetLibraryElementVersionParams struct {
+	// in:path
+	// required:true
+	UID string `json:"library_element_uid"`
+	// in:path
+	// required:true
+	Version int64 `json:"version"`
+}
+
+// swagger:parameters restoreLibraryElementVersion
+type RestoreLibraryElementVersionParams struct {
+	// in:body
+	// required:true
+	Body model.RestoreLibraryElementVersionCommand `json:"body"`
+	// in:path
+	// required:true
+	UID string `json:"library_element_uid"`
+}
+
+// swagger:parameters calculateLibraryElementDiff
+type CalculateLibraryElementDiffParams struct {
+	// in:body
+	// required:true
+	Body model.CalculateLibraryElementDiffCommand `json:"body"`
+}
+
 // swagger:parameters getLibraryElementByName
 type LibraryElementByNameParams struct {
 	// in:path
@@ -478,3 +662,21 @@ type GetLibraryElementConnectionsResponse struct {
 	// in: body
 	Body model.LibraryElementConnectionsResponse `json:"body"`
 }
+
+// swagger:response getLibraryElementVersionsResponse
+type GetLibraryElementVersionsResponse struct {
+	// in: body
+	Body model.LibraryElementVersionsResponse `json:"body"`
+}
+
+// swagger:response getLibraryElementVersionResponse
+type GetLibraryElementVersionResponse struct {
+	// in: body
+	Body model.LibraryElementVersionResponse `json:"body"`
+}
+
+// swagger:response calculateLibraryElementDiffResponse
+type CalculateLibraryElementDiffResponse struct {
+	// in: body
+	Body []byte `json:"body"`
+}
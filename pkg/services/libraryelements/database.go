@@ -197,7 +197,7 @@ func (l *LibraryElementService) createLibraryElement(c context.Context, signedIn
 			}
 			return err
 		}
-		return nil
+		return saveLibraryElementVersion(session, element, 0, 0, "")
 	})
 
 	metrics.MFolderIDsServiceCount.WithLabelValues(metrics.LibraryElements).Inc()
@@ -271,6 +271,10 @@ func (l *LibraryElementService) deleteLibraryElement(c context.Context, signedIn
 			return model.ErrLibraryElementNotFound
 		}
 
+		if _, err := session.Exec("DELETE FROM "+model.LibraryElementVersionTableName+" WHERE element_id=?", element.ID); err != nil {
+			return err
+		}
+
 		elementID = element.ID
 		return nil
 	})
@@ -639,6 +643,14 @@ func (l *LibraryElementService) patchLibraryElement(c context.Context, signedInU
 			return model.ErrLibraryElementNotFound
 		}
 
+		libraryElement.FolderUID = elementInDB.FolderUID
+		if cmd.FolderUID != nil {
+			libraryElement.FolderUID = *cmd.FolderUID
+		}
+		if err := saveLibraryElementVersion(session, libraryElement, elementInDB.Version, cmd.RestoredFrom, cmd.Message); err != nil {
+			return err
+		}
+
 		metrics.MFolderIDsServiceCount.WithLabelValues(metrics.LibraryElements).Inc()
 		dto = model.LibraryElementDTO{
 			ID:          libraryElement.ID,
@@ -887,6 +899,10 @@ func (l *LibraryElementService) deleteLibraryElementsInFolderUID(c context.Conte
 			if err != nil {
 				return err
 			}
+			_, err = session.Exec("DELETE FROM "+model.LibraryElementVersionTableName+" WHERE element_id=?", elementID.ID)
+			if err != nil {
+				return err
+			}
 		}
 		if _, err := session.Exec("DELETE FROM library_element WHERE folder_id=? AND org_id=?", folderID, signedInUser.GetOrgID()); err != nil {
 			return err
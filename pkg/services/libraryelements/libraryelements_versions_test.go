@@ -0,0 +1,166 @@
+package libraryelements
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/libraryelements/model"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func TestLibraryElementVersions(t *testing.T) {
+	scenarioWithPanel(t, "When an admin gets the versions of a new library panel, it should return the initial save",
+		func(t *testing.T, sc scenarioContext) {
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			result := validateAndUnMarshalVersionsResponse(t, sc.service.getVersionsHandler(sc.reqContext))
+
+			require.Len(t, result.Result, 1)
+			require.Equal(t, int64(1), result.Result[0].Version)
+			require.Equal(t, int64(0), result.Result[0].ParentVersion)
+			require.Equal(t, "Initial save", result.Result[0].Message)
+			require.Equal(t, "Text - Library Panel", result.Result[0].Name)
+			require.Empty(t, result.Result[0].AffectedDashboards)
+		})
+
+	scenarioWithPanel(t, "When an admin patches a connected library panel, it should record the affected dashboards",
+		func(t *testing.T, sc scenarioContext) {
+			dashInDB := connectToNewDashboard(t, sc)
+			patchLibraryPanelTitle(t, sc, "Updated title", 1)
+
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			result := validateAndUnMarshalVersionsResponse(t, sc.service.getVersionsHandler(sc.reqContext))
+
+			require.Len(t, result.Result, 2)
+			require.Equal(t, int64(2), result.Result[0].Version)
+			require.Equal(t, int64(1), result.Result[0].ParentVersion)
+			require.Equal(t, "Change title", result.Result[0].Message)
+			require.Equal(t, []string{dashInDB.UID}, result.Result[0].AffectedDashboards)
+			require.Equal(t, int64(1), result.Result[1].Version)
+		})
+
+	scenarioWithPanel(t, "When an admin gets a version of a library panel, it should return its model",
+		func(t *testing.T, sc scenarioContext) {
+			patchLibraryPanelTitle(t, sc, "Updated title", 1)
+
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID, ":version": "1"})
+			resp := sc.service.getVersionHandler(sc.reqContext)
+			require.Equal(t, 200, resp.Status())
+			var result model.LibraryElementVersionResponse
+			require.NoError(t, json.Unmarshal(resp.Body(), &result))
+
+			require.Equal(t, int64(1), result.Result.Version)
+			require.Equal(t, "Text - Library Panel", simplejson.MustJson(result.Result.Model).Get("title").MustString())
+		})
+
+	scenarioWithPanel(t, "When an admin gets a version of a library panel that does not exist, it should fail",
+		func(t *testing.T, sc scenarioContext) {
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID, ":version": "5"})
+			resp := sc.service.getVersionHandler(sc.reqContext)
+			require.Equal(t, 404, resp.Status())
+		})
+
+	scenarioWithPanel(t, "When an admin restores a version of a library panel, it should save it as a new version",
+		func(t *testing.T, sc scenarioContext) {
+			patchLibraryPanelTitle(t, sc, "Updated title", 1)
+
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			sc.reqContext.Req.Body = mockRequestBody(model.RestoreLibraryElementVersionCommand{Version: 1})
+			restored := validateAndUnMarshalResponse(t, sc.service.restoreVersionHandler(sc.reqContext))
+			require.Equal(t, int64(3), restored.Result.Version)
+			require.Equal(t, "Text - Library Panel", restored.Result.Model["title"])
+			require.Equal(t, sc.folder.UID, restored.Result.Meta.FolderUID)
+
+			versions := validateAndUnMarshalVersionsResponse(t, sc.service.getVersionsHandler(sc.reqContext))
+			require.Len(t, versions.Result, 3)
+			require.Equal(t, int64(1), versions.Result[0].RestoredFrom)
+			require.Equal(t, "Restored from version 1", versions.Result[0].Message)
+		})
+
+	scenarioWithPanel(t, "When an admin compares two versions of a library panel, it should return the diff",
+		func(t *testing.T, sc scenarioContext) {
+			patchLibraryPanelTitle(t, sc, "Updated title", 1)
+
+			cmd := model.CalculateLibraryElementDiffCommand{
+				Base:     model.CalculateLibraryElementDiffTarget{UID: sc.initialResult.Result.UID, Version: 1},
+				New:      model.CalculateLibraryElementDiffTarget{UID: sc.initialResult.Result.UID, Version: 2},
+				DiffType: "basic",
+			}
+			sc.reqContext.Req.Body = mockRequestBody(cmd)
+			resp := sc.service.calculateDiffHandler(sc.reqContext)
+			require.Equal(t, 200, resp.Status())
+			require.Contains(t, string(resp.Body()), "Updated title")
+
+			cmd.New.Version = 1
+			sc.reqContext.Req.Body = mockRequestBody(cmd)
+			resp = sc.service.calculateDiffHandler(sc.reqContext)
+			require.Equal(t, 400, resp.Status())
+		})
+
+	scenarioWithPanel(t, "When an admin deletes a library panel, it should delete its versions",
+		func(t *testing.T, sc scenarioContext) {
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			resp := sc.service.deleteHandler(sc.reqContext)
+			require.Equal(t, 200, resp.Status())
+
+			count, err := sc.sqlStore.GetEngine().Table(model.LibraryElementVersionTableName).Where("element_id=?", sc.initialResult.Result.ID).Count()
+			require.NoError(t, err)
+			require.Zero(t, count)
+		})
+}
+
+func connectToNewDashboard(t *testing.T, sc scenarioContext) *dashboards.Dashboard {
+	t.Helper()
+
+	dash := dashboards.Dashboard{
+		Title: "Testing library element versions",
+		Data: simplejson.NewFromAny(map[string]any{
+			"panels": []any{
+				map[string]any{
+					"id": int64(1),
+					"libraryPanel": map[string]any{
+						"uid":  sc.initialResult.Result.UID,
+						"name": sc.initialResult.Result.Name,
+					},
+				},
+			},
+		}),
+	}
+	// nolint:staticcheck
+	dashInDB := createDashboard(t, sc.sqlStore, sc.user, &dash, sc.folder.ID, sc.folder.UID)
+	err := sc.service.ConnectElementsToDashboard(sc.reqContext.Req.Context(), sc.reqContext.SignedInUser, []string{sc.initialResult.Result.UID}, dashInDB.ID)
+	require.NoError(t, err)
+
+	return dashInDB
+}
+
+func patchLibraryPanelTitle(t *testing.T, sc scenarioContext, title string, version int64) {
+	t.Helper()
+
+	cmd := model.PatchLibraryElementCommand{
+		FolderID: -1, // nolint:staticcheck
+		Model:    []byte(`{"title": "` + title + `", "type": "text"}`),
+		Kind:     int64(model.PanelElement),
+		Version:  version,
+		Message:  "Change title",
+	}
+	sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+	sc.reqContext.Req.Body = mockRequestBody(cmd)
+	resp := sc.service.patchHandler(sc.reqContext)
+	require.Equal(t, 200, resp.Status())
+}
+
+func validateAndUnMarshalVersionsResponse(t *testing.T, resp response.Response) model.LibraryElementVersionsResponse {
+	t.Helper()
+
+	require.Equal(t, 200, resp.Status())
+	var result model.LibraryElementVersionsResponse
+	err := json.Unmarshal(resp.Body(), &result)
+	require.NoError(t, err)
+
+	return result
+}
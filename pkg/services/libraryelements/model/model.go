@@ -137,6 +137,51 @@ type LibraryElementConnectionDTO struct {
 	CreatedBy     librarypanel.LibraryElementDTOMetaUser `json:"createdBy"`
 }
 
+// LibraryElementVersion is the model for a saved version of a library element.
+type LibraryElementVersion struct {
+	ID                 int64  `xorm:"pk autoincr 'id'"`
+	OrgID              int64  `xorm:"org_id"`
+	ElementID          int64  `xorm:"element_id"`
+	Version            int64  `xorm:"version"`
+	ParentVersion      int64  `xorm:"parent_version"`
+	RestoredFrom       int64  `xorm:"restored_from"`
+	Name               string `xorm:"name"`
+	FolderUID          string `xorm:"folder_uid"`
+	Model              json.RawMessage
+	AffectedDashboards []string `xorm:"affected_dashboards"`
+	Message            string
+	Created            time.Time
+	CreatedBy          int64
+}
+
+// LibraryElementVersionWithMeta is the model used to retrieve versions with additional meta information.
+type LibraryElementVersionWithMeta struct {
+	LibraryElementVersion `xorm:"extends"`
+	CreatedByName         string
+	CreatedByEmail        string
+}
+
+// LibraryElementVersionMeta is the frontend DTO for the versions of a library element, without the model.
+type LibraryElementVersionMeta struct {
+	ID                 int64                                  `json:"id"`
+	ElementUID         string                                 `json:"elementUid"`
+	Version            int64                                  `json:"version"`
+	ParentVersion      int64                                  `json:"parentVersion"`
+	RestoredFrom       int64                                  `json:"restoredFrom"`
+	Name               string                                 `json:"name"`
+	FolderUID          string                                 `json:"folderUid"`
+	AffectedDashboards []string                               `json:"affectedDashboards"`
+	Message            string                                 `json:"message"`
+	Created            time.Time                              `json:"created"`
+	CreatedBy          librarypanel.LibraryElementDTOMetaUser `json:"createdBy"`
+}
+
+// LibraryElementVersionDTO is the frontend DTO for a single version of a library element.
+type LibraryElementVersionDTO struct {
+	LibraryElementVersionMeta
+	Model json.RawMessage `json:"model"`
+}
+
 var (
 	// errLibraryElementAlreadyExists is an error for when the user tries to add a library element that already exists.
 	ErrLibraryElementAlreadyExists = errors.New("library element with that name or UID already exists")
@@ -156,6 +201,8 @@ var (
 	ErrLibraryElementInvalidUID = errors.New("uid contains illegal characters")
 	// errLibraryElementUIDTooLong is an error for when the uid of a library element is invalid
 	ErrLibraryElementUIDTooLong = errors.New("uid too long, max 40 characters")
+	// ErrLibraryElementVersionNotFound is an error for when a version of a library element can't be found.
+	ErrLibraryElementVersionNotFound = errors.New("library element version could not be found")
 )
 
 // Commands
@@ -206,6 +253,11 @@ type PatchLibraryElementCommand struct {
 	Version int64 `json:"version" binding:"Required"`
 	// required: false
 	UID string `json:"uid"`
+	// Message describing the change, stored in the version history.
+	// required: false
+	Message string `json:"message"`
+	// RestoredFrom is set when the patch restores a previous version.
+	RestoredFrom int64 `json:"-"`
 }
 
 // GetLibraryElementCommand is the command for getting a library element.
@@ -216,6 +268,34 @@ type GetLibraryElementCommand struct {
 	UID      string
 }
 
+// ListLibraryElementVersionsQuery is the query used for listing the versions of a library element.
+type ListLibraryElementVersionsQuery struct {
+	UID   string
+	Limit int
+	Start int
+}
+
+// RestoreLibraryElementVersionCommand is the command for restoring a previous version of a library element.
+type RestoreLibraryElementVersionCommand struct {
+	// Version of the library element to restore.
+	Version int64 `json:"version" binding:"Required"`
+}
+
+// CalculateLibraryElementDiffCommand is the command for comparing two versions of library elements.
+type CalculateLibraryElementDiffCommand struct {
+	Base CalculateLibraryElementDiffTarget `json:"base" binding:"Required"`
+	New  CalculateLibraryElementDiffTarget `json:"new" binding:"Required"`
+	// The type of diff to compute.
+	// Enum: json,basic
+	DiffType string `json:"diffType" binding:"Required"`
+}
+
+// CalculateLibraryElementDiffTarget identifies one side of a library element diff.
+type CalculateLibraryElementDiffTarget struct {
+	UID     string `json:"uid" binding:"Required"`
+	Version int64  `json:"version" binding:"Required"`
+}
+
 // SearchLibraryElementsQuery is the query used for searching for Elements
 type SearchLibraryElementsQuery struct {
 	PerPage       int
@@ -250,6 +330,16 @@ type LibraryElementConnectionsResponse struct {
 	Result []LibraryElementConnectionDTO `json:"result"`
 }
 
+// LibraryElementVersionsResponse is a response struct for an array of LibraryElementVersionMeta.
+type LibraryElementVersionsResponse struct {
+	Result []LibraryElementVersionMeta `json:"result"`
+}
+
+// LibraryElementVersionResponse is a response struct for LibraryElementVersionDTO.
+type LibraryElementVersionResponse struct {
+	Result LibraryElementVersionDTO `json:"result"`
+}
+
 // DeleteLibraryElementResponse is the response struct for deleting a library element.
 type DeleteLibraryElementResponse struct {
 	ID      int64  `json:"id"`
@@ -267,3 +357,9 @@ const (
 )
 
 const LibraryElementConnectionTableName = "library_element_connection"
+
+const LibraryElementVersionTableName = "library_element_version"
+
+// LibraryElementVersionMigrationMessage is the message of the versions the migration created for the library
+// elements which existed before their history was recorded.
+const LibraryElementVersionMigrationMessage = "Initial save (created by migration)"
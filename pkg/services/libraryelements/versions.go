@@ -0,0 +1,227 @@
+package libraryelements
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/components/dashdiffs"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/metrics"
+	"github.com/grafana/grafana/pkg/kinds/librarypanel"
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	"github.com/grafana/grafana/pkg/services/libraryelements/model"
+)
+
+const (
+	defaultVersionsLimit = 1000
+
+	selectLibraryElementVersionWithMeta = `
+SELECT lev.*, u.login AS created_by_name, u.email AS created_by_email
+FROM ` + model.LibraryElementVersionTableName + ` AS lev
+LEFT JOIN %s AS u ON lev.created_by = u.id
+WHERE lev.element_id=?`
+
+	selectAffectedDashboards = `
+SELECT dashboard.uid FROM ` + model.LibraryElementConnectionTableName + ` AS lec
+INNER JOIN dashboard AS dashboard ON lec.connection_id = dashboard.id
+WHERE lec.element_id=? AND lec.kind=1
+ORDER BY dashboard.uid`
+)
+
+// saveLibraryElementVersion stores the current state of a library element in its version history,
+// together with the UIDs of the dashboards the change applies to.
+func saveLibraryElementVersion(session *db.Session, element model.LibraryElement, parentVersion int64, restoredFrom int64, message string) error {
+	affected := make([]string, 0)
+	if err := session.SQL(selectAffectedDashboards, element.ID).Find(&affected); err != nil {
+		return err
+	}
+
+	version := model.LibraryElementVersion{
+		OrgID:              element.OrgID,
+		ElementID:          element.ID,
+		Version:            element.Version,
+		ParentVersion:      parentVersion,
+		RestoredFrom:       restoredFrom,
+		Name:               element.Name,
+		FolderUID:          element.FolderUID,
+		Model:              element.Model,
+		AffectedDashboards: affected,
+		Message:            message,
+		Created:            element.Updated,
+		CreatedBy:          element.UpdatedBy,
+	}
+	_, err := session.Insert(&version)
+	return err
+}
+
+// getLibraryElementForHistory gets a library element and makes sure the user is allowed to see its history.
+// Like for dashboards, the history is only available to users who can edit the element.
+func (l *LibraryElementService) getLibraryElementForHistory(c context.Context, session *db.Session, signedInUser identity.Requester, uid string) (model.LibraryElementWithMeta, error) {
+	element, err := GetLibraryElement(l.SQLStore.GetDialect(), session, uid, signedInUser.GetOrgID())
+	if err != nil {
+		return model.LibraryElementWithMeta{}, err
+	}
+	metrics.MFolderIDsServiceCount.WithLabelValues(metrics.LibraryElements).Inc()
+	// nolint:staticcheck
+	if err := l.requireEditPermissionsOnFolder(c, signedInUser, element.FolderID); err != nil {
+		return model.LibraryElementWithMeta{}, err
+	}
+	return element, nil
+}
+
+func (l *LibraryElementService) findLibraryElementVersions(session *db.Session, elementID int64, where string, limit int, start int, params ...any) ([]model.LibraryElementVersionWithMeta, error) {
+	versions := make([]model.LibraryElementVersionWithMeta, 0)
+	sql := fmt.Sprintf(selectLibraryElementVersionWithMeta, l.SQLStore.GetDialect().Quote("user")) + where +
+		" ORDER BY lev.version DESC" + l.SQLStore.GetDialect().LimitOffset(int64(limit), int64(start))
+	err := session.SQL(sql, append([]any{elementID}, params...)...).Find(&versions)
+	return versions, err
+}
+
+// getLibraryElementVersions gets the versions of a library element, newest first.
+func (l *LibraryElementService) getLibraryElementVersions(c context.Context, signedInUser identity.Requester, query model.ListLibraryElementVersionsQuery) ([]model.LibraryElementVersionMeta, error) {
+	if query.Limit <= 0 {
+		query.Limit = defaultVersionsLimit
+	}
+	if query.Start < 0 {
+		query.Start = 0
+	}
+
+	var result []model.LibraryElementVersionMeta
+	err := l.SQLStore.WithDbSession(c, func(session *db.Session) error {
+		element, err := l.getLibraryElementForHistory(c, session, signedInUser, query.UID)
+		if err != nil {
+			return err
+		}
+
+		versions, err := l.findLibraryElementVersions(session, element.ID, "", query.Limit, query.Start)
+		if err != nil {
+			return err
+		}
+
+		result = make([]model.LibraryElementVersionMeta, 0, len(versions))
+		for _, version := range versions {
+			result = append(result, l.toLibraryElementVersionMeta(element.UID, version))
+		}
+		return nil
+	})
+
+	return result, err
+}
+
+// getLibraryElementVersion gets a single version of a library element.
+func (l *LibraryElementService) getLibraryElementVersion(c context.Context, signedInUser identity.Requester, uid string, version int64) (model.LibraryElementVersionDTO, error) {
+	var dto model.LibraryElementVersionDTO
+	err := l.SQLStore.WithDbSession(c, func(session *db.Session) error {
+		element, err := l.getLibraryElementForHistory(c, session, signedInUser, uid)
+		if err != nil {
+			return err
+		}
+
+		versions, err := l.findLibraryElementVersions(session, element.ID, " AND lev.version=?", 1, 0, version)
+		if err != nil {
+			return err
+		}
+		if len(versions) == 0 {
+			return model.ErrLibraryElementVersionNotFound
+		}
+
+		dto = model.LibraryElementVersionDTO{
+			LibraryElementVersionMeta: l.toLibraryElementVersionMeta(element.UID, versions[0]),
+			Model:                     versions[0].Model,
+		}
+		return nil
+	})
+
+	return dto, err
+}
+
+// restoreLibraryElementVersion saves the name and model of a previous version as the newest version
+// of a library element. The element stays in its current folder.
+func (l *LibraryElementService) restoreLibraryElementVersion(c context.Context, signedInUser identity.Requester, uid string, cmd model.RestoreLibraryElementVersionCommand) (model.LibraryElementDTO, error) {
+	version, err := l.getLibraryElementVersion(c, signedInUser, uid, cmd.Version)
+	if err != nil {
+		return model.LibraryElementDTO{}, err
+	}
+
+	var current model.LibraryElementWithMeta
+	err = l.SQLStore.WithDbSession(c, func(session *db.Session) error {
+		current, err = GetLibraryElement(l.SQLStore.GetDialect(), session, uid, signedInUser.GetOrgID())
+		return err
+	})
+	if err != nil {
+		return model.LibraryElementDTO{}, err
+	}
+
+	metrics.MFolderIDsServiceCount.WithLabelValues(metrics.LibraryElements).Inc()
+	return l.patchLibraryElement(c, signedInUser, model.PatchLibraryElementCommand{
+		FolderID:     -1, // nolint:staticcheck
+		Name:         version.Name,
+		Model:        version.Model,
+		Kind:         current.Kind,
+		Version:      current.Version,
+		Message:      fmt.Sprintf("Restored from version %d", version.Version),
+		RestoredFrom: version.Version,
+	}, uid)
+}
+
+// calculateLibraryElementDiff computes the diff between the models of two library element versions.
+func (l *LibraryElementService) calculateLibraryElementDiff(c context.Context, signedInUser identity.Requester, cmd model.CalculateLibraryElementDiffCommand) (*dashdiffs.Result, error) {
+	base, err := l.getLibraryElementVersion(c, signedInUser, cmd.Base.UID, cmd.Base.Version)
+	if err != nil {
+		return nil, err
+	}
+	newVersion, err := l.getLibraryElementVersion(c, signedInUser, cmd.New.UID, cmd.New.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	baseData, err := simplejson.NewJson(base.Model)
+	if err != nil {
+		return nil, err
+	}
+	newData, err := simplejson.NewJson(newVersion.Model)
+	if err != nil {
+		return nil, err
+	}
+
+	options := dashdiffs.Options{
+		OrgId:    signedInUser.GetOrgID(),
+		DiffType: dashdiffs.ParseDiffType(cmd.DiffType),
+	}
+	return dashdiffs.CalculateDiff(c, &options, baseData, newData)
+}
+
+func (l *LibraryElementService) toLibraryElementVersionMeta(elementUID string, version model.LibraryElementVersionWithMeta) model.LibraryElementVersionMeta {
+	msg := version.Message
+	switch {
+	case version.RestoredFrom > 0:
+		msg = fmt.Sprintf("Restored from version %d", version.RestoredFrom)
+	case version.ParentVersion == 0:
+		msg = "Initial save"
+	}
+
+	affected := version.AffectedDashboards
+	if affected == nil {
+		affected = []string{}
+	}
+
+	return model.LibraryElementVersionMeta{
+		ID:                 version.ID,
+		ElementUID:         elementUID,
+		Version:            version.Version,
+		ParentVersion:      version.ParentVersion,
+		RestoredFrom:       version.RestoredFrom,
+		Name:               version.Name,
+		FolderUID:          version.FolderUID,
+		AffectedDashboards: affected,
+		Message:            msg,
+		Created:            version.Created,
+		CreatedBy: librarypanel.LibraryElementDTOMetaUser{
+			Id:        version.CreatedBy,
+			Name:      version.CreatedByName,
+			AvatarUrl: dtos.GetGravatarUrl(l.Cfg, version.CreatedByEmail),
+		},
+	}
+}
@@ -98,7 +98,7 @@ func NewScreenshotImageServiceFromCfg(cfg *setting.Cfg, db *store.DBstore, ds da
 
 		// Image uploading is an optional feature
 		if cfg.UnifiedAlerting.Screenshots.UploadExternalImageStorage {
-			m, err := imguploader.NewImageUploader(cfg)
+			m, err := imguploader.NewImageUploader(cfg, db.SQLStore)
 			if err != nil {
 				return nil, fmt.Errorf("failed to initialize uploading screenshot service: %w", err)
 			}
@@ -176,7 +176,7 @@ func (s *ScreenshotImageService) NewImage(ctx context.Context, r *models.AlertRu
 
 		// Uploading images is optional
 		if s.uploads != nil {
-			if image, err = s.uploads.Upload(ctx, image); err != nil {
+			if image, err = s.uploads.Upload(imguploader.ContextWithOrgID(ctx, r.OrgID), image); err != nil {
 				logger.Warn("Failed to upload image", "error", err)
 			} else {
 				logger.Debug("Uploaded image", "url", image.URL)
@@ -11,6 +11,7 @@ import (
 	"golang.org/x/exp/maps"
 	"golang.org/x/exp/slices"
 
+	"github.com/grafana/grafana/pkg/events"
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/auth/identity"
@@ -70,6 +71,15 @@ func (st DBstore) DeleteAlertRulesByUID(ctx context.Context, orgID int64, ruleUI
 			return err
 		}
 		logger.Debug("Deleted alert instances", "count", rows)
+
+		now := TimeNow()
+		for _, uid := range ruleUID {
+			sess.PublishAfterCommit(&events.AlertRuleDeleted{
+				Timestamp: now,
+				UID:       uid,
+				OrgID:     orgID,
+			})
+		}
 		return nil
 	})
 }
@@ -185,6 +195,17 @@ func (st DBstore) InsertAlertRules(ctx context.Context, rules []ngmodels.AlertRu
 				return fmt.Errorf("failed to create new rule versions: %w", err)
 			}
 		}
+
+		for _, r := range newRules {
+			sess.PublishAfterCommit(&events.AlertRuleCreated{
+				Timestamp:    r.Updated,
+				Title:        r.Title,
+				UID:          r.UID,
+				OrgID:        r.OrgID,
+				NamespaceUID: r.NamespaceUID,
+				RuleGroup:    r.RuleGroup,
+			})
+		}
 		return nil
 	})
 }
@@ -248,6 +269,17 @@ func (st DBstore) UpdateAlertRules(ctx context.Context, rules []ngmodels.UpdateR
 				return fmt.Errorf("failed to create new rule versions: %w", err)
 			}
 		}
+
+		for _, r := range rules {
+			sess.PublishAfterCommit(&events.AlertRuleUpdated{
+				Timestamp:    r.New.Updated,
+				Title:        r.New.Title,
+				UID:          r.New.UID,
+				OrgID:        r.New.OrgID,
+				NamespaceUID: r.New.NamespaceUID,
+				RuleGroup:    r.New.RuleGroup,
+			})
+		}
 		return nil
 	})
 }
@@ -240,6 +240,7 @@ func (ss *sqlStore) Delete(ctx context.Context, cmd *org.DeleteOrgCommand) error
 			"DELETE FROM alert_rule_version WHERE rule_org_id = ?",
 			"DELETE FROM alert WHERE org_id = ?",
 			"DELETE FROM annotation WHERE org_id = ?",
+			"DELETE FROM annotation_webhook WHERE org_id = ?",
 			"DELETE FROM kv_store WHERE org_id = ?",
 			"DELETE FROM team WHERE org_id = ?",
 			"DELETE FROM team_member WHERE org_id = ?",
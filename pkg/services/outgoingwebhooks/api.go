@@ -0,0 +1,269 @@
+package outgoingwebhooks
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+const (
+	defaultDeliveriesLimit = 100
+	maxDeliveriesLimit     = 1000
+)
+
+func (s *WebhooksService) registerAPIEndpoints() {
+	s.routeRegister.Group("/api/admin/webhooks", func(webhooks routing.RouteRegister) {
+		webhooks.Get("/", routing.Wrap(s.listHandler))
+		webhooks.Post("/", routing.Wrap(s.createHandler))
+
+		webhooks.Group("/:uid", func(webhook routing.RouteRegister) {
+			webhook.Get("/", routing.Wrap(s.getHandler))
+			webhook.Put("/", routing.Wrap(s.updateHandler))
+			webhook.Delete("/", routing.Wrap(s.deleteHandler))
+			webhook.Get("/deliveries", routing.Wrap(s.getDeliveriesHandler))
+			webhook.Post("/deliveries/:deliveryUID/retry", routing.Wrap(s.retryDeliveryHandler))
+		})
+	}, middleware.ReqGrafanaAdmin)
+}
+
+// swagger:route GET /admin/webhooks outgoing_webhooks listOutgoingWebhooks
+//
+// Get all outgoing webhooks.
+//
+// Only available to Grafana server admins.
+//
+// Responses:
+// 200: listOutgoingWebhooksResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *WebhooksService) listHandler(c *contextmodel.ReqContext) response.Response {
+	webhooks, err := s.ListWebhooks(c.Req.Context())
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get webhooks", err)
+	}
+	return response.JSON(http.StatusOK, webhooks)
+}
+
+// swagger:route POST /admin/webhooks outgoing_webhooks createOutgoingWebhook
+//
+// Create an outgoing webhook.
+//
+// The secret used to sign the payloads is only returned in this response.
+//
+// Responses:
+// 200: createOutgoingWebhookResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *WebhooksService) createHandler(c *contextmodel.ReqContext) response.Response {
+	cmd := CreateWebhookCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	webhook, secret, err := s.CreateWebhook(c.Req.Context(), cmd)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to create webhook", err)
+	}
+	return response.JSON(http.StatusOK, CreateWebhookResponseBody{Webhook: webhook, Secret: secret})
+}
+
+// swagger:route GET /admin/webhooks/{uid} outgoing_webhooks getOutgoingWebhook
+//
+// Get an outgoing webhook.
+//
+// Responses:
+// 200: getOutgoingWebhookResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *WebhooksService) getHandler(c *contextmodel.ReqContext) response.Response {
+	webhook, err := s.GetWebhook(c.Req.Context(), web.Params(c.Req)[":uid"])
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get webhook", err)
+	}
+	return response.JSON(http.StatusOK, webhook)
+}
+
+// swagger:route PUT /admin/webhooks/{uid} outgoing_webhooks updateOutgoingWebhook
+//
+// Update an outgoing webhook.
+//
+// The secret is kept when it's empty.
+//
+// Responses:
+// 200: getOutgoingWebhookResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *WebhooksService) updateHandler(c *contextmodel.ReqContext) response.Response {
+	cmd := UpdateWebhookCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.UID = web.Params(c.Req)[":uid"]
+
+	webhook, err := s.UpdateWebhook(c.Req.Context(), cmd)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to update webhook", err)
+	}
+	return response.JSON(http.StatusOK, webhook)
+}
+
+// swagger:route DELETE /admin/webhooks/{uid} outgoing_webhooks deleteOutgoingWebhook
+//
+// Delete an outgoing webhook and the history of its deliveries.
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *WebhooksService) deleteHandler(c *contextmodel.ReqContext) response.Response {
+	if err := s.DeleteWebhook(c.Req.Context(), web.Params(c.Req)[":uid"]); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to delete webhook", err)
+	}
+	return response.Success("Webhook deleted")
+}
+
+// swagger:route GET /admin/webhooks/{uid}/deliveries outgoing_webhooks getOutgoingWebhookDeliveries
+//
+// Get the latest deliveries of an outgoing webhook.
+//
+// Responses:
+// 200: getOutgoingWebhookDeliveriesResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *WebhooksService) getDeliveriesHandler(c *contextmodel.ReqContext) response.Response {
+	query := GetDeliveriesQuery{
+		WebhookUID: web.Params(c.Req)[":uid"],
+		Status:     DeliveryStatus(c.Query("status")),
+		Limit:      c.QueryInt("limit"),
+	}
+	switch query.Status {
+	case "", DeliveryStatusPending, DeliveryStatusSuccess, DeliveryStatusFailed:
+	default:
+		return response.Error(http.StatusBadRequest, "Invalid delivery status", nil)
+	}
+	if query.Limit <= 0 {
+		query.Limit = defaultDeliveriesLimit
+	}
+	if query.Limit > maxDeliveriesLimit {
+		query.Limit = maxDeliveriesLimit
+	}
+
+	deliveries, err := s.GetDeliveries(c.Req.Context(), query)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get webhook deliveries", err)
+	}
+	return response.JSON(http.StatusOK, deliveries)
+}
+
+// swagger:route POST /admin/webhooks/{uid}/deliveries/{deliveryUID}/retry outgoing_webhooks retryOutgoingWebhookDelivery
+//
+// Send a delivery of an outgoing webhook again.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *WebhooksService) retryDeliveryHandler(c *contextmodel.ReqContext) response.Response {
+	params := web.Params(c.Req)
+	if err := s.RetryDelivery(c.Req.Context(), params[":uid"], params[":deliveryUID"]); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to retry webhook delivery", err)
+	}
+	return response.Success("Webhook delivery scheduled")
+}
+
+// swagger:model
+type CreateWebhookResponseBody struct {
+	Webhook
+	// The secret used to sign the payloads, only returned when the webhook is created.
+	Secret string `json:"secret"`
+}
+
+// swagger:parameters createOutgoingWebhook
+type CreateOutgoingWebhookParams struct {
+	// in:body
+	// required:true
+	Body CreateWebhookCommand `json:"body"`
+}
+
+// swagger:parameters getOutgoingWebhook deleteOutgoingWebhook getOutgoingWebhookDeliveries
+type OutgoingWebhookUIDParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+}
+
+// swagger:parameters updateOutgoingWebhook
+type UpdateOutgoingWebhookParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+	// in:body
+	// required:true
+	Body UpdateWebhookCommand `json:"body"`
+}
+
+// swagger:parameters getOutgoingWebhookDeliveries
+type GetOutgoingWebhookDeliveriesParams struct {
+	// in:query
+	// required:false
+	// Enum: pending,success,failed
+	Status string `json:"status"`
+	// in:query
+	// required:false
+	// default:100
+	Limit int `json:"limit"`
+}
+
+// swagger:parameters retryOutgoingWebhookDelivery
+type RetryOutgoingWebhookDeliveryParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+	// in:path
+	// required:true
+	DeliveryUID string `json:"deliveryUID"`
+}
+
+// swagger:response listOutgoingWebhooksResponse
+type ListOutgoingWebhooksResponse struct {
+	// in: body
+	Body []Webhook `json:"body"`
+}
+
+// swagger:response createOutgoingWebhookResponse
+type CreateOutgoingWebhookResponse struct {
+	// in: body
+	Body CreateWebhookResponseBody `json:"body"`
+}
+
+// swagger:response getOutgoingWebhookResponse
+type GetOutgoingWebhookResponse struct {
+	// in: body
+	Body Webhook `json:"body"`
+}
+
+// swagger:response getOutgoingWebhookDeliveriesResponse
+type GetOutgoingWebhookDeliveriesResponse struct {
+	// in: body
+	Body []Delivery `json:"body"`
+}
@@ -0,0 +1,329 @@
+package outgoingwebhooks
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+type webhookRow struct {
+	ID      int64     `xorm:"pk autoincr 'id'"`
+	UID     string    `xorm:"uid"`
+	OrgID   int64     `xorm:"org_id"`
+	Name    string    `xorm:"name"`
+	URL     string    `xorm:"url"`
+	Events  string    `xorm:"events"`
+	Secret  string    `xorm:"secret"`
+	Enabled bool      `xorm:"enabled"`
+	Created time.Time `xorm:"created"`
+	Updated time.Time `xorm:"updated"`
+}
+
+func (webhookRow) TableName() string { return "outgoing_webhook" }
+
+func (r webhookRow) toWebhook() Webhook {
+	events := []string{}
+	// the events are validated before they are stored
+	_ = json.Unmarshal([]byte(r.Events), &events)
+	return Webhook{
+		UID:     r.UID,
+		OrgID:   r.OrgID,
+		Name:    r.Name,
+		URL:     r.URL,
+		Events:  events,
+		Enabled: r.Enabled,
+		Created: r.Created,
+		Updated: r.Updated,
+	}
+}
+
+type deliveryRow struct {
+	ID             int64          `xorm:"pk autoincr 'id'"`
+	UID            string         `xorm:"uid"`
+	WebhookUID     string         `xorm:"webhook_uid"`
+	Event          string         `xorm:"event"`
+	Payload        string         `xorm:"payload"`
+	Status         DeliveryStatus `xorm:"status"`
+	Attempts       int            `xorm:"attempts"`
+	NextAttemptAt  int64          `xorm:"next_attempt_at"`
+	ResponseStatus int            `xorm:"response_status"`
+	Error          string         `xorm:"error"`
+	Created        int64          `xorm:"created"`
+	Updated        int64          `xorm:"updated"`
+}
+
+func (deliveryRow) TableName() string { return "outgoing_webhook_delivery" }
+
+func (r deliveryRow) toDelivery() Delivery {
+	d := Delivery{
+		UID:            r.UID,
+		WebhookUID:     r.WebhookUID,
+		Event:          r.Event,
+		Payload:        json.RawMessage(r.Payload),
+		Status:         r.Status,
+		Attempts:       r.Attempts,
+		ResponseStatus: r.ResponseStatus,
+		Error:          r.Error,
+		Created:        time.Unix(r.Created, 0),
+		Updated:        time.Unix(r.Updated, 0),
+	}
+	if r.Status == DeliveryStatusPending {
+		next := time.Unix(r.NextAttemptAt, 0)
+		d.NextAttempt = &next
+	}
+	return d
+}
+
+func (s *WebhooksService) createWebhook(ctx context.Context, cmd CreateWebhookCommand) (Webhook, error) {
+	secret, err := s.encryptSecret(ctx, cmd.Secret)
+	if err != nil {
+		return Webhook{}, err
+	}
+	events, err := json.Marshal(nonNil(cmd.Events))
+	if err != nil {
+		return Webhook{}, err
+	}
+
+	now := s.now()
+	row := webhookRow{
+		UID:     util.GenerateShortUID(),
+		OrgID:   cmd.OrgID,
+		Name:    cmd.Name,
+		URL:     cmd.URL,
+		Events:  string(events),
+		Secret:  secret,
+		Enabled: cmd.Enabled == nil || *cmd.Enabled,
+		Created: now,
+		Updated: now,
+	}
+	err = s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.UseBool("enabled").Insert(&row)
+		return err
+	})
+	return row.toWebhook(), err
+}
+
+func (s *WebhooksService) updateWebhook(ctx context.Context, cmd UpdateWebhookCommand) (Webhook, error) {
+	var secret string
+	if cmd.Secret != "" {
+		var err error
+		if secret, err = s.encryptSecret(ctx, cmd.Secret); err != nil {
+			return Webhook{}, err
+		}
+	}
+	events, err := json.Marshal(nonNil(cmd.Events))
+	if err != nil {
+		return Webhook{}, err
+	}
+
+	var row webhookRow
+	err = s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		has, err := sess.Where("uid = ?", cmd.UID).Get(&row)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return ErrWebhookNotFound.Errorf("webhook %s not found", cmd.UID)
+		}
+
+		row.OrgID = cmd.OrgID
+		row.Name = cmd.Name
+		row.URL = cmd.URL
+		row.Events = string(events)
+		row.Enabled = cmd.Enabled
+		row.Updated = s.now()
+		if secret != "" {
+			row.Secret = secret
+		}
+		_, err = sess.ID(row.ID).AllCols().Update(&row)
+		return err
+	})
+	return row.toWebhook(), err
+}
+
+func (s *WebhooksService) getWebhook(ctx context.Context, uid string) (Webhook, error) {
+	row, err := s.getWebhookRow(ctx, uid)
+	if err != nil {
+		return Webhook{}, err
+	}
+	return row.toWebhook(), nil
+}
+
+func (s *WebhooksService) getWebhookRow(ctx context.Context, uid string) (webhookRow, error) {
+	var row webhookRow
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		has, err := sess.Where("uid = ?", uid).Get(&row)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return ErrWebhookNotFound.Errorf("webhook %s not found", uid)
+		}
+		return nil
+	})
+	return row, err
+}
+
+func (s *WebhooksService) listWebhooks(ctx context.Context) ([]Webhook, error) {
+	rows := make([]webhookRow, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.OrderBy("name ASC").Find(&rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	webhooks := make([]Webhook, 0, len(rows))
+	for _, row := range rows {
+		webhooks = append(webhooks, row.toWebhook())
+	}
+	return webhooks, nil
+}
+
+// deleteWebhook deletes the webhook and the history of its deliveries.
+func (s *WebhooksService) deleteWebhook(ctx context.Context, uid string) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		affected, err := sess.Where("uid = ?", uid).Delete(&webhookRow{})
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return ErrWebhookNotFound.Errorf("webhook %s not found", uid)
+		}
+		_, err = sess.Where("webhook_uid = ?", uid).Delete(&deliveryRow{})
+		return err
+	})
+}
+
+func (s *WebhooksService) insertDeliveries(ctx context.Context, rows []deliveryRow) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Insert(&rows)
+		return err
+	})
+}
+
+func (s *WebhooksService) getDueDeliveries(ctx context.Context, limit int) ([]deliveryRow, error) {
+	rows := make([]deliveryRow, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("status = ? AND next_attempt_at <= ?", DeliveryStatusPending, s.now().Unix()).
+			OrderBy("next_attempt_at ASC").Limit(limit).Find(&rows)
+	})
+	return rows, err
+}
+
+// claimDelivery counts a new attempt of the delivery and schedules the next one, in
+// case this one fails. It returns false if another instance claimed the attempt first.
+func (s *WebhooksService) claimDelivery(ctx context.Context, row *deliveryRow, nextAttemptAt time.Time) (bool, error) {
+	var claimed bool
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec("UPDATE outgoing_webhook_delivery SET attempts = ?, next_attempt_at = ?, updated = ? WHERE id = ? AND attempts = ? AND status = ?",
+			row.Attempts+1, nextAttemptAt.Unix(), s.now().Unix(), row.ID, row.Attempts, DeliveryStatusPending)
+		if err != nil {
+			return err
+		}
+		affected, err := res.RowsAffected()
+		claimed = affected == 1
+		return err
+	})
+	if claimed {
+		row.Attempts++
+		row.NextAttemptAt = nextAttemptAt.Unix()
+	}
+	return claimed, err
+}
+
+func (s *WebhooksService) finishAttempt(ctx context.Context, row *deliveryRow) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Exec("UPDATE outgoing_webhook_delivery SET status = ?, response_status = ?, error = ?, updated = ? WHERE id = ?",
+			row.Status, row.ResponseStatus, row.Error, s.now().Unix(), row.ID)
+		return err
+	})
+}
+
+func (s *WebhooksService) getDeliveries(ctx context.Context, query GetDeliveriesQuery) ([]Delivery, error) {
+	rows := make([]deliveryRow, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		sess.Where("webhook_uid = ?", query.WebhookUID)
+		if query.Status != "" {
+			sess.And("status = ?", query.Status)
+		}
+		return sess.OrderBy("created DESC, id DESC").Limit(query.Limit).Find(&rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries := make([]Delivery, 0, len(rows))
+	for _, row := range rows {
+		deliveries = append(deliveries, row.toDelivery())
+	}
+	return deliveries, nil
+}
+
+// retryDelivery schedules a new series of attempts of a finished delivery.
+func (s *WebhooksService) retryDelivery(ctx context.Context, webhookUID, deliveryUID string) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		var row deliveryRow
+		has, err := sess.Where("webhook_uid = ? AND uid = ?", webhookUID, deliveryUID).Get(&row)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return ErrDeliveryNotFound.Errorf("delivery %s of webhook %s not found", deliveryUID, webhookUID)
+		}
+		if row.Status == DeliveryStatusPending {
+			return ErrDeliveryPending.Errorf("delivery %s is pending", deliveryUID)
+		}
+
+		now := s.now().Unix()
+		_, err = sess.Exec("UPDATE outgoing_webhook_delivery SET status = ?, attempts = 0, next_attempt_at = ?, updated = ? WHERE id = ?",
+			DeliveryStatusPending, now, now, row.ID)
+		return err
+	})
+}
+
+func (s *WebhooksService) deleteDeliveriesOlderThan(ctx context.Context, olderThan time.Time) (int64, error) {
+	var affected int64
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec("DELETE FROM outgoing_webhook_delivery WHERE created < ? AND status <> ?", olderThan.Unix(), DeliveryStatusPending)
+		if err != nil {
+			return err
+		}
+		affected, err = res.RowsAffected()
+		return err
+	})
+	return affected, err
+}
+
+// encryptSecret encrypts the secret, which must be done outside of database transactions.
+func (s *WebhooksService) encryptSecret(ctx context.Context, secret string) (string, error) {
+	encrypted, err := s.secrets.Encrypt(ctx, []byte(secret), secrets.WithoutScope())
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(encrypted), nil
+}
+
+func (s *WebhooksService) decryptSecret(ctx context.Context, secret string) (string, error) {
+	decoded, err := base64.StdEncoding.DecodeString(secret)
+	if err != nil {
+		return "", err
+	}
+	decrypted, err := s.secrets.Decrypt(ctx, decoded)
+	if err != nil {
+		return "", err
+	}
+	return string(decrypted), nil
+}
+
+func nonNil(events []string) []string {
+	if events == nil {
+		return []string{}
+	}
+	return events
+}
@@ -0,0 +1,82 @@
+package outgoingwebhooks
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/events"
+)
+
+const (
+	kindDashboard  = "dashboard"
+	kindFolder     = "folder"
+	kindDataSource = "datasource"
+	kindAlertRule  = "alert_rule"
+	kindUser       = "user"
+	kindTeam       = "team"
+)
+
+// addEventListeners subscribes to the change events published on the bus after the
+// changes are committed to the database.
+func (s *WebhooksService) addEventListeners(b bus.Bus) {
+	b.AddEventListener(func(ctx context.Context, e *events.DashboardCreated) error {
+		return s.enqueue(ctx, EventDashboardCreated, e.OrgID, Resource{Kind: kindDashboard, ID: e.ID, UID: e.UID, Name: e.Title, FolderUID: e.FolderUID})
+	})
+	b.AddEventListener(func(ctx context.Context, e *events.DashboardUpdated) error {
+		return s.enqueue(ctx, EventDashboardUpdated, e.OrgID, Resource{Kind: kindDashboard, ID: e.ID, UID: e.UID, Name: e.Title, FolderUID: e.FolderUID})
+	})
+	b.AddEventListener(func(ctx context.Context, e *events.DashboardDeleted) error {
+		return s.enqueue(ctx, EventDashboardDeleted, e.OrgID, Resource{Kind: kindDashboard, ID: e.ID, UID: e.UID, Name: e.Title, FolderUID: e.FolderUID})
+	})
+
+	b.AddEventListener(func(ctx context.Context, e *events.FolderCreated) error {
+		return s.enqueue(ctx, EventFolderCreated, e.OrgID, Resource{Kind: kindFolder, ID: e.ID, UID: e.UID, Name: e.Title})
+	})
+	b.AddEventListener(func(ctx context.Context, e *events.FolderUpdated) error {
+		return s.enqueue(ctx, EventFolderUpdated, e.OrgID, Resource{Kind: kindFolder, ID: e.ID, UID: e.UID, Name: e.Title})
+	})
+	b.AddEventListener(func(ctx context.Context, e *events.FolderDeleted) error {
+		return s.enqueue(ctx, EventFolderDeleted, e.OrgID, Resource{Kind: kindFolder, ID: e.ID, UID: e.UID, Name: e.Title})
+	})
+
+	b.AddEventListener(func(ctx context.Context, e *events.DataSourceCreated) error {
+		return s.enqueue(ctx, EventDataSourceCreated, e.OrgID, Resource{Kind: kindDataSource, ID: e.ID, UID: e.UID, Name: e.Name})
+	})
+	b.AddEventListener(func(ctx context.Context, e *events.DataSourceUpdated) error {
+		return s.enqueue(ctx, EventDataSourceUpdated, e.OrgID, Resource{Kind: kindDataSource, ID: e.ID, UID: e.UID, Name: e.Name})
+	})
+	b.AddEventListener(func(ctx context.Context, e *events.DataSourceDeleted) error {
+		return s.enqueue(ctx, EventDataSourceDeleted, e.OrgID, Resource{Kind: kindDataSource, ID: e.ID, UID: e.UID, Name: e.Name})
+	})
+
+	b.AddEventListener(func(ctx context.Context, e *events.AlertRuleCreated) error {
+		return s.enqueue(ctx, EventAlertRuleCreated, e.OrgID, Resource{Kind: kindAlertRule, UID: e.UID, Name: e.Title, FolderUID: e.NamespaceUID})
+	})
+	b.AddEventListener(func(ctx context.Context, e *events.AlertRuleUpdated) error {
+		return s.enqueue(ctx, EventAlertRuleUpdated, e.OrgID, Resource{Kind: kindAlertRule, UID: e.UID, Name: e.Title, FolderUID: e.NamespaceUID})
+	})
+	b.AddEventListener(func(ctx context.Context, e *events.AlertRuleDeleted) error {
+		return s.enqueue(ctx, EventAlertRuleDeleted, e.OrgID, Resource{Kind: kindAlertRule, UID: e.UID})
+	})
+
+	// users don't belong to a single organization, their events are sent to the webhooks of all organizations
+	b.AddEventListener(func(ctx context.Context, e *events.UserCreated) error {
+		return s.enqueue(ctx, EventUserCreated, 0, Resource{Kind: kindUser, ID: e.Id, Name: e.Login})
+	})
+	b.AddEventListener(func(ctx context.Context, e *events.UserUpdated) error {
+		return s.enqueue(ctx, EventUserUpdated, 0, Resource{Kind: kindUser, ID: e.Id, Name: e.Login})
+	})
+	b.AddEventListener(func(ctx context.Context, e *events.UserDeleted) error {
+		return s.enqueue(ctx, EventUserDeleted, 0, Resource{Kind: kindUser, ID: e.Id, Name: e.Login})
+	})
+
+	b.AddEventListener(func(ctx context.Context, e *events.TeamCreated) error {
+		return s.enqueue(ctx, EventTeamCreated, e.OrgID, Resource{Kind: kindTeam, ID: e.ID, UID: e.UID, Name: e.Name})
+	})
+	b.AddEventListener(func(ctx context.Context, e *events.TeamUpdated) error {
+		return s.enqueue(ctx, EventTeamUpdated, e.OrgID, Resource{Kind: kindTeam, ID: e.ID, Name: e.Name})
+	})
+	b.AddEventListener(func(ctx context.Context, e *events.TeamDeleted) error {
+		return s.enqueue(ctx, EventTeamDeleted, e.OrgID, Resource{Kind: kindTeam, ID: e.ID, UID: e.UID, Name: e.Name})
+	})
+}
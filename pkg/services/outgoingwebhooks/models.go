@@ -0,0 +1,212 @@
+package outgoingwebhooks
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/util/errutil"
+)
+
+var (
+	ErrWebhookNotFound  = errutil.NotFound("outgoingwebhooks.notFound", errutil.WithPublicMessage("Webhook not found"))
+	ErrDeliveryNotFound = errutil.NotFound("outgoingwebhooks.deliveryNotFound", errutil.WithPublicMessage("Webhook delivery not found"))
+	ErrInvalidName      = errutil.BadRequest("outgoingwebhooks.invalidName", errutil.WithPublicMessage("Webhook name is required"))
+	ErrInvalidURL       = errutil.BadRequest("outgoingwebhooks.invalidURL", errutil.WithPublicMessage("Webhook URL must be an absolute http or https URL"))
+	ErrInvalidEvent     = errutil.BadRequest("outgoingwebhooks.invalidEvent", errutil.WithPublicMessage("Unknown webhook event"))
+	ErrDeliveryPending  = errutil.BadRequest("outgoingwebhooks.deliveryPending", errutil.WithPublicMessage("Webhook delivery is still pending"))
+)
+
+// The events which can be sent to webhooks, named <resource kind>.<action>.
+const (
+	EventDashboardCreated  = "dashboard.created"
+	EventDashboardUpdated  = "dashboard.updated"
+	EventDashboardDeleted  = "dashboard.deleted"
+	EventFolderCreated     = "folder.created"
+	EventFolderUpdated     = "folder.updated"
+	EventFolderDeleted     = "folder.deleted"
+	EventDataSourceCreated = "datasource.created"
+	EventDataSourceUpdated = "datasource.updated"
+	EventDataSourceDeleted = "datasource.deleted"
+	EventAlertRuleCreated  = "alert_rule.created"
+	EventAlertRuleUpdated  = "alert_rule.updated"
+	EventAlertRuleDeleted  = "alert_rule.deleted"
+	EventUserCreated       = "user.created"
+	EventUserUpdated       = "user.updated"
+	EventUserDeleted       = "user.deleted"
+	EventTeamCreated       = "team.created"
+	EventTeamUpdated       = "team.updated"
+	EventTeamDeleted       = "team.deleted"
+)
+
+var allEvents = []string{
+	EventDashboardCreated, EventDashboardUpdated, EventDashboardDeleted,
+	EventFolderCreated, EventFolderUpdated, EventFolderDeleted,
+	EventDataSourceCreated, EventDataSourceUpdated, EventDataSourceDeleted,
+	EventAlertRuleCreated, EventAlertRuleUpdated, EventAlertRuleDeleted,
+	EventUserCreated, EventUserUpdated, EventUserDeleted,
+	EventTeamCreated, EventTeamUpdated, EventTeamDeleted,
+}
+
+type DeliveryStatus string
+
+const (
+	DeliveryStatusPending DeliveryStatus = "pending"
+	DeliveryStatusSuccess DeliveryStatus = "success"
+	DeliveryStatusFailed  DeliveryStatus = "failed"
+)
+
+// Webhook is an endpoint the change events of Grafana resources are sent to.
+type Webhook struct {
+	UID string `json:"uid"`
+	// OrgID is the organization the events are sent for, 0 for all organizations.
+	// The events of users, which don't belong to an organization, are only sent
+	// to webhooks of all organizations.
+	OrgID int64  `json:"orgId"`
+	Name  string `json:"name"`
+	URL   string `json:"url"`
+	// Events is the list of events sent to the webhook, eg. dashboard.updated or
+	// dashboard.* for all the events of dashboards. All events are sent when empty.
+	Events  []string  `json:"events"`
+	Enabled bool      `json:"enabled"`
+	Created time.Time `json:"created"`
+	Updated time.Time `json:"updated"`
+}
+
+// Matches returns true if the event should be sent to the webhook.
+func (w *Webhook) Matches(orgID int64, event string) bool {
+	if !w.Enabled || (w.OrgID != 0 && w.OrgID != orgID) {
+		return false
+	}
+	return matchEvent(w.Events, event)
+}
+
+func matchEvent(patterns []string, event string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		if pattern == "*" || pattern == event {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(pattern, ".*"); ok && strings.HasPrefix(event, prefix+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateWebhookCommand is the command for creating a webhook.
+// swagger:model
+type CreateWebhookCommand struct {
+	OrgID int64 `json:"orgId"`
+	// required:true
+	Name string `json:"name"`
+	// required:true
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	// Secret used to sign the payloads, generated when empty.
+	Secret string `json:"secret"`
+	// Defaults to true.
+	Enabled *bool `json:"enabled"`
+}
+
+func (cmd CreateWebhookCommand) Validate() error {
+	return validate(cmd.Name, cmd.URL, cmd.Events)
+}
+
+// UpdateWebhookCommand is the command for updating a webhook.
+// swagger:model
+type UpdateWebhookCommand struct {
+	UID   string `json:"-"`
+	OrgID int64  `json:"orgId"`
+	// required:true
+	Name string `json:"name"`
+	// required:true
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	// Secret used to sign the payloads, the current secret is kept when empty.
+	Secret  string `json:"secret"`
+	Enabled bool   `json:"enabled"`
+}
+
+func (cmd UpdateWebhookCommand) Validate() error {
+	return validate(cmd.Name, cmd.URL, cmd.Events)
+}
+
+func validate(name, rawURL string, events []string) error {
+	if strings.TrimSpace(name) == "" {
+		return ErrInvalidName.Errorf("empty webhook name")
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ErrInvalidURL.Errorf("invalid webhook URL %q", rawURL)
+	}
+
+	for _, event := range events {
+		if !validEventPattern(event) {
+			return ErrInvalidEvent.Errorf("unknown event %q", event)
+		}
+	}
+	return nil
+}
+
+func validEventPattern(pattern string) bool {
+	for _, event := range allEvents {
+		if matchEvent([]string{pattern}, event) {
+			return true
+		}
+	}
+	return false
+}
+
+// Delivery is the delivery of an event to a webhook.
+type Delivery struct {
+	UID        string          `json:"uid"`
+	WebhookUID string          `json:"webhookUid"`
+	Event      string          `json:"event"`
+	Payload    json.RawMessage `json:"payload"`
+	Status     DeliveryStatus  `json:"status"`
+	Attempts   int             `json:"attempts"`
+	// NextAttempt is the time of the next attempt of a pending delivery.
+	NextAttempt *time.Time `json:"nextAttempt,omitempty"`
+	// ResponseStatus is the HTTP status of the response to the last attempt, 0 if the request failed.
+	ResponseStatus int       `json:"responseStatus"`
+	Error          string    `json:"error,omitempty"`
+	Created        time.Time `json:"created"`
+	Updated        time.Time `json:"updated"`
+}
+
+type GetDeliveriesQuery struct {
+	WebhookUID string
+	Status     DeliveryStatus
+	Limit      int
+}
+
+// Payload is the body of the requests sent to webhooks.
+type Payload struct {
+	// ID is the unique ID of the event, the same for all the webhooks it's sent to.
+	ID        string    `json:"id"`
+	Event     string    `json:"event"`
+	Timestamp time.Time `json:"timestamp"`
+	OrgID     int64     `json:"orgId,omitempty"`
+	Resource  Resource  `json:"resource"`
+	Actor     *Actor    `json:"actor,omitempty"`
+}
+
+type Resource struct {
+	Kind string `json:"kind"`
+	ID   int64  `json:"id,omitempty"`
+	UID  string `json:"uid,omitempty"`
+	Name string `json:"name,omitempty"`
+	// FolderUID is the folder of dashboards and alert rules.
+	FolderUID string `json:"folderUid,omitempty"`
+}
+
+// Actor is the user who made the change, when it was made by a user.
+type Actor struct {
+	ID    int64  `json:"id"`
+	Login string `json:"login"`
+}
@@ -0,0 +1,329 @@
+package outgoingwebhooks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/appcontext"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	pollInterval    = 10 * time.Second
+	cleanupInterval = time.Hour
+	// maxDeliveriesPerPoll is the number of due deliveries attempted at every poll
+	maxDeliveriesPerPoll = 100
+	// maxRetryInterval caps the exponential backoff between the attempts of a delivery
+	maxRetryInterval = 24 * time.Hour
+	secretLength     = 32
+)
+
+type Service interface {
+	CreateWebhook(ctx context.Context, cmd CreateWebhookCommand) (Webhook, string, error)
+	UpdateWebhook(ctx context.Context, cmd UpdateWebhookCommand) (Webhook, error)
+	GetWebhook(ctx context.Context, uid string) (Webhook, error)
+	ListWebhooks(ctx context.Context) ([]Webhook, error)
+	DeleteWebhook(ctx context.Context, uid string) error
+	GetDeliveries(ctx context.Context, query GetDeliveriesQuery) ([]Delivery, error)
+	RetryDelivery(ctx context.Context, webhookUID, deliveryUID string) error
+}
+
+// WebhooksService sends the create, update and delete events of Grafana resources to
+// the configured webhooks. The deliveries are stored in the database when the event is
+// published, and sent by the instance which claims them first, so that the events
+// aren't lost when a webhook or Grafana is unavailable.
+type WebhooksService struct {
+	cfg           setting.OutgoingWebhooksSettings
+	db            db.DB
+	webhookSender notifications.WebhookSender
+	secrets       secrets.Service
+	serverLock    *serverlock.ServerLockService
+	routeRegister routing.RouteRegister
+	log           log.Logger
+	now           func() time.Time
+
+	// wakeup is signaled when deliveries are added, to send them without waiting for the next poll
+	wakeup chan struct{}
+}
+
+var _ Service = (*WebhooksService)(nil)
+
+func ProvideService(cfg *setting.Cfg, sqlStore db.DB, b bus.Bus, routeRegister routing.RouteRegister, webhookSender notifications.WebhookSender,
+	secretsService secrets.Service, serverLock *serverlock.ServerLockService) *WebhooksService {
+	s := &WebhooksService{
+		cfg:           cfg.OutgoingWebhooks,
+		db:            sqlStore,
+		webhookSender: webhookSender,
+		secrets:       secretsService,
+		serverLock:    serverLock,
+		routeRegister: routeRegister,
+		log:           log.New("outgoing-webhooks"),
+		now:           time.Now,
+		wakeup:        make(chan struct{}, 1),
+	}
+
+	if s.cfg.Enabled {
+		s.registerAPIEndpoints()
+		s.addEventListeners(b)
+	}
+
+	return s
+}
+
+func (s *WebhooksService) IsDisabled() bool {
+	return !s.cfg.Enabled
+}
+
+func (s *WebhooksService) CreateWebhook(ctx context.Context, cmd CreateWebhookCommand) (Webhook, string, error) {
+	if err := cmd.Validate(); err != nil {
+		return Webhook{}, "", err
+	}
+	if cmd.Secret == "" {
+		secret, err := util.GetRandomString(secretLength)
+		if err != nil {
+			return Webhook{}, "", err
+		}
+		cmd.Secret = secret
+	}
+
+	webhook, err := s.createWebhook(ctx, cmd)
+	return webhook, cmd.Secret, err
+}
+
+func (s *WebhooksService) UpdateWebhook(ctx context.Context, cmd UpdateWebhookCommand) (Webhook, error) {
+	if err := cmd.Validate(); err != nil {
+		return Webhook{}, err
+	}
+	return s.updateWebhook(ctx, cmd)
+}
+
+func (s *WebhooksService) GetWebhook(ctx context.Context, uid string) (Webhook, error) {
+	return s.getWebhook(ctx, uid)
+}
+
+func (s *WebhooksService) ListWebhooks(ctx context.Context) ([]Webhook, error) {
+	return s.listWebhooks(ctx)
+}
+
+func (s *WebhooksService) DeleteWebhook(ctx context.Context, uid string) error {
+	return s.deleteWebhook(ctx, uid)
+}
+
+func (s *WebhooksService) GetDeliveries(ctx context.Context, query GetDeliveriesQuery) ([]Delivery, error) {
+	if _, err := s.getWebhook(ctx, query.WebhookUID); err != nil {
+		return nil, err
+	}
+	return s.getDeliveries(ctx, query)
+}
+
+func (s *WebhooksService) RetryDelivery(ctx context.Context, webhookUID, deliveryUID string) error {
+	if err := s.retryDelivery(ctx, webhookUID, deliveryUID); err != nil {
+		return err
+	}
+	s.notify()
+	return nil
+}
+
+// enqueue stores a delivery of the event for every webhook subscribed to it. Errors
+// are only logged, the change the event is about has already been committed.
+func (s *WebhooksService) enqueue(ctx context.Context, event string, orgID int64, resource Resource) error {
+	webhooks, err := s.listWebhooks(ctx)
+	if err != nil {
+		s.log.Error("Failed to get the webhooks", "event", event, "error", err)
+		return nil
+	}
+
+	now := s.now()
+	payload := Payload{
+		ID:        uuid.NewString(),
+		Event:     event,
+		Timestamp: now.UTC(),
+		OrgID:     orgID,
+		Resource:  resource,
+	}
+	if usr, err := appcontext.User(ctx); err == nil && usr.UserID > 0 {
+		payload.Actor = &Actor{ID: usr.UserID, Login: usr.Login}
+	}
+
+	var body []byte
+	deliveries := make([]deliveryRow, 0)
+	for _, webhook := range webhooks {
+		if !webhook.Matches(orgID, event) {
+			continue
+		}
+		if body == nil {
+			if body, err = json.Marshal(payload); err != nil {
+				s.log.Error("Failed to encode the webhook payload", "event", event, "error", err)
+				return nil
+			}
+		}
+		deliveries = append(deliveries, deliveryRow{
+			UID:           util.GenerateShortUID(),
+			WebhookUID:    webhook.UID,
+			Event:         event,
+			Payload:       string(body),
+			Status:        DeliveryStatusPending,
+			NextAttemptAt: now.Unix(),
+			Created:       now.Unix(),
+			Updated:       now.Unix(),
+		})
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	if err := s.insertDeliveries(ctx, deliveries); err != nil {
+		s.log.Error("Failed to store the webhook deliveries", "event", event, "error", err)
+		return nil
+	}
+	s.notify()
+	return nil
+}
+
+func (s *WebhooksService) notify() {
+	select {
+	case s.wakeup <- struct{}{}:
+	default:
+	}
+}
+
+func (s *WebhooksService) Run(ctx context.Context) error {
+	pollTicker := time.NewTicker(pollInterval)
+	defer pollTicker.Stop()
+	cleanupTicker := time.NewTicker(cleanupInterval)
+	defer cleanupTicker.Stop()
+
+	for {
+		select {
+		case <-pollTicker.C:
+			s.deliverDue(ctx)
+		case <-s.wakeup:
+			s.deliverDue(ctx)
+		case <-cleanupTicker.C:
+			err := s.serverLock.LockAndExecute(ctx, "cleanup outgoing webhook deliveries", cleanupInterval, func(ctx context.Context) {
+				deleted, err := s.deleteDeliveriesOlderThan(ctx, s.now().Add(-s.cfg.DeliveryRetention))
+				if err != nil {
+					s.log.Error("Failed to delete old webhook deliveries", "error", err)
+					return
+				}
+				s.log.Debug("Deleted old webhook deliveries", "count", deleted)
+			})
+			if err != nil {
+				s.log.Error("Failed to lock and execute cleanup of webhook deliveries", "error", err)
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// deliverDue attempts the deliveries which are due.
+func (s *WebhooksService) deliverDue(ctx context.Context) {
+	rows, err := s.getDueDeliveries(ctx, maxDeliveriesPerPoll)
+	if err != nil {
+		s.log.Error("Failed to get the due webhook deliveries", "error", err)
+		return
+	}
+
+	webhooks := map[string]*webhookRow{}
+	for i := range rows {
+		row := &rows[i]
+		webhook, ok := webhooks[row.WebhookUID]
+		if !ok {
+			w, err := s.getWebhookRow(ctx, row.WebhookUID)
+			if err != nil {
+				s.log.Error("Failed to get the webhook of a delivery", "webhook", row.WebhookUID, "delivery", row.UID, "error", err)
+				continue
+			}
+			webhook = &w
+			webhooks[row.WebhookUID] = webhook
+		}
+
+		if err := s.attempt(ctx, webhook, row); err != nil {
+			s.log.Error("Failed to attempt the webhook delivery", "webhook", row.WebhookUID, "delivery", row.UID, "error", err)
+		}
+	}
+}
+
+// attempt sends the delivery to the webhook, if no other instance attempted it first.
+func (s *WebhooksService) attempt(ctx context.Context, webhook *webhookRow, row *deliveryRow) error {
+	claimed, err := s.claimDelivery(ctx, row, s.now().Add(s.retryInterval(row.Attempts+1)))
+	if err != nil || !claimed {
+		return err
+	}
+
+	if !webhook.Enabled {
+		row.Status = DeliveryStatusFailed
+		row.Error = "webhook is disabled"
+		return s.finishAttempt(ctx, row)
+	}
+
+	row.ResponseStatus, err = s.send(ctx, webhook, row)
+	switch {
+	case err == nil:
+		row.Status = DeliveryStatusSuccess
+		row.Error = ""
+	case row.Attempts >= s.cfg.MaxAttempts:
+		row.Status = DeliveryStatusFailed
+		row.Error = err.Error()
+	default:
+		// the delivery stays pending until its next attempt
+		row.Error = err.Error()
+		s.log.Debug("Webhook delivery failed, retrying later", "webhook", webhook.UID, "delivery", row.UID, "attempts", row.Attempts, "error", err)
+	}
+	return s.finishAttempt(ctx, row)
+}
+
+// send posts the payload to the webhook and returns the status of the response.
+func (s *WebhooksService) send(ctx context.Context, webhook *webhookRow, row *deliveryRow) (int, error) {
+	secret, err := s.decryptSecret(ctx, webhook.Secret)
+	if err != nil {
+		return 0, fmt.Errorf("failed to decrypt the secret of the webhook: %w", err)
+	}
+
+	timestamp := s.now().Unix()
+	var statusCode int
+	err = s.webhookSender.SendWebhookSync(ctx, &notifications.SendWebhookSync{
+		Url:        webhook.URL,
+		Body:       row.Payload,
+		HttpMethod: http.MethodPost,
+		HttpHeader: map[string]string{
+			headerEvent:              row.Event,
+			headerDelivery:           row.UID,
+			headerSignatureTimestamp: strconv.FormatInt(timestamp, 10),
+			headerSignature:          sign(secret, timestamp, []byte(row.Payload)),
+		},
+		ContentType: "application/json",
+		Validation: func(body []byte, code int) error {
+			statusCode = code
+			return nil
+		},
+	})
+	return statusCode, err
+}
+
+// retryInterval returns the delay before the attempt following the given one.
+func (s *WebhooksService) retryInterval(attempt int) time.Duration {
+	interval := s.cfg.RetryInterval
+	for i := 1; i < attempt && interval < maxRetryInterval; i++ {
+		interval *= 2
+	}
+	if interval > maxRetryInterval {
+		return maxRetryInterval
+	}
+	return interval
+}
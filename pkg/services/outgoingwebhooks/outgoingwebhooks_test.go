@@ -0,0 +1,253 @@
+package outgoingwebhooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/appcontext"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestWebhookMatches(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		webhook Webhook
+		orgID   int64
+		event   string
+		matches bool
+	}{
+		{desc: "all events", webhook: Webhook{Enabled: true}, orgID: 1, event: EventDashboardUpdated, matches: true},
+		{desc: "wildcard", webhook: Webhook{Enabled: true, Events: []string{"*"}}, orgID: 1, event: EventTeamDeleted, matches: true},
+		{desc: "exact event", webhook: Webhook{Enabled: true, Events: []string{EventDashboardUpdated}}, orgID: 1, event: EventDashboardUpdated, matches: true},
+		{desc: "other event", webhook: Webhook{Enabled: true, Events: []string{EventDashboardUpdated}}, orgID: 1, event: EventDashboardDeleted, matches: false},
+		{desc: "events of a kind", webhook: Webhook{Enabled: true, Events: []string{"dashboard.*"}}, orgID: 1, event: EventDashboardDeleted, matches: true},
+		{desc: "events of another kind", webhook: Webhook{Enabled: true, Events: []string{"dashboard.*"}}, orgID: 1, event: EventDataSourceDeleted, matches: false},
+		{desc: "disabled", webhook: Webhook{Enabled: false}, orgID: 1, event: EventDashboardUpdated, matches: false},
+		{desc: "same org", webhook: Webhook{Enabled: true, OrgID: 2}, orgID: 2, event: EventDashboardUpdated, matches: true},
+		{desc: "other org", webhook: Webhook{Enabled: true, OrgID: 2}, orgID: 1, event: EventDashboardUpdated, matches: false},
+		{desc: "event without org", webhook: Webhook{Enabled: true, OrgID: 2}, orgID: 0, event: EventUserCreated, matches: false},
+		{desc: "event without org to all orgs", webhook: Webhook{Enabled: true}, orgID: 0, event: EventUserCreated, matches: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			assert.Equal(t, tc.matches, tc.webhook.Matches(tc.orgID, tc.event))
+		})
+	}
+}
+
+func TestValidateWebhook(t *testing.T) {
+	valid := CreateWebhookCommand{Name: "cmdb", URL: "https://cmdb.example.com/hooks/grafana", Events: []string{"dashboard.*", EventFolderDeleted}}
+	require.NoError(t, valid.Validate())
+
+	invalid := valid
+	invalid.Name = " "
+	require.ErrorIs(t, invalid.Validate(), ErrInvalidName)
+
+	for _, u := range []string{"", "cmdb.example.com", "ftp://cmdb.example.com", "https://"} {
+		invalid = valid
+		invalid.URL = u
+		require.ErrorIs(t, invalid.Validate(), ErrInvalidURL, u)
+	}
+
+	for _, event := range []string{"dashboard.viewed", "playlist.*", "dashboard"} {
+		invalid = valid
+		invalid.Events = []string{event}
+		require.ErrorIs(t, invalid.Validate(), ErrInvalidEvent, event)
+	}
+}
+
+func TestSign(t *testing.T) {
+	body := []byte(`{"event":"dashboard.updated"}`)
+
+	signature := sign("secret", 1700000000, body)
+	require.Equal(t, "sha256=4ddb0505ff9792e174f2720a5c164bb628cbe07bd81a6206ad9597e1e1cc409b", signature)
+	require.NotEqual(t, signature, sign("other secret", 1700000000, body))
+	require.NotEqual(t, signature, sign("secret", 1700000001, body))
+}
+
+func TestRetryInterval(t *testing.T) {
+	s := &WebhooksService{cfg: setting.OutgoingWebhooksSettings{RetryInterval: 30 * time.Second}}
+
+	require.Equal(t, 30*time.Second, s.retryInterval(1))
+	require.Equal(t, time.Minute, s.retryInterval(2))
+	require.Equal(t, 2*time.Minute, s.retryInterval(3))
+	require.Equal(t, maxRetryInterval, s.retryInterval(100))
+}
+
+func setupTestService(t *testing.T, sender notifications.WebhookSender) *WebhooksService {
+	t.Helper()
+
+	return &WebhooksService{
+		cfg: setting.OutgoingWebhooksSettings{
+			Enabled:           true,
+			MaxAttempts:       2,
+			RetryInterval:     time.Minute,
+			DeliveryRetention: 7 * 24 * time.Hour,
+		},
+		db:            db.InitTestDB(t),
+		webhookSender: sender,
+		secrets:       fakes.NewFakeSecretsService(),
+		log:           log.New("test-logger"),
+		now:           time.Now,
+		wakeup:        make(chan struct{}, 1),
+	}
+}
+
+func TestIntegrationOutgoingWebhooks(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := appcontext.WithUser(context.Background(), &user.SignedInUser{UserID: 3, Login: "editor", OrgID: 1})
+
+	t.Run("should send signed payloads of the subscribed events", func(t *testing.T) {
+		var sent []*notifications.SendWebhookSync
+		sender := &notifications.NotificationServiceMock{
+			WebhookHandler: func(ctx context.Context, cmd *notifications.SendWebhookSync) error {
+				sent = append(sent, cmd)
+				return cmd.Validation(nil, 200)
+			},
+		}
+		s := setupTestService(t, sender)
+
+		webhook, secret, err := s.CreateWebhook(ctx, CreateWebhookCommand{Name: "cmdb", URL: "https://cmdb.example.com", OrgID: 1, Events: []string{"dashboard.*"}})
+		require.NoError(t, err)
+		require.NotEmpty(t, secret)
+
+		require.NoError(t, s.enqueue(ctx, EventDashboardUpdated, 1, Resource{Kind: kindDashboard, UID: "prod", Name: "Production"}))
+		require.NoError(t, s.enqueue(ctx, EventDashboardUpdated, 2, Resource{Kind: kindDashboard, UID: "other-org"}))
+		require.NoError(t, s.enqueue(ctx, EventTeamCreated, 1, Resource{Kind: kindTeam, Name: "team"}))
+		s.deliverDue(ctx)
+
+		require.Len(t, sent, 1)
+		cmd := sent[0]
+		require.Equal(t, "https://cmdb.example.com", cmd.Url)
+		require.Equal(t, EventDashboardUpdated, cmd.HttpHeader[headerEvent])
+
+		timestamp, err := strconv.ParseInt(cmd.HttpHeader[headerSignatureTimestamp], 10, 64)
+		require.NoError(t, err)
+		require.Equal(t, sign(secret, timestamp, []byte(cmd.Body)), cmd.HttpHeader[headerSignature])
+
+		var payload Payload
+		require.NoError(t, json.Unmarshal([]byte(cmd.Body), &payload))
+		require.Equal(t, EventDashboardUpdated, payload.Event)
+		require.Equal(t, int64(1), payload.OrgID)
+		require.Equal(t, "prod", payload.Resource.UID)
+		require.Equal(t, &Actor{ID: 3, Login: "editor"}, payload.Actor)
+
+		deliveries, err := s.GetDeliveries(ctx, GetDeliveriesQuery{WebhookUID: webhook.UID, Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, deliveries, 1)
+		require.Equal(t, DeliveryStatusSuccess, deliveries[0].Status)
+		require.Equal(t, cmd.HttpHeader[headerDelivery], deliveries[0].UID)
+		require.Equal(t, 200, deliveries[0].ResponseStatus)
+		require.Equal(t, 1, deliveries[0].Attempts)
+	})
+
+	t.Run("should retry failed deliveries until the max attempts", func(t *testing.T) {
+		attempts := 0
+		sender := &notifications.NotificationServiceMock{
+			WebhookHandler: func(ctx context.Context, cmd *notifications.SendWebhookSync) error {
+				attempts++
+				_ = cmd.Validation(nil, 503)
+				return errors.New("webhook response status 503 Service Unavailable")
+			},
+		}
+		s := setupTestService(t, sender)
+
+		webhook, _, err := s.CreateWebhook(ctx, CreateWebhookCommand{Name: "chat", URL: "https://chat.example.com"})
+		require.NoError(t, err)
+		require.NoError(t, s.enqueue(ctx, EventUserDeleted, 0, Resource{Kind: kindUser, ID: 2, Name: "viewer"}))
+
+		s.deliverDue(ctx)
+		require.Equal(t, 1, attempts)
+		deliveries, err := s.GetDeliveries(ctx, GetDeliveriesQuery{WebhookUID: webhook.UID, Limit: 10})
+		require.NoError(t, err)
+		require.Equal(t, DeliveryStatusPending, deliveries[0].Status)
+		require.Equal(t, 503, deliveries[0].ResponseStatus)
+		require.NotNil(t, deliveries[0].NextAttempt)
+
+		// the next attempt isn't due yet
+		s.deliverDue(ctx)
+		require.Equal(t, 1, attempts)
+
+		s.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+		s.deliverDue(ctx)
+		require.Equal(t, 2, attempts)
+		deliveries, err = s.GetDeliveries(ctx, GetDeliveriesQuery{WebhookUID: webhook.UID, Limit: 10})
+		require.NoError(t, err)
+		require.Equal(t, DeliveryStatusFailed, deliveries[0].Status)
+		require.Contains(t, deliveries[0].Error, "503")
+
+		t.Run("and retry a failed delivery on demand", func(t *testing.T) {
+			require.NoError(t, s.RetryDelivery(ctx, webhook.UID, deliveries[0].UID))
+			require.ErrorIs(t, s.RetryDelivery(ctx, webhook.UID, deliveries[0].UID), ErrDeliveryPending)
+
+			s.deliverDue(ctx)
+			require.Equal(t, 3, attempts)
+		})
+	})
+
+	t.Run("should not send deliveries claimed by another instance", func(t *testing.T) {
+		s := setupTestService(t, &notifications.NotificationServiceMock{})
+
+		_, _, err := s.CreateWebhook(ctx, CreateWebhookCommand{Name: "cmdb", URL: "https://cmdb.example.com"})
+		require.NoError(t, err)
+		require.NoError(t, s.enqueue(ctx, EventTeamDeleted, 1, Resource{Kind: kindTeam, ID: 1}))
+
+		rows, err := s.getDueDeliveries(ctx, 10)
+		require.NoError(t, err)
+		require.Len(t, rows, 1)
+		stale := rows[0]
+
+		claimed, err := s.claimDelivery(ctx, &rows[0], time.Now().Add(time.Minute))
+		require.NoError(t, err)
+		require.True(t, claimed)
+
+		claimed, err = s.claimDelivery(ctx, &stale, time.Now().Add(time.Minute))
+		require.NoError(t, err)
+		require.False(t, claimed)
+	})
+
+	t.Run("should keep the secret when it isn't updated and delete the deliveries with the webhook", func(t *testing.T) {
+		s := setupTestService(t, &notifications.NotificationServiceMock{})
+
+		webhook, _, err := s.CreateWebhook(ctx, CreateWebhookCommand{Name: "cmdb", URL: "https://cmdb.example.com", Secret: "s3cr3t"})
+		require.NoError(t, err)
+		require.True(t, webhook.Enabled)
+
+		updated, err := s.UpdateWebhook(ctx, UpdateWebhookCommand{UID: webhook.UID, Name: "cmdb v2", URL: "https://cmdb.example.com/v2", Enabled: false})
+		require.NoError(t, err)
+		require.Equal(t, "cmdb v2", updated.Name)
+		require.False(t, updated.Enabled)
+
+		row, err := s.getWebhookRow(ctx, webhook.UID)
+		require.NoError(t, err)
+		secret, err := s.decryptSecret(ctx, row.Secret)
+		require.NoError(t, err)
+		require.Equal(t, "s3cr3t", secret)
+
+		require.NoError(t, s.DeleteWebhook(ctx, webhook.UID))
+		_, err = s.GetWebhook(ctx, webhook.UID)
+		require.ErrorIs(t, err, ErrWebhookNotFound)
+		require.ErrorIs(t, s.DeleteWebhook(ctx, webhook.UID), ErrWebhookNotFound)
+	})
+}
@@ -0,0 +1,28 @@
+package outgoingwebhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+)
+
+const (
+	headerEvent              = "X-Grafana-Event"
+	headerDelivery           = "X-Grafana-Delivery"
+	headerSignature          = "X-Grafana-Signature"
+	headerSignatureTimestamp = "X-Grafana-Signature-Timestamp"
+
+	signaturePrefix = "sha256="
+)
+
+// sign returns the signature of the payload sent at the unix time timestamp. The
+// timestamp is part of the signed content so that receivers can reject replayed
+// requests.
+func sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
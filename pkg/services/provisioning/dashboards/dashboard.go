@@ -5,10 +5,12 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/folder"
 	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/provisioning/gitsync"
 	"github.com/grafana/grafana/pkg/services/provisioning/utils"
 )
 
@@ -21,6 +23,9 @@ type DashboardProvisioner interface {
 	GetProvisionerResolvedPath(name string) string
 	GetAllowUIUpdatesFromConfig(name string) bool
 	CleanUpOrphanedDashboards(ctx context.Context)
+	GetGitSyncStatus() []gitsync.Status
+	QueueDashboardCommit(provisioning *dashboards.DashboardProvisioning, data *simplejson.Json, message string, author gitsync.Author) error
+	SetAlertingProvisioner(fn func(ctx context.Context, path string) error)
 }
 
 // DashboardProvisionerFactory creates DashboardProvisioners based on input
@@ -130,6 +135,36 @@ func (provider *Provisioner) GetAllowUIUpdatesFromConfig(name string) bool {
 	return false
 }
 
+// GetGitSyncStatus returns the sync state of the providers of type git.
+func (provider *Provisioner) GetGitSyncStatus() []gitsync.Status {
+	statuses := make([]gitsync.Status, 0)
+	for _, reader := range provider.fileReaders {
+		if reader.git != nil {
+			statuses = append(statuses, reader.git.repo.Status())
+		}
+	}
+	return statuses
+}
+
+// QueueDashboardCommit queues the commit of a dashboard saved in the UI to the git repository it was
+// provisioned from. The commits which fail are reported in the git sync status. It does nothing for
+// dashboards of providers which don't commit UI changes.
+func (provider *Provisioner) QueueDashboardCommit(provisioning *dashboards.DashboardProvisioning, data *simplejson.Json, message string, author gitsync.Author) error {
+	for _, reader := range provider.fileReaders {
+		if reader.Cfg.Name == provisioning.Name {
+			return reader.queueDashboardCommit(provisioning, data, message, author)
+		}
+	}
+	return nil
+}
+
+// SetAlertingProvisioner sets the function used to provision the alerting resources of git repositories.
+func (provider *Provisioner) SetAlertingProvisioner(fn func(ctx context.Context, path string) error) {
+	for _, reader := range provider.fileReaders {
+		reader.provisionAlerting = fn
+	}
+}
+
 func getFileReaders(
 	configs []*config,
 	logger log.Logger,
@@ -153,6 +188,18 @@ func getFileReaders(
 				return nil, fmt.Errorf("failed to create file reader for config %v: %w", config.Name, err)
 			}
 			readers = append(readers, fileReader)
+		case "git":
+			gitReader, err := NewDashboardGitReader(
+				config,
+				logger.New("type", config.Type, "name", config.Name),
+				service,
+				store,
+				folderService,
+			)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create git reader for config %v: %w", config.Name, err)
+			}
+			readers = append(readers, gitReader)
 		default:
 			return nil, fmt.Errorf("type %s is not supported", config.Type)
 		}
@@ -1,6 +1,12 @@
 package dashboards
 
-import "context"
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/provisioning/gitsync"
+)
 
 // Calls is a mock implementation of the provisioner interface
 type calls struct {
@@ -8,6 +14,8 @@ type calls struct {
 	PollChanges                 []any
 	GetProvisionerResolvedPath  []any
 	GetAllowUIUpdatesFromConfig []any
+	GetGitSyncStatus            []any
+	QueueDashboardCommit        []any
 }
 
 // ProvisionerMock is a mock implementation of `Provisioner`
@@ -17,6 +25,8 @@ type ProvisionerMock struct {
 	PollChangesFunc                 func(ctx context.Context)
 	GetProvisionerResolvedPathFunc  func(name string) string
 	GetAllowUIUpdatesFromConfigFunc func(name string) bool
+	GetGitSyncStatusFunc            func() []gitsync.Status
+	QueueDashboardCommitFunc        func(provisioning *dashboards.DashboardProvisioning, data *simplejson.Json, message string, author gitsync.Author) error
 }
 
 // NewDashboardProvisionerMock returns a new dashboardprovisionermock
@@ -67,3 +77,24 @@ func (dpm *ProvisionerMock) GetAllowUIUpdatesFromConfig(name string) bool {
 
 // CleanUpOrphanedDashboards not implemented for mocks
 func (dpm *ProvisionerMock) CleanUpOrphanedDashboards(ctx context.Context) {}
+
+// GetGitSyncStatus is a mock implementation of `Provisioner.GetGitSyncStatus`
+func (dpm *ProvisionerMock) GetGitSyncStatus() []gitsync.Status {
+	dpm.Calls.GetGitSyncStatus = append(dpm.Calls.GetGitSyncStatus, nil)
+	if dpm.GetGitSyncStatusFunc != nil {
+		return dpm.GetGitSyncStatusFunc()
+	}
+	return nil
+}
+
+// QueueDashboardCommit is a mock implementation of `Provisioner.QueueDashboardCommit`
+func (dpm *ProvisionerMock) QueueDashboardCommit(provisioning *dashboards.DashboardProvisioning, data *simplejson.Json, message string, author gitsync.Author) error {
+	dpm.Calls.QueueDashboardCommit = append(dpm.Calls.QueueDashboardCommit, provisioning)
+	if dpm.QueueDashboardCommitFunc != nil {
+		return dpm.QueueDashboardCommitFunc(provisioning, data, message, author)
+	}
+	return nil
+}
+
+// SetAlertingProvisioner not implemented for mocks
+func (dpm *ProvisionerMock) SetAlertingProvisioner(fn func(ctx context.Context, path string) error) {}
@@ -41,6 +41,10 @@ type FileReader struct {
 	mux                     sync.RWMutex
 	usageTracker            *usageTracker
 	dbWriteAccessRestricted bool
+
+	// set for providers of type git
+	git               *gitSource
+	provisionAlerting func(ctx context.Context, path string) error
 }
 
 // NewDashboardFileReader returns a new filereader based on `config`
@@ -76,6 +80,10 @@ func NewDashboardFileReader(cfg *config, log log.Logger, service dashboards.Dash
 
 // pollChanges periodically runs walkDisk based on interval specified in the config.
 func (fr *FileReader) pollChanges(ctx context.Context) {
+	if fr.git != nil {
+		go fr.git.repo.Run(ctx)
+	}
+
 	ticker := time.NewTicker(time.Duration(int64(time.Second) * fr.Cfg.UpdateIntervalSeconds))
 	for {
 		select {
@@ -93,6 +101,12 @@ func (fr *FileReader) pollChanges(ctx context.Context) {
 // and applies any change to the database.
 func (fr *FileReader) walkDisk(ctx context.Context) error {
 	fr.log.Debug("Start walking disk", "path", fr.Path)
+	if fr.git != nil {
+		if err := fr.syncGitRepository(ctx); err != nil {
+			return err
+		}
+	}
+
 	resolvedPath := fr.resolvedPath()
 	if _, err := os.Stat(resolvedPath); err != nil {
 		return err
@@ -0,0 +1,203 @@
+package dashboards
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/provisioning/gitsync"
+	"github.com/grafana/grafana/pkg/services/provisioning/utils"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const defaultGitBranch = "main"
+
+// gitSource holds the state of a dashboard provider backed by a git repository.
+type gitSource struct {
+	repo            *gitsync.Repository
+	alertingPath    string
+	commitUIChanges bool
+
+	mu sync.Mutex
+	// checksums of the files committed from the UI, which are not provisioned until the next poll
+	committed map[string]string
+}
+
+// NewDashboardGitReader returns a FileReader that reads dashboards from a branch of a local git repository.
+func NewDashboardGitReader(cfg *config, log log.Logger, service dashboards.DashboardProvisioningService,
+	dashboardStore utils.DashboardStore, folderService folder.Service) (*FileReader, error) {
+	repoPath, ok := cfg.Options["path"].(string)
+	if !ok {
+		return nil, fmt.Errorf("failed to load dashboards, path param is not a string")
+	}
+
+	branch, _ := cfg.Options["branch"].(string)
+	if branch == "" {
+		branch = defaultGitBranch
+	}
+	push, _ := cfg.Options["push"].(bool)
+	commitUIChanges, _ := cfg.Options["commitUiChanges"].(bool)
+	if commitUIChanges && !cfg.AllowUIUpdates {
+		return nil, fmt.Errorf("'commitUiChanges' requires 'allowUiUpdates' to be enabled")
+	}
+
+	repo, err := gitsync.NewRepository(cfg.Name, repoPath, branch, push, log)
+	if err != nil {
+		return nil, err
+	}
+
+	fr, err := NewDashboardFileReader(cfg, log, service, dashboardStore, folderService)
+	if err != nil {
+		return nil, err
+	}
+
+	dashboardsPath, _ := cfg.Options["dashboardsPath"].(string)
+	fr.Path = filepath.Join(repo.Path(), dashboardsPath)
+
+	fr.git = &gitSource{
+		repo:            repo,
+		commitUIChanges: commitUIChanges,
+		committed:       map[string]string{},
+	}
+	if alertingPath, _ := cfg.Options["alertingPath"].(string); alertingPath != "" {
+		fr.git.alertingPath = filepath.Join(repo.Path(), alertingPath)
+	}
+
+	return fr, nil
+}
+
+// syncGitRepository brings the work tree up to date with the configured branch and
+// provisions the alerting resources of the repository when the branch moved.
+func (fr *FileReader) syncGitRepository(ctx context.Context) error {
+	commit, changed, err := fr.git.repo.Sync(ctx)
+	if err != nil {
+		if !errors.Is(err, gitsync.ErrDiverged) {
+			return err
+		}
+		// keep provisioning the local state until the branches are reconciled
+		fr.log.Warn("Git repository has diverged from upstream", "commit", commit)
+	}
+
+	if !changed || fr.git.alertingPath == "" || fr.provisionAlerting == nil {
+		return nil
+	}
+
+	fr.log.Debug("Provisioning alerting resources from git repository", "commit", commit, "path", fr.git.alertingPath)
+	// alerting errors must not block the dashboards of the same repository
+	if err := fr.provisionAlerting(ctx, fr.git.alertingPath); err != nil {
+		fr.log.Error("Failed to provision alerting resources from git repository", "commit", commit, "error", err)
+	}
+	return nil
+}
+
+// queueDashboardCommit queues the commit of a dashboard saved in the UI, which is done by the commit loop
+// of the repository, so that saving the dashboard doesn't wait for git.
+func (fr *FileReader) queueDashboardCommit(provisioning *dashboards.DashboardProvisioning, data *simplejson.Json,
+	message string, author gitsync.Author) error {
+	if fr.git == nil || !fr.git.commitUIChanges {
+		return nil
+	}
+
+	relPath, err := filepath.Rel(fr.git.repo.Path(), provisioning.ExternalID)
+	if err != nil {
+		return err
+	}
+
+	// copy the dashboard, which is committed after the save returned
+	raw, err := data.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	dash, err := simplejson.NewJson(raw)
+	if err != nil {
+		return err
+	}
+
+	return fr.git.repo.Enqueue(gitsync.Change{
+		Path: relPath,
+		UID:  dash.Get("uid").MustString(),
+		Commit: func(ctx context.Context) error {
+			return fr.commitDashboard(ctx, provisioning, dash, message, author)
+		},
+	})
+}
+
+// commitDashboard writes a dashboard saved in the UI back to its file and commits it.
+// The change is rejected with gitsync.ErrConflict when the file changed in the repository
+// since it was last provisioned, so that neither side overwrites the other.
+func (fr *FileReader) commitDashboard(ctx context.Context, provisioning *dashboards.DashboardProvisioning, data *simplejson.Json,
+	message string, author gitsync.Author) error {
+	if fr.git == nil || !fr.git.commitUIChanges {
+		return nil
+	}
+
+	relPath, err := filepath.Rel(fr.git.repo.Path(), provisioning.ExternalID)
+	if err != nil {
+		return err
+	}
+
+	fr.git.mu.Lock()
+	defer fr.git.mu.Unlock()
+
+	// nolint:gosec
+	// We can ignore the gosec G304 warning on this one because the path comes from the provisioning metadata.
+	current, err := os.ReadFile(provisioning.ExternalID)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err == nil {
+		checkSum, err := util.Md5SumString(string(current))
+		if err != nil {
+			return err
+		}
+		if checkSum != provisioning.CheckSum && checkSum != fr.git.committed[relPath] {
+			fr.git.repo.AddConflict(gitsync.Conflict{
+				Path:   relPath,
+				UID:    data.Get("uid").MustString(),
+				Reason: gitsync.ErrConflict.Error(),
+			})
+			return gitsync.ErrConflict
+		}
+	}
+
+	// copy the dashboard, the id is specific to this Grafana instance and is not part of the provisioned file
+	raw, err := data.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	dash, err := simplejson.NewJson(raw)
+	if err != nil {
+		return err
+	}
+	dash.Del("id")
+	content, err := dash.EncodePretty()
+	if err != nil {
+		return err
+	}
+
+	if message == "" {
+		message = fmt.Sprintf("Update dashboard %q", dash.Get("title").MustString())
+	}
+	content = append(content, '\n')
+	commit, err := fr.git.repo.CommitFile(ctx, relPath, content, message, author)
+	if err != nil {
+		return err
+	}
+
+	checkSum, err := util.Md5SumString(string(content))
+	if err != nil {
+		return err
+	}
+	fr.git.committed[relPath] = checkSum
+
+	fr.git.repo.ResolveConflict(relPath)
+	fr.log.Info("Committed dashboard change to git repository", "file", relPath, "commit", commit)
+	return nil
+}
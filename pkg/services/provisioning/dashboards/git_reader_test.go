@@ -0,0 +1,159 @@
+package dashboards
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/provisioning/gitsync"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func TestDashboardGitReader(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	setup := func(t *testing.T) (*config, string) {
+		t.Helper()
+
+		repoPath := t.TempDir()
+		runGit(t, repoPath, "init", "--quiet", "--initial-branch=main")
+		require.NoError(t, os.MkdirAll(filepath.Join(repoPath, "dashboards"), 0750))
+		require.NoError(t, os.WriteFile(filepath.Join(repoPath, "dashboards", "home.json"), []byte(`{"uid": "home", "title": "Home"}`), 0600))
+		runGit(t, repoPath, "add", ".")
+		runGit(t, repoPath, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--quiet", "-m", "init")
+
+		return &config{
+			Name:           "gitops",
+			Type:           "git",
+			OrgID:          1,
+			AllowUIUpdates: true,
+			Options: map[string]any{
+				"path":            repoPath,
+				"dashboardsPath":  "dashboards",
+				"commitUiChanges": true,
+			},
+		}, repoPath
+	}
+
+	provisioningOf := func(t *testing.T, file string) *dashboards.DashboardProvisioning {
+		t.Helper()
+
+		content, err := os.ReadFile(file)
+		require.NoError(t, err)
+		checkSum, err := util.Md5SumString(string(content))
+		require.NoError(t, err)
+		return &dashboards.DashboardProvisioning{Name: "gitops", ExternalID: file, CheckSum: checkSum}
+	}
+
+	t.Run("should read dashboards from the dashboards path of the repository", func(t *testing.T) {
+		cfg, repoPath := setup(t)
+		reader, err := NewDashboardGitReader(cfg, log.New("test-logger"), nil, nil, nil)
+		require.NoError(t, err)
+
+		resolvedRepoPath, err := filepath.EvalSymlinks(repoPath)
+		require.NoError(t, err)
+		require.Equal(t, filepath.Join(resolvedRepoPath, "dashboards"), reader.Path)
+	})
+
+	t.Run("should require allowUiUpdates to commit UI changes", func(t *testing.T) {
+		cfg, _ := setup(t)
+		cfg.AllowUIUpdates = false
+		_, err := NewDashboardGitReader(cfg, log.New("test-logger"), nil, nil, nil)
+		require.Error(t, err)
+	})
+
+	t.Run("should commit dashboards saved in the UI without their id", func(t *testing.T) {
+		cfg, _ := setup(t)
+		reader, err := NewDashboardGitReader(cfg, log.New("test-logger"), nil, nil, nil)
+		require.NoError(t, err)
+
+		file := filepath.Join(reader.Path, "home.json")
+		provisioning := provisioningOf(t, file)
+		data := simplejson.NewFromAny(map[string]any{"id": 3, "uid": "home", "title": "Home v2"})
+
+		err = reader.commitDashboard(context.Background(), provisioning, data, "", gitsync.Author{Name: "Jane Doe", Email: "jane@example.com"})
+		require.NoError(t, err)
+		require.Equal(t, 3, data.Get("id").MustInt())
+
+		content, err := os.ReadFile(file)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"uid": "home", "title": "Home v2"}`, string(content))
+		require.Equal(t, "Jane Doe: Update dashboard \"Home v2\"", runGit(t, reader.Path, "log", "-1", "--format=%an: %s"))
+
+		// saving again before the next poll isn't a conflict
+		data.Set("title", "Home v3")
+		err = reader.commitDashboard(context.Background(), provisioning, data, "Rename", gitsync.Author{Name: "Jane Doe"})
+		require.NoError(t, err)
+		require.Empty(t, reader.git.repo.Status().Conflicts)
+	})
+
+	t.Run("should commit dashboards saved in the UI in the background", func(t *testing.T) {
+		cfg, _ := setup(t)
+		reader, err := NewDashboardGitReader(cfg, log.New("test-logger"), nil, nil, nil)
+		require.NoError(t, err)
+
+		file := filepath.Join(reader.Path, "home.json")
+		provisioning := provisioningOf(t, file)
+		data := simplejson.NewFromAny(map[string]any{"uid": "home", "title": "Home v2"})
+
+		err = reader.queueDashboardCommit(provisioning, data, "Rename", gitsync.Author{Name: "Jane Doe"})
+		require.NoError(t, err)
+		// the queued dashboard is a copy of the saved one
+		data.Set("title", "Changed after the save")
+
+		content, err := os.ReadFile(file)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"uid": "home", "title": "Home"}`, string(content))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+		go reader.git.repo.Run(ctx)
+
+		// the repository isn't synced in the test, its commit is only set by the commit of the dashboard
+		require.Eventually(t, func() bool {
+			return reader.git.repo.Status().Commit != ""
+		}, 10*time.Second, 10*time.Millisecond)
+		require.Equal(t, "Rename", runGit(t, reader.Path, "log", "-1", "--format=%s"))
+		content, err = os.ReadFile(file)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"uid": "home", "title": "Home v2"}`, string(content))
+	})
+
+	t.Run("should not commit dashboards changed in the repository since they were provisioned", func(t *testing.T) {
+		cfg, _ := setup(t)
+		reader, err := NewDashboardGitReader(cfg, log.New("test-logger"), nil, nil, nil)
+		require.NoError(t, err)
+
+		file := filepath.Join(reader.Path, "home.json")
+		provisioning := provisioningOf(t, file)
+		require.NoError(t, os.WriteFile(file, []byte(`{"uid": "home", "title": "Changed in git"}`), 0600))
+
+		data := simplejson.NewFromAny(map[string]any{"uid": "home", "title": "Changed in the UI"})
+		err = reader.commitDashboard(context.Background(), provisioning, data, "", gitsync.Author{})
+		require.ErrorIs(t, err, gitsync.ErrConflict)
+
+		conflicts := reader.git.repo.Status().Conflicts
+		require.Len(t, conflicts, 1)
+		require.Equal(t, filepath.Join("dashboards", "home.json"), conflicts[0].Path)
+		require.Equal(t, "home", conflicts[0].UID)
+	})
+}
+
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+
+	out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+	require.NoError(t, err, string(out))
+	return strings.TrimSpace(string(out))
+}
@@ -33,6 +33,7 @@ var (
 
 	oneDatasourceWithTwoCorrelations   = "testdata/one-datasource-two-correlations"
 	correlationsDifferentOrganizations = "testdata/correlations-different-organizations"
+	oneDatasourceExternalCorrelation   = "testdata/one-datasource-external-correlation"
 )
 
 func TestDatasourceAsConfig(t *testing.T) {
@@ -291,6 +292,27 @@ func TestDatasourceAsConfig(t *testing.T) {
 			require.Equal(t, true, correlationsStore.deletedBySourceUID[0].OnlyProvisioned)
 		})
 
+		t.Run("Creates external correlation with chained transformations", func(t *testing.T) {
+			store := &spyStore{}
+			orgFake := &orgtest.FakeOrgService{}
+			correlationsStore := &mockCorrelationsStore{}
+			dc := newDatasourceProvisioner(logger, store, correlationsStore, orgFake)
+			err := dc.applyChanges(context.Background(), oneDatasourceExternalCorrelation)
+			if err != nil {
+				t.Fatalf("applyChanges return an error %v", err)
+			}
+
+			require.Equal(t, 1, len(correlationsStore.created))
+			created := correlationsStore.created[0]
+			require.Nil(t, created.TargetUID)
+			require.Equal(t, correlations.ConfigTypeExternal, created.Config.Type)
+			require.Equal(t, "https://tickets.example.com/browse/${ticket}", created.Config.Target["url"])
+			require.Equal(t, correlations.Transformations{
+				{Type: "jsonpath", Expression: "$.labels", MapValue: "labels"},
+				{Type: "keyvalue", Input: "labels", Delimiter: ",", Separator: ":"},
+			}, created.Config.Transformations)
+		})
+
 		t.Run("Updating existing datasource deletes existing correlations and creates two", func(t *testing.T) {
 			store := &spyStore{items: []*datasources.DataSource{{Name: "Graphite", OrgID: 1, ID: 1}}}
 			orgFake := &orgtest.FakeOrgService{}
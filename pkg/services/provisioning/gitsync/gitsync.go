@@ -0,0 +1,356 @@
+// Package gitsync keeps a local git repository used as a provisioning source
+// in sync with its upstream branch, and commits changes made in Grafana back
+// to it.
+package gitsync
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+var (
+	// ErrNotARepository is returned when the configured path is not inside a git work tree.
+	ErrNotARepository = errors.New("path is not a git repository")
+	// ErrDirtyWorkTree is returned when the branch can't be checked out because of uncommitted changes.
+	ErrDirtyWorkTree = errors.New("git work tree has uncommitted changes")
+	// ErrDiverged is returned when the local branch and its upstream both have commits the other one is missing.
+	ErrDiverged = errors.New("local branch has diverged from upstream")
+	// ErrConflict is returned when a file was changed in the repository since it was last provisioned.
+	ErrConflict = errors.New("file was changed in the repository since it was last provisioned")
+	// ErrOutsideRepository is returned when a file path points outside of the repository.
+	ErrOutsideRepository = errors.New("path is outside of the git repository")
+	// ErrQueueFull is returned when a change can't be queued because too many changes are waiting to be committed.
+	ErrQueueFull = errors.New("too many changes are waiting to be committed")
+)
+
+// commitQueueSize is the number of changes which can wait to be committed.
+const commitQueueSize = 100
+
+// Author identifies the user who made a change that is committed to the repository.
+type Author struct {
+	Name  string
+	Email string
+}
+
+// Change is a change made in Grafana which is committed to the repository by Run.
+type Change struct {
+	// Path is the path of the changed file, relative to the root of the work tree.
+	Path string
+	// UID is the UID of the changed resource.
+	UID string
+	// Commit writes and commits the change.
+	Commit func(ctx context.Context) error
+}
+
+// Conflict describes a change made in Grafana that could not be committed to the repository.
+type Conflict struct {
+	Path       string    `json:"path"`
+	UID        string    `json:"uid,omitempty"`
+	Reason     string    `json:"reason"`
+	DetectedAt time.Time `json:"detectedAt"`
+}
+
+// Status is the sync state of a repository.
+type Status struct {
+	Name      string     `json:"name"`
+	Path      string     `json:"path"`
+	Branch    string     `json:"branch"`
+	Commit    string     `json:"commit"`
+	LastSync  time.Time  `json:"lastSync"`
+	Error     string     `json:"error,omitempty"`
+	Conflicts []Conflict `json:"conflicts"`
+}
+
+// Repository is a local git repository checked out on a given branch.
+type Repository struct {
+	name   string
+	path   string
+	branch string
+	push   bool
+	log    log.Logger
+	now    func() time.Time
+
+	// serializes git commands, which can't run concurrently on the same work tree
+	gitMu sync.Mutex
+
+	// the changes waiting to be committed by Run
+	changes chan Change
+
+	mu        sync.RWMutex
+	commit    string
+	lastSync  time.Time
+	lastErr   error
+	conflicts map[string]Conflict
+}
+
+// NewRepository returns a Repository for the git work tree at path. When push is
+// true, local commits are pushed to the upstream of branch.
+func NewRepository(name, path, branch string, push bool, logger log.Logger) (*Repository, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil, fmt.Errorf("git executable not found: %w", err)
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Repository{
+		name:      name,
+		path:      absPath,
+		branch:    branch,
+		push:      push,
+		log:       logger,
+		now:       time.Now,
+		changes:   make(chan Change, commitQueueSize),
+		conflicts: map[string]Conflict{},
+	}
+
+	out, err := r.git(context.Background(), "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrNotARepository, path)
+	}
+	// the configured path might be a subdirectory or a symlink to the work tree
+	if r.path, err = filepath.EvalSymlinks(out); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Path returns the root of the work tree.
+func (r *Repository) Path() string {
+	return r.path
+}
+
+// Sync checks out the configured branch, fast-forwards it to its upstream if
+// there is one, and pushes local commits when pushing is enabled. It returns the
+// commit the work tree is at and whether it changed since the last sync.
+func (r *Repository) Sync(ctx context.Context) (string, bool, error) {
+	r.gitMu.Lock()
+	defer r.gitMu.Unlock()
+
+	err := r.sync(ctx)
+	commit, headErr := r.git(ctx, "rev-parse", "HEAD")
+	if err == nil {
+		err = headErr
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	changed := commit != r.commit
+	if headErr == nil {
+		r.commit = commit
+	}
+	r.lastSync = r.now()
+	r.lastErr = err
+
+	return commit, changed, err
+}
+
+func (r *Repository) sync(ctx context.Context) error {
+	current, err := r.git(ctx, "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return err
+	}
+	if current != r.branch {
+		if status, err := r.git(ctx, "status", "--porcelain"); err != nil {
+			return err
+		} else if status != "" {
+			return fmt.Errorf("%w: can't check out branch %q", ErrDirtyWorkTree, r.branch)
+		}
+		if _, err := r.git(ctx, "checkout", "--quiet", r.branch); err != nil {
+			return err
+		}
+	}
+
+	// a repository without a remote is only changed locally
+	if _, err := r.git(ctx, "rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{upstream}"); err != nil {
+		return nil
+	}
+
+	if _, err := r.git(ctx, "fetch", "--quiet"); err != nil {
+		return err
+	}
+
+	counts, err := r.git(ctx, "rev-list", "--left-right", "--count", "HEAD...@{upstream}")
+	if err != nil {
+		return err
+	}
+	var ahead, behind int
+	if _, err := fmt.Sscanf(counts, "%d\t%d", &ahead, &behind); err != nil {
+		return fmt.Errorf("unexpected rev-list output %q: %w", counts, err)
+	}
+
+	switch {
+	case ahead > 0 && behind > 0:
+		return ErrDiverged
+	case behind > 0:
+		_, err = r.git(ctx, "merge", "--quiet", "--ff-only", "@{upstream}")
+	case ahead > 0 && r.push:
+		_, err = r.git(ctx, "push", "--quiet")
+	}
+	return err
+}
+
+// Enqueue queues a change to be committed by Run, so that the caller doesn't wait for git. A change
+// which can't be queued is recorded as a conflict, and ErrQueueFull is returned.
+func (r *Repository) Enqueue(c Change) error {
+	select {
+	case r.changes <- c:
+		return nil
+	default:
+		r.AddConflict(Conflict{Path: c.Path, UID: c.UID, Reason: ErrQueueFull.Error()})
+		return ErrQueueFull
+	}
+}
+
+// Run commits the queued changes until ctx is done. The changes which can't be committed are recorded
+// as conflicts, so that they are part of the status.
+func (r *Repository) Run(ctx context.Context) {
+	for {
+		select {
+		case c := <-r.changes:
+			if err := c.Commit(ctx); err != nil {
+				r.log.Warn("Failed to commit change", "repository", r.name, "path", c.Path, "error", err)
+				r.AddConflict(Conflict{Path: c.Path, UID: c.UID, Reason: err.Error()})
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// CommitFile writes content to the file at path, relative to the root of the
+// work tree, and commits it with the given author. It returns the new commit,
+// or the current one if the content didn't change.
+func (r *Repository) CommitFile(ctx context.Context, path string, content []byte, message string, author Author) (string, error) {
+	path = filepath.Clean(path)
+	if filepath.IsAbs(path) || path == ".." || strings.HasPrefix(path, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%w: %s", ErrOutsideRepository, path)
+	}
+
+	r.gitMu.Lock()
+	defer r.gitMu.Unlock()
+
+	fullPath := filepath.Join(r.path, path)
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0750); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(fullPath, content, 0640); err != nil {
+		return "", err
+	}
+
+	if _, err := r.git(ctx, "add", "--", path); err != nil {
+		return "", err
+	}
+
+	// diff exits with 1 when there are staged changes
+	if _, err := r.git(ctx, "diff", "--cached", "--quiet", "--", path); err == nil {
+		return r.git(ctx, "rev-parse", "HEAD")
+	}
+
+	if author.Name == "" {
+		author.Name = "Grafana"
+	}
+	if _, err := r.git(ctx,
+		"-c", "user.name="+author.Name,
+		"-c", "user.email="+author.Email,
+		"commit", "--quiet", "--no-verify", "-m", message, "--", path,
+	); err != nil {
+		return "", err
+	}
+
+	// the commit is pushed again by the next sync when it fails
+	var pushErr error
+	if r.push {
+		if _, pushErr = r.git(ctx, "push", "--quiet"); pushErr != nil {
+			r.log.Warn("Failed to push commit", "repository", r.name, "error", pushErr)
+		}
+	}
+
+	commit, err := r.git(ctx, "rev-parse", "HEAD")
+	if err != nil {
+		return "", err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.commit = commit
+	if pushErr != nil {
+		r.lastErr = pushErr
+	}
+
+	return commit, nil
+}
+
+// AddConflict records a change that could not be committed.
+func (r *Repository) AddConflict(c Conflict) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if c.DetectedAt.IsZero() {
+		c.DetectedAt = r.now()
+	}
+	r.conflicts[c.Path] = c
+}
+
+// ResolveConflict forgets the conflict recorded for path, if any.
+func (r *Repository) ResolveConflict(path string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.conflicts, path)
+}
+
+// Status returns the current sync state of the repository.
+func (r *Repository) Status() Status {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	status := Status{
+		Name:      r.name,
+		Path:      r.path,
+		Branch:    r.branch,
+		Commit:    r.commit,
+		LastSync:  r.lastSync,
+		Conflicts: make([]Conflict, 0, len(r.conflicts)),
+	}
+	if r.lastErr != nil {
+		status.Error = r.lastErr.Error()
+	}
+	for _, c := range r.conflicts {
+		status.Conflicts = append(status.Conflicts, c)
+	}
+	sort.Slice(status.Conflicts, func(i, j int) bool {
+		return status.Conflicts[i].Path < status.Conflicts[j].Path
+	})
+	return status
+}
+
+func (r *Repository) git(ctx context.Context, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	// nolint:gosec
+	// The arguments are built by this package, only the paths and the branch come from the provisioning config.
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", r.path}, args...)...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// never wait for credentials on a terminal that doesn't exist
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
@@ -0,0 +1,200 @@
+package gitsync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+func TestRepository(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	ctx := context.Background()
+	upstream := initRepository(t)
+	writeAndCommit(t, upstream, "dashboards/home.json", `{"title": "Home"}`)
+
+	local := t.TempDir()
+	runGit(t, "", "clone", "--quiet", upstream, local)
+	runGit(t, upstream, "checkout", "--quiet", "-b", "other")
+
+	repo, err := NewRepository("gitops", local, "main", false, log.NewNopLogger())
+	require.NoError(t, err)
+
+	commit, changed, err := repo.Sync(ctx)
+	require.NoError(t, err)
+	require.True(t, changed)
+	require.NotEmpty(t, commit)
+
+	t.Run("sync without upstream changes keeps the commit", func(t *testing.T) {
+		again, changed, err := repo.Sync(ctx)
+		require.NoError(t, err)
+		require.False(t, changed)
+		require.Equal(t, commit, again)
+	})
+
+	t.Run("sync fast-forwards to upstream", func(t *testing.T) {
+		runGit(t, upstream, "checkout", "--quiet", "main")
+		upstreamCommit := writeAndCommit(t, upstream, "dashboards/home.json", `{"title": "Home v2"}`)
+		runGit(t, upstream, "checkout", "--quiet", "other")
+
+		synced, changed, err := repo.Sync(ctx)
+		require.NoError(t, err)
+		require.True(t, changed)
+		require.Equal(t, upstreamCommit, synced)
+
+		content, err := os.ReadFile(filepath.Join(local, "dashboards/home.json"))
+		require.NoError(t, err)
+		require.JSONEq(t, `{"title": "Home v2"}`, string(content))
+	})
+
+	t.Run("commit file uses the author", func(t *testing.T) {
+		commit, err := repo.CommitFile(ctx, "dashboards/new.json", []byte(`{"title": "New"}`), "Add new dashboard", Author{Name: "Jane Doe", Email: "jane@example.com"})
+		require.NoError(t, err)
+		require.Equal(t, commit, repo.Status().Commit)
+		require.Equal(t, "Jane Doe <jane@example.com>", runGit(t, local, "log", "-1", "--format=%an <%ae>"))
+		require.Equal(t, "Add new dashboard", runGit(t, local, "log", "-1", "--format=%s"))
+
+		same, err := repo.CommitFile(ctx, "dashboards/new.json", []byte(`{"title": "New"}`), "No change", Author{Name: "Jane Doe"})
+		require.NoError(t, err)
+		require.Equal(t, commit, same)
+	})
+
+	t.Run("commit file refuses paths outside of the repository", func(t *testing.T) {
+		_, err := repo.CommitFile(ctx, "../outside.json", []byte(`{}`), "Escape", Author{})
+		require.ErrorIs(t, err, ErrOutsideRepository)
+	})
+
+	t.Run("sync reports diverged branches", func(t *testing.T) {
+		runGit(t, upstream, "checkout", "--quiet", "main")
+		writeAndCommit(t, upstream, "dashboards/home.json", `{"title": "Home v3"}`)
+		runGit(t, upstream, "checkout", "--quiet", "other")
+
+		_, _, err := repo.Sync(ctx)
+		require.ErrorIs(t, err, ErrDiverged)
+		require.Equal(t, ErrDiverged.Error(), repo.Status().Error)
+	})
+
+	t.Run("conflicts are part of the status", func(t *testing.T) {
+		repo.AddConflict(Conflict{Path: "dashboards/b.json", Reason: "changed"})
+		repo.AddConflict(Conflict{Path: "dashboards/a.json", Reason: "changed"})
+		repo.ResolveConflict("dashboards/b.json")
+
+		status := repo.Status()
+		require.Equal(t, "gitops", status.Name)
+		require.Equal(t, "main", status.Branch)
+		require.Len(t, status.Conflicts, 1)
+		require.Equal(t, "dashboards/a.json", status.Conflicts[0].Path)
+		require.False(t, status.Conflicts[0].DetectedAt.IsZero())
+	})
+}
+
+func TestRepositoryCommitQueue(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	setup := func(t *testing.T) (*Repository, string) {
+		t.Helper()
+
+		dir := initRepository(t)
+		writeAndCommit(t, dir, "dashboards/home.json", `{"title": "Home"}`)
+		repo, err := NewRepository("gitops", dir, "main", false, log.NewNopLogger())
+		require.NoError(t, err)
+		return repo, dir
+	}
+
+	t.Run("queued changes are committed by the commit loop", func(t *testing.T) {
+		repo, dir := setup(t)
+
+		require.NoError(t, repo.Enqueue(Change{Path: "dashboards/new.json", UID: "new", Commit: func(ctx context.Context) error {
+			_, err := repo.CommitFile(ctx, "dashboards/new.json", []byte(`{"title": "New"}`), "Add new dashboard", Author{Name: "Jane Doe"})
+			return err
+		}}))
+		require.NoError(t, repo.Enqueue(Change{Path: "dashboards/broken.json", UID: "broken", Commit: func(ctx context.Context) error {
+			return errors.New("disk full")
+		}}))
+		require.Equal(t, "update dashboards/home.json", runGit(t, dir, "log", "-1", "--format=%s"), "nothing is committed before the loop runs")
+
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+		go repo.Run(ctx)
+
+		// the changes are committed in order, the failure of the last one is recorded once the first one is committed
+		require.Eventually(t, func() bool {
+			return len(repo.Status().Conflicts) == 1
+		}, 10*time.Second, 10*time.Millisecond)
+		require.Equal(t, "Jane Doe: Add new dashboard", runGit(t, dir, "log", "-1", "--format=%an: %s"))
+
+		conflict := repo.Status().Conflicts[0]
+		require.Equal(t, "dashboards/broken.json", conflict.Path)
+		require.Equal(t, "broken", conflict.UID)
+		require.Equal(t, "disk full", conflict.Reason)
+	})
+
+	t.Run("changes are rejected when the queue is full", func(t *testing.T) {
+		repo, _ := setup(t)
+
+		noop := func(ctx context.Context) error { return nil }
+		for i := 0; i < commitQueueSize; i++ {
+			require.NoError(t, repo.Enqueue(Change{Path: fmt.Sprintf("dashboards/%d.json", i), Commit: noop}))
+		}
+
+		err := repo.Enqueue(Change{Path: "dashboards/last.json", UID: "last", Commit: noop})
+		require.ErrorIs(t, err, ErrQueueFull)
+
+		conflicts := repo.Status().Conflicts
+		require.Len(t, conflicts, 1)
+		require.Equal(t, "dashboards/last.json", conflicts[0].Path)
+		require.Equal(t, ErrQueueFull.Error(), conflicts[0].Reason)
+	})
+}
+
+func TestNewRepositoryRequiresGitWorkTree(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	_, err := NewRepository("gitops", t.TempDir(), "main", false, log.NewNopLogger())
+	require.ErrorIs(t, err, ErrNotARepository)
+}
+
+func initRepository(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	runGit(t, dir, "init", "--quiet", "--initial-branch=main")
+	return dir
+}
+
+func writeAndCommit(t *testing.T, dir, path, content string) string {
+	t.Helper()
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, filepath.Dir(path)), 0750))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, path), []byte(content), 0600))
+	runGit(t, dir, "add", path)
+	runGit(t, dir, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--quiet", "-m", "update "+path)
+	return runGit(t, dir, "rev-parse", "HEAD")
+}
+
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+
+	if dir != "" {
+		args = append([]string{"-C", dir}, args...)
+	}
+	out, err := exec.Command("git", args...).CombinedOutput()
+	require.NoError(t, err, string(out))
+	return strings.TrimSpace(string(out))
+}
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"sync"
 
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/registry"
@@ -26,6 +27,7 @@ import (
 	prov_alerting "github.com/grafana/grafana/pkg/services/provisioning/alerting"
 	"github.com/grafana/grafana/pkg/services/provisioning/dashboards"
 	"github.com/grafana/grafana/pkg/services/provisioning/datasources"
+	"github.com/grafana/grafana/pkg/services/provisioning/gitsync"
 	"github.com/grafana/grafana/pkg/services/provisioning/plugins"
 	"github.com/grafana/grafana/pkg/services/quota"
 	"github.com/grafana/grafana/pkg/services/searchV2"
@@ -89,6 +91,8 @@ func (ps *ProvisioningServiceImpl) setDashboardProvisioner() error {
 	if err != nil {
 		return fmt.Errorf("%v: %w", "Failed to create provisioner", err)
 	}
+	// git repositories can hold alert rules next to their dashboards
+	dashProvisioner.SetAlertingProvisioner(ps.provisionAlertingFromPath)
 	ps.dashboardProvisioner = dashProvisioner
 	return nil
 }
@@ -102,6 +106,8 @@ type ProvisioningService interface {
 	ProvisionAlerting(ctx context.Context) error
 	GetDashboardProvisionerResolvedPath(name string) string
 	GetAllowUIUpdatesFromConfig(name string) bool
+	GetGitSyncStatus() []gitsync.Status
+	QueueProvisionedDashboardCommit(provisioning *dashboardservice.DashboardProvisioning, data *simplejson.Json, message string, author gitsync.Author) error
 }
 
 // Add a public constructor for overriding service to be able to instantiate OSS as fallback
@@ -248,7 +254,10 @@ func (ps *ProvisioningServiceImpl) ProvisionDashboards(ctx context.Context) erro
 }
 
 func (ps *ProvisioningServiceImpl) ProvisionAlerting(ctx context.Context) error {
-	alertingPath := filepath.Join(ps.Cfg.ProvisioningPath, "alerting")
+	return ps.provisionAlertingFromPath(ctx, filepath.Join(ps.Cfg.ProvisioningPath, "alerting"))
+}
+
+func (ps *ProvisioningServiceImpl) provisionAlertingFromPath(ctx context.Context, alertingPath string) error {
 	st := store.DBstore{
 		Cfg:              ps.Cfg.UnifiedAlerting,
 		SQLStore:         ps.SQLStore,
@@ -298,6 +307,16 @@ func (ps *ProvisioningServiceImpl) GetAllowUIUpdatesFromConfig(name string) bool
 	return ps.dashboardProvisioner.GetAllowUIUpdatesFromConfig(name)
 }
 
+func (ps *ProvisioningServiceImpl) GetGitSyncStatus() []gitsync.Status {
+	return ps.dashboardProvisioner.GetGitSyncStatus()
+}
+
+// QueueProvisionedDashboardCommit queues the commit of a provisioned dashboard saved in the UI to the git
+// repository it comes from, if its provider is configured to do so. The commit runs in the background.
+func (ps *ProvisioningServiceImpl) QueueProvisionedDashboardCommit(provisioning *dashboardservice.DashboardProvisioning, data *simplejson.Json, message string, author gitsync.Author) error {
+	return ps.dashboardProvisioner.QueueDashboardCommit(provisioning, data, message, author)
+}
+
 func (ps *ProvisioningServiceImpl) cancelPolling() {
 	if ps.pollingCtxCancel != nil {
 		ps.log.Debug("Stop polling for dashboard changes")
@@ -1,6 +1,12 @@
 package provisioning
 
-import "context"
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/provisioning/gitsync"
+)
 
 type Calls struct {
 	RunInitProvisioners                 []any
@@ -10,6 +16,8 @@ type Calls struct {
 	ProvisionAlerting                   []any
 	GetDashboardProvisionerResolvedPath []any
 	GetAllowUIUpdatesFromConfig         []any
+	GetGitSyncStatus                    []any
+	QueueProvisionedDashboardCommit     []any
 	Run                                 []any
 }
 
@@ -21,6 +29,8 @@ type ProvisioningServiceMock struct {
 	ProvisionDashboardsFunc                 func() error
 	GetDashboardProvisionerResolvedPathFunc func(name string) string
 	GetAllowUIUpdatesFromConfigFunc         func(name string) bool
+	GetGitSyncStatusFunc                    func() []gitsync.Status
+	QueueProvisionedDashboardCommitFunc     func(provisioning *dashboards.DashboardProvisioning, data *simplejson.Json, message string, author gitsync.Author) error
 	RunFunc                                 func(ctx context.Context) error
 }
 
@@ -83,6 +93,22 @@ func (mock *ProvisioningServiceMock) GetAllowUIUpdatesFromConfig(name string) bo
 	return false
 }
 
+func (mock *ProvisioningServiceMock) GetGitSyncStatus() []gitsync.Status {
+	mock.Calls.GetGitSyncStatus = append(mock.Calls.GetGitSyncStatus, nil)
+	if mock.GetGitSyncStatusFunc != nil {
+		return mock.GetGitSyncStatusFunc()
+	}
+	return nil
+}
+
+func (mock *ProvisioningServiceMock) QueueProvisionedDashboardCommit(provisioning *dashboards.DashboardProvisioning, data *simplejson.Json, message string, author gitsync.Author) error {
+	mock.Calls.QueueProvisionedDashboardCommit = append(mock.Calls.QueueProvisionedDashboardCommit, provisioning)
+	if mock.QueueProvisionedDashboardCommitFunc != nil {
+		return mock.QueueProvisionedDashboardCommitFunc(provisioning, data, message, author)
+	}
+	return nil
+}
+
 func (mock *ProvisioningServiceMock) Run(ctx context.Context) error {
 	mock.Calls.Run = append(mock.Calls.Run, nil)
 	if mock.RunFunc != nil {
@@ -3,8 +3,10 @@ package api
 import (
 	"context"
 	"net/http"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
 
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/api/routing"
@@ -16,6 +18,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/licensing"
 	"github.com/grafana/grafana/pkg/services/publicdashboards"
+	"github.com/grafana/grafana/pkg/services/publicdashboards/metric"
 	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
 	"github.com/grafana/grafana/pkg/services/publicdashboards/validation"
 	"github.com/grafana/grafana/pkg/setting"
@@ -32,6 +35,10 @@ type Api struct {
 	license       licensing.Licensing
 	log           log.Logger
 	routeRegister routing.RouteRegister
+	metricService *metric.Service
+
+	tokenRateLimiter    *rateLimiter
+	clientIPRateLimiter *rateLimiter
 }
 
 func ProvideApi(
@@ -42,6 +49,7 @@ func ProvideApi(
 	md publicdashboards.Middleware,
 	cfg *setting.Cfg,
 	license licensing.Licensing,
+	metricService *metric.Service,
 ) *Api {
 	api := &Api{
 		PublicDashboardService: pd,
@@ -52,6 +60,9 @@ func ProvideApi(
 		license:                license,
 		log:                    log.New("publicdashboards.api"),
 		routeRegister:          rr,
+		metricService:          metricService,
+		tokenRateLimiter:       newRateLimiter(cfg.PublicDashboardsTokenRateLimit),
+		clientIPRateLimiter:    newRateLimiter(cfg.PublicDashboardsClientIPRateLimit),
 	}
 
 	// register endpoints if the feature is enabled
@@ -73,9 +84,9 @@ func (api *Api) RegisterAPIEndpoints() {
 	// because it is deeply dependent on the HTTPServer.Index() method and would result in a
 	// circular dependency
 	api.routeRegister.Group("/api/public/dashboards/:accessToken", func(apiRoute routing.RouteRegister) {
-		apiRoute.Get("/", routing.Wrap(api.ViewPublicDashboard))
-		apiRoute.Get("/annotations", routing.Wrap(api.GetPublicAnnotations))
-		apiRoute.Post("/panels/:panelId/query", routing.Wrap(api.QueryPublicDashboard))
+		apiRoute.Get("/", RequiresValidAccessToken(api.PublicDashboardService, true), routing.Wrap(api.ViewPublicDashboard))
+		apiRoute.Get("/annotations", RequiresValidAccessToken(api.PublicDashboardService, false), api.rateLimit(annotationsEndpoint), routing.Wrap(api.GetPublicAnnotations))
+		apiRoute.Post("/panels/:panelId/query", RequiresValidAccessToken(api.PublicDashboardService, false), api.rateLimit(queryEndpoint), routing.Wrap(api.QueryPublicDashboard))
 	}, api.Middleware.HandleApi)
 
 	// Auth endpoints
@@ -103,6 +114,16 @@ func (api *Api) RegisterAPIEndpoints() {
 	api.routeRegister.Delete("/api/dashboards/uid/:dashboardUid/public-dashboards/:uid",
 		auth(accesscontrol.EvalPermission(dashboards.ActionDashboardsPublicWrite, uidScope)),
 		routing.Wrap(api.DeletePublicDashboard))
+
+	// Suspend Public dashboard
+	api.routeRegister.Post("/api/dashboards/uid/:dashboardUid/public-dashboards/:uid/suspend",
+		auth(accesscontrol.EvalPermission(dashboards.ActionDashboardsPublicWrite, uidScope)),
+		routing.Wrap(api.SuspendPublicDashboard))
+
+	// Resume Public dashboard
+	api.routeRegister.Delete("/api/dashboards/uid/:dashboardUid/public-dashboards/:uid/suspend",
+		auth(accesscontrol.EvalPermission(dashboards.ActionDashboardsPublicWrite, uidScope)),
+		routing.Wrap(api.ResumePublicDashboard))
 }
 
 // swagger:route GET /dashboards/public-dashboards dashboard_public listPublicDashboards
@@ -297,6 +318,81 @@ func (api *Api) DeletePublicDashboard(c *contextmodel.ReqContext) response.Respo
 		return response.Err(err)
 	}
 
+	if api.metricService != nil {
+		api.metricService.DeletePublicDashboard(uid)
+	}
+
+	return response.JSON(http.StatusOK, nil)
+}
+
+// swagger:route POST /dashboards/uid/{dashboardUid}/public-dashboards/{uid}/suspend dashboard_public suspendPublicDashboard
+//
+//	Temporarily suspend the access token of a public dashboard
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestPublicError
+// 401: unauthorisedPublicError
+// 403: forbiddenPublicError
+// 404: notFoundPublicError
+// 500: internalServerPublicError
+func (api *Api) SuspendPublicDashboard(c *contextmodel.ReqContext) response.Response {
+	uid := web.Params(c.Req)[":uid"]
+	if !validation.IsValidShortUID(uid) {
+		return response.Err(ErrInvalidUid.Errorf("SuspendPublicDashboard: invalid Uid %s", uid))
+	}
+
+	dashboardUid := web.Params(c.Req)[":dashboardUid"]
+	if !validation.IsValidShortUID(dashboardUid) {
+		return response.Err(ErrInvalidUid.Errorf("SuspendPublicDashboard: invalid dashboard Uid %s", dashboardUid))
+	}
+
+	dto := SuspendPublicDashboardDTO{}
+	if err := web.Bind(c.Req, &dto); err != nil {
+		return response.Err(ErrBadRequest.Errorf("SuspendPublicDashboard: bad request data %v", err))
+	}
+
+	duration, err := gtime.ParseDuration(dto.Duration)
+	if err != nil || duration <= 0 {
+		return response.Err(ErrInvalidSuspendDuration.Errorf("SuspendPublicDashboard: invalid duration %s", dto.Duration))
+	}
+
+	until := time.Now().Add(duration)
+	err = api.PublicDashboardService.Suspend(c.Req.Context(), uid, dashboardUid, &until)
+	if err != nil {
+		return response.Err(err)
+	}
+
+	return response.JSON(http.StatusOK, nil)
+}
+
+// swagger:route DELETE /dashboards/uid/{dashboardUid}/public-dashboards/{uid}/suspend dashboard_public resumePublicDashboard
+//
+//	Resume the access token of a suspended public dashboard
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestPublicError
+// 401: unauthorisedPublicError
+// 403: forbiddenPublicError
+// 404: notFoundPublicError
+// 500: internalServerPublicError
+func (api *Api) ResumePublicDashboard(c *contextmodel.ReqContext) response.Response {
+	uid := web.Params(c.Req)[":uid"]
+	if !validation.IsValidShortUID(uid) {
+		return response.Err(ErrInvalidUid.Errorf("ResumePublicDashboard: invalid Uid %s", uid))
+	}
+
+	dashboardUid := web.Params(c.Req)[":dashboardUid"]
+	if !validation.IsValidShortUID(dashboardUid) {
+		return response.Err(ErrInvalidUid.Errorf("ResumePublicDashboard: invalid dashboard Uid %s", dashboardUid))
+	}
+
+	err := api.PublicDashboardService.Suspend(c.Req.Context(), uid, dashboardUid, nil)
+	if err != nil {
+		return response.Err(err)
+	}
+
 	return response.JSON(http.StatusOK, nil)
 }
 
@@ -379,3 +475,26 @@ type DeletePublicDashboardParams struct {
 	// required:true
 	Uid string `json:"uid"`
 }
+
+// swagger:parameters suspendPublicDashboard
+type SuspendPublicDashboardParams struct {
+	// in:path
+	// required:true
+	DashboardUid string `json:"dashboardUid"`
+	// in:path
+	// required:true
+	Uid string `json:"uid"`
+	// in:body
+	// required:true
+	Body SuspendPublicDashboardDTO
+}
+
+// swagger:parameters resumePublicDashboard
+type ResumePublicDashboardParams struct {
+	// in:path
+	// required:true
+	DashboardUid string `json:"dashboardUid"`
+	// in:path
+	// required:true
+	Uid string `json:"uid"`
+}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -287,6 +288,93 @@ func TestAPIDeletePublicDashboard(t *testing.T) {
 	}
 }
 
+func TestAPISuspendPublicDashboard(t *testing.T) {
+	dashboardUid := "abc1234"
+	publicDashboardUid := "1234asdfasdf"
+	userViewer := &user.SignedInUser{UserID: 1, OrgID: 1, OrgRole: org.RoleViewer, Login: "testViewerUser"}
+	userEditorPublicDashboard := &user.SignedInUser{UserID: 4, OrgID: 1, OrgRole: org.RoleEditor, Login: "testEditorUser", Permissions: map[int64]map[string][]string{1: {dashboards.ActionDashboardsPublicWrite: {fmt.Sprintf("dashboards:uid:%s", dashboardUid)}}}}
+
+	testCases := []struct {
+		Name                 string
+		User                 *user.SignedInUser
+		Method               string
+		Body                 string
+		ResponseErr          error
+		ExpectedHttpResponse int
+		ShouldCallService    bool
+	}{
+		{
+			Name:                 "User viewer cannot suspend public dashboard",
+			User:                 userViewer,
+			Method:               http.MethodPost,
+			Body:                 `{"duration": "1h"}`,
+			ExpectedHttpResponse: http.StatusForbidden,
+		},
+		{
+			Name:                 "User editor with dashboard access can suspend public dashboard",
+			User:                 userEditorPublicDashboard,
+			Method:               http.MethodPost,
+			Body:                 `{"duration": "1h"}`,
+			ExpectedHttpResponse: http.StatusOK,
+			ShouldCallService:    true,
+		},
+		{
+			Name:                 "Invalid duration returns an error",
+			User:                 userEditorPublicDashboard,
+			Method:               http.MethodPost,
+			Body:                 `{"duration": "soon"}`,
+			ExpectedHttpResponse: http.StatusBadRequest,
+		},
+		{
+			Name:                 "Negative duration returns an error",
+			User:                 userEditorPublicDashboard,
+			Method:               http.MethodPost,
+			Body:                 `{"duration": "-1h"}`,
+			ExpectedHttpResponse: http.StatusBadRequest,
+		},
+		{
+			Name:                 "User editor with dashboard access can resume public dashboard",
+			User:                 userEditorPublicDashboard,
+			Method:               http.MethodDelete,
+			ExpectedHttpResponse: http.StatusOK,
+			ShouldCallService:    true,
+		},
+		{
+			Name:                 "Public dashboard uid does not exist",
+			User:                 userEditorPublicDashboard,
+			Method:               http.MethodPost,
+			Body:                 `{"duration": "1h"}`,
+			ResponseErr:          ErrPublicDashboardNotFound.Errorf(""),
+			ExpectedHttpResponse: http.StatusNotFound,
+			ShouldCallService:    true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.Name, func(t *testing.T) {
+			service := publicdashboards.NewFakePublicDashboardService(t)
+
+			if test.ShouldCallService {
+				service.On("Suspend", mock.Anything, publicDashboardUid, dashboardUid, mock.MatchedBy(func(until *time.Time) bool {
+					if test.Method == http.MethodDelete {
+						return until == nil
+					}
+					return until != nil && until.After(time.Now().Add(59*time.Minute))
+				})).Return(test.ResponseErr)
+			}
+
+			testServer := setupTestServer(t, nil, service, test.User, true)
+
+			response := callAPI(testServer, test.Method, fmt.Sprintf("/api/dashboards/uid/%s/public-dashboards/%s/suspend", dashboardUid, publicDashboardUid), strings.NewReader(test.Body), t)
+			assert.Equal(t, test.ExpectedHttpResponse, response.Code)
+
+			if !test.ShouldCallService {
+				service.AssertNotCalled(t, "Suspend")
+			}
+		})
+	}
+}
+
 func TestAPIGetPublicDashboard(t *testing.T) {
 	pubdash := &PublicDashboard{IsEnabled: true}
 
@@ -1,11 +1,15 @@
 package api
 
 import (
+	"context"
+	"errors"
 	"net/http"
+	"time"
 
 	"github.com/grafana/grafana/pkg/infra/metrics"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/publicdashboards"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
 	"github.com/grafana/grafana/pkg/services/publicdashboards/validation"
 	"github.com/grafana/grafana/pkg/web"
 )
@@ -62,6 +66,52 @@ func RequiresExistingAccessToken(publicDashboardService publicdashboards.Service
 	}
 }
 
+// RequiresValidAccessToken Middleware to reject the access tokens of public dashboards that expired or are suspended.
+// When isView is true, the access tokens of public dashboards that reached their maximum number of views are rejected
+// too. Tokens that are invalid or do not reference a public dashboard are left to the handler.
+func RequiresValidAccessToken(publicDashboardService publicdashboards.Service, isView bool) func(c *contextmodel.ReqContext) {
+	return func(c *contextmodel.ReqContext) {
+		accessToken, ok := web.Params(c.Req)[":accessToken"]
+		if !ok || !validation.IsValidAccessToken(accessToken) {
+			return
+		}
+
+		pubdash, err := publicDashboardService.FindByAccessToken(c.Req.Context(), accessToken)
+		if err != nil {
+			if errors.Is(err, ErrPublicDashboardNotFound) {
+				return
+			}
+			c.JsonApiErr(http.StatusInternalServerError, "Failed to query access token", nil)
+			return
+		}
+
+		if pubdash.IsExpired(time.Now()) {
+			c.JsonApiErr(http.StatusForbidden, "Public dashboard link expired", nil)
+			return
+		}
+
+		if pubdash.IsSuspended(time.Now()) {
+			c.JsonApiErr(http.StatusForbidden, "Public dashboard link suspended", nil)
+			return
+		}
+
+		if isView && pubdash.ViewLimitReached() {
+			c.JsonApiErr(http.StatusForbidden, "Public dashboard link reached its maximum number of views", nil)
+			return
+		}
+
+		c.Req = c.Req.WithContext(context.WithValue(c.Req.Context(), publicDashboardContextKey{}, pubdash))
+	}
+}
+
+type publicDashboardContextKey struct{}
+
+// publicDashboardFromContext returns the public dashboard of the access token found by RequiresValidAccessToken, or nil
+func publicDashboardFromContext(c *contextmodel.ReqContext) *PublicDashboard {
+	pubdash, _ := c.Req.Context().Value(publicDashboardContextKey{}).(*PublicDashboard)
+	return pubdash
+}
+
 func CountPublicDashboardRequest() func(c *contextmodel.ReqContext) {
 	return func(c *contextmodel.ReqContext) {
 		metrics.MPublicDashboardRequestCount.Inc()
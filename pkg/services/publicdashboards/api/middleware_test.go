@@ -5,11 +5,13 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"errors"
 
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/publicdashboards"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
 	"github.com/grafana/grafana/pkg/services/publicdashboards/service"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/web"
@@ -83,6 +85,86 @@ func TestRequiresExistingAccessToken(t *testing.T) {
 	}
 }
 
+func TestRequiresValidAccessToken(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		Name                 string
+		IsView               bool
+		AccessToken          string
+		PublicDashboard      *PublicDashboard
+		Err                  error
+		ExpectedResponseCode int
+	}{
+		{
+			Name:                 "Returns 200 when public dashboard is valid",
+			AccessToken:          validAccessToken,
+			PublicDashboard:      &PublicDashboard{ExpiresAt: &future, MaxViews: 10, ViewCount: 9},
+			ExpectedResponseCode: http.StatusOK,
+		},
+		{
+			Name:                 "Returns 200 when access token is invalid",
+			AccessToken:          "invalidAccessToken",
+			ExpectedResponseCode: http.StatusOK,
+		},
+		{
+			Name:                 "Returns 200 when public dashboard does not exist",
+			AccessToken:          validAccessToken,
+			Err:                  ErrPublicDashboardNotFound.Errorf(""),
+			ExpectedResponseCode: http.StatusOK,
+		},
+		{
+			Name:                 "Returns 500 when public dashboard service gives an error",
+			AccessToken:          validAccessToken,
+			Err:                  errors.New("database error of some sort"),
+			ExpectedResponseCode: http.StatusInternalServerError,
+		},
+		{
+			Name:                 "Returns 403 when public dashboard expired",
+			AccessToken:          validAccessToken,
+			PublicDashboard:      &PublicDashboard{ExpiresAt: &past},
+			ExpectedResponseCode: http.StatusForbidden,
+		},
+		{
+			Name:                 "Returns 403 when public dashboard is suspended",
+			AccessToken:          validAccessToken,
+			PublicDashboard:      &PublicDashboard{SuspendedUntil: &future},
+			ExpectedResponseCode: http.StatusForbidden,
+		},
+		{
+			Name:                 "Returns 200 when public dashboard suspension ended",
+			AccessToken:          validAccessToken,
+			PublicDashboard:      &PublicDashboard{SuspendedUntil: &past},
+			ExpectedResponseCode: http.StatusOK,
+		},
+		{
+			Name:                 "Returns 403 when public dashboard reached its maximum number of views on view",
+			IsView:               true,
+			AccessToken:          validAccessToken,
+			PublicDashboard:      &PublicDashboard{MaxViews: 10, ViewCount: 10},
+			ExpectedResponseCode: http.StatusForbidden,
+		},
+		{
+			Name:                 "Returns 200 when public dashboard reached its maximum number of views on query",
+			AccessToken:          validAccessToken,
+			PublicDashboard:      &PublicDashboard{MaxViews: 10, ViewCount: 10},
+			ExpectedResponseCode: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			publicdashboardService := &publicdashboards.FakePublicDashboardService{}
+			publicdashboardService.On("FindByAccessToken", mock.Anything, tt.AccessToken).Return(tt.PublicDashboard, tt.Err)
+			params := map[string]string{":accessToken": tt.AccessToken}
+			mw := RequiresValidAccessToken(publicdashboardService, tt.IsView)
+			_, resp := runMw(t, nil, "GET", "/api/public/dashboards/myAccesstoken", params, mw)
+			require.Equal(t, tt.ExpectedResponseCode, resp.Code)
+		})
+	}
+}
+
 func TestSetPublicDashboardOrgIdOnContext(t *testing.T) {
 	tests := []struct {
 		Name          string
@@ -0,0 +1,136 @@
+package api
+
+import (
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/infra/network"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+const (
+	// rateLimiterExpiration is how long the rate limiter of a key is kept after its last request. Limiters are full
+	// again a minute after their last request, so they can be dropped after that.
+	rateLimiterExpiration = 10 * time.Minute
+
+	queryEndpoint       = "query"
+	annotationsEndpoint = "annotations"
+)
+
+// rateLimiter limits the number of requests per minute of each key, such as an access token or a client IP
+type rateLimiter struct {
+	limit    rate.Limit
+	burst    int
+	limiters *localcache.CacheService
+	mu       sync.Mutex
+}
+
+// newRateLimiter returns a rate limiter of the number of requests per minute, or nil when it is not limited
+func newRateLimiter(requestsPerMinute int) *rateLimiter {
+	if requestsPerMinute <= 0 {
+		return nil
+	}
+
+	return &rateLimiter{
+		limit:    rate.Limit(float64(requestsPerMinute) / 60),
+		burst:    requestsPerMinute,
+		limiters: localcache.New(rateLimiterExpiration, rateLimiterExpiration),
+	}
+}
+
+// allow returns true when a request of the key is allowed now
+func (l *rateLimiter) allow(key string) bool {
+	if l == nil {
+		return true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	limiter, ok := l.limiters.Get(key)
+	if !ok {
+		limiter = rate.NewLimiter(l.limit, l.burst)
+	}
+	// the expiration is extended on every request
+	l.limiters.SetDefault(key, limiter)
+
+	return limiter.(*rate.Limiter).Allow()
+}
+
+// rateLimit Middleware to limit the number of requests of each public dashboard and of each client IP, and to count the
+// requests of each public dashboard. Use after RequiresValidAccessToken.
+//
+// The client IP is resolved by clientIP. The access tokens which don't reference a public dashboard are not limited, so
+// they don't add limiters.
+func (api *Api) rateLimit(endpoint string) func(c *contextmodel.ReqContext) {
+	return func(c *contextmodel.ReqContext) {
+		accessToken := web.Params(c.Req)[":accessToken"]
+		pubdash := publicDashboardFromContext(c)
+
+		record := func(limit string) {
+			if pubdash == nil || api.metricService == nil {
+				return
+			}
+			if limit == "" {
+				api.metricService.RecordRequest(pubdash.Uid, endpoint)
+			} else {
+				api.metricService.RecordRateLimitedRequest(pubdash.Uid, endpoint, limit)
+			}
+		}
+
+		if !api.clientIPRateLimiter.allow(clientIP(c.Req, api.cfg.PublicDashboardsTrustedProxies)) {
+			record("client_ip")
+			c.JsonApiErr(http.StatusTooManyRequests, "Too many requests", nil)
+			return
+		}
+
+		if pubdash != nil && !api.tokenRateLimiter.allow(accessToken) {
+			record("token")
+			c.JsonApiErr(http.StatusTooManyRequests, "Too many requests", nil)
+			return
+		}
+
+		record("")
+	}
+}
+
+// clientIP returns the IP of the client of the request. It is the address of the connection, unless the connection
+// comes from a trusted proxy. Then the X-Forwarded-For header is read from right to left, since each proxy appends the
+// address of its own client, and the client IP is the first address which is not a trusted proxy. The addresses on the
+// left of it are set by the client, so they are never used.
+func clientIP(r *http.Request, trustedProxies []*net.IPNet) string {
+	ip, err := network.GetIPFromAddress(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	hops := make([]string, 0)
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(header, ",")...)
+	}
+	for i := len(hops) - 1; i >= 0 && isTrustedProxy(ip, trustedProxies); i-- {
+		hop := net.ParseIP(strings.TrimSpace(hops[i]))
+		if hop == nil {
+			// the proxies only append valid addresses, so the invalid ones are set by the client
+			break
+		}
+		ip = hop
+	}
+	return ip.String()
+}
+
+func isTrustedProxy(ip net.IP, trustedProxies []*net.IPNet) bool {
+	for _, proxy := range trustedProxies {
+		if proxy.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,185 @@
+package api
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/publicdashboards"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+	"github.com/grafana/grafana/pkg/services/publicdashboards/service"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestRateLimiter(t *testing.T) {
+	t.Run("allows every request when not limited", func(t *testing.T) {
+		limiter := newRateLimiter(0)
+		require.Nil(t, limiter)
+		for i := 0; i < 100; i++ {
+			require.True(t, limiter.allow("key"))
+		}
+	})
+
+	t.Run("limits the requests of each key", func(t *testing.T) {
+		limiter := newRateLimiter(2)
+		assert.True(t, limiter.allow("a"))
+		assert.True(t, limiter.allow("a"))
+		assert.False(t, limiter.allow("a"))
+		assert.True(t, limiter.allow("b"))
+	})
+}
+
+func TestAPIRateLimitPublicDashboardQueries(t *testing.T) {
+	setup := func(t *testing.T) *publicdashboards.FakePublicDashboardService {
+		pubdashService := publicdashboards.NewFakePublicDashboardService(t)
+		pubdashService.On("FindByAccessToken", mock.Anything, mock.AnythingOfType("string")).
+			Return(&PublicDashboard{Uid: "pubdash-uid"}, nil)
+		pubdashService.On("GetQueryDataResponse", mock.Anything, mock.Anything, int64(2), mock.AnythingOfType("string")).
+			Return(&backend.QueryDataResponse{}, nil)
+		return pubdashService
+	}
+
+	otherAccessToken, err := service.GenerateAccessToken()
+	require.NoError(t, err)
+
+	cfgWithLimits := func(tokenLimit int, clientIPLimit int) *setting.Cfg {
+		cfg := setting.NewCfg()
+		cfg.PublicDashboardsEnabled = true
+		cfg.PublicDashboardsTokenRateLimit = tokenLimit
+		cfg.PublicDashboardsClientIPRateLimit = clientIPLimit
+		return cfg
+	}
+
+	t.Run("rejects the requests over the limit of the access token", func(t *testing.T) {
+		pubdashService := setup(t)
+		server := setupTestServer(t, cfgWithLimits(2, 0), pubdashService, anonymousUser, true)
+
+		for i := 0; i < 2; i++ {
+			resp := callAPI(server, http.MethodPost, getValidQueryPath(validAccessToken), strings.NewReader("{}"), t)
+			require.Equal(t, http.StatusOK, resp.Code)
+		}
+
+		resp := callAPI(server, http.MethodPost, getValidQueryPath(validAccessToken), strings.NewReader("{}"), t)
+		require.Equal(t, http.StatusTooManyRequests, resp.Code)
+		pubdashService.AssertNumberOfCalls(t, "GetQueryDataResponse", 2)
+
+		// other access tokens have their own limit
+		resp = callAPI(server, http.MethodPost, getValidQueryPath(otherAccessToken), strings.NewReader("{}"), t)
+		require.Equal(t, http.StatusOK, resp.Code)
+	})
+
+	t.Run("rejects the requests over the limit of the client IP", func(t *testing.T) {
+		pubdashService := setup(t)
+		server := setupTestServer(t, cfgWithLimits(0, 1), pubdashService, anonymousUser, true)
+
+		resp := callAPI(server, http.MethodPost, getValidQueryPath(validAccessToken), strings.NewReader("{}"), t)
+		require.Equal(t, http.StatusOK, resp.Code)
+
+		resp = callAPI(server, http.MethodPost, getValidQueryPath(otherAccessToken), strings.NewReader("{}"), t)
+		require.Equal(t, http.StatusTooManyRequests, resp.Code)
+		pubdashService.AssertNumberOfCalls(t, "GetQueryDataResponse", 1)
+	})
+
+	t.Run("does not use the client IP headers set by the clients", func(t *testing.T) {
+		pubdashService := setup(t)
+		server := setupTestServer(t, cfgWithLimits(0, 1), pubdashService, anonymousUser, true)
+
+		for i, ip := range []string{"10.0.0.1", "10.0.0.2"} {
+			req, err := http.NewRequest(http.MethodPost, getValidQueryPath(validAccessToken), strings.NewReader("{}"))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-Forwarded-For", ip)
+			req.RemoteAddr = "192.0.2.1:1234"
+			recorder := httptest.NewRecorder()
+			server.ServeHTTP(recorder, req)
+			if i == 0 {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			} else {
+				require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+			}
+		}
+	})
+
+	t.Run("limits the clients forwarded by the trusted proxies separately", func(t *testing.T) {
+		pubdashService := setup(t)
+		cfg := cfgWithLimits(0, 1)
+		_, proxies, err := net.ParseCIDR("192.0.2.0/24")
+		require.NoError(t, err)
+		cfg.PublicDashboardsTrustedProxies = []*net.IPNet{proxies}
+		server := setupTestServer(t, cfg, pubdashService, anonymousUser, true)
+
+		for _, test := range []struct {
+			forwardedFor string
+			code         int
+		}{
+			{forwardedFor: "10.0.0.1", code: http.StatusOK},
+			{forwardedFor: "10.0.0.2", code: http.StatusOK},
+			// the addresses set by the client on the left of the proxies are not used
+			{forwardedFor: "10.0.0.3, 10.0.0.1", code: http.StatusTooManyRequests},
+		} {
+			req, err := http.NewRequest(http.MethodPost, getValidQueryPath(validAccessToken), strings.NewReader("{}"))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-Forwarded-For", test.forwardedFor)
+			req.RemoteAddr = "192.0.2.1:1234"
+			recorder := httptest.NewRecorder()
+			server.ServeHTTP(recorder, req)
+			require.Equal(t, test.code, recorder.Code, test.forwardedFor)
+		}
+	})
+
+	t.Run("does not limit the access tokens of public dashboards that do not exist", func(t *testing.T) {
+		pubdashService := publicdashboards.NewFakePublicDashboardService(t)
+		pubdashService.On("FindByAccessToken", mock.Anything, mock.AnythingOfType("string")).
+			Return(nil, ErrPublicDashboardNotFound.Errorf("not found"))
+		pubdashService.On("GetQueryDataResponse", mock.Anything, mock.Anything, int64(2), mock.AnythingOfType("string")).
+			Return(nil, ErrPublicDashboardNotFound.Errorf("not found"))
+		server := setupTestServer(t, cfgWithLimits(1, 0), pubdashService, anonymousUser, true)
+
+		for i := 0; i < 3; i++ {
+			resp := callAPI(server, http.MethodPost, getValidQueryPath(validAccessToken), strings.NewReader("{}"), t)
+			require.Equal(t, http.StatusNotFound, resp.Code)
+		}
+	})
+}
+
+func TestClientIP(t *testing.T) {
+	_, proxies, err := net.ParseCIDR("192.0.2.0/24")
+	require.NoError(t, err)
+	trustedProxies := []*net.IPNet{proxies}
+
+	testCases := []struct {
+		desc           string
+		remoteAddr     string
+		forwardedFor   []string
+		trustedProxies []*net.IPNet
+		expected       string
+	}{
+		{desc: "connection without proxy", remoteAddr: "10.0.0.1:1234", expected: "10.0.0.1"},
+		{desc: "untrusted connection", remoteAddr: "10.0.0.1:1234", forwardedFor: []string{"10.0.0.2"}, trustedProxies: trustedProxies, expected: "10.0.0.1"},
+		{desc: "no trusted proxies", remoteAddr: "192.0.2.1:1234", forwardedFor: []string{"10.0.0.2"}, expected: "192.0.2.1"},
+		{desc: "trusted proxy", remoteAddr: "192.0.2.1:1234", forwardedFor: []string{"10.0.0.2"}, trustedProxies: trustedProxies, expected: "10.0.0.2"},
+		{desc: "chain of trusted proxies", remoteAddr: "192.0.2.1:1234", forwardedFor: []string{"10.0.0.3, 10.0.0.2, 192.0.2.2"}, trustedProxies: trustedProxies, expected: "10.0.0.2"},
+		{desc: "several headers", remoteAddr: "192.0.2.1:1234", forwardedFor: []string{"10.0.0.3", "10.0.0.2"}, trustedProxies: trustedProxies, expected: "10.0.0.2"},
+		{desc: "invalid address", remoteAddr: "192.0.2.1:1234", forwardedFor: []string{"10.0.0.3, unknown, 192.0.2.2"}, trustedProxies: trustedProxies, expected: "192.0.2.2"},
+		{desc: "trusted proxy without header", remoteAddr: "192.0.2.1:1234", trustedProxies: trustedProxies, expected: "192.0.2.1"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tc.remoteAddr
+			for _, header := range tc.forwardedFor {
+				req.Header.Add("X-Forwarded-For", header)
+			}
+			require.Equal(t, tc.expected, clientIP(req, tc.trustedProxies))
+		})
+	}
+}
@@ -230,6 +230,30 @@ func TestIntegrationExistsEnabledByAccessToken(t *testing.T) {
 		require.False(t, res)
 	})
 
+	t.Run("ExistsEnabledByAccessToken will return false when the public dashboard expired", func(t *testing.T) {
+		setup()
+
+		expiresAt := time.Now().Add(-time.Hour)
+		_, err := publicdashboardStore.Create(context.Background(), SavePublicDashboardCommand{
+			PublicDashboard: PublicDashboard{
+				IsEnabled:    true,
+				Uid:          "abc123",
+				DashboardUid: savedDashboard.UID,
+				OrgId:        savedDashboard.OrgID,
+				CreatedAt:    time.Now(),
+				CreatedBy:    7,
+				AccessToken:  "accessToken",
+				ExpiresAt:    &expiresAt,
+			},
+		})
+		require.NoError(t, err)
+
+		res, err := publicdashboardStore.ExistsEnabledByAccessToken(context.Background(), "accessToken")
+		require.NoError(t, err)
+
+		require.False(t, res)
+	})
+
 	t.Run("ExistsEnabledByAccessToken will return false when no public dashboard has matching access token", func(t *testing.T) {
 		setup()
 
@@ -625,6 +649,95 @@ func TestIntegrationUpdatePublicDashboard(t *testing.T) {
 	})
 }
 
+func TestIntegrationIncrementViewCount(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	sqlStore, cfg := db.InitTestDBwithCfg(t)
+	quotaService := quotatest.New(false, nil)
+	dashboardStore, err := dashboardsDB.ProvideDashboardStore(sqlStore, cfg, featuremgmt.WithFeatures(), tagimpl.ProvideService(sqlStore), quotaService)
+	require.NoError(t, err)
+	publicdashboardStore := ProvideStore(sqlStore, cfg, featuremgmt.WithFeatures())
+	savedDashboard := insertTestDashboard(t, dashboardStore, "testDashie", 1, "", true)
+
+	_, err = publicdashboardStore.Create(context.Background(), SavePublicDashboardCommand{
+		PublicDashboard: PublicDashboard{
+			IsEnabled:    true,
+			Uid:          "abc123",
+			DashboardUid: savedDashboard.UID,
+			OrgId:        savedDashboard.OrgID,
+			CreatedAt:    time.Now(),
+			CreatedBy:    7,
+			AccessToken:  "accessToken",
+			MaxViews:     2,
+		},
+	})
+	require.NoError(t, err)
+
+	for _, expected := range []bool{true, true, false} {
+		counted, err := publicdashboardStore.IncrementViewCount(context.Background(), "abc123")
+		require.NoError(t, err)
+		assert.Equal(t, expected, counted)
+	}
+
+	pubdash, err := publicdashboardStore.Find(context.Background(), "abc123")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), pubdash.ViewCount)
+	assert.True(t, pubdash.ViewLimitReached())
+}
+
+func TestIntegrationSuspend(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	sqlStore, cfg := db.InitTestDBwithCfg(t)
+	quotaService := quotatest.New(false, nil)
+	dashboardStore, err := dashboardsDB.ProvideDashboardStore(sqlStore, cfg, featuremgmt.WithFeatures(), tagimpl.ProvideService(sqlStore), quotaService)
+	require.NoError(t, err)
+	publicdashboardStore := ProvideStore(sqlStore, cfg, featuremgmt.WithFeatures())
+	savedDashboard := insertTestDashboard(t, dashboardStore, "testDashie", 1, "", true)
+
+	_, err = publicdashboardStore.Create(context.Background(), SavePublicDashboardCommand{
+		PublicDashboard: PublicDashboard{
+			IsEnabled:    true,
+			Uid:          "abc123",
+			DashboardUid: savedDashboard.UID,
+			OrgId:        savedDashboard.OrgID,
+			CreatedAt:    time.Now(),
+			CreatedBy:    7,
+			AccessToken:  "accessToken",
+		},
+	})
+	require.NoError(t, err)
+
+	until := time.Now().Add(time.Hour)
+	affectedRows, err := publicdashboardStore.Suspend(context.Background(), "abc123", &until)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), affectedRows)
+
+	pubdash, err := publicdashboardStore.Find(context.Background(), "abc123")
+	require.NoError(t, err)
+	assert.True(t, pubdash.IsSuspended(time.Now()))
+	exists, err := publicdashboardStore.ExistsEnabledByAccessToken(context.Background(), "accessToken")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	affectedRows, err = publicdashboardStore.Suspend(context.Background(), "abc123", nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), affectedRows)
+
+	pubdash, err = publicdashboardStore.Find(context.Background(), "abc123")
+	require.NoError(t, err)
+	assert.Nil(t, pubdash.SuspendedUntil)
+	exists, err = publicdashboardStore.ExistsEnabledByAccessToken(context.Background(), "accessToken")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	affectedRows, err = publicdashboardStore.Suspend(context.Background(), "unknown", &until)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), affectedRows)
+}
+
 func TestIntegrationGetOrgIdByAccessToken(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping integration test")
@@ -35,15 +35,42 @@ func ProvideService(
 }
 
 func (s *Service) registerMetrics(prom prometheus.Registerer) error {
-	err := prom.Register(s.Metrics.PublicDashboardsAmount)
-	var alreadyRegisterErr prometheus.AlreadyRegisteredError
-	if errors.As(err, &alreadyRegisterErr) {
-		if alreadyRegisterErr.ExistingCollector == alreadyRegisterErr.NewCollector {
-			err = nil
+	collectors := []prometheus.Collector{
+		s.Metrics.PublicDashboardsAmount,
+		s.Metrics.PublicDashboardRequests,
+		s.Metrics.PublicDashboardRateLimitedRequests,
+	}
+
+	for _, collector := range collectors {
+		err := prom.Register(collector)
+		var alreadyRegisterErr prometheus.AlreadyRegisteredError
+		if errors.As(err, &alreadyRegisterErr) {
+			if alreadyRegisterErr.ExistingCollector == alreadyRegisterErr.NewCollector {
+				err = nil
+			}
+		}
+		if err != nil {
+			return err
 		}
 	}
 
-	return err
+	return nil
+}
+
+// RecordRequest counts a query or annotation request of a public dashboard
+func (s *Service) RecordRequest(publicDashboardUid string, endpoint string) {
+	s.Metrics.PublicDashboardRequests.WithLabelValues(publicDashboardUid, endpoint).Inc()
+}
+
+// RecordRateLimitedRequest counts a query or annotation request of a public dashboard rejected by a rate limit
+func (s *Service) RecordRateLimitedRequest(publicDashboardUid string, endpoint string, limit string) {
+	s.Metrics.PublicDashboardRateLimitedRequests.WithLabelValues(publicDashboardUid, endpoint, limit).Inc()
+}
+
+// DeletePublicDashboard removes the metrics of a deleted public dashboard
+func (s *Service) DeletePublicDashboard(publicDashboardUid string) {
+	s.Metrics.PublicDashboardRequests.DeletePartialMatch(prometheus.Labels{"public_dashboard_uid": publicDashboardUid})
+	s.Metrics.PublicDashboardRateLimitedRequests.DeletePartialMatch(prometheus.Labels{"public_dashboard_uid": publicDashboardUid})
 }
 
 func (s *Service) Run(ctx context.Context) error {
@@ -10,6 +10,10 @@ const (
 
 type Metrics struct {
 	PublicDashboardsAmount *prometheus.GaugeVec
+	// requests of the query and annotation endpoints of each public dashboard
+	PublicDashboardRequests *prometheus.CounterVec
+	// requests of the query and annotation endpoints of each public dashboard rejected by the rate limits
+	PublicDashboardRateLimitedRequests *prometheus.CounterVec
 }
 
 func newMetrics() *Metrics {
@@ -19,5 +23,15 @@ func newMetrics() *Metrics {
 			Name:      "public_dashboards_amount",
 			Help:      "Total amount of public dashboards",
 		}, []string{"is_enabled", "share_type"}),
+		PublicDashboardRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "public_dashboard_requests_total",
+			Help:      "Total number of query and annotation requests of each public dashboard",
+		}, []string{"public_dashboard_uid", "endpoint"}),
+		PublicDashboardRateLimitedRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "public_dashboard_rate_limited_requests_total",
+			Help:      "Total number of query and annotation requests of each public dashboard rejected by the rate limits",
+		}, []string{"public_dashboard_uid", "endpoint", "limit"}),
 	}
 }
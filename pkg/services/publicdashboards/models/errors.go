@@ -23,6 +23,13 @@ var (
 	ErrDashboardIsPublic                   = errutil.BadRequest("publicdashboards.dashboardIsPublic", errutil.WithPublicMessage("Dashboard is already public"))
 	ErrPublicDashboardUidExists            = errutil.BadRequest("publicdashboards.uidExists", errutil.WithPublicMessage("Public Dashboard Uid already exists"))
 	ErrPublicDashboardAccessTokenExists    = errutil.BadRequest("publicdashboards.accessTokenExists", errutil.WithPublicMessage("Public Dashboard Access Token already exists"))
+	ErrInvalidVariableAllowlist            = errutil.BadRequest("publicdashboards.invalidVariableAllowlist", errutil.WithPublicMessage("Invalid template variable allowlist"))
+	ErrInvalidVariable                     = errutil.BadRequest("publicdashboards.invalidVariable", errutil.WithPublicMessage("Template variable value not allowed"))
+	ErrInvalidMaxViews                     = errutil.BadRequest("publicdashboards.invalidMaxViews", errutil.WithPublicMessage("maxViews should not be negative"))
+	ErrInvalidSuspendDuration              = errutil.BadRequest("publicdashboards.invalidSuspendDuration", errutil.WithPublicMessage("Invalid suspension duration"))
 
-	ErrPublicDashboardNotEnabled = errutil.Forbidden("publicdashboards.notEnabled", errutil.WithPublicMessage("Public dashboard paused"))
+	ErrPublicDashboardNotEnabled       = errutil.Forbidden("publicdashboards.notEnabled", errutil.WithPublicMessage("Public dashboard paused"))
+	ErrPublicDashboardExpired          = errutil.Forbidden("publicdashboards.expired", errutil.WithPublicMessage("Public dashboard link expired"))
+	ErrPublicDashboardViewLimitReached = errutil.Forbidden("publicdashboards.viewLimitReached", errutil.WithPublicMessage("Public dashboard link reached its maximum number of views"))
+	ErrPublicDashboardSuspended        = errutil.Forbidden("publicdashboards.suspended", errutil.WithPublicMessage("Public dashboard link suspended"))
 )
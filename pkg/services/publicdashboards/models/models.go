@@ -54,6 +54,15 @@ type PublicDashboard struct {
 	AnnotationsEnabled   bool          `json:"annotationsEnabled" xorm:"annotations_enabled"`
 	Share                ShareType     `json:"share" xorm:"share"`
 	Recipients           []EmailDTO    `json:"recipients,omitempty" xorm:"-"`
+	// template variables that viewers may change, and the values they may change them to
+	VariableAllowlist VariableAllowlist `json:"variableAllowlist" xorm:"variable_allowlist"`
+	// the access token is rejected after this time, or never expires when it is nil
+	ExpiresAt *time.Time `json:"expiresAt,omitempty" xorm:"expires_at"`
+	// the access token is rejected after the dashboard has been viewed this many times, or is not limited when it is 0
+	MaxViews  int64 `json:"maxViews" xorm:"max_views"`
+	ViewCount int64 `json:"viewCount" xorm:"view_count"`
+	// the access token is rejected until this time, when an admin suspended it
+	SuspendedUntil *time.Time `json:"suspendedUntil,omitempty" xorm:"suspended_until"`
 }
 
 type PublicDashboardDTO struct {
@@ -63,6 +72,10 @@ type PublicDashboardDTO struct {
 	IsEnabled            *bool     `json:"isEnabled"`
 	AnnotationsEnabled   *bool     `json:"annotationsEnabled"`
 	Share                ShareType `json:"share"`
+	// ExpiresAt is in epoch milliseconds, and 0 removes the expiration
+	ExpiresAt         *int64             `json:"expiresAt"`
+	MaxViews          *int64             `json:"maxViews"`
+	VariableAllowlist *VariableAllowlist `json:"variableAllowlist"`
 }
 
 type EmailDTO struct {
@@ -96,6 +109,21 @@ func (pd PublicDashboard) TableName() string {
 	return "dashboard_public"
 }
 
+// IsExpired returns true when the access token expired at the time
+func (pd PublicDashboard) IsExpired(now time.Time) bool {
+	return pd.ExpiresAt != nil && !now.Before(*pd.ExpiresAt)
+}
+
+// IsSuspended returns true when the access token is suspended at the time
+func (pd PublicDashboard) IsSuspended(now time.Time) bool {
+	return pd.SuspendedUntil != nil && now.Before(*pd.SuspendedUntil)
+}
+
+// ViewLimitReached returns true when the dashboard has been viewed the maximum number of times
+func (pd PublicDashboard) ViewLimitReached() bool {
+	return pd.MaxViews > 0 && pd.ViewCount >= pd.MaxViews
+}
+
 type PublicDashboardListQuery struct {
 	OrgID  int64
 	Query  string
@@ -134,6 +162,50 @@ func (ts *TimeSettings) ToDB() ([]byte, error) {
 	return json.Marshal(ts)
 }
 
+// AllowedVariable is a template variable that viewers may change to one of the values
+type AllowedVariable struct {
+	Name   string   `json:"name"`
+	Values []string `json:"values"`
+}
+
+type VariableAllowlist []AllowedVariable
+
+func (v *VariableAllowlist) FromDB(data []byte) error {
+	if len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, v)
+}
+
+func (v *VariableAllowlist) ToDB() ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Find returns the allowed variable with the name
+func (v VariableAllowlist) Find(name string) (AllowedVariable, bool) {
+	for _, variable := range v {
+		if variable.Name == name {
+			return variable, true
+		}
+	}
+	return AllowedVariable{}, false
+}
+
+// Allows returns true when the value is allowed
+func (v AllowedVariable) Allows(value string) bool {
+	for _, allowed := range v.Values {
+		if allowed == value {
+			return true
+		}
+	}
+	return false
+}
+
+// SuspendPublicDashboardDTO is the duration to suspend the access token of a public dashboard for, such as 1h
+type SuspendPublicDashboardDTO struct {
+	Duration string `json:"duration"`
+}
+
 // DTO for transforming user input in the api
 type SavePublicDashboardDTO struct {
 	Uid             string
@@ -154,6 +226,8 @@ type PublicDashboardQueryDTO struct {
 	MaxDataPoints   int64
 	QueryCachingTTL int64
 	TimeRange       TimeRangeDTO
+	// values of the template variables selected by the viewer, by name
+	Variables map[string][]string
 }
 
 type AnnotationsQueryDTO struct {
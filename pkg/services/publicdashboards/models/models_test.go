@@ -2,10 +2,40 @@ package models
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestPublicDashboardTableName(t *testing.T) {
 	assert.Equal(t, "dashboard_public", PublicDashboard{}.TableName())
 }
+
+func TestPublicDashboardIsExpired(t *testing.T) {
+	now := time.Now()
+	expiresAt := now.Add(time.Hour)
+
+	assert.False(t, PublicDashboard{}.IsExpired(now))
+	assert.False(t, PublicDashboard{ExpiresAt: &expiresAt}.IsExpired(now))
+	assert.True(t, PublicDashboard{ExpiresAt: &expiresAt}.IsExpired(expiresAt))
+}
+
+func TestPublicDashboardViewLimitReached(t *testing.T) {
+	assert.False(t, PublicDashboard{ViewCount: 10}.ViewLimitReached())
+	assert.False(t, PublicDashboard{MaxViews: 10, ViewCount: 9}.ViewLimitReached())
+	assert.True(t, PublicDashboard{MaxViews: 10, ViewCount: 10}.ViewLimitReached())
+}
+
+func TestVariableAllowlist(t *testing.T) {
+	var allowlist VariableAllowlist
+	require.NoError(t, allowlist.FromDB([]byte(`[{"name": "env", "values": ["dev", "prod"]}]`)))
+
+	env, ok := allowlist.Find("env")
+	require.True(t, ok)
+	assert.True(t, env.Allows("prod"))
+	assert.False(t, env.Allows("staging"))
+
+	_, ok = allowlist.Find("job")
+	assert.False(t, ok)
+}
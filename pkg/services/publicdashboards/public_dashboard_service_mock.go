@@ -15,6 +15,8 @@ import (
 
 	models "github.com/grafana/grafana/pkg/services/publicdashboards/models"
 
+	time "time"
+
 	user "github.com/grafana/grafana/pkg/services/user"
 )
 
@@ -425,25 +427,25 @@ func (_m *FakePublicDashboardService) GetPublicDashboardForView(ctx context.Cont
 	return r0, r1
 }
 
-// GetQueryDataResponse provides a mock function with given fields: ctx, skipDSCache, reqDTO, panelId, accessToken
-func (_m *FakePublicDashboardService) GetQueryDataResponse(ctx context.Context, skipDSCache bool, reqDTO models.PublicDashboardQueryDTO, panelId int64, accessToken string) (*backend.QueryDataResponse, error) {
-	ret := _m.Called(ctx, skipDSCache, reqDTO, panelId, accessToken)
+// GetQueryDataResponse provides a mock function with given fields: ctx, reqDTO, panelId, accessToken
+func (_m *FakePublicDashboardService) GetQueryDataResponse(ctx context.Context, reqDTO models.PublicDashboardQueryDTO, panelId int64, accessToken string) (*backend.QueryDataResponse, error) {
+	ret := _m.Called(ctx, reqDTO, panelId, accessToken)
 
 	var r0 *backend.QueryDataResponse
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, bool, models.PublicDashboardQueryDTO, int64, string) (*backend.QueryDataResponse, error)); ok {
-		return rf(ctx, skipDSCache, reqDTO, panelId, accessToken)
+	if rf, ok := ret.Get(0).(func(context.Context, models.PublicDashboardQueryDTO, int64, string) (*backend.QueryDataResponse, error)); ok {
+		return rf(ctx, reqDTO, panelId, accessToken)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, bool, models.PublicDashboardQueryDTO, int64, string) *backend.QueryDataResponse); ok {
-		r0 = rf(ctx, skipDSCache, reqDTO, panelId, accessToken)
+	if rf, ok := ret.Get(0).(func(context.Context, models.PublicDashboardQueryDTO, int64, string) *backend.QueryDataResponse); ok {
+		r0 = rf(ctx, reqDTO, panelId, accessToken)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*backend.QueryDataResponse)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, bool, models.PublicDashboardQueryDTO, int64, string) error); ok {
-		r1 = rf(ctx, skipDSCache, reqDTO, panelId, accessToken)
+	if rf, ok := ret.Get(1).(func(context.Context, models.PublicDashboardQueryDTO, int64, string) error); ok {
+		r1 = rf(ctx, reqDTO, panelId, accessToken)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -499,6 +501,20 @@ func (_m *FakePublicDashboardService) NewPublicDashboardUid(ctx context.Context)
 	return r0, r1
 }
 
+// Suspend provides a mock function with given fields: ctx, uid, dashboardUid, until
+func (_m *FakePublicDashboardService) Suspend(ctx context.Context, uid string, dashboardUid string, until *time.Time) error {
+	ret := _m.Called(ctx, uid, dashboardUid, until)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, *time.Time) error); ok {
+		r0 = rf(ctx, uid, dashboardUid, until)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Update provides a mock function with given fields: ctx, u, dto
 func (_m *FakePublicDashboardService) Update(ctx context.Context, u *user.SignedInUser, dto *models.SavePublicDashboardDTO) (*models.PublicDashboard, error) {
 	ret := _m.Called(ctx, u, dto)
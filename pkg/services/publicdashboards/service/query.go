@@ -2,10 +2,16 @@ package service
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/bits"
 	"strconv"
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/expr"
@@ -123,8 +129,9 @@ func (pd *PublicDashboardServiceImpl) GetMetricRequest(ctx context.Context, dash
 	return metricReqDTO, nil
 }
 
-// GetQueryDataResponse returns a query data response for the given panel and query
-func (pd *PublicDashboardServiceImpl) GetQueryDataResponse(ctx context.Context, skipDSCache bool, queryDto models.PublicDashboardQueryDTO, panelId int64, accessToken string) (*backend.QueryDataResponse, error) {
+// GetQueryDataResponse returns a query data response for the given panel and query. The data source cache can't be
+// skipped by the viewers of public dashboards, since it protects the data sources from them.
+func (pd *PublicDashboardServiceImpl) GetQueryDataResponse(ctx context.Context, queryDto models.PublicDashboardQueryDTO, panelId int64, accessToken string) (*backend.QueryDataResponse, error) {
 	publicDashboard, dashboard, err := pd.FindEnabledPublicDashboardAndDashboardByAccessToken(ctx, accessToken)
 	if err != nil {
 		return nil, err
@@ -139,8 +146,16 @@ func (pd *PublicDashboardServiceImpl) GetQueryDataResponse(ctx context.Context,
 		return nil, models.ErrPanelQueriesNotFound.Errorf("GetQueryDataResponse: failed to extract queries from panel")
 	}
 
+	// responses are shared by the viewers of a public dashboard for the current time bucket
+	cacheKey, cacheable := pd.responseCacheKey(metricReq, panelId, accessToken)
+	if cacheable {
+		if cached, ok := pd.responseCache.Get(cacheKey); ok {
+			return cached.(*backend.QueryDataResponse), nil
+		}
+	}
+
 	anonymousUser := buildAnonymousUser(ctx, dashboard, pd.features)
-	res, err := pd.QueryDataService.QueryData(ctx, anonymousUser, skipDSCache, metricReq)
+	res, err := pd.QueryDataService.QueryData(ctx, anonymousUser, false, metricReq)
 
 	reqDatasources := metricReq.GetUniqueDatasourceTypes()
 	if err != nil {
@@ -151,9 +166,60 @@ func (pd *PublicDashboardServiceImpl) GetQueryDataResponse(ctx context.Context,
 
 	sanitizeMetadataFromQueryData(res)
 
+	// responses are not cached when the cache is full, until the expired ones are removed
+	if cacheable && pd.responseCache.ItemCount() < maxCachedResponses {
+		pd.responseCache.SetDefault(cacheKey, res)
+	}
+
 	return res, nil
 }
 
+// responseCacheKey returns the key of the cached response of a query of a panel in the current time bucket, and false
+// when the response should not be cached. The key is built from the metric request, whose template variables are
+// interpolated and whose resolution is rounded by buildMetricRequest, and with the time range in buckets of the minimum
+// refresh interval, so that viewers can't change them to skip the cache.
+func (pd *PublicDashboardServiceImpl) responseCacheKey(metricReq dtos.MetricRequest, panelId int64, accessToken string) (string, bool) {
+	if pd.responseCache == nil {
+		return "", false
+	}
+
+	queries := make([]*simplejson.Json, 0, len(metricReq.Queries))
+	for _, query := range metricReq.Queries {
+		// the queries are copied, since they are sent to the data sources
+		raw, err := query.Encode()
+		if err != nil {
+			return "", false
+		}
+		q, err := simplejson.NewJson(raw)
+		if err != nil {
+			return "", false
+		}
+		q.Del("queryCachingTTL")
+		queries = append(queries, q)
+	}
+
+	interval := pd.minRefreshInterval.Milliseconds()
+	timeRange := legacydata.NewDataTimeRange(metricReq.From, metricReq.To)
+	key, err := json.Marshal(struct {
+		PanelID int64
+		From    int64
+		To      int64
+		Queries []*simplejson.Json
+	}{
+		PanelID: panelId,
+		From:    timeRange.GetFromAsMsEpoch() / interval,
+		To:      timeRange.GetToAsMsEpoch() / interval,
+		Queries: queries,
+	})
+	if err != nil {
+		return "", false
+	}
+	hash := sha256.Sum256(key)
+	bucket := time.Now().UnixMilli() / interval
+
+	return fmt.Sprintf("%s:%x:%d", accessToken, hash, bucket), true
+}
+
 // buildMetricRequest merges public dashboard parameters with dashboard and returns a metrics request to be sent to query backend
 func (pd *PublicDashboardServiceImpl) buildMetricRequest(dashboard *dashboards.Dashboard, publicDashboard *models.PublicDashboard, panelId int64, reqDTO models.PublicDashboardQueryDTO) (dtos.MetricRequest, error) {
 	// group queries by panel
@@ -163,8 +229,17 @@ func (pd *PublicDashboardServiceImpl) buildMetricRequest(dashboard *dashboards.D
 		return dtos.MetricRequest{}, models.ErrPanelNotFound.Errorf("buildMetricRequest: public dashboard panel not found")
 	}
 
+	// replace the template variables with the dashboard values, or the allowed values selected by the viewer
+	interpolateVariables(queries, getVariableValues(dashboard.Data, reqDTO.Variables))
+
 	ts := buildTimeSettings(dashboard, reqDTO, publicDashboard)
 
+	// the cached responses are shared by the viewers, so their resolutions are rounded to a few buckets
+	if pd.responseCache != nil {
+		reqDTO.IntervalMs = roundUpToPowerOfTwo(reqDTO.IntervalMs)
+		reqDTO.MaxDataPoints = roundUpToPowerOfTwo(reqDTO.MaxDataPoints)
+	}
+
 	// determine safe resolution to query data at
 	safeInterval, safeResolution := pd.getSafeIntervalAndMaxDataPoints(reqDTO, ts)
 	for i := range queries {
@@ -180,6 +255,14 @@ func (pd *PublicDashboardServiceImpl) buildMetricRequest(dashboard *dashboards.D
 	}, nil
 }
 
+// roundUpToPowerOfTwo returns the smallest power of two greater than or equal to the positive value v, or v itself
+func roundUpToPowerOfTwo(v int64) int64 {
+	if v <= 0 {
+		return v
+	}
+	return 1 << bits.Len64(uint64(v-1))
+}
+
 // buildAnonymousUser creates a user with permissions to read from all datasources used in the dashboard
 func buildAnonymousUser(ctx context.Context, dashboard *dashboards.Dashboard, features featuremgmt.FeatureToggles) *user.SignedInUser {
 	datasourceUids := getUniqueDashboardDatasourceUids(dashboard.Data)
@@ -347,6 +430,29 @@ func sanitizeMetadataFromQueryData(res *backend.QueryDataResponse) {
 	}
 }
 
+// enforceMinRefreshInterval raises the refresh interval of the dashboard to the minimum refresh interval
+func enforceMinRefreshInterval(data *simplejson.Json, minRefreshInterval time.Duration) {
+	refresh := data.Get("refresh").MustString()
+	if minRefreshInterval <= 0 || refresh == "" {
+		return
+	}
+
+	interval, err := gtime.ParseDuration(refresh)
+	if err == nil && interval >= minRefreshInterval {
+		return
+	}
+
+	switch {
+	case minRefreshInterval%time.Hour == 0:
+		refresh = fmt.Sprintf("%dh", minRefreshInterval/time.Hour)
+	case minRefreshInterval%time.Minute == 0:
+		refresh = fmt.Sprintf("%dm", minRefreshInterval/time.Minute)
+	default:
+		refresh = fmt.Sprintf("%ds", int64(math.Ceil(minRefreshInterval.Seconds())))
+	}
+	data.Set("refresh", refresh)
+}
+
 // sanitizeData removes the query expressions from the dashboard data
 func sanitizeData(data *simplejson.Json) {
 	for _, panelObj := range data.Get("panels").MustArray() {
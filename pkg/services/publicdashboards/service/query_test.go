@@ -12,6 +12,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/localcache"
 	dashboard2 "github.com/grafana/grafana/pkg/kinds/dashboard"
 	"github.com/grafana/grafana/pkg/services/annotations"
 	"github.com/grafana/grafana/pkg/services/dashboards"
@@ -724,11 +725,108 @@ func TestGetQueryDataResponse(t *testing.T) {
 		pubdashDto, err := service.Create(context.Background(), SignedInUser, dto)
 		require.NoError(t, err)
 
-		resp, _ := service.GetQueryDataResponse(context.Background(), true, publicDashboardQueryDTO, 1, pubdashDto.AccessToken)
+		resp, _ := service.GetQueryDataResponse(context.Background(), publicDashboardQueryDTO, 1, pubdashDto.AccessToken)
 		require.NotNil(t, resp)
 	})
 }
 
+func TestGetQueryDataResponseWithMinRefreshInterval(t *testing.T) {
+	fakeDashboardService := &dashboards.FakeDashboardService{}
+	service, sqlStore, _ := newPublicDashboardServiceImpl(t, nil, fakeDashboardService, nil)
+	service.minRefreshInterval = time.Hour
+	service.responseCache = localcache.New(service.minRefreshInterval, 2*service.minRefreshInterval)
+	fakeQueryService := &query.FakeQueryService{}
+	fakeQueryService.On("QueryData", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(&backend.QueryDataResponse{}, nil)
+	service.QueryDataService = fakeQueryService
+
+	dashboardStore, err := dashboardsDB.ProvideDashboardStore(sqlStore, service.cfg, featuremgmt.WithFeatures(), tagimpl.ProvideService(sqlStore), quotatest.New(false, nil))
+	require.NoError(t, err)
+
+	customPanels := []interface{}{
+		map[string]interface{}{
+			"id":         1,
+			"datasource": map[string]interface{}{"uid": "ds1"},
+			"targets":    []interface{}{map[string]interface{}{"datasource": map[string]interface{}{"uid": "ds1"}, "refId": "A"}},
+		}}
+	dashboard := insertTestDashboard(t, dashboardStore, "testDashWithMinRefreshInterval", 1, 0, "", true, []map[string]interface{}{}, customPanels)
+	fakeDashboardService.On("GetDashboard", mock.Anything, mock.Anything, mock.Anything).Return(dashboard, nil)
+
+	isEnabled := true
+	pubdashDto, err := service.Create(context.Background(), SignedInUser, &SavePublicDashboardDTO{
+		DashboardUid:    dashboard.UID,
+		UserId:          7,
+		OrgID:           dashboard.OrgID,
+		PublicDashboard: &PublicDashboardDTO{IsEnabled: &isEnabled},
+	})
+	require.NoError(t, err)
+
+	queryDto := PublicDashboardQueryDTO{IntervalMs: int64(1), MaxDataPoints: int64(1)}
+
+	t.Run("returns the cached response of the same query", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			resp, err := service.GetQueryDataResponse(context.Background(), queryDto, 1, pubdashDto.AccessToken)
+			require.NoError(t, err)
+			require.NotNil(t, resp)
+		}
+		fakeQueryService.AssertNumberOfCalls(t, "QueryData", 1)
+
+	})
+
+	t.Run("does not let the viewers skip the cache with intervals shorter than the safe interval", func(t *testing.T) {
+		_, err := service.GetQueryDataResponse(context.Background(), PublicDashboardQueryDTO{IntervalMs: int64(2), MaxDataPoints: int64(5)}, 1, pubdashDto.AccessToken)
+		require.NoError(t, err)
+		fakeQueryService.AssertNumberOfCalls(t, "QueryData", 1)
+	})
+
+	t.Run("caches the responses of the rounded resolutions separately", func(t *testing.T) {
+		_, err := service.GetQueryDataResponse(context.Background(), PublicDashboardQueryDTO{IntervalMs: int64(24 * time.Hour / time.Millisecond), MaxDataPoints: int64(100)}, 1, pubdashDto.AccessToken)
+		require.NoError(t, err)
+		fakeQueryService.AssertNumberOfCalls(t, "QueryData", 2)
+
+		_, err = service.GetQueryDataResponse(context.Background(), PublicDashboardQueryDTO{IntervalMs: int64(23 * time.Hour / time.Millisecond), MaxDataPoints: int64(120)}, 1, pubdashDto.AccessToken)
+		require.NoError(t, err)
+		fakeQueryService.AssertNumberOfCalls(t, "QueryData", 2)
+
+		_, err = service.GetQueryDataResponse(context.Background(), PublicDashboardQueryDTO{IntervalMs: int64(24 * time.Hour / time.Millisecond), MaxDataPoints: int64(4000)}, 1, pubdashDto.AccessToken)
+		require.NoError(t, err)
+		fakeQueryService.AssertNumberOfCalls(t, "QueryData", 3)
+	})
+}
+
+func TestRoundUpToPowerOfTwo(t *testing.T) {
+	assert.Equal(t, int64(-1), roundUpToPowerOfTwo(-1))
+	assert.Equal(t, int64(0), roundUpToPowerOfTwo(0))
+	assert.Equal(t, int64(1), roundUpToPowerOfTwo(1))
+	assert.Equal(t, int64(128), roundUpToPowerOfTwo(100))
+	assert.Equal(t, int64(128), roundUpToPowerOfTwo(128))
+	assert.Equal(t, int64(256), roundUpToPowerOfTwo(129))
+}
+
+func TestEnforceMinRefreshInterval(t *testing.T) {
+	testCases := []struct {
+		name               string
+		refresh            string
+		minRefreshInterval time.Duration
+		expected           string
+	}{
+		{name: "no minimum refresh interval", refresh: "5s", minRefreshInterval: 0, expected: "5s"},
+		{name: "no refresh", refresh: "", minRefreshInterval: time.Minute, expected: ""},
+		{name: "refresh longer than the minimum", refresh: "5m", minRefreshInterval: time.Minute, expected: "5m"},
+		{name: "refresh shorter than the minimum in minutes", refresh: "5s", minRefreshInterval: time.Minute, expected: "1m"},
+		{name: "refresh shorter than the minimum in hours", refresh: "30m", minRefreshInterval: 2 * time.Hour, expected: "2h"},
+		{name: "refresh shorter than the minimum in seconds", refresh: "5s", minRefreshInterval: 90 * time.Second, expected: "90s"},
+		{name: "invalid refresh", refresh: "soon", minRefreshInterval: time.Minute, expected: "1m"},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			data := simplejson.NewFromAny(map[string]any{"refresh": test.refresh})
+			enforceMinRefreshInterval(data, test.minRefreshInterval)
+			assert.Equal(t, test.expected, data.Get("refresh").MustString())
+		})
+	}
+}
+
 func TestFindAnnotations(t *testing.T) {
 	color := "red"
 	name := "annoName"
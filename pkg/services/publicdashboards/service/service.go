@@ -10,6 +10,7 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 
 	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/infra/localcache"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/metrics"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
@@ -42,10 +43,15 @@ type PublicDashboardServiceImpl struct {
 	serviceWrapper     publicdashboards.ServiceWrapper
 	dashboardService   dashboards.DashboardService
 	license            licensing.Licensing
+	minRefreshInterval time.Duration
+	responseCache      *localcache.CacheService
 }
 
 var LogPrefix = "publicdashboards.service"
 
+// maxCachedResponses is the maximum number of query responses cached for the viewers of public dashboards
+const maxCachedResponses = 10000
+
 // Gives us compile time error if the service does not adhere to the contract of
 // the interface
 var _ publicdashboards.Service = (*PublicDashboardServiceImpl)(nil)
@@ -63,7 +69,7 @@ func ProvideService(
 	dashboardService dashboards.DashboardService,
 	license licensing.Licensing,
 ) *PublicDashboardServiceImpl {
-	return &PublicDashboardServiceImpl{
+	pd := &PublicDashboardServiceImpl{
 		log:                log.New(LogPrefix),
 		cfg:                cfg,
 		features:           features,
@@ -75,7 +81,15 @@ func ProvideService(
 		serviceWrapper:     serviceWrapper,
 		dashboardService:   dashboardService,
 		license:            license,
+		minRefreshInterval: cfg.PublicDashboardsMinRefreshInterval,
+	}
+
+	// query responses are cached in time buckets of the minimum refresh interval
+	if pd.minRefreshInterval > 0 {
+		pd.responseCache = localcache.New(pd.minRefreshInterval, 2*pd.minRefreshInterval)
 	}
+
+	return pd
 }
 
 func (pd *PublicDashboardServiceImpl) GetPublicDashboardForView(ctx context.Context, accessToken string) (*dtos.DashboardFullWithMeta, error) {
@@ -84,6 +98,15 @@ func (pd *PublicDashboardServiceImpl) GetPublicDashboardForView(ctx context.Cont
 		return nil, err
 	}
 
+	// count the view, unless the public dashboard reached its maximum number of views
+	counted, err := pd.store.IncrementViewCount(ctx, pubdash.Uid)
+	if err != nil {
+		return nil, ErrInternalServerError.Errorf("GetPublicDashboardForView: failed to count the view of public dashboard %s: %w", pubdash.Uid, err)
+	}
+	if !counted {
+		return nil, ErrPublicDashboardViewLimitReached.Errorf("GetPublicDashboardForView: Public dashboard reached its maximum number of views accessToken: %s", accessToken)
+	}
+
 	metrics.MFolderIDsServiceCount.WithLabelValues(metrics.PublicDashboards).Inc()
 	meta := dtos.DashboardMeta{
 		Slug:                   dash.Slug,
@@ -102,8 +125,10 @@ func (pd *PublicDashboardServiceImpl) GetPublicDashboardForView(ctx context.Cont
 		PublicDashboardEnabled: pubdash.IsEnabled,
 	}
 	dash.Data.Get("timepicker").Set("hidden", !pubdash.TimeSelectionEnabled)
+	enforceMinRefreshInterval(dash.Data, pd.minRefreshInterval)
 
 	sanitizeData(dash.Data)
+	sanitizeVariables(dash.Data, pubdash.VariableAllowlist)
 
 	return &dtos.DashboardFullWithMeta{Meta: meta, Dashboard: dash.Data}, nil
 }
@@ -166,6 +191,14 @@ func (pd *PublicDashboardServiceImpl) FindEnabledPublicDashboardAndDashboardByAc
 		return nil, nil, ErrPublicDashboardNotFound.Errorf("FindEnabledPublicDashboardAndDashboardByAccessToken: Dashboard not found accessToken: %s", accessToken)
 	}
 
+	if pubdash.IsExpired(time.Now()) {
+		return nil, nil, ErrPublicDashboardExpired.Errorf("FindEnabledPublicDashboardAndDashboardByAccessToken: Public dashboard expired accessToken: %s", accessToken)
+	}
+
+	if pubdash.IsSuspended(time.Now()) {
+		return nil, nil, ErrPublicDashboardSuspended.Errorf("FindEnabledPublicDashboardAndDashboardByAccessToken: Public dashboard suspended accessToken: %s", accessToken)
+	}
+
 	return pubdash, dash, err
 }
 
@@ -197,11 +230,19 @@ func (pd *PublicDashboardServiceImpl) Create(ctx context.Context, u *user.Signed
 	}
 
 	// ensure dashboard exists
-	_, err = pd.FindDashboard(ctx, u.OrgID, dto.DashboardUid)
+	dash, err := pd.FindDashboard(ctx, u.OrgID, dto.DashboardUid)
 	if err != nil {
 		return nil, err
 	}
 
+	// validate the allowed variables are variables of the dashboard
+	if dto.PublicDashboard.VariableAllowlist != nil {
+		err = validation.ValidateVariableAllowlist(*dto.PublicDashboard.VariableAllowlist, getDashboardVariableNames(dash.Data))
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// validate the dashboard does not already have a public dashboard
 	existingPubdash, err := pd.FindByDashboardUid(ctx, u.OrgID, dto.DashboardUid)
 	if err != nil && !errors.Is(err, ErrPublicDashboardNotFound) {
@@ -254,11 +295,19 @@ func (pd *PublicDashboardServiceImpl) Update(ctx context.Context, u *user.Signed
 	}
 
 	// validate dashboard exists
-	_, err = pd.FindDashboard(ctx, u.OrgID, dto.DashboardUid)
+	dash, err := pd.FindDashboard(ctx, u.OrgID, dto.DashboardUid)
 	if err != nil {
 		return nil, err
 	}
 
+	// validate the allowed variables are variables of the dashboard
+	if dto.PublicDashboard.VariableAllowlist != nil {
+		err = validation.ValidateVariableAllowlist(*dto.PublicDashboard.VariableAllowlist, getDashboardVariableNames(dash.Data))
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// get existing public dashboard if exists
 	existingPubdash, err := pd.store.Find(ctx, dto.Uid)
 	if err != nil {
@@ -376,6 +425,43 @@ func (pd *PublicDashboardServiceImpl) Delete(ctx context.Context, uid string, da
 	return pd.serviceWrapper.Delete(ctx, uid)
 }
 
+// Suspend suspends the access token of a public dashboard until the time, or resumes it when the time is nil
+func (pd *PublicDashboardServiceImpl) Suspend(ctx context.Context, uid string, dashboardUid string, until *time.Time) error {
+	// get existing public dashboard if exists
+	existingPubdash, err := pd.store.Find(ctx, uid)
+	if err != nil {
+		return ErrInternalServerError.Errorf("Suspend: failed to find public dashboard by uid: %s: %w", uid, err)
+	}
+	if existingPubdash == nil {
+		return ErrPublicDashboardNotFound.Errorf("Suspend: public dashboard not found by uid: %s", uid)
+	}
+
+	// validate the public dashboard belongs to the dashboard
+	if existingPubdash.DashboardUid != dashboardUid {
+		return ErrInvalidUid.Errorf("Suspend: the public dashboard does not belong to the dashboard")
+	}
+
+	if until != nil && !until.After(time.Now()) {
+		return ErrInvalidSuspendDuration.Errorf("Suspend: the suspension should end in the future")
+	}
+
+	affectedRows, err := pd.store.Suspend(ctx, uid, until)
+	if err != nil {
+		return ErrInternalServerError.Errorf("Suspend: failed to suspend public dashboard: %w", err)
+	}
+	if affectedRows == 0 {
+		return ErrPublicDashboardNotFound.Errorf("Suspend: failed to suspend public dashboard not found by uid: %s", uid)
+	}
+
+	if until != nil {
+		pd.log.Info("Public dashboard suspended", "publicDashboardUid", uid, "dashboardUid", dashboardUid, "until", until)
+	} else {
+		pd.log.Info("Public dashboard resumed", "publicDashboardUid", uid, "dashboardUid", dashboardUid)
+	}
+
+	return nil
+}
+
 func (pd *PublicDashboardServiceImpl) DeleteByDashboard(ctx context.Context, dashboard *dashboards.Dashboard) error {
 	if dashboard.IsFolder {
 		// get all pubdashes for the folder
@@ -497,6 +583,8 @@ func (pd *PublicDashboardServiceImpl) newCreatePublicDashboard(ctx context.Conte
 	isEnabled := returnValueOrDefault(dto.PublicDashboard.IsEnabled, false)
 	annotationsEnabled := returnValueOrDefault(dto.PublicDashboard.AnnotationsEnabled, false)
 	timeSelectionEnabled := returnValueOrDefault(dto.PublicDashboard.TimeSelectionEnabled, false)
+	maxViews := returnValueOrDefault(dto.PublicDashboard.MaxViews, 0)
+	variableAllowlist := returnValueOrDefault(dto.PublicDashboard.VariableAllowlist, nil)
 
 	share := dto.PublicDashboard.Share
 	if dto.PublicDashboard.Share == "" {
@@ -514,6 +602,9 @@ func (pd *PublicDashboardServiceImpl) newCreatePublicDashboard(ctx context.Conte
 		TimeSelectionEnabled: timeSelectionEnabled,
 		TimeSettings:         &TimeSettings{},
 		Share:                share,
+		VariableAllowlist:    variableAllowlist,
+		ExpiresAt:            returnExpiresAtOrDefault(dto.PublicDashboard.ExpiresAt, nil),
+		MaxViews:             maxViews,
 		CreatedBy:            dto.UserId,
 		CreatedAt:            now,
 		UpdatedBy:            dto.UserId,
@@ -527,6 +618,8 @@ func newUpdatePublicDashboard(dto *SavePublicDashboardDTO, pd *PublicDashboard)
 	timeSelectionEnabled := returnValueOrDefault(pubdashDTO.TimeSelectionEnabled, pd.TimeSelectionEnabled)
 	isEnabled := returnValueOrDefault(pubdashDTO.IsEnabled, pd.IsEnabled)
 	annotationsEnabled := returnValueOrDefault(pubdashDTO.AnnotationsEnabled, pd.AnnotationsEnabled)
+	maxViews := returnValueOrDefault(pubdashDTO.MaxViews, pd.MaxViews)
+	variableAllowlist := returnValueOrDefault(pubdashDTO.VariableAllowlist, pd.VariableAllowlist)
 
 	share := pubdashDTO.Share
 	if pubdashDTO.Share == "" {
@@ -540,15 +633,31 @@ func newUpdatePublicDashboard(dto *SavePublicDashboardDTO, pd *PublicDashboard)
 		TimeSelectionEnabled: timeSelectionEnabled,
 		TimeSettings:         pd.TimeSettings,
 		Share:                share,
+		VariableAllowlist:    variableAllowlist,
+		ExpiresAt:            returnExpiresAtOrDefault(pubdashDTO.ExpiresAt, pd.ExpiresAt),
+		MaxViews:             maxViews,
 		UpdatedBy:            dto.UserId,
 		UpdatedAt:            time.Now(),
 	}
 }
 
-func returnValueOrDefault(value *bool, defaultValue bool) bool {
+func returnValueOrDefault[T any](value *T, defaultValue T) T {
 	if value != nil {
 		return *value
 	}
 
 	return defaultValue
 }
+
+// returnExpiresAtOrDefault converts an expiration in epoch milliseconds, where 0 removes the expiration
+func returnExpiresAtOrDefault(value *int64, defaultValue *time.Time) *time.Time {
+	if value == nil {
+		return defaultValue
+	}
+	if *value == 0 {
+		return nil
+	}
+
+	expiresAt := time.UnixMilli(*value).UTC()
+	return &expiresAt
+}
@@ -385,6 +385,7 @@ func TestGetPublicDashboardForView(t *testing.T) {
 		t.Run(test.Name, func(t *testing.T) {
 			fakeStore := &FakePublicDashboardStore{}
 			fakeStore.On("FindByAccessToken", mock.Anything, mock.Anything).Return(test.StoreResp.pd, test.StoreResp.err)
+			fakeStore.On("IncrementViewCount", mock.Anything, mock.Anything).Return(true, nil)
 			fakeDashboardService := &dashboards.FakeDashboardService{}
 			fakeDashboardService.On("GetDashboard", mock.Anything, mock.Anything, mock.Anything).Return(test.StoreResp.d, test.StoreResp.err)
 			service, _, _ := newPublicDashboardServiceImpl(t, fakeStore, fakeDashboardService, nil)
@@ -438,6 +439,91 @@ func TestGetPublicDashboardForView(t *testing.T) {
 	}
 }
 
+func TestGetPublicDashboardForViewWithLimits(t *testing.T) {
+	// #nosec G101 -- This is dummy/test token
+	accessToken := "c54b1c4dd2b143a1a7a43005264d256d"
+
+	setup := func(t *testing.T, pubdash *PublicDashboard, data string, counted bool) *PublicDashboardServiceImpl {
+		dashboardData, err := simplejson.NewJson([]byte(data))
+		require.NoError(t, err)
+
+		fakeStore := &FakePublicDashboardStore{}
+		fakeStore.On("FindByAccessToken", mock.Anything, accessToken).Return(pubdash, nil)
+		fakeStore.On("IncrementViewCount", mock.Anything, pubdash.Uid).Return(counted, nil)
+		fakeDashboardService := &dashboards.FakeDashboardService{}
+		fakeDashboardService.On("GetDashboard", mock.Anything, mock.Anything, mock.Anything).Return(&dashboards.Dashboard{UID: "mydashboard", Data: dashboardData}, nil)
+		service, _, _ := newPublicDashboardServiceImpl(t, fakeStore, fakeDashboardService, nil)
+		return service
+	}
+
+	t.Run("returns an error when the public dashboard expired", func(t *testing.T) {
+		expiresAt := time.Now().Add(-time.Minute)
+		service := setup(t, &PublicDashboard{Uid: "pubdash", AccessToken: accessToken, IsEnabled: true, ExpiresAt: &expiresAt}, `{"panels": []}`, true)
+
+		_, err := service.GetPublicDashboardForView(context.Background(), accessToken)
+		require.ErrorIs(t, err, ErrPublicDashboardExpired)
+	})
+
+	t.Run("returns an error when the public dashboard is suspended", func(t *testing.T) {
+		suspendedUntil := time.Now().Add(time.Minute)
+		service := setup(t, &PublicDashboard{Uid: "pubdash", AccessToken: accessToken, IsEnabled: true, SuspendedUntil: &suspendedUntil}, `{"panels": []}`, true)
+
+		_, err := service.GetPublicDashboardForView(context.Background(), accessToken)
+		require.ErrorIs(t, err, ErrPublicDashboardSuspended)
+	})
+
+	t.Run("raises the refresh interval to the minimum refresh interval", func(t *testing.T) {
+		service := setup(t, &PublicDashboard{Uid: "pubdash", AccessToken: accessToken, IsEnabled: true}, `{"panels": [], "refresh": "5s"}`, true)
+		service.minRefreshInterval = time.Minute
+
+		dashboardFullWithMeta, err := service.GetPublicDashboardForView(context.Background(), accessToken)
+		require.NoError(t, err)
+		assert.Equal(t, "1m", dashboardFullWithMeta.Dashboard.Get("refresh").MustString())
+	})
+
+	t.Run("returns an error when the public dashboard reached its maximum number of views", func(t *testing.T) {
+		service := setup(t, &PublicDashboard{Uid: "pubdash", AccessToken: accessToken, IsEnabled: true, MaxViews: 3, ViewCount: 3}, `{"panels": []}`, false)
+
+		_, err := service.GetPublicDashboardForView(context.Background(), accessToken)
+		require.ErrorIs(t, err, ErrPublicDashboardViewLimitReached)
+	})
+
+	t.Run("returns the template variables as custom variables", func(t *testing.T) {
+		pubdash := &PublicDashboard{
+			Uid:               "pubdash",
+			AccessToken:       accessToken,
+			IsEnabled:         true,
+			VariableAllowlist: VariableAllowlist{{Name: "env", Values: []string{"dev", "prod"}}},
+		}
+		service := setup(t, pubdash, `{
+			"panels": [],
+			"templating": {
+				"list": [
+					{"name": "env", "type": "query", "query": "label_values(env)", "datasource": {"uid": "prom"}, "current": {"text": "staging", "value": "staging"}},
+					{"name": "job", "type": "query", "query": "label_values(job)", "datasource": {"uid": "prom"}, "current": {"text": "api", "value": "api"}}
+				]
+			}
+		}`, true)
+
+		dashboardFullWithMeta, err := service.GetPublicDashboardForView(context.Background(), accessToken)
+		require.NoError(t, err)
+
+		variables := dashboardFullWithMeta.Dashboard.GetPath("templating", "list")
+		env, job := variables.GetIndex(0), variables.GetIndex(1)
+		assert.Equal(t, "custom", env.Get("type").MustString())
+		assert.Equal(t, "dev,prod", env.Get("query").MustString())
+		assert.Equal(t, "dev", env.GetPath("current", "value").MustString())
+		assert.Equal(t, 0, env.Get("hide").MustInt())
+		_, ok := env.CheckGet("datasource")
+		assert.False(t, ok)
+
+		assert.Equal(t, "custom", job.Get("type").MustString())
+		assert.Equal(t, "api", job.Get("query").MustString())
+		assert.Equal(t, "api", job.GetPath("current", "value").MustString())
+		assert.Equal(t, hideVariable, job.Get("hide").MustInt())
+	})
+}
+
 func TestGetPublicDashboard(t *testing.T) {
 	type storeResp struct {
 		pd  *PublicDashboard
@@ -1285,6 +1371,79 @@ func TestDeletePublicDashboard(t *testing.T) {
 	}
 }
 
+func TestSuspendPublicDashboard(t *testing.T) {
+	pubdash := &PublicDashboard{Uid: "2", OrgId: 1, DashboardUid: "uid"}
+	future := time.Now().Add(time.Hour)
+	past := time.Now().Add(-time.Hour)
+
+	testCases := []struct {
+		Name             string
+		Until            *time.Time
+		ExpectedErrResp  error
+		FindResp         *PublicDashboard
+		ShouldCallStore  bool
+		AffectedRowsResp int64
+	}{
+		{
+			Name:             "Successfully suspends a public dashboard",
+			Until:            &future,
+			FindResp:         pubdash,
+			ShouldCallStore:  true,
+			AffectedRowsResp: 1,
+		},
+		{
+			Name:             "Successfully resumes a public dashboard",
+			Until:            nil,
+			FindResp:         pubdash,
+			ShouldCallStore:  true,
+			AffectedRowsResp: 1,
+		},
+		{
+			Name:            "Public dashboard not found by UID",
+			Until:           &future,
+			ExpectedErrResp: ErrPublicDashboardNotFound.Errorf("Suspend: public dashboard not found by uid: pubdashUID"),
+		},
+		{
+			Name:            "Public dashboard UID does not belong to the dashboard",
+			Until:           &future,
+			ExpectedErrResp: ErrInvalidUid.Errorf("Suspend: the public dashboard does not belong to the dashboard"),
+			FindResp:        &PublicDashboard{Uid: "2", OrgId: 1, DashboardUid: "wrong"},
+		},
+		{
+			Name:            "Suspension ending in the past",
+			Until:           &past,
+			ExpectedErrResp: ErrInvalidSuspendDuration.Errorf("Suspend: the suspension should end in the future"),
+			FindResp:        pubdash,
+		},
+		{
+			Name:             "Public dashboard deleted before the suspension",
+			Until:            &future,
+			ExpectedErrResp:  ErrPublicDashboardNotFound.Errorf("Suspend: failed to suspend public dashboard not found by uid: pubdashUID"),
+			FindResp:         pubdash,
+			ShouldCallStore:  true,
+			AffectedRowsResp: 0,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.Name, func(t *testing.T) {
+			store := NewFakePublicDashboardStore(t)
+			store.On("Find", mock.Anything, "pubdashUID").Return(tt.FindResp, nil)
+			if tt.ShouldCallStore {
+				store.On("Suspend", mock.Anything, "pubdashUID", tt.Until).Return(tt.AffectedRowsResp, nil)
+			}
+			service, _, _ := newPublicDashboardServiceImpl(t, store, nil, nil)
+
+			err := service.Suspend(context.Background(), "pubdashUID", "uid", tt.Until)
+			if tt.ExpectedErrResp != nil {
+				assert.Equal(t, tt.ExpectedErrResp.Error(), err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
 func TestPublicDashboardServiceImpl_getSafeIntervalAndMaxDataPoints(t *testing.T) {
 	type args struct {
 		reqDTO PublicDashboardQueryDTO
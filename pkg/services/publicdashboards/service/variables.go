@@ -0,0 +1,220 @@
+package service
+
+import (
+	"encoding/json"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/publicdashboards/models"
+)
+
+const (
+	// allVariableValue is the value of the "All" option of a variable
+	allVariableValue = "$__all"
+	// hideVariable hides both the label and the value of a variable on the dashboard
+	hideVariable = 2
+)
+
+// variableRegex matches the references to the variables in the $name, ${name}, ${name:format}, [[name]] and
+// [[name:format]] syntaxes
+var variableRegex = regexp.MustCompile(`\$(\w+)|\[\[(\w+?)(?::(\w+))?\]\]|\$\{(\w+)(?::(\w+))?\}`)
+
+// getDashboardVariableNames returns the names of the template variables of the dashboard
+func getDashboardVariableNames(dashboard *simplejson.Json) []string {
+	var names []string
+	for _, variableObj := range dashboard.GetPath("templating", "list").MustArray() {
+		names = append(names, simplejson.NewFromAny(variableObj).Get("name").MustString())
+	}
+	return names
+}
+
+// getVariableValues returns the values of the template variables of the dashboard by name, with the values selected
+// by the viewer instead of the current ones. The values must have been validated against the allowlist.
+func getVariableValues(dashboard *simplejson.Json, selected map[string][]string) map[string][]string {
+	values := make(map[string][]string)
+	for _, variableObj := range dashboard.GetPath("templating", "list").MustArray() {
+		variable := simplejson.NewFromAny(variableObj)
+		name := variable.Get("name").MustString()
+
+		current, ok := selected[name]
+		if !ok {
+			current = jsonStrings(variable.GetPath("current", "value"))
+		}
+
+		if len(current) == 1 && current[0] == allVariableValue {
+			if allValue := variable.Get("allValue").MustString(); allValue != "" {
+				current = []string{allValue}
+			} else {
+				current = nil
+				for _, optionObj := range variable.Get("options").MustArray() {
+					if value := simplejson.NewFromAny(optionObj).Get("value").MustString(); value != allVariableValue {
+						current = append(current, value)
+					}
+				}
+			}
+		}
+
+		values[name] = current
+	}
+	return values
+}
+
+// jsonStrings returns the value of a variable, which is a string or an array of strings
+func jsonStrings(value *simplejson.Json) []string {
+	if s, err := value.String(); err == nil {
+		return []string{s}
+	}
+	return value.MustStringArray()
+}
+
+// interpolateVariables replaces the references to the template variables in the string fields of the queries with
+// their values
+func interpolateVariables(queries []*simplejson.Json, values map[string][]string) {
+	if len(values) == 0 {
+		return
+	}
+
+	for _, query := range queries {
+		datasourceType := query.Get("datasource").Get("type").MustString()
+		for key, field := range query.MustMap() {
+			if key == "refId" || key == "datasource" {
+				continue
+			}
+			query.Set(key, interpolateValue(field, values, datasourceType))
+		}
+	}
+}
+
+func interpolateValue(value any, values map[string][]string, datasourceType string) any {
+	switch v := value.(type) {
+	case string:
+		return interpolateString(v, values, datasourceType)
+	case map[string]any:
+		for key, field := range v {
+			v[key] = interpolateValue(field, values, datasourceType)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = interpolateValue(item, values, datasourceType)
+		}
+	}
+	return value
+}
+
+func interpolateString(s string, values map[string][]string, datasourceType string) string {
+	return variableRegex.ReplaceAllStringFunc(s, func(match string) string {
+		groups := variableRegex.FindStringSubmatch(match)
+		name, format := groups[1]+groups[2]+groups[4], groups[3]+groups[5]
+
+		value, ok := values[name]
+		if !ok {
+			return match
+		}
+		return formatVariableValue(value, format, datasourceType)
+	})
+}
+
+// formatVariableValue formats the values of a variable like the frontend does. Without a format, single values are
+// used as is, and multiple values are formatted as a regular expression for Prometheus and Loki, or as a glob.
+func formatVariableValue(values []string, format string, datasourceType string) string {
+	if format == "" {
+		if len(values) == 1 {
+			return values[0]
+		}
+		format = "glob"
+		if datasourceType == "prometheus" || datasourceType == "loki" {
+			format = "regex"
+		}
+	}
+
+	switch format {
+	case "csv":
+		return strings.Join(values, ",")
+	case "pipe":
+		return strings.Join(values, "|")
+	case "regex":
+		escaped := make([]string, 0, len(values))
+		for _, value := range values {
+			escaped = append(escaped, regexp.QuoteMeta(value))
+		}
+		if len(escaped) == 1 {
+			return escaped[0]
+		}
+		return "(" + strings.Join(escaped, "|") + ")"
+	case "glob":
+		if len(values) == 1 {
+			return values[0]
+		}
+		return "{" + strings.Join(values, ",") + "}"
+	case "json":
+		b, _ := json.Marshal(values)
+		return string(b)
+	case "singlequote":
+		return quoteValues(values, "'", `\'`)
+	case "doublequote":
+		return quoteValues(values, `"`, `\"`)
+	case "sqlstring":
+		return quoteValues(values, "'", "''")
+	default:
+		// raw and unknown formats
+		return strings.Join(values, ",")
+	}
+}
+
+func quoteValues(values []string, quote string, escapedQuote string) string {
+	quoted := make([]string, 0, len(values))
+	for _, value := range values {
+		quoted = append(quoted, quote+strings.ReplaceAll(value, quote, escapedQuote)+quote)
+	}
+	return strings.Join(quoted, ",")
+}
+
+// sanitizeVariables turns the template variables of the dashboard into custom variables, so that their queries are
+// not sent to the viewers. The allowed variables have the allowed values as options, and the others are hidden with
+// their current values.
+func sanitizeVariables(data *simplejson.Json, allowlist models.VariableAllowlist) {
+	values := getVariableValues(data, nil)
+	for _, variableObj := range data.GetPath("templating", "list").MustArray() {
+		variable := simplejson.NewFromAny(variableObj)
+		name := variable.Get("name").MustString()
+		current := values[name]
+
+		options := current
+		allowed, isAllowed := allowlist.Find(name)
+		if isAllowed {
+			options = allowed.Values
+			current = jsonStrings(variable.GetPath("current", "value"))
+			if len(current) == 0 || slices.ContainsFunc(current, func(value string) bool { return !allowed.Allows(value) }) {
+				current = allowed.Values[:1]
+			}
+		} else {
+			variable.Set("hide", hideVariable)
+		}
+
+		variableOptions := make([]any, 0, len(options))
+		for _, option := range options {
+			variableOptions = append(variableOptions, map[string]any{
+				"text":     option,
+				"value":    option,
+				"selected": slices.Contains(current, option),
+			})
+		}
+
+		var currentValue any = current
+		if len(current) == 1 && !variable.Get("multi").MustBool() {
+			currentValue = current[0]
+		}
+
+		variable.Set("type", "custom")
+		variable.Set("query", strings.Join(options, ","))
+		variable.Set("options", variableOptions)
+		variable.Set("current", map[string]any{"text": currentValue, "value": currentValue})
+		variable.Set("includeAll", false)
+		variable.Del("allValue")
+		variable.Del("datasource")
+		variable.Del("definition")
+		variable.Del("regex")
+	}
+}
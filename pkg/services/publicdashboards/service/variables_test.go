@@ -0,0 +1,89 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+const dashboardWithVariables = `{
+  "templating": {
+    "list": [
+      {"name": "env", "current": {"text": "dev", "value": "dev"}},
+      {"name": "job", "current": {"text": ["api", "db"], "value": ["api", "db"]}},
+      {
+        "name": "instance",
+        "current": {"text": "All", "value": "$__all"},
+        "options": [{"value": "$__all"}, {"value": "a:9090"}, {"value": "b:9090"}]
+      },
+      {"name": "region", "allValue": ".*", "current": {"text": "All", "value": ["$__all"]}}
+    ]
+  }
+}`
+
+func TestGetVariableValues(t *testing.T) {
+	dashboard, err := simplejson.NewJson([]byte(dashboardWithVariables))
+	require.NoError(t, err)
+
+	t.Run("returns the current values of the variables", func(t *testing.T) {
+		assert.Equal(t, map[string][]string{
+			"env":      {"dev"},
+			"job":      {"api", "db"},
+			"instance": {"a:9090", "b:9090"},
+			"region":   {".*"},
+		}, getVariableValues(dashboard, nil))
+	})
+
+	t.Run("returns the selected values instead of the current ones", func(t *testing.T) {
+		values := getVariableValues(dashboard, map[string][]string{"env": {"prod"}})
+		assert.Equal(t, []string{"prod"}, values["env"])
+		assert.Equal(t, []string{"api", "db"}, values["job"])
+	})
+}
+
+func TestInterpolateVariables(t *testing.T) {
+	values := map[string][]string{
+		"env": {"dev"},
+		"job": {"api", "db.1"},
+	}
+
+	testCases := []struct {
+		name           string
+		datasourceType string
+		query          string
+		expected       string
+	}{
+		{name: "single value", datasourceType: "mysql", query: "SELECT * FROM t WHERE env = '$env'", expected: "SELECT * FROM t WHERE env = 'dev'"},
+		{name: "braces syntax", datasourceType: "mysql", query: "${env}_total", expected: "dev_total"},
+		{name: "brackets syntax", datasourceType: "mysql", query: "[[env]]_total", expected: "dev_total"},
+		{name: "unknown variables", datasourceType: "mysql", query: "$__interval $environment", expected: "$__interval $environment"},
+		{name: "multiple values as regex for prometheus", datasourceType: "prometheus", query: `up{job=~"$job"}`, expected: `up{job=~"(api|db\.1)"}`},
+		{name: "multiple values as glob", datasourceType: "graphite", query: "servers.$job.cpu", expected: "servers.{api,db.1}.cpu"},
+		{name: "csv format", datasourceType: "prometheus", query: "${job:csv}", expected: "api,db.1"},
+		{name: "pipe format", datasourceType: "prometheus", query: "${job:pipe}", expected: "api|db.1"},
+		{name: "json format", datasourceType: "prometheus", query: "${job:json}", expected: `["api","db.1"]`},
+		{name: "singlequote format", datasourceType: "mysql", query: "IN (${job:singlequote})", expected: "IN ('api','db.1')"},
+		{name: "sqlstring format", datasourceType: "mysql", query: "[[job:sqlstring]]", expected: "'api','db.1'"},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			query := simplejson.NewFromAny(map[string]any{
+				"datasource": map[string]any{"type": test.datasourceType, "uid": "$env"},
+				"refId":      "$env",
+				"expr":       test.query,
+				"nested":     map[string]any{"values": []any{test.query}},
+			})
+
+			interpolateVariables([]*simplejson.Json{query}, values)
+
+			assert.Equal(t, test.expected, query.Get("expr").MustString())
+			assert.Equal(t, test.expected, query.GetPath("nested", "values").GetIndex(0).MustString())
+			assert.Equal(t, "$env", query.Get("refId").MustString())
+			assert.Equal(t, "$env", query.Get("datasource").Get("uid").MustString())
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package validation
 
 import (
+	"slices"
+
 	"github.com/google/uuid"
 	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
 	"github.com/grafana/grafana/pkg/tsdb/legacydata"
@@ -13,6 +15,10 @@ func ValidatePublicDashboard(dto *SavePublicDashboardDTO) error {
 		return ErrInvalidShareType.Errorf("ValidateSavePublicDashboard: invalid share type")
 	}
 
+	if dto.PublicDashboard.MaxViews != nil && *dto.PublicDashboard.MaxViews < 0 {
+		return ErrInvalidMaxViews.Errorf("ValidateSavePublicDashboard: maxViews should not be negative")
+	}
+
 	return nil
 }
 
@@ -25,6 +31,18 @@ func ValidateQueryPublicDashboardRequest(req PublicDashboardQueryDTO, pd *Public
 		return ErrInvalidMaxDataPoints.Errorf("ValidateQueryPublicDashboardRequest: maxDataPoints should be greater than 0")
 	}
 
+	for name, values := range req.Variables {
+		allowed, ok := pd.VariableAllowlist.Find(name)
+		if !ok {
+			return ErrInvalidVariable.Errorf("ValidateQueryPublicDashboardRequest: variable %s is not allowed", name)
+		}
+		for _, value := range values {
+			if !allowed.Allows(value) {
+				return ErrInvalidVariable.Errorf("ValidateQueryPublicDashboardRequest: value %s of variable %s is not allowed", value, name)
+			}
+		}
+	}
+
 	if pd.TimeSelectionEnabled {
 		timeRange := legacydata.NewDataTimeRange(req.TimeRange.From, req.TimeRange.To)
 
@@ -41,6 +59,30 @@ func ValidateQueryPublicDashboardRequest(req PublicDashboardQueryDTO, pd *Public
 	return nil
 }
 
+// ValidateVariableAllowlist asserts that the allowed variables are variables of the dashboard, with at least one
+// allowed value
+func ValidateVariableAllowlist(allowlist VariableAllowlist, dashboardVariables []string) error {
+	names := make(map[string]bool, len(allowlist))
+	for _, variable := range allowlist {
+		if variable.Name == "" {
+			return ErrInvalidVariableAllowlist.Errorf("ValidateVariableAllowlist: variable name is empty")
+		}
+		if names[variable.Name] {
+			return ErrInvalidVariableAllowlist.Errorf("ValidateVariableAllowlist: variable %s is duplicated", variable.Name)
+		}
+		names[variable.Name] = true
+
+		if !slices.Contains(dashboardVariables, variable.Name) {
+			return ErrInvalidVariableAllowlist.Errorf("ValidateVariableAllowlist: variable %s is not a variable of the dashboard", variable.Name)
+		}
+		if len(variable.Values) == 0 {
+			return ErrInvalidVariableAllowlist.Errorf("ValidateVariableAllowlist: variable %s has no allowed values", variable.Name)
+		}
+	}
+
+	return nil
+}
+
 // IsValidAccessToken asserts that an accessToken is a valid uuid
 func IsValidAccessToken(token string) bool {
 	_, err := uuid.Parse(token)
@@ -22,6 +22,14 @@ func TestValidatePublicDashboard(t *testing.T) {
 		err := ValidatePublicDashboard(dto)
 		require.Error(t, err)
 	})
+
+	t.Run("Returns error when maxViews is negative", func(t *testing.T) {
+		maxViews := int64(-1)
+		dto := &SavePublicDashboardDTO{DashboardUid: "abc123", UserId: 1, PublicDashboard: &PublicDashboardDTO{MaxViews: &maxViews}}
+
+		err := ValidatePublicDashboard(dto)
+		require.ErrorIs(t, err, ErrInvalidMaxViews)
+	})
 }
 
 func TestValidateQueryPublicDashboardRequest(t *testing.T) {
@@ -129,6 +137,42 @@ func TestValidateQueryPublicDashboardRequest(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "Returns no error when variable values are allowed",
+			args: args{
+				req: PublicDashboardQueryDTO{
+					Variables: map[string][]string{"env": {"dev", "prod"}},
+				},
+				pd: &PublicDashboard{
+					VariableAllowlist: VariableAllowlist{{Name: "env", Values: []string{"dev", "prod"}}},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Returns validation error when variable is not allowed",
+			args: args{
+				req: PublicDashboardQueryDTO{
+					Variables: map[string][]string{"instance": {"a"}},
+				},
+				pd: &PublicDashboard{
+					VariableAllowlist: VariableAllowlist{{Name: "env", Values: []string{"dev"}}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Returns validation error when variable value is not allowed",
+			args: args{
+				req: PublicDashboardQueryDTO{
+					Variables: map[string][]string{"env": {"dev", "prod"}},
+				},
+				pd: &PublicDashboard{
+					VariableAllowlist: VariableAllowlist{{Name: "env", Values: []string{"dev"}}},
+				},
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -139,6 +183,30 @@ func TestValidateQueryPublicDashboardRequest(t *testing.T) {
 	}
 }
 
+func TestValidateVariableAllowlist(t *testing.T) {
+	dashboardVariables := []string{"env", "instance"}
+
+	t.Run("Returns no error when the allowlist is valid", func(t *testing.T) {
+		err := ValidateVariableAllowlist(VariableAllowlist{{Name: "env", Values: []string{"dev"}}}, dashboardVariables)
+		require.NoError(t, err)
+	})
+
+	t.Run("Returns error when the variable is not a variable of the dashboard", func(t *testing.T) {
+		err := ValidateVariableAllowlist(VariableAllowlist{{Name: "job", Values: []string{"a"}}}, dashboardVariables)
+		require.ErrorIs(t, err, ErrInvalidVariableAllowlist)
+	})
+
+	t.Run("Returns error when the variable is duplicated", func(t *testing.T) {
+		err := ValidateVariableAllowlist(VariableAllowlist{{Name: "env", Values: []string{"dev"}}, {Name: "env", Values: []string{"prod"}}}, dashboardVariables)
+		require.ErrorIs(t, err, ErrInvalidVariableAllowlist)
+	})
+
+	t.Run("Returns error when the variable has no allowed values", func(t *testing.T) {
+		err := ValidateVariableAllowlist(VariableAllowlist{{Name: "env"}}, dashboardVariables)
+		require.ErrorIs(t, err, ErrInvalidVariableAllowlist)
+	})
+}
+
 func TestValidAccessToken(t *testing.T) {
 	t.Run("true", func(t *testing.T) {
 		uuid := "da82510c2aa64d78a2e87fef36c58e89"
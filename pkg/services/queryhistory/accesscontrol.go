@@ -0,0 +1,70 @@
+package queryhistory
+
+import (
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/org"
+)
+
+const (
+	// ActionLibraryRead allows searching the queries published to the library.
+	ActionLibraryRead = "queries.library:read"
+	// ActionLibraryWrite allows publishing queries to the library, and unpublishing them.
+	ActionLibraryWrite = "queries.library:write"
+	// ActionLibraryDelete allows unpublishing the queries published by other users.
+	ActionLibraryDelete = "queries.library:delete"
+	// ActionLibraryTeamsRead allows searching the queries published to any team, not only to the teams of the user.
+	ActionLibraryTeamsRead = "queries.library.teams:read"
+)
+
+var (
+	libraryReaderRole = accesscontrol.RoleDTO{
+		Name:        "fixed:queries.library:reader",
+		DisplayName: "Query library reader",
+		Description: "Search the queries published to the query library",
+		Group:       "Query library",
+		Permissions: []accesscontrol.Permission{
+			{Action: ActionLibraryRead},
+		},
+	}
+
+	libraryWriterRole = accesscontrol.RoleDTO{
+		Name:        "fixed:queries.library:writer",
+		DisplayName: "Query library writer",
+		Description: "Search the query library, and publish queries to it",
+		Group:       "Query library",
+		Permissions: []accesscontrol.Permission{
+			{Action: ActionLibraryRead},
+			{Action: ActionLibraryWrite},
+		},
+	}
+
+	libraryManagerRole = accesscontrol.RoleDTO{
+		Name:        "fixed:queries.library:manager",
+		DisplayName: "Query library manager",
+		Description: "Search the queries of any team in the query library, publish queries to it, and unpublish the queries of any user",
+		Group:       "Query library",
+		Permissions: []accesscontrol.Permission{
+			{Action: ActionLibraryRead},
+			{Action: ActionLibraryWrite},
+			{Action: ActionLibraryDelete},
+			{Action: ActionLibraryTeamsRead},
+		},
+	}
+)
+
+func declareFixedRoles(ac accesscontrol.Service) error {
+	libraryReader := accesscontrol.RoleRegistration{
+		Role:   libraryReaderRole,
+		Grants: []string{string(org.RoleViewer)},
+	}
+	libraryWriter := accesscontrol.RoleRegistration{
+		Role:   libraryWriterRole,
+		Grants: []string{string(org.RoleEditor)},
+	}
+	libraryManager := accesscontrol.RoleRegistration{
+		Role:   libraryManagerRole,
+		Grants: []string{string(org.RoleAdmin)},
+	}
+
+	return ac.DeclareFixedRoles(libraryReader, libraryWriter, libraryManager)
+}
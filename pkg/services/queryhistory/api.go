@@ -1,11 +1,13 @@
 package queryhistory
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/middleware"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/tsdb/legacydata"
 	"github.com/grafana/grafana/pkg/util"
@@ -13,6 +15,8 @@ import (
 )
 
 func (s *QueryHistoryService) registerAPIEndpoints() {
+	authorize := ac.Middleware(s.accessControl)
+
 	s.RouteRegister.Group("/api/query-history", func(entities routing.RouteRegister) {
 		entities.Post("/", middleware.ReqSignedIn, routing.Wrap(s.createHandler))
 		entities.Get("/", middleware.ReqSignedIn, routing.Wrap(s.searchHandler))
@@ -20,6 +24,12 @@ func (s *QueryHistoryService) registerAPIEndpoints() {
 		entities.Post("/star/:uid", middleware.ReqSignedIn, routing.Wrap(s.starHandler))
 		entities.Delete("/star/:uid", middleware.ReqSignedIn, routing.Wrap(s.unstarHandler))
 		entities.Patch("/:uid", middleware.ReqSignedIn, routing.Wrap(s.patchCommentHandler))
+		entities.Put("/tags/:uid", middleware.ReqSignedIn, routing.Wrap(s.setTagsHandler))
+		entities.Get("/export", middleware.ReqSignedIn, routing.Wrap(s.exportHandler))
+		entities.Post("/import", middleware.ReqSignedIn, routing.Wrap(s.importHandler))
+		entities.Get("/library", middleware.ReqSignedIn, authorize(ac.EvalPermission(ActionLibraryRead)), routing.Wrap(s.searchLibraryHandler))
+		entities.Post("/library/:uid", middleware.ReqSignedIn, authorize(ac.EvalPermission(ActionLibraryWrite)), routing.Wrap(s.publishHandler))
+		entities.Delete("/library/:uid", middleware.ReqSignedIn, authorize(ac.EvalPermission(ActionLibraryWrite)), routing.Wrap(s.unpublishHandler))
 	})
 }
 
@@ -65,6 +75,7 @@ func (s *QueryHistoryService) searchHandler(c *contextmodel.ReqContext) response
 
 	query := SearchInQueryHistoryQuery{
 		DatasourceUIDs: c.QueryStrings("datasourceUid"),
+		Tags:           c.QueryStrings("tag"),
 		SearchString:   c.Query("searchString"),
 		OnlyStarred:    c.QueryBoolWithDefault("onlyStarred", false),
 		Sort:           c.Query("sort"),
@@ -187,7 +198,194 @@ func (s *QueryHistoryService) unstarHandler(c *contextmodel.ReqContext) response
 	return response.JSON(http.StatusOK, QueryHistoryResponse{Result: query})
 }
 
-// swagger:parameters starQuery patchQueryComment deleteQuery unstarQuery
+// swagger:route PUT /query-history/tags/{query_history_uid} query_history setQueryTags
+//
+// Set tags of query in query history.
+//
+// Replaces the tags of query in query history as specified by the UID.
+//
+// Responses:
+// 200: getQueryHistoryResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 404: notFoundError
+// 500: internalServerError
+func (s *QueryHistoryService) setTagsHandler(c *contextmodel.ReqContext) response.Response {
+	queryUID := web.Params(c.Req)[":uid"]
+	if len(queryUID) > 0 && !util.IsValidShortUID(queryUID) {
+		return response.Error(http.StatusNotFound, "Query in query history not found", nil)
+	}
+
+	cmd := SetQueryTagsInQueryHistoryCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	query, err := s.SetQueryTagsInQueryHistory(c.Req.Context(), c.SignedInUser, queryUID, cmd)
+	if err != nil {
+		return errorResponse("Failed to set tags of query in query history", err)
+	}
+
+	return response.JSON(http.StatusOK, QueryHistoryResponse{Result: query})
+}
+
+// swagger:route GET /query-history/export query_history exportQueries
+//
+// Export query history.
+//
+// Exports the queries in the query history of the user, with their comments, tags and stars.
+// The export can be imported by other users, or in other Grafana instances.
+//
+// Responses:
+// 200: getQueryHistoryExportResponse
+// 401: unauthorisedError
+// 500: internalServerError
+func (s *QueryHistoryService) exportHandler(c *contextmodel.ReqContext) response.Response {
+	export, err := s.ExportQueriesFromQueryHistory(c.Req.Context(), c.SignedInUser, c.QueryStrings("datasourceUid"))
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to export query history", err)
+	}
+
+	return response.JSON(http.StatusOK, export)
+}
+
+// swagger:route POST /query-history/import query_history importQueries
+//
+// Import queries in query history.
+//
+// Adds exported queries to the query history of the user. Up to 1000 queries can be imported by a request.
+// Either all queries are imported, or none.
+//
+// Responses:
+// 200: getQueryHistoryImportResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 500: internalServerError
+func (s *QueryHistoryService) importHandler(c *contextmodel.ReqContext) response.Response {
+	cmd := ImportQueriesInQueryHistoryCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	imported, err := s.ImportQueriesInQueryHistory(c.Req.Context(), c.SignedInUser, cmd)
+	if err != nil {
+		return errorResponse("Failed to import queries in query history", err)
+	}
+
+	return response.JSON(http.StatusOK, QueryHistoryImportResponse{
+		Message:  "Queries imported",
+		Imported: imported,
+	})
+}
+
+// swagger:route GET /query-history/library query_history searchQueryLibrary
+//
+// Query library search.
+//
+// Returns a list of queries published to the library that matches the search criteria.
+// The library contains the queries published to the organization and to the teams of the user.
+// Query library search supports pagination. Use the `limit` parameter to control the maximum number of queries returned; the default limit is 100.
+//
+// Responses:
+// 200: getQueryLibrarySearchResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *QueryHistoryService) searchLibraryHandler(c *contextmodel.ReqContext) response.Response {
+	query := SearchInQueryLibraryQuery{
+		DatasourceUIDs: c.QueryStrings("datasourceUid"),
+		Tags:           c.QueryStrings("tag"),
+		SearchString:   c.Query("searchString"),
+		TeamID:         c.QueryInt64("teamId"),
+		Sort:           c.Query("sort"),
+		Page:           c.QueryInt("page"),
+		Limit:          c.QueryInt("limit"),
+	}
+
+	result, err := s.SearchInQueryLibrary(c.Req.Context(), c.SignedInUser, query)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get query library", err)
+	}
+
+	return response.JSON(http.StatusOK, QueryLibrarySearchResponse{Result: result})
+}
+
+// swagger:route POST /query-history/library/{query_history_uid} query_history publishQuery
+//
+// Publish query to the query library.
+//
+// Publishes query in query history as specified by the UID to the library of the organization,
+// or to the library of a team of the user. Published queries are not removed by the query history cleanup.
+//
+// Responses:
+// 200: getQueryLibraryResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 409: conflictError
+// 500: internalServerError
+func (s *QueryHistoryService) publishHandler(c *contextmodel.ReqContext) response.Response {
+	queryUID := web.Params(c.Req)[":uid"]
+	if len(queryUID) > 0 && !util.IsValidShortUID(queryUID) {
+		return response.Error(http.StatusNotFound, "Query in query history not found", nil)
+	}
+
+	cmd := PublishQueryInQueryHistoryCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	query, err := s.PublishQueryInQueryHistory(c.Req.Context(), c.SignedInUser, queryUID, cmd)
+	if err != nil {
+		return errorResponse("Failed to publish query to query library", err)
+	}
+
+	return response.JSON(http.StatusOK, QueryLibraryResponse{Result: query})
+}
+
+// swagger:route DELETE /query-history/library/{query_history_uid} query_history unpublishQuery
+//
+// Unpublish query from the query library.
+//
+// Removes query from the query library as specified by the UID. The query is kept in the query history of its author.
+// Users can only unpublish the queries they published, unless they have the `queries.library:delete` permission.
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *QueryHistoryService) unpublishHandler(c *contextmodel.ReqContext) response.Response {
+	queryUID := web.Params(c.Req)[":uid"]
+	if len(queryUID) > 0 && !util.IsValidShortUID(queryUID) {
+		return response.Error(http.StatusNotFound, "Published query not found", nil)
+	}
+
+	if err := s.UnpublishQueryInQueryHistory(c.Req.Context(), c.SignedInUser, queryUID); err != nil {
+		return errorResponse("Failed to unpublish query from query library", err)
+	}
+
+	return response.Success("Query unpublished")
+}
+
+func errorResponse(message string, err error) response.Response {
+	switch {
+	case errors.Is(err, ErrQueryNotFound), errors.Is(err, ErrPublishedQueryNotFound):
+		return response.Error(http.StatusNotFound, err.Error(), err)
+	case errors.Is(err, ErrQueryAlreadyPublished):
+		return response.Error(http.StatusConflict, err.Error(), err)
+	case errors.Is(err, ErrNotTeamMember):
+		return response.Error(http.StatusForbidden, err.Error(), err)
+	case errors.Is(err, ErrInvalidTag), errors.Is(err, ErrTooManyTags),
+		errors.Is(err, ErrTooManyImportedQueries), errors.Is(err, ErrImportedQueryHasNoQuery):
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	}
+	return response.Error(http.StatusInternalServerError, message, err)
+}
+
+// swagger:parameters starQuery patchQueryComment deleteQuery unstarQuery setQueryTags publishQuery unpublishQuery
 type QueryHistoryByUID struct {
 	// in:path
 	// required:true
@@ -202,6 +400,12 @@ type SearchQueriesParams struct {
 	// type: array
 	// collectionFormat: multi
 	DatasourceUid []string `json:"datasourceUid"`
+	// List of tags the queries must all have
+	// in:query
+	// required: false
+	// type: array
+	// collectionFormat: multi
+	Tag []string `json:"tag"`
 	// Text inside query or comments that is searched for
 	// in:query
 	// required: false
@@ -234,6 +438,54 @@ type SearchQueriesParams struct {
 	To int64 `json:"to"`
 }
 
+// swagger:parameters searchQueryLibrary
+type SearchQueryLibraryParams struct {
+	// List of data source UIDs to search for
+	// in:query
+	// required: false
+	// type: array
+	// collectionFormat: multi
+	DatasourceUid []string `json:"datasourceUid"`
+	// List of tags the queries must all have
+	// in:query
+	// required: false
+	// type: array
+	// collectionFormat: multi
+	Tag []string `json:"tag"`
+	// Text inside query or comments that is searched for
+	// in:query
+	// required: false
+	SearchString string `json:"searchString"`
+	// ID of the team the queries are published to
+	// in:query
+	// required: false
+	TeamID int64 `json:"teamId"`
+	// Sort method, by publication time
+	// in:query
+	// required: false
+	// default: time-desc
+	// Enum: time-desc,time-asc
+	Sort string `json:"sort"`
+	// Use this parameter to access hits beyond limit. Numbering starts at 1. limit param acts as page size.
+	// in:query
+	// required: false
+	Page int `json:"page"`
+	// Limit the number of returned results
+	// in:query
+	// required: false
+	Limit int `json:"limit"`
+}
+
+// swagger:parameters exportQueries
+type ExportQueriesParams struct {
+	// List of data source UIDs whose queries are exported
+	// in:query
+	// required: false
+	// type: array
+	// collectionFormat: multi
+	DatasourceUid []string `json:"datasourceUid"`
+}
+
 // swagger:parameters createQuery
 type CreateQueryParams struct {
 	// in:body
@@ -265,3 +517,48 @@ type GetQueryHistoryDeleteQueryResponse struct {
 	// in: body
 	Body QueryHistoryDeleteQueryResponse `json:"body"`
 }
+
+// swagger:parameters setQueryTags
+type SetQueryTagsParams struct {
+	// in:body
+	// required:true
+	Body SetQueryTagsInQueryHistoryCommand `json:"body"`
+}
+
+// swagger:parameters publishQuery
+type PublishQueryParams struct {
+	// in:body
+	// required:true
+	Body PublishQueryInQueryHistoryCommand `json:"body"`
+}
+
+// swagger:parameters importQueries
+type ImportQueriesParams struct {
+	// in:body
+	// required:true
+	Body ImportQueriesInQueryHistoryCommand `json:"body"`
+}
+
+// swagger:response getQueryLibrarySearchResponse
+type GetQueryLibrarySearchResponse struct {
+	// in: body
+	Body QueryLibrarySearchResponse `json:"body"`
+}
+
+// swagger:response getQueryLibraryResponse
+type GetQueryLibraryResponse struct {
+	// in: body
+	Body QueryLibraryResponse `json:"body"`
+}
+
+// swagger:response getQueryHistoryExportResponse
+type GetQueryHistoryExportResponse struct {
+	// in: body
+	Body QueryHistoryExport `json:"body"`
+}
+
+// swagger:response getQueryHistoryImportResponse
+type GetQueryHistoryImportResponse struct {
+	// in: body
+	Body QueryHistoryImportResponse `json:"body"`
+}
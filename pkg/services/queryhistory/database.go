@@ -2,9 +2,14 @@ package queryhistory
 
 import (
 	"context"
+	"slices"
 	"strconv"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/grafana/grafana/pkg/infra/db"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/util"
 )
@@ -37,6 +42,7 @@ func (s QueryHistoryService) createQuery(ctx context.Context, user *user.SignedI
 		Comment:       queryHistory.Comment,
 		Queries:       queryHistory.Queries,
 		Starred:       false,
+		Tags:          []string{},
 	}
 
 	return dto, nil
@@ -63,6 +69,8 @@ func (s QueryHistoryService) searchQueries(ctx context.Context, user *user.Signe
 		query.Sort = "time-desc"
 	}
 
+	query.Tags = uniqueTags(query.Tags)
+
 	err := s.store.WithDbSession(ctx, func(session *db.Session) error {
 		dtosBuilder := db.SQLBuilder{}
 		dtosBuilder.Write(`SELECT
@@ -84,6 +92,10 @@ func (s QueryHistoryService) searchQueries(ctx context.Context, user *user.Signe
 			return err
 		}
 
+		if err := fillTagsAndPublished(session, dtos); err != nil {
+			return err
+		}
+
 		countBuilder := db.SQLBuilder{}
 		countBuilder.Write(`SELECT
 		`)
@@ -125,6 +137,14 @@ func (s QueryHistoryService) deleteQuery(ctx context.Context, user *user.SignedI
 			return ErrQueryNotFound
 		}
 
+		// Tags and the library entry only exist along with the query
+		if _, err := session.Where("query_uid = ?", UID).Delete(&QueryHistoryTag{}); err != nil {
+			return err
+		}
+		if _, err := session.Where("org_id = ? AND query_uid = ?", user.OrgID, UID).Delete(&QueryLibraryEntry{}); err != nil {
+			return err
+		}
+
 		queryID = id
 		return nil
 	})
@@ -135,7 +155,8 @@ func (s QueryHistoryService) deleteQuery(ctx context.Context, user *user.SignedI
 // patchQueryComment searches updates comment for query in query history
 func (s QueryHistoryService) patchQueryComment(ctx context.Context, user *user.SignedInUser, UID string, cmd PatchQueryCommentInQueryHistoryCommand) (QueryHistoryDTO, error) {
 	var queryHistory QueryHistory
-	var isStarred bool
+	var isStarred, isPublished bool
+	var tags []string
 
 	err := s.store.WithTransactionalDbSession(ctx, func(session *db.Session) error {
 		exists, err := session.Where("org_id = ? AND created_by = ? AND uid = ?", user.OrgID, user.UserID, UID).Get(&queryHistory)
@@ -157,7 +178,9 @@ func (s QueryHistoryService) patchQueryComment(ctx context.Context, user *user.S
 			return err
 		}
 		isStarred = starred
-		return nil
+
+		tags, isPublished, err = getTagsAndPublished(session, user.OrgID, UID)
+		return err
 	})
 
 	if err != nil {
@@ -172,6 +195,8 @@ func (s QueryHistoryService) patchQueryComment(ctx context.Context, user *user.S
 		Comment:       queryHistory.Comment,
 		Queries:       queryHistory.Queries,
 		Starred:       isStarred,
+		Tags:          tags,
+		Published:     isPublished,
 	}
 
 	return dto, nil
@@ -180,7 +205,8 @@ func (s QueryHistoryService) patchQueryComment(ctx context.Context, user *user.S
 // starQuery adds query into query_history_star table together with user_id and org_id
 func (s QueryHistoryService) starQuery(ctx context.Context, user *user.SignedInUser, UID string) (QueryHistoryDTO, error) {
 	var queryHistory QueryHistory
-	var isStarred bool
+	var isStarred, isPublished bool
+	var tags []string
 
 	err := s.store.WithTransactionalDbSession(ctx, func(session *db.Session) error {
 		// Check if query exists as we want to star only existing queries
@@ -207,7 +233,9 @@ func (s QueryHistoryService) starQuery(ctx context.Context, user *user.SignedInU
 		}
 
 		isStarred = true
-		return nil
+
+		tags, isPublished, err = getTagsAndPublished(session, user.OrgID, UID)
+		return err
 	})
 
 	if err != nil {
@@ -222,6 +250,8 @@ func (s QueryHistoryService) starQuery(ctx context.Context, user *user.SignedInU
 		Comment:       queryHistory.Comment,
 		Queries:       queryHistory.Queries,
 		Starred:       isStarred,
+		Tags:          tags,
+		Published:     isPublished,
 	}
 
 	return dto, nil
@@ -230,7 +260,8 @@ func (s QueryHistoryService) starQuery(ctx context.Context, user *user.SignedInU
 // unstarQuery deletes query with with user_id and org_id from query_history_star table
 func (s QueryHistoryService) unstarQuery(ctx context.Context, user *user.SignedInUser, UID string) (QueryHistoryDTO, error) {
 	var queryHistory QueryHistory
-	var isStarred bool
+	var isStarred, isPublished bool
+	var tags []string
 
 	err := s.store.WithTransactionalDbSession(ctx, func(session *db.Session) error {
 		exists, err := session.Table("query_history").Where("org_id = ? AND created_by = ? AND uid = ?", user.OrgID, user.UserID, UID).Get(&queryHistory)
@@ -250,7 +281,9 @@ func (s QueryHistoryService) unstarQuery(ctx context.Context, user *user.SignedI
 		}
 
 		isStarred = false
-		return nil
+
+		tags, isPublished, err = getTagsAndPublished(session, user.OrgID, UID)
+		return err
 	})
 
 	if err != nil {
@@ -265,11 +298,15 @@ func (s QueryHistoryService) unstarQuery(ctx context.Context, user *user.SignedI
 		Comment:       queryHistory.Comment,
 		Queries:       queryHistory.Queries,
 		Starred:       isStarred,
+		Tags:          tags,
+		Published:     isPublished,
 	}
 
 	return dto, nil
 }
 
+// deleteStaleQueries removes the queries older than olderThan, except the starred queries and the
+// queries published to the library
 func (s QueryHistoryService) deleteStaleQueries(ctx context.Context, olderThan int64) (int, error) {
 	var rowsCount int64
 
@@ -281,7 +318,10 @@ func (s QueryHistoryService) deleteStaleQueries(ctx context.Context, olderThan i
 					SELECT uid FROM query_history
 					LEFT JOIN query_history_star
 					ON query_history_star.query_uid = query_history.uid
+					LEFT JOIN query_library
+					ON query_library.query_uid = query_history.uid
 					WHERE query_history_star.query_uid IS NULL
+					AND query_library.query_uid IS NULL
 					AND query_history.created_at <= ?
 					ORDER BY query_history.id ASC
 					LIMIT 10000
@@ -298,7 +338,7 @@ func (s QueryHistoryService) deleteStaleQueries(ctx context.Context, olderThan i
 			return err
 		}
 
-		return nil
+		return deleteOrphanedTags(session)
 	})
 
 	if err != nil {
@@ -345,7 +385,10 @@ func (s QueryHistoryService) enforceQueryHistoryRowLimit(ctx context.Context, li
 							SELECT uid FROM query_history
 							LEFT JOIN query_history_star
 							ON query_history_star.query_uid = query_history.uid
+							LEFT JOIN query_library
+							ON query_library.query_uid = query_history.uid
 							WHERE query_history_star.query_uid IS NULL
+							AND query_library.query_uid IS NULL
 							ORDER BY query_history.id ASC
 							LIMIT ?
 						) AS q
@@ -366,6 +409,10 @@ func (s QueryHistoryService) enforceQueryHistoryRowLimit(ctx context.Context, li
 			if err != nil {
 				return err
 			}
+
+			if !starredQueries {
+				return deleteOrphanedTags(session)
+			}
 		}
 		return nil
 	})
@@ -375,3 +422,459 @@ func (s QueryHistoryService) enforceQueryHistoryRowLimit(ctx context.Context, li
 
 	return int(deletedRowsCount), nil
 }
+
+// setQueryTags replaces the tags of a query in query history
+func (s QueryHistoryService) setQueryTags(ctx context.Context, user *user.SignedInUser, UID string, cmd SetQueryTagsInQueryHistoryCommand) (QueryHistoryDTO, error) {
+	tags, err := normalizeTags(cmd.Tags)
+	if err != nil {
+		return QueryHistoryDTO{}, err
+	}
+
+	var queryHistory QueryHistory
+	var isStarred, isPublished bool
+
+	err = s.store.WithTransactionalDbSession(ctx, func(session *db.Session) error {
+		exists, err := session.Where("org_id = ? AND created_by = ? AND uid = ?", user.OrgID, user.UserID, UID).Get(&queryHistory)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrQueryNotFound
+		}
+
+		if _, err := session.Where("query_uid = ?", UID).Delete(&QueryHistoryTag{}); err != nil {
+			return err
+		}
+		if err := insertTags(session, UID, tags); err != nil {
+			return err
+		}
+
+		isStarred, err = session.Table("query_history_star").Where("user_id = ? AND query_uid = ?", user.UserID, UID).Exist()
+		if err != nil {
+			return err
+		}
+		isPublished, err = session.Where("org_id = ? AND query_uid = ?", user.OrgID, UID).Exist(&QueryLibraryEntry{})
+		return err
+	})
+
+	if err != nil {
+		return QueryHistoryDTO{}, err
+	}
+
+	slices.Sort(tags)
+	dto := QueryHistoryDTO{
+		UID:           queryHistory.UID,
+		DatasourceUID: queryHistory.DatasourceUID,
+		CreatedBy:     queryHistory.CreatedBy,
+		CreatedAt:     queryHistory.CreatedAt,
+		Comment:       queryHistory.Comment,
+		Queries:       queryHistory.Queries,
+		Starred:       isStarred,
+		Tags:          tags,
+		Published:     isPublished,
+	}
+
+	return dto, nil
+}
+
+// publishQuery adds a query of the user into the library of the organization, or into the library
+// of one of the teams of the user
+func (s QueryHistoryService) publishQuery(ctx context.Context, user *user.SignedInUser, UID string, cmd PublishQueryInQueryHistoryCommand) (QueryLibraryDTO, error) {
+	if cmd.TeamID != 0 && !slices.Contains(user.Teams, cmd.TeamID) {
+		return QueryLibraryDTO{}, ErrNotTeamMember
+	}
+
+	var queryHistory QueryHistory
+	var tags []string
+	entry := QueryLibraryEntry{
+		QueryUID:    UID,
+		OrgID:       user.OrgID,
+		TeamID:      cmd.TeamID,
+		PublishedBy: user.UserID,
+		PublishedAt: s.now().Unix(),
+	}
+
+	err := s.store.WithTransactionalDbSession(ctx, func(session *db.Session) error {
+		// Only the queries of the user can be published
+		exists, err := session.Where("org_id = ? AND created_by = ? AND uid = ?", user.OrgID, user.UserID, UID).Get(&queryHistory)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrQueryNotFound
+		}
+
+		_, err = session.Insert(&entry)
+		if err != nil {
+			if s.store.GetDialect().IsUniqueConstraintViolation(err) {
+				return ErrQueryAlreadyPublished
+			}
+			return err
+		}
+
+		queryTags, err := getQueryTags(session, []string{UID})
+		if err != nil {
+			return err
+		}
+		tags = tagsOrEmpty(queryTags[UID])
+		return nil
+	})
+
+	if err != nil {
+		return QueryLibraryDTO{}, err
+	}
+
+	dto := QueryLibraryDTO{
+		UID:           queryHistory.UID,
+		DatasourceUID: queryHistory.DatasourceUID,
+		CreatedBy:     queryHistory.CreatedBy,
+		CreatedAt:     queryHistory.CreatedAt,
+		Comment:       queryHistory.Comment,
+		Queries:       queryHistory.Queries,
+		Tags:          tags,
+		TeamID:        entry.TeamID,
+		PublishedBy:   entry.PublishedBy,
+		PublishedAt:   entry.PublishedAt,
+	}
+
+	return dto, nil
+}
+
+// unpublishQuery removes a query from the library. Users can only unpublish the queries they published,
+// unless they can unpublish the queries of other users.
+func (s QueryHistoryService) unpublishQuery(ctx context.Context, user *user.SignedInUser, UID string) error {
+	canUnpublishAll, err := s.accessControl.Evaluate(ctx, user, ac.EvalPermission(ActionLibraryDelete))
+	if err != nil {
+		return err
+	}
+
+	return s.store.WithDbSession(ctx, func(session *db.Session) error {
+		sess := session.Where("org_id = ? AND query_uid = ?", user.OrgID, UID)
+		if !canUnpublishAll {
+			sess = sess.And("published_by = ?", user.UserID)
+		}
+
+		count, err := sess.Delete(&QueryLibraryEntry{})
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return ErrPublishedQueryNotFound
+		}
+		return nil
+	})
+}
+
+// searchLibrary searches for queries in the library based on provided parameters. Users can search the
+// queries published to the organization and to their teams, unless they can search the queries of any team.
+func (s QueryHistoryService) searchLibrary(ctx context.Context, user *user.SignedInUser, query SearchInQueryLibraryQuery) (QueryLibrarySearchResult, error) {
+	dtos := make([]QueryLibraryDTO, 0)
+	var totalCount int
+
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+
+	if query.Limit <= 0 {
+		query.Limit = 100
+	}
+
+	if query.Sort == "" {
+		query.Sort = "time-desc"
+	}
+
+	query.Tags = uniqueTags(query.Tags)
+
+	canReadAllTeams, err := s.accessControl.Evaluate(ctx, user, ac.EvalPermission(ActionLibraryTeamsRead))
+	if err != nil {
+		return QueryLibrarySearchResult{}, err
+	}
+
+	datasourceFilter, err := ac.Filter(user, "query_history.datasource_uid", datasources.ScopePrefix, datasources.ActionQuery)
+	if err != nil {
+		return QueryLibrarySearchResult{}, err
+	}
+
+	err = s.store.WithDbSession(ctx, func(session *db.Session) error {
+		dtosBuilder := db.SQLBuilder{}
+		dtosBuilder.Write(`SELECT
+			query_history.uid,
+			query_history.datasource_uid,
+			query_history.created_by,
+			query_history.created_at,
+			query_history.comment,
+			query_history.queries,
+			query_library.team_id,
+			query_library.published_by,
+			query_library.published_at
+		`)
+		writeLibraryFiltersSQL(query, user, canReadAllTeams, datasourceFilter, s.store, &dtosBuilder)
+		writeLibrarySortSQL(query, s.store, &dtosBuilder)
+		dtosBuilder.Write(" LIMIT ? OFFSET ? ", query.Limit, query.Limit*(query.Page-1))
+
+		err := session.SQL(dtosBuilder.GetSQLString(), dtosBuilder.GetParams()...).Find(&dtos)
+		if err != nil {
+			return err
+		}
+
+		uids := make([]string, 0, len(dtos))
+		for _, dto := range dtos {
+			uids = append(uids, dto.UID)
+		}
+		tags, err := getQueryTags(session, uids)
+		if err != nil {
+			return err
+		}
+		for i := range dtos {
+			dtos[i].Tags = tagsOrEmpty(tags[dtos[i].UID])
+		}
+
+		countBuilder := db.SQLBuilder{}
+		countBuilder.Write(`SELECT COUNT(*) `)
+		writeLibraryFiltersSQL(query, user, canReadAllTeams, datasourceFilter, s.store, &countBuilder)
+		_, err = session.SQL(countBuilder.GetSQLString(), countBuilder.GetParams()...).Get(&totalCount)
+		return err
+	})
+
+	if err != nil {
+		return QueryLibrarySearchResult{}, err
+	}
+
+	response := QueryLibrarySearchResult{
+		Queries:    dtos,
+		TotalCount: totalCount,
+		Page:       query.Page,
+		PerPage:    query.Limit,
+	}
+
+	return response, nil
+}
+
+// exportQueries returns the queries of the user in query history, from the oldest to the most recent one
+func (s QueryHistoryService) exportQueries(ctx context.Context, user *user.SignedInUser, datasourceUIDs []string) (QueryHistoryExport, error) {
+	export := QueryHistoryExport{Queries: make([]ExportedQuery, 0)}
+
+	err := s.store.WithDbSession(ctx, func(session *db.Session) error {
+		var queries []QueryHistory
+		sess := session.Where("org_id = ? AND created_by = ?", user.OrgID, user.UserID)
+		if len(datasourceUIDs) > 0 {
+			sess = sess.In("datasource_uid", datasourceUIDs)
+		}
+		if err := sess.OrderBy("created_at ASC, id ASC").Find(&queries); err != nil {
+			return err
+		}
+
+		var starredUIDs []string
+		if err := session.Table("query_history_star").Cols("query_uid").Where("user_id = ?", user.UserID).Find(&starredUIDs); err != nil {
+			return err
+		}
+		starred := make(map[string]bool, len(starredUIDs))
+		for _, uid := range starredUIDs {
+			starred[uid] = true
+		}
+
+		uids := make([]string, 0, len(queries))
+		for _, query := range queries {
+			uids = append(uids, query.UID)
+		}
+		tags, err := getQueryTags(session, uids)
+		if err != nil {
+			return err
+		}
+
+		for _, query := range queries {
+			export.Queries = append(export.Queries, ExportedQuery{
+				DatasourceUID: query.DatasourceUID,
+				CreatedAt:     query.CreatedAt,
+				Comment:       query.Comment,
+				Queries:       query.Queries,
+				Starred:       starred[query.UID],
+				Tags:          tagsOrEmpty(tags[query.UID]),
+			})
+		}
+		return nil
+	})
+
+	if err != nil {
+		return QueryHistoryExport{}, err
+	}
+
+	return export, nil
+}
+
+// importQueries adds exported queries into the query history of the user. Either all queries are imported, or none.
+func (s QueryHistoryService) importQueries(ctx context.Context, user *user.SignedInUser, cmd ImportQueriesInQueryHistoryCommand) (int, error) {
+	if len(cmd.Queries) > maxImportedQueries {
+		return 0, ErrTooManyImportedQueries
+	}
+
+	tags := make([][]string, len(cmd.Queries))
+	for i, query := range cmd.Queries {
+		if query.Queries == nil {
+			return 0, ErrImportedQueryHasNoQuery
+		}
+
+		var err error
+		tags[i], err = normalizeTags(query.Tags)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	err := s.store.WithTransactionalDbSession(ctx, func(session *db.Session) error {
+		for i, query := range cmd.Queries {
+			queryHistory := QueryHistory{
+				OrgID:         user.OrgID,
+				UID:           util.GenerateShortUID(),
+				Queries:       query.Queries,
+				DatasourceUID: query.DatasourceUID,
+				CreatedBy:     user.UserID,
+				CreatedAt:     query.CreatedAt,
+				Comment:       query.Comment,
+			}
+			if queryHistory.CreatedAt <= 0 {
+				queryHistory.CreatedAt = s.now().Unix()
+			}
+
+			if _, err := session.Insert(&queryHistory); err != nil {
+				return err
+			}
+
+			if query.Starred {
+				if _, err := session.Insert(&QueryHistoryStar{UserID: user.UserID, QueryUID: queryHistory.UID}); err != nil {
+					return err
+				}
+			}
+
+			if err := insertTags(session, queryHistory.UID, tags[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return len(cmd.Queries), nil
+}
+
+// normalizeTags trims the tags of a query and removes the duplicates
+func normalizeTags(tags []string) ([]string, error) {
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || utf8.RuneCountInString(tag) > maxTagLength {
+			return nil, ErrInvalidTag
+		}
+		if !slices.Contains(normalized, tag) {
+			normalized = append(normalized, tag)
+		}
+	}
+
+	if len(normalized) > maxTags {
+		return nil, ErrTooManyTags
+	}
+
+	return normalized, nil
+}
+
+// uniqueTags removes the empty and duplicate tags of a search
+func uniqueTags(tags []string) []string {
+	unique := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag != "" && !slices.Contains(unique, tag) {
+			unique = append(unique, tag)
+		}
+	}
+	return unique
+}
+
+func insertTags(session *db.Session, UID string, tags []string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	rows := make([]QueryHistoryTag, 0, len(tags))
+	for _, tag := range tags {
+		rows = append(rows, QueryHistoryTag{QueryUID: UID, Tag: tag})
+	}
+	_, err := session.InsertMulti(&rows)
+	return err
+}
+
+// getQueryTags returns the sorted tags of the queries by their UID. The queries are fetched in batches
+// to stay below the limit of parameters of the database.
+func getQueryTags(session *db.Session, uids []string) (map[string][]string, error) {
+	tags := make(map[string][]string, len(uids))
+	for start := 0; start < len(uids); start += queryUIDsBatchSize {
+		end := min(start+queryUIDsBatchSize, len(uids))
+
+		var rows []QueryHistoryTag
+		if err := session.In("query_uid", uids[start:end]).OrderBy("tag ASC").Find(&rows); err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			tags[row.QueryUID] = append(tags[row.QueryUID], row.Tag)
+		}
+	}
+	return tags, nil
+}
+
+// getTagsAndPublished returns the tags of a query, and whether it is published to the library
+func getTagsAndPublished(session *db.Session, orgID int64, UID string) ([]string, bool, error) {
+	tags, err := getQueryTags(session, []string{UID})
+	if err != nil {
+		return nil, false, err
+	}
+
+	published, err := session.Where("org_id = ? AND query_uid = ?", orgID, UID).Exist(&QueryLibraryEntry{})
+	if err != nil {
+		return nil, false, err
+	}
+
+	return tagsOrEmpty(tags[UID]), published, nil
+}
+
+// fillTagsAndPublished sets the tags of the queries found by a search, and whether they are published
+// to the library
+func fillTagsAndPublished(session *db.Session, dtos []QueryHistoryDTO) error {
+	uids := make([]string, 0, len(dtos))
+	for _, dto := range dtos {
+		uids = append(uids, dto.UID)
+	}
+
+	tags, err := getQueryTags(session, uids)
+	if err != nil {
+		return err
+	}
+
+	var publishedUIDs []string
+	for start := 0; start < len(uids); start += queryUIDsBatchSize {
+		end := min(start+queryUIDsBatchSize, len(uids))
+		if err := session.Table("query_library").Cols("query_uid").In("query_uid", uids[start:end]).Find(&publishedUIDs); err != nil {
+			return err
+		}
+	}
+
+	for i := range dtos {
+		dtos[i].Tags = tagsOrEmpty(tags[dtos[i].UID])
+		dtos[i].Published = slices.Contains(publishedUIDs, dtos[i].UID)
+	}
+	return nil
+}
+
+func tagsOrEmpty(tags []string) []string {
+	if tags == nil {
+		return []string{}
+	}
+	return tags
+}
+
+// deleteOrphanedTags removes the tags of the queries deleted from query history
+func deleteOrphanedTags(session *db.Session) error {
+	_, err := session.Exec("DELETE FROM query_history_tag WHERE query_uid NOT IN (SELECT uid FROM query_history)")
+	return err
+}
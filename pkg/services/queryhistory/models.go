@@ -10,6 +10,25 @@ var (
 	ErrQueryNotFound        = errors.New("query in query history not found")
 	ErrStarredQueryNotFound = errors.New("starred query not found")
 	ErrQueryAlreadyStarred  = errors.New("query was already starred")
+
+	ErrInvalidTag              = errors.New("tags must be between 1 and 50 characters long")
+	ErrTooManyTags             = errors.New("too many tags")
+	ErrQueryAlreadyPublished   = errors.New("query was already published")
+	ErrPublishedQueryNotFound  = errors.New("published query not found")
+	ErrNotTeamMember           = errors.New("queries can only be published to the teams of the user")
+	ErrTooManyImportedQueries  = errors.New("too many queries to import")
+	ErrImportedQueryHasNoQuery = errors.New("imported queries must have queries")
+)
+
+const (
+	// maxTags is the maximum number of tags of a query in query history.
+	maxTags = 20
+	// maxTagLength is the length of the tag column of query_history_tag.
+	maxTagLength = 50
+	// maxImportedQueries is the maximum number of queries imported by a request.
+	maxImportedQueries = 1000
+	// queryUIDsBatchSize is the number of queries whose tags are fetched by a single statement.
+	queryUIDsBatchSize = 500
 )
 
 // QueryHistory is the model for query history definitions
@@ -31,8 +50,29 @@ type QueryHistoryStar struct {
 	UserID   int64  `xorm:"user_id"`
 }
 
+// QueryHistoryTag is the model for the tags of queries in query history
+type QueryHistoryTag struct {
+	ID       int64  `xorm:"pk autoincr 'id'"`
+	QueryUID string `xorm:"query_uid"`
+	Tag      string `xorm:"tag"`
+}
+
+// QueryLibraryEntry is the model for the queries published to the library of the organization,
+// or to the library of a team when TeamID is set.
+type QueryLibraryEntry struct {
+	ID          int64  `xorm:"pk autoincr 'id'"`
+	QueryUID    string `xorm:"query_uid"`
+	OrgID       int64  `xorm:"org_id"`
+	TeamID      int64  `xorm:"team_id"`
+	PublishedBy int64  `xorm:"published_by"`
+	PublishedAt int64  `xorm:"published_at"`
+}
+
+func (QueryLibraryEntry) TableName() string { return "query_library" }
+
 type SearchInQueryHistoryQuery struct {
 	DatasourceUIDs []string `json:"datasourceUids"`
+	Tags           []string `json:"tags"`
 	SearchString   string   `json:"searchString"`
 	OnlyStarred    bool     `json:"onlyStarred"`
 	Sort           string   `json:"sort"`
@@ -50,6 +90,8 @@ type QueryHistoryDTO struct {
 	Comment       string           `json:"comment"`
 	Queries       *simplejson.Json `json:"queries"`
 	Starred       bool             `json:"starred"`
+	Tags          []string         `json:"tags" xorm:"-"`
+	Published     bool             `json:"published" xorm:"-"`
 }
 
 // QueryHistoryResponse is a response struct for QueryHistoryDTO
@@ -91,3 +133,94 @@ type PatchQueryCommentInQueryHistoryCommand struct {
 	// Updated comment
 	Comment string `json:"comment"`
 }
+
+// SetQueryTagsInQueryHistoryCommand is the command for replacing the tags of a query in query history
+// swagger:model
+type SetQueryTagsInQueryHistoryCommand struct {
+	// Tags of the query, up to 20 tags of up to 50 characters.
+	// example: ["errors","checkout"]
+	Tags []string `json:"tags"`
+}
+
+// PublishQueryInQueryHistoryCommand is the command for publishing a query in query history to the library
+// swagger:model
+type PublishQueryInQueryHistoryCommand struct {
+	// ID of the team whose members can use the query, the query is published to the whole organization when it isn't set.
+	TeamID int64 `json:"teamId"`
+}
+
+type SearchInQueryLibraryQuery struct {
+	DatasourceUIDs []string `json:"datasourceUids"`
+	Tags           []string `json:"tags"`
+	SearchString   string   `json:"searchString"`
+	TeamID         int64    `json:"teamId"`
+	Sort           string   `json:"sort"`
+	Page           int      `json:"page"`
+	Limit          int      `json:"limit"`
+}
+
+// QueryLibraryDTO is a query published to the library
+type QueryLibraryDTO struct {
+	UID           string           `json:"uid" xorm:"uid"`
+	DatasourceUID string           `json:"datasourceUid" xorm:"datasource_uid"`
+	CreatedBy     int64            `json:"createdBy"`
+	CreatedAt     int64            `json:"createdAt"`
+	Comment       string           `json:"comment"`
+	Queries       *simplejson.Json `json:"queries"`
+	Tags          []string         `json:"tags" xorm:"-"`
+	TeamID        int64            `json:"teamId" xorm:"team_id"`
+	PublishedBy   int64            `json:"publishedBy"`
+	PublishedAt   int64            `json:"publishedAt"`
+}
+
+// QueryLibraryResponse is a response struct for QueryLibraryDTO
+type QueryLibraryResponse struct {
+	Result QueryLibraryDTO `json:"result"`
+}
+
+type QueryLibrarySearchResult struct {
+	TotalCount int               `json:"totalCount"`
+	Queries    []QueryLibraryDTO `json:"queries"`
+	Page       int               `json:"page"`
+	PerPage    int               `json:"perPage"`
+}
+
+type QueryLibrarySearchResponse struct {
+	Result QueryLibrarySearchResult `json:"result"`
+}
+
+// ExportedQuery is a query exported from query history, which can be imported by other users
+// or in other instances.
+// swagger:model
+type ExportedQuery struct {
+	// UID of the data source of the queries.
+	// example: PE1C5CBDA0504A6A3
+	DatasourceUID string `json:"datasourceUid"`
+	// Unix time the query was added at, the time of the import when it isn't set.
+	CreatedAt int64  `json:"createdAt"`
+	Comment   string `json:"comment"`
+	// The JSON model of queries.
+	// required: true
+	Queries *simplejson.Json `json:"queries"`
+	Starred bool             `json:"starred"`
+	Tags    []string         `json:"tags"`
+}
+
+// QueryHistoryExport is the export of the query history of a user
+// swagger:model
+type QueryHistoryExport struct {
+	Queries []ExportedQuery `json:"queries"`
+}
+
+// ImportQueriesInQueryHistoryCommand is the command for importing queries in query history
+// swagger:model
+type ImportQueriesInQueryHistoryCommand struct {
+	// Queries to import, up to 1000 per request.
+	Queries []ExportedQuery `json:"queries"`
+}
+
+// QueryHistoryImportResponse is the response struct for importing queries in query history
+type QueryHistoryImportResponse struct {
+	Imported int    `json:"imported"`
+	Message  string `json:"message"`
+}
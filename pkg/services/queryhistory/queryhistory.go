@@ -7,25 +7,30 @@ import (
 	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
-func ProvideService(cfg *setting.Cfg, sqlStore db.DB, routeRegister routing.RouteRegister) *QueryHistoryService {
+func ProvideService(cfg *setting.Cfg, sqlStore db.DB, routeRegister routing.RouteRegister, accessControl ac.AccessControl, accesscontrolService ac.Service) (*QueryHistoryService, error) {
 	s := &QueryHistoryService{
 		store:         sqlStore,
 		Cfg:           cfg,
 		RouteRegister: routeRegister,
+		accessControl: accessControl,
 		log:           log.New("query-history"),
 		now:           time.Now,
 	}
 
 	// Register routes only when query history is enabled
 	if s.Cfg.QueryHistoryEnabled {
+		if err := declareFixedRoles(accesscontrolService); err != nil {
+			return nil, err
+		}
 		s.registerAPIEndpoints()
 	}
 
-	return s
+	return s, nil
 }
 
 type Service interface {
@@ -37,12 +42,19 @@ type Service interface {
 	UnstarQueryInQueryHistory(ctx context.Context, user *user.SignedInUser, UID string) (QueryHistoryDTO, error)
 	DeleteStaleQueriesInQueryHistory(ctx context.Context, olderThan int64) (int, error)
 	EnforceRowLimitInQueryHistory(ctx context.Context, limit int, starredQueries bool) (int, error)
+	SetQueryTagsInQueryHistory(ctx context.Context, user *user.SignedInUser, UID string, cmd SetQueryTagsInQueryHistoryCommand) (QueryHistoryDTO, error)
+	PublishQueryInQueryHistory(ctx context.Context, user *user.SignedInUser, UID string, cmd PublishQueryInQueryHistoryCommand) (QueryLibraryDTO, error)
+	UnpublishQueryInQueryHistory(ctx context.Context, user *user.SignedInUser, UID string) error
+	SearchInQueryLibrary(ctx context.Context, user *user.SignedInUser, query SearchInQueryLibraryQuery) (QueryLibrarySearchResult, error)
+	ExportQueriesFromQueryHistory(ctx context.Context, user *user.SignedInUser, datasourceUIDs []string) (QueryHistoryExport, error)
+	ImportQueriesInQueryHistory(ctx context.Context, user *user.SignedInUser, cmd ImportQueriesInQueryHistoryCommand) (int, error)
 }
 
 type QueryHistoryService struct {
 	store         db.DB
 	Cfg           *setting.Cfg
 	RouteRegister routing.RouteRegister
+	accessControl ac.AccessControl
 	log           log.Logger
 	now           func() time.Time
 }
@@ -78,3 +90,27 @@ func (s QueryHistoryService) DeleteStaleQueriesInQueryHistory(ctx context.Contex
 func (s QueryHistoryService) EnforceRowLimitInQueryHistory(ctx context.Context, limit int, starredQueries bool) (int, error) {
 	return s.enforceQueryHistoryRowLimit(ctx, limit, starredQueries)
 }
+
+func (s QueryHistoryService) SetQueryTagsInQueryHistory(ctx context.Context, user *user.SignedInUser, UID string, cmd SetQueryTagsInQueryHistoryCommand) (QueryHistoryDTO, error) {
+	return s.setQueryTags(ctx, user, UID, cmd)
+}
+
+func (s QueryHistoryService) PublishQueryInQueryHistory(ctx context.Context, user *user.SignedInUser, UID string, cmd PublishQueryInQueryHistoryCommand) (QueryLibraryDTO, error) {
+	return s.publishQuery(ctx, user, UID, cmd)
+}
+
+func (s QueryHistoryService) UnpublishQueryInQueryHistory(ctx context.Context, user *user.SignedInUser, UID string) error {
+	return s.unpublishQuery(ctx, user, UID)
+}
+
+func (s QueryHistoryService) SearchInQueryLibrary(ctx context.Context, user *user.SignedInUser, query SearchInQueryLibraryQuery) (QueryLibrarySearchResult, error) {
+	return s.searchLibrary(ctx, user, query)
+}
+
+func (s QueryHistoryService) ExportQueriesFromQueryHistory(ctx context.Context, user *user.SignedInUser, datasourceUIDs []string) (QueryHistoryExport, error) {
+	return s.exportQueries(ctx, user, datasourceUIDs)
+}
+
+func (s QueryHistoryService) ImportQueriesInQueryHistory(ctx context.Context, user *user.SignedInUser, cmd ImportQueriesInQueryHistoryCommand) (int, error) {
+	return s.importQueries(ctx, user, cmd)
+}
@@ -33,6 +33,19 @@ func TestIntegrationDeleteStaleQueryFromQueryHistory(t *testing.T) {
 			require.Equal(t, 0, rowsDeleted)
 		})
 
+	testScenarioWithQueryInQueryHistory(t, "Stale published query history can not be deleted",
+		func(t *testing.T, sc scenarioContext) {
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			sc.reqContext.Req.Body = mockRequestBody(PublishQueryInQueryHistoryCommand{})
+			resp := sc.service.publishHandler(sc.reqContext)
+			require.Equal(t, 200, resp.Status())
+
+			olderThan := sc.service.now().Unix() + 60
+			rowsDeleted, err := sc.service.DeleteStaleQueriesInQueryHistory(context.Background(), olderThan)
+			require.NoError(t, err)
+			require.Equal(t, 0, rowsDeleted)
+		})
+
 	testScenarioWithQueryInQueryHistory(t, "Not stale query history is not deleted",
 		func(t *testing.T, sc scenarioContext) {
 			olderThan := sc.service.now().Unix() - 60
@@ -5,6 +5,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/web"
 )
 
 func TestIntegrationEnforceRowLimitInQueryHistory(t *testing.T) {
@@ -19,6 +21,19 @@ func TestIntegrationEnforceRowLimitInQueryHistory(t *testing.T) {
 			require.Equal(t, 1, rowsDeleted)
 		})
 
+	testScenarioWithQueryInQueryHistory(t, "Enforce limit for query_history does not delete published queries",
+		func(t *testing.T, sc scenarioContext) {
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			sc.reqContext.Req.Body = mockRequestBody(PublishQueryInQueryHistoryCommand{})
+			resp := sc.service.publishHandler(sc.reqContext)
+			require.Equal(t, 200, resp.Status())
+
+			limit := 0
+			rowsDeleted, err := sc.service.EnforceRowLimitInQueryHistory(context.Background(), limit, false)
+			require.NoError(t, err)
+			require.Equal(t, 0, rowsDeleted)
+		})
+
 	// In this scenario we have 2 starred queries and 1 not starred query
 	testScenarioWithMultipleQueriesInQueryHistory(t, "Enforce limit for unstarred queries in query_history",
 		func(t *testing.T, sc scenarioContext) {
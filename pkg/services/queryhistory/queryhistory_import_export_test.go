@@ -0,0 +1,118 @@
+package queryhistory
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func TestIntegrationExportQueriesFromQueryHistory(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	testScenario(t, "When user exports empty query history, it should return no queries",
+		func(t *testing.T, sc scenarioContext) {
+			resp := sc.service.exportHandler(sc.reqContext)
+			var export QueryHistoryExport
+			err := json.Unmarshal(resp.Body(), &export)
+			require.NoError(t, err)
+			require.Equal(t, 200, resp.Status())
+			require.Len(t, export.Queries, 0)
+		})
+
+	testScenarioWithMultipleQueriesInQueryHistory(t, "When user exports query history, it should return the queries from the oldest one",
+		func(t *testing.T, sc scenarioContext) {
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			sc.reqContext.Req.Body = mockRequestBody(SetQueryTagsInQueryHistoryCommand{Tags: []string{"errors"}})
+			sc.service.setTagsHandler(sc.reqContext)
+
+			resp := sc.service.exportHandler(sc.reqContext)
+			var export QueryHistoryExport
+			err := json.Unmarshal(resp.Body(), &export)
+			require.NoError(t, err)
+			require.Equal(t, 200, resp.Status())
+			require.Len(t, export.Queries, 3)
+			require.Equal(t, "test comment 2", export.Queries[0].Comment)
+			require.Equal(t, []string{"errors"}, export.Queries[0].Tags)
+			require.False(t, export.Queries[0].Starred)
+			require.True(t, export.Queries[1].Starred)
+		})
+
+	testScenarioWithMultipleQueriesInQueryHistory(t, "When user exports query history of a datasource, it should only return its queries",
+		func(t *testing.T, sc scenarioContext) {
+			sc.reqContext.Req.Form.Add("datasourceUid", testDsUID2)
+			resp := sc.service.exportHandler(sc.reqContext)
+			var export QueryHistoryExport
+			err := json.Unmarshal(resp.Body(), &export)
+			require.NoError(t, err)
+			require.Equal(t, 200, resp.Status())
+			require.Len(t, export.Queries, 1)
+			require.Equal(t, testDsUID2, export.Queries[0].DatasourceUID)
+		})
+}
+
+func TestIntegrationImportQueriesInQueryHistory(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	testScenario(t, "When user imports queries, it should add them to query history",
+		func(t *testing.T, sc scenarioContext) {
+			cmd := ImportQueriesInQueryHistoryCommand{
+				Queries: []ExportedQuery{
+					{
+						DatasourceUID: testDsUID1,
+						CreatedAt:     sc.service.now().Unix() - 60,
+						Comment:       "imported",
+						Queries:       simplejson.NewFromAny(map[string]any{"expr": "test"}),
+						Starred:       true,
+						Tags:          []string{"errors"},
+					},
+					{
+						DatasourceUID: testDsUID2,
+						Queries:       simplejson.NewFromAny(map[string]any{"expr": "test2"}),
+					},
+				},
+			}
+			sc.reqContext.Req.Body = mockRequestBody(cmd)
+			resp := sc.service.importHandler(sc.reqContext)
+			require.Equal(t, 200, resp.Status())
+
+			var response QueryHistoryImportResponse
+			err := json.Unmarshal(resp.Body(), &response)
+			require.NoError(t, err)
+			require.Equal(t, 2, response.Imported)
+
+			sc.reqContext.Req.Form.Add("onlyStarred", "true")
+			resp = sc.service.searchHandler(sc.reqContext)
+			var searchResponse QueryHistorySearchResponse
+			err = json.Unmarshal(resp.Body(), &searchResponse)
+			require.NoError(t, err)
+			require.Equal(t, 1, searchResponse.Result.TotalCount)
+			require.Equal(t, "imported", searchResponse.Result.QueryHistory[0].Comment)
+			require.Equal(t, []string{"errors"}, searchResponse.Result.QueryHistory[0].Tags)
+		})
+
+	testScenario(t, "When user imports queries without queries, it should fail",
+		func(t *testing.T, sc scenarioContext) {
+			cmd := ImportQueriesInQueryHistoryCommand{
+				Queries: []ExportedQuery{{DatasourceUID: testDsUID1}},
+			}
+			sc.reqContext.Req.Body = mockRequestBody(cmd)
+			resp := sc.service.importHandler(sc.reqContext)
+			require.Equal(t, 400, resp.Status())
+		})
+
+	testScenario(t, "When user imports too many queries, it should fail",
+		func(t *testing.T, sc scenarioContext) {
+			cmd := ImportQueriesInQueryHistoryCommand{
+				Queries: make([]ExportedQuery, maxImportedQueries+1),
+			}
+			sc.reqContext.Req.Body = mockRequestBody(cmd)
+			resp := sc.service.importHandler(sc.reqContext)
+			require.Equal(t, 400, resp.Status())
+		})
+}
@@ -0,0 +1,236 @@
+package queryhistory
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func TestIntegrationPublishQueryInQueryHistory(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	testScenarioWithQueryInQueryHistory(t, "When user tries to publish query in query history that does not exist, it should fail",
+		func(t *testing.T, sc scenarioContext) {
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": "abc123"})
+			sc.reqContext.Req.Body = mockRequestBody(PublishQueryInQueryHistoryCommand{})
+			resp := sc.service.publishHandler(sc.reqContext)
+			require.Equal(t, 404, resp.Status())
+		})
+
+	testScenarioWithQueryInQueryHistory(t, "When user tries to publish query in query history that exists, it should succeed",
+		func(t *testing.T, sc scenarioContext) {
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			sc.reqContext.Req.Body = mockRequestBody(PublishQueryInQueryHistoryCommand{})
+			resp := sc.service.publishHandler(sc.reqContext)
+			require.Equal(t, 200, resp.Status())
+
+			var response QueryLibraryResponse
+			err := json.Unmarshal(resp.Body(), &response)
+			require.NoError(t, err)
+			require.Equal(t, sc.initialResult.Result.UID, response.Result.UID)
+			require.Equal(t, testUserID, response.Result.PublishedBy)
+			require.Equal(t, int64(0), response.Result.TeamID)
+
+			resp = sc.service.searchHandler(sc.reqContext)
+			var searchResponse QueryHistorySearchResponse
+			err = json.Unmarshal(resp.Body(), &searchResponse)
+			require.NoError(t, err)
+			require.True(t, searchResponse.Result.QueryHistory[0].Published)
+		})
+
+	testScenarioWithQueryInQueryHistory(t, "When user tries to publish query that is already published, it should fail",
+		func(t *testing.T, sc scenarioContext) {
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			sc.reqContext.Req.Body = mockRequestBody(PublishQueryInQueryHistoryCommand{})
+			sc.service.publishHandler(sc.reqContext)
+			sc.reqContext.Req.Body = mockRequestBody(PublishQueryInQueryHistoryCommand{})
+			resp := sc.service.publishHandler(sc.reqContext)
+			require.Equal(t, 409, resp.Status())
+		})
+
+	testScenarioWithQueryInQueryHistory(t, "When user tries to publish query to a team they are not a member of, it should fail",
+		func(t *testing.T, sc scenarioContext) {
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			sc.reqContext.Req.Body = mockRequestBody(PublishQueryInQueryHistoryCommand{TeamID: 1})
+			resp := sc.service.publishHandler(sc.reqContext)
+			require.Equal(t, 403, resp.Status())
+		})
+}
+
+func TestIntegrationUnpublishQueryInQueryHistory(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	testScenarioWithQueryInQueryHistory(t, "When user tries to unpublish query that is not published, it should fail",
+		func(t *testing.T, sc scenarioContext) {
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			resp := sc.service.unpublishHandler(sc.reqContext)
+			require.Equal(t, 404, resp.Status())
+		})
+
+	testScenarioWithQueryInQueryHistory(t, "When user tries to unpublish query they published, it should succeed",
+		func(t *testing.T, sc scenarioContext) {
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			sc.reqContext.Req.Body = mockRequestBody(PublishQueryInQueryHistoryCommand{})
+			sc.service.publishHandler(sc.reqContext)
+			resp := sc.service.unpublishHandler(sc.reqContext)
+			require.Equal(t, 200, resp.Status())
+		})
+
+	testScenarioWithQueryInQueryHistory(t, "When user tries to unpublish query published by another user, it should fail unless they can manage the library",
+		func(t *testing.T, sc scenarioContext) {
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			sc.reqContext.Req.Body = mockRequestBody(PublishQueryInQueryHistoryCommand{})
+			sc.service.publishHandler(sc.reqContext)
+
+			sc.reqContext.SignedInUser.UserID = testUserID + 1
+			resp := sc.service.unpublishHandler(sc.reqContext)
+			require.Equal(t, 404, resp.Status())
+
+			sc.service.accessControl = actest.FakeAccessControl{ExpectedEvaluate: true}
+			resp = sc.service.unpublishHandler(sc.reqContext)
+			require.Equal(t, 200, resp.Status())
+		})
+
+	testScenarioWithQueryInQueryHistory(t, "When user deletes published query from query history, it should be unpublished",
+		func(t *testing.T, sc scenarioContext) {
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			sc.reqContext.Req.Body = mockRequestBody(PublishQueryInQueryHistoryCommand{})
+			sc.service.publishHandler(sc.reqContext)
+			resp := sc.service.deleteHandler(sc.reqContext)
+			require.Equal(t, 200, resp.Status())
+
+			resp = sc.service.searchLibraryHandler(sc.reqContext)
+			var response QueryLibrarySearchResponse
+			err := json.Unmarshal(resp.Body(), &response)
+			require.NoError(t, err)
+			require.Equal(t, 0, response.Result.TotalCount)
+		})
+}
+
+func TestIntegrationSearchInQueryLibrary(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	testScenarioWithMultipleQueriesInQueryHistory(t, "When user searches the query library, it should return the queries published to the organization",
+		func(t *testing.T, sc scenarioContext) {
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			sc.reqContext.Req.Body = mockRequestBody(PublishQueryInQueryHistoryCommand{})
+			sc.service.publishHandler(sc.reqContext)
+
+			resp := sc.service.searchLibraryHandler(sc.reqContext)
+			var response QueryLibrarySearchResponse
+			err := json.Unmarshal(resp.Body(), &response)
+			require.NoError(t, err)
+			require.Equal(t, 200, resp.Status())
+			require.Equal(t, 1, response.Result.TotalCount)
+			require.Equal(t, sc.initialResult.Result.UID, response.Result.Queries[0].UID)
+			require.Equal(t, "test comment 2", response.Result.Queries[0].Comment)
+		})
+
+	testScenarioWithMultipleQueriesInQueryHistory(t, "When user searches the query library by datasource, tag and text, it should return the matching queries",
+		func(t *testing.T, sc scenarioContext) {
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			sc.reqContext.Req.Body = mockRequestBody(SetQueryTagsInQueryHistoryCommand{Tags: []string{"errors"}})
+			sc.service.setTagsHandler(sc.reqContext)
+			sc.reqContext.Req.Body = mockRequestBody(PublishQueryInQueryHistoryCommand{})
+			sc.service.publishHandler(sc.reqContext)
+
+			sc.reqContext.Req.Form.Add("datasourceUid", testDsUID1)
+			sc.reqContext.Req.Form.Add("tag", "errors")
+			sc.reqContext.Req.Form.Add("searchString", "comment")
+			resp := sc.service.searchLibraryHandler(sc.reqContext)
+			var response QueryLibrarySearchResponse
+			err := json.Unmarshal(resp.Body(), &response)
+			require.NoError(t, err)
+			require.Equal(t, 200, resp.Status())
+			require.Equal(t, 1, response.Result.TotalCount)
+			require.Equal(t, []string{"errors"}, response.Result.Queries[0].Tags)
+
+			sc.reqContext.Req.Form.Set("datasourceUid", testDsUID2)
+			resp = sc.service.searchLibraryHandler(sc.reqContext)
+			var otherDatasourceResponse QueryLibrarySearchResponse
+			err = json.Unmarshal(resp.Body(), &otherDatasourceResponse)
+			require.NoError(t, err)
+			require.Equal(t, 0, otherDatasourceResponse.Result.TotalCount)
+		})
+
+	testScenarioWithQueryInQueryHistory(t, "When user searches the query library, it should only return the queries published to their teams",
+		func(t *testing.T, sc scenarioContext) {
+			sc.reqContext.SignedInUser.Teams = []int64{1}
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			sc.reqContext.Req.Body = mockRequestBody(PublishQueryInQueryHistoryCommand{TeamID: 1})
+			resp := sc.service.publishHandler(sc.reqContext)
+			require.Equal(t, 200, resp.Status())
+
+			resp = sc.service.searchLibraryHandler(sc.reqContext)
+			var response QueryLibrarySearchResponse
+			err := json.Unmarshal(resp.Body(), &response)
+			require.NoError(t, err)
+			require.Equal(t, 1, response.Result.TotalCount)
+			require.Equal(t, int64(1), response.Result.Queries[0].TeamID)
+
+			sc.reqContext.SignedInUser.Teams = []int64{2}
+			resp = sc.service.searchLibraryHandler(sc.reqContext)
+			var otherTeamResponse QueryLibrarySearchResponse
+			err = json.Unmarshal(resp.Body(), &otherTeamResponse)
+			require.NoError(t, err)
+			require.Equal(t, 0, otherTeamResponse.Result.TotalCount)
+
+			// unpublishing the queries of other users does not allow searching the queries of any team
+			sc.service.accessControl = acimpl.ProvideAccessControl(setting.NewCfg())
+			sc.reqContext.SignedInUser.Permissions = map[int64]map[string][]string{testOrgID: {ActionLibraryDelete: {}, datasources.ActionQuery: {datasources.ScopeAll}}}
+			resp = sc.service.searchLibraryHandler(sc.reqContext)
+			var deleterResponse QueryLibrarySearchResponse
+			err = json.Unmarshal(resp.Body(), &deleterResponse)
+			require.NoError(t, err)
+			require.Equal(t, 0, deleterResponse.Result.TotalCount)
+
+			sc.reqContext.SignedInUser.Permissions = map[int64]map[string][]string{testOrgID: {ActionLibraryTeamsRead: {}, datasources.ActionQuery: {datasources.ScopeAll}}}
+			resp = sc.service.searchLibraryHandler(sc.reqContext)
+			var managerResponse QueryLibrarySearchResponse
+			err = json.Unmarshal(resp.Body(), &managerResponse)
+			require.NoError(t, err)
+			require.Equal(t, 1, managerResponse.Result.TotalCount)
+		})
+
+	testScenarioWithQueryInQueryHistory(t, "When user searches the query library, it should only return the queries of the datasources they can query",
+		func(t *testing.T, sc scenarioContext) {
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			sc.reqContext.Req.Body = mockRequestBody(PublishQueryInQueryHistoryCommand{})
+			resp := sc.service.publishHandler(sc.reqContext)
+			require.Equal(t, 200, resp.Status())
+
+			sc.reqContext.SignedInUser.Permissions = map[int64]map[string][]string{testOrgID: {datasources.ActionQuery: {datasources.ScopeProvider.GetResourceScopeUID(testDsUID1)}}}
+			resp = sc.service.searchLibraryHandler(sc.reqContext)
+			var response QueryLibrarySearchResponse
+			err := json.Unmarshal(resp.Body(), &response)
+			require.NoError(t, err)
+			require.Equal(t, 1, response.Result.TotalCount)
+
+			sc.reqContext.SignedInUser.Permissions = map[int64]map[string][]string{testOrgID: {datasources.ActionQuery: {datasources.ScopeProvider.GetResourceScopeUID(testDsUID2)}}}
+			resp = sc.service.searchLibraryHandler(sc.reqContext)
+			var otherDatasourceResponse QueryLibrarySearchResponse
+			err = json.Unmarshal(resp.Body(), &otherDatasourceResponse)
+			require.NoError(t, err)
+			require.Equal(t, 200, resp.Status())
+			require.Equal(t, 0, otherDatasourceResponse.Result.TotalCount)
+			require.Empty(t, otherDatasourceResponse.Result.Queries)
+
+			sc.reqContext.SignedInUser.Permissions = map[int64]map[string][]string{testOrgID: {}}
+			resp = sc.service.searchLibraryHandler(sc.reqContext)
+			var noDatasourceResponse QueryLibrarySearchResponse
+			err = json.Unmarshal(resp.Body(), &noDatasourceResponse)
+			require.NoError(t, err)
+			require.Equal(t, 200, resp.Status())
+			require.Equal(t, 0, noDatasourceResponse.Result.TotalCount)
+		})
+}
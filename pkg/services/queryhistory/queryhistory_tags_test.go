@@ -0,0 +1,90 @@
+package queryhistory
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func TestIntegrationSetQueryTagsInQueryHistory(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	testScenarioWithQueryInQueryHistory(t, "When user tries to set tags of query in query history that does not exist, it should fail",
+		func(t *testing.T, sc scenarioContext) {
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": "abc123"})
+			sc.reqContext.Req.Body = mockRequestBody(SetQueryTagsInQueryHistoryCommand{Tags: []string{"errors"}})
+			resp := sc.service.setTagsHandler(sc.reqContext)
+			require.Equal(t, 404, resp.Status())
+		})
+
+	testScenarioWithQueryInQueryHistory(t, "When user tries to set tags of query in query history that exists, it should succeed",
+		func(t *testing.T, sc scenarioContext) {
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			sc.reqContext.Req.Body = mockRequestBody(SetQueryTagsInQueryHistoryCommand{Tags: []string{" errors ", "checkout", "errors"}})
+			resp := sc.service.setTagsHandler(sc.reqContext)
+			result := validateAndUnMarshalResponse(t, resp)
+			require.Equal(t, []string{"checkout", "errors"}, result.Result.Tags)
+
+			sc.reqContext.Req.Body = mockRequestBody(SetQueryTagsInQueryHistoryCommand{Tags: []string{"latency"}})
+			resp = sc.service.setTagsHandler(sc.reqContext)
+			result = validateAndUnMarshalResponse(t, resp)
+			require.Equal(t, []string{"latency"}, result.Result.Tags)
+		})
+
+	testScenarioWithQueryInQueryHistory(t, "When user tries to set invalid tags of query in query history, it should fail",
+		func(t *testing.T, sc scenarioContext) {
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			sc.reqContext.Req.Body = mockRequestBody(SetQueryTagsInQueryHistoryCommand{Tags: []string{" "}})
+			resp := sc.service.setTagsHandler(sc.reqContext)
+			require.Equal(t, 400, resp.Status())
+
+			sc.reqContext.Req.Body = mockRequestBody(SetQueryTagsInQueryHistoryCommand{Tags: []string{strings.Repeat("a", maxTagLength+1)}})
+			resp = sc.service.setTagsHandler(sc.reqContext)
+			require.Equal(t, 400, resp.Status())
+		})
+
+	testScenarioWithQueryInQueryHistory(t, "When user tries to set too many tags of query in query history, it should fail",
+		func(t *testing.T, sc scenarioContext) {
+			tags := make([]string, 0, maxTags+1)
+			for i := 0; i <= maxTags; i++ {
+				tags = append(tags, strings.Repeat("a", i+1))
+			}
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			sc.reqContext.Req.Body = mockRequestBody(SetQueryTagsInQueryHistoryCommand{Tags: tags})
+			resp := sc.service.setTagsHandler(sc.reqContext)
+			require.Equal(t, 400, resp.Status())
+		})
+
+	testScenarioWithMultipleQueriesInQueryHistory(t, "When user searches for queries by tags, it should return the queries with all tags",
+		func(t *testing.T, sc scenarioContext) {
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			sc.reqContext.Req.Body = mockRequestBody(SetQueryTagsInQueryHistoryCommand{Tags: []string{"errors", "checkout"}})
+			resp := sc.service.setTagsHandler(sc.reqContext)
+			require.Equal(t, 200, resp.Status())
+
+			sc.reqContext.Req.Form.Add("tag", "errors")
+			sc.reqContext.Req.Form.Add("tag", "checkout")
+			resp = sc.service.searchHandler(sc.reqContext)
+			var response QueryHistorySearchResponse
+			err := json.Unmarshal(resp.Body(), &response)
+			require.NoError(t, err)
+			require.Equal(t, 200, resp.Status())
+			require.Len(t, response.Result.QueryHistory, 1)
+			require.Equal(t, sc.initialResult.Result.UID, response.Result.QueryHistory[0].UID)
+			require.Equal(t, []string{"checkout", "errors"}, response.Result.QueryHistory[0].Tags)
+			require.Equal(t, 1, response.Result.TotalCount)
+
+			sc.reqContext.Req.Form.Add("tag", "latency")
+			resp = sc.service.searchHandler(sc.reqContext)
+			var noTagsResponse QueryHistorySearchResponse
+			err = json.Unmarshal(resp.Body(), &noTagsResponse)
+			require.NoError(t, err)
+			require.Equal(t, 200, resp.Status())
+			require.Equal(t, 0, noTagsResponse.Result.TotalCount)
+		})
+}
@@ -15,7 +15,9 @@ import (
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/org/orgimpl"
 	"github.com/grafana/grafana/pkg/services/quota/quotatest"
@@ -57,9 +59,10 @@ func testScenario(t *testing.T, desc string, fn func(t *testing.T, sc scenarioCo
 		ctx.Req.Header.Add("Content-Type", "application/json")
 		sqlStore := db.InitTestDB(t)
 		service := QueryHistoryService{
-			Cfg:   setting.NewCfg(),
-			store: sqlStore,
-			now:   time.Now,
+			Cfg:           setting.NewCfg(),
+			store:         sqlStore,
+			accessControl: actest.FakeAccessControl{},
+			now:           time.Now,
 		}
 		service.Cfg.QueryHistoryEnabled = true
 		quotaService := quotatest.New(false, nil)
@@ -76,6 +79,9 @@ func testScenario(t *testing.T, desc string, fn func(t *testing.T, sc scenarioCo
 			OrgID:      testOrgID,
 			OrgRole:    org.RoleViewer,
 			LastSeenAt: service.now(),
+			Permissions: map[int64]map[string][]string{
+				testOrgID: {datasources.ActionQuery: {datasources.ScopeAll}},
+			},
 		}
 
 		_, err = usrSvc.Create(context.Background(), &user.CreateUserCommand{
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/grafana/grafana/pkg/infra/db"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/user"
 )
 
@@ -45,6 +46,63 @@ func writeFiltersSQL(query SearchInQueryHistoryQuery, user *user.SignedInUser, s
 		sql.WriteString(" AND query_history.datasource_uid IN (? " + strings.Repeat(",?", len(query.DatasourceUIDs)-1) + ") ")
 	}
 	builder.Write(sql.String(), params...)
+	writeTagsFilterSQL(query.Tags, builder)
+}
+
+// writeTagsFilterSQL filters the queries having all the tags, which must be unique
+func writeTagsFilterSQL(tags []string, builder *db.SQLBuilder) {
+	if len(tags) == 0 {
+		return
+	}
+
+	params := make([]any, 0, len(tags)+1)
+	for _, tag := range tags {
+		params = append(params, tag)
+	}
+	params = append(params, len(tags))
+	builder.Write(" AND (SELECT COUNT(*) FROM query_history_tag WHERE query_history_tag.query_uid = query_history.uid AND query_history_tag.tag IN (? "+strings.Repeat(",?", len(tags)-1)+")) = ? ", params...)
+}
+
+func writeLibraryFiltersSQL(query SearchInQueryLibraryQuery, user *user.SignedInUser, canReadAllTeams bool, datasourceFilter ac.SQLFilter, sqlStore db.DB, builder *db.SQLBuilder) {
+	params := []any{user.OrgID, "%" + query.SearchString + "%", "%" + query.SearchString + "%"}
+	var sql bytes.Buffer
+	sql.WriteString(" FROM query_library INNER JOIN query_history ON query_history.uid = query_library.query_uid ")
+	sql.WriteString(" WHERE query_library.org_id = ? AND (query_history.queries " + sqlStore.GetDialect().LikeStr() + " ? OR query_history.comment " + sqlStore.GetDialect().LikeStr() + " ?) ")
+
+	// Queries published to the organization have no team
+	if !canReadAllTeams {
+		params = append(params, 0)
+		for _, teamID := range user.Teams {
+			params = append(params, teamID)
+		}
+		sql.WriteString(" AND query_library.team_id IN (? " + strings.Repeat(",?", len(user.Teams)) + ") ")
+	}
+
+	// Published queries are only returned for the data sources the user can query
+	params = append(params, datasourceFilter.Args...)
+	sql.WriteString(" AND" + datasourceFilter.Where + " ")
+
+	if query.TeamID > 0 {
+		params = append(params, query.TeamID)
+		sql.WriteString(" AND query_library.team_id = ? ")
+	}
+
+	if len(query.DatasourceUIDs) > 0 {
+		for _, uid := range query.DatasourceUIDs {
+			params = append(params, uid)
+		}
+		sql.WriteString(" AND query_history.datasource_uid IN (? " + strings.Repeat(",?", len(query.DatasourceUIDs)-1) + ") ")
+	}
+	builder.Write(sql.String(), params...)
+	writeTagsFilterSQL(query.Tags, builder)
+}
+
+func writeLibrarySortSQL(query SearchInQueryLibraryQuery, sqlStore db.DB, builder *db.SQLBuilder) {
+	if query.Sort == "time-asc" {
+		builder.Write(" ORDER BY query_library.published_at ASC ")
+	} else {
+		builder.Write(" ORDER BY query_library.published_at DESC ")
+	}
 }
 
 func writeSortSQL(query SearchInQueryHistoryQuery, sqlStore db.DB, builder *db.SQLBuilder) {
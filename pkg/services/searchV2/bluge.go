@@ -35,6 +35,11 @@ const (
 	documentFieldDSType      = "ds_type"
 	DocumentFieldCreatedAt   = "created_at"
 	DocumentFieldUpdatedAt   = "updated_at"
+
+	documentFieldDescription    = "description"
+	documentFieldQuery          = "query"          // query text of the panels and variables
+	documentFieldTransformation = "transformation" // transformation ids and options
+	documentFieldVariable       = "variable"       // template variable names and definitions
 )
 
 func initOrgIndex(dashboards []dashboard, logger log.Logger, extendDoc ExtendDashboardFunc) (*orgIndex, error) {
@@ -185,9 +190,22 @@ func getNonFolderDashboardDoc(dash dashboard, location string) *bluge.Document {
 		}
 	}
 
+	// the dashboard matches the queries of all its panels and variables
+	for _, panel := range dash.content.panels {
+		addExpressionFields(doc, documentFieldQuery, panel.queries)
+	}
+	addExpressionFields(doc, documentFieldQuery, dash.content.variables)
+	addExpressionFields(doc, documentFieldVariable, dash.content.variables)
+
 	return doc
 }
 
+func addExpressionFields(doc *bluge.Document, field string, values []string) {
+	for _, v := range values {
+		doc.AddField(bluge.NewTextField(field, v).WithAnalyzer(expressionAnalyzer))
+	}
+}
+
 func getDashboardPanelDocs(dash dashboard, location string) []*bluge.Document {
 	dashURL := fmt.Sprintf("/d/%s/%s", dash.uid, slugify.Slugify(dash.summary.Name))
 
@@ -233,6 +251,10 @@ func getDashboardPanelDocs(dash dashboard, location string) []*bluge.Document {
 			}
 		}
 
+		content := dash.content.panels[int64(panelId)]
+		addExpressionFields(doc, documentFieldQuery, content.queries)
+		addExpressionFields(doc, documentFieldTransformation, content.transformations)
+
 		docs = append(docs, doc)
 	}
 	return docs
@@ -252,6 +274,9 @@ func newSearchDocument(uid string, name string, descr string, url string) *bluge
 			doc.AddField(bluge.NewKeywordField(documentFieldName_sort, sortStr).Sortable())
 		}
 	}
+	if descr != "" {
+		doc.AddField(bluge.NewTextField(documentFieldDescription, descr))
+	}
 	if url != "" {
 		doc.AddField(bluge.NewKeywordField(documentFieldURL, url).StoreValue())
 	}
@@ -447,6 +472,18 @@ func doSearchQuery(
 		hasConstraints = true
 	}
 
+	// Field-qualified terms, eg. query:node_cpu
+	var qualifiedTerms []qualifiedTerm
+	q.Query, qualifiedTerms = parseSearchQuery(q.Query)
+	for _, term := range qualifiedTerms {
+		termQuery := newQualifiedTermQuery(term)
+		if termQuery == nil {
+			termQuery = bluge.NewMatchNoneQuery()
+		}
+		fullQuery.AddMust(termQuery)
+		hasConstraints = true
+	}
+
 	isMatchAllQuery := q.Query == "*" || q.Query == ""
 	if isMatchAllQuery {
 		if !hasConstraints {
@@ -0,0 +1,143 @@
+package searchV2
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/blugelabs/bluge/analysis"
+	"github.com/blugelabs/bluge/analysis/token"
+	"github.com/blugelabs/bluge/analysis/tokenizer"
+)
+
+// Keys of a panel target holding the query text for the core data sources,
+// eg. PromQL and LogQL in `expr`, SQL in `rawSql` and Graphite in `target`.
+var queryTextKeys = []string{"expr", "expression", "query", "rawSql", "rawQuery", "target", "queryText"}
+
+// dashboardContent is the text of a dashboard that is indexed for full-text
+// search, but is not part of its entity summary.
+type dashboardContent struct {
+	// names and definitions of the template variables
+	variables []string
+	panels    map[int64]panelContent
+}
+
+type panelContent struct {
+	queries         []string
+	transformations []string
+}
+
+type contentDashboard struct {
+	Panels     []contentPanel `json:"panels"`
+	Templating struct {
+		List []map[string]any `json:"list"`
+	} `json:"templating"`
+}
+
+type contentPanel struct {
+	ID              int64            `json:"id"`
+	Targets         []map[string]any `json:"targets"`
+	Transformations []map[string]any `json:"transformations"`
+	// Rows define panels as sub objects
+	Panels []contentPanel `json:"panels"`
+}
+
+// readDashboardContent extracts the panel queries, transformations and template
+// variable definitions of a dashboard. A dashboard that can't be parsed has no content.
+func readDashboardContent(body []byte) dashboardContent {
+	content := dashboardContent{panels: map[int64]panelContent{}}
+
+	var dash contentDashboard
+	if err := json.Unmarshal(body, &dash); err != nil {
+		return content
+	}
+
+	var addPanels func(panels []contentPanel)
+	addPanels = func(panels []contentPanel) {
+		for _, panel := range panels {
+			pc := panelContent{}
+			for _, target := range panel.Targets {
+				for _, key := range queryTextKeys {
+					if v, ok := target[key].(string); ok && strings.TrimSpace(v) != "" {
+						pc.queries = append(pc.queries, v)
+					}
+				}
+			}
+			for _, transformation := range panel.Transformations {
+				pc.transformations = appendStrings(pc.transformations, transformation["id"], transformation["options"])
+			}
+			content.panels[panel.ID] = pc
+			addPanels(panel.Panels)
+		}
+	}
+	addPanels(dash.Panels)
+
+	for _, variable := range dash.Templating.List {
+		content.variables = appendStrings(content.variables,
+			variable["name"], variable["query"], variable["definition"], variable["regex"])
+	}
+
+	return content
+}
+
+// appendStrings appends the non-empty strings found in values, walking nested maps and slices.
+func appendStrings(dst []string, values ...any) []string {
+	for _, value := range values {
+		switch v := value.(type) {
+		case string:
+			if strings.TrimSpace(v) != "" {
+				dst = append(dst, v)
+			}
+		case []any:
+			dst = appendStrings(dst, v...)
+		case map[string]any:
+			// sort the keys so documents are stable
+			keys := make([]string, 0, len(v))
+			for k := range v {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				dst = appendStrings(dst, v[k])
+			}
+		case nil:
+		default:
+			dst = append(dst, fmt.Sprint(v))
+		}
+	}
+	return dst
+}
+
+// Query expressions are split on everything but the characters that can be part of a
+// metric, label or table name, so that `node_cpu_seconds_total`, `job:rate5m` and
+// `servers.web01.cpu` are indexed as single terms.
+type expressionCharFilter struct{}
+
+func (t *expressionCharFilter) Filter(input []byte) []byte {
+	return []byte(strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == ':' || r == '.' {
+			return r
+		}
+		return ' '
+	}, string(input)))
+}
+
+var expressionAnalyzer = &analysis.Analyzer{
+	CharFilters: []analysis.CharFilter{&expressionCharFilter{}},
+	Tokenizer:   tokenizer.NewWhitespaceTokenizer(),
+	TokenFilters: []analysis.TokenFilter{
+		token.NewLowerCaseFilter(),
+	},
+}
+
+// analyzeExpression returns the terms of a query expression as they are indexed.
+func analyzeExpression(expr string) []string {
+	stream := expressionAnalyzer.Analyze([]byte(expr))
+	terms := make([]string, 0, len(stream))
+	for _, t := range stream {
+		terms = append(terms, string(t.Term))
+	}
+	return terms
+}
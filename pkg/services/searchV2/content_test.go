@@ -0,0 +1,55 @@
+package searchV2
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadDashboardContent(t *testing.T) {
+	content := readDashboardContent([]byte(`{
+		"panels": [
+			{"id": 1, "targets": [{"refId": "A", "expr": "up"}, {"refId": "B", "rawSql": "SELECT 1", "rawQuery": true}]},
+			{"id": 2, "type": "row", "panels": [
+				{"id": 3, "targets": [{"target": "servers.*.cpu"}], "transformations": [{"id": "reduce", "options": {"reducers": ["mean"]}}]}
+			]}
+		],
+		"templating": {"list": [{"name": "host", "definition": "label_values(host)", "query": {"query": "label_values(host)", "refId": "A"}}]}
+	}`))
+
+	require.Equal(t, []string{"up", "SELECT 1"}, content.panels[1].queries)
+	require.Equal(t, []string{"servers.*.cpu"}, content.panels[3].queries)
+	require.Equal(t, []string{"reduce", "mean"}, content.panels[3].transformations)
+	require.Equal(t, []string{"host", "label_values(host)", "A", "label_values(host)"}, content.variables)
+
+	require.Empty(t, readDashboardContent([]byte(`not json`)).panels)
+}
+
+func TestParseSearchQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		text  string
+		terms []qualifiedTerm
+	}{
+		{query: "cpu usage", text: "cpu usage"},
+		{query: "query:node_cpu", text: "", terms: []qualifiedTerm{{qualifier: "query", value: "node_cpu"}}},
+		{query: `cpu ds:prom-uid query:"sum(rate(node_cpu"`, text: "cpu", terms: []qualifiedTerm{
+			{qualifier: "ds", value: "prom-uid"},
+			{qualifier: "query", value: "sum(rate(node_cpu"},
+		}},
+		{query: "job:rate5m query:", text: "job:rate5m query:"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			text, terms := parseSearchQuery(tt.query)
+			require.Equal(t, tt.text, text)
+			require.Equal(t, tt.terms, terms)
+		})
+	}
+}
+
+func TestAnalyzeExpression(t *testing.T) {
+	require.Equal(t, []string{"sum", "rate", "node_cpu_seconds_total", "job:rate5m", "5m"},
+		analyzeExpression(`sum(rate(node_cpu_seconds_total{job:rate5m}[5m]))`))
+}
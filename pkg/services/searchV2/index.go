@@ -57,6 +57,8 @@ type dashboard struct {
 
 	// Use generic structure
 	summary *entity.EntitySummary
+	// Text indexed for full-text search
+	content dashboardContent
 }
 
 // buildSignal is sent when search index is accessed in organization for which
@@ -957,6 +959,7 @@ func (l sqlDashboardLoader) LoadDashboards(ctx context.Context, orgID int64, das
 				created:  row.Created,
 				updated:  row.Updated,
 				summary:  summary,
+				content:  readDashboardContent(row.Data),
 			})
 		}
 		readDashboardSpan.End()
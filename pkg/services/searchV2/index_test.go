@@ -731,3 +731,91 @@ func TestDashboardIndex_MultiTermPrefixMatch(t *testing.T) {
 		})
 	}
 }
+
+var dashboardsWithContent = []dashboard{
+	{
+		id:  1,
+		uid: "1",
+		summary: &entity.EntitySummary{
+			Name: "Node exporter",
+			Nested: []*entity.EntitySummary{
+				newNestedPanel(1, 1, "CPU"),
+				newNestedPanel(2, 1, "Memory"),
+			},
+			References: []*entity.EntityExternalReference{
+				{Family: entity.StandardKindDataSource, Type: "prometheus", Identifier: "prometheus-uid"},
+			},
+		},
+		content: readDashboardContent([]byte(`{
+			"panels": [
+				{"id": 1, "description": "Busy CPU time", "targets": [{"expr": "sum(rate(node_cpu_seconds_total{mode!=\"idle\"}[5m]))"}]},
+				{"id": 2, "targets": [{"expr": "node_memory_MemAvailable_bytes"}], "transformations": [{"id": "organize", "options": {"renameByName": {"Value": "Available"}}}]}
+			],
+			"templating": {"list": [{"name": "instance", "query": "label_values(node_uname_info, instance)"}]}
+		}`)),
+	},
+	{
+		id:  2,
+		uid: "2",
+		summary: &entity.EntitySummary{
+			Name:        "Orders",
+			Description: "Orders placed in the shop",
+			Nested: []*entity.EntitySummary{
+				newNestedPanel(1, 2, "Orders per day"),
+			},
+			References: []*entity.EntityExternalReference{
+				{Family: entity.StandardKindDataSource, Type: "mysql", Identifier: "mysql-uid"},
+			},
+		},
+		content: readDashboardContent([]byte(`{
+			"panels": [{"id": 1, "targets": [{"rawSql": "SELECT day, count(*) FROM orders GROUP BY day"}]}]
+		}`)),
+	},
+}
+
+func TestDashboardIndex_FullText(t *testing.T) {
+	index := initTestOrgIndexFromDashes(t, dashboardsWithContent)
+
+	search := func(t *testing.T, query DashboardQuery) []string {
+		t.Helper()
+		resp := doSearchQuery(context.Background(), testLogger, index, testAllowAllFilter, query, &NoopQueryExtender{}, "")
+		require.NoError(t, resp.Error)
+		uidField, _ := resp.Frames[0].FieldByName("uid")
+		uids := make([]string, 0, uidField.Len())
+		for i := 0; i < uidField.Len(); i++ {
+			uids = append(uids, uidField.At(i).(string))
+		}
+		return uids
+	}
+
+	t.Run("query matches metric substrings in dashboards and panels", func(t *testing.T) {
+		require.ElementsMatch(t, []string{"1", "1#1"}, search(t, DashboardQuery{Query: "query:node_cpu"}))
+		require.ElementsMatch(t, []string{"1", "1#1"}, search(t, DashboardQuery{Query: "query:cpu_seconds"}))
+		require.ElementsMatch(t, []string{"1#2"}, search(t, DashboardQuery{Query: "query:MemAvailable", Kind: []string{string(entityKindPanel)}}))
+	})
+
+	t.Run("query matches sql and quoted expressions", func(t *testing.T) {
+		require.ElementsMatch(t, []string{"2", "2#1"}, search(t, DashboardQuery{Query: `query:"FROM orders"`}))
+		require.Empty(t, search(t, DashboardQuery{Query: `query:"FROM customers"`}))
+	})
+
+	t.Run("query matches variable definitions on the dashboard", func(t *testing.T) {
+		require.ElementsMatch(t, []string{"1"}, search(t, DashboardQuery{Query: "query:node_uname_info"}))
+		require.ElementsMatch(t, []string{"1"}, search(t, DashboardQuery{Query: "var:instance"}))
+	})
+
+	t.Run("ds matches data source uid and type", func(t *testing.T) {
+		require.ElementsMatch(t, []string{"2"}, search(t, DashboardQuery{Query: "ds:mysql-uid", Kind: []string{string(entityKindDashboard)}}))
+		require.ElementsMatch(t, []string{"1"}, search(t, DashboardQuery{Query: "ds:prometheus", Kind: []string{string(entityKindDashboard)}}))
+	})
+
+	t.Run("qualified terms combine with the free text", func(t *testing.T) {
+		require.ElementsMatch(t, []string{"2"}, search(t, DashboardQuery{Query: "Orders description:shop"}))
+		require.ElementsMatch(t, []string{"1#2"}, search(t, DashboardQuery{Query: "memory transformation:available"}))
+		require.Empty(t, search(t, DashboardQuery{Query: "Orders query:node_cpu"}))
+	})
+
+	t.Run("query without terms matches nothing", func(t *testing.T) {
+		require.Empty(t, search(t, DashboardQuery{Query: "query:(("}))
+	})
+}
@@ -0,0 +1,115 @@
+package searchV2
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/blugelabs/bluge"
+)
+
+// Qualifiers of the field-qualified terms of a search query, eg. `query:node_cpu`.
+const (
+	qualifierQuery          = "query"
+	qualifierDatasource     = "ds"
+	qualifierDescription    = "description"
+	qualifierTransformation = "transformation"
+	qualifierVariable       = "var"
+)
+
+var searchQualifiers = map[string]bool{
+	qualifierQuery:          true,
+	qualifierDatasource:     true,
+	qualifierDescription:    true,
+	qualifierTransformation: true,
+	qualifierVariable:       true,
+}
+
+type qualifiedTerm struct {
+	qualifier string
+	value     string
+}
+
+// parseSearchQuery splits a search query into its free text and its field-qualified terms.
+// Values can be quoted to contain spaces, eg. `query:"sum(rate(node_cpu"`. Terms with an
+// unknown qualifier, like `job:rate5m`, are part of the free text.
+func parseSearchQuery(query string) (string, []qualifiedTerm) {
+	var text []string
+	var terms []qualifiedTerm
+
+	for _, token := range splitSearchQuery(query) {
+		qualifier, value, found := strings.Cut(token, ":")
+		if found && searchQualifiers[qualifier] {
+			value = strings.ReplaceAll(value, `"`, "")
+			if value != "" {
+				terms = append(terms, qualifiedTerm{qualifier: qualifier, value: value})
+				continue
+			}
+		}
+		text = append(text, token)
+	}
+
+	return strings.Join(text, " "), terms
+}
+
+// splitSearchQuery splits a query on the spaces that are not quoted.
+func splitSearchQuery(query string) []string {
+	var tokens []string
+	var current strings.Builder
+	quoted := false
+	for _, r := range query {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			current.WriteRune(r)
+		case unicode.IsSpace(r) && !quoted:
+			if current.Len() > 0 {
+				tokens = append(tokens, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if current.Len() > 0 {
+		tokens = append(tokens, current.String())
+	}
+	return tokens
+}
+
+// newQualifiedTermQuery returns the query matching a field-qualified term, or nil if the
+// term can't match anything.
+func newQualifiedTermQuery(term qualifiedTerm) bluge.Query {
+	switch term.qualifier {
+	case qualifierDatasource:
+		bq := bluge.NewBooleanQuery()
+		bq.AddShould(bluge.NewTermQuery(term.value).SetField(documentFieldDSUID))
+		bq.AddShould(bluge.NewTermQuery(term.value).SetField(documentFieldDSType))
+		return bq
+	case qualifierDescription:
+		return bluge.NewMatchQuery(term.value).
+			SetField(documentFieldDescription).
+			SetOperator(bluge.MatchQueryOperatorAnd)
+	case qualifierQuery:
+		return newExpressionQuery(term.value, documentFieldQuery)
+	case qualifierTransformation:
+		return newExpressionQuery(term.value, documentFieldTransformation)
+	case qualifierVariable:
+		return newExpressionQuery(term.value, documentFieldVariable)
+	}
+	return nil
+}
+
+// newExpressionQuery matches the documents with a term containing each of the terms of expr,
+// so that `node_cpu` matches `node_cpu_seconds_total`.
+func newExpressionQuery(expr string, field string) bluge.Query {
+	terms := analyzeExpression(expr)
+	if len(terms) == 0 {
+		return nil
+	}
+
+	bq := bluge.NewBooleanQuery()
+	for _, t := range terms {
+		bq.AddMust(NewSubstringQuery(t).SetField(field))
+	}
+	return bq
+}
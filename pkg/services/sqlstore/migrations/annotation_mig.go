@@ -183,6 +183,17 @@ func addAnnotationMig(mg *Migrator) {
 	mg.AddMigration("Increase tags column to length 4096", NewRawSQLMigration("").
 		Postgres("ALTER TABLE annotation ALTER COLUMN tags TYPE VARCHAR(4096);").
 		Mysql("ALTER TABLE annotation MODIFY tags VARCHAR(4096);"))
+
+	//
+	// Import annotations only once
+	//
+	mg.AddMigration("Add idempotency_key column to annotation table", NewAddColumnMigration(table, &Column{
+		Name: "idempotency_key", Type: DB_NVarchar, Length: 190, Nullable: true,
+	}))
+
+	mg.AddMigration("Add unique index for org_id_idempotency_key on annotation table", NewAddIndexMigration(table, &Index{
+		Cols: []string{"org_id", "idempotency_key"}, Type: UniqueIndex,
+	}))
 }
 
 type AddMakeRegionSingleRowMigration struct {
@@ -0,0 +1,32 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addAnnotationWebhookMigrations(mg *Migrator) {
+	annotationWebhookV1 := Table{
+		Name: "annotation_webhook",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "name", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "source", Type: DB_NVarchar, Length: 16, Nullable: false},
+			{Name: "secret", Type: DB_Text, Nullable: false},
+			{Name: "dashboard_uid", Type: DB_NVarchar, Length: 40, Nullable: true},
+			{Name: "panel_id", Type: DB_BigInt, Nullable: false, Default: "0"},
+			{Name: "mapping", Type: DB_Text, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"uid"}, Type: UniqueIndex},
+			{Cols: []string{"org_id"}},
+		},
+	}
+
+	mg.AddMigration("create annotation_webhook table v1", NewAddTableMigration(annotationWebhookV1))
+	mg.AddMigration("add unique index annotation_webhook.uid", NewAddIndexMigration(annotationWebhookV1, annotationWebhookV1.Indices[0]))
+	mg.AddMigration("add index annotation_webhook.org_id", NewAddIndexMigration(annotationWebhookV1, annotationWebhookV1.Indices[1]))
+}
@@ -96,4 +96,36 @@ func addPublicDashboardMigration(mg *Migrator) {
 	mg.AddMigration("backfill empty share column fields with default of public", NewRawSQLMigration(
 		"UPDATE dashboard_public SET share='public' WHERE share=''",
 	))
+
+	mg.AddMigration("add variable_allowlist column", NewAddColumnMigration(dashboardPublicCfgV2, &Column{
+		Name:     "variable_allowlist",
+		Type:     DB_Text,
+		Nullable: true,
+	}))
+
+	mg.AddMigration("add expires_at column", NewAddColumnMigration(dashboardPublicCfgV2, &Column{
+		Name:     "expires_at",
+		Type:     DB_DateTime,
+		Nullable: true,
+	}))
+
+	mg.AddMigration("add max_views column", NewAddColumnMigration(dashboardPublicCfgV2, &Column{
+		Name:     "max_views",
+		Type:     DB_BigInt,
+		Nullable: false,
+		Default:  "0",
+	}))
+
+	mg.AddMigration("add view_count column", NewAddColumnMigration(dashboardPublicCfgV2, &Column{
+		Name:     "view_count",
+		Type:     DB_BigInt,
+		Nullable: false,
+		Default:  "0",
+	}))
+
+	mg.AddMigration("add suspended_until column", NewAddColumnMigration(dashboardPublicCfgV2, &Column{
+		Name:     "suspended_until",
+		Type:     DB_DateTime,
+		Nullable: true,
+	}))
 }
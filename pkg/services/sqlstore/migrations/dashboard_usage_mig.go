@@ -0,0 +1,47 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addDashboardUsageMigrations(mg *Migrator) {
+	dashboardUsageV1 := Table{
+		Name: "dashboard_usage",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "dashboard_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "day", Type: DB_BigInt, Nullable: false},
+			{Name: "views", Type: DB_BigInt, Nullable: false, Default: "0"},
+			{Name: "query_errors", Type: DB_BigInt, Nullable: false, Default: "0"},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "dashboard_uid", "day"}, Type: UniqueIndex},
+			{Cols: []string{"day"}},
+		},
+	}
+
+	mg.AddMigration("create dashboard_usage table v1", NewAddTableMigration(dashboardUsageV1))
+	mg.AddMigration("add unique index dashboard_usage.org_id_dashboard_uid_day", NewAddIndexMigration(dashboardUsageV1, dashboardUsageV1.Indices[0]))
+	mg.AddMigration("add index dashboard_usage.day", NewAddIndexMigration(dashboardUsageV1, dashboardUsageV1.Indices[1]))
+
+	dashboardUsageViewerV1 := Table{
+		Name: "dashboard_usage_viewer",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "dashboard_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "user_id", Type: DB_BigInt, Nullable: false},
+			{Name: "views", Type: DB_BigInt, Nullable: false, Default: "0"},
+			{Name: "last_viewed", Type: DB_BigInt, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "dashboard_uid", "user_id"}, Type: UniqueIndex},
+			{Cols: []string{"last_viewed"}},
+		},
+	}
+
+	mg.AddMigration("create dashboard_usage_viewer table v1", NewAddTableMigration(dashboardUsageViewerV1))
+	mg.AddMigration("add unique index dashboard_usage_viewer.org_id_dashboard_uid_user_id", NewAddIndexMigration(dashboardUsageViewerV1, dashboardUsageViewerV1.Indices[0]))
+	mg.AddMigration("add index dashboard_usage_viewer.last_viewed", NewAddIndexMigration(dashboardUsageViewerV1, dashboardUsageViewerV1.Indices[1]))
+}
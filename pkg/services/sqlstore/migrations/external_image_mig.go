@@ -0,0 +1,27 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addExternalImageMigrations(mg *Migrator) {
+	externalImageV1 := Table{
+		Name: "external_image",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "content", Type: DB_MediumBlob, Nullable: false},
+			{Name: "created", Type: DB_BigInt, Nullable: false},
+			{Name: "expires", Type: DB_BigInt, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"uid"}, Type: UniqueIndex},
+			{Cols: []string{"expires"}},
+		},
+	}
+
+	mg.AddMigration("create external_image table v1", NewAddTableMigration(externalImageV1))
+	mg.AddMigration("add unique index external_image.uid", NewAddIndexMigration(externalImageV1, externalImageV1.Indices[0]))
+	mg.AddMigration("add index external_image.expires", NewAddIndexMigration(externalImageV1, externalImageV1.Indices[1]))
+}
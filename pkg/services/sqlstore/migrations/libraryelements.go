@@ -84,3 +84,63 @@ func addLibraryElementsMigrations(mg *migrator.Migrator) {
 
 	mg.AddMigration("add index library_element org_id-folder_uid-name-kind", migrator.NewAddIndexMigration(libraryElementsV1, &migrator.Index{Cols: []string{"org_id", "folder_uid", "name", "kind"}, Type: migrator.UniqueIndex}))
 }
+
+// addLibraryElementVersionMigrations defines database migrations for the library element version history.
+func addLibraryElementVersionMigrations(mg *migrator.Migrator) {
+	libraryElementVersionV1 := migrator.Table{
+		Name: model.LibraryElementVersionTableName,
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "element_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "version", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "parent_version", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "restored_from", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "name", Type: migrator.DB_NVarchar, Length: 150, Nullable: false},
+			{Name: "folder_uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: true},
+			{Name: "model", Type: migrator.DB_MediumText, Nullable: false},
+			{Name: "affected_dashboards", Type: migrator.DB_Text, Nullable: true},
+			{Name: "message", Type: migrator.DB_Text, Nullable: false},
+			{Name: "created", Type: migrator.DB_DateTime, Nullable: false},
+			{Name: "created_by", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"element_id", "version"}, Type: migrator.UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create "+model.LibraryElementVersionTableName+" table v1", migrator.NewAddTableMigration(libraryElementVersionV1))
+	mg.AddMigration("add unique index "+model.LibraryElementVersionTableName+" element_id-version", migrator.NewAddIndexMigration(libraryElementVersionV1, libraryElementVersionV1.Indices[0]))
+
+	// the current state of existing library elements becomes the first entry of their history
+	const rawSQL = `INSERT INTO ` + model.LibraryElementVersionTableName + `
+(
+	org_id,
+	element_id,
+	version,
+	parent_version,
+	restored_from,
+	name,
+	folder_uid,
+	model,
+	affected_dashboards,
+	message,
+	created,
+	created_by
+)
+SELECT
+	library_element.org_id,
+	library_element.id,
+	library_element.version,
+	library_element.version,
+	0,
+	library_element.name,
+	library_element.folder_uid,
+	library_element.model,
+	'[]',
+	'` + model.LibraryElementVersionMigrationMessage + `',
+	library_element.updated,
+	library_element.updated_by
+FROM library_element;`
+	mg.AddMigration("save existing library elements in "+model.LibraryElementVersionTableName+" table v1", migrator.NewRawSQLMigration(rawSQL))
+}
@@ -121,6 +121,14 @@ func (oss *OSSMigrations) AddMigration(mg *Migrator) {
 	accesscontrol.AddAlertingScopeRemovalMigration(mg)
 
 	accesscontrol.AddManagedFolderAlertingSilencesActionsMigrator(mg)
+
+	addLibraryElementVersionMigrations(mg)
+
+	addDashboardUsageMigrations(mg)
+	addOutgoingWebhooksMigrations(mg)
+	addExternalImageMigrations(mg)
+	addAnnotationWebhookMigrations(mg)
+	addQueryLibraryMigrations(mg)
 }
 
 func addStarMigrations(mg *Migrator) {
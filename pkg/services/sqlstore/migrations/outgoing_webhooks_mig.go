@@ -0,0 +1,61 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addOutgoingWebhooksMigrations(mg *Migrator) {
+	outgoingWebhookV1 := Table{
+		Name: "outgoing_webhook",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "name", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "url", Type: DB_Text, Nullable: false},
+			{Name: "events", Type: DB_Text, Nullable: false},
+			{Name: "secret", Type: DB_Text, Nullable: false},
+			{Name: "enabled", Type: DB_Bool, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"uid"}, Type: UniqueIndex},
+			{Cols: []string{"org_id"}},
+		},
+	}
+
+	mg.AddMigration("create outgoing_webhook table v1", NewAddTableMigration(outgoingWebhookV1))
+	mg.AddMigration("add unique index outgoing_webhook.uid", NewAddIndexMigration(outgoingWebhookV1, outgoingWebhookV1.Indices[0]))
+	mg.AddMigration("add index outgoing_webhook.org_id", NewAddIndexMigration(outgoingWebhookV1, outgoingWebhookV1.Indices[1]))
+
+	outgoingWebhookDeliveryV1 := Table{
+		Name: "outgoing_webhook_delivery",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "webhook_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "event", Type: DB_NVarchar, Length: 64, Nullable: false},
+			{Name: "payload", Type: DB_MediumText, Nullable: false},
+			{Name: "status", Type: DB_NVarchar, Length: 16, Nullable: false},
+			{Name: "attempts", Type: DB_Int, Nullable: false, Default: "0"},
+			{Name: "next_attempt_at", Type: DB_BigInt, Nullable: false},
+			{Name: "response_status", Type: DB_Int, Nullable: false, Default: "0"},
+			{Name: "error", Type: DB_Text, Nullable: true},
+			{Name: "created", Type: DB_BigInt, Nullable: false},
+			{Name: "updated", Type: DB_BigInt, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"uid"}, Type: UniqueIndex},
+			{Cols: []string{"webhook_uid", "created"}},
+			{Cols: []string{"status", "next_attempt_at"}},
+			{Cols: []string{"created"}},
+		},
+	}
+
+	mg.AddMigration("create outgoing_webhook_delivery table v1", NewAddTableMigration(outgoingWebhookDeliveryV1))
+	mg.AddMigration("add unique index outgoing_webhook_delivery.uid", NewAddIndexMigration(outgoingWebhookDeliveryV1, outgoingWebhookDeliveryV1.Indices[0]))
+	mg.AddMigration("add index outgoing_webhook_delivery.webhook_uid_created", NewAddIndexMigration(outgoingWebhookDeliveryV1, outgoingWebhookDeliveryV1.Indices[1]))
+	mg.AddMigration("add index outgoing_webhook_delivery.status_next_attempt_at", NewAddIndexMigration(outgoingWebhookDeliveryV1, outgoingWebhookDeliveryV1.Indices[2]))
+	mg.AddMigration("add index outgoing_webhook_delivery.created", NewAddIndexMigration(outgoingWebhookDeliveryV1, outgoingWebhookDeliveryV1.Indices[3]))
+}
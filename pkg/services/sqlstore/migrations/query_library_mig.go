@@ -0,0 +1,44 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addQueryLibraryMigrations(mg *Migrator) {
+	queryHistoryTagV1 := Table{
+		Name: "query_history_tag",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "query_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "tag", Type: DB_NVarchar, Length: 50, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"query_uid", "tag"}, Type: UniqueIndex},
+			{Cols: []string{"tag"}},
+		},
+	}
+
+	mg.AddMigration("create query_history_tag table v1", NewAddTableMigration(queryHistoryTagV1))
+	mg.AddMigration("add unique index query_history_tag.query_uid-tag", NewAddIndexMigration(queryHistoryTagV1, queryHistoryTagV1.Indices[0]))
+	mg.AddMigration("add index query_history_tag.tag", NewAddIndexMigration(queryHistoryTagV1, queryHistoryTagV1.Indices[1]))
+
+	queryLibraryV1 := Table{
+		Name: "query_library",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "query_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "team_id", Type: DB_BigInt, Nullable: false, Default: "0"},
+			{Name: "published_by", Type: DB_BigInt, Nullable: false},
+			{Name: "published_at", Type: DB_BigInt, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"query_uid"}, Type: UniqueIndex},
+			{Cols: []string{"org_id", "team_id"}},
+		},
+	}
+
+	mg.AddMigration("create query_library table v1", NewAddTableMigration(queryLibraryV1))
+	mg.AddMigration("add unique index query_library.query_uid", NewAddIndexMigration(queryLibraryV1, queryLibraryV1.Indices[0]))
+	mg.AddMigration("add index query_library.org_id-team_id", NewAddIndexMigration(queryLibraryV1, queryLibraryV1.Indices[1]))
+}
@@ -55,6 +55,10 @@ type Dialect interface {
 	// UpsertSQL returns the upsert sql statement for a dialect
 	UpsertSQL(tableName string, keyCols, updateCols []string) string
 	UpsertMultipleSQL(tableName string, keyCols, updateCols []string, count int) (string, error)
+	// UpsertIncrementSQL returns the upsert sql statement for a dialect, which adds the values of incrementCols to the
+	// ones of the existing row and replaces the ones of updateCols. The values are bound in the order of keyCols,
+	// incrementCols and updateCols.
+	UpsertIncrementSQL(tableName string, keyCols, incrementCols, updateCols []string) string
 
 	ColString(*Column) string
 	ColStringNoPk(*Column) string
@@ -351,6 +355,23 @@ func (b *BaseDialect) UpsertSQL(tableName string, keyCols, updateCols []string)
 	return ""
 }
 
+// UpsertIncrementSQL returns empty string
+func (b *BaseDialect) UpsertIncrementSQL(tableName string, keyCols, incrementCols, updateCols []string) string {
+	return ""
+}
+
+// quotedColumns returns the quoted columns and a placeholder for each of them.
+func quotedColumns(quote func(string) string, cols ...[]string) (string, string) {
+	var quoted, placeHolders []string
+	for _, c := range cols {
+		for _, col := range c {
+			quoted = append(quoted, quote(col))
+			placeHolders = append(placeHolders, "?")
+		}
+	}
+	return strings.Join(quoted, ", "), strings.Join(placeHolders, ", ")
+}
+
 func (b *BaseDialect) Lock(_ LockCfg) error {
 	return nil
 }
@@ -262,6 +262,20 @@ func (db *MySQLDialect) UpsertMultipleSQL(tableName string, keyCols, updateCols
 	return s, nil
 }
 
+// UpsertIncrementSQL returns the upsert sql statement for MySQL dialect, which adds to the values of incrementCols
+func (db *MySQLDialect) UpsertIncrementSQL(tableName string, keyCols, incrementCols, updateCols []string) string {
+	columns, placeHolders := quotedColumns(db.Quote, keyCols, incrementCols, updateCols)
+	set := make([]string, 0, len(incrementCols)+len(updateCols))
+	for _, c := range incrementCols {
+		set = append(set, fmt.Sprintf("%s=%s+VALUES(%s)", db.Quote(c), db.Quote(c), db.Quote(c)))
+	}
+	for _, c := range updateCols {
+		set = append(set, fmt.Sprintf("%s=VALUES(%s)", db.Quote(c), db.Quote(c)))
+	}
+	return fmt.Sprintf(`INSERT INTO %s (%s) VALUES (%s) ON DUPLICATE KEY UPDATE %s`,
+		tableName, columns, placeHolders, strings.Join(set, ", "))
+}
+
 func (db *MySQLDialect) Lock(cfg LockCfg) error {
 	query := "SELECT GET_LOCK(?, ?)"
 	var success sql.NullBool
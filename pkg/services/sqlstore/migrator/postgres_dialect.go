@@ -282,6 +282,21 @@ func (db *PostgresDialect) UpsertMultipleSQL(tableName string, keyCols, updateCo
 	return s, nil
 }
 
+// UpsertIncrementSQL returns the upsert sql statement for PostgreSQL dialect, which adds to the values of incrementCols
+func (db *PostgresDialect) UpsertIncrementSQL(tableName string, keyCols, incrementCols, updateCols []string) string {
+	columns, placeHolders := quotedColumns(db.Quote, keyCols, incrementCols, updateCols)
+	keys, _ := quotedColumns(db.Quote, keyCols)
+	set := make([]string, 0, len(incrementCols)+len(updateCols))
+	for _, c := range incrementCols {
+		set = append(set, fmt.Sprintf("%s=%s.%s+EXCLUDED.%s", db.Quote(c), db.Quote(tableName), db.Quote(c), db.Quote(c)))
+	}
+	for _, c := range updateCols {
+		set = append(set, fmt.Sprintf("%s=EXCLUDED.%s", db.Quote(c), db.Quote(c)))
+	}
+	return fmt.Sprintf(`INSERT INTO %s (%s) VALUES (%s) ON CONFLICT (%s) DO UPDATE SET %s`,
+		tableName, columns, placeHolders, keys, strings.Join(set, ", "))
+}
+
 func (db *PostgresDialect) Lock(cfg LockCfg) error {
 	// trying to obtain the lock for a resource identified by a 64-bit or 32-bit key value
 	// the lock is exclusive: multiple lock requests stack, so that if the same resource is locked three times
@@ -214,6 +214,21 @@ func (db *SQLite3) UpsertMultipleSQL(tableName string, keyCols, updateCols []str
 	return s, nil
 }
 
+// UpsertIncrementSQL returns the upsert sql statement for SQLite dialect, which adds to the values of incrementCols
+func (db *SQLite3) UpsertIncrementSQL(tableName string, keyCols, incrementCols, updateCols []string) string {
+	columns, placeHolders := quotedColumns(db.Quote, keyCols, incrementCols, updateCols)
+	keys, _ := quotedColumns(db.Quote, keyCols)
+	set := make([]string, 0, len(incrementCols)+len(updateCols))
+	for _, c := range incrementCols {
+		set = append(set, fmt.Sprintf("%s=%s+excluded.%s", db.Quote(c), db.Quote(c), db.Quote(c)))
+	}
+	for _, c := range updateCols {
+		set = append(set, fmt.Sprintf("%s=excluded.%s", db.Quote(c), db.Quote(c)))
+	}
+	return fmt.Sprintf(`INSERT INTO %s (%s) VALUES (%s) ON CONFLICT(%s) DO UPDATE SET %s`,
+		tableName, columns, placeHolders, keys, strings.Join(set, ", "))
+}
+
 func (db *SQLite3) Concat(strs ...string) string {
 	return strings.Join(strs, " || ")
 }
@@ -72,3 +72,24 @@ func TestUpsertMultiple(t *testing.T) {
 		})
 	}
 }
+
+func TestUpsertIncrement(t *testing.T) {
+	keyCols := []string{"key1", "key2"}
+	incrementCols := []string{"count1", "count2"}
+	updateCols := []string{"val1"}
+
+	var db Dialect = &PostgresDialect{}
+	require.Equal(t,
+		"INSERT INTO test_table (\"key1\", \"key2\", \"count1\", \"count2\", \"val1\") VALUES (?, ?, ?, ?, ?) ON CONFLICT (\"key1\", \"key2\") DO UPDATE SET \"count1\"=\"test_table\".\"count1\"+EXCLUDED.\"count1\", \"count2\"=\"test_table\".\"count2\"+EXCLUDED.\"count2\", \"val1\"=EXCLUDED.\"val1\"",
+		db.UpsertIncrementSQL("test_table", keyCols, incrementCols, updateCols), "Postgres query incorrect")
+
+	db = &MySQLDialect{}
+	require.Equal(t,
+		"INSERT INTO test_table (`key1`, `key2`, `count1`, `count2`, `val1`) VALUES (?, ?, ?, ?, ?) ON DUPLICATE KEY UPDATE `count1`=`count1`+VALUES(`count1`), `count2`=`count2`+VALUES(`count2`), `val1`=VALUES(`val1`)",
+		db.UpsertIncrementSQL("test_table", keyCols, incrementCols, updateCols), "MySQL query incorrect")
+
+	db = &SQLite3{}
+	require.Equal(t,
+		"INSERT INTO test_table (`key1`, `key2`, `count1`, `count2`, `val1`) VALUES (?, ?, ?, ?, ?) ON CONFLICT(`key1`, `key2`) DO UPDATE SET `count1`=`count1`+excluded.`count1`, `count2`=`count2`+excluded.`count2`, `val1`=excluded.`val1`",
+		db.UpsertIncrementSQL("test_table", keyCols, incrementCols, updateCols), "SQLite query incorrect")
+}
@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/grafana/grafana/pkg/events"
 	"github.com/grafana/grafana/pkg/infra/db"
 	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/auth/identity"
@@ -96,8 +97,18 @@ func (ss *xormStore) Create(name, email string, orgID int64) (team.Team, error)
 			return team.ErrTeamNameTaken
 		}
 
-		_, err := sess.Insert(&t)
-		return err
+		if _, err := sess.Insert(&t); err != nil {
+			return err
+		}
+
+		sess.PublishAfterCommit(&events.TeamCreated{
+			Timestamp: t.Created,
+			Name:      t.Name,
+			ID:        t.ID,
+			UID:       t.UID,
+			OrgID:     t.OrgID,
+		})
+		return nil
 	})
 	return t, err
 }
@@ -128,6 +139,12 @@ func (ss *xormStore) Update(ctx context.Context, cmd *team.UpdateTeamCommand) er
 			return team.ErrTeamNotFound
 		}
 
+		sess.PublishAfterCommit(&events.TeamUpdated{
+			Timestamp: t.Updated,
+			Name:      cmd.Name,
+			ID:        cmd.ID,
+			OrgID:     cmd.OrgID,
+		})
 		return nil
 	})
 }
@@ -135,14 +152,18 @@ func (ss *xormStore) Update(ctx context.Context, cmd *team.UpdateTeamCommand) er
 // DeleteTeam will delete a team, its member and any permissions connected to the team
 func (ss *xormStore) Delete(ctx context.Context, cmd *team.DeleteTeamCommand) error {
 	return ss.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
-		if _, err := teamExists(cmd.OrgID, cmd.ID, sess); err != nil {
+		var existing team.Team
+		if has, err := sess.Where("org_id=? and id=?", cmd.OrgID, cmd.ID).Get(&existing); err != nil {
 			return err
+		} else if !has {
+			return team.ErrTeamNotFound
 		}
 
 		deletes := []string{
 			"DELETE FROM team_member WHERE org_id=? and team_id = ?",
 			"DELETE FROM team WHERE org_id=? and id = ?",
 			"DELETE FROM dashboard_acl WHERE org_id=? and team_id = ?",
+			"DELETE FROM query_library WHERE org_id=? and team_id = ?",
 		}
 
 		deletes = append(deletes, ss.deletes...)
@@ -153,6 +174,14 @@ func (ss *xormStore) Delete(ctx context.Context, cmd *team.DeleteTeamCommand) er
 				return err
 			}
 		}
+
+		sess.PublishAfterCommit(&events.TeamDeleted{
+			Timestamp: time.Now(),
+			Name:      existing.Name,
+			ID:        existing.ID,
+			UID:       existing.UID,
+			OrgID:     existing.OrgID,
+		})
 		return nil
 	})
 }
@@ -115,10 +115,28 @@ func (ss *sqlStore) Get(ctx context.Context, usr *user.User) (*user.User, error)
 }
 
 func (ss *sqlStore) Delete(ctx context.Context, userID int64) error {
-	err := ss.db.WithDbSession(ctx, func(sess *db.Session) error {
+	err := ss.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		usr := user.User{}
+		has, err := sess.ID(userID).Get(&usr)
+		if err != nil {
+			return err
+		}
+
 		var rawSQL = "DELETE FROM " + ss.dialect.Quote("user") + " WHERE id = ?"
-		_, err := sess.Exec(rawSQL, userID)
-		return err
+		if _, err := sess.Exec(rawSQL, userID); err != nil {
+			return err
+		}
+
+		if has {
+			sess.PublishAfterCommit(&events.UserDeleted{
+				Timestamp: time.Now(),
+				Id:        usr.ID,
+				Name:      usr.Name,
+				Login:     usr.Login,
+				Email:     usr.Email,
+			})
+		}
+		return nil
 	})
 	if err != nil {
 		return err
@@ -0,0 +1,31 @@
+package signing
+
+import (
+	"context"
+	"encoding/base64"
+
+	"github.com/grafana/grafana/pkg/services/secrets"
+)
+
+// EncryptSecret encrypts the secret of a webhook for storage, which must be done
+// outside of database transactions.
+func EncryptSecret(ctx context.Context, secretsService secrets.Service, secret string) (string, error) {
+	encrypted, err := secretsService.Encrypt(ctx, []byte(secret), secrets.WithoutScope())
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(encrypted), nil
+}
+
+// DecryptSecret decrypts a secret encrypted with EncryptSecret.
+func DecryptSecret(ctx context.Context, secretsService secrets.Service, secret string) (string, error) {
+	decoded, err := base64.StdEncoding.DecodeString(secret)
+	if err != nil {
+		return "", err
+	}
+	decrypted, err := secretsService.Decrypt(ctx, decoded)
+	if err != nil {
+		return "", err
+	}
+	return string(decrypted), nil
+}
@@ -0,0 +1,36 @@
+// Package signing signs and verifies the payloads of the webhooks sent and
+// received by Grafana, and encrypts the secrets they are signed with.
+package signing
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+)
+
+// The headers of the webhooks signed by Grafana.
+const (
+	HeaderDelivery           = "X-Grafana-Delivery"
+	HeaderSignature          = "X-Grafana-Signature"
+	HeaderSignatureTimestamp = "X-Grafana-Signature-Timestamp"
+
+	signaturePrefix = "sha256="
+)
+
+// Sign returns the signature of the payload sent at the unix time timestamp. The
+// timestamp is part of the signed content so that receivers can reject replayed
+// requests.
+func Sign(secret string, timestamp int64, body []byte) string {
+	return HMACSHA256(secret, []byte(strconv.FormatInt(timestamp, 10)+"."), body)
+}
+
+// HMACSHA256 returns the prefixed hex HMAC-SHA256 of the content, which is also
+// how GitHub signs its webhooks.
+func HMACSHA256(secret string, content ...[]byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	for _, c := range content {
+		mac.Write(c)
+	}
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
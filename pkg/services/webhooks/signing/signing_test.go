@@ -0,0 +1,21 @@
+package signing
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSign(t *testing.T) {
+	body := []byte(`{"event":"dashboard.updated"}`)
+
+	signature := Sign("secret", 1700000000, body)
+	require.Equal(t, "sha256=4ddb0505ff9792e174f2720a5c164bb628cbe07bd81a6206ad9597e1e1cc409b", signature)
+	require.NotEqual(t, signature, Sign("other secret", 1700000000, body))
+	require.NotEqual(t, signature, Sign("secret", 1700000001, body))
+}
+
+func TestHMACSHA256(t *testing.T) {
+	require.Equal(t, HMACSHA256("secret", []byte("a"), []byte("b")), HMACSHA256("secret", []byte("ab")))
+	require.NotEqual(t, HMACSHA256("secret", []byte("ab")), HMACSHA256("other secret", []byte("ab")))
+}
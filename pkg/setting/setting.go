@@ -10,6 +10,7 @@ import (
 	"errors"
 	"fmt"
 	"io/fs"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
@@ -336,6 +337,7 @@ type Cfg struct {
 	SqlDatasourceMaxOpenConnsDefault    int
 	SqlDatasourceMaxIdleConnsDefault    int
 	SqlDatasourceMaxConnLifetimeDefault int
+	SqliteDatasourceAllowedPaths        []string
 
 	// Snapshots
 	SnapshotEnabled      bool
@@ -486,11 +488,21 @@ type Cfg struct {
 	DatabaseInstrumentQueries bool
 
 	// Public dashboards
-	PublicDashboardsEnabled bool
+	PublicDashboardsEnabled            bool
+	PublicDashboardsTokenRateLimit     int
+	PublicDashboardsClientIPRateLimit  int
+	PublicDashboardsTrustedProxies     []*net.IPNet
+	PublicDashboardsMinRefreshInterval time.Duration
 
 	// Cloud Migration
 	CloudMigration CloudMigrationSettings
 
+	// Dashboard usage analytics
+	DashboardUsage DashboardUsageSettings
+
+	// Outgoing webhooks
+	OutgoingWebhooks OutgoingWebhooksSettings
+
 	// Feature Management Settings
 	FeatureManagement FeatureMgmtSettings
 
@@ -1274,8 +1286,16 @@ func (cfg *Cfg) parseINIFile(iniFile *ini.File) error {
 	cfg.UserFacingDefaultError = logSection.Key("user_facing_default_error").MustString("please inspect Grafana server log for details")
 
 	cfg.readFeatureManagementConfig()
-	cfg.readPublicDashboardsSettings()
+	if err := cfg.readPublicDashboardsSettings(); err != nil {
+		return err
+	}
 	cfg.readCloudMigrationSettings()
+	if err := cfg.readDashboardUsageSettings(); err != nil {
+		return err
+	}
+	if err := cfg.readOutgoingWebhooksSettings(); err != nil {
+		return err
+	}
 
 	// read experimental scopes settings.
 	scopesSection := iniFile.Section("scopes")
@@ -1931,6 +1951,7 @@ func (cfg *Cfg) readSqlDataSourceSettings() {
 	cfg.SqlDatasourceMaxOpenConnsDefault = sqlDatasources.Key("max_open_conns_default").MustInt(100)
 	cfg.SqlDatasourceMaxIdleConnsDefault = sqlDatasources.Key("max_idle_conns_default").MustInt(100)
 	cfg.SqlDatasourceMaxConnLifetimeDefault = sqlDatasources.Key("max_conn_lifetime_default").MustInt(14400)
+	cfg.SqliteDatasourceAllowedPaths = util.SplitString(sqlDatasources.Key("sqlite_allowed_paths").MustString(""))
 }
 
 func GetAllowedOriginGlobs(originPatterns []string) ([]glob.Glob, error) {
@@ -1978,7 +1999,28 @@ func (cfg *Cfg) readLiveSettings(iniFile *ini.File) error {
 	return nil
 }
 
-func (cfg *Cfg) readPublicDashboardsSettings() {
+func (cfg *Cfg) readPublicDashboardsSettings() error {
 	publicDashboards := cfg.Raw.Section("public_dashboards")
 	cfg.PublicDashboardsEnabled = publicDashboards.Key("enabled").MustBool(true)
+	cfg.PublicDashboardsTokenRateLimit = publicDashboards.Key("token_rate_limit").MustInt(0)
+	cfg.PublicDashboardsClientIPRateLimit = publicDashboards.Key("client_ip_rate_limit").MustInt(0)
+	cfg.PublicDashboardsMinRefreshInterval = publicDashboards.Key("min_refresh_interval").MustDuration(0)
+
+	// the addresses are IPs or CIDRs, an IP is a network of a single address
+	cfg.PublicDashboardsTrustedProxies = nil
+	for _, proxy := range util.SplitString(publicDashboards.Key("trusted_proxies").MustString("")) {
+		if !strings.Contains(proxy, "/") {
+			if ip := net.ParseIP(proxy); ip != nil && ip.To4() != nil {
+				proxy += "/32"
+			} else {
+				proxy += "/128"
+			}
+		}
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return fmt.Errorf("invalid trusted proxy %q of public dashboards: %w", proxy, err)
+		}
+		cfg.PublicDashboardsTrustedProxies = append(cfg.PublicDashboardsTrustedProxies, network)
+	}
+	return nil
 }
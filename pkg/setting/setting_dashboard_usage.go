@@ -0,0 +1,40 @@
+package setting
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+)
+
+// DashboardUsageStalePeriod is how long a dashboard has not been viewed for to be stale. The usage must be kept longer,
+// so that the dashboards which were viewed in this period are known.
+const DashboardUsageStalePeriod = 90 * 24 * time.Hour
+
+type DashboardUsageSettings struct {
+	Enabled bool
+	// TrackUsers records which users viewed a dashboard, otherwise only the number of views is stored
+	TrackUsers    bool
+	Retention     time.Duration
+	FlushInterval time.Duration
+}
+
+func (cfg *Cfg) readDashboardUsageSettings() error {
+	section := cfg.Raw.Section("dashboard_usage")
+	cfg.DashboardUsage.Enabled = section.Key("enabled").MustBool(false)
+	cfg.DashboardUsage.TrackUsers = section.Key("track_users").MustBool(false)
+	cfg.DashboardUsage.FlushInterval = section.Key("flush_interval").MustDuration(time.Minute)
+	if cfg.DashboardUsage.FlushInterval <= 0 {
+		return fmt.Errorf("dashboard_usage flush_interval must be positive, got %s", cfg.DashboardUsage.FlushInterval)
+	}
+
+	retention, err := gtime.ParseDuration(valueAsString(section, "retention", "1y"))
+	if err != nil {
+		return fmt.Errorf("invalid dashboard_usage retention: %w", err)
+	}
+	if retention <= DashboardUsageStalePeriod {
+		return fmt.Errorf("dashboard_usage retention must be longer than the stale period of 90 days, got %s", retention)
+	}
+	cfg.DashboardUsage.Retention = retention
+	return nil
+}
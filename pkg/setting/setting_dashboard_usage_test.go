@@ -0,0 +1,46 @@
+package setting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/ini.v1"
+)
+
+func TestReadDashboardUsageSettings(t *testing.T) {
+	readRetention := func(t *testing.T, retention string) (time.Duration, error) {
+		t.Helper()
+		f := ini.Empty()
+		s, err := f.NewSection("dashboard_usage")
+		require.NoError(t, err)
+		_, err = s.NewKey("retention", retention)
+		require.NoError(t, err)
+
+		cfg := NewCfg()
+		cfg.Raw = f
+		err = cfg.readDashboardUsageSettings()
+		return cfg.DashboardUsage.Retention, err
+	}
+
+	t.Run("is disabled by default", func(t *testing.T) {
+		cfg := NewCfg()
+		cfg.Raw = ini.Empty()
+		require.NoError(t, cfg.readDashboardUsageSettings())
+		assert.False(t, cfg.DashboardUsage.Enabled)
+	})
+
+	t.Run("will load the retention", func(t *testing.T) {
+		retention, err := readRetention(t, "180d")
+		require.NoError(t, err)
+		assert.Equal(t, 180*24*time.Hour, retention)
+	})
+
+	t.Run("will reject a retention that is not longer than the stale period", func(t *testing.T) {
+		for _, retention := range []string{"1d", "90d"} {
+			_, err := readRetention(t, retention)
+			require.Error(t, err, retention)
+		}
+	})
+}
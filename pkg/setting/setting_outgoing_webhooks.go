@@ -0,0 +1,37 @@
+package setting
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+)
+
+type OutgoingWebhooksSettings struct {
+	Enabled bool
+	// MaxAttempts is the number of times a delivery is attempted before it's marked as failed
+	MaxAttempts int
+	// RetryInterval is the delay before the first retry, doubled after every failed attempt
+	RetryInterval     time.Duration
+	DeliveryRetention time.Duration
+}
+
+func (cfg *Cfg) readOutgoingWebhooksSettings() error {
+	section := cfg.Raw.Section("outgoing_webhooks")
+	cfg.OutgoingWebhooks.Enabled = section.Key("enabled").MustBool(false)
+	cfg.OutgoingWebhooks.MaxAttempts = section.Key("max_attempts").MustInt(3)
+	if cfg.OutgoingWebhooks.MaxAttempts < 1 {
+		return fmt.Errorf("outgoing_webhooks max_attempts must be at least 1, got %d", cfg.OutgoingWebhooks.MaxAttempts)
+	}
+	cfg.OutgoingWebhooks.RetryInterval = section.Key("retry_interval").MustDuration(30 * time.Second)
+	if cfg.OutgoingWebhooks.RetryInterval <= 0 {
+		return fmt.Errorf("outgoing_webhooks retry_interval must be positive, got %s", cfg.OutgoingWebhooks.RetryInterval)
+	}
+
+	retention, err := gtime.ParseDuration(valueAsString(section, "delivery_retention", "7d"))
+	if err != nil {
+		return fmt.Errorf("invalid outgoing_webhooks delivery_retention: %w", err)
+	}
+	cfg.OutgoingWebhooks.DeliveryRetention = retention
+	return nil
+}
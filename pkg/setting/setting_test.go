@@ -681,3 +681,21 @@ func TestDynamicSection(t *testing.T) {
 		assert.Equal(t, value, ds.section.Key(key).String())
 	})
 }
+
+func TestPublicDashboardsTrustedProxies(t *testing.T) {
+	cfg, err := NewCfgFromBytes([]byte(`
+[public_dashboards]
+trusted_proxies = 10.0.0.0/8, 192.0.2.1 2001:db8::1
+`))
+	require.NoError(t, err)
+	require.Len(t, cfg.PublicDashboardsTrustedProxies, 3)
+	require.Equal(t, "10.0.0.0/8", cfg.PublicDashboardsTrustedProxies[0].String())
+	require.Equal(t, "192.0.2.1/32", cfg.PublicDashboardsTrustedProxies[1].String())
+	require.Equal(t, "2001:db8::1/128", cfg.PublicDashboardsTrustedProxies[2].String())
+
+	_, err = NewCfgFromBytes([]byte(`
+[public_dashboards]
+trusted_proxies = proxy.local
+`))
+	require.Error(t, err)
+}
@@ -0,0 +1,154 @@
+package es
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ESQLRequest is a request to the ES|QL endpoint
+type ESQLRequest struct {
+	Query    string `json:"query"`
+	Columnar bool   `json:"columnar"`
+}
+
+// SQLRequest is a request to the SQL endpoint
+type SQLRequest struct {
+	Query     string `json:"query"`
+	Columnar  bool   `json:"columnar"`
+	FetchSize int    `json:"fetch_size,omitempty"`
+	TimeZone  string `json:"time_zone,omitempty"`
+}
+
+// ColumnarResponseColumn is a column of the response of the ES|QL and SQL endpoints
+type ColumnarResponseColumn struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// ColumnarResponse is the response of the ES|QL and SQL endpoints for columnar requests,
+// which contains the values of each column rather than of each row. The numbers are
+// decoded as json.Number.
+type ColumnarResponse struct {
+	Columns []ColumnarResponseColumn `json:"columns"`
+	Values  [][]any                  `json:"values"`
+	// Cursor is returned by the SQL endpoint when there are more rows than the fetch size
+	Cursor string `json:"cursor,omitempty"`
+}
+
+type columnarErrorResponse struct {
+	Error struct {
+		Type   string `json:"type"`
+		Reason string `json:"reason"`
+	} `json:"error"`
+}
+
+// ExecuteESQL runs an ES|QL query
+func (c *baseClientImpl) ExecuteESQL(r *ESQLRequest) (*ColumnarResponse, error) {
+	return c.executeColumnarRequest("datasource.elasticsearch.queryData.executeESQL", "_query", "", r)
+}
+
+// ExecuteSQL runs an Elasticsearch SQL query. Only the first page of the results is returned, the
+// cursor of the next pages is closed.
+func (c *baseClientImpl) ExecuteSQL(r *SQLRequest) (*ColumnarResponse, error) {
+	res, err := c.executeColumnarRequest("datasource.elasticsearch.queryData.executeSQL", "_sql", "format=json", r)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.Cursor != "" {
+		c.closeSQLCursor(res.Cursor)
+	}
+	return res, nil
+}
+
+func (c *baseClientImpl) executeColumnarRequest(spanName, uriPath, uriQuery string, r any) (*ColumnarResponse, error) {
+	var err error
+	_, span := c.tracer.Start(c.ctx, spanName, trace.WithAttributes(
+		attribute.String("url", c.ds.URL),
+	))
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	body, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	res, err := c.executeRequest(http.MethodPost, uriPath, uriQuery, "application/json", body)
+	if err != nil {
+		status := "error"
+		if errors.Is(err, context.Canceled) {
+			status = "cancelled"
+		}
+		c.logger.Error("Error received from Elasticsearch", "error", err, "status", status, "duration", time.Since(start), "stage", StageDatabaseRequest)
+		return nil, err
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			c.logger.Warn("Failed to close response body", "error", err)
+		}
+	}()
+
+	c.logger.Info("Response received from Elasticsearch", "statusCode", res.StatusCode, "contentLength", res.ContentLength, "duration", time.Since(start), "stage", StageDatabaseRequest)
+
+	dec := json.NewDecoder(res.Body)
+	dec.UseNumber()
+
+	if res.StatusCode/100 != 2 {
+		var errRes columnarErrorResponse
+		if decodeErr := dec.Decode(&errRes); decodeErr != nil || errRes.Error.Reason == "" {
+			err = fmt.Errorf("unexpected status code from Elasticsearch: %d", res.StatusCode)
+			return nil, err
+		}
+		err = &ColumnarQueryError{StatusCode: res.StatusCode, Type: errRes.Error.Type, Reason: errRes.Error.Reason}
+		return nil, err
+	}
+
+	var cr ColumnarResponse
+	if err = dec.Decode(&cr); err != nil {
+		c.logger.Error("Failed to decode response from Elasticsearch", "error", err, "duration", time.Since(start))
+		return nil, err
+	}
+	return &cr, nil
+}
+
+func (c *baseClientImpl) closeSQLCursor(cursor string) {
+	body, err := json.Marshal(map[string]string{"cursor": cursor})
+	if err != nil {
+		return
+	}
+
+	res, err := c.executeRequest(http.MethodPost, "_sql/close", "", "application/json", body)
+	if err != nil {
+		c.logger.Warn("Failed to close SQL cursor", "error", err)
+		return
+	}
+	if err := res.Body.Close(); err != nil {
+		c.logger.Warn("Failed to close response body", "error", err)
+	}
+}
+
+// ColumnarQueryError is an error returned by Elasticsearch for an ES|QL or SQL query, such as a syntax error
+type ColumnarQueryError struct {
+	StatusCode int
+	Type       string
+	Reason     string
+}
+
+func (e *ColumnarQueryError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Type, e.Reason)
+}
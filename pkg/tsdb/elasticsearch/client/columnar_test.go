@@ -0,0 +1,108 @@
+package es
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+func newColumnarTestClient(t *testing.T, handler http.HandlerFunc) Client {
+	t.Helper()
+	ts := httptest.NewServer(handler)
+	t.Cleanup(ts.Close)
+
+	ds := DatasourceInfo{
+		URL:              ts.URL,
+		HTTPClient:       ts.Client(),
+		Database:         "metrics",
+		ConfiguredFields: ConfiguredFields{TimeField: "@timestamp"},
+	}
+	c, err := NewClient(context.Background(), &ds, log.New("test", "test"), tracing.InitializeTracerForTest())
+	require.NoError(t, err)
+	return c
+}
+
+func TestClient_ExecuteESQL(t *testing.T) {
+	t.Run("sends the query to the ES|QL endpoint", func(t *testing.T) {
+		var request *http.Request
+		var requestBody map[string]any
+		c := newColumnarTestClient(t, func(rw http.ResponseWriter, r *http.Request) {
+			request = r
+			body, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			require.NoError(t, json.Unmarshal(body, &requestBody))
+
+			rw.Header().Set("Content-Type", "application/json")
+			_, err = rw.Write([]byte(`{
+				"columns": [{"name": "@timestamp", "type": "date"}, {"name": "count", "type": "long"}],
+				"values": [["2024-01-01T00:00:00.000Z"], [9007199254740993]]
+			}`))
+			require.NoError(t, err)
+		})
+
+		res, err := c.ExecuteESQL(&ESQLRequest{Query: "FROM metrics | STATS count = COUNT(*)", Columnar: true})
+		require.NoError(t, err)
+
+		assert.Equal(t, http.MethodPost, request.Method)
+		assert.Equal(t, "/_query", request.URL.Path)
+		assert.Equal(t, "application/json", request.Header.Get("Content-Type"))
+		assert.Equal(t, map[string]any{"query": "FROM metrics | STATS count = COUNT(*)", "columnar": true}, requestBody)
+
+		require.Len(t, res.Columns, 2)
+		assert.Equal(t, ColumnarResponseColumn{Name: "count", Type: "long"}, res.Columns[1])
+		// the numbers are decoded without losing precision
+		assert.Equal(t, json.Number("9007199254740993"), res.Values[1][0])
+	})
+
+	t.Run("returns the reason of the errors", func(t *testing.T) {
+		c := newColumnarTestClient(t, func(rw http.ResponseWriter, r *http.Request) {
+			rw.WriteHeader(http.StatusBadRequest)
+			_, err := rw.Write([]byte(`{"error": {"type": "verification_exception", "reason": "Unknown index [nope]"}, "status": 400}`))
+			require.NoError(t, err)
+		})
+
+		_, err := c.ExecuteESQL(&ESQLRequest{Query: "FROM nope"})
+		var queryErr *ColumnarQueryError
+		require.ErrorAs(t, err, &queryErr)
+		assert.Equal(t, http.StatusBadRequest, queryErr.StatusCode)
+		assert.Equal(t, "verification_exception: Unknown index [nope]", err.Error())
+	})
+}
+
+func TestClient_ExecuteSQL(t *testing.T) {
+	t.Run("closes the cursor of the next page", func(t *testing.T) {
+		var paths []string
+		var closedCursor map[string]any
+		c := newColumnarTestClient(t, func(rw http.ResponseWriter, r *http.Request) {
+			paths = append(paths, r.URL.Path+"?"+r.URL.RawQuery)
+			body, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+
+			if r.URL.Path == "/_sql/close" {
+				require.NoError(t, json.Unmarshal(body, &closedCursor))
+				_, err = rw.Write([]byte(`{"succeeded": true}`))
+				require.NoError(t, err)
+				return
+			}
+			_, err = rw.Write([]byte(`{"columns": [{"name": "host", "type": "keyword"}], "values": [["a", "b"]], "cursor": "abc"}`))
+			require.NoError(t, err)
+		})
+
+		res, err := c.ExecuteSQL(&SQLRequest{Query: "SELECT host FROM metrics", Columnar: true, FetchSize: 2})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"/_sql?format=json", "/_sql/close?"}, paths)
+		assert.Equal(t, map[string]any{"cursor": "abc"}, closedCursor)
+		assert.Equal(t, "abc", res.Cursor)
+		assert.Equal(t, []any{"a", "b"}, res.Values[0])
+	})
+}
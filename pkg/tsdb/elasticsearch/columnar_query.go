@@ -0,0 +1,299 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana-plugin-sdk-go/experimental/errorsource"
+
+	es "github.com/grafana/grafana/pkg/tsdb/elasticsearch/client"
+)
+
+const (
+	esqlQueryType = "esql"
+	sqlQueryType  = "sql"
+
+	// sqlFetchSize is the maximum number of rows returned by SQL queries
+	sqlFetchSize = 10000
+
+	columnarTimeFormat = "2006-01-02T15:04:05.000Z"
+)
+
+var columnarMacroRegexp = regexp.MustCompile(`\$(__timeFilter|__timeFrom|__timeTo)\(([^\)]*)\)`)
+
+// isColumnarQuery returns true for the ES|QL and SQL queries, which are sent to their own endpoints
+// rather than built with the query DSL.
+func isColumnarQuery(q *Query) bool {
+	return q.QueryType == esqlQueryType || q.QueryType == sqlQueryType
+}
+
+func (e *elasticsearchDataQuery) executeColumnarQuery(q *Query) backend.DataResponse {
+	query, err := interpolateColumnarQuery(q, e.client.GetConfiguredFields().TimeField)
+	if err != nil {
+		return errorsource.Response(errorsource.PluginError(err, false))
+	}
+	if strings.TrimSpace(query) == "" {
+		return backend.DataResponse{}
+	}
+
+	var res *es.ColumnarResponse
+	if q.QueryType == esqlQueryType {
+		res, err = e.client.ExecuteESQL(&es.ESQLRequest{Query: query, Columnar: true})
+	} else {
+		res, err = e.client.ExecuteSQL(&es.SQLRequest{Query: query, Columnar: true, FetchSize: sqlFetchSize, TimeZone: "Z"})
+	}
+	if err != nil {
+		var queryErr *es.ColumnarQueryError
+		if errors.As(err, &queryErr) {
+			return errorsource.Response(errorsource.DownstreamError(err, false))
+		}
+		return errorsource.Response(err)
+	}
+
+	frame, err := columnarResponseToFrame(res, q.RefID)
+	if err != nil {
+		return errorsource.Response(errorsource.PluginError(err, false))
+	}
+
+	frame, err = toTimeSeriesIfPossible(frame)
+	if err != nil {
+		return errorsource.Response(errorsource.PluginError(err, false))
+	}
+
+	if frame.Meta == nil {
+		frame.Meta = &data.FrameMeta{}
+	}
+	frame.Meta.ExecutedQueryString = query
+	if res.Cursor != "" {
+		frame.AppendNotices(data.Notice{
+			Severity: data.NoticeSeverityWarning,
+			Text:     fmt.Sprintf("Only the first %d rows are returned. Add a LIMIT clause or aggregate the results to see all of them.", sqlFetchSize),
+		})
+	}
+
+	return backend.DataResponse{Frames: data.Frames{frame}}
+}
+
+// interpolateColumnarQuery replaces the macros of ES|QL and SQL queries:
+//   - $__timeFilter(field) filters the rows in the time range of the query. The field defaults to the time field of the data source.
+//   - $__timeFrom() and $__timeTo() are the start and the end of the time range.
+//   - $__interval is the interval of the query as a time span, and $__interval_ms the interval in milliseconds.
+func interpolateColumnarQuery(q *Query, timeField string) (string, error) {
+	from := q.TimeRange.From.UTC().Format(columnarTimeFormat)
+	to := q.TimeRange.To.UTC().Format(columnarTimeFormat)
+
+	datetime := func(value string) string {
+		if q.QueryType == esqlQueryType {
+			return fmt.Sprintf(`TO_DATETIME("%s")`, value)
+		}
+		return fmt.Sprintf(`CAST('%s' AS DATETIME)`, value)
+	}
+
+	var macroErr error
+	query := columnarMacroRegexp.ReplaceAllStringFunc(q.RawQuery, func(match string) string {
+		groups := columnarMacroRegexp.FindStringSubmatch(match)
+		arg := strings.TrimSpace(groups[2])
+		switch groups[1] {
+		case "__timeFilter":
+			field := arg
+			if field == "" {
+				if timeField == "" {
+					macroErr = fmt.Errorf("missing time field argument for macro %s", groups[1])
+					return match
+				}
+				field = quoteColumnarIdentifier(q.QueryType, timeField)
+			}
+			return fmt.Sprintf("%s >= %s AND %s <= %s", field, datetime(from), field, datetime(to))
+		case "__timeFrom":
+			return datetime(from)
+		default:
+			return datetime(to)
+		}
+	})
+	if macroErr != nil {
+		return "", macroErr
+	}
+
+	interval := q.Interval
+	if q.IntervalMs > 0 {
+		interval = time.Duration(q.IntervalMs) * time.Millisecond
+	}
+	seconds := int64(math.Max(1, math.Ceil(interval.Seconds())))
+	query = strings.ReplaceAll(query, "$__interval_ms", strconv.FormatInt(interval.Milliseconds(), 10))
+	if q.QueryType == esqlQueryType {
+		query = strings.ReplaceAll(query, "$__interval", fmt.Sprintf("%d seconds", seconds))
+	} else {
+		query = strings.ReplaceAll(query, "$__interval", fmt.Sprintf("INTERVAL %d SECONDS", seconds))
+	}
+
+	return query, nil
+}
+
+var (
+	simpleESQLIdentifier = regexp.MustCompile(`^[a-zA-Z_@][a-zA-Z0-9_.]*$`)
+	simpleSQLIdentifier  = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_.]*$`)
+)
+
+func quoteColumnarIdentifier(queryType, identifier string) string {
+	if queryType == esqlQueryType {
+		if simpleESQLIdentifier.MatchString(identifier) {
+			return identifier
+		}
+		return "`" + strings.ReplaceAll(identifier, "`", "``") + "`"
+	}
+	if simpleSQLIdentifier.MatchString(identifier) {
+		return identifier
+	}
+	return `"` + strings.ReplaceAll(identifier, `"`, `""`) + `"`
+}
+
+// columnarResponseToFrame converts the response of a columnar ES|QL or SQL query to a frame,
+// with a field for each column.
+func columnarResponseToFrame(res *es.ColumnarResponse, refID string) (*data.Frame, error) {
+	frame := data.NewFrame(refID)
+	for i, column := range res.Columns {
+		var values []any
+		if i < len(res.Values) {
+			values = res.Values[i]
+		}
+		field, err := newColumnarField(column, values)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert column %q: %w", column.Name, err)
+		}
+		frame.Fields = append(frame.Fields, field)
+	}
+	return frame, nil
+}
+
+func newColumnarField(column es.ColumnarResponseColumn, values []any) (*data.Field, error) {
+	switch column.Type {
+	case "date", "datetime", "date_nanos":
+		times := make([]*time.Time, len(values))
+		for i, v := range values {
+			switch t := v.(type) {
+			case string:
+				parsed, err := time.Parse(time.RFC3339Nano, t)
+				if err != nil {
+					return nil, err
+				}
+				times[i] = &parsed
+			case json.Number:
+				ms, err := t.Int64()
+				if err != nil {
+					return nil, err
+				}
+				parsed := time.UnixMilli(ms).UTC()
+				times[i] = &parsed
+			}
+		}
+		return data.NewField(column.Name, nil, times), nil
+	case "long", "integer", "short", "byte", "counter_long", "counter_integer":
+		ints := make([]*int64, len(values))
+		for i, v := range values {
+			if n, ok := v.(json.Number); ok {
+				parsed, err := n.Int64()
+				if err != nil {
+					return nil, err
+				}
+				ints[i] = &parsed
+			}
+		}
+		return data.NewField(column.Name, nil, ints), nil
+	case "double", "float", "half_float", "scaled_float", "unsigned_long", "counter_double":
+		floats := make([]*float64, len(values))
+		for i, v := range values {
+			if n, ok := v.(json.Number); ok {
+				parsed, err := n.Float64()
+				if err != nil {
+					return nil, err
+				}
+				floats[i] = &parsed
+			}
+		}
+		return data.NewField(column.Name, nil, floats), nil
+	case "boolean":
+		bools := make([]*bool, len(values))
+		for i, v := range values {
+			if b, ok := v.(bool); ok {
+				bools[i] = &b
+			}
+		}
+		return data.NewField(column.Name, nil, bools), nil
+	default:
+		// keywords, texts, IPs and versions are strings, the other types such as the geo points
+		// and the multi-valued fields are kept as JSON
+		strs := make([]*string, len(values))
+		for i, v := range values {
+			switch s := v.(type) {
+			case nil:
+			case string:
+				strs[i] = &s
+			default:
+				b, err := json.Marshal(s)
+				if err != nil {
+					return nil, err
+				}
+				str := string(b)
+				strs[i] = &str
+			}
+		}
+		return data.NewField(column.Name, nil, strs), nil
+	}
+}
+
+// toTimeSeriesIfPossible converts the frames which have a time field and numeric fields to wide time
+// series, with the string fields as labels, so that they can be used by alerting and the time series panels.
+// The other frames are returned as they are.
+func toTimeSeriesIfPossible(frame *data.Frame) (*data.Frame, error) {
+	schema := frame.TimeSeriesSchema()
+	if schema.Type == data.TimeSeriesTypeNot {
+		return frame, nil
+	}
+
+	frame = sortFrameByTime(frame, schema.TimeIndex)
+	if schema.Type == data.TimeSeriesTypeLong {
+		return data.LongToWide(frame, nil)
+	}
+	return frame, nil
+}
+
+// sortFrameByTime returns the rows of the frame sorted by time, without the rows which have no time.
+func sortFrameByTime(frame *data.Frame, timeIndex int) *data.Frame {
+	timeField := frame.Fields[timeIndex]
+	rows := make([]int, 0, frame.Rows())
+	for i := 0; i < frame.Rows(); i++ {
+		if _, ok := timeField.ConcreteAt(i); ok {
+			rows = append(rows, i)
+		}
+	}
+	sort.SliceStable(rows, func(a, b int) bool {
+		ta, _ := timeField.ConcreteAt(rows[a])
+		tb, _ := timeField.ConcreteAt(rows[b])
+		return ta.(time.Time).Before(tb.(time.Time))
+	})
+
+	sorted := frame.EmptyCopy()
+	for _, row := range rows {
+		sorted.AppendRow(frame.RowCopy(row)...)
+	}
+
+	// the time field of wide time series can't be nullable
+	if timeField.Nullable() {
+		times := make([]time.Time, sorted.Rows())
+		for i := range times {
+			t, _ := sorted.Fields[timeIndex].ConcreteAt(i)
+			times[i] = t.(time.Time)
+		}
+		sorted.Fields[timeIndex] = data.NewField(timeField.Name, timeField.Labels, times)
+	}
+	return sorted
+}
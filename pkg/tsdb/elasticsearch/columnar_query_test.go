@@ -0,0 +1,163 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	es "github.com/grafana/grafana/pkg/tsdb/elasticsearch/client"
+)
+
+func TestInterpolateColumnarQuery(t *testing.T) {
+	timeRange := backend.TimeRange{
+		From: time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC),
+		To:   time.Date(2024, 1, 1, 11, 0, 0, 0, time.UTC),
+	}
+
+	t.Run("ES|QL macros", func(t *testing.T) {
+		q := &Query{
+			QueryType:  esqlQueryType,
+			RawQuery:   "FROM logs | WHERE $__timeFilter() AND $__timeFilter(event.created) | STATS c = COUNT(*) BY BUCKET(@timestamp, $__interval) | EVAL ms = $__interval_ms",
+			TimeRange:  timeRange,
+			IntervalMs: 30000,
+		}
+		query, err := interpolateColumnarQuery(q, "@timestamp")
+		require.NoError(t, err)
+		assert.Equal(t, `FROM logs | WHERE @timestamp >= TO_DATETIME("2024-01-01T10:00:00.000Z") AND @timestamp <= TO_DATETIME("2024-01-01T11:00:00.000Z") AND `+
+			`event.created >= TO_DATETIME("2024-01-01T10:00:00.000Z") AND event.created <= TO_DATETIME("2024-01-01T11:00:00.000Z") | `+
+			`STATS c = COUNT(*) BY BUCKET(@timestamp, 30 seconds) | EVAL ms = 30000`, query)
+	})
+
+	t.Run("SQL macros", func(t *testing.T) {
+		q := &Query{
+			QueryType: sqlQueryType,
+			RawQuery:  "SELECT HISTOGRAM(\"@timestamp\", $__interval) AS t, COUNT(*) FROM logs WHERE $__timeFilter() AND updated < $__timeTo() AND updated > $__timeFrom() GROUP BY t",
+			TimeRange: timeRange,
+			Interval:  500 * time.Millisecond,
+		}
+		query, err := interpolateColumnarQuery(q, "@timestamp")
+		require.NoError(t, err)
+		assert.Equal(t, `SELECT HISTOGRAM("@timestamp", INTERVAL 1 SECONDS) AS t, COUNT(*) FROM logs WHERE `+
+			`"@timestamp" >= CAST('2024-01-01T10:00:00.000Z' AS DATETIME) AND "@timestamp" <= CAST('2024-01-01T11:00:00.000Z' AS DATETIME) AND `+
+			`updated < CAST('2024-01-01T11:00:00.000Z' AS DATETIME) AND updated > CAST('2024-01-01T10:00:00.000Z' AS DATETIME) GROUP BY t`, query)
+	})
+
+	t.Run("time filter without a time field", func(t *testing.T) {
+		q := &Query{QueryType: esqlQueryType, RawQuery: "FROM logs | WHERE $__timeFilter()", TimeRange: timeRange}
+		_, err := interpolateColumnarQuery(q, "")
+		require.Error(t, err)
+	})
+}
+
+func TestColumnarResponseToFrame(t *testing.T) {
+	t.Run("converts the columns to typed fields", func(t *testing.T) {
+		res := &es.ColumnarResponse{
+			Columns: []es.ColumnarResponseColumn{
+				{Name: "@timestamp", Type: "date"},
+				{Name: "bytes", Type: "long"},
+				{Name: "ratio", Type: "double"},
+				{Name: "ok", Type: "boolean"},
+				{Name: "tags", Type: "keyword"},
+			},
+			Values: [][]any{
+				{"2024-01-01T10:00:00.000Z", nil},
+				{json.Number("12"), nil},
+				{json.Number("0.5"), nil},
+				{true, nil},
+				{[]any{"a", "b"}, "c"},
+			},
+		}
+
+		frame, err := columnarResponseToFrame(res, "A")
+		require.NoError(t, err)
+		require.Len(t, frame.Fields, 5)
+		require.Equal(t, 2, frame.Rows())
+
+		assert.Equal(t, data.FieldTypeNullableTime, frame.Fields[0].Type())
+		assert.Equal(t, time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC), *frame.Fields[0].At(0).(*time.Time))
+		assert.Equal(t, data.FieldTypeNullableInt64, frame.Fields[1].Type())
+		assert.Equal(t, int64(12), *frame.Fields[1].At(0).(*int64))
+		assert.Nil(t, frame.Fields[1].At(1))
+		assert.Equal(t, data.FieldTypeNullableFloat64, frame.Fields[2].Type())
+		assert.Equal(t, data.FieldTypeNullableBool, frame.Fields[3].Type())
+		assert.Equal(t, `["a","b"]`, *frame.Fields[4].At(0).(*string))
+		assert.Equal(t, "c", *frame.Fields[4].At(1).(*string))
+	})
+}
+
+func TestExecuteColumnarQuery(t *testing.T) {
+	from := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	to := from.Add(time.Hour)
+
+	t.Run("converts the rows with a time and a number to time series", func(t *testing.T) {
+		c := newFakeClient()
+		c.columnarResponse = &es.ColumnarResponse{
+			Columns: []es.ColumnarResponseColumn{
+				{Name: "t", Type: "date"},
+				{Name: "host", Type: "keyword"},
+				{Name: "c", Type: "long"},
+			},
+			Values: [][]any{
+				{"2024-01-01T10:01:00.000Z", "2024-01-01T10:00:00.000Z", "2024-01-01T10:00:00.000Z", "2024-01-01T10:01:00.000Z"},
+				{"a", "a", "b", "b"},
+				{json.Number("2"), json.Number("1"), json.Number("3"), json.Number("4")},
+			},
+		}
+
+		res, err := executeElasticsearchDataQuery(c, `{
+			"queryType": "esql",
+			"query": "FROM logs | WHERE $__timeFilter() | STATS c = COUNT(*) BY t = BUCKET(@timestamp, 1 minute), host"
+		}`, from, to)
+		require.NoError(t, err)
+		require.Len(t, c.esqlRequests, 1)
+		require.Empty(t, c.multisearchRequests)
+		assert.True(t, c.esqlRequests[0].Columnar)
+
+		frames := res.Responses["A"].Frames
+		require.NoError(t, res.Responses["A"].Error)
+		require.Len(t, frames, 1)
+		frame := frames[0]
+		assert.Equal(t, data.TimeSeriesTypeWide, frame.TimeSeriesSchema().Type)
+		require.Len(t, frame.Fields, 3)
+		assert.Equal(t, data.FieldTypeTime, frame.Fields[0].Type())
+		assert.Equal(t, from, frame.Fields[0].At(0))
+		assert.Equal(t, data.Labels{"host": "a"}, frame.Fields[1].Labels)
+		assert.Equal(t, int64(1), *frame.Fields[1].At(0).(*int64))
+		assert.Equal(t, int64(2), *frame.Fields[1].At(1).(*int64))
+		assert.Equal(t, data.Labels{"host": "b"}, frame.Fields[2].Labels)
+		assert.Contains(t, frame.Meta.ExecutedQueryString, `TO_DATETIME("2024-01-01T10:00:00.000Z")`)
+	})
+
+	t.Run("keeps the rows without numbers as a table", func(t *testing.T) {
+		c := newFakeClient()
+		c.columnarResponse = &es.ColumnarResponse{
+			Columns: []es.ColumnarResponseColumn{{Name: "host", Type: "keyword"}},
+			Values:  [][]any{{"a", "b"}},
+			Cursor:  "next",
+		}
+
+		res, err := executeElasticsearchDataQuery(c, `{"queryType": "sql", "query": "SELECT host FROM logs"}`, from, to)
+		require.NoError(t, err)
+		require.Len(t, c.sqlRequests, 1)
+		assert.Equal(t, sqlFetchSize, c.sqlRequests[0].FetchSize)
+
+		frame := res.Responses["A"].Frames[0]
+		assert.Equal(t, 2, frame.Rows())
+		require.Len(t, frame.Meta.Notices, 1)
+	})
+
+	t.Run("returns the errors of the query", func(t *testing.T) {
+		c := newFakeClient()
+		c.columnarError = &es.ColumnarQueryError{StatusCode: 400, Type: "parsing_exception", Reason: "line 1:1: mismatched input"}
+
+		res, err := executeElasticsearchDataQuery(c, `{"queryType": "esql", "query": "FROMM logs"}`, from, to)
+		require.NoError(t, err)
+		require.EqualError(t, res.Responses["A"].Error, "parsing_exception: line 1:1: mismatched input")
+		assert.Equal(t, backend.ErrorSourceDownstream, res.Responses["A"].ErrorSource)
+	})
+}
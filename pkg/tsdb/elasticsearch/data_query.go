@@ -58,6 +58,20 @@ func (e *elasticsearchDataQuery) execute() (*backend.QueryDataResponse, error) {
 		return errorsource.AddPluginErrorToResponse(e.dataQueries[0].RefID, response, err), nil
 	}
 
+	// ES|QL and SQL queries are sent to their own endpoints, the other queries are sent together in a multisearch request
+	searchQueries := make([]*Query, 0, len(queries))
+	for _, q := range queries {
+		if isColumnarQuery(q) {
+			response.Responses[q.RefID] = e.executeColumnarQuery(q)
+			continue
+		}
+		searchQueries = append(searchQueries, q)
+	}
+	if len(searchQueries) == 0 {
+		return response, nil
+	}
+	queries = searchQueries
+
 	ms := e.client.MultiSearch()
 
 	for _, q := range queries {
@@ -84,7 +98,14 @@ func (e *elasticsearchDataQuery) execute() (*backend.QueryDataResponse, error) {
 		return errorsource.AddErrorToResponse(e.dataQueries[0].RefID, response, err), nil
 	}
 
-	return parseResponse(e.ctx, res.Responses, queries, e.client.GetConfiguredFields(), e.keepLabelsInResponse, e.logger, e.tracer)
+	result, err := parseResponse(e.ctx, res.Responses, queries, e.client.GetConfiguredFields(), e.keepLabelsInResponse, e.logger, e.tracer)
+	if err != nil {
+		return result, err
+	}
+	for refID, columnarResponse := range response.Responses {
+		result.Responses[refID] = columnarResponse
+	}
+	return result, nil
 }
 
 func (e *elasticsearchDataQuery) processQuery(q *Query, ms *es.MultiSearchRequestBuilder, from, to int64) error {
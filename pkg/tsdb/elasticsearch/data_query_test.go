@@ -1806,6 +1806,10 @@ type fakeClient struct {
 	multiSearchError    error
 	builder             *es.MultiSearchRequestBuilder
 	multisearchRequests []*es.MultiSearchRequest
+	columnarResponse    *es.ColumnarResponse
+	columnarError       error
+	esqlRequests        []*es.ESQLRequest
+	sqlRequests         []*es.SQLRequest
 }
 
 func newFakeClient() *fakeClient {
@@ -1836,6 +1840,16 @@ func (c *fakeClient) MultiSearch() *es.MultiSearchRequestBuilder {
 	return c.builder
 }
 
+func (c *fakeClient) ExecuteESQL(r *es.ESQLRequest) (*es.ColumnarResponse, error) {
+	c.esqlRequests = append(c.esqlRequests, r)
+	return c.columnarResponse, c.columnarError
+}
+
+func (c *fakeClient) ExecuteSQL(r *es.SQLRequest) (*es.ColumnarResponse, error) {
+	c.sqlRequests = append(c.sqlRequests, r)
+	return c.columnarResponse, c.columnarError
+}
+
 func newDataQuery(body string) (backend.QueryDataRequest, error) {
 	return backend.QueryDataRequest{
 		Queries: []backend.DataQuery{
@@ -9,6 +9,8 @@ import (
 
 // Query represents the time series query model of the datasource
 type Query struct {
+	// QueryType is empty for the queries built with the query DSL, or one of the ES|QL and SQL query types
+	QueryType     string       `json:"queryType"`
 	RawQuery      string       `json:"query"`
 	BucketAggs    []*BucketAgg `json:"bucketAggs"`
 	Metrics       []*MetricAgg `json:"metrics"`
@@ -33,7 +33,13 @@ func parseQuery(tsdbQuery []backend.DataQuery, logger log.Logger) ([]*Query, err
 		intervalMs := model.Get("intervalMs").MustInt64(0)
 		interval := q.Interval
 
+		queryType := q.QueryType
+		if queryType == "" {
+			queryType = model.Get("queryType").MustString("")
+		}
+
 		queries = append(queries, &Query{
+			QueryType:     queryType,
 			RawQuery:      rawQuery,
 			BucketAggs:    bucketAggs,
 			Metrics:       metrics,
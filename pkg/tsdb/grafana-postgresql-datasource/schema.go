@@ -0,0 +1,51 @@
+package postgres
+
+import "github.com/grafana/grafana/pkg/tsdb/sqleng"
+
+// postgresSchemaDialect lists the schemas of the database of the connection as the databases, since
+// Postgres can't query the tables of other databases. The information_schema views only list the
+// tables the user of the data source has privileges on.
+type postgresSchemaDialect struct{}
+
+var _ sqleng.SchemaDialect = postgresSchemaDialect{}
+
+const excludedSchemas = `('information_schema', 'pg_catalog', '_timescaledb_cache', '_timescaledb_catalog',
+	'_timescaledb_internal', '_timescaledb_config', 'timescaledb_information', 'timescaledb_experimental')`
+
+func (postgresSchemaDialect) DatabasesQuery() string {
+	return `SELECT DISTINCT table_schema FROM information_schema.tables
+		WHERE table_schema NOT IN ` + excludedSchemas + `
+		ORDER BY table_schema`
+}
+
+func (postgresSchemaDialect) TablesQuery(database string) (string, []any) {
+	if database == "" {
+		return `SELECT table_name FROM information_schema.tables WHERE table_schema = current_schema() ORDER BY table_name`, nil
+	}
+	return `SELECT table_name FROM information_schema.tables WHERE table_schema = $1 ORDER BY table_name`, []any{database}
+}
+
+func (postgresSchemaDialect) ColumnsQuery(database, table string) (string, []any) {
+	if database == "" {
+		return `SELECT column_name, data_type FROM information_schema.columns
+			WHERE table_schema = current_schema() AND table_name = $1 ORDER BY ordinal_position`, []any{table}
+	}
+	return `SELECT column_name, data_type FROM information_schema.columns
+		WHERE table_schema = $1 AND table_name = $2 ORDER BY ordinal_position`, []any{database, table}
+}
+
+func (postgresSchemaDialect) FunctionsQuery(database string) (string, []any) {
+	if database == "" {
+		return `SELECT DISTINCT routine_name FROM information_schema.routines
+			WHERE routine_type = 'FUNCTION' AND routine_schema = current_schema() ORDER BY routine_name`, nil
+	}
+	return `SELECT DISTINCT routine_name FROM information_schema.routines
+		WHERE routine_type = 'FUNCTION' AND routine_schema = $1 ORDER BY routine_name`, []any{database}
+}
+
+func (postgresSchemaDialect) BuiltinFunctions() []string {
+	return []string{
+		"avg", "bit_and", "bit_or", "bool_and", "bool_or", "count", "max", "min", "percentile_cont",
+		"percentile_disc", "stddev", "stddev_pop", "stddev_samp", "string_agg", "sum", "variance",
+	}
+}
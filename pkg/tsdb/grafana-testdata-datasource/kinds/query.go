@@ -65,6 +65,7 @@ const (
 	TestDataQueryTypeRandomWalkTable              TestDataQueryType = "random_walk_table"
 	TestDataQueryTypeRandomWalkWithError          TestDataQueryType = "random_walk_with_error"
 	TestDataQueryTypeRawFrame                     TestDataQueryType = "raw_frame"
+	TestDataQueryTypeReplay                       TestDataQueryType = "replay"
 	TestDataQueryTypeServerError500               TestDataQueryType = "server_error_500"
 	TestDataQueryTypeSimulation                   TestDataQueryType = "simulation"
 	TestDataQueryTypeSlowQuery                    TestDataQueryType = "slow_query"
@@ -110,6 +111,7 @@ type TestDataQuery struct {
 
 	Nodes     *NodesQuery      `json:"nodes,omitempty"`
 	PulseWave *PulseWaveQuery  `json:"pulseWave,omitempty"`
+	Replay    *ReplayQuery     `json:"replay,omitempty"`
 	Sim       *SimulationQuery `json:"sim,omitempty"`
 	Stream    *StreamingQuery  `json:"stream,omitempty"`
 	Usa       *USAQuery        `json:"usa,omitempty"`
@@ -139,6 +141,14 @@ type PulseWaveQuery struct {
 	TimeStep int64   `json:"timeStep,omitempty"`
 }
 
+// ReplayQuery defines model for ReplayQuery.
+type ReplayQuery struct {
+	// The recording of a query response, as returned by the recordings admin API
+	Recording string `json:"recording,omitempty"`
+	// Keep the recorded timestamps instead of aligning them with the time range of the query
+	DisableTimeShift bool `json:"disableTimeShift,omitempty"`
+}
+
 // SimulationQuery defines model for SimulationQuery.
 type SimulationQuery struct {
 	Config map[string]any `json:"config,omitempty"`
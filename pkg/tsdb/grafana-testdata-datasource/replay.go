@@ -0,0 +1,97 @@
+package testdatasource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// Recording is the captured response of a data source query, which the replay scenario returns again.
+type Recording struct {
+	// RecordedAt is the end of the time range of the recorded query, in epoch milliseconds. The recorded timestamps
+	// are shifted by the time between it and the end of the time range of the replaying query.
+	RecordedAt int64       `json:"recordedAt"`
+	Frames     data.Frames `json:"frames"`
+}
+
+// NewRecording returns the recording of the frames of a query whose time range ended at the given time.
+func NewRecording(to time.Time, frames data.Frames) Recording {
+	if frames == nil {
+		frames = data.Frames{}
+	}
+	return Recording{
+		RecordedAt: to.UnixMilli(),
+		Frames:     frames,
+	}
+}
+
+// ParseRecording parses a recording, as stored in the replay scenario.
+func ParseRecording(content string) (Recording, error) {
+	var recording Recording
+	if err := json.Unmarshal([]byte(content), &recording); err != nil {
+		return Recording{}, err
+	}
+	if recording.RecordedAt <= 0 {
+		return Recording{}, fmt.Errorf("the recording has no recordedAt time")
+	}
+	return recording, nil
+}
+
+func (s *Service) handleReplayScenario(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	resp := backend.NewQueryDataResponse()
+
+	for _, q := range req.Queries {
+		model, err := GetJSONModel(q.JSON)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse query json: %v", err)
+		}
+
+		if model.Replay == nil || model.Replay.Recording == "" {
+			resp.Responses[q.RefID] = backend.DataResponse{}
+			continue
+		}
+
+		recording, err := ParseRecording(model.Replay.Recording)
+		if err != nil {
+			resp.Responses[q.RefID] = backend.ErrDataResponse(backend.StatusBadRequest, fmt.Sprintf("invalid recording: %v", err))
+			continue
+		}
+
+		if !model.Replay.DisableTimeShift {
+			shift := q.TimeRange.To.Sub(time.UnixMilli(recording.RecordedAt))
+			for _, frame := range recording.Frames {
+				shiftTimes(frame, shift)
+			}
+		}
+
+		for _, frame := range recording.Frames {
+			frame.RefID = q.RefID
+		}
+		resp.Responses[q.RefID] = backend.DataResponse{Frames: recording.Frames}
+	}
+
+	return resp, nil
+}
+
+// shiftTimes adds the duration to the values of the time fields of the frame.
+func shiftTimes(frame *data.Frame, d time.Duration) {
+	for _, field := range frame.Fields {
+		switch field.Type() {
+		case data.FieldTypeTime:
+			for i := 0; i < field.Len(); i++ {
+				field.Set(i, field.At(i).(time.Time).Add(d))
+			}
+		case data.FieldTypeNullableTime:
+			for i := 0; i < field.Len(); i++ {
+				if t, ok := field.ConcreteAt(i); ok {
+					shifted := t.(time.Time).Add(d)
+					field.Set(i, &shifted)
+				}
+			}
+		}
+	}
+}
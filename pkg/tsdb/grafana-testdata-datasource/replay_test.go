@@ -0,0 +1,81 @@
+package testdatasource
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/tsdb/grafana-testdata-datasource/kinds"
+)
+
+func TestReplayScenario(t *testing.T) {
+	s := &Service{}
+	recordedAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	end := recordedAt.Add(-time.Minute)
+
+	recording := NewRecording(recordedAt, data.Frames{
+		data.NewFrame("cpu",
+			data.NewField("time", nil, []time.Time{recordedAt.Add(-2 * time.Minute), recordedAt.Add(-time.Minute)}),
+			data.NewField("end", nil, []*time.Time{nil, &end}),
+			data.NewField("value", data.Labels{"host": "a"}, []float64{1, 2}),
+		),
+	})
+	content, err := json.Marshal(recording)
+	require.NoError(t, err)
+
+	replay := func(t *testing.T, to time.Time, replay *kinds.ReplayQuery) backend.DataResponse {
+		t.Helper()
+		query, err := json.Marshal(kinds.TestDataQuery{ScenarioId: kinds.TestDataQueryTypeReplay, Replay: replay})
+		require.NoError(t, err)
+		resp, err := s.handleReplayScenario(context.Background(), &backend.QueryDataRequest{
+			Queries: []backend.DataQuery{{
+				RefID:     "B",
+				TimeRange: backend.TimeRange{From: to.Add(-time.Hour), To: to},
+				JSON:      query,
+			}},
+		})
+		require.NoError(t, err)
+		return resp.Responses["B"]
+	}
+
+	t.Run("shifts the recorded times to the time range of the query", func(t *testing.T) {
+		now := time.Date(2024, 6, 1, 8, 30, 0, 0, time.UTC)
+		shift := now.Sub(recordedAt)
+
+		res := replay(t, now, &kinds.ReplayQuery{Recording: string(content)})
+		require.NoError(t, res.Error)
+		require.Len(t, res.Frames, 1)
+
+		frame := res.Frames[0]
+		require.Equal(t, "B", frame.RefID)
+		require.Equal(t, recordedAt.Add(-2*time.Minute).Add(shift), frame.Fields[0].At(0).(time.Time).UTC())
+		require.Equal(t, recordedAt.Add(-time.Minute).Add(shift), frame.Fields[0].At(1).(time.Time).UTC())
+		require.Nil(t, frame.Fields[1].At(0))
+		require.Equal(t, end.Add(shift), frame.Fields[1].At(1).(*time.Time).UTC())
+		require.Equal(t, 2.0, frame.Fields[2].At(1))
+		require.Equal(t, data.Labels{"host": "a"}, frame.Fields[2].Labels)
+	})
+
+	t.Run("keeps the recorded times when the time shift is disabled", func(t *testing.T) {
+		res := replay(t, time.Now(), &kinds.ReplayQuery{Recording: string(content), DisableTimeShift: true})
+		require.NoError(t, res.Error)
+		require.Equal(t, recordedAt.Add(-2*time.Minute), res.Frames[0].Fields[0].At(0).(time.Time).UTC())
+	})
+
+	t.Run("returns no data without a recording", func(t *testing.T) {
+		res := replay(t, time.Now(), nil)
+		require.NoError(t, res.Error)
+		require.Empty(t, res.Frames)
+	})
+
+	t.Run("returns an error for an invalid recording", func(t *testing.T) {
+		res := replay(t, time.Now(), &kinds.ReplayQuery{Recording: `{"frames": []}`})
+		require.ErrorContains(t, res.Error, "invalid recording")
+		require.Equal(t, backend.StatusBadRequest, res.Status)
+	})
+}
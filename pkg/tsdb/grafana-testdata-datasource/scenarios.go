@@ -193,6 +193,13 @@ Timestamps will line up evenly on timeStepSeconds (For example, 60 seconds means
 		handler: s.handleCsvContentScenario,
 	})
 
+	s.registerScenario(&Scenario{
+		ID:          kinds.TestDataQueryTypeReplay,
+		Name:        "Replay",
+		handler:     s.handleReplayScenario,
+		Description: "Returns the frames of a recorded query response, with their timestamps aligned with the time range of the query.",
+	})
+
 	s.registerScenario(&Scenario{
 		ID:   kinds.TestDataQueryTypeTrace,
 		Name: "Trace",
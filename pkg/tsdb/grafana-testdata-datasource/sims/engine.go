@@ -66,6 +66,9 @@ func NewSimulationEngine() (*SimulationEngine, error) {
 		newFlightSimInfo,
 		newSinewaveInfo,
 		newTankSimInfo,
+		newQueueSimInfo,
+		newKubernetesSimInfo,
+		newSLOSimInfo,
 	}
 
 	for _, init := range initializers {
@@ -291,11 +294,17 @@ func (s *SimulationEngine) RunStream(ctx context.Context, req *backend.RunStream
 			return ctx.Err()
 
 		case t := <-ticker.C:
+			// the fields of some simulations depend on their config, which can change while streaming
+			if next := sim.NewFrame(1); !sameFields(frame, next) {
+				frame = next
+				mode = data.IncludeAll
+			}
 			setFrameRow(frame, 0, sim.GetValues(t))
 			err := sender.SendFrame(frame, mode)
 			if err != nil {
 				return err
 			}
+			mode = data.IncludeDataOnly
 		}
 	}
 }
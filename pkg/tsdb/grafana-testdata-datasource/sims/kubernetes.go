@@ -0,0 +1,247 @@
+package sims
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+const (
+	// kubernetesStep is the longest time step of the simulation of the pods.
+	kubernetesStep = time.Second
+	// kubernetesMaxCatchUp is the longest time simulated at once, the simulation is paused for the rest of longer gaps.
+	kubernetesMaxCatchUp = time.Hour
+	// kubernetesRestartDelay is the time a restarted container is not ready.
+	kubernetesRestartDelay = 10 * time.Second
+	mebibyte               = 1024 * 1024
+)
+
+// kubernetesSim simulates the containers of the pods of a deployment, whose CPU usage varies around the load, and
+// whose memory grows with the memory leak until they are OOM killed. The containers can also crash randomly.
+type kubernetesSim struct {
+	key   simulationKey
+	cfg   kubernetesConfig
+	state kubernetesState
+	rand  *rand.Rand
+	mutex sync.Mutex
+}
+
+var (
+	_ Simulation = (*kubernetesSim)(nil)
+)
+
+type kubernetesConfig struct {
+	Namespace   string  `json:"namespace"`
+	App         string  `json:"app"`
+	Pods        float64 `json:"pods"`
+	Nodes       float64 `json:"nodes"`
+	CPULimit    float64 `json:"cpuLimit"`    // cores
+	MemoryLimit float64 `json:"memoryLimit"` // MiB
+	MemoryBase  float64 `json:"memoryBase"`  // MiB used by a container after it starts
+	Load        float64 `json:"load"`        // average CPU usage, as a ratio of the limit
+	MemoryLeak  float64 `json:"memoryLeak"`  // MiB per minute
+	CrashRate   float64 `json:"crashRate"`   // crashes per hour of each container
+	Seed        int64   `json:"seed"`
+}
+
+type kubernetesPod struct {
+	cpu        float64 // cores
+	memory     float64 // MiB
+	restarts   float64
+	readyAfter time.Time
+}
+
+type kubernetesState struct {
+	time time.Time
+	pods []kubernetesPod
+}
+
+func (s *kubernetesSim) GetState() simulationState {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return simulationState{
+		Key:    s.key,
+		Config: s.cfg,
+	}
+}
+
+func (s *kubernetesSim) SetConfig(vals map[string]any) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return updateConfigObjectFromJSON(&s.cfg, vals)
+}
+
+// podLabels returns the labels of the series of the pods.
+func (s *kubernetesSim) podLabels() []data.Labels {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.labels()
+}
+
+func (s *kubernetesSim) labels() []data.Labels {
+	pods := int(s.cfg.Pods)
+	nodes := int(math.Max(1, s.cfg.Nodes))
+	labels := make([]data.Labels, 0, pods)
+	for i := 0; i < pods; i++ {
+		labels = append(labels, data.Labels{
+			"namespace": s.cfg.Namespace,
+			"app":       s.cfg.App,
+			"pod":       fmt.Sprintf("%s-%d", s.cfg.App, i),
+			"node":      fmt.Sprintf("node-%d", i%nodes),
+		})
+	}
+	return labels
+}
+
+func (s *kubernetesSim) NewFrame(size int) *data.Frame {
+	frame := data.NewFrame("", data.NewField("time", nil, make([]time.Time, size)))
+	for _, labels := range s.podLabels() {
+		frame.Fields = append(frame.Fields,
+			data.NewField("cpu_usage", labels, make([]float64, size)).SetConfig(&data.FieldConfig{Unit: "short"}),
+			data.NewField("memory_usage", labels, make([]float64, size)).SetConfig(&data.FieldConfig{Unit: "bytes"}),
+			data.NewField("restarts", labels, make([]float64, size)),
+			data.NewField("ready", labels, make([]bool, size)),
+		)
+	}
+	return frame
+}
+
+func (s *kubernetesSim) GetValues(t time.Time) map[string]any {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if t.Before(s.state.time) {
+		return nil // can not look backwards!
+	}
+	if s.state.time.IsZero() {
+		s.state.time = t
+	}
+	if gap := t.Sub(s.state.time); gap > kubernetesMaxCatchUp {
+		s.state.time = s.state.time.Add(gap - kubernetesMaxCatchUp)
+	}
+
+	// the pods are scaled as the config changes
+	pods := int(math.Max(0, s.cfg.Pods))
+	for len(s.state.pods) < pods {
+		s.state.pods = append(s.state.pods, s.startPod(s.state.time))
+	}
+	s.state.pods = s.state.pods[:pods]
+
+	for s.state.time.Before(t) {
+		step := t.Sub(s.state.time)
+		if step > kubernetesStep {
+			step = kubernetesStep
+		}
+		s.state.time = s.state.time.Add(step)
+		for i := range s.state.pods {
+			s.stepPod(&s.state.pods[i], s.state.time, step.Seconds())
+		}
+	}
+
+	values := map[string]any{
+		"time": t,
+	}
+	for i, labels := range s.labels() {
+		pod := s.state.pods[i]
+		values[valueKey("cpu_usage", labels)] = pod.cpu
+		values[valueKey("memory_usage", labels)] = pod.memory * mebibyte
+		values[valueKey("restarts", labels)] = pod.restarts
+		values[valueKey("ready", labels)] = !t.Before(pod.readyAfter)
+	}
+	return values
+}
+
+func (s *kubernetesSim) startPod(t time.Time) kubernetesPod {
+	return kubernetesPod{
+		cpu:        s.cfg.Load * s.cfg.CPULimit,
+		memory:     s.cfg.MemoryBase * (0.9 + 0.2*s.rand.Float64()),
+		readyAfter: t,
+	}
+}
+
+// stepPod updates the usage of a container over a time step of the seconds.
+func (s *kubernetesSim) stepPod(pod *kubernetesPod, t time.Time, seconds float64) {
+	if t.Before(pod.readyAfter) {
+		// the container is restarting
+		pod.cpu = 0
+		return
+	}
+
+	// the CPU usage reverts to the load, and is throttled at the limit
+	target := s.cfg.Load * s.cfg.CPULimit
+	pod.cpu += (target-pod.cpu)*math.Min(1, seconds/30) + s.rand.NormFloat64()*0.05*s.cfg.CPULimit*math.Sqrt(seconds)
+	pod.cpu = math.Max(0, math.Min(s.cfg.CPULimit, pod.cpu))
+
+	pod.memory += s.cfg.MemoryLeak*seconds/60 + s.rand.NormFloat64()*0.002*s.cfg.MemoryLimit*math.Sqrt(seconds)
+	pod.memory = math.Max(s.cfg.MemoryBase*0.5, pod.memory)
+
+	crashed := s.cfg.CrashRate > 0 && s.rand.Float64() < s.cfg.CrashRate*seconds/3600
+	if pod.memory >= s.cfg.MemoryLimit || crashed {
+		restarts := pod.restarts + 1
+		*pod = s.startPod(t.Add(kubernetesRestartDelay))
+		pod.restarts = restarts
+	}
+}
+
+func (s *kubernetesSim) Close() error {
+	return nil
+}
+
+func newKubernetesSimInfo() simulationInfo {
+	kc := kubernetesConfig{
+		Namespace:   "default",
+		App:         "web",
+		Pods:        6,
+		Nodes:       3,
+		CPULimit:    1,
+		MemoryLimit: 512,
+		MemoryBase:  200,
+		Load:        0.5,
+		MemoryLeak:  0,
+		CrashRate:   0,
+		Seed:        1,
+	}
+
+	df := data.NewFrame("")
+	df.Fields = append(df.Fields, data.NewField("namespace", nil, []string{kc.Namespace}))
+	df.Fields = append(df.Fields, data.NewField("app", nil, []string{kc.App}))
+	df.Fields = append(df.Fields, data.NewField("pods", nil, []float64{kc.Pods}))
+	df.Fields = append(df.Fields, data.NewField("nodes", nil, []float64{kc.Nodes}))
+	df.Fields = append(df.Fields, data.NewField("cpuLimit", nil, []float64{kc.CPULimit}))
+	df.Fields = append(df.Fields, data.NewField("memoryLimit", nil, []float64{kc.MemoryLimit}).SetConfig(&data.FieldConfig{
+		Unit: "mbytes",
+	}))
+	df.Fields = append(df.Fields, data.NewField("memoryBase", nil, []float64{kc.MemoryBase}).SetConfig(&data.FieldConfig{
+		Unit: "mbytes",
+	}))
+	df.Fields = append(df.Fields, data.NewField("load", nil, []float64{kc.Load}).SetConfig(&data.FieldConfig{
+		Unit: "percentunit",
+	}))
+	df.Fields = append(df.Fields, data.NewField("memoryLeak", nil, []float64{kc.MemoryLeak}))
+	df.Fields = append(df.Fields, data.NewField("crashRate", nil, []float64{kc.CrashRate}))
+	df.Fields = append(df.Fields, data.NewField("seed", nil, []float64{float64(kc.Seed)}))
+
+	return simulationInfo{
+		Type:         "kubernetes",
+		Name:         "Kubernetes",
+		Description:  "Pods with CPU and memory usage, restarts and OOM kills",
+		ConfigFields: df,
+		OnlyForward:  true,
+		create: func(cfg simulationState) (Simulation, error) {
+			s := &kubernetesSim{
+				key: cfg.Key,
+				cfg: kc,
+			}
+			err := updateConfigObjectFromJSON(&s.cfg, cfg.Config) // override any fields
+			s.rand = rand.New(rand.NewSource(s.cfg.Seed))
+			return s, err
+		},
+	}
+}
@@ -0,0 +1,57 @@
+package sims
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKubernetesSimulation(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("returns the series of each pod", func(t *testing.T) {
+		sim := newTestSimulation(t, "kubernetes", map[string]any{"pods": 4, "nodes": 2, "app": "api"})
+		frame := runSimulation(sim, start, time.Minute)
+
+		require.Len(t, frame.Fields, 1+4*4)
+		require.Equal(t, data.Labels{"namespace": "default", "app": "api", "pod": "api-3", "node": "node-1"}, frame.Fields[13].Labels)
+		for _, field := range frame.Fields[1:] {
+			require.Equal(t, 61, field.Len())
+			if field.Name == "cpu_usage" {
+				cpu := field.At(60).(float64)
+				require.GreaterOrEqual(t, cpu, 0.0)
+				require.LessOrEqual(t, cpu, 1.0)
+			}
+		}
+	})
+
+	t.Run("restarts the pods running out of memory", func(t *testing.T) {
+		sim := newTestSimulation(t, "kubernetes", map[string]any{"pods": 2, "memoryBase": 200, "memoryLimit": 300, "memoryLeak": 60})
+		frame := runSimulation(sim, start, 10*time.Minute)
+
+		for _, field := range frame.Fields {
+			switch field.Name {
+			case "restarts":
+				require.GreaterOrEqual(t, field.At(field.Len()-1), 3.0)
+			case "memory_usage":
+				for i := 0; i < field.Len(); i++ {
+					require.Less(t, field.At(i), 300.0*mebibyte)
+				}
+			}
+		}
+	})
+
+	t.Run("scales the pods with the config", func(t *testing.T) {
+		sim := newTestSimulation(t, "kubernetes", map[string]any{"pods": 2})
+		before := sim.NewFrame(1)
+		require.NoError(t, sim.SetConfig(map[string]any{"pods": 3}))
+		after := sim.NewFrame(1)
+
+		require.False(t, sameFields(before, after))
+		require.Len(t, after.Fields, 1+3*4)
+		values := sim.GetValues(start)
+		require.Contains(t, values, valueKey("ready", after.Fields[12].Labels))
+	})
+}
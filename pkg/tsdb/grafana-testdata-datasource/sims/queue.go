@@ -0,0 +1,396 @@
+package sims
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+const (
+	// queueMaxCatchUp is the longest time simulated at once. The simulation is paused for the rest of longer gaps
+	// between the values, as the number of events to simulate grows with them.
+	queueMaxCatchUp = 5 * time.Minute
+	// queueMaxEvents is the maximum number of arrivals and departures simulated at once.
+	queueMaxEvents = 1_000_000
+	// queueRecentLatencies is the number of latest latencies the percentiles are computed from.
+	queueRecentLatencies = 1000
+	// queueRateWindow is the time constant of the moving averages of the rates.
+	queueRateWindow = 10 * time.Second
+)
+
+// queueLatencyBuckets are the upper bounds of the latency histogram buckets, in seconds.
+var queueLatencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// queueSim simulates a M/M/c queue: requests arrive with exponential inter-arrival times, and are served by c servers
+// with exponential service times. The requests arriving when all the servers are busy wait in a queue of limited
+// capacity, and are dropped when it is full.
+type queueSim struct {
+	key   simulationKey
+	cfg   queueConfig
+	state queueState
+	rand  *rand.Rand
+	mutex sync.Mutex
+}
+
+var (
+	_ Simulation = (*queueSim)(nil)
+)
+
+type queueConfig struct {
+	ArrivalRate   float64 `json:"arrivalRate"`   // requests per second (lambda)
+	ServiceRate   float64 `json:"serviceRate"`   // requests per second of each server (mu)
+	Servers       float64 `json:"servers"`       // number of servers (c)
+	QueueCapacity float64 `json:"queueCapacity"` // requests waiting for a server before the arrivals are dropped
+	Seed          int64   `json:"seed"`
+}
+
+type queueRequest struct {
+	arrival time.Time
+	done    time.Time
+}
+
+type queueState struct {
+	time        time.Time
+	nextArrival time.Time // zero when no arrival is scheduled
+	waiting     []time.Time
+	serving     []queueRequest
+
+	arrived    float64
+	served     float64
+	dropped    float64
+	buckets    []float64 // cumulative count of the latencies of each bucket, and of all of them for +Inf
+	latencySum float64
+	recent     []float64
+	recentNext int
+
+	arrivalRate float64
+	throughput  float64
+	dropRate    float64
+}
+
+func (s *queueSim) GetState() simulationState {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return simulationState{
+		Key:    s.key,
+		Config: s.cfg,
+	}
+}
+
+func (s *queueSim) SetConfig(vals map[string]any) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	err := updateConfigObjectFromJSON(&s.cfg, vals)
+	// the next arrival is scheduled again with the new arrival rate
+	s.state.nextArrival = time.Time{}
+	return err
+}
+
+func (s *queueSim) NewFrame(size int) *data.Frame {
+	frame := data.NewFrameOfFieldTypes("", size,
+		data.FieldTypeTime,    // time
+		data.FieldTypeFloat64, // queueLength
+		data.FieldTypeFloat64, // busyServers
+		data.FieldTypeFloat64, // utilization
+		data.FieldTypeFloat64, // arrivalRate
+		data.FieldTypeFloat64, // throughput
+		data.FieldTypeFloat64, // dropRate
+		data.FieldTypeFloat64, // dropRatio
+		data.FieldTypeFloat64, // requestsTotal
+		data.FieldTypeFloat64, // droppedTotal
+		data.FieldTypeFloat64, // latencyP50
+		data.FieldTypeFloat64, // latencyP95
+		data.FieldTypeFloat64, // latencyP99
+	)
+
+	var zero data.ConfFloat64 = 0.0
+	var one data.ConfFloat64 = 1.0
+
+	frame.Fields[0].Name = "time"
+	frame.Fields[1].Name = "queueLength"
+	frame.Fields[2].Name = "busyServers"
+	frame.Fields[3].Name = "utilization"
+	frame.Fields[3].Config = &data.FieldConfig{Unit: "percentunit", Min: &zero, Max: &one}
+	frame.Fields[4].Name = "arrivalRate"
+	frame.Fields[4].Config = &data.FieldConfig{Unit: "reqps"}
+	frame.Fields[5].Name = "throughput"
+	frame.Fields[5].Config = &data.FieldConfig{Unit: "reqps"}
+	frame.Fields[6].Name = "dropRate"
+	frame.Fields[6].Config = &data.FieldConfig{Unit: "reqps"}
+	frame.Fields[7].Name = "dropRatio"
+	frame.Fields[7].Config = &data.FieldConfig{Unit: "percentunit", Min: &zero, Max: &one}
+	frame.Fields[8].Name = "requestsTotal"
+	frame.Fields[9].Name = "droppedTotal"
+	frame.Fields[10].Name = "latencyP50"
+	frame.Fields[10].Config = &data.FieldConfig{Unit: "s"}
+	frame.Fields[11].Name = "latencyP95"
+	frame.Fields[11].Config = &data.FieldConfig{Unit: "s"}
+	frame.Fields[12].Name = "latencyP99"
+	frame.Fields[12].Config = &data.FieldConfig{Unit: "s"}
+
+	// the latency histogram, as the cumulative counters of the buckets of a Prometheus histogram
+	for _, le := range queueBucketLabels() {
+		frame.Fields = append(frame.Fields, data.NewField("latency_bucket", data.Labels{"le": le}, make([]float64, size)))
+	}
+	frame.Fields = append(frame.Fields,
+		data.NewField("latency_sum", nil, make([]float64, size)),
+		data.NewField("latency_count", nil, make([]float64, size)),
+	)
+	return frame
+}
+
+func (s *queueSim) GetValues(t time.Time) map[string]any {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if t.Before(s.state.time) {
+		return nil // can not look backwards!
+	}
+	if s.state.time.IsZero() {
+		s.state.time = t
+	}
+
+	prev := s.state
+	s.advance(t)
+	if elapsed := t.Sub(prev.time); elapsed > 0 {
+		seconds := elapsed.Seconds()
+		alpha := 1 - math.Exp(-seconds/queueRateWindow.Seconds())
+		s.state.arrivalRate += alpha * ((s.state.arrived-prev.arrived)/seconds - s.state.arrivalRate)
+		s.state.throughput += alpha * ((s.state.served-prev.served)/seconds - s.state.throughput)
+		s.state.dropRate += alpha * ((s.state.dropped-prev.dropped)/seconds - s.state.dropRate)
+	}
+	s.state.time = t
+
+	utilization := 0.0
+	if servers := int(s.cfg.Servers); servers > 0 {
+		utilization = math.Min(1, float64(len(s.state.serving))/float64(servers))
+	}
+	dropRatio := 0.0
+	if s.state.arrivalRate > 0 {
+		dropRatio = math.Min(1, s.state.dropRate/s.state.arrivalRate)
+	}
+
+	recent := append([]float64(nil), s.state.recent...)
+	sort.Float64s(recent)
+
+	values := map[string]any{
+		"time":          t,
+		"queueLength":   float64(len(s.state.waiting)),
+		"busyServers":   float64(len(s.state.serving)),
+		"utilization":   utilization,
+		"arrivalRate":   s.state.arrivalRate,
+		"throughput":    s.state.throughput,
+		"dropRate":      s.state.dropRate,
+		"dropRatio":     dropRatio,
+		"requestsTotal": s.state.arrived,
+		"droppedTotal":  s.state.dropped,
+		"latencyP50":    percentile(recent, 0.5),
+		"latencyP95":    percentile(recent, 0.95),
+		"latencyP99":    percentile(recent, 0.99),
+		"latency_sum":   s.state.latencySum,
+		"latency_count": s.state.served,
+	}
+	for i, le := range queueBucketLabels() {
+		count := 0.0
+		if i < len(s.state.buckets) {
+			count = s.state.buckets[i]
+		}
+		values[valueKey("latency_bucket", data.Labels{"le": le})] = count
+	}
+	return values
+}
+
+// advance simulates the arrivals and the departures until the time.
+func (s *queueSim) advance(t time.Time) {
+	if gap := t.Sub(s.state.time); gap > queueMaxCatchUp {
+		s.pause(gap - queueMaxCatchUp)
+	}
+
+	arrivalRate := math.Max(0, s.cfg.ArrivalRate)
+	if s.state.nextArrival.IsZero() && arrivalRate > 0 {
+		s.state.nextArrival = s.state.time.Add(s.exponential(arrivalRate))
+	}
+
+	for events := 0; ; events++ {
+		next := -1
+		for i, r := range s.state.serving {
+			if !r.done.IsZero() && (next < 0 || r.done.Before(s.state.serving[next].done)) {
+				next = i
+			}
+		}
+		arrival := !s.state.nextArrival.IsZero() && (next < 0 || s.state.nextArrival.Before(s.state.serving[next].done))
+
+		var eventTime time.Time
+		switch {
+		case arrival:
+			eventTime = s.state.nextArrival
+		case next >= 0:
+			eventTime = s.state.serving[next].done
+		default:
+			return
+		}
+		if eventTime.After(t) {
+			return
+		}
+		if events >= queueMaxEvents {
+			s.pause(t.Sub(eventTime))
+			return
+		}
+
+		if arrival {
+			s.arrive(eventTime)
+			if arrivalRate > 0 {
+				s.state.nextArrival = eventTime.Add(s.exponential(arrivalRate))
+			} else {
+				s.state.nextArrival = time.Time{}
+			}
+		} else {
+			s.depart(next)
+		}
+	}
+}
+
+func (s *queueSim) arrive(t time.Time) {
+	s.state.arrived++
+	switch {
+	case len(s.state.serving) < int(s.cfg.Servers):
+		s.state.serving = append(s.state.serving, queueRequest{arrival: t, done: s.serviceDone(t)})
+	case len(s.state.waiting) < int(s.cfg.QueueCapacity):
+		s.state.waiting = append(s.state.waiting, t)
+	default:
+		s.state.dropped++
+	}
+}
+
+// depart completes the request of a server, which then serves the first waiting request.
+func (s *queueSim) depart(server int) {
+	r := s.state.serving[server]
+	s.observe(r.done.Sub(r.arrival).Seconds())
+
+	if len(s.state.waiting) > 0 && len(s.state.serving) <= int(s.cfg.Servers) {
+		arrival := s.state.waiting[0]
+		s.state.waiting = s.state.waiting[1:]
+		s.state.serving[server] = queueRequest{arrival: arrival, done: s.serviceDone(r.done)}
+		return
+	}
+	s.state.serving = append(s.state.serving[:server], s.state.serving[server+1:]...)
+}
+
+func (s *queueSim) serviceDone(start time.Time) time.Time {
+	if s.cfg.ServiceRate <= 0 {
+		return time.Time{} // never
+	}
+	return start.Add(s.exponential(s.cfg.ServiceRate))
+}
+
+// observe records the latency of a served request.
+func (s *queueSim) observe(latency float64) {
+	if len(s.state.buckets) == 0 {
+		s.state.buckets = make([]float64, len(queueLatencyBuckets)+1)
+	}
+	for i, le := range queueLatencyBuckets {
+		if latency <= le {
+			s.state.buckets[i]++
+		}
+	}
+	s.state.buckets[len(queueLatencyBuckets)]++
+	s.state.latencySum += latency
+	s.state.served++
+
+	if len(s.state.recent) < queueRecentLatencies {
+		s.state.recent = append(s.state.recent, latency)
+		return
+	}
+	s.state.recent[s.state.recentNext] = latency
+	s.state.recentNext = (s.state.recentNext + 1) % queueRecentLatencies
+}
+
+// pause shifts the times of the state, as if the simulation had not been running for the duration.
+func (s *queueSim) pause(d time.Duration) {
+	s.state.time = s.state.time.Add(d)
+	if !s.state.nextArrival.IsZero() {
+		s.state.nextArrival = s.state.nextArrival.Add(d)
+	}
+	for i := range s.state.waiting {
+		s.state.waiting[i] = s.state.waiting[i].Add(d)
+	}
+	for i, r := range s.state.serving {
+		s.state.serving[i].arrival = r.arrival.Add(d)
+		if !r.done.IsZero() {
+			s.state.serving[i].done = r.done.Add(d)
+		}
+	}
+}
+
+func (s *queueSim) exponential(rate float64) time.Duration {
+	return time.Duration(s.rand.ExpFloat64() / rate * float64(time.Second))
+}
+
+func (s *queueSim) Close() error {
+	return nil
+}
+
+func queueBucketLabels() []string {
+	labels := make([]string, 0, len(queueLatencyBuckets)+1)
+	for _, le := range queueLatencyBuckets {
+		labels = append(labels, strconv.FormatFloat(le, 'f', -1, 64))
+	}
+	return append(labels, "+Inf")
+}
+
+// percentile returns the percentile of the sorted values, or 0 if there are none.
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	idx := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	return sorted[idx]
+}
+
+func newQueueSimInfo() simulationInfo {
+	qc := queueConfig{
+		ArrivalRate:   8,
+		ServiceRate:   1,
+		Servers:       10,
+		QueueCapacity: 50,
+		Seed:          1,
+	}
+
+	df := data.NewFrame("")
+	df.Fields = append(df.Fields, data.NewField("arrivalRate", nil, []float64{qc.ArrivalRate}).SetConfig(&data.FieldConfig{
+		Unit: "reqps",
+	}))
+	df.Fields = append(df.Fields, data.NewField("serviceRate", nil, []float64{qc.ServiceRate}).SetConfig(&data.FieldConfig{
+		Unit: "reqps",
+	}))
+	df.Fields = append(df.Fields, data.NewField("servers", nil, []float64{qc.Servers}))
+	df.Fields = append(df.Fields, data.NewField("queueCapacity", nil, []float64{qc.QueueCapacity}))
+	df.Fields = append(df.Fields, data.NewField("seed", nil, []float64{float64(qc.Seed)}))
+
+	return simulationInfo{
+		Type:         "queue",
+		Name:         "Queue",
+		Description:  "M/M/c queue with latency histograms and dropped requests",
+		ConfigFields: df,
+		OnlyForward:  true,
+		create: func(cfg simulationState) (Simulation, error) {
+			s := &queueSim{
+				key: cfg.Key,
+				cfg: qc,
+			}
+			err := updateConfigObjectFromJSON(&s.cfg, cfg.Config) // override any fields
+			s.rand = rand.New(rand.NewSource(s.cfg.Seed))
+			return s, err
+		},
+	}
+}
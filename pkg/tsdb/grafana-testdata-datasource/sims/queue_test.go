@@ -0,0 +1,75 @@
+package sims
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSimulation(t *testing.T, simType string, config map[string]any) Simulation {
+	t.Helper()
+
+	engine, err := NewSimulationEngine()
+	require.NoError(t, err)
+	sim, err := engine.Lookup(simulationState{
+		Key:    simulationKey{Type: simType, TickHZ: 1},
+		Config: config,
+	})
+	require.NoError(t, err)
+	return sim
+}
+
+// runSimulation returns the values of the simulation every second for the duration.
+func runSimulation(sim Simulation, start time.Time, d time.Duration) *data.Frame {
+	frame := sim.NewFrame(0)
+	for t := start; !t.After(start.Add(d)); t = t.Add(time.Second) {
+		appendFrameRow(frame, sim.GetValues(t))
+	}
+	return frame
+}
+
+func TestQueueSimulation(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("serves the requests when there are enough servers", func(t *testing.T) {
+		sim := newTestSimulation(t, "queue", map[string]any{"arrivalRate": 5, "serviceRate": 1, "servers": 20})
+		frame := runSimulation(sim, start, 10*time.Minute)
+		last := frame.Rows() - 1
+
+		values := sim.GetValues(start.Add(10 * time.Minute))
+		require.Equal(t, 0.0, values["droppedTotal"])
+		require.InDelta(t, 5, values["arrivalRate"], 1.5)
+		require.InDelta(t, 5, values["throughput"], 1.5)
+		require.InDelta(t, 0.25, values["utilization"], 0.25)
+		require.Greater(t, frame.Fields[8].At(last), 2000.0)
+
+		// the buckets of the latency histogram are cumulative
+		previous := 0.0
+		for _, le := range queueBucketLabels() {
+			count := values[valueKey("latency_bucket", data.Labels{"le": le})].(float64)
+			require.GreaterOrEqual(t, count, previous)
+			previous = count
+		}
+		require.Equal(t, values["latency_count"], previous)
+		require.InDelta(t, 1, values["latency_sum"].(float64)/values["latency_count"].(float64), 0.2)
+	})
+
+	t.Run("drops the requests when the queue is full", func(t *testing.T) {
+		sim := newTestSimulation(t, "queue", map[string]any{"arrivalRate": 20, "serviceRate": 1, "servers": 5, "queueCapacity": 10})
+		runSimulation(sim, start, 5*time.Minute)
+
+		values := sim.GetValues(start.Add(5 * time.Minute))
+		require.Equal(t, 1.0, values["utilization"])
+		require.InDelta(t, 10, values["queueLength"], 3)
+		require.InDelta(t, 0.75, values["dropRatio"], 0.1)
+		require.Greater(t, values["latencyP99"], values["latencyP50"])
+	})
+
+	t.Run("can not look backwards", func(t *testing.T) {
+		sim := newTestSimulation(t, "queue", nil)
+		require.NotNil(t, sim.GetValues(start))
+		require.Nil(t, sim.GetValues(start.Add(-time.Second)))
+	})
+}
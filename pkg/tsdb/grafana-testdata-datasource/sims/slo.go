@@ -0,0 +1,354 @@
+package sims
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+const (
+	// sloStep is the longest time step of the simulation of the requests.
+	sloStep = 10 * time.Second
+	// sloBurnRateBucket is the size of the buckets of the requests the burn rates are computed from.
+	sloBurnRateBucket = 10 * time.Second
+	// sloBurnRateWindow is the longest window of the burn rates.
+	sloBurnRateWindow = 6 * time.Hour
+	// sloBudgetBucket is the size of the buckets of the requests the error budget is computed from.
+	sloBudgetBucket = time.Minute
+	// sloMaxWindow is the longest SLO window.
+	sloMaxWindow = 90 * 24 * time.Hour
+)
+
+// sloSim simulates the requests of a service with an availability SLO, and the burn of its error budget. The SLO
+// window starts with the simulation, so the error budget is computed from the requests since then.
+type sloSim struct {
+	key   simulationKey
+	cfg   sloConfig
+	state sloState
+	rand  *rand.Rand
+	mutex sync.Mutex
+}
+
+var (
+	_ Simulation = (*sloSim)(nil)
+)
+
+type sloConfig struct {
+	Objective         float64 `json:"objective"`         // ratio of successful requests, such as 0.999
+	Window            float64 `json:"window"`            // days
+	RequestRate       float64 `json:"requestRate"`       // requests per second
+	ErrorRate         float64 `json:"errorRate"`         // ratio of failed requests
+	Incident          bool    `json:"incident"`          // fails the requests at the incident error rate
+	IncidentErrorRate float64 `json:"incidentErrorRate"` // ratio of failed requests during an incident
+	Seed              int64   `json:"seed"`
+}
+
+type sloState struct {
+	time      time.Time
+	requests  float64
+	errors    float64
+	burnRates *requestWindow
+	budget    *requestWindow
+}
+
+func (s *sloSim) GetState() simulationState {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return simulationState{
+		Key:    s.key,
+		Config: s.cfg,
+	}
+}
+
+func (s *sloSim) SetConfig(vals map[string]any) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return updateConfigObjectFromJSON(&s.cfg, vals)
+}
+
+func (s *sloSim) NewFrame(size int) *data.Frame {
+	frame := data.NewFrameOfFieldTypes("", size,
+		data.FieldTypeTime,    // time
+		data.FieldTypeFloat64, // requestRate
+		data.FieldTypeFloat64, // errorRatio
+		data.FieldTypeFloat64, // requestsTotal
+		data.FieldTypeFloat64, // errorsTotal
+		data.FieldTypeFloat64, // burnRate5m
+		data.FieldTypeFloat64, // burnRate30m
+		data.FieldTypeFloat64, // burnRate1h
+		data.FieldTypeFloat64, // burnRate6h
+		data.FieldTypeFloat64, // sli
+		data.FieldTypeFloat64, // objective
+		data.FieldTypeFloat64, // errorBudgetRemaining
+		data.FieldTypeBool,    // incident
+	)
+
+	frame.Fields[0].Name = "time"
+	frame.Fields[1].Name = "requestRate"
+	frame.Fields[1].Config = &data.FieldConfig{Unit: "reqps"}
+	frame.Fields[2].Name = "errorRatio"
+	frame.Fields[2].Config = &data.FieldConfig{Unit: "percentunit"}
+	frame.Fields[3].Name = "requestsTotal"
+	frame.Fields[4].Name = "errorsTotal"
+	frame.Fields[5].Name = "burnRate5m"
+	frame.Fields[6].Name = "burnRate30m"
+	frame.Fields[7].Name = "burnRate1h"
+	frame.Fields[8].Name = "burnRate6h"
+	frame.Fields[9].Name = "sli"
+	frame.Fields[9].Config = &data.FieldConfig{Unit: "percentunit"}
+	frame.Fields[10].Name = "objective"
+	frame.Fields[10].Config = &data.FieldConfig{Unit: "percentunit"}
+	frame.Fields[11].Name = "errorBudgetRemaining"
+	frame.Fields[11].Config = &data.FieldConfig{Unit: "percentunit"}
+	frame.Fields[12].Name = "incident"
+	return frame
+}
+
+func (s *sloSim) GetValues(t time.Time) map[string]any {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if t.Before(s.state.time) {
+		return nil // can not look backwards!
+	}
+	window := s.window()
+	if s.state.time.IsZero() {
+		s.state.time = t
+		s.state.burnRates = newRequestWindow(sloBurnRateBucket, sloBurnRateWindow)
+		s.state.budget = newRequestWindow(sloBudgetBucket, sloMaxWindow)
+	}
+	if gap := t.Sub(s.state.time); gap > window {
+		s.state.time = s.state.time.Add(gap - window)
+	}
+
+	var stepRequests, stepErrors float64
+	start := s.state.time
+	for s.state.time.Before(t) {
+		step := t.Sub(s.state.time)
+		if step > sloStep {
+			step = sloStep
+		}
+		s.state.time = s.state.time.Add(step)
+
+		requests := poisson(s.rand, math.Max(0, s.cfg.RequestRate)*step.Seconds())
+		errors := binomial(s.rand, requests, s.errorRate())
+		s.state.requests += requests
+		s.state.errors += errors
+		s.state.burnRates.add(s.state.time, requests, errors)
+		s.state.budget.add(s.state.time, requests, errors)
+		stepRequests += requests
+		stepErrors += errors
+	}
+
+	requestRate, errorRatio := 0.0, 0.0
+	if seconds := t.Sub(start).Seconds(); seconds > 0 {
+		requestRate = stepRequests / seconds
+	}
+	if stepRequests > 0 {
+		errorRatio = stepErrors / stepRequests
+	}
+
+	allowed := 1 - s.cfg.Objective
+	burnRate := func(d time.Duration) float64 {
+		requests, errors := s.state.burnRates.sum(t, d)
+		if requests == 0 || allowed <= 0 {
+			return 0
+		}
+		return errors / requests / allowed
+	}
+
+	sli, budgetRemaining := 1.0, 1.0
+	if requests, errors := s.state.budget.sum(t, window); requests > 0 {
+		sli = 1 - errors/requests
+		if allowed > 0 {
+			budgetRemaining = 1 - errors/(requests*allowed)
+		}
+	}
+
+	return map[string]any{
+		"time":                 t,
+		"requestRate":          requestRate,
+		"errorRatio":           errorRatio,
+		"requestsTotal":        s.state.requests,
+		"errorsTotal":          s.state.errors,
+		"burnRate5m":           burnRate(5 * time.Minute),
+		"burnRate30m":          burnRate(30 * time.Minute),
+		"burnRate1h":           burnRate(time.Hour),
+		"burnRate6h":           burnRate(6 * time.Hour),
+		"sli":                  sli,
+		"objective":            s.cfg.Objective,
+		"errorBudgetRemaining": budgetRemaining,
+		"incident":             s.cfg.Incident,
+	}
+}
+
+func (s *sloSim) window() time.Duration {
+	window := time.Duration(s.cfg.Window * float64(24*time.Hour))
+	if window <= 0 || window > sloMaxWindow {
+		return sloMaxWindow
+	}
+	return window
+}
+
+func (s *sloSim) errorRate() float64 {
+	rate := s.cfg.ErrorRate
+	if s.cfg.Incident {
+		rate = s.cfg.IncidentErrorRate
+	}
+	return math.Max(0, math.Min(1, rate))
+}
+
+func (s *sloSim) Close() error {
+	return nil
+}
+
+// requestWindow counts the requests and the errors in buckets of time, over a sliding window.
+type requestWindow struct {
+	size     time.Duration
+	requests []float64
+	errors   []float64
+	last     int64 // index of the bucket of the last added requests, from the epoch
+}
+
+func newRequestWindow(size time.Duration, window time.Duration) *requestWindow {
+	n := int(window / size)
+	return &requestWindow{
+		size:     size,
+		requests: make([]float64, n),
+		errors:   make([]float64, n),
+	}
+}
+
+func (w *requestWindow) add(t time.Time, requests float64, errors float64) {
+	idx := t.UnixNano() / int64(w.size)
+	n := int64(len(w.requests))
+	// clear the buckets between the last added requests and these
+	for i := w.last + 1; i <= idx && i <= w.last+n; i++ {
+		w.requests[i%n] = 0
+		w.errors[i%n] = 0
+	}
+	if idx > w.last {
+		w.last = idx
+	}
+	if idx <= w.last-n {
+		return // too old
+	}
+	w.requests[idx%n] += requests
+	w.errors[idx%n] += errors
+}
+
+// sum returns the requests and the errors of the buckets ending in the duration until the time.
+func (w *requestWindow) sum(t time.Time, d time.Duration) (float64, float64) {
+	end := t.UnixNano() / int64(w.size)
+	n := int64(len(w.requests))
+	buckets := int64(d / w.size)
+	if buckets > n {
+		buckets = n
+	}
+	var requests, errors float64
+	for i := end - buckets + 1; i <= end; i++ {
+		if i > w.last || i <= w.last-n || i < 0 {
+			continue
+		}
+		requests += w.requests[i%n]
+		errors += w.errors[i%n]
+	}
+	return requests, errors
+}
+
+// poisson returns a random number of events of a Poisson distribution with the mean, approximated by a normal
+// distribution for large means.
+func poisson(r *rand.Rand, mean float64) float64 {
+	if mean <= 0 {
+		return 0
+	}
+	if mean > 100 {
+		return math.Max(0, math.Round(mean+r.NormFloat64()*math.Sqrt(mean)))
+	}
+	// Knuth's algorithm
+	limit := math.Exp(-mean)
+	k, p := 0.0, r.Float64()
+	for p > limit {
+		k++
+		p *= r.Float64()
+	}
+	return k
+}
+
+// binomial returns a random number of successes of trials with the probability, approximated by a Poisson or a normal
+// distribution for large numbers of trials.
+func binomial(r *rand.Rand, trials float64, p float64) float64 {
+	if trials <= 0 || p <= 0 {
+		return 0
+	}
+	if p >= 1 {
+		return trials
+	}
+	if trials > 100 {
+		mean := trials * p
+		if mean < 20 {
+			return math.Min(trials, poisson(r, mean))
+		}
+		return math.Max(0, math.Min(trials, math.Round(mean+r.NormFloat64()*math.Sqrt(mean*(1-p)))))
+	}
+	k := 0.0
+	for i := 0.0; i < trials; i++ {
+		if r.Float64() < p {
+			k++
+		}
+	}
+	return k
+}
+
+func newSLOSimInfo() simulationInfo {
+	sc := sloConfig{
+		Objective:         0.999,
+		Window:            30,
+		RequestRate:       100,
+		ErrorRate:         0.0005,
+		Incident:          false,
+		IncidentErrorRate: 0.05,
+		Seed:              1,
+	}
+
+	df := data.NewFrame("")
+	df.Fields = append(df.Fields, data.NewField("objective", nil, []float64{sc.Objective}).SetConfig(&data.FieldConfig{
+		Unit: "percentunit",
+	}))
+	df.Fields = append(df.Fields, data.NewField("window", nil, []float64{sc.Window}).SetConfig(&data.FieldConfig{
+		Unit: "d",
+	}))
+	df.Fields = append(df.Fields, data.NewField("requestRate", nil, []float64{sc.RequestRate}).SetConfig(&data.FieldConfig{
+		Unit: "reqps",
+	}))
+	df.Fields = append(df.Fields, data.NewField("errorRate", nil, []float64{sc.ErrorRate}).SetConfig(&data.FieldConfig{
+		Unit: "percentunit",
+	}))
+	df.Fields = append(df.Fields, data.NewField("incident", nil, []bool{sc.Incident}))
+	df.Fields = append(df.Fields, data.NewField("incidentErrorRate", nil, []float64{sc.IncidentErrorRate}).SetConfig(&data.FieldConfig{
+		Unit: "percentunit",
+	}))
+	df.Fields = append(df.Fields, data.NewField("seed", nil, []float64{float64(sc.Seed)}))
+
+	return simulationInfo{
+		Type:         "slo",
+		Name:         "SLO",
+		Description:  "Error budget burn of an availability SLO",
+		ConfigFields: df,
+		OnlyForward:  true,
+		create: func(cfg simulationState) (Simulation, error) {
+			s := &sloSim{
+				key: cfg.Key,
+				cfg: sc,
+			}
+			err := updateConfigObjectFromJSON(&s.cfg, cfg.Config) // override any fields
+			s.rand = rand.New(rand.NewSource(s.cfg.Seed))
+			return s, err
+		},
+	}
+}
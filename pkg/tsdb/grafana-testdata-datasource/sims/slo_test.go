@@ -0,0 +1,60 @@
+package sims
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSLOSimulation(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("burns the error budget slowly without incident", func(t *testing.T) {
+		sim := newTestSimulation(t, "slo", map[string]any{"objective": 0.999, "requestRate": 100, "errorRate": 0.0005})
+		runSimulation(sim, start, time.Hour)
+
+		values := sim.GetValues(start.Add(time.Hour + time.Second))
+		require.InDelta(t, 0.5, values["burnRate1h"], 0.1)
+		require.InDelta(t, 0.5, values["errorBudgetRemaining"], 0.1)
+		require.InDelta(t, 0.9995, values["sli"], 0.0001)
+		require.InDelta(t, 100, values["requestRate"], 30)
+	})
+
+	t.Run("burns the error budget fast during an incident", func(t *testing.T) {
+		sim := newTestSimulation(t, "slo", map[string]any{"objective": 0.999, "requestRate": 100, "errorRate": 0.0005, "incidentErrorRate": 0.05})
+		runSimulation(sim, start, time.Hour)
+		require.NoError(t, sim.SetConfig(map[string]any{"incident": true}))
+		runSimulation(sim, start.Add(time.Hour+time.Second), 10*time.Minute)
+
+		values := sim.GetValues(start.Add(time.Hour + 10*time.Minute + 2*time.Second))
+		require.Equal(t, true, values["incident"])
+		require.InDelta(t, 50, values["burnRate5m"], 5)
+		require.Less(t, values["burnRate1h"], values["burnRate5m"])
+		require.Less(t, values["errorBudgetRemaining"], 0.0)
+	})
+}
+
+func TestRequestWindow(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	w := newRequestWindow(time.Minute, 10*time.Minute)
+
+	for i := 0; i < 20; i++ {
+		w.add(start.Add(time.Duration(i)*time.Minute), 10, float64(i))
+	}
+	end := start.Add(19 * time.Minute)
+
+	requests, errors := w.sum(end, 2*time.Minute)
+	require.Equal(t, 20.0, requests)
+	require.Equal(t, 37.0, errors)
+
+	// the window only holds the last buckets
+	requests, _ = w.sum(end, time.Hour)
+	require.Equal(t, 100.0, requests)
+
+	// the buckets after a gap are empty
+	w.add(end.Add(5*time.Minute), 10, 0)
+	requests, errors = w.sum(end.Add(5*time.Minute), 5*time.Minute)
+	require.Equal(t, 10.0, requests)
+	require.Equal(t, 0.0, errors)
+}
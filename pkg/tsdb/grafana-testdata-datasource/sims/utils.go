@@ -47,18 +47,40 @@ func asStringMap(input any) (map[string]any, error) {
 	return v, err
 }
 
+// valueKey returns the key of the values of a field. The simulations returning several fields with the same name
+// tell them apart by their labels.
+func valueKey(name string, labels data.Labels) string {
+	if len(labels) == 0 {
+		return name
+	}
+	return name + labels.String()
+}
+
 func setFrameRow(frame *data.Frame, idx int, values map[string]any) {
 	for _, field := range frame.Fields {
-		v, ok := values[field.Name]
+		v, ok := values[valueKey(field.Name, field.Labels)]
 		if ok {
 			field.Set(idx, v)
 		}
 	}
 }
 
+// sameFields tells if the frames have the same fields, by name, labels and type.
+func sameFields(a *data.Frame, b *data.Frame) bool {
+	if len(a.Fields) != len(b.Fields) {
+		return false
+	}
+	for i, f := range a.Fields {
+		if f.Name != b.Fields[i].Name || f.Type() != b.Fields[i].Type() || !f.Labels.Equals(b.Fields[i].Labels) {
+			return false
+		}
+	}
+	return true
+}
+
 func appendFrameRow(frame *data.Frame, values map[string]any) {
 	for _, field := range frame.Fields {
-		v, ok := values[field.Name]
+		v, ok := values[valueKey(field.Name, field.Labels)]
 		if ok {
 			field.Append(v)
 		} else {
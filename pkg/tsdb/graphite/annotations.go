@@ -0,0 +1,81 @@
+package graphite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana-plugin-sdk-go/experimental/errorsource"
+)
+
+// annotationQueryType is the query type of the queries returning the Graphite events as annotations.
+const annotationQueryType = "annotations"
+
+type annotationQueryModel struct {
+	// Tags filters the events which have all the tags. The events aren't filtered when it is empty.
+	Tags []string `json:"tags"`
+}
+
+func splitAnnotationQueries(queries []backend.DataQuery) (annotationQueries []backend.DataQuery, dataQueries []backend.DataQuery) {
+	for _, q := range queries {
+		if q.QueryType == annotationQueryType {
+			annotationQueries = append(annotationQueries, q)
+		} else {
+			dataQueries = append(dataQueries, q)
+		}
+	}
+	return annotationQueries, dataQueries
+}
+
+// queryAnnotations returns the events in the time range of the query as an annotations frame, with the
+// time, title, text and tags fields. The tags are separated by commas.
+func (s *Service) queryAnnotations(ctx context.Context, dsInfo *datasourceInfo, q backend.DataQuery) backend.DataResponse {
+	var model annotationQueryModel
+	if err := json.Unmarshal(q.JSON, &model); err != nil {
+		return errorsource.Response(errorsource.PluginError(fmt.Errorf("failed to parse annotation query: %w", err), false))
+	}
+
+	from, until := epochMStoGraphiteTime(q.TimeRange)
+	params := url.Values{
+		"from":  []string{from},
+		"until": []string{until},
+	}
+	tags := make([]string, 0, len(model.Tags))
+	for _, tag := range model.Tags {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	if len(tags) > 0 {
+		params.Set("tags", strings.Join(tags, " "))
+	}
+
+	events, err := s.getEvents(ctx, dsInfo, params)
+	if err != nil {
+		return errorsource.Response(errorsource.DownstreamError(err, false))
+	}
+
+	frame := data.NewFrame(q.RefID,
+		data.NewField("time", nil, []time.Time{}),
+		data.NewField("title", nil, []string{}),
+		data.NewField("text", nil, []string{}),
+		data.NewField("tags", nil, []string{}),
+	)
+	for _, e := range events {
+		sec, frac := math.Modf(e.When)
+		frame.AppendRow(time.Unix(int64(sec), int64(frac*1e9)).UTC(), e.What, e.Data, strings.Join(e.Tags, ","))
+	}
+	frame.Meta = &data.FrameMeta{
+		Custom: map[string]any{
+			"rowCount": len(events),
+		},
+	}
+
+	return backend.DataResponse{Frames: data.Frames{frame}}
+}
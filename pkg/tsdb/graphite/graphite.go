@@ -17,6 +17,7 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
@@ -32,8 +33,9 @@ import (
 var logger = log.New("tsdb.graphite")
 
 type Service struct {
-	im     instancemgmt.InstanceManager
-	tracer tracing.Tracer
+	im              instancemgmt.InstanceManager
+	tracer          tracing.Tracer
+	resourceHandler backend.CallResourceHandler
 }
 
 const (
@@ -42,10 +44,18 @@ const (
 )
 
 func ProvideService(httpClientProvider httpclient.Provider, tracer tracing.Tracer) *Service {
-	return &Service{
+	s := &Service{
 		im:     datasource.NewInstanceManager(newInstanceSettings(httpClientProvider)),
 		tracer: tracer,
 	}
+	s.resourceHandler = httpadapter.New(s.newResourceMux())
+	return s
+}
+
+// CallResource serves the /metrics/find, /tags/autoComplete/tags, /tags/autoComplete/values and /events/get_data
+// resources, which forward the requests to the endpoints of the Graphite API with the same names.
+func (s *Service) CallResource(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	return s.resourceHandler.CallResource(ctx, req, sender)
 }
 
 type datasourceInfo struct {
@@ -98,8 +108,18 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 		return nil, err
 	}
 
+	// the annotation queries are sent to the events endpoint rather than rendered
+	annotationQueries, queries := splitAnnotationQueries(req.Queries)
+	annotationResponses := make(backend.Responses, len(annotationQueries))
+	for _, q := range annotationQueries {
+		annotationResponses[q.RefID] = s.queryAnnotations(ctx, dsInfo, q)
+	}
+	if len(queries) == 0 {
+		return &backend.QueryDataResponse{Responses: annotationResponses}, nil
+	}
+
 	// take the first query in the request list, since all query should share the same timerange
-	q := req.Queries[0]
+	q := queries[0]
 
 	/*
 		graphite doc about from and until, with sdk we are getting absolute instead of relative time
@@ -115,7 +135,7 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 	}
 
 	// Convert datasource query to graphite target request
-	targetList, emptyQueries, origRefIds, err := s.processQueries(logger, req.Queries)
+	targetList, emptyQueries, origRefIds, err := s.processQueries(logger, queries)
 	if err != nil {
 		return nil, err
 	}
@@ -124,7 +144,7 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 	if len(emptyQueries) != 0 {
 		logger.Warn("Found query models without targets", "models without targets", strings.Join(emptyQueries, "\n"))
 		// If no queries had a valid target, return an error; otherwise, attempt with the targets we have
-		if len(emptyQueries) == len(req.Queries) {
+		if len(emptyQueries) == len(queries) {
 			return &result, errors.New("no query target found for the alert rule")
 		}
 	}
@@ -177,7 +197,7 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 	}
 
 	result = backend.QueryDataResponse{
-		Responses: make(backend.Responses),
+		Responses: annotationResponses,
 	}
 
 	for _, f := range frames {
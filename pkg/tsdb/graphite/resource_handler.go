@@ -0,0 +1,292 @@
+package graphite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// resourceParams are the parameters of each resource which are forwarded to the Graphite API.
+var resourceParams = map[string][]string{
+	"metrics/find":             {"query", "from", "until"},
+	"tags/autoComplete/tags":   {"expr", "tagPrefix", "limit", "from", "until"},
+	"tags/autoComplete/values": {"expr", "tag", "valuePrefix", "limit", "from", "until"},
+	"events/get_data":          {"from", "until", "tags"},
+}
+
+func (s *Service) newResourceMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics/find", s.handleMetricsFind)
+	mux.HandleFunc("/tags/autoComplete/tags", s.handleTagsAutoComplete("tags/autoComplete/tags", ""))
+	mux.HandleFunc("/tags/autoComplete/values", s.handleTagsAutoComplete("tags/autoComplete/values", "tag"))
+	mux.HandleFunc("/events/get_data", s.handleEvents)
+	return mux
+}
+
+// MetricFindValue is a node of the metrics tree returned by the /metrics/find resource.
+type MetricFindValue struct {
+	Text       string `json:"text"`
+	ID         string `json:"id"`
+	Expandable bool   `json:"expandable"`
+}
+
+// Event is a Graphite event returned by the /events/get_data resource. Its time is in seconds, as in Graphite.
+type Event struct {
+	When float64  `json:"when"`
+	What string   `json:"what"`
+	Data string   `json:"data"`
+	Tags []string `json:"tags"`
+}
+
+func (s *Service) handleMetricsFind(rw http.ResponseWriter, req *http.Request) {
+	params, ok := resourceRequestParams(rw, req, "metrics/find", "query")
+	if !ok {
+		return
+	}
+
+	dsInfo, err := s.getDSInfo(req.Context(), httpadapter.PluginConfigFromContext(req.Context()))
+	if err != nil {
+		writeResourceError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	// the query is sent in the body, as the frontend does, since the patterns can be long
+	body, err := s.doResourceRequest(req.Context(), dsInfo, http.MethodPost, "metrics/find", params)
+	if err != nil {
+		writeResourceError(rw, http.StatusBadGateway, err.Error())
+		return
+	}
+
+	var nodes []struct {
+		Text       string          `json:"text"`
+		ID         string          `json:"id"`
+		Expandable json.RawMessage `json:"expandable"`
+	}
+	if err := json.Unmarshal(body, &nodes); err != nil {
+		writeResourceError(rw, http.StatusBadGateway, fmt.Sprintf("failed to parse Graphite response: %s", err))
+		return
+	}
+
+	values := make([]MetricFindValue, 0, len(nodes))
+	for _, node := range nodes {
+		// graphite-web returns expandable as 0 or 1, other implementations as a boolean
+		expandable := string(node.Expandable)
+		values = append(values, MetricFindValue{
+			Text:       node.Text,
+			ID:         node.ID,
+			Expandable: expandable == "1" || expandable == "true",
+		})
+	}
+	writeResourceResponse(rw, values)
+}
+
+func (s *Service) handleTagsAutoComplete(endpoint string, requiredParam string) http.HandlerFunc {
+	return func(rw http.ResponseWriter, req *http.Request) {
+		params, ok := resourceRequestParams(rw, req, endpoint, requiredParam)
+		if !ok {
+			return
+		}
+
+		dsInfo, err := s.getDSInfo(req.Context(), httpadapter.PluginConfigFromContext(req.Context()))
+		if err != nil {
+			writeResourceError(rw, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		body, err := s.doResourceRequest(req.Context(), dsInfo, http.MethodGet, endpoint, params)
+		if err != nil {
+			writeResourceError(rw, http.StatusBadGateway, err.Error())
+			return
+		}
+
+		tags := make([]string, 0)
+		if err := json.Unmarshal(body, &tags); err != nil {
+			writeResourceError(rw, http.StatusBadGateway, fmt.Sprintf("failed to parse Graphite response: %s", err))
+			return
+		}
+		writeResourceResponse(rw, tags)
+	}
+}
+
+func (s *Service) handleEvents(rw http.ResponseWriter, req *http.Request) {
+	params, ok := resourceRequestParams(rw, req, "events/get_data", "")
+	if !ok {
+		return
+	}
+
+	dsInfo, err := s.getDSInfo(req.Context(), httpadapter.PluginConfigFromContext(req.Context()))
+	if err != nil {
+		writeResourceError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	events, err := s.getEvents(req.Context(), dsInfo, params)
+	if err != nil {
+		writeResourceError(rw, http.StatusBadGateway, err.Error())
+		return
+	}
+	writeResourceResponse(rw, events)
+}
+
+// getEvents returns the events of Graphite, with their tags as lists. Old Graphite versions return the
+// tags as a single string, separated by commas or spaces.
+func (s *Service) getEvents(ctx context.Context, dsInfo *datasourceInfo, params url.Values) ([]Event, error) {
+	body, err := s.doResourceRequest(ctx, dsInfo, http.MethodGet, "events/get_data", params)
+	if err != nil {
+		return nil, err
+	}
+
+	var rawEvents []struct {
+		When float64         `json:"when"`
+		What string          `json:"what"`
+		Data string          `json:"data"`
+		Tags json.RawMessage `json:"tags"`
+	}
+	if err := json.Unmarshal(body, &rawEvents); err != nil {
+		return nil, fmt.Errorf("failed to parse Graphite response: %w", err)
+	}
+
+	events := make([]Event, 0, len(rawEvents))
+	for _, e := range rawEvents {
+		event := Event{When: e.When, What: e.What, Data: e.Data, Tags: []string{}}
+		var tagString string
+		if err := json.Unmarshal(e.Tags, &tagString); err == nil {
+			event.Tags = parseEventTags(tagString)
+		} else if len(e.Tags) > 0 && string(e.Tags) != "null" {
+			if err := json.Unmarshal(e.Tags, &event.Tags); err != nil {
+				return nil, fmt.Errorf("failed to parse the tags of event %q: %w", e.What, err)
+			}
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+func parseEventTags(tags string) []string {
+	separator := ","
+	if !strings.Contains(tags, separator) {
+		separator = " "
+	}
+
+	result := make([]string, 0)
+	for _, tag := range strings.Split(tags, separator) {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			result = append(result, tag)
+		}
+	}
+	return result
+}
+
+// doResourceRequest sends a request to an endpoint of the Graphite API and returns the body of the response.
+// The parameters are sent in the body of POST requests and in the URL of GET requests.
+func (s *Service) doResourceRequest(ctx context.Context, dsInfo *datasourceInfo, method string, endpoint string, params url.Values) ([]byte, error) {
+	logger := logger.FromContext(ctx)
+
+	u, err := url.Parse(dsInfo.URL)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = path.Join(u.Path, endpoint)
+
+	var body io.Reader
+	if method == http.MethodPost {
+		body = strings.NewReader(params.Encode())
+	} else {
+		u.RawQuery = params.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if method == http.MethodPost {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+
+	ctx, span := s.tracer.Start(ctx, "graphite resource")
+	defer span.End()
+	span.SetAttributes(
+		attribute.String("endpoint", endpoint),
+		attribute.Int64("datasource_id", dsInfo.Id),
+	)
+	s.tracer.Inject(ctx, req.Header, span)
+
+	res, err := dsInfo.HTTPClient.Do(req)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			logger.Warn("Failed to close response body", "error", err)
+		}
+	}()
+	span.SetAttributes(attribute.Int("graphite.response.code", res.StatusCode))
+
+	resBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode/100 != 2 {
+		logger.Info("Request failed", "endpoint", endpoint, "status", res.Status, "body", string(resBody))
+		err := fmt.Errorf("request failed, status: %s", res.Status)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	return resBody, nil
+}
+
+// resourceRequestParams returns the parameters of the request which are forwarded to the endpoint,
+// from the URL or the form of the request. It writes an error when the required parameter is missing.
+func resourceRequestParams(rw http.ResponseWriter, req *http.Request, endpoint string, requiredParam string) (url.Values, bool) {
+	if req.Method != http.MethodGet && req.Method != http.MethodPost {
+		writeResourceError(rw, http.StatusMethodNotAllowed, "method not allowed")
+		return nil, false
+	}
+	if err := req.ParseForm(); err != nil {
+		writeResourceError(rw, http.StatusBadRequest, err.Error())
+		return nil, false
+	}
+
+	params := url.Values{}
+	for _, name := range resourceParams[endpoint] {
+		for _, value := range req.Form[name] {
+			params.Add(name, value)
+		}
+	}
+	if requiredParam != "" && params.Get(requiredParam) == "" {
+		writeResourceError(rw, http.StatusBadRequest, fmt.Sprintf("%s parameter is required", requiredParam))
+		return nil, false
+	}
+	return params, true
+}
+
+func writeResourceResponse(rw http.ResponseWriter, result any) {
+	body, err := json.Marshal(result)
+	if err != nil {
+		writeResourceError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	if _, err := rw.Write(body); err != nil {
+		logger.Error("Failed to write response", "error", err)
+	}
+}
+
+func writeResourceError(rw http.ResponseWriter, status int, message string) {
+	body, _ := json.Marshal(map[string]string{"message": message})
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(status)
+	_, _ = rw.Write(body)
+}
@@ -0,0 +1,188 @@
+package graphite
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+type graphiteRequest struct {
+	method string
+	path   string
+	params map[string][]string
+}
+
+func newResourceTestService(t *testing.T, responses map[string]string) (*Service, *[]graphiteRequest) {
+	t.Helper()
+
+	requests := []graphiteRequest{}
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		require.NoError(t, req.ParseForm())
+		requests = append(requests, graphiteRequest{method: req.Method, path: req.URL.Path, params: req.Form})
+		body, ok := responses[req.URL.Path]
+		if !ok {
+			rw.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = rw.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	s := &Service{
+		im:     resourceInstanceManager{info: datasourceInfo{HTTPClient: server.Client(), URL: server.URL + "/graphite"}},
+		tracer: tracing.InitializeTracerForTest(),
+	}
+	s.resourceHandler = httpadapter.New(s.newResourceMux())
+	return s, &requests
+}
+
+func callResource(t *testing.T, s *Service, method string, url string, body string) *backend.CallResourceResponse {
+	t.Helper()
+
+	var res *backend.CallResourceResponse
+	resourcePath, _, _ := strings.Cut(url, "?")
+	req := &backend.CallResourceRequest{Method: method, Path: resourcePath, URL: url, Body: []byte(body)}
+	if body != "" {
+		req.Headers = map[string][]string{"Content-Type": {"application/x-www-form-urlencoded"}}
+	}
+	err := s.CallResource(context.Background(), req, backend.CallResourceResponseSenderFunc(func(r *backend.CallResourceResponse) error {
+		res = r
+		return nil
+	}))
+	require.NoError(t, err)
+	require.NotNil(t, res)
+	return res
+}
+
+func TestResources(t *testing.T) {
+	t.Run("finds metrics", func(t *testing.T) {
+		s, requests := newResourceTestService(t, map[string]string{
+			"/graphite/metrics/find": `[{"text": "cpu", "id": "servers.cpu", "expandable": 1, "leaf": 0}, {"text": "load", "id": "servers.load", "expandable": false, "leaf": true}]`,
+		})
+
+		res := callResource(t, s, http.MethodPost, "/metrics/find?from=-1h&until=now", "query=servers.*&ignored=1")
+		require.Equal(t, http.StatusOK, res.Status)
+
+		var values []MetricFindValue
+		require.NoError(t, json.Unmarshal(res.Body, &values))
+		assert.Equal(t, []MetricFindValue{
+			{Text: "cpu", ID: "servers.cpu", Expandable: true},
+			{Text: "load", ID: "servers.load", Expandable: false},
+		}, values)
+
+		require.Len(t, *requests, 1)
+		assert.Equal(t, http.MethodPost, (*requests)[0].method)
+		assert.Equal(t, map[string][]string{"query": {"servers.*"}, "from": {"-1h"}, "until": {"now"}}, (*requests)[0].params)
+	})
+
+	t.Run("requires the query of the metrics", func(t *testing.T) {
+		s, requests := newResourceTestService(t, nil)
+
+		res := callResource(t, s, http.MethodGet, "/metrics/find", "")
+		assert.Equal(t, http.StatusBadRequest, res.Status)
+		assert.Empty(t, *requests)
+	})
+
+	t.Run("autocompletes tags and tag values", func(t *testing.T) {
+		s, requests := newResourceTestService(t, map[string]string{
+			"/graphite/tags/autoComplete/tags":   `["dc", "host"]`,
+			"/graphite/tags/autoComplete/values": `["server-1", "server-2"]`,
+		})
+
+		res := callResource(t, s, http.MethodGet, "/tags/autoComplete/tags?expr=name=cpu&expr=dc=eu&tagPrefix=h&limit=10", "")
+		require.Equal(t, http.StatusOK, res.Status)
+		assert.JSONEq(t, `["dc", "host"]`, string(res.Body))
+		assert.Equal(t, map[string][]string{"expr": {"name=cpu", "dc=eu"}, "tagPrefix": {"h"}, "limit": {"10"}}, (*requests)[0].params)
+
+		res = callResource(t, s, http.MethodGet, "/tags/autoComplete/values?tag=host&valuePrefix=server", "")
+		require.Equal(t, http.StatusOK, res.Status)
+		assert.JSONEq(t, `["server-1", "server-2"]`, string(res.Body))
+		assert.Equal(t, http.MethodGet, (*requests)[1].method)
+
+		res = callResource(t, s, http.MethodGet, "/tags/autoComplete/values", "")
+		assert.Equal(t, http.StatusBadRequest, res.Status)
+	})
+
+	t.Run("returns the events with their tags as lists", func(t *testing.T) {
+		s, _ := newResourceTestService(t, map[string]string{
+			"/graphite/events/get_data": `[
+				{"when": 1700000000, "what": "deploy", "data": "v1", "tags": ["deploy", "prod"]},
+				{"when": 1700000060, "what": "restart", "data": "", "tags": "ops,prod"},
+				{"when": 1700000120, "what": "alert", "data": "", "tags": "ops prod"},
+				{"when": 1700000180, "what": "untagged", "data": "", "tags": null}
+			]`,
+		})
+
+		res := callResource(t, s, http.MethodGet, "/events/get_data?from=-1h&until=now&tags=prod", "")
+		require.Equal(t, http.StatusOK, res.Status)
+
+		var events []Event
+		require.NoError(t, json.Unmarshal(res.Body, &events))
+		require.Len(t, events, 4)
+		assert.Equal(t, []string{"deploy", "prod"}, events[0].Tags)
+		assert.Equal(t, []string{"ops", "prod"}, events[1].Tags)
+		assert.Equal(t, []string{"ops", "prod"}, events[2].Tags)
+		assert.Equal(t, []string{}, events[3].Tags)
+	})
+
+	t.Run("returns the errors of Graphite", func(t *testing.T) {
+		s, _ := newResourceTestService(t, nil)
+
+		res := callResource(t, s, http.MethodGet, "/events/get_data", "")
+		assert.Equal(t, http.StatusBadGateway, res.Status)
+	})
+}
+
+func TestAnnotationQueries(t *testing.T) {
+	s, requests := newResourceTestService(t, map[string]string{
+		"/graphite/events/get_data": `[{"when": 1700000000.5, "what": "deploy", "data": "v1", "tags": ["deploy", "prod"]}]`,
+	})
+
+	from := time.Unix(1699999000, 0)
+	res, err := s.QueryData(context.Background(), &backend.QueryDataRequest{
+		Queries: []backend.DataQuery{{
+			RefID:     "Anno",
+			QueryType: annotationQueryType,
+			TimeRange: backend.TimeRange{From: from, To: from.Add(time.Hour)},
+			JSON:      []byte(`{"tags": ["deploy", " ", "prod"]}`),
+		}},
+	})
+	require.NoError(t, err)
+
+	require.Len(t, *requests, 1)
+	assert.Equal(t, map[string][]string{"from": {"1699999000"}, "until": {"1700002600"}, "tags": {"deploy prod"}}, (*requests)[0].params)
+
+	resp := res.Responses["Anno"]
+	require.NoError(t, resp.Error)
+	require.Len(t, resp.Frames, 1)
+	frame := resp.Frames[0]
+	require.Equal(t, 1, frame.Rows())
+	assert.Equal(t, time.Unix(1700000000, 500000000).UTC(), frame.Fields[0].At(0))
+	assert.Equal(t, "deploy", frame.Fields[1].At(0))
+	assert.Equal(t, "v1", frame.Fields[2].At(0))
+	assert.Equal(t, "deploy,prod", frame.Fields[3].At(0))
+}
+
+type resourceInstanceManager struct {
+	info datasourceInfo
+}
+
+func (f resourceInstanceManager) Get(_ context.Context, _ backend.PluginContext) (instancemgmt.Instance, error) {
+	return f.info, nil
+}
+
+func (f resourceInstanceManager) Do(_ context.Context, _ backend.PluginContext, _ instancemgmt.InstanceCallbackFunc) error {
+	return nil
+}
@@ -0,0 +1,80 @@
+package flux
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/tsdb/influxdb/models"
+)
+
+// Measurements returns the measurements of the default bucket.
+func Measurements(ctx context.Context, dsInfo *models.DatasourceInfo) ([]string, error) {
+	return schemaValues(ctx, dsInfo, `schema.measurements(bucket: %s)`)
+}
+
+// TagKeys returns the tag keys of the measurement, or of the default bucket when the measurement is empty.
+func TagKeys(ctx context.Context, dsInfo *models.DatasourceInfo, measurement string) ([]string, error) {
+	if measurement == "" {
+		return schemaValues(ctx, dsInfo, `schema.tagKeys(bucket: %s)`)
+	}
+	return schemaValues(ctx, dsInfo, `schema.measurementTagKeys(bucket: %s, measurement: `+quoteString(measurement)+`)`)
+}
+
+// FieldKeys returns the field keys of the measurement, or of the default bucket when the measurement is empty.
+func FieldKeys(ctx context.Context, dsInfo *models.DatasourceInfo, measurement string) ([]string, error) {
+	if measurement == "" {
+		return schemaValues(ctx, dsInfo, `schema.fieldKeys(bucket: %s)`)
+	}
+	return schemaValues(ctx, dsInfo, `schema.measurementFieldKeys(bucket: %s, measurement: `+quoteString(measurement)+`)`)
+}
+
+// RetentionPolicies returns the buckets of the organization, which replace the databases and their retention
+// policies in InfluxDB 2.
+func RetentionPolicies(ctx context.Context, dsInfo *models.DatasourceInfo) ([]string, error) {
+	return runValues(ctx, dsInfo, `buckets() |> keep(columns: ["name"]) |> rename(columns: {name: "_value"})`)
+}
+
+// schemaValues runs a function of the schema package, whose %s is replaced by the default bucket.
+func schemaValues(ctx context.Context, dsInfo *models.DatasourceInfo, function string) ([]string, error) {
+	if dsInfo.DefaultBucket == "" {
+		return nil, fmt.Errorf("missing default bucket in datasource configuration")
+	}
+	return runValues(ctx, dsInfo, "import \"influxdata/influxdb/schema\"\n"+fmt.Sprintf(function, quoteString(dsInfo.DefaultBucket)))
+}
+
+// runValues runs a Flux query and returns the sorted distinct string values of its _value column.
+func runValues(ctx context.Context, dsInfo *models.DatasourceInfo, query string) ([]string, error) {
+	r, err := runnerFromDataSource(dsInfo)
+	if err != nil {
+		return nil, err
+	}
+	defer r.client.Close()
+
+	result, err := r.runQuery(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = result.Close()
+	}()
+
+	seen := map[string]bool{}
+	values := []string{}
+	for result.Next() {
+		if value, ok := result.Record().Value().(string); ok && !seen[value] {
+			seen[value] = true
+			values = append(values, value)
+		}
+	}
+	if err := result.Err(); err != nil {
+		return nil, err
+	}
+	sort.Strings(values)
+	return values, nil
+}
+
+func quoteString(value string) string {
+	return `"` + strings.ReplaceAll(strings.ReplaceAll(value, `\`, `\\`), `"`, `\"`) + `"`
+}
@@ -59,12 +59,23 @@ func newQueryDataResponse(reader recordReader, query sqlutil.Query, headers meta
 
 		if frame.TimeSeriesSchema().Type == data.TimeSeriesTypeLong {
 			var err error
-			frame, err = data.LongToWide(frame, nil)
+			frame, err = data.LongToWide(frame, query.FillMissing)
 			if err != nil {
 				resp.Error = err
 				return resp
 			}
 		}
+
+		if query.FillMissing != nil && query.Interval > 0 {
+			// the intervals start at multiples of the interval, as the ones of date_bin()
+			from := time.Unix(0, query.TimeRange.From.UnixNano()/int64(query.Interval)*int64(query.Interval)).UTC()
+			resampled, err := sqlutil.ResampleWideFrame(frame, query.FillMissing, backend.TimeRange{From: from, To: query.TimeRange.To}, query.Interval)
+			if err != nil {
+				frame.AppendNotices(data.Notice{Text: "Failed to fill the missing intervals", Severity: data.NoticeSeverityWarning})
+			} else {
+				frame = resampled
+			}
+		}
 	case sqlutil.FormatOptionTable:
 		// No changes to the output. Send it as is.
 	case sqlutil.FormatOptionLogs:
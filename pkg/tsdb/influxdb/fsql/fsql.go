@@ -42,9 +42,9 @@ func Query(ctx context.Context, dsInfo *models.DatasourceInfo, req backend.Query
 	}
 
 	for _, q := range req.Queries {
-		qm, err := getQueryModel(q)
+		qm, err := getQueryModel(q, dsInfo.InfluxQLToSQL)
 		if err != nil {
-			tRes.Responses[q.RefID] = backend.ErrDataResponse(backend.StatusInternal, "bad request")
+			tRes.Responses[q.RefID] = backend.ErrDataResponse(backend.StatusBadRequest, fmt.Sprintf("bad request: %s", err))
 			continue
 		}
 
@@ -0,0 +1,362 @@
+package fsql
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/tsdb/influxdb/models"
+)
+
+// influxQLAggregations are the InfluxQL aggregations which have the same SQL function with another name.
+var influxQLAggregations = map[string]string{
+	"count":  "count",
+	"max":    "max",
+	"mean":   "avg",
+	"median": "median",
+	"min":    "min",
+	"stddev": "stddev",
+	"sum":    "sum",
+}
+
+var influxQLNumberPattern = regexp.MustCompile(`^-?[0-9.]+$`)
+
+// influxQLTranslation is the SQL query translated from an InfluxQL query.
+type influxQLTranslation struct {
+	SQL string
+	// Interval is the interval of the GROUP BY time(), if any.
+	Interval time.Duration
+	// FillMissing is how the empty intervals are filled, when the query has a fill() other than none.
+	FillMissing *data.FillMissing
+}
+
+// translateInfluxQL translates an InfluxQL query built with the query builder into SQL for InfluxDB 3.
+// The raw InfluxQL queries, and the query parts without an SQL equivalent, return an error instead.
+// The SQL uses the $__timeFrom and $__timeTo macros for the time range of the query.
+func translateInfluxQL(query *models.Query) (*influxQLTranslation, error) {
+	if query.UseRawQuery {
+		return nil, fmt.Errorf("raw InfluxQL queries can't be translated to SQL, rewrite the query in SQL")
+	}
+	if query.Measurement == "" {
+		return nil, fmt.Errorf("the InfluxQL query has no measurement")
+	}
+	if strings.HasPrefix(query.Measurement, "/") {
+		return nil, fmt.Errorf("the measurement regular expression %s can't be translated to SQL", query.Measurement)
+	}
+	switch query.Policy {
+	case "", "default", "autogen":
+	default:
+		return nil, fmt.Errorf("the retention policy %q can't be translated to SQL, use a data source for its database", query.Policy)
+	}
+	if query.Slimit != "" {
+		return nil, fmt.Errorf("SLIMIT can't be translated to SQL")
+	}
+	if query.Tz != "" {
+		return nil, fmt.Errorf("tz() can't be translated to SQL")
+	}
+
+	res := &influxQLTranslation{}
+	var timeBin string
+	var tags []string
+	for _, part := range query.GroupBy {
+		switch part.Type {
+		case "time":
+			bin, interval, err := translateGroupByTime(part, query.Interval)
+			if err != nil {
+				return nil, err
+			}
+			timeBin, res.Interval = bin, interval
+		case "tag":
+			if len(part.Params) == 0 || part.Params[0] == "*" {
+				return nil, fmt.Errorf("GROUP BY * can't be translated to SQL, group by the tags")
+			}
+			tags = append(tags, quoteSQLIdentifier(trimTypeSuffix(part.Params[0])))
+		case "fill":
+			fill, err := translateFill(part)
+			if err != nil {
+				return nil, err
+			}
+			res.FillMissing = fill
+		default:
+			return nil, fmt.Errorf("GROUP BY %s() can't be translated to SQL", part.Type)
+		}
+	}
+	if timeBin == "" {
+		res.FillMissing = nil
+	}
+
+	selects := make([]string, 0, len(query.Selects))
+	aggregated := false
+	for _, sel := range query.Selects {
+		expr, isAggregation, err := translateSelect(*sel)
+		if err != nil {
+			return nil, err
+		}
+		aggregated = aggregated || isAggregation
+		selects = append(selects, expr)
+	}
+	if len(selects) == 0 {
+		return nil, fmt.Errorf("the InfluxQL query selects no field")
+	}
+	if timeBin != "" && !aggregated {
+		return nil, fmt.Errorf("GROUP BY time() requires an aggregation of the fields")
+	}
+
+	var sql strings.Builder
+	sql.WriteString("SELECT ")
+	switch {
+	case timeBin != "":
+		sql.WriteString(timeBin + " AS time")
+	case aggregated:
+		sql.WriteString("$__timeFrom AS time")
+	default:
+		sql.WriteString("time")
+	}
+	for _, tag := range tags {
+		sql.WriteString(", " + tag)
+	}
+	sql.WriteString(", " + strings.Join(selects, ", "))
+	sql.WriteString(" FROM " + quoteSQLIdentifier(query.Measurement))
+
+	conditions, err := translateTags(query.Tags)
+	if err != nil {
+		return nil, err
+	}
+	sql.WriteString(" WHERE ")
+	if conditions != "" {
+		sql.WriteString("(" + conditions + ") AND ")
+	}
+	sql.WriteString("time >= $__timeFrom AND time <= $__timeTo")
+
+	if aggregated {
+		groupBy := tags
+		if timeBin != "" {
+			groupBy = append([]string{timeBin}, tags...)
+		}
+		if len(groupBy) > 0 {
+			sql.WriteString(" GROUP BY " + strings.Join(groupBy, ", "))
+		}
+	}
+
+	sql.WriteString(" ORDER BY time")
+	if strings.EqualFold(query.OrderByTime, "DESC") {
+		sql.WriteString(" DESC")
+	}
+	if query.Limit != "" {
+		limit, err := strconv.Atoi(query.Limit)
+		if err != nil || limit < 0 {
+			return nil, fmt.Errorf("invalid limit %q", query.Limit)
+		}
+		sql.WriteString(" LIMIT " + strconv.Itoa(limit))
+	}
+
+	res.SQL = sql.String()
+	return res, nil
+}
+
+// translateSelect translates the field, its aggregation and its math of a select, and tells if it's aggregated.
+func translateSelect(parts models.Select) (string, bool, error) {
+	var expr, name, alias string
+	aggregated := false
+	for _, part := range parts {
+		switch part.Type {
+		case "field", "tag":
+			if len(part.Params) == 0 {
+				return "", false, fmt.Errorf("%s() requires a parameter", part.Type)
+			}
+			if part.Params[0] == "*" {
+				return "", false, fmt.Errorf("SELECT * can't be translated to SQL, select the fields")
+			}
+			name = trimTypeSuffix(part.Params[0])
+			expr = quoteSQLIdentifier(name)
+		case "first", "last":
+			expr = fmt.Sprintf("selector_%s(%s, time)['value']", part.Type, expr)
+			name, aggregated = part.Type, true
+		case "spread":
+			expr = fmt.Sprintf("max(%s) - min(%s)", expr, expr)
+			name, aggregated = part.Type, true
+		case "percentile":
+			if len(part.Params) == 0 {
+				return "", false, fmt.Errorf("percentile() requires a parameter")
+			}
+			nth, err := strconv.ParseFloat(part.Params[0], 64)
+			if err != nil || nth < 0 || nth > 100 {
+				return "", false, fmt.Errorf("invalid percentile %q", part.Params[0])
+			}
+			expr = fmt.Sprintf("approx_percentile_cont(%s, %s)", expr, strconv.FormatFloat(nth/100, 'f', -1, 64))
+			name, aggregated = part.Type, true
+		case "math":
+			if len(part.Params) == 0 {
+				return "", false, fmt.Errorf("math() requires a parameter")
+			}
+			expr = fmt.Sprintf("%s %s", expr, part.Params[0])
+		case "alias":
+			if len(part.Params) == 0 {
+				return "", false, fmt.Errorf("alias() requires a parameter")
+			}
+			alias = part.Params[0]
+		default:
+			function, ok := influxQLAggregations[part.Type]
+			if !ok {
+				return "", false, fmt.Errorf("%s() can't be translated to SQL", part.Type)
+			}
+			expr = fmt.Sprintf("%s(%s)", function, expr)
+			name, aggregated = part.Type, true
+		}
+	}
+	if expr == "" {
+		return "", false, fmt.Errorf("the select has no field")
+	}
+
+	// the columns are named as in InfluxQL, so that the series keep their names
+	if alias == "" {
+		alias = name
+	}
+	return fmt.Sprintf("%s AS %s", expr, quoteSQLIdentifier(alias)), aggregated, nil
+}
+
+// translateGroupByTime returns the date_bin() expression of a GROUP BY time(), and its interval.
+func translateGroupByTime(part *models.QueryPart, queryInterval time.Duration) (string, time.Duration, error) {
+	interval := queryInterval
+	if len(part.Params) > 0 {
+		switch param := part.Params[0]; param {
+		case "", "auto", "$__interval", "$interval":
+		default:
+			var err error
+			if interval, err = gtime.ParseDuration(param); err != nil {
+				return "", 0, fmt.Errorf("invalid GROUP BY time() interval %q: %w", param, err)
+			}
+		}
+	}
+	if interval <= 0 {
+		return "", 0, fmt.Errorf("invalid GROUP BY time() interval %s", interval)
+	}
+
+	origin := time.Unix(0, 0).UTC()
+	if len(part.Params) > 1 && part.Params[1] != "" && part.Params[1] != "0" {
+		offset, err := gtime.ParseDuration(part.Params[1])
+		if err != nil {
+			return "", 0, fmt.Errorf("invalid GROUP BY time() offset %q: %w", part.Params[1], err)
+		}
+		origin = origin.Add(offset)
+	}
+
+	var sqlInterval string
+	if interval%time.Second == 0 {
+		sqlInterval = fmt.Sprintf("%d second", int64(interval/time.Second))
+	} else {
+		sqlInterval = fmt.Sprintf("%d millisecond", interval.Milliseconds())
+	}
+	return fmt.Sprintf("date_bin(interval '%s', time, timestamp '%s')", sqlInterval, origin.Format(time.RFC3339Nano)), interval, nil
+}
+
+func translateFill(part *models.QueryPart) (*data.FillMissing, error) {
+	if len(part.Params) == 0 {
+		return nil, fmt.Errorf("fill() requires a parameter")
+	}
+	switch param := part.Params[0]; param {
+	case "none":
+		return nil, nil
+	case "null":
+		return &data.FillMissing{Mode: data.FillModeNull}, nil
+	case "previous":
+		return &data.FillMissing{Mode: data.FillModePrevious}, nil
+	default:
+		value, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return nil, fmt.Errorf("fill(%s) can't be translated to SQL", param)
+		}
+		return &data.FillMissing{Mode: data.FillModeValue, Value: value}, nil
+	}
+}
+
+// translateTags translates the conditions of the WHERE clause.
+func translateTags(tags []*models.Tag) (string, error) {
+	conditions := make([]string, 0, len(tags))
+	for i, tag := range tags {
+		condition := ""
+		if i > 0 {
+			switch strings.ToUpper(tag.Condition) {
+			case "", "AND":
+				condition = "AND "
+			case "OR":
+				condition = "OR "
+			default:
+				return "", fmt.Errorf("invalid condition %q", tag.Condition)
+			}
+		}
+
+		operator := tag.Operator
+		if operator == "" {
+			if strings.HasPrefix(tag.Value, "/") && strings.HasSuffix(tag.Value, "/") {
+				operator = "=~"
+			} else {
+				operator = "="
+			}
+		}
+
+		var value string
+		switch operator {
+		case "=~", "!~":
+			pattern := tag.Value
+			if len(pattern) >= 2 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+				pattern = pattern[1 : len(pattern)-1]
+			}
+			value = quoteSQLString(pattern)
+			operator = strings.TrimPrefix(operator, "=")
+		case "<", ">", "<=", ">=":
+			value = sqlValue(removeRegexWrappers(tag.Value))
+		case "Is", "Is Not":
+			if strings.HasSuffix(tag.Key, "::tag") {
+				value = quoteSQLString(tag.Value)
+			} else {
+				value = sqlValue(tag.Value)
+			}
+			operator = map[string]string{"Is": "=", "Is Not": "!="}[operator]
+		case "=", "!=", "<>":
+			value = quoteSQLString(removeRegexWrappers(tag.Value))
+		default:
+			return "", fmt.Errorf("the operator %q can't be translated to SQL", operator)
+		}
+
+		conditions = append(conditions, fmt.Sprintf("%s%s %s %s", condition, quoteSQLIdentifier(trimTypeSuffix(tag.Key)), operator, value))
+	}
+	return strings.Join(conditions, " "), nil
+}
+
+// sqlValue returns the booleans and the numbers as they are, and the other values as strings.
+func sqlValue(value string) string {
+	lower := strings.ToLower(value)
+	if lower == "true" || lower == "false" {
+		return lower
+	}
+	if influxQLNumberPattern.MatchString(value) {
+		return value
+	}
+	return quoteSQLString(value)
+}
+
+// removeRegexWrappers returns the value of a /^value$/ regular expression used as a value, as in InfluxQL.
+func removeRegexWrappers(value string) string {
+	if strings.HasPrefix(value, "/^") && strings.HasSuffix(value, "$/") && len(value) >= 4 {
+		return value[2 : len(value)-2]
+	}
+	return value
+}
+
+func trimTypeSuffix(key string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(key, "::tag"), "::field")
+}
+
+func quoteSQLIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func quoteSQLString(value string) string {
+	return `'` + strings.ReplaceAll(value, `'`, `''`) + `'`
+}
@@ -0,0 +1,117 @@
+package fsql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/tsdb/influxdb/models"
+)
+
+func TestTranslateInfluxQL(t *testing.T) {
+	parse := func(t *testing.T, model string) *models.Query {
+		t.Helper()
+		query, err := models.QueryParse(backend.DataQuery{JSON: []byte(model), Interval: 10 * time.Second})
+		require.NoError(t, err)
+		return query
+	}
+
+	t.Run("translates an aggregation grouped by time and tags", func(t *testing.T) {
+		query := parse(t, `{
+			"measurement": "cpu",
+			"policy": "default",
+			"select": [[{"type": "field", "params": ["usage_idle"]}, {"type": "mean", "params": []}, {"type": "math", "params": ["* 100"]}]],
+			"tags": [{"key": "host::tag", "operator": "=", "value": "a'b"}, {"condition": "OR", "key": "region", "operator": "=~", "value": "/^eu-.*$/"}],
+			"groupBy": [{"type": "time", "params": ["$__interval"]}, {"type": "tag", "params": ["host"]}, {"type": "fill", "params": ["0"]}]
+		}`)
+
+		res, err := translateInfluxQL(query)
+		require.NoError(t, err)
+		bin := `date_bin(interval '10 second', time, timestamp '1970-01-01T00:00:00Z')`
+		assert.Equal(t, `SELECT `+bin+` AS time, "host", avg("usage_idle") * 100 AS "mean" FROM "cpu"`+
+			` WHERE ("host" = 'a''b' OR "region" ~ '^eu-.*$') AND time >= $__timeFrom AND time <= $__timeTo`+
+			` GROUP BY `+bin+`, "host" ORDER BY time`, res.SQL)
+		assert.Equal(t, 10*time.Second, res.Interval)
+		assert.Equal(t, &data.FillMissing{Mode: data.FillModeValue, Value: 0}, res.FillMissing)
+	})
+
+	t.Run("translates raw fields with an alias and a limit", func(t *testing.T) {
+		query := parse(t, `{
+			"measurement": "mem",
+			"select": [[{"type": "field", "params": ["used"]}, {"type": "alias", "params": ["Used memory"]}]],
+			"tags": [{"key": "used", "operator": ">", "value": "100"}],
+			"orderByTime": "DESC",
+			"limit": "10"
+		}`)
+
+		res, err := translateInfluxQL(query)
+		require.NoError(t, err)
+		assert.Equal(t, `SELECT time, "used" AS "Used memory" FROM "mem" WHERE ("used" > 100) AND time >= $__timeFrom AND time <= $__timeTo ORDER BY time DESC LIMIT 10`, res.SQL)
+		assert.Nil(t, res.FillMissing)
+	})
+
+	t.Run("translates selectors and percentiles with a fixed interval", func(t *testing.T) {
+		query := parse(t, `{
+			"measurement": "http",
+			"select": [
+				[{"type": "field", "params": ["latency"]}, {"type": "percentile", "params": [95]}],
+				[{"type": "field", "params": ["status"]}, {"type": "last", "params": []}]
+			],
+			"groupBy": [{"type": "time", "params": ["1m", "30s"]}, {"type": "fill", "params": ["null"]}]
+		}`)
+
+		res, err := translateInfluxQL(query)
+		require.NoError(t, err)
+		bin := `date_bin(interval '60 second', time, timestamp '1970-01-01T00:00:30Z')`
+		assert.Equal(t, `SELECT `+bin+` AS time, approx_percentile_cont("latency", 0.95) AS "percentile", selector_last("status", time)['value'] AS "last"`+
+			` FROM "http" WHERE time >= $__timeFrom AND time <= $__timeTo GROUP BY `+bin+` ORDER BY time`, res.SQL)
+		assert.Equal(t, time.Minute, res.Interval)
+		assert.Equal(t, &data.FillMissing{Mode: data.FillModeNull}, res.FillMissing)
+	})
+
+	t.Run("rejects the queries without an SQL equivalent", func(t *testing.T) {
+		for name, model := range map[string]string{
+			"raw query":   `{"rawQuery": true, "query": "SELECT * FROM cpu"}`,
+			"derivative":  `{"measurement": "cpu", "select": [[{"type": "field", "params": ["value"]}, {"type": "mean", "params": []}, {"type": "derivative", "params": ["1s"]}]]}`,
+			"regex":       `{"measurement": "/cpu.*/", "select": [[{"type": "field", "params": ["value"]}]]}`,
+			"policy":      `{"measurement": "cpu", "policy": "one_week", "select": [[{"type": "field", "params": ["value"]}]]}`,
+			"group by *":  `{"measurement": "cpu", "select": [[{"type": "field", "params": ["value"]}, {"type": "mean", "params": []}]], "groupBy": [{"type": "tag", "params": ["*"]}]}`,
+			"no function": `{"measurement": "cpu", "select": [[{"type": "field", "params": ["value"]}]], "groupBy": [{"type": "time", "params": ["1m"]}]}`,
+		} {
+			t.Run(name, func(t *testing.T) {
+				_, err := translateInfluxQL(parse(t, model))
+				require.Error(t, err)
+			})
+		}
+	})
+}
+
+func TestGetQueryModelTranslatesInfluxQL(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	query := backend.DataQuery{
+		RefID:     "A",
+		Interval:  time.Minute,
+		TimeRange: backend.TimeRange{From: from, To: from.Add(time.Hour)},
+		JSON: []byte(`{
+			"refId": "A",
+			"measurement": "cpu",
+			"select": [[{"type": "field", "params": ["value"]}, {"type": "max", "params": []}]],
+			"groupBy": [{"type": "time", "params": ["5m"]}, {"type": "fill", "params": ["previous"]}]
+		}`),
+	}
+
+	qm, err := getQueryModel(query, true)
+	require.NoError(t, err)
+	assert.Contains(t, qm.RawSQL, `max("value") AS "max"`)
+	assert.Contains(t, qm.RawSQL, `time >= cast('2024-01-01T00:00:00Z' as timestamp) AND time <= cast('2024-01-01T01:00:00Z' as timestamp)`)
+	assert.Equal(t, 5*time.Minute, qm.Interval)
+	assert.Equal(t, &data.FillMissing{Mode: data.FillModePrevious}, qm.FillMissing)
+
+	qm, err = getQueryModel(query, false)
+	require.NoError(t, err)
+	assert.Empty(t, qm.RawSQL)
+}
@@ -7,6 +7,8 @@ import (
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/data/sqlutil"
+
+	"github.com/grafana/grafana/pkg/tsdb/influxdb/models"
 )
 
 type queryModel struct {
@@ -23,12 +25,30 @@ type queryRequest struct {
 	Format               string `json:"format"`
 }
 
-func getQueryModel(dataQuery backend.DataQuery) (*queryModel, error) {
+// getQueryModel returns the model of a query. The InfluxQL queries are translated to SQL when translateInfluxQL is set.
+func getQueryModel(dataQuery backend.DataQuery, translateInfluxQL bool) (*queryModel, error) {
 	var q queryRequest
 	if err := json.Unmarshal(dataQuery.JSON, &q); err != nil {
 		return nil, fmt.Errorf("unmarshal json: %w", err)
 	}
 
+	var translation *influxQLTranslation
+	if translateInfluxQL && q.RawQuery == "" {
+		influxQLQuery, err := models.QueryParse(dataQuery)
+		if err != nil {
+			return nil, fmt.Errorf("parse InfluxQL query: %w", err)
+		}
+		if influxQLQuery.Measurement != "" || influxQLQuery.UseRawQuery {
+			if translation, err = translateInfluxQL(influxQLQuery); err != nil {
+				return nil, err
+			}
+			q.RawQuery = translation.SQL
+			if q.Format == "" {
+				q.Format = influxQLQuery.ResultFormat
+			}
+		}
+	}
+
 	var format sqlutil.FormatQueryOption
 	switch q.Format {
 	case "time_series":
@@ -48,6 +68,11 @@ func getQueryModel(dataQuery backend.DataQuery) (*queryModel, error) {
 		Format:        format,
 	}
 
+	if translation != nil && translation.Interval > 0 {
+		query.Interval = translation.Interval
+		query.FillMissing = translation.FillMissing
+	}
+
 	// Process macros and execute the query.
 	sql, err := sqlutil.Interpolate(query, macros)
 	if err != nil {
@@ -0,0 +1,106 @@
+package fsql
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"google.golang.org/grpc/metadata"
+
+	"github.com/grafana/grafana/pkg/tsdb/influxdb/models"
+)
+
+// tagDataType is the data type of the tag columns, which InfluxDB 3 encodes as dictionaries.
+const tagDataType = "Dictionary(Int32, Utf8)"
+
+// Measurements returns the tables of the database.
+func Measurements(ctx context.Context, dsInfo *models.DatasourceInfo) ([]string, error) {
+	return queryValues(ctx, dsInfo, "SELECT table_name FROM information_schema.tables WHERE table_schema = 'iox'")
+}
+
+// TagKeys returns the tag columns of the table, or of the database when the measurement is empty.
+func TagKeys(ctx context.Context, dsInfo *models.DatasourceInfo, measurement string) ([]string, error) {
+	return queryValues(ctx, dsInfo, fmt.Sprintf("SELECT column_name FROM information_schema.columns WHERE table_schema = 'iox'%s AND data_type = %s",
+		tableCondition(measurement), quoteSQLString(tagDataType)))
+}
+
+// FieldKeys returns the field columns of the table, or of the database when the measurement is empty.
+func FieldKeys(ctx context.Context, dsInfo *models.DatasourceInfo, measurement string) ([]string, error) {
+	return queryValues(ctx, dsInfo, fmt.Sprintf("SELECT column_name FROM information_schema.columns WHERE table_schema = 'iox'%s AND column_name <> 'time' AND data_type <> %s",
+		tableCondition(measurement), quoteSQLString(tagDataType)))
+}
+
+// RetentionPolicies returns no retention policies, as the databases of InfluxDB 3 have a single retention period.
+func RetentionPolicies(_ context.Context, _ *models.DatasourceInfo) ([]string, error) {
+	return []string{}, nil
+}
+
+func tableCondition(measurement string) string {
+	if measurement == "" {
+		return ""
+	}
+	return " AND table_name = " + quoteSQLString(measurement)
+}
+
+// queryValues runs an SQL query and returns the sorted distinct values of its first column.
+func queryValues(ctx context.Context, dsInfo *models.DatasourceInfo, sql string) ([]string, error) {
+	logger := glog.FromContext(ctx)
+	r, err := runnerFromDataSource(dsInfo)
+	if err != nil {
+		return nil, err
+	}
+	defer func(client *client) {
+		if err := client.Close(); err != nil {
+			logger.Warn("Failed to close fsql client", "err", err)
+		}
+	}(r.client)
+
+	if r.client.md.Len() != 0 {
+		ctx = metadata.NewOutgoingContext(ctx, r.client.md)
+	}
+
+	info, err := r.client.Execute(ctx, sql)
+	if err != nil {
+		return nil, fmt.Errorf("flightsql: %w", err)
+	}
+	if len(info.Endpoint) != 1 {
+		return nil, fmt.Errorf("unsupported endpoint count in response: %d", len(info.Endpoint))
+	}
+
+	reader, err := r.client.DoGetWithHeaderExtraction(ctx, info.Endpoint[0].Ticket)
+	if err != nil {
+		return nil, fmt.Errorf("flightsql: %w", err)
+	}
+	defer reader.Release()
+
+	frame, err := frameForRecords(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	values := []string{}
+	if len(frame.Fields) == 0 {
+		return values, nil
+	}
+	for i := 0; i < frame.Fields[0].Len(); i++ {
+		var value string
+		switch v := frame.Fields[0].At(i).(type) {
+		case string:
+			value = v
+		case *string:
+			if v == nil {
+				continue
+			}
+			value = *v
+		default:
+			continue
+		}
+		if !seen[value] {
+			seen[value] = true
+			values = append(values, value)
+		}
+	}
+	sort.Strings(values)
+	return values, nil
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/tracing"
 
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
@@ -23,15 +24,24 @@ import (
 var logger log.Logger = log.New("tsdb.influxdb")
 
 type Service struct {
-	im       instancemgmt.InstanceManager
-	features featuremgmt.FeatureToggles
+	im              instancemgmt.InstanceManager
+	features        featuremgmt.FeatureToggles
+	resourceHandler backend.CallResourceHandler
 }
 
 func ProvideService(httpClient httpclient.Provider, features featuremgmt.FeatureToggles) *Service {
-	return &Service{
+	s := &Service{
 		im:       datasource.NewInstanceManager(newInstanceSettings(httpClient)),
 		features: features,
 	}
+	s.resourceHandler = httpadapter.New(s.newResourceMux())
+	return s
+}
+
+// CallResource serves the /measurements, /tag-keys, /field-keys and /retention-policies resources, which list the
+// schema of the database in the InfluxQL, Flux and SQL modes.
+func (s *Service) CallResource(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	return s.resourceHandler.CallResource(ctx, req, sender)
 }
 
 func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.InstanceFactoryFunc {
@@ -83,6 +93,7 @@ func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.Inst
 			Organization:  jsonData.Organization,
 			MaxSeries:     maxSeries,
 			InsecureGrpc:  jsonData.InsecureGrpc,
+			InfluxQLToSQL: jsonData.InfluxQLToSQL,
 			Token:         settings.DecryptedSecureJSONData["token"],
 			Timeout:       opts.Timeouts.Timeout,
 		}
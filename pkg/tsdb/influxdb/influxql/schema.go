@@ -0,0 +1,100 @@
+package influxql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/tsdb/influxdb/models"
+)
+
+// Measurements returns the measurements of the database.
+func Measurements(ctx context.Context, dsInfo *models.DatasourceInfo) ([]string, error) {
+	return showValues(ctx, dsInfo, "SHOW MEASUREMENTS LIMIT 1000")
+}
+
+// TagKeys returns the tag keys of the measurement, or of the database when the measurement is empty.
+func TagKeys(ctx context.Context, dsInfo *models.DatasourceInfo, measurement string) ([]string, error) {
+	return showValues(ctx, dsInfo, "SHOW TAG KEYS"+fromMeasurement(measurement))
+}
+
+// FieldKeys returns the field keys of the measurement, or of the database when the measurement is empty.
+func FieldKeys(ctx context.Context, dsInfo *models.DatasourceInfo, measurement string) ([]string, error) {
+	return showValues(ctx, dsInfo, "SHOW FIELD KEYS"+fromMeasurement(measurement))
+}
+
+// RetentionPolicies returns the retention policies of the database.
+func RetentionPolicies(ctx context.Context, dsInfo *models.DatasourceInfo) ([]string, error) {
+	return showValues(ctx, dsInfo, fmt.Sprintf("SHOW RETENTION POLICIES ON %s", quoteIdentifier(dsInfo.DbName)))
+}
+
+func fromMeasurement(measurement string) string {
+	if measurement == "" {
+		return ""
+	}
+	return " FROM " + quoteIdentifier(measurement)
+}
+
+func quoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(strings.ReplaceAll(name, `\`, `\\`), `"`, `\"`) + `"`
+}
+
+// showValues runs a SHOW query and returns the sorted distinct values of the first column of its series.
+func showValues(ctx context.Context, dsInfo *models.DatasourceInfo, query string) ([]string, error) {
+	logger := glog.FromContext(ctx)
+	req, err := createRequest(ctx, logger, dsInfo, query, "")
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := dsInfo.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			logger.Warn("Failed to close response body", "err", err)
+		}
+	}()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var response models.Response
+	if err := json.Unmarshal(body, &response); err != nil {
+		if res.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("InfluxDB returned error: %s", strings.TrimSpace(string(body)))
+		}
+		return nil, fmt.Errorf("failed to decode InfluxDB response: %w", err)
+	}
+	if response.Error != "" {
+		return nil, fmt.Errorf("InfluxDB returned error: %s", response.Error)
+	}
+
+	seen := map[string]bool{}
+	values := []string{}
+	for _, result := range response.Results {
+		if result.Error != "" {
+			return nil, fmt.Errorf("InfluxDB returned error: %s", result.Error)
+		}
+		for _, series := range result.Series {
+			for _, row := range series.Values {
+				if len(row) == 0 {
+					continue
+				}
+				if value, ok := row[0].(string); ok && !seen[value] {
+					seen[value] = true
+					values = append(values, value)
+				}
+			}
+		}
+	}
+	sort.Strings(values)
+	return values, nil
+}
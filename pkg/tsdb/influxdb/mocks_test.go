@@ -12,6 +12,7 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	sdkhttpclient "github.com/grafana/grafana-plugin-sdk-go/backend/httpclient"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
 
 	"github.com/grafana/grafana/pkg/infra/httpclient"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
@@ -113,7 +114,7 @@ func (rt *RoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
 }
 
 func GetMockService(version string, rt RoundTripper) *Service {
-	return &Service{
+	s := &Service{
 		im: &fakeInstance{
 			version:          version,
 			fakeRoundTripper: rt,
@@ -122,4 +123,6 @@ func GetMockService(version string, rt RoundTripper) *Service {
 		// featuremgmt.FlagInfluxqlStreamingParser: false
 		features: featuremgmt.WithFeatures(),
 	}
+	s.resourceHandler = httpadapter.New(s.newResourceMux())
+	return s
 }
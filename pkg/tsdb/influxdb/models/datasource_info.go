@@ -22,4 +22,6 @@ type DatasourceInfo struct {
 
 	// FlightSQL grpc connection
 	InsecureGrpc bool `json:"insecureGrpc"`
+	// InfluxQLToSQL translates the InfluxQL builder queries to SQL in the FlightSQL mode
+	InfluxQLToSQL bool `json:"influxqlToSql"`
 }
@@ -0,0 +1,98 @@
+package influxdb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
+
+	"github.com/grafana/grafana/pkg/tsdb/influxdb/flux"
+	"github.com/grafana/grafana/pkg/tsdb/influxdb/fsql"
+	"github.com/grafana/grafana/pkg/tsdb/influxdb/influxql"
+	"github.com/grafana/grafana/pkg/tsdb/influxdb/models"
+)
+
+// schemaQuerier lists the schema of the database, as InfluxQL, Flux and FlightSQL describe it differently.
+type schemaQuerier struct {
+	measurements      func(ctx context.Context, dsInfo *models.DatasourceInfo) ([]string, error)
+	tagKeys           func(ctx context.Context, dsInfo *models.DatasourceInfo, measurement string) ([]string, error)
+	fieldKeys         func(ctx context.Context, dsInfo *models.DatasourceInfo, measurement string) ([]string, error)
+	retentionPolicies func(ctx context.Context, dsInfo *models.DatasourceInfo) ([]string, error)
+}
+
+var schemaQueriers = map[string]schemaQuerier{
+	influxVersionInfluxQL: {influxql.Measurements, influxql.TagKeys, influxql.FieldKeys, influxql.RetentionPolicies},
+	influxVersionFlux:     {flux.Measurements, flux.TagKeys, flux.FieldKeys, flux.RetentionPolicies},
+	influxVersionSQL:      {fsql.Measurements, fsql.TagKeys, fsql.FieldKeys, fsql.RetentionPolicies},
+}
+
+func (s *Service) newResourceMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/measurements", s.handleSchema(func(ctx context.Context, q schemaQuerier, dsInfo *models.DatasourceInfo, _ string) ([]string, error) {
+		return q.measurements(ctx, dsInfo)
+	}))
+	mux.HandleFunc("/tag-keys", s.handleSchema(func(ctx context.Context, q schemaQuerier, dsInfo *models.DatasourceInfo, measurement string) ([]string, error) {
+		return q.tagKeys(ctx, dsInfo, measurement)
+	}))
+	mux.HandleFunc("/field-keys", s.handleSchema(func(ctx context.Context, q schemaQuerier, dsInfo *models.DatasourceInfo, measurement string) ([]string, error) {
+		return q.fieldKeys(ctx, dsInfo, measurement)
+	}))
+	mux.HandleFunc("/retention-policies", s.handleSchema(func(ctx context.Context, q schemaQuerier, dsInfo *models.DatasourceInfo, _ string) ([]string, error) {
+		return q.retentionPolicies(ctx, dsInfo)
+	}))
+	return mux
+}
+
+// handleSchema returns a handler which responds with the names listed by the query, for the measurement of the
+// optional measurement parameter.
+func (s *Service) handleSchema(query func(ctx context.Context, q schemaQuerier, dsInfo *models.DatasourceInfo, measurement string) ([]string, error)) http.HandlerFunc {
+	return func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			writeResourceError(rw, http.StatusMethodNotAllowed, fmt.Sprintf("method %s not allowed", req.Method))
+			return
+		}
+
+		ctx := req.Context()
+		dsInfo, err := s.getDSInfo(ctx, httpadapter.PluginConfigFromContext(ctx))
+		if err != nil {
+			writeResourceError(rw, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		querier, ok := schemaQueriers[dsInfo.Version]
+		if !ok {
+			writeResourceError(rw, http.StatusBadRequest, fmt.Sprintf("unknown influxdb version %q", dsInfo.Version))
+			return
+		}
+
+		names, err := query(ctx, querier, dsInfo, req.URL.Query().Get("measurement"))
+		if err != nil {
+			logger.FromContext(ctx).Error("Failed to query the schema", "path", req.URL.Path, "error", err)
+			writeResourceError(rw, http.StatusBadGateway, err.Error())
+			return
+		}
+		writeResourceResponse(rw, names)
+	}
+}
+
+func writeResourceResponse(rw http.ResponseWriter, result any) {
+	body, err := json.Marshal(result)
+	if err != nil {
+		writeResourceError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	if _, err := rw.Write(body); err != nil {
+		logger.Error("Failed to write response", "error", err)
+	}
+}
+
+func writeResourceError(rw http.ResponseWriter, status int, message string) {
+	body, _ := json.Marshal(map[string]string{"message": message})
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(status)
+	_, _ = rw.Write(body)
+}
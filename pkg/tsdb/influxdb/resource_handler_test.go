@@ -0,0 +1,70 @@
+package influxdb
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func callResource(t *testing.T, s *Service, path string) *backend.CallResourceResponse {
+	t.Helper()
+	var res *backend.CallResourceResponse
+	err := s.CallResource(context.Background(), &backend.CallResourceRequest{
+		Method: http.MethodGet,
+		Path:   path,
+		URL:    path,
+	}, backend.CallResourceResponseSenderFunc(func(r *backend.CallResourceResponse) error {
+		res = r
+		return nil
+	}))
+	require.NoError(t, err)
+	require.NotNil(t, res)
+	return res
+}
+
+func TestSchemaResources(t *testing.T) {
+	t.Run("lists the measurements with InfluxQL", func(t *testing.T) {
+		s := GetMockService(influxVersionInfluxQL, RoundTripper{Body: `{"results": [{"statement_id": 0, "series": [
+			{"name": "measurements", "columns": ["name"], "values": [["mem"], ["cpu"]]}
+		]}]}`})
+
+		res := callResource(t, s, "/measurements")
+		require.Equal(t, http.StatusOK, res.Status)
+		var names []string
+		require.NoError(t, json.Unmarshal(res.Body, &names))
+		assert.Equal(t, []string{"cpu", "mem"}, names)
+	})
+
+	t.Run("lists the field keys of a measurement with InfluxQL", func(t *testing.T) {
+		s := GetMockService(influxVersionInfluxQL, RoundTripper{Body: `{"results": [{"statement_id": 0, "series": [
+			{"name": "cpu", "columns": ["fieldKey", "fieldType"], "values": [["usage_user", "float"], ["usage_idle", "float"]]}
+		]}]}`})
+
+		res := callResource(t, s, "/field-keys?measurement=cpu")
+		require.Equal(t, http.StatusOK, res.Status)
+		var names []string
+		require.NoError(t, json.Unmarshal(res.Body, &names))
+		assert.Equal(t, []string{"usage_idle", "usage_user"}, names)
+	})
+
+	t.Run("returns the errors of InfluxDB", func(t *testing.T) {
+		s := GetMockService(influxVersionInfluxQL, RoundTripper{Body: `{"results": [{"statement_id": 0, "error": "database not found: testdb"}]}`})
+
+		res := callResource(t, s, "/retention-policies")
+		require.Equal(t, http.StatusBadGateway, res.Status)
+		assert.JSONEq(t, `{"message": "InfluxDB returned error: database not found: testdb"}`, string(res.Body))
+	})
+
+	t.Run("returns no retention policies with SQL", func(t *testing.T) {
+		s := GetMockService(influxVersionSQL, RoundTripper{})
+
+		res := callResource(t, s, "/retention-policies")
+		require.Equal(t, http.StatusOK, res.Status)
+		assert.JSONEq(t, `[]`, string(res.Body))
+	})
+}
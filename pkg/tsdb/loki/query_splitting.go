@@ -0,0 +1,426 @@
+package loki
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/dskit/concurrency"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+const (
+	defaultSplitMaxConcurrency = 5
+
+	// streamShardLabel is the label Loki adds to the streams when it shards them by size
+	streamShardLabel = "__stream_shard__"
+)
+
+// querySplittingSettings are the settings of the data source splitting the range queries into smaller
+// queries, which are run concurrently and whose responses are merged. Long range queries are then less
+// likely to time out.
+type querySplittingSettings struct {
+	// Duration is the duration of the time chunks. The queries aren't split by time when it is zero.
+	Duration time.Duration
+	// MaxConcurrency is the maximum number of the queries of a split query running at the same time.
+	MaxConcurrency int
+	// Sharding splits the log queries further by the stream shards of their stream selector.
+	Sharding bool
+}
+
+type querySplittingJSONData struct {
+	Duration       string `json:"querySplittingDuration"`
+	MaxConcurrency int    `json:"querySplittingMaxConcurrency"`
+	Sharding       bool   `json:"queryShardingEnabled"`
+}
+
+func parseQuerySplittingSettings(jsonData json.RawMessage) (querySplittingSettings, error) {
+	settings := querySplittingSettings{MaxConcurrency: defaultSplitMaxConcurrency}
+	if len(jsonData) == 0 {
+		return settings, nil
+	}
+
+	var model querySplittingJSONData
+	if err := json.Unmarshal(jsonData, &model); err != nil {
+		return settings, fmt.Errorf("failed to parse the query splitting settings: %w", err)
+	}
+
+	if model.Duration != "" {
+		duration, err := gtime.ParseDuration(model.Duration)
+		if err != nil {
+			return settings, fmt.Errorf("invalid query splitting duration %q: %w", model.Duration, err)
+		}
+		settings.Duration = duration
+	}
+	if model.MaxConcurrency > 0 {
+		settings.MaxConcurrency = model.MaxConcurrency
+	}
+	settings.Sharding = model.Sharding
+	return settings, nil
+}
+
+func (s querySplittingSettings) enabled() bool {
+	return s.Duration > 0 || s.Sharding
+}
+
+// isLogsQuery returns true for the queries returning log lines, which start with their stream selector.
+func isLogsQuery(expr string) bool {
+	return strings.HasPrefix(strings.TrimSpace(expr), "{")
+}
+
+// splitQueryByTime returns the queries of the time chunks of a range query, in chronological order.
+// Instant queries aren't split.
+//
+// The log queries are split from their end, as Loki includes the start of their time range but not the
+// end, so that the shorter chunk is the oldest one. The metric queries are split at multiples of their step,
+// as Loki includes both ends of their time range, so that the data points are the same as the ones of
+// the query which isn't split.
+func splitQueryByTime(query *lokiQuery, duration time.Duration) []*lokiQuery {
+	if query.QueryType != QueryTypeRange || duration <= 0 {
+		return []*lokiQuery{query}
+	}
+
+	startMs := query.Start.UnixMilli()
+	endMs := query.End.UnixMilli()
+	durationMs := duration.Milliseconds()
+
+	chunk := func(start, end int64) *lokiQuery {
+		q := *query
+		q.Start = time.UnixMilli(start).UTC()
+		q.End = time.UnixMilli(end).UTC()
+		return &q
+	}
+
+	queries := []*lokiQuery{}
+	if isLogsQuery(query.Expr) {
+		if endMs-startMs <= durationMs {
+			return []*lokiQuery{query}
+		}
+		for end := endMs; end > startMs; end -= durationMs {
+			queries = append(queries, chunk(max(end-durationMs, startMs), end))
+		}
+		// the chunks were created from the end of the time range
+		for i, j := 0, len(queries)-1; i < j; i, j = i+1, j-1 {
+			queries[i], queries[j] = queries[j], queries[i]
+		}
+		return queries
+	}
+
+	stepMs := query.Step.Milliseconds()
+	if stepMs <= 0 || durationMs < stepMs || endMs-startMs <= durationMs {
+		return []*lokiQuery{query}
+	}
+
+	// the last chunk may only have the data point at the end of the time range
+	alignedDurationMs := durationMs / stepMs * stepMs
+	for start := startMs - startMs%stepMs; start <= endMs; start += alignedDurationMs {
+		queries = append(queries, chunk(start, min(start+alignedDurationMs-stepMs, endMs)))
+	}
+	return queries
+}
+
+// shardQuery returns a query for each of the stream shards, and one for the streams which aren't sharded.
+// The query isn't sharded when its stream selector can't be modified safely.
+func shardQuery(query *lokiQuery, shards []string) []*lokiQuery {
+	if len(shards) == 0 {
+		return []*lokiQuery{query}
+	}
+
+	queries := make([]*lokiQuery, 0, len(shards)+1)
+	for _, shard := range append(append([]string{}, shards...), "") {
+		expr, ok := addStreamShardMatcher(query.Expr, shard)
+		if !ok {
+			return []*lokiQuery{query}
+		}
+		q := *query
+		q.Expr = expr
+		queries = append(queries, &q)
+	}
+	return queries
+}
+
+// addStreamShardMatcher adds the matcher of a stream shard to the stream selector of a log query. Only the
+// queries with a single pair of braces are modified, as the others may have several stream selectors or
+// templates such as the ones of line_format.
+func addStreamShardMatcher(expr string, shard string) (string, bool) {
+	if !isLogsQuery(expr) || strings.Count(expr, "{") != 1 || strings.Count(expr, "}") != 1 {
+		return expr, false
+	}
+
+	end := strings.Index(expr, "}")
+	start := strings.Index(expr, "{")
+	matcher := fmt.Sprintf("%s=%q", streamShardLabel, shard)
+	if strings.TrimSpace(expr[start+1:end]) == "" {
+		return expr[:start+1] + matcher + expr[end:], true
+	}
+	return expr[:end] + ", " + matcher + expr[end:], true
+}
+
+// streamSelector returns the stream selector of a log query.
+func streamSelector(expr string) string {
+	start := strings.Index(expr, "{")
+	end := strings.Index(expr, "}")
+	if start == -1 || end < start {
+		return ""
+	}
+	return expr[start : end+1]
+}
+
+// StreamShards returns the values of the stream shard label of the streams selected by a log query.
+func (api *LokiAPI) StreamShards(ctx context.Context, query *lokiQuery) ([]string, error) {
+	qs := url.Values{}
+	qs.Set("query", streamSelector(query.Expr))
+	qs.Set("start", strconv.FormatInt(query.Start.UnixNano(), 10))
+	qs.Set("end", strconv.FormatInt(query.End.UnixNano(), 10))
+
+	res, err := api.RawQuery(ctx, fmt.Sprintf("/loki/api/v1/label/%s/values?%s", streamShardLabel, qs.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	if res.Status/100 != 2 {
+		return nil, makeLokiError(res.Body)
+	}
+
+	var values struct {
+		Data []string `json:"data"`
+	}
+	if err := json.Unmarshal(res.Body, &values); err != nil {
+		return nil, err
+	}
+	return values.Data, nil
+}
+
+// runSplitQuery splits a query by time and by stream shards according to the settings, runs the queries with a
+// bounded concurrency, and returns their merged responses.
+//
+// The frames are returned with an error when only some of the queries failed, so that the partial results
+// are still displayed.
+func runSplitQuery(ctx context.Context, api *LokiAPI, query *lokiQuery, settings querySplittingSettings, responseOpts ResponseOpts, plog log.Logger) (*backend.DataResponse, error) {
+	queries := splitQueryByTime(query, settings.Duration)
+
+	if settings.Sharding && query.QueryType == QueryTypeRange && isLogsQuery(query.Expr) {
+		if _, ok := addStreamShardMatcher(query.Expr, ""); ok {
+			shards, err := api.StreamShards(ctx, query)
+			if err != nil {
+				plog.Warn("Failed to get the stream shards, the query isn't sharded", "error", err)
+			} else {
+				sharded := make([]*lokiQuery, 0, len(queries)*(len(shards)+1))
+				for _, q := range queries {
+					sharded = append(sharded, shardQuery(q, shards)...)
+				}
+				queries = sharded
+			}
+		}
+	}
+
+	if len(queries) == 1 {
+		return runQuery(ctx, api, queries[0], responseOpts, plog)
+	}
+
+	plog.Debug("Running split query", "queries", len(queries), "maxConcurrency", settings.MaxConcurrency)
+
+	responses := make([]*backend.DataResponse, len(queries))
+	errs := make([]error, len(queries))
+	mu := sync.Mutex{}
+	err := concurrency.ForEachJob(ctx, len(queries), settings.MaxConcurrency, func(ctx context.Context, idx int) error {
+		res, err := runQuery(ctx, api, queries[idx], responseOpts, plog)
+		if err == nil && res != nil && res.Error != nil {
+			err = res.Error
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			errs[idx] = err
+			if res != nil {
+				responses[idx] = &backend.DataResponse{ErrorSource: res.ErrorSource}
+			}
+			return nil // errors are saved per query, so that the other queries complete
+		}
+		responses[idx] = res
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &backend.DataResponse{Frames: mergeSplitFrames(query, responses)}
+	failed := 0
+	for idx, queryErr := range errs {
+		if queryErr == nil {
+			continue
+		}
+		if failed == 0 {
+			result.Error = queryErr
+			if responses[idx] != nil {
+				result.ErrorSource = responses[idx].ErrorSource
+			}
+		}
+		failed++
+	}
+	if failed > 0 && failed < len(queries) {
+		result.Error = fmt.Errorf("%d of %d split queries failed, the results are incomplete: %w", failed, len(queries), result.Error)
+	}
+	return result, nil
+}
+
+// mergeSplitFrames merges the frames of the responses of the split queries. The log lines are merged in a single
+// frame, sorted in the direction of the query and limited to its maximum number of lines. The data points of
+// the metric frames with the same name and labels are merged in a single frame. The duplicate log lines and
+// data points at the limits of the time chunks are removed.
+func mergeSplitFrames(query *lokiQuery, responses []*backend.DataResponse) data.Frames {
+	groups := map[string]data.Frames{}
+	keys := []string{}
+	for _, res := range responses {
+		if res == nil {
+			continue
+		}
+		for _, frame := range res.Frames {
+			key := splitFrameKey(frame)
+			if _, ok := groups[key]; !ok {
+				keys = append(keys, key)
+			}
+			groups[key] = append(groups[key], frame)
+		}
+	}
+
+	frames := make(data.Frames, 0, len(keys))
+	for _, key := range keys {
+		frames = append(frames, mergeFrames(query, groups[key]))
+	}
+	return frames
+}
+
+func splitFrameKey(frame *data.Frame) string {
+	if isLogsFrame(frame) {
+		return "logs"
+	}
+	var labels data.Labels
+	if len(frame.Fields) > 1 {
+		labels = frame.Fields[1].Labels
+	}
+	return frame.Name + "\x00" + labels.String()
+}
+
+func isLogsFrame(frame *data.Frame) bool {
+	_, idx := frame.FieldByName("id")
+	return idx != -1
+}
+
+func mergeFrames(query *lokiQuery, frames data.Frames) *data.Frame {
+	base := frames[0]
+	if len(frames) == 1 && !isLogsFrame(base) {
+		return base
+	}
+
+	timeIndex := -1
+	for i, field := range base.Fields {
+		if field.Type() == data.FieldTypeTime {
+			timeIndex = i
+			break
+		}
+	}
+	_, idIndex := base.FieldByName("id")
+	logs := idIndex != -1
+
+	type row struct {
+		frame *data.Frame
+		index int
+	}
+	rows := []row{}
+	seen := map[string]bool{}
+	for _, frame := range frames {
+		if !sameFrameSchema(base, frame) {
+			continue
+		}
+		for i := 0; i < frame.Rows(); i++ {
+			var key string
+			if logs {
+				key = frame.Fields[idIndex].At(i).(string)
+			} else if timeIndex != -1 {
+				key = strconv.FormatInt(frame.Fields[timeIndex].At(i).(time.Time).UnixNano(), 10)
+			}
+			if key != "" {
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+			}
+			rows = append(rows, row{frame: frame, index: i})
+		}
+	}
+
+	if timeIndex != -1 {
+		descending := logs && query.Direction == DirectionBackward
+		sort.SliceStable(rows, func(a, b int) bool {
+			ta := rows[a].frame.Fields[timeIndex].At(rows[a].index).(time.Time)
+			tb := rows[b].frame.Fields[timeIndex].At(rows[b].index).(time.Time)
+			if descending {
+				return ta.After(tb)
+			}
+			return ta.Before(tb)
+		})
+	}
+	if logs && query.MaxLines > 0 && len(rows) > query.MaxLines {
+		rows = rows[:query.MaxLines]
+	}
+
+	merged := base.EmptyCopy()
+	for i, field := range base.Fields {
+		merged.Fields[i].Config = field.Config
+	}
+	for _, r := range rows {
+		merged.AppendRow(r.frame.RowCopy(r.index)...)
+	}
+
+	if base.Meta != nil {
+		meta := *base.Meta
+		meta.Stats = mergeStats(frames)
+		if logs {
+			meta.ExecutedQueryString = "Expr: " + query.Expr
+		}
+		merged.Meta = &meta
+	}
+	return merged
+}
+
+func sameFrameSchema(a *data.Frame, b *data.Frame) bool {
+	if len(a.Fields) != len(b.Fields) {
+		return false
+	}
+	for i := range a.Fields {
+		if a.Fields[i].Name != b.Fields[i].Name || a.Fields[i].Type() != b.Fields[i].Type() {
+			return false
+		}
+	}
+	return true
+}
+
+// mergeStats sums the stats of the frames with the same name.
+func mergeStats(frames data.Frames) []data.QueryStat {
+	var stats []data.QueryStat
+	indexes := map[string]int{}
+	for _, frame := range frames {
+		if frame.Meta == nil {
+			continue
+		}
+		for _, stat := range frame.Meta.Stats {
+			if i, ok := indexes[stat.DisplayName]; ok {
+				stats[i].Value += stat.Value
+				continue
+			}
+			indexes[stat.DisplayName] = len(stats)
+			stats = append(stats, stat)
+		}
+	}
+	return stats
+}
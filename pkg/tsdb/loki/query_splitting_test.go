@@ -0,0 +1,211 @@
+package loki
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// makeSplitMockedAPI returns an API whose responses are returned by the handler, which is called concurrently.
+func makeSplitMockedAPI(handler func(req *http.Request) (int, string)) *LokiAPI {
+	mu := sync.Mutex{}
+	client := http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			status, body := handler(req)
+			return &http.Response{
+				StatusCode: status,
+				Header:     http.Header{"Content-Type": []string{"application/json"}},
+				Body:       io.NopCloser(bytes.NewReader([]byte(body))),
+			}, nil
+		}),
+	}
+	return newLokiAPI(&client, "http://localhost:3100", backend.NewLoggerWith("logger", "test"), tracing.InitializeTracerForTest(), false)
+}
+
+func requestNs(t *testing.T, req *http.Request, param string) int64 {
+	t.Helper()
+	ns, err := strconv.ParseInt(req.URL.Query().Get(param), 10, 64)
+	require.NoError(t, err)
+	return ns
+}
+
+func TestParseQuerySplittingSettings(t *testing.T) {
+	t.Run("splitting is disabled by default", func(t *testing.T) {
+		settings, err := parseQuerySplittingSettings([]byte(`{"maxLines": "1000"}`))
+		require.NoError(t, err)
+		assert.False(t, settings.enabled())
+		assert.Equal(t, defaultSplitMaxConcurrency, settings.MaxConcurrency)
+	})
+
+	t.Run("parses the settings", func(t *testing.T) {
+		settings, err := parseQuerySplittingSettings([]byte(`{"querySplittingDuration": "1d", "querySplittingMaxConcurrency": 2, "queryShardingEnabled": true}`))
+		require.NoError(t, err)
+		assert.Equal(t, querySplittingSettings{Duration: 24 * time.Hour, MaxConcurrency: 2, Sharding: true}, settings)
+	})
+
+	t.Run("rejects invalid durations", func(t *testing.T) {
+		_, err := parseQuerySplittingSettings([]byte(`{"querySplittingDuration": "one day"}`))
+		require.Error(t, err)
+	})
+}
+
+func TestSplitQueryByTime(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("splits log queries from the end of the time range", func(t *testing.T) {
+		query := &lokiQuery{Expr: `{app="a"}`, QueryType: QueryTypeRange, Start: start, End: start.Add(150 * time.Minute)}
+		queries := splitQueryByTime(query, time.Hour)
+		require.Len(t, queries, 3)
+		assert.Equal(t, start, queries[0].Start)
+		assert.Equal(t, start.Add(30*time.Minute), queries[0].End)
+		assert.Equal(t, start.Add(30*time.Minute), queries[1].Start)
+		assert.Equal(t, start.Add(90*time.Minute), queries[1].End)
+		assert.Equal(t, start.Add(90*time.Minute), queries[2].Start)
+		assert.Equal(t, start.Add(150*time.Minute), queries[2].End)
+	})
+
+	t.Run("splits metric queries at multiples of the step", func(t *testing.T) {
+		query := &lokiQuery{Expr: `rate({app="a"}[1m])`, QueryType: QueryTypeRange, Step: time.Minute, Start: start.Add(30 * time.Second), End: start.Add(2 * time.Hour)}
+		queries := splitQueryByTime(query, time.Hour)
+		require.Len(t, queries, 3)
+		assert.Equal(t, start, queries[0].Start)
+		assert.Equal(t, start.Add(59*time.Minute), queries[0].End)
+		assert.Equal(t, start.Add(time.Hour), queries[1].Start)
+		assert.Equal(t, start.Add(119*time.Minute), queries[1].End)
+		assert.Equal(t, start.Add(2*time.Hour), queries[2].Start)
+		assert.Equal(t, start.Add(2*time.Hour), queries[2].End)
+	})
+
+	t.Run("doesn't split instant and short queries", func(t *testing.T) {
+		instant := &lokiQuery{Expr: `count_over_time({app="a"}[1d])`, QueryType: QueryTypeInstant, Start: start, End: start.Add(48 * time.Hour)}
+		assert.Equal(t, []*lokiQuery{instant}, splitQueryByTime(instant, time.Hour))
+
+		short := &lokiQuery{Expr: `{app="a"}`, QueryType: QueryTypeRange, Start: start, End: start.Add(time.Hour)}
+		assert.Equal(t, []*lokiQuery{short}, splitQueryByTime(short, time.Hour))
+	})
+}
+
+func TestAddStreamShardMatcher(t *testing.T) {
+	expr, ok := addStreamShardMatcher(`{app="a", env=~"prod|dev"} |= "error"`, "1")
+	require.True(t, ok)
+	assert.Equal(t, `{app="a", env=~"prod|dev", __stream_shard__="1"} |= "error"`, expr)
+
+	expr, ok = addStreamShardMatcher(`{app="a"}`, "")
+	require.True(t, ok)
+	assert.Equal(t, `{app="a", __stream_shard__=""}`, expr)
+
+	_, ok = addStreamShardMatcher(`{app="a"} | line_format "{{.msg}}"`, "1")
+	assert.False(t, ok)
+
+	_, ok = addStreamShardMatcher(`sum(rate({app="a"}[1m]))`, "1")
+	assert.False(t, ok)
+}
+
+func TestRunSplitQuery(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	logger := backend.NewLoggerWith("logger", "test")
+
+	t.Run("merges the data points of the metric queries", func(t *testing.T) {
+		api := makeSplitMockedAPI(func(req *http.Request) (int, string) {
+			// every chunk returns data points at its start and its end, the last chunk has a single data point
+			from := requestNs(t, req, "start") / int64(time.Second)
+			to := requestNs(t, req, "end") / int64(time.Second)
+			return http.StatusOK, fmt.Sprintf(`{"status": "success", "data": {"resultType": "matrix", "result": [
+				{"metric": {"app": "a"}, "values": [[%d, "1"], [%d, "2"]]},
+				{"metric": {"app": "b"}, "values": [[%d, "3"]]}
+			]}}`, from, to, to)
+		})
+
+		query := &lokiQuery{Expr: `sum by (app) (rate({env="prod"}[1m]))`, QueryType: QueryTypeRange, Step: time.Minute, Start: start, End: start.Add(3 * time.Hour), RefID: "A"}
+		res, err := runSplitQuery(context.Background(), api, query, querySplittingSettings{Duration: time.Hour, MaxConcurrency: 2}, ResponseOpts{}, logger)
+		require.NoError(t, err)
+		require.NoError(t, res.Error)
+		require.Len(t, res.Frames, 2)
+
+		a := res.Frames[0]
+		assert.Equal(t, `{app="a"}`, a.Name)
+		require.Equal(t, 7, a.Rows())
+		for i := 1; i < a.Rows(); i++ {
+			assert.True(t, a.Fields[0].At(i-1).(time.Time).Before(a.Fields[0].At(i).(time.Time)))
+		}
+		assert.Equal(t, 4, res.Frames[1].Rows())
+	})
+
+	t.Run("merges the log lines of the shards and the time chunks", func(t *testing.T) {
+		var shardRequests int
+		var shards []string
+		api := makeSplitMockedAPI(func(req *http.Request) (int, string) {
+			if req.URL.Path == "/loki/api/v1/label/__stream_shard__/values" {
+				shardRequests++
+				assert.Equal(t, `{app="a"}`, req.URL.Query().Get("query"))
+				return http.StatusOK, `{"status": "success", "data": ["0", "1"]}`
+			}
+
+			query := req.URL.Query().Get("query")
+			shards = append(shards, query)
+			end := requestNs(t, req, "end")
+			// every shard returns the same line at the end of the chunk, which is only returned once
+			return http.StatusOK, fmt.Sprintf(`{"status": "success", "data": {"resultType": "streams", "result": [
+				{"stream": {"app": "a"}, "values": [["%d", "line %d"], ["%d", %q]]}
+			]}}`, end, end, end-int64(time.Minute), "shard "+query)
+		})
+
+		query := &lokiQuery{Expr: `{app="a"} |= "line"`, QueryType: QueryTypeRange, Direction: DirectionBackward, MaxLines: 5, Step: time.Minute, Start: start, End: start.Add(2 * time.Hour), RefID: "A"}
+		res, err := runSplitQuery(context.Background(), api, query, querySplittingSettings{Duration: time.Hour, MaxConcurrency: 3, Sharding: true}, ResponseOpts{}, logger)
+		require.NoError(t, err)
+		require.NoError(t, res.Error)
+
+		assert.Equal(t, 1, shardRequests)
+		assert.Len(t, shards, 6)
+		assert.Contains(t, shards, `{app="a", __stream_shard__="1"} |= "line"`)
+		assert.Contains(t, shards, `{app="a", __stream_shard__=""} |= "line"`)
+
+		require.Len(t, res.Frames, 1)
+		frame := res.Frames[0]
+		require.Equal(t, 5, frame.Rows())
+		for i := 1; i < frame.Rows(); i++ {
+			assert.False(t, frame.Fields[1].At(i-1).(time.Time).Before(frame.Fields[1].At(i).(time.Time)))
+		}
+		assert.Equal(t, fmt.Sprintf("line %d", start.Add(2*time.Hour).UnixNano()), frame.Fields[2].At(0))
+		assert.Equal(t, `Expr: {app="a"} |= "line"`, frame.Meta.ExecutedQueryString)
+	})
+
+	t.Run("returns the partial results when some queries fail", func(t *testing.T) {
+		api := makeSplitMockedAPI(func(req *http.Request) (int, string) {
+			if requestNs(t, req, "start") == start.UnixNano() {
+				return http.StatusBadGateway, `{"message": "timeout"}`
+			}
+			return http.StatusOK, fmt.Sprintf(`{"status": "success", "data": {"resultType": "streams", "result": [
+				{"stream": {"app": "a"}, "values": [["%d", "line"]]}
+			]}}`, requestNs(t, req, "end"))
+		})
+
+		query := &lokiQuery{Expr: `{app="a"}`, QueryType: QueryTypeRange, Direction: DirectionBackward, Start: start, End: start.Add(3 * time.Hour), RefID: "A"}
+		res, err := runSplitQuery(context.Background(), api, query, querySplittingSettings{Duration: time.Hour, MaxConcurrency: 1}, ResponseOpts{}, logger)
+		require.NoError(t, err)
+		require.EqualError(t, res.Error, "1 of 3 split queries failed, the results are incomplete: timeout")
+		assert.Equal(t, backend.ErrorSourceDownstream, res.ErrorSource)
+		require.Len(t, res.Frames, 1)
+		assert.Equal(t, 2, res.Frames[0].Rows())
+	})
+}
@@ -0,0 +1,62 @@
+package mssql
+
+import (
+	"strings"
+
+	"github.com/grafana/grafana/pkg/tsdb/sqleng"
+)
+
+// mssqlSchemaDialect reads the INFORMATION_SCHEMA views, which only list the tables the user of the data
+// source has privileges on, and the databases the user can access.
+//
+// The values are passed as @p parameters, which all the drivers of the data source accept. The database is part
+// of the name of the views, so it is quoted as an identifier instead.
+type mssqlSchemaDialect struct{}
+
+var _ sqleng.SchemaDialect = mssqlSchemaDialect{}
+
+func (mssqlSchemaDialect) DatabasesQuery() string {
+	return `SELECT name FROM sys.databases
+		WHERE name NOT IN ('master', 'tempdb', 'model', 'msdb') AND HAS_DBACCESS(name) = 1
+		ORDER BY name`
+}
+
+// TablesQuery returns the tables qualified with their schema, such as dbo.metrics.
+func (mssqlSchemaDialect) TablesQuery(database string) (string, []any) {
+	return `SELECT TABLE_SCHEMA + '.' + TABLE_NAME FROM ` + informationSchema(database, "TABLES") + `
+		ORDER BY TABLE_SCHEMA, TABLE_NAME`, nil
+}
+
+// ColumnsQuery accepts tables qualified with their schema, as returned by TablesQuery.
+func (mssqlSchemaDialect) ColumnsQuery(database, table string) (string, []any) {
+	query := `SELECT COLUMN_NAME, DATA_TYPE FROM ` + informationSchema(database, "COLUMNS") + ` WHERE `
+	if schema, name, ok := strings.Cut(table, "."); ok {
+		return query + `TABLE_SCHEMA = @p1 AND TABLE_NAME = @p2 ORDER BY ORDINAL_POSITION`, []any{schema, name}
+	}
+	return query + `TABLE_NAME = @p1 ORDER BY ORDINAL_POSITION`, []any{table}
+}
+
+func (mssqlSchemaDialect) FunctionsQuery(database string) (string, []any) {
+	return `SELECT ROUTINE_NAME FROM ` + informationSchema(database, "ROUTINES") + `
+		WHERE ROUTINE_TYPE = 'FUNCTION' ORDER BY ROUTINE_NAME`, nil
+}
+
+func (mssqlSchemaDialect) BuiltinFunctions() []string {
+	return []string{
+		"AVG", "CHECKSUM_AGG", "COUNT", "COUNT_BIG", "MAX", "MIN", "STDEV", "STDEVP",
+		"STRING_AGG", "SUM", "VAR", "VARP",
+	}
+}
+
+// informationSchema returns the name of the INFORMATION_SCHEMA view of the database, or of the default
+// database of the connection if the database is empty.
+func informationSchema(database, view string) string {
+	if database == "" {
+		return "INFORMATION_SCHEMA." + view
+	}
+	return quoteIdentifier(database) + ".INFORMATION_SCHEMA." + view
+}
+
+func quoteIdentifier(identifier string) string {
+	return "[" + strings.ReplaceAll(identifier, "]", "]]") + "]"
+}
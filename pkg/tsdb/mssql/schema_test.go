@@ -0,0 +1,35 @@
+package mssql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaDialect(t *testing.T) {
+	dialect := mssqlSchemaDialect{}
+
+	t.Run("tables of the default database", func(t *testing.T) {
+		query, args := dialect.TablesQuery("")
+		require.Contains(t, query, "FROM INFORMATION_SCHEMA.TABLES")
+		require.Nil(t, args)
+	})
+
+	t.Run("tables of another database", func(t *testing.T) {
+		query, _ := dialect.TablesQuery("my]db")
+		require.Contains(t, query, "FROM [my]]db].INFORMATION_SCHEMA.TABLES")
+	})
+
+	t.Run("columns of a table qualified with its schema", func(t *testing.T) {
+		query, args := dialect.ColumnsQuery("metrics", "dbo.cpu")
+		require.Contains(t, query, "FROM [metrics].INFORMATION_SCHEMA.COLUMNS WHERE TABLE_SCHEMA = @p1 AND TABLE_NAME = @p2")
+		require.Equal(t, []any{"dbo", "cpu"}, args)
+	})
+
+	t.Run("passes the table as a parameter", func(t *testing.T) {
+		query, args := dialect.ColumnsQuery("", "cpu'; DROP TABLE cpu; --")
+		require.Contains(t, query, "WHERE TABLE_NAME = @p1")
+		require.NotContains(t, query, "DROP TABLE")
+		require.Equal(t, []any{"cpu'; DROP TABLE cpu; --"}, args)
+	})
+}
@@ -0,0 +1,47 @@
+package mysql
+
+import "github.com/grafana/grafana/pkg/tsdb/sqleng"
+
+// mysqlSchemaDialect reads the information_schema views, which only list the databases and tables
+// the user of the data source has privileges on.
+type mysqlSchemaDialect struct{}
+
+var _ sqleng.SchemaDialect = mysqlSchemaDialect{}
+
+func (mysqlSchemaDialect) DatabasesQuery() string {
+	return `SELECT schema_name FROM information_schema.schemata
+		WHERE schema_name NOT IN ('information_schema', 'mysql', 'performance_schema', 'sys')
+		ORDER BY schema_name`
+}
+
+func (mysqlSchemaDialect) TablesQuery(database string) (string, []any) {
+	if database == "" {
+		return `SELECT table_name FROM information_schema.tables WHERE table_schema = database() ORDER BY table_name`, nil
+	}
+	return `SELECT table_name FROM information_schema.tables WHERE table_schema = ? ORDER BY table_name`, []any{database}
+}
+
+func (mysqlSchemaDialect) ColumnsQuery(database, table string) (string, []any) {
+	if database == "" {
+		return `SELECT column_name, data_type FROM information_schema.columns
+			WHERE table_schema = database() AND table_name = ? ORDER BY ordinal_position`, []any{table}
+	}
+	return `SELECT column_name, data_type FROM information_schema.columns
+		WHERE table_schema = ? AND table_name = ? ORDER BY ordinal_position`, []any{database, table}
+}
+
+func (mysqlSchemaDialect) FunctionsQuery(database string) (string, []any) {
+	if database == "" {
+		return `SELECT routine_name FROM information_schema.routines
+			WHERE routine_type = 'FUNCTION' AND routine_schema = database() ORDER BY routine_name`, nil
+	}
+	return `SELECT routine_name FROM information_schema.routines
+		WHERE routine_type = 'FUNCTION' AND routine_schema = ? ORDER BY routine_name`, []any{database}
+}
+
+func (mysqlSchemaDialect) BuiltinFunctions() []string {
+	return []string{
+		"AVG", "BIT_AND", "BIT_OR", "BIT_XOR", "COUNT", "GROUP_CONCAT", "MAX", "MIN",
+		"STD", "STDDEV", "STDDEV_POP", "STDDEV_SAMP", "SUM", "VAR_POP", "VAR_SAMP", "VARIANCE",
+	}
+}
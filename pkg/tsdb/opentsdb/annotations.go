@@ -0,0 +1,131 @@
+package opentsdb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana-plugin-sdk-go/experimental/errorsource"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+// annotationQueryType is the query type of the queries returning the OpenTSDB annotations.
+const annotationQueryType = "annotations"
+
+type annotationQueryModel struct {
+	// Target is the metric of the annotations.
+	Target string `json:"target"`
+	// IsGlobal returns the global annotations instead of the annotations of the series of the metric.
+	IsGlobal bool `json:"isGlobal"`
+	// FromAnnotations is set by the annotation queries of the dashboards which have no query type.
+	FromAnnotations bool `json:"fromAnnotations"`
+}
+
+func splitAnnotationQueries(queries []backend.DataQuery) (annotationQueries []backend.DataQuery, dataQueries []backend.DataQuery) {
+	for _, q := range queries {
+		if q.QueryType == annotationQueryType {
+			annotationQueries = append(annotationQueries, q)
+			continue
+		}
+		var model annotationQueryModel
+		if err := json.Unmarshal(q.JSON, &model); err == nil && model.FromAnnotations {
+			annotationQueries = append(annotationQueries, q)
+			continue
+		}
+		dataQueries = append(dataQueries, q)
+	}
+	return annotationQueries, dataQueries
+}
+
+// queryAnnotations returns the annotations of the metric in the time range of the query as an annotations frame,
+// with the time, timeEnd, text and tags fields. The custom fields of the annotations are their tags, as key=value
+// separated by commas.
+func (s *Service) queryAnnotations(ctx context.Context, logger log.Logger, dsInfo *datasourceInfo, q backend.DataQuery) backend.DataResponse {
+	var model annotationQueryModel
+	if err := json.Unmarshal(q.JSON, &model); err != nil {
+		return errorsource.Response(errorsource.PluginError(fmt.Errorf("failed to parse annotation query: %w", err), false))
+	}
+	if model.Target == "" {
+		return errorsource.Response(errorsource.DownstreamError(fmt.Errorf("the annotation query has no metric"), false))
+	}
+
+	tsdbQuery := OpenTsdbQuery{
+		Start:             q.TimeRange.From.UnixMilli(),
+		End:               q.TimeRange.To.UnixMilli(),
+		Queries:           []map[string]any{{"aggregator": "sum", "metric": model.Target}},
+		GlobalAnnotations: true,
+	}
+	req, err := s.createRequest(ctx, logger, dsInfo, tsdbQuery)
+	if err != nil {
+		return errorsource.Response(errorsource.PluginError(err, false))
+	}
+	res, err := dsInfo.HTTPClient.Do(req)
+	if err != nil {
+		return errorsource.Response(errorsource.DownstreamError(err, false))
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			logger.Warn("Failed to close response body", "err", err)
+		}
+	}()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return errorsource.Response(errorsource.DownstreamError(err, false))
+	}
+	if res.StatusCode/100 != 2 {
+		return errorsource.Response(errorsource.DownstreamError(fmt.Errorf("request failed, status: %s%s", res.Status, errorMessage(body)), false))
+	}
+
+	var series []OpenTsdbResponse
+	if err := json.Unmarshal(body, &series); err != nil {
+		return errorsource.Response(errorsource.DownstreamError(fmt.Errorf("failed to parse OpenTSDB response: %w", err), false))
+	}
+
+	var annotations []OpenTsdbAnnotation
+	if len(series) > 0 {
+		// the global annotations are returned with every series, and the annotations of the series are aggregated
+		annotations = series[0].Annotations
+		if model.IsGlobal {
+			annotations = series[0].GlobalAnnotations
+		}
+	}
+
+	frame := data.NewFrame(q.RefID,
+		data.NewField("time", nil, []time.Time{}),
+		data.NewField("timeEnd", nil, []time.Time{}),
+		data.NewField("text", nil, []string{}),
+		data.NewField("tags", nil, []string{}),
+	)
+	for _, a := range annotations {
+		start := time.Unix(a.StartTime, 0).UTC()
+		end := start
+		if a.EndTime > a.StartTime {
+			end = time.Unix(a.EndTime, 0).UTC()
+		}
+		frame.AppendRow(start, end, a.Description, customTags(a.Custom))
+	}
+	frame.Meta = &data.FrameMeta{
+		Custom: map[string]any{
+			"rowCount": len(annotations),
+		},
+	}
+
+	return backend.DataResponse{Frames: data.Frames{frame}}
+}
+
+func customTags(custom map[string]string) string {
+	tags := make([]string, 0, len(custom))
+	for k, v := range custom {
+		tags = append(tags, k+"="+v)
+	}
+	sort.Strings(tags)
+	return strings.Join(tags, ",")
+}
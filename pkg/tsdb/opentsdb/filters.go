@@ -0,0 +1,63 @@
+package opentsdb
+
+import (
+	"fmt"
+)
+
+// filterTypes are the filter types built into OpenTSDB.
+var filterTypes = map[string]bool{
+	"literal_or":      true,
+	"iliteral_or":     true,
+	"not_literal_or":  true,
+	"not_iliteral_or": true,
+	"wildcard":        true,
+	"iwildcard":       true,
+	"regexp":          true,
+}
+
+// prepareFilters validates the filters of the metric. OpenTSDB before 2.2 has no filters, so the literal_or and the
+// wildcard filters which group by their tag are converted to the tags of the metric for it, as they are equivalent.
+func prepareFilters(metric map[string]any, tsdbVersion int) error {
+	filters, ok := metric["filters"].([]Filter)
+	if !ok || len(filters) == 0 {
+		return nil
+	}
+
+	for _, f := range filters {
+		if f.Tagk == "" {
+			return fmt.Errorf("the %s filter has no tag key", f.Type)
+		}
+		if !filterTypes[f.Type] {
+			return fmt.Errorf("unknown filter type %q", f.Type)
+		}
+	}
+	if tsdbVersion >= tsdbVersion22 {
+		return nil
+	}
+
+	tags := map[string]any{}
+	if existing, ok := metric["tags"].(map[string]any); ok {
+		for k, v := range existing {
+			tags[k] = v
+		}
+	}
+	for _, f := range filters {
+		if !f.GroupBy {
+			return fmt.Errorf("the filters of the tag %q without group by require OpenTSDB 2.2 or later", f.Tagk)
+		}
+		switch f.Type {
+		case "literal_or":
+			tags[f.Tagk] = f.Filter
+		case "wildcard":
+			if f.Filter != "*" {
+				return fmt.Errorf("the wildcard filter %q of the tag %q requires OpenTSDB 2.2 or later, only * is supported", f.Filter, f.Tagk)
+			}
+			tags[f.Tagk] = "*"
+		default:
+			return fmt.Errorf("the %s filter of the tag %q requires OpenTSDB 2.2 or later", f.Type, f.Tagk)
+		}
+	}
+	delete(metric, "filters")
+	metric["tags"] = tags
+	return nil
+}
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/url"
 	"path"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -15,7 +16,9 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana-plugin-sdk-go/experimental/errorsource"
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/httpclient"
@@ -25,19 +28,48 @@ import (
 
 var logger = log.New("tsdb.opentsdb")
 
+const (
+	// the versions of OpenTSDB, as they are stored in the tsdbVersion setting
+	tsdbVersion21 = 1
+	tsdbVersion22 = 2
+	tsdbVersion23 = 3
+
+	// tsdbResolutionMs is the tsdbResolution setting of the data sources whose timestamps are in milliseconds
+	tsdbResolutionMs = 2
+
+	defaultLookupLimit = 1000
+)
+
 type Service struct {
-	im instancemgmt.InstanceManager
+	im              instancemgmt.InstanceManager
+	resourceHandler backend.CallResourceHandler
 }
 
 func ProvideService(httpClientProvider httpclient.Provider) *Service {
-	return &Service{
+	s := &Service{
 		im: datasource.NewInstanceManager(newInstanceSettings(httpClientProvider)),
 	}
+	s.resourceHandler = httpadapter.New(s.newResourceMux())
+	return s
+}
+
+// CallResource serves the /api/suggest, /api/search/lookup and /api/aggregators resources.
+func (s *Service) CallResource(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	return s.resourceHandler.CallResource(ctx, req, sender)
 }
 
 type datasourceInfo struct {
-	HTTPClient *http.Client
-	URL        string
+	HTTPClient     *http.Client
+	URL            string
+	TSDBVersion    int
+	TSDBResolution int
+	LookupLimit    int
+}
+
+type jsonData struct {
+	TSDBVersion    int `json:"tsdbVersion"`
+	TSDBResolution int `json:"tsdbResolution"`
+	LookupLimit    int `json:"lookupLimit"`
 }
 
 type DsAccess string
@@ -54,50 +86,115 @@ func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.Inst
 			return nil, err
 		}
 
+		var jd jsonData
+		if len(settings.JSONData) > 0 {
+			if err := json.Unmarshal(settings.JSONData, &jd); err != nil {
+				return nil, fmt.Errorf("error reading settings: %w", err)
+			}
+		}
+		if jd.TSDBVersion == 0 {
+			jd.TSDBVersion = tsdbVersion21
+		}
+		if jd.LookupLimit <= 0 {
+			jd.LookupLimit = defaultLookupLimit
+		}
+
 		model := &datasourceInfo{
-			HTTPClient: client,
-			URL:        settings.URL,
+			HTTPClient:     client,
+			URL:            settings.URL,
+			TSDBVersion:    jd.TSDBVersion,
+			TSDBResolution: jd.TSDBResolution,
+			LookupLimit:    jd.LookupLimit,
 		}
 
 		return model, nil
 	}
 }
 
+// QueryData runs the metric queries in a single request to OpenTSDB 2.3 and later, and in a request per query to the
+// earlier versions. The annotation queries are run separately.
 func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
-	var tsdbQuery OpenTsdbQuery
-
 	logger := logger.FromContext(ctx)
 
-	q := req.Queries[0]
-
-	myRefID := q.RefID
+	dsInfo, err := s.getDSInfo(ctx, req.PluginContext)
+	if err != nil {
+		return nil, err
+	}
 
-	tsdbQuery.Start = q.TimeRange.From.UnixNano() / int64(time.Millisecond)
-	tsdbQuery.End = q.TimeRange.To.UnixNano() / int64(time.Millisecond)
+	annotationQueries, dataQueries := splitAnnotationQueries(req.Queries)
+	result := backend.NewQueryDataResponse()
+	for _, q := range annotationQueries {
+		result.Responses[q.RefID] = s.queryAnnotations(ctx, logger, dsInfo, q)
+	}
 
-	for _, query := range req.Queries {
+	var tsdbQuery OpenTsdbQuery
+	refIDs := make([]string, 0, len(dataQueries))
+	for _, query := range dataQueries {
 		metric := s.buildMetric(query)
+		if metric == nil || metric["metric"] == "" {
+			// there is nothing to query, as in the query editor
+			result.Responses[query.RefID] = backend.DataResponse{}
+			continue
+		}
+		if err := prepareFilters(metric, dsInfo.TSDBVersion); err != nil {
+			result.Responses[query.RefID] = errorsource.Response(errorsource.DownstreamError(err, false))
+			continue
+		}
 		tsdbQuery.Queries = append(tsdbQuery.Queries, metric)
+		refIDs = append(refIDs, query.RefID)
+	}
+	if len(refIDs) == 0 {
+		return result, nil
 	}
 
+	timeRange := dataQueries[0].TimeRange
+	tsdbQuery.Start = timeRange.From.UnixNano() / int64(time.Millisecond)
+	tsdbQuery.End = timeRange.To.UnixNano() / int64(time.Millisecond)
+	tsdbQuery.MsResolution = dsInfo.TSDBResolution == tsdbResolutionMs
+	tsdbQuery.ShowQuery = dsInfo.TSDBVersion >= tsdbVersion23
+
+	// the series of the sub queries can only be told apart with showQuery, otherwise the queries are sent separately
+	batches := []OpenTsdbQuery{tsdbQuery}
+	batchRefIDs := [][]string{refIDs}
+	if !tsdbQuery.ShowQuery && len(refIDs) > 1 {
+		batches, batchRefIDs = nil, nil
+		for i, metric := range tsdbQuery.Queries {
+			single := tsdbQuery
+			single.Queries = []map[string]any{metric}
+			batches = append(batches, single)
+			batchRefIDs = append(batchRefIDs, refIDs[i:i+1])
+		}
+	}
+
+	for i, batch := range batches {
+		queriesResult, err := s.runQueries(ctx, logger, dsInfo, batch, batchRefIDs[i])
+		if err != nil {
+			return &backend.QueryDataResponse{}, err
+		}
+		for refID, r := range queriesResult.Responses {
+			result.Responses[refID] = r
+		}
+	}
+
+	return result, nil
+}
+
+// runQueries sends the metric queries in a single request to OpenTSDB, and returns the responses of the queries whose
+// refIDs are in the order of the sub queries.
+func (s *Service) runQueries(ctx context.Context, logger log.Logger, dsInfo *datasourceInfo, tsdbQuery OpenTsdbQuery, refIDs []string) (*backend.QueryDataResponse, error) {
 	// TODO: Don't use global variable
 	if setting.Env == setting.Dev {
 		logger.Debug("OpenTsdb request", "params", tsdbQuery)
 	}
 
-	dsInfo, err := s.getDSInfo(ctx, req.PluginContext)
-	if err != nil {
-		return nil, err
-	}
-
 	request, err := s.createRequest(ctx, logger, dsInfo, tsdbQuery)
 	if err != nil {
-		return &backend.QueryDataResponse{}, err
+		return nil, err
 	}
 
 	res, err := dsInfo.HTTPClient.Do(request)
 	if err != nil {
-		return &backend.QueryDataResponse{}, err
+		return nil, err
 	}
 
 	defer func() {
@@ -107,12 +204,7 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 		}
 	}()
 
-	result, err := s.parseResponse(logger, res, myRefID)
-	if err != nil {
-		return &backend.QueryDataResponse{}, err
-	}
-
-	return result, nil
+	return s.parseResponse(logger, res, tsdbQuery, refIDs)
 }
 
 func (s *Service) createRequest(ctx context.Context, logger log.Logger, dsInfo *datasourceInfo, data OpenTsdbQuery) (*http.Request, error) {
@@ -138,8 +230,13 @@ func (s *Service) createRequest(ctx context.Context, logger log.Logger, dsInfo *
 	return req, nil
 }
 
-func (s *Service) parseResponse(logger log.Logger, res *http.Response, myRefID string) (*backend.QueryDataResponse, error) {
+// parseResponse returns the series of the response of the batched queries, as the responses of the queries whose
+// refIDs are in the order of the sub queries.
+func (s *Service) parseResponse(logger log.Logger, res *http.Response, tsdbQuery OpenTsdbQuery, refIDs []string) (*backend.QueryDataResponse, error) {
 	resp := backend.NewQueryDataResponse()
+	for _, refID := range refIDs {
+		resp.Responses[refID] = backend.DataResponse{Frames: data.Frames{}}
+	}
 
 	body, err := io.ReadAll(res.Body)
 	if err != nil {
@@ -153,7 +250,7 @@ func (s *Service) parseResponse(logger log.Logger, res *http.Response, myRefID s
 
 	if res.StatusCode/100 != 2 {
 		logger.Info("Request failed", "status", res.Status, "body", string(body))
-		return nil, fmt.Errorf("request failed, status: %s", res.Status)
+		return nil, fmt.Errorf("request failed, status: %s%s", res.Status, errorMessage(body))
 	}
 
 	var responseData []OpenTsdbResponse
@@ -163,32 +260,72 @@ func (s *Service) parseResponse(logger log.Logger, res *http.Response, myRefID s
 		return nil, err
 	}
 
-	frames := data.Frames{}
 	for _, val := range responseData {
-		timeVector := make([]time.Time, 0, len(val.DataPoints))
-		values := make([]float64, 0, len(val.DataPoints))
-		name := val.Metric
-		tags := val.Tags
-
+		type point struct {
+			t time.Time
+			v float64
+		}
+		points := make([]point, 0, len(val.DataPoints))
 		for timeString, value := range val.DataPoints {
 			timestamp, err := strconv.ParseInt(timeString, 10, 64)
 			if err != nil {
 				logger.Info("Failed to unmarshal opentsdb timestamp", "timestamp", timeString)
 				return nil, err
 			}
-			timeVector = append(timeVector, time.Unix(timestamp, 0).UTC())
-			values = append(values, value)
+			t := time.Unix(timestamp, 0).UTC()
+			if tsdbQuery.MsResolution {
+				t = time.UnixMilli(timestamp).UTC()
+			}
+			points = append(points, point{t: t, v: value})
 		}
-		frames = append(frames, data.NewFrame(name,
+		sort.Slice(points, func(i, j int) bool { return points[i].t.Before(points[j].t) })
+
+		timeVector := make([]time.Time, 0, len(points))
+		values := make([]float64, 0, len(points))
+		for _, p := range points {
+			timeVector = append(timeVector, p.t)
+			values = append(values, p.v)
+		}
+
+		refID, ok := seriesRefID(val, refIDs)
+		if !ok {
+			logger.Debug("Series not matching any query", "metric", val.Metric, "tags", val.Tags)
+			continue
+		}
+		result := resp.Responses[refID]
+		result.Frames = append(result.Frames, data.NewFrame(val.Metric,
 			data.NewField("time", nil, timeVector),
-			data.NewField("value", tags, values)))
+			data.NewField("value", val.Tags, values)))
+		resp.Responses[refID] = result
 	}
-	result := resp.Responses[myRefID]
-	result.Frames = frames
-	resp.Responses[myRefID] = result
 	return resp, nil
 }
 
+// seriesRefID returns the refID of the query of a series. The queries are only batched when the request has showQuery
+// set, so that OpenTSDB returns the index of the sub query of the series.
+func seriesRefID(series OpenTsdbResponse, refIDs []string) (string, bool) {
+	if len(refIDs) == 1 {
+		return refIDs[0], true
+	}
+	if series.Query != nil && series.Query.Index >= 0 && series.Query.Index < len(refIDs) {
+		return refIDs[series.Query.Index], true
+	}
+	return "", false
+}
+
+// errorMessage returns the message of an OpenTSDB error response, prefixed by a comma, or nothing if there is none.
+func errorMessage(body []byte) string {
+	var e struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(body, &e); err != nil || e.Error.Message == "" {
+		return ""
+	}
+	return ", " + e.Error.Message
+}
+
 func (s *Service) buildMetric(query backend.DataQuery) map[string]any {
 	metric := make(map[string]any)
 
@@ -248,7 +385,16 @@ func (s *Service) buildMetric(query backend.DataQuery) map[string]any {
 	// Setting filters
 	filters, filtersCheck := model.CheckGet("filters")
 	if filtersCheck && len(filters.MustArray()) > 0 {
-		metric["filters"] = filters.MustArray()
+		var typedFilters []Filter
+		if raw, err := filters.MarshalJSON(); err == nil && json.Unmarshal(raw, &typedFilters) == nil {
+			metric["filters"] = typedFilters
+		} else {
+			metric["filters"] = filters.MustArray()
+		}
+	}
+
+	if model.Get("explicitTags").MustBool() {
+		metric["explicitTags"] = true
 	}
 
 	return metric
@@ -0,0 +1,194 @@
+package opentsdb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
+)
+
+// suggestTypes are the types of the /api/suggest endpoint.
+var suggestTypes = map[string]bool{"metrics": true, "tagk": true, "tagv": true}
+
+func (s *Service) newResourceMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/suggest", s.handleSuggest)
+	mux.HandleFunc("/api/search/lookup", s.handleLookup)
+	mux.HandleFunc("/api/aggregators", s.handleAggregators)
+	return mux
+}
+
+// LookupResult is a time series returned by the /api/search/lookup resource.
+type LookupResult struct {
+	Metric string            `json:"metric"`
+	Tags   map[string]string `json:"tags"`
+}
+
+// LookupResponse is the response of the /api/search/lookup resource.
+type LookupResponse struct {
+	Results []LookupResult `json:"results"`
+}
+
+// handleSuggest returns the metrics, tag keys or tag values starting with the q parameter.
+func (s *Service) handleSuggest(rw http.ResponseWriter, req *http.Request) {
+	dsInfo, ok := s.resourceDSInfo(rw, req)
+	if !ok {
+		return
+	}
+
+	query := req.URL.Query()
+	typ := query.Get("type")
+	if !suggestTypes[typ] {
+		writeResourceError(rw, http.StatusBadRequest, "type parameter must be metrics, tagk or tagv")
+		return
+	}
+	params := url.Values{
+		"type": []string{typ},
+		"q":    []string{query.Get("q")},
+		"max":  []string{limitParam(query.Get("max"), dsInfo.LookupLimit)},
+	}
+
+	suggestions := make([]string, 0)
+	if err := s.getResourceJSON(req.Context(), dsInfo, "api/suggest", params, &suggestions); err != nil {
+		writeResourceError(rw, http.StatusBadGateway, err.Error())
+		return
+	}
+	writeResourceResponse(rw, suggestions)
+}
+
+// handleLookup returns the time series matching the m parameter, a metric with optional tags such as
+// cpu{host=*}.
+func (s *Service) handleLookup(rw http.ResponseWriter, req *http.Request) {
+	dsInfo, ok := s.resourceDSInfo(rw, req)
+	if !ok {
+		return
+	}
+
+	query := req.URL.Query()
+	if query.Get("m") == "" {
+		writeResourceError(rw, http.StatusBadRequest, "m parameter is required")
+		return
+	}
+	params := url.Values{
+		"m":     []string{query.Get("m")},
+		"limit": []string{limitParam(query.Get("limit"), dsInfo.LookupLimit)},
+	}
+	if useMeta := query.Get("useMeta"); useMeta != "" {
+		params.Set("useMeta", useMeta)
+	}
+
+	res := LookupResponse{}
+	if err := s.getResourceJSON(req.Context(), dsInfo, "api/search/lookup", params, &res); err != nil {
+		writeResourceError(rw, http.StatusBadGateway, err.Error())
+		return
+	}
+	if res.Results == nil {
+		res.Results = []LookupResult{}
+	}
+	writeResourceResponse(rw, res)
+}
+
+// handleAggregators returns the sorted names of the aggregators of OpenTSDB.
+func (s *Service) handleAggregators(rw http.ResponseWriter, req *http.Request) {
+	dsInfo, ok := s.resourceDSInfo(rw, req)
+	if !ok {
+		return
+	}
+
+	aggregators := make([]string, 0)
+	if err := s.getResourceJSON(req.Context(), dsInfo, "api/aggregators", url.Values{}, &aggregators); err != nil {
+		writeResourceError(rw, http.StatusBadGateway, err.Error())
+		return
+	}
+	sort.Strings(aggregators)
+	writeResourceResponse(rw, aggregators)
+}
+
+func (s *Service) resourceDSInfo(rw http.ResponseWriter, req *http.Request) (*datasourceInfo, bool) {
+	if req.Method != http.MethodGet {
+		writeResourceError(rw, http.StatusMethodNotAllowed, "method not allowed")
+		return nil, false
+	}
+	dsInfo, err := s.getDSInfo(req.Context(), httpadapter.PluginConfigFromContext(req.Context()))
+	if err != nil {
+		writeResourceError(rw, http.StatusInternalServerError, err.Error())
+		return nil, false
+	}
+	return dsInfo, true
+}
+
+// limitParam returns the limit of the request, which can't be greater than the lookup limit of the data source.
+func limitParam(value string, lookupLimit int) string {
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit <= 0 || limit > lookupLimit {
+		limit = lookupLimit
+	}
+	return strconv.Itoa(limit)
+}
+
+// getResourceJSON sends a GET request to an endpoint of the OpenTSDB API and decodes its JSON response.
+func (s *Service) getResourceJSON(ctx context.Context, dsInfo *datasourceInfo, endpoint string, params url.Values, result any) error {
+	logger := logger.FromContext(ctx)
+
+	u, err := url.Parse(dsInfo.URL)
+	if err != nil {
+		return err
+	}
+	u.Path = path.Join(u.Path, endpoint)
+	u.RawQuery = params.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	res, err := dsInfo.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			logger.Warn("Failed to close response body", "error", err)
+		}
+	}()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	if res.StatusCode/100 != 2 {
+		logger.Info("Request failed", "endpoint", endpoint, "status", res.Status, "body", string(body))
+		return fmt.Errorf("request failed, status: %s%s", res.Status, errorMessage(body))
+	}
+	if err := json.Unmarshal(body, result); err != nil {
+		return fmt.Errorf("failed to parse OpenTSDB response: %w", err)
+	}
+	return nil
+}
+
+func writeResourceResponse(rw http.ResponseWriter, result any) {
+	body, err := json.Marshal(result)
+	if err != nil {
+		writeResourceError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	if _, err := rw.Write(body); err != nil {
+		logger.Error("Failed to write response", "error", err)
+	}
+}
+
+func writeResourceError(rw http.ResponseWriter, status int, message string) {
+	body, _ := json.Marshal(map[string]string{"message": message})
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(status)
+	_, _ = rw.Write(body)
+}
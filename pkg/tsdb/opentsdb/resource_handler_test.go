@@ -0,0 +1,216 @@
+package opentsdb
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeInstanceManager struct {
+	info datasourceInfo
+}
+
+func (m fakeInstanceManager) Get(_ context.Context, _ backend.PluginContext) (instancemgmt.Instance, error) {
+	info := m.info
+	return &info, nil
+}
+
+func (m fakeInstanceManager) Do(_ context.Context, _ backend.PluginContext, _ instancemgmt.InstanceCallbackFunc) error {
+	return nil
+}
+
+type tsdbRequest struct {
+	path   string
+	params map[string][]string
+	body   string
+}
+
+// newTestService returns a service whose OpenTSDB returns the responses by path.
+func newTestService(t *testing.T, info datasourceInfo, responses map[string]string) (*Service, *[]tsdbRequest) {
+	t.Helper()
+
+	requests := []tsdbRequest{}
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		body, err := io.ReadAll(req.Body)
+		require.NoError(t, err)
+		requests = append(requests, tsdbRequest{path: req.URL.Path, params: req.URL.Query(), body: string(body)})
+		response, ok := responses[req.URL.Path]
+		if !ok {
+			rw.WriteHeader(http.StatusBadRequest)
+			_, _ = rw.Write([]byte(`{"error": {"code": 400, "message": "No such name for 'metrics'"}}`))
+			return
+		}
+		_, _ = rw.Write([]byte(response))
+	}))
+	t.Cleanup(server.Close)
+
+	info.HTTPClient = server.Client()
+	info.URL = server.URL
+	if info.LookupLimit == 0 {
+		info.LookupLimit = defaultLookupLimit
+	}
+	s := &Service{im: fakeInstanceManager{info: info}}
+	s.resourceHandler = httpadapter.New(s.newResourceMux())
+	return s, &requests
+}
+
+func callResource(t *testing.T, s *Service, url string) *backend.CallResourceResponse {
+	t.Helper()
+
+	var res *backend.CallResourceResponse
+	resourcePath, _, _ := strings.Cut(url, "?")
+	err := s.CallResource(context.Background(), &backend.CallResourceRequest{Method: http.MethodGet, Path: resourcePath, URL: url},
+		backend.CallResourceResponseSenderFunc(func(r *backend.CallResourceResponse) error {
+			res = r
+			return nil
+		}))
+	require.NoError(t, err)
+	require.NotNil(t, res)
+	return res
+}
+
+func TestResources(t *testing.T) {
+	s, requests := newTestService(t, datasourceInfo{LookupLimit: 100}, map[string]string{
+		"/api/suggest":       `["cpu.user", "cpu.system"]`,
+		"/api/search/lookup": `{"type": "LOOKUP", "metric": "cpu", "results": [{"tsuid": "0001", "metric": "cpu", "tags": {"host": "a"}}]}`,
+		"/api/aggregators":   `["sum", "avg", "max"]`,
+	})
+
+	t.Run("suggests the metrics with the limit of the data source", func(t *testing.T) {
+		res := callResource(t, s, "/api/suggest?type=metrics&q=cpu&max=5000")
+		require.Equal(t, http.StatusOK, res.Status)
+		assert.JSONEq(t, `["cpu.user", "cpu.system"]`, string(res.Body))
+
+		req := (*requests)[len(*requests)-1]
+		assert.Equal(t, []string{"cpu"}, req.params["q"])
+		assert.Equal(t, []string{"100"}, req.params["max"])
+	})
+
+	t.Run("rejects unknown suggestion types", func(t *testing.T) {
+		res := callResource(t, s, "/api/suggest?type=hosts&q=a")
+		assert.Equal(t, http.StatusBadRequest, res.Status)
+	})
+
+	t.Run("looks up the time series", func(t *testing.T) {
+		res := callResource(t, s, "/api/search/lookup?m=cpu%7Bhost%3D*%7D&limit=10")
+		require.Equal(t, http.StatusOK, res.Status)
+		var lookup LookupResponse
+		require.NoError(t, json.Unmarshal(res.Body, &lookup))
+		assert.Equal(t, []LookupResult{{Metric: "cpu", Tags: map[string]string{"host": "a"}}}, lookup.Results)
+
+		req := (*requests)[len(*requests)-1]
+		assert.Equal(t, []string{"cpu{host=*}"}, req.params["m"])
+		assert.Equal(t, []string{"10"}, req.params["limit"])
+	})
+
+	t.Run("returns the sorted aggregators", func(t *testing.T) {
+		res := callResource(t, s, "/api/aggregators")
+		require.Equal(t, http.StatusOK, res.Status)
+		assert.JSONEq(t, `["avg", "max", "sum"]`, string(res.Body))
+	})
+}
+
+func TestQueryData(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	timeRange := backend.TimeRange{From: from, To: from.Add(time.Hour)}
+
+	t.Run("batches the queries and returns the series of each query", func(t *testing.T) {
+		s, requests := newTestService(t, datasourceInfo{TSDBVersion: tsdbVersion23}, map[string]string{
+			"/api/query": `[
+				{"metric": "cpu", "tags": {"host": "a"}, "dps": {"1704067260": 2, "1704067200": 1}, "query": {"index": 1}},
+				{"metric": "mem", "tags": {}, "dps": {"1704067200": 3}, "query": {"index": 0}}
+			]`,
+		})
+
+		res, err := s.QueryData(context.Background(), &backend.QueryDataRequest{Queries: []backend.DataQuery{
+			{RefID: "A", TimeRange: timeRange, JSON: []byte(`{"metric": "mem", "aggregator": "sum", "disableDownsampling": true}`)},
+			{RefID: "B", TimeRange: timeRange, JSON: []byte(`{"metric": "cpu", "aggregator": "sum", "disableDownsampling": true,
+				"filters": [{"type": "literal_or", "tagk": "host", "filter": "a|b", "groupBy": true}]}`)},
+			{RefID: "C", TimeRange: timeRange, JSON: []byte(`{"aggregator": "sum"}`)},
+		}})
+		require.NoError(t, err)
+		require.Len(t, *requests, 1)
+
+		var body map[string]any
+		require.NoError(t, json.Unmarshal([]byte((*requests)[0].body), &body))
+		assert.Equal(t, true, body["showQuery"])
+		queries := body["queries"].([]any)
+		require.Len(t, queries, 2)
+		assert.Equal(t, []any{map[string]any{"type": "literal_or", "tagk": "host", "filter": "a|b", "groupBy": true}}, queries[1].(map[string]any)["filters"])
+
+		require.Len(t, res.Responses["A"].Frames, 1)
+		assert.Equal(t, "mem", res.Responses["A"].Frames[0].Name)
+		cpu := res.Responses["B"].Frames
+		require.Len(t, cpu, 1)
+		assert.Equal(t, from, cpu[0].Fields[0].At(0))
+		assert.Equal(t, 2.0, cpu[0].Fields[1].At(1))
+		assert.Empty(t, res.Responses["C"].Frames)
+	})
+
+	t.Run("converts the filters to tags before OpenTSDB 2.2", func(t *testing.T) {
+		s, requests := newTestService(t, datasourceInfo{TSDBVersion: tsdbVersion21}, map[string]string{
+			"/api/query": `[
+				{"metric": "cpu", "tags": {"host": "a"}, "dps": {"1704067200": 1}},
+				{"metric": "cpu", "tags": {"host": "c"}, "dps": {"1704067200": 1}}
+			]`,
+		})
+
+		res, err := s.QueryData(context.Background(), &backend.QueryDataRequest{Queries: []backend.DataQuery{
+			{RefID: "A", TimeRange: timeRange, JSON: []byte(`{"metric": "cpu", "aggregator": "sum", "disableDownsampling": true,
+				"filters": [{"type": "literal_or", "tagk": "host", "filter": "a|b", "groupBy": true}]}`)},
+			{RefID: "B", TimeRange: timeRange, JSON: []byte(`{"metric": "cpu", "aggregator": "sum", "disableDownsampling": true,
+				"filters": [{"type": "wildcard", "tagk": "host", "filter": "*", "groupBy": true}]}`)},
+			{RefID: "C", TimeRange: timeRange, JSON: []byte(`{"metric": "cpu", "aggregator": "sum", "disableDownsampling": true,
+				"filters": [{"type": "regexp", "tagk": "host", "filter": "a.*", "groupBy": true}]}`)},
+		}})
+		require.NoError(t, err)
+
+		// the series of the queries can't be told apart before OpenTSDB 2.3, so the queries are sent separately
+		require.Len(t, *requests, 2)
+		assert.Contains(t, (*requests)[0].body, `"tags":{"host":"a|b"}`)
+		assert.Contains(t, (*requests)[1].body, `"tags":{"host":"*"}`)
+		assert.NotContains(t, (*requests)[0].body, `"filters"`)
+		assert.NotContains(t, (*requests)[0].body, `"showQuery"`)
+		assert.Len(t, res.Responses["A"].Frames, 2)
+		assert.Len(t, res.Responses["B"].Frames, 2)
+		require.ErrorContains(t, res.Responses["C"].Error, "requires OpenTSDB 2.2 or later")
+	})
+
+	t.Run("returns the annotations as frames", func(t *testing.T) {
+		s, requests := newTestService(t, datasourceInfo{}, map[string]string{
+			"/api/query": `[{"metric": "deploys", "tags": {}, "dps": {},
+				"annotations": [{"description": "deploy v1", "startTime": 1704067200, "custom": {"owner": "ops"}}],
+				"globalAnnotations": [{"description": "outage", "startTime": 1704067300, "endTime": 1704067900}]
+			}]`,
+		})
+
+		res, err := s.QueryData(context.Background(), &backend.QueryDataRequest{Queries: []backend.DataQuery{
+			{RefID: "A", TimeRange: timeRange, JSON: []byte(`{"fromAnnotations": true, "target": "deploys"}`)},
+			{RefID: "B", TimeRange: timeRange, QueryType: annotationQueryType, JSON: []byte(`{"target": "deploys", "isGlobal": true}`)},
+		}})
+		require.NoError(t, err)
+		require.Len(t, *requests, 2)
+		assert.Contains(t, (*requests)[0].body, `"globalAnnotations":true`)
+
+		a := res.Responses["A"].Frames[0]
+		require.Equal(t, 1, a.Rows())
+		assert.Equal(t, []any{from, from, "deploy v1", "owner=ops"}, []any{a.Fields[0].At(0), a.Fields[1].At(0), a.Fields[2].At(0), a.Fields[3].At(0)})
+
+		b := res.Responses["B"].Frames[0]
+		require.Equal(t, 1, b.Rows())
+		assert.Equal(t, from.Add(100*time.Second), b.Fields[0].At(0))
+		assert.Equal(t, from.Add(700*time.Second), b.Fields[1].At(0))
+		assert.Equal(t, "outage", b.Fields[2].At(0))
+	})
+}
@@ -1,13 +1,42 @@
 package opentsdb
 
 type OpenTsdbQuery struct {
-	Start   int64            `json:"start"`
-	End     int64            `json:"end"`
-	Queries []map[string]any `json:"queries"`
+	Start             int64            `json:"start"`
+	End               int64            `json:"end"`
+	Queries           []map[string]any `json:"queries"`
+	MsResolution      bool             `json:"msResolution,omitempty"`
+	ShowQuery         bool             `json:"showQuery,omitempty"`
+	GlobalAnnotations bool             `json:"globalAnnotations,omitempty"`
 }
 
 type OpenTsdbResponse struct {
-	Metric     string             `json:"metric"`
-	Tags       map[string]string  `json:"tags"`
-	DataPoints map[string]float64 `json:"dps"`
+	Metric            string               `json:"metric"`
+	Tags              map[string]string    `json:"tags"`
+	DataPoints        map[string]float64   `json:"dps"`
+	Query             *OpenTsdbSubQuery    `json:"query,omitempty"`
+	Annotations       []OpenTsdbAnnotation `json:"annotations,omitempty"`
+	GlobalAnnotations []OpenTsdbAnnotation `json:"globalAnnotations,omitempty"`
+}
+
+// OpenTsdbSubQuery is the sub query of a series, returned when the request has showQuery set.
+type OpenTsdbSubQuery struct {
+	Index int `json:"index"`
+}
+
+// OpenTsdbAnnotation is an annotation of a series, or a global annotation. Its times are in seconds.
+type OpenTsdbAnnotation struct {
+	TSUID       string            `json:"tsuid"`
+	Description string            `json:"description"`
+	Notes       string            `json:"notes"`
+	StartTime   int64             `json:"startTime"`
+	EndTime     int64             `json:"endTime"`
+	Custom      map[string]string `json:"custom"`
+}
+
+// Filter is a tag filter of a sub query, supported since OpenTSDB 2.2.
+type Filter struct {
+	Type    string `json:"type"`
+	Tagk    string `json:"tagk"`
+	Filter  string `json:"filter"`
+	GroupBy bool   `json:"groupBy"`
 }
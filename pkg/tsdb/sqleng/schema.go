@@ -0,0 +1,221 @@
+package sqleng
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/patrickmn/go-cache"
+)
+
+// schemaCacheTTL is how long the results of the schema resources are cached by a data source instance.
+// The instance is recreated when the data source is updated, which also clears the cache.
+const schemaCacheTTL = 5 * time.Minute
+
+// maxSchemaCacheEntries is the maximum number of results of the schema resources cached by a data source instance,
+// since their keys are the databases and tables of the requests.
+const maxSchemaCacheEntries = 1000
+
+// SchemaDialect returns the queries of a SQL dialect used by the schema resources of the data sources,
+// which are meant for query editors to discover the databases, tables and columns.
+//
+// The queries are run with the connection of the data source, and should read views which only list the
+// objects the user of the data source has privileges on, such as the information_schema views, so that
+// the resources don't reveal objects that the data source can't query. An empty database is the default
+// database of the connection.
+type SchemaDialect interface {
+	DatabasesQuery() string
+	TablesQuery(database string) (string, []any)
+	ColumnsQuery(database, table string) (string, []any)
+	// FunctionsQuery returns the query listing the user defined functions.
+	FunctionsQuery(database string) (string, []any)
+	// BuiltinFunctions returns the names of the built-in functions suggested by query editors.
+	BuiltinFunctions() []string
+}
+
+// SchemaColumn is a column returned by the columns resource.
+type SchemaColumn struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// newSchemaCache returns the cache of the results of the schema resources, whose expired results are removed every
+// schemaCacheTTL.
+func newSchemaCache() *cache.Cache {
+	return cache.New(schemaCacheTTL, schemaCacheTTL)
+}
+
+// CallResource serves the schema resources of the data source: /databases, /tables, /columns and /functions.
+// The resources are only available for the data sources which have configured a SchemaDialect.
+func (e *DataSourceHandler) CallResource(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	return e.resourceHandler.CallResource(ctx, req, sender)
+}
+
+func (e *DataSourceHandler) registerSchemaRoutes() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/databases", e.handleDatabases)
+	mux.HandleFunc("/tables", e.handleTables)
+	mux.HandleFunc("/columns", e.handleColumns)
+	mux.HandleFunc("/functions", e.handleFunctions)
+	return mux
+}
+
+func (e *DataSourceHandler) handleDatabases(rw http.ResponseWriter, req *http.Request) {
+	if !e.checkSchemaRequest(rw, req) {
+		return
+	}
+
+	e.serveSchema(rw, req, "databases", func(ctx context.Context) (any, error) {
+		return e.queryStrings(ctx, e.schemaDialect.DatabasesQuery())
+	})
+}
+
+func (e *DataSourceHandler) handleTables(rw http.ResponseWriter, req *http.Request) {
+	if !e.checkSchemaRequest(rw, req) {
+		return
+	}
+
+	database := req.URL.Query().Get("database")
+	e.serveSchema(rw, req, "tables\x00"+database, func(ctx context.Context) (any, error) {
+		query, args := e.schemaDialect.TablesQuery(database)
+		return e.queryStrings(ctx, query, args...)
+	})
+}
+
+func (e *DataSourceHandler) handleColumns(rw http.ResponseWriter, req *http.Request) {
+	if !e.checkSchemaRequest(rw, req) {
+		return
+	}
+
+	database := req.URL.Query().Get("database")
+	table := req.URL.Query().Get("table")
+	if table == "" {
+		writeSchemaError(rw, http.StatusBadRequest, "table parameter is required")
+		return
+	}
+
+	e.serveSchema(rw, req, "columns\x00"+database+"\x00"+table, func(ctx context.Context) (any, error) {
+		query, args := e.schemaDialect.ColumnsQuery(database, table)
+		rows, err := e.db.QueryContext(ctx, query, args...)
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			if err := rows.Close(); err != nil {
+				e.log.Warn("Failed to close rows", "err", err)
+			}
+		}()
+
+		columns := make([]SchemaColumn, 0)
+		for rows.Next() {
+			var column SchemaColumn
+			if err := rows.Scan(&column.Name, &column.Type); err != nil {
+				return nil, err
+			}
+			columns = append(columns, column)
+		}
+		return columns, rows.Err()
+	})
+}
+
+func (e *DataSourceHandler) handleFunctions(rw http.ResponseWriter, req *http.Request) {
+	if !e.checkSchemaRequest(rw, req) {
+		return
+	}
+
+	database := req.URL.Query().Get("database")
+	e.serveSchema(rw, req, "functions\x00"+database, func(ctx context.Context) (any, error) {
+		query, args := e.schemaDialect.FunctionsQuery(database)
+		userFunctions, err := e.queryStrings(ctx, query, args...)
+		if err != nil {
+			return nil, err
+		}
+
+		seen := map[string]bool{}
+		functions := make([]string, 0)
+		for _, names := range [][]string{e.schemaDialect.BuiltinFunctions(), userFunctions} {
+			for _, name := range names {
+				if !seen[name] {
+					seen[name] = true
+					functions = append(functions, name)
+				}
+			}
+		}
+		sort.Strings(functions)
+		return functions, nil
+	})
+}
+
+func (e *DataSourceHandler) checkSchemaRequest(rw http.ResponseWriter, req *http.Request) bool {
+	if e.schemaDialect == nil {
+		writeSchemaError(rw, http.StatusNotFound, "schema resources are not supported by this data source")
+		return false
+	}
+	if req.Method != http.MethodGet {
+		writeSchemaError(rw, http.StatusMethodNotAllowed, "method not allowed")
+		return false
+	}
+	return true
+}
+
+// serveSchema writes the cached result of the resource, or the result of the query when it isn't cached.
+func (e *DataSourceHandler) serveSchema(rw http.ResponseWriter, req *http.Request, cacheKey string, query func(ctx context.Context) (any, error)) {
+	result, ok := e.schemaCache.Get(cacheKey)
+	if !ok {
+		var err error
+		result, err = query(req.Context())
+		if err != nil {
+			e.log.FromContext(req.Context()).Error("Schema query failed", "resource", req.URL.Path, "err", err)
+			writeSchemaError(rw, http.StatusInternalServerError, e.TransformQueryError(e.log, err).Error())
+			return
+		}
+		// results are not cached when the cache is full, until the expired ones are removed
+		if e.schemaCache.ItemCount() < maxSchemaCacheEntries {
+			e.schemaCache.SetDefault(cacheKey, result)
+		}
+	}
+
+	body, err := json.Marshal(result)
+	if err != nil {
+		writeSchemaError(rw, http.StatusInternalServerError, err.Error())
+		return
+	}
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	if _, err := rw.Write(body); err != nil {
+		e.log.Error("Failed to write response", "err", err)
+	}
+}
+
+// queryStrings returns the values of the first column of the rows returned by the query.
+func (e *DataSourceHandler) queryStrings(ctx context.Context, query string, args ...any) ([]string, error) {
+	rows, err := e.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			e.log.Warn("Failed to close rows", "err", err)
+		}
+	}()
+
+	values := make([]string, 0)
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, rows.Err()
+}
+
+func writeSchemaError(rw http.ResponseWriter, status int, message string) {
+	body, _ := json.Marshal(map[string]string{"message": message})
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(status)
+	_, _ = rw.Write(body)
+}
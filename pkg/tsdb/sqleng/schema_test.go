@@ -0,0 +1,141 @@
+package sqleng
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/patrickmn/go-cache"
+	"github.com/stretchr/testify/require"
+)
+
+// testSchemaDialect lists the tables of a SQLite database, which has a single database named main.
+type testSchemaDialect struct{}
+
+func (testSchemaDialect) DatabasesQuery() string {
+	return `SELECT 'main'`
+}
+
+func (testSchemaDialect) TablesQuery(_ string) (string, []any) {
+	return `SELECT name FROM sqlite_master WHERE type = 'table' ORDER BY name`, nil
+}
+
+func (testSchemaDialect) ColumnsQuery(_, table string) (string, []any) {
+	return `SELECT name, type FROM pragma_table_info(?)`, []any{table}
+}
+
+func (testSchemaDialect) FunctionsQuery(_ string) (string, []any) {
+	return `SELECT 'my_func' UNION ALL SELECT 'SUM'`, nil
+}
+
+func (testSchemaDialect) BuiltinFunctions() []string {
+	return []string{"SUM", "AVG"}
+}
+
+func TestSchemaResources(t *testing.T) {
+	newHandler := func(t *testing.T, dialect SchemaDialect) (*DataSourceHandler, *sql.DB) {
+		t.Helper()
+		db, err := sql.Open("sqlite3", ":memory:")
+		require.NoError(t, err)
+		db.SetMaxOpenConns(1)
+		t.Cleanup(func() { _ = db.Close() })
+
+		_, err = db.Exec(`CREATE TABLE metrics (time INTEGER, value REAL, host TEXT)`)
+		require.NoError(t, err)
+
+		handler, err := NewQueryDataHandler("error", db, DataPluginConfiguration{SchemaDialect: dialect}, nil, nil, log.New())
+		require.NoError(t, err)
+		return handler, db
+	}
+
+	get := func(t *testing.T, handler *DataSourceHandler, url string, result any) int {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		handler.registerSchemaRoutes().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, url, nil))
+		if result != nil && rec.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), result))
+		}
+		return rec.Code
+	}
+
+	t.Run("lists databases, tables and columns", func(t *testing.T) {
+		handler, _ := newHandler(t, testSchemaDialect{})
+
+		var databases []string
+		require.Equal(t, http.StatusOK, get(t, handler, "/databases", &databases))
+		require.Equal(t, []string{"main"}, databases)
+
+		var tables []string
+		require.Equal(t, http.StatusOK, get(t, handler, "/tables?database=main", &tables))
+		require.Equal(t, []string{"metrics"}, tables)
+
+		var columns []SchemaColumn
+		require.Equal(t, http.StatusOK, get(t, handler, "/columns?database=main&table=metrics", &columns))
+		require.Equal(t, []SchemaColumn{
+			{Name: "time", Type: "INTEGER"},
+			{Name: "value", Type: "REAL"},
+			{Name: "host", Type: "TEXT"},
+		}, columns)
+	})
+
+	t.Run("lists the built-in and user defined functions once", func(t *testing.T) {
+		handler, _ := newHandler(t, testSchemaDialect{})
+
+		var functions []string
+		require.Equal(t, http.StatusOK, get(t, handler, "/functions", &functions))
+		require.Equal(t, []string{"AVG", "SUM", "my_func"}, functions)
+	})
+
+	t.Run("caches the results until they expire", func(t *testing.T) {
+		handler, db := newHandler(t, testSchemaDialect{})
+		handler.schemaCache = cache.New(50*time.Millisecond, time.Minute)
+
+		var tables []string
+		require.Equal(t, http.StatusOK, get(t, handler, "/tables", &tables))
+		require.Equal(t, []string{"metrics"}, tables)
+
+		_, err := db.Exec(`CREATE TABLE logs (time INTEGER, line TEXT)`)
+		require.NoError(t, err)
+
+		require.Equal(t, http.StatusOK, get(t, handler, "/tables", &tables))
+		require.Equal(t, []string{"metrics"}, tables)
+
+		time.Sleep(100 * time.Millisecond)
+		require.Equal(t, http.StatusOK, get(t, handler, "/tables", &tables))
+		require.Equal(t, []string{"logs", "metrics"}, tables)
+	})
+
+	t.Run("does not cache the results when the cache is full", func(t *testing.T) {
+		handler, db := newHandler(t, testSchemaDialect{})
+		for i := 0; i < maxSchemaCacheEntries; i++ {
+			handler.schemaCache.SetDefault(fmt.Sprintf("columns\x00\x00table%d", i), []SchemaColumn{})
+		}
+
+		var tables []string
+		require.Equal(t, http.StatusOK, get(t, handler, "/tables", &tables))
+		require.Equal(t, []string{"metrics"}, tables)
+
+		_, err := db.Exec(`CREATE TABLE logs (time INTEGER, line TEXT)`)
+		require.NoError(t, err)
+
+		require.Equal(t, http.StatusOK, get(t, handler, "/tables", &tables))
+		require.Equal(t, []string{"logs", "metrics"}, tables)
+		require.Equal(t, maxSchemaCacheEntries, handler.schemaCache.ItemCount())
+	})
+
+	t.Run("requires the table of the columns", func(t *testing.T) {
+		handler, _ := newHandler(t, testSchemaDialect{})
+		require.Equal(t, http.StatusBadRequest, get(t, handler, "/columns", nil))
+	})
+
+	t.Run("is not found without a schema dialect", func(t *testing.T) {
+		handler, _ := newHandler(t, nil)
+		require.Equal(t, http.StatusNotFound, get(t, handler, "/databases", nil))
+	})
+}
@@ -20,6 +20,8 @@ import (
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
+	"github.com/patrickmn/go-cache"
 )
 
 // MetaKeyExecutedQueryString is the key where the executed query should get stored
@@ -38,6 +40,13 @@ type SqlQueryResultTransformer interface {
 	GetConverterList() []sqlutil.StringConverter
 }
 
+// SqlQueryFrameConverter can be implemented by a SqlQueryResultTransformer to convert the fields of
+// the frame built from the rows, before the time and value columns are processed. It's meant for
+// drivers which don't report the types of some columns, such as the results of expressions.
+type SqlQueryFrameConverter interface {
+	ConvertFrame(frame *data.Frame, columnTypes []*sql.ColumnType) error
+}
+
 type JsonData struct {
 	MaxOpenConns            int    `json:"maxOpenConns"`
 	MaxIdleConns            int    `json:"maxIdleConns"`
@@ -77,6 +86,8 @@ type DataPluginConfiguration struct {
 	TimeColumnNames   []string
 	MetricColumnTypes []string
 	RowLimit          int64
+	// SchemaDialect enables the schema resources of the data source when set.
+	SchemaDialect SchemaDialect
 }
 
 type DataSourceHandler struct {
@@ -89,6 +100,9 @@ type DataSourceHandler struct {
 	dsInfo                 DataSourceInfo
 	rowLimit               int64
 	userError              string
+	schemaDialect          SchemaDialect
+	schemaCache            *cache.Cache
+	resourceHandler        backend.CallResourceHandler
 }
 
 type QueryJson struct {
@@ -124,7 +138,10 @@ func NewQueryDataHandler(userFacingDefaultError string, db *sql.DB, config DataP
 		dsInfo:                 config.DSInfo,
 		rowLimit:               config.RowLimit,
 		userError:              userFacingDefaultError,
+		schemaDialect:          config.SchemaDialect,
+		schemaCache:            newSchemaCache(),
 	}
+	queryDataHandler.resourceHandler = httpadapter.New(queryDataHandler.registerSchemaRoutes())
 
 	if len(config.TimeColumnNames) > 0 {
 		queryDataHandler.timeColumnNames = config.TimeColumnNames
@@ -273,6 +290,13 @@ func (e *DataSourceHandler) executeQuery(query backend.DataQuery, wg *sync.WaitG
 		return
 	}
 
+	if frameConverter, ok := e.queryResultTransformer.(SqlQueryFrameConverter); ok {
+		if err := frameConverter.ConvertFrame(frame, qm.columnTypes); err != nil {
+			errAppendDebug("convert frame error", err, interpolatedQuery)
+			return
+		}
+	}
+
 	if frame.Meta == nil {
 		frame.Meta = &data.FrameMeta{}
 	}
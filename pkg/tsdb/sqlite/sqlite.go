@@ -0,0 +1,267 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana-plugin-sdk-go/data/sqlutil"
+	"github.com/mattn/go-sqlite3"
+
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tsdb/sqleng"
+)
+
+const (
+	// driverName is the driver of the connections of the data source, see restrictConnection
+	driverName = "sqlite3_datasource"
+	// busyTimeout is how long queries wait for the locks held by the processes writing to the database file
+	busyTimeout = 5 * time.Second
+)
+
+var (
+	errPathRequired   = errors.New("the path of the database file is required")
+	errPathNotAllowed = errors.New("the database file is not in the SQLite paths allowed by the server configuration")
+)
+
+// readOnlyPragmas are the pragmas which only read the schema of the database. They are used by the
+// query editor, through table-valued functions such as pragma_table_info.
+var readOnlyPragmas = []string{"table_info", "table_xinfo", "table_list", "index_list", "index_info", "index_xinfo", "foreign_key_list"}
+
+func init() {
+	sql.Register(driverName, &sqlite3.SQLiteDriver{ConnectHook: restrictConnection})
+}
+
+// restrictConnection stops the queries from opening other database files than the one of the data
+// source, and from changing the settings of the connection. It's enforced by SQLite when statements
+// are prepared, so it doesn't depend on how the queries are written.
+func restrictConnection(conn *sqlite3.SQLiteConn) error {
+	conn.SetLimit(sqlite3.SQLITE_LIMIT_ATTACHED, 0)
+	conn.RegisterAuthorizer(func(action int, arg1, _, _ string) int {
+		switch action {
+		case sqlite3.SQLITE_ATTACH, sqlite3.SQLITE_DETACH:
+			return sqlite3.SQLITE_DENY
+		case sqlite3.SQLITE_PRAGMA:
+			if !slices.Contains(readOnlyPragmas, strings.ToLower(arg1)) {
+				return sqlite3.SQLITE_DENY
+			}
+		}
+		return sqlite3.SQLITE_OK
+	})
+	return nil
+}
+
+type Service struct {
+	im     instancemgmt.InstanceManager
+	logger log.Logger
+}
+
+func ProvideService(cfg *setting.Cfg) *Service {
+	logger := backend.NewLoggerWith("logger", "tsdb.sqlite")
+	return &Service{
+		im:     datasource.NewInstanceManager(newInstanceSettings(cfg, logger)),
+		logger: logger,
+	}
+}
+
+type sqliteJsonData struct {
+	sqleng.JsonData
+	// Path is the absolute path of the database file.
+	Path string `json:"path"`
+}
+
+func newInstanceSettings(cfg *setting.Cfg, logger log.Logger) datasource.InstanceFactoryFunc {
+	return func(ctx context.Context, settings backend.DataSourceInstanceSettings) (instancemgmt.Instance, error) {
+		jsonData := sqliteJsonData{
+			JsonData: sqleng.JsonData{
+				MaxOpenConns:    cfg.SqlDatasourceMaxOpenConnsDefault,
+				MaxIdleConns:    cfg.SqlDatasourceMaxIdleConnsDefault,
+				ConnMaxLifetime: cfg.SqlDatasourceMaxConnLifetimeDefault,
+			},
+		}
+
+		err := json.Unmarshal(settings.JSONData, &jsonData)
+		if err != nil {
+			return nil, fmt.Errorf("error reading settings: %w", err)
+		}
+
+		path, err := validatePath(jsonData.Path, cfg.SqliteDatasourceAllowedPaths)
+		if err != nil {
+			return nil, err
+		}
+
+		dsInfo := sqleng.DataSourceInfo{
+			JsonData:                jsonData.JsonData,
+			URL:                     path,
+			Database:                path,
+			ID:                      settings.ID,
+			Updated:                 settings.Updated,
+			UID:                     settings.UID,
+			DecryptedSecureJSONData: settings.DecryptedSecureJSONData,
+		}
+
+		config := sqleng.DataPluginConfiguration{
+			DSInfo:            dsInfo,
+			TimeColumnNames:   []string{"time", "time_sec"},
+			MetricColumnTypes: []string{"TEXT", "VARCHAR", "CHAR", "NVARCHAR", "NCHAR", "CLOB"},
+			RowLimit:          cfg.DataProxyRowLimit,
+		}
+
+		db, err := sql.Open(driverName, generateConnectionString(path))
+		if err != nil {
+			return nil, err
+		}
+
+		db.SetMaxOpenConns(config.DSInfo.JsonData.MaxOpenConns)
+		db.SetMaxIdleConns(config.DSInfo.JsonData.MaxIdleConns)
+		db.SetConnMaxLifetime(time.Duration(config.DSInfo.JsonData.ConnMaxLifetime) * time.Second)
+
+		return sqleng.NewQueryDataHandler(cfg.UserFacingDefaultError, db, config, &sqliteQueryResultTransformer{},
+			newSqliteMacroEngine(), logger)
+	}
+}
+
+// validatePath returns the path of the database file with its symbolic links resolved, if it is
+// one of the allowed paths or inside one of them.
+func validatePath(path string, allowedPaths []string) (string, error) {
+	if path == "" {
+		return "", errPathRequired
+	}
+	if !filepath.IsAbs(path) {
+		return "", fmt.Errorf("the path of the database file must be absolute: %s", path)
+	}
+
+	resolved, err := filepath.EvalSymlinks(filepath.Clean(path))
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve the path of the database file: %w", err)
+	}
+
+	for _, allowedPath := range allowedPaths {
+		allowed, err := filepath.EvalSymlinks(filepath.Clean(allowedPath))
+		if err != nil {
+			// allowed paths which don't exist can't contain the database file
+			continue
+		}
+		rel, err := filepath.Rel(allowed, resolved)
+		if err != nil {
+			continue
+		}
+		if rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return resolved, nil
+		}
+	}
+
+	return "", errPathNotAllowed
+}
+
+// generateConnectionString returns a connection string which opens the database file read-only,
+// and stops statements from modifying it.
+func generateConnectionString(path string) string {
+	params := url.Values{}
+	params.Set("mode", "ro")
+	params.Set("_query_only", "true")
+	params.Set("_busy_timeout", strconv.FormatInt(busyTimeout.Milliseconds(), 10))
+	return "file:" + (&url.URL{Path: path}).EscapedPath() + "?" + params.Encode()
+}
+
+func (s *Service) getDataSourceHandler(ctx context.Context, pluginCtx backend.PluginContext) (*sqleng.DataSourceHandler, error) {
+	i, err := s.im.Get(ctx, pluginCtx)
+	if err != nil {
+		return nil, err
+	}
+	instance := i.(*sqleng.DataSourceHandler)
+	return instance, nil
+}
+
+func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	dsHandler, err := s.getDataSourceHandler(ctx, req.PluginContext)
+	if err != nil {
+		return nil, err
+	}
+	return dsHandler.QueryData(ctx, req)
+}
+
+// CheckHealth opens the database file and reads its schema
+func (s *Service) CheckHealth(ctx context.Context, req *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
+	dsHandler, err := s.getDataSourceHandler(ctx, req.PluginContext)
+	if err != nil {
+		return &backend.CheckHealthResult{Status: backend.HealthStatusError, Message: err.Error()}, nil
+	}
+
+	if err := dsHandler.Ping(); err != nil {
+		return &backend.CheckHealthResult{Status: backend.HealthStatusError, Message: dsHandler.TransformQueryError(s.logger, err).Error()}, nil
+	}
+	return &backend.CheckHealthResult{Status: backend.HealthStatusOk, Message: "Database Connection OK"}, nil
+}
+
+type sqliteQueryResultTransformer struct{}
+
+// TransformQueryError returns the errors of SQLite as they are, since they are about the
+// query and the local database file rather than a server.
+func (t *sqliteQueryResultTransformer) TransformQueryError(_ log.Logger, err error) error {
+	return err
+}
+
+// ConvertFrame converts the fields of the columns without a declared type, such as the results of
+// the macros and aggregate functions, to numbers when all their values are numbers. SQLite only
+// reports the declared types of table columns.
+func (t *sqliteQueryResultTransformer) ConvertFrame(frame *data.Frame, columnTypes []*sql.ColumnType) error {
+	for i, columnType := range columnTypes {
+		if i >= len(frame.Fields) || columnType.DatabaseTypeName() != "" {
+			continue
+		}
+
+		field := frame.Fields[i]
+		if field.Type() != data.FieldTypeNullableString {
+			continue
+		}
+
+		values := make([]*float64, field.Len())
+		numeric := true
+		for j := 0; j < field.Len() && numeric; j++ {
+			s, ok := field.ConcreteAt(j)
+			if !ok {
+				continue
+			}
+			v, err := strconv.ParseFloat(s.(string), 64)
+			if err != nil {
+				numeric = false
+				break
+			}
+			values[j] = &v
+		}
+		if !numeric {
+			continue
+		}
+
+		newField := data.NewField(field.Name, field.Labels, values)
+		newField.Config = field.Config
+		frame.Fields[i] = newField
+	}
+	return nil
+}
+
+func (t *sqliteQueryResultTransformer) GetConverterList() []sqlutil.StringConverter {
+	return []sqlutil.StringConverter{
+		{
+			Name:           "handle untyped columns",
+			InputScanKind:  reflect.Ptr,
+			InputTypeName:  "",
+			ConversionFunc: func(in *string) (*string, error) { return in, nil },
+		},
+	}
+}
@@ -0,0 +1,169 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/experimental"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/tsdb/sqleng"
+)
+
+var updateGoldenFiles = false
+
+// These tests create the SQLite database files they need, and run when the
+// env variable GRAFANA_TEST_DB = sqlite
+func TestIntegrationSQLiteSnapshots(t *testing.T) {
+	// the logic in this function is copied from mysql_snapshot_test.go
+	shouldRunTest := func() bool {
+		if testing.Short() {
+			return false
+		}
+
+		testDbName, present := os.LookupEnv("GRAFANA_TEST_DB")
+
+		if present && testDbName == "sqlite" {
+			return true
+		}
+
+		return false
+	}
+
+	if !shouldRunTest() {
+		t.Skip()
+	}
+
+	sqlQueryCommentRe := regexp.MustCompile(`^-- (.+)\n`)
+
+	readSqlFile := func(path string) (string, []string) {
+		// the file-path is not coming from the outside,
+		// it is hardcoded in this file.
+		//nolint:gosec
+		sqlBytes, err := os.ReadFile(path)
+		require.NoError(t, err)
+
+		sql := string(sqlBytes)
+
+		// first line of the file contains the sql query to run, commented out
+		match := sqlQueryCommentRe.FindStringSubmatch(sql)
+		require.Len(t, match, 2)
+
+		rawSQL := strings.TrimSpace(match[1])
+
+		// we split the queries by an "empty new line"
+		sqls := strings.Split(sql, "\n\n")
+
+		return rawSQL, sqls
+	}
+
+	makeQuery := func(rawSQL string, format string) backend.QueryDataRequest {
+		queryData := map[string]string{
+			"rawSql": rawSQL,
+			"format": format,
+		}
+
+		queryBytes, err := json.Marshal(queryData)
+		require.NoError(t, err)
+
+		return backend.QueryDataRequest{
+			Queries: []backend.DataQuery{
+				{
+					JSON:  queryBytes,
+					RefID: "A",
+					TimeRange: backend.TimeRange{
+						From: time.Date(2023, 12, 24, 14, 15, 22, 123456, time.UTC),
+						To:   time.Date(2023, 12, 24, 14, 45, 13, 876543, time.UTC),
+					},
+				},
+			},
+		}
+	}
+
+	tt := []struct {
+		name   string
+		format string
+	}{
+		{format: "time_series", name: "simple"},
+		{format: "time_series", name: "time_group"},
+		{format: "table", name: "simple"},
+	}
+
+	for _, test := range tt {
+		require.True(t, test.format == "table" || test.format == "time_series")
+		t.Run(test.name, func(t *testing.T) {
+			origInterpolate := sqleng.Interpolate
+			t.Cleanup(func() {
+				sqleng.Interpolate = origInterpolate
+			})
+
+			sqleng.Interpolate = func(query backend.DataQuery, timeRange backend.TimeRange, timeInterval string, sql string) string {
+				return sql
+			}
+
+			path := filepath.Join(t.TempDir(), "grafana_ds_tests.db")
+
+			sqlFilePath := filepath.Join("testdata", test.format, test.name+".sql")
+			goldenFileName := filepath.Join(test.format, test.name+".golden")
+
+			rawSQL, sqls := readSqlFile(sqlFilePath)
+
+			// the fixtures are written with a separate connection, since the one of the data source is read-only
+			rw, err := sql.Open(driverName, path)
+			require.NoError(t, err)
+			for _, sql := range sqls {
+				_, err = rw.Exec(sql)
+				require.NoError(t, err)
+			}
+			require.NoError(t, rw.Close())
+
+			dsInfo := sqleng.DataSourceInfo{
+				JsonData: sqleng.JsonData{
+					MaxOpenConns:    0,
+					MaxIdleConns:    2,
+					ConnMaxLifetime: 14400,
+				},
+			}
+
+			db, err := sql.Open(driverName, generateConnectionString(path))
+			require.NoError(t, err)
+
+			db.SetMaxOpenConns(dsInfo.JsonData.MaxOpenConns)
+			db.SetMaxIdleConns(dsInfo.JsonData.MaxIdleConns)
+			db.SetConnMaxLifetime(time.Duration(dsInfo.JsonData.ConnMaxLifetime))
+
+			logger := backend.NewLoggerWith("logger", "sqlite.test")
+
+			config := sqleng.DataPluginConfiguration{
+				DSInfo:            dsInfo,
+				TimeColumnNames:   []string{"time", "time_sec"},
+				MetricColumnTypes: []string{"TEXT", "VARCHAR", "CHAR", "NVARCHAR", "NCHAR", "CLOB"},
+				RowLimit:          1000000,
+			}
+
+			handler, err := sqleng.NewQueryDataHandler("", db, config, &sqliteQueryResultTransformer{}, newSqliteMacroEngine(), logger)
+			require.NoError(t, err)
+
+			t.Cleanup(func() {
+				require.NoError(t, db.Close())
+			})
+
+			query := makeQuery(rawSQL, test.format)
+
+			result, err := handler.QueryData(context.Background(), &query)
+			require.Len(t, result.Responses, 1)
+			response, found := result.Responses["A"]
+			require.True(t, found)
+			require.NoError(t, err)
+			experimental.CheckGoldenJSONResponse(t, "testdata", goldenFileName, &response, updateGoldenFiles)
+		})
+	}
+}
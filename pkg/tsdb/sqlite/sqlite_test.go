@@ -0,0 +1,141 @@
+package sqlite
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidatePath(t *testing.T) {
+	dir := t.TempDir()
+	allowedDir := filepath.Join(dir, "allowed")
+	require.NoError(t, os.Mkdir(allowedDir, 0750))
+	allowedFile := filepath.Join(allowedDir, "metrics.db")
+	require.NoError(t, os.WriteFile(allowedFile, nil, 0600))
+	otherFile := filepath.Join(dir, "other.db")
+	require.NoError(t, os.WriteFile(otherFile, nil, 0600))
+	link := filepath.Join(allowedDir, "link.db")
+	require.NoError(t, os.Symlink(otherFile, link))
+
+	t.Run("files in an allowed directory are allowed", func(t *testing.T) {
+		path, err := validatePath(allowedFile, []string{allowedDir})
+		require.NoError(t, err)
+		require.Equal(t, allowedFile, path)
+	})
+
+	t.Run("allowed files are allowed", func(t *testing.T) {
+		path, err := validatePath(otherFile, []string{allowedFile, otherFile})
+		require.NoError(t, err)
+		require.Equal(t, otherFile, path)
+	})
+
+	t.Run("files outside of the allowed paths aren't allowed", func(t *testing.T) {
+		_, err := validatePath(otherFile, []string{allowedDir})
+		require.ErrorIs(t, err, errPathNotAllowed)
+
+		_, err = validatePath(filepath.Join(allowedDir, "..", "other.db"), []string{allowedDir})
+		require.ErrorIs(t, err, errPathNotAllowed)
+	})
+
+	t.Run("symbolic links are resolved", func(t *testing.T) {
+		_, err := validatePath(link, []string{allowedDir})
+		require.ErrorIs(t, err, errPathNotAllowed)
+	})
+
+	t.Run("no files are allowed without allowed paths", func(t *testing.T) {
+		_, err := validatePath(allowedFile, nil)
+		require.ErrorIs(t, err, errPathNotAllowed)
+	})
+
+	t.Run("the path must be absolute", func(t *testing.T) {
+		_, err := validatePath("", []string{allowedDir})
+		require.ErrorIs(t, err, errPathRequired)
+
+		_, err = validatePath("metrics.db", []string{allowedDir})
+		require.Error(t, err)
+	})
+}
+
+func TestGenerateConnectionString(t *testing.T) {
+	require.Equal(t, "file:/var/lib/edge/metrics.db?_busy_timeout=5000&_query_only=true&mode=ro",
+		generateConnectionString("/var/lib/edge/metrics.db"))
+	require.Equal(t, "file:/var/lib/edge%20data/metrics%3F.db?_busy_timeout=5000&_query_only=true&mode=ro",
+		generateConnectionString("/var/lib/edge data/metrics?.db"))
+}
+
+func TestIntegrationReadOnlyConnection(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	path := filepath.Join(t.TempDir(), "metrics.db")
+	rw, err := sql.Open(driverName, path)
+	require.NoError(t, err)
+	_, err = rw.Exec("CREATE TABLE tbl (time DATETIME, value REAL)")
+	require.NoError(t, err)
+	_, err = rw.Exec("INSERT INTO tbl (time, value) VALUES ('2023-12-24 14:30:03', 10)")
+	require.NoError(t, err)
+	require.NoError(t, rw.Close())
+
+	db, err := sql.Open(driverName, generateConnectionString(path))
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, db.Close()) })
+
+	var value float64
+	require.NoError(t, db.QueryRow("SELECT value FROM tbl").Scan(&value))
+	require.Equal(t, float64(10), value)
+
+	_, err = db.Exec("INSERT INTO tbl (time, value) VALUES ('2023-12-24 14:31:03', 20)")
+	require.Error(t, err)
+	_, err = db.Exec("DROP TABLE tbl")
+	require.Error(t, err)
+
+	other := filepath.Join(t.TempDir(), "other.db")
+	for _, query := range []string{
+		"ATTACH DATABASE '" + other + "' AS other",
+		"/**/ATTACH/**/'" + other + "' AS other",
+		"SELECT 1; ATTACH'" + other + "'AS other",
+		"PRAGMA query_only = false",
+		"SELECT 1;\npragma writable_schema = on",
+	} {
+		_, err = db.Exec(query)
+		require.Error(t, err, query)
+	}
+
+	var column string
+	require.NoError(t, db.QueryRow("SELECT name FROM pragma_table_info('tbl') WHERE type = 'REAL'").Scan(&column))
+	require.Equal(t, "value", column)
+}
+
+func TestConvertFrame(t *testing.T) {
+	str := func(s string) *string { return &s }
+	num := func(f float64) *float64 { return &f }
+
+	frame := data.NewFrame("",
+		data.NewField("time", nil, []*string{str("1703428203"), nil, str("1703428263")}),
+		data.NewField("name", nil, []*string{str("a"), str("1"), nil}),
+		data.NewField("declared", nil, []*string{str("1"), str("2"), str("3")}),
+	)
+
+	db, err := sql.Open(driverName, ":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, db.Close()) })
+	_, err = db.Exec("CREATE TABLE tbl (declared TEXT)")
+	require.NoError(t, err)
+	rows, err := db.Query("SELECT 1 AS time, 'a' AS name, declared FROM tbl")
+	require.NoError(t, err)
+	columnTypes, err := rows.ColumnTypes()
+	require.NoError(t, err)
+	require.NoError(t, rows.Close())
+
+	transformer := &sqliteQueryResultTransformer{}
+	require.NoError(t, transformer.ConvertFrame(frame, columnTypes))
+
+	require.Equal(t, data.NewField("time", nil, []*float64{num(1703428203), nil, num(1703428263)}), frame.Fields[0])
+	require.Equal(t, data.FieldTypeNullableString, frame.Fields[1].Type())
+	require.Equal(t, data.FieldTypeNullableString, frame.Fields[2].Type())
+}
@@ -0,0 +1,18 @@
+-- SELECT * FROM tbl
+CREATE TABLE tbl (
+    time DATETIME NOT NULL,
+    v REAL,
+    c TEXT
+);
+
+INSERT INTO tbl (time, v, c) VALUES
+('2023-12-24 14:30:03', 10, 'a'),
+('2023-12-24 14:30:03', 110, 'b'),
+('2023-12-24 14:31:03', 20, 'a'),
+('2023-12-24 14:31:03', 120, 'b'),
+('2023-12-24 14:32:03', 30, 'a'),
+('2023-12-24 14:32:03', 130, 'b'),
+('2023-12-24 14:33:03', 40, 'a'),
+('2023-12-24 14:33:03', 140, 'b'),
+('2023-12-24 14:34:03', 50, 'a'),
+('2023-12-24 14:34:03', 150, 'b');
\ No newline at end of file
@@ -0,0 +1,102 @@
+package tempo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+// apiError is the error of a request to the Tempo API which didn't succeed.
+type apiError struct {
+	StatusCode int
+	Status     string
+	Body       string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("Tempo request failed. Status: %s Body: %s", e.Status, e.Body)
+}
+
+// errorResponse returns the response of a query which failed with the error, with the source of the error.
+func errorResponse(err error) *backend.DataResponse {
+	res := &backend.DataResponse{Error: err}
+	var apiErr *apiError
+	if errors.As(err, &apiErr) {
+		res.ErrorSource = backend.ErrorSourceFromHTTPStatus(apiErr.StatusCode)
+	}
+	return res
+}
+
+// getJSON sends a GET request to an endpoint of the Tempo API and decodes its JSON response.
+func (s *Service) getJSON(ctx context.Context, dsInfo *Datasource, endpoint string, params url.Values, result any) error {
+	ctxLogger := s.logger.FromContext(ctx)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s%s?%s", dsInfo.URL, endpoint, params.Encode()), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	body, err := s.do(ctx, dsInfo, req)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, result); err != nil {
+		ctxLogger.Error("Failed to decode Tempo response", "error", err, "endpoint", endpoint, "function", logEntrypoint())
+		return fmt.Errorf("failed to decode Tempo response: %w", err)
+	}
+	return nil
+}
+
+// fetchTrace returns the trace with the ID, looked up in the time range when it isn't zero.
+func (s *Service) fetchTrace(ctx context.Context, dsInfo *Datasource, traceID string, start int64, end int64) (ptrace.Traces, error) {
+	req, err := s.createRequest(ctx, dsInfo, traceID, start, end)
+	if err != nil {
+		return ptrace.Traces{}, err
+	}
+
+	body, err := s.do(ctx, dsInfo, req)
+	if err != nil {
+		return ptrace.Traces{}, err
+	}
+
+	pbUnmarshaler := ptrace.ProtoUnmarshaler{}
+	otTrace, err := pbUnmarshaler.UnmarshalTraces(body)
+	if err != nil {
+		return ptrace.Traces{}, fmt.Errorf("failed to convert tempo response to Otlp: %w", err)
+	}
+	return otTrace, nil
+}
+
+func (s *Service) do(ctx context.Context, dsInfo *Datasource, req *http.Request) ([]byte, error) {
+	ctxLogger := s.logger.FromContext(ctx)
+
+	resp, err := dsInfo.HTTPClient.Do(req)
+	if err != nil {
+		ctxLogger.Error("Failed to send request to Tempo", "error", err, "function", logEntrypoint())
+		return nil, fmt.Errorf("failed get to tempo: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			ctxLogger.Error("Failed to close response body", "error", err, "function", logEntrypoint())
+		}
+	}()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		ctxLogger.Error("Failed to read response body", "error", err, "function", logEntrypoint())
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &apiError{StatusCode: resp.StatusCode, Status: resp.Status, Body: string(body)}
+	}
+	return body, nil
+}
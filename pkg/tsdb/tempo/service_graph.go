@@ -0,0 +1,298 @@
+package tempo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+
+	"github.com/grafana/dskit/concurrency"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/tracing"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/grafana/grafana/pkg/tsdb/tempo/kinds/dataquery"
+)
+
+const (
+	// defaultServiceGraphTraceLimit is the number of traces the service graph is built from when the query has no limit.
+	defaultServiceGraphTraceLimit = 20
+	// serviceGraphConcurrency is the maximum number of traces fetched at the same time.
+	serviceGraphConcurrency = 5
+	// unknownServiceName is the name of the service of the spans without a service.name resource attribute.
+	unknownServiceName = "unknown"
+)
+
+// searchResponse is the response of the Tempo search API, of which only the trace IDs are used.
+type searchResponse struct {
+	Traces []struct {
+		TraceID string `json:"traceID"`
+	} `json:"traces"`
+}
+
+// queryTraceServiceGraph searches the traces matching the TraceQL query, and returns the node graph of the calls
+// between the services of their spans. It doesn't need the span metrics to be generated by Tempo, but the rates
+// and the error ratios are only computed from the traces found by the search.
+func (s *Service) queryTraceServiceGraph(ctx context.Context, pCtx backend.PluginContext, query backend.DataQuery) (*backend.DataResponse, error) {
+	ctxLogger := s.logger.FromContext(ctx)
+
+	ctx, span := tracing.DefaultTracer().Start(ctx, "datasource.tempo.queryTraceServiceGraph", trace.WithAttributes(
+		attribute.String("queryType", query.QueryType),
+	))
+	defer span.End()
+
+	model := &dataquery.TempoQuery{}
+	if err := json.Unmarshal(query.JSON, model); err != nil {
+		ctxLogger.Error("Failed to unmarshall Tempo query model", "error", err, "function", logEntrypoint())
+		return &backend.DataResponse{}, err
+	}
+
+	traceQuery := "{}"
+	if model.Query != nil && *model.Query != "" {
+		traceQuery = *model.Query
+	}
+	limit := int64(defaultServiceGraphTraceLimit)
+	if model.Limit != nil && *model.Limit > 0 {
+		limit = *model.Limit
+	}
+
+	dsInfo, err := s.getDSInfo(ctx, pCtx)
+	if err != nil {
+		ctxLogger.Error("Failed to get datasource information", "error", err, "function", logEntrypoint())
+		return nil, err
+	}
+
+	start, end := query.TimeRange.From.Unix(), query.TimeRange.To.Unix()
+	params := url.Values{}
+	params.Set("q", traceQuery)
+	params.Set("start", strconv.FormatInt(start, 10))
+	params.Set("end", strconv.FormatInt(end, 10))
+	params.Set("limit", strconv.FormatInt(limit, 10))
+
+	var searchRes searchResponse
+	if err := s.getJSON(ctx, dsInfo, "/api/search", params, &searchRes); err != nil {
+		ctxLogger.Error("Failed to search traces", "error", err, "function", logEntrypoint())
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return errorResponse(err), nil
+	}
+
+	traces := make([]ptrace.Traces, len(searchRes.Traces))
+	errs := make([]error, len(searchRes.Traces))
+	err = concurrency.ForEachJob(ctx, len(searchRes.Traces), serviceGraphConcurrency, func(ctx context.Context, idx int) error {
+		traces[idx], errs[idx] = s.fetchTrace(ctx, dsInfo, searchRes.Traces[idx].TraceID, start, end)
+		return nil
+	})
+	if err != nil {
+		return &backend.DataResponse{}, err
+	}
+
+	graph := newServiceGraph()
+	var failed int
+	var lastErr error
+	for i, t := range traces {
+		if errs[i] != nil {
+			ctxLogger.Warn("Failed to get trace of the service graph", "error", errs[i], "traceID", searchRes.Traces[i].TraceID, "function", logEntrypoint())
+			failed++
+			lastErr = errs[i]
+			continue
+		}
+		graph.addTrace(t)
+	}
+	if failed > 0 && failed == len(traces) {
+		span.RecordError(lastErr)
+		span.SetStatus(codes.Error, lastErr.Error())
+		return errorResponse(lastErr), nil
+	}
+
+	notices := []data.Notice{{
+		Severity: data.NoticeSeverityInfo,
+		Text:     fmt.Sprintf("The service graph was built from %d traces.", len(traces)-failed),
+	}}
+	if failed > 0 {
+		notices = append(notices, data.Notice{
+			Severity: data.NoticeSeverityWarning,
+			Text:     fmt.Sprintf("%d of %d traces couldn't be retrieved: %s", failed, len(traces), lastErr),
+		})
+	}
+
+	nodes, edges := graph.frames(query.RefID, query.TimeRange.Duration().Seconds())
+	nodes.Meta.Notices = notices
+	return &backend.DataResponse{Frames: data.Frames{nodes, edges}}, nil
+}
+
+// serviceGraph counts the requests of the services, and of the calls between them, in the spans of traces.
+type serviceGraph struct {
+	nodes map[string]*serviceGraphStats
+	edges map[serviceGraphEdge]*serviceGraphStats
+}
+
+type serviceGraphEdge struct {
+	source string
+	target string
+}
+
+type serviceGraphStats struct {
+	total  int
+	failed int
+}
+
+func (s *serviceGraphStats) add(span ptrace.Span) {
+	s.total++
+	if span.Status().Code() == ptrace.StatusCodeError {
+		s.failed++
+	}
+}
+
+func newServiceGraph() *serviceGraph {
+	return &serviceGraph{
+		nodes: map[string]*serviceGraphStats{},
+		edges: map[serviceGraphEdge]*serviceGraphStats{},
+	}
+}
+
+// addTrace counts the requests of the trace. A request of a service is a span which has no parent, or whose parent
+// is a span of another service, in which case it is also a call from the service of the parent.
+func (g *serviceGraph) addTrace(t ptrace.Traces) {
+	type serviceSpan struct {
+		service string
+		span    ptrace.Span
+	}
+	var spans []serviceSpan
+	services := map[pcommon.SpanID]string{}
+
+	resourceSpans := t.ResourceSpans()
+	for i := 0; i < resourceSpans.Len(); i++ {
+		rs := resourceSpans.At(i)
+		service := unknownServiceName
+		if name, ok := rs.Resource().Attributes().Get("service.name"); ok && name.AsString() != "" {
+			service = name.AsString()
+		}
+
+		scopeSpans := rs.ScopeSpans()
+		for j := 0; j < scopeSpans.Len(); j++ {
+			ss := scopeSpans.At(j).Spans()
+			for k := 0; k < ss.Len(); k++ {
+				span := ss.At(k)
+				spans = append(spans, serviceSpan{service: service, span: span})
+				services[span.SpanID()] = service
+			}
+		}
+	}
+
+	for _, s := range spans {
+		parentService, hasParent := services[s.span.ParentSpanID()]
+		if hasParent && parentService == s.service {
+			continue
+		}
+		g.node(s.service).add(s.span)
+		if hasParent {
+			g.node(parentService)
+			edge := serviceGraphEdge{source: parentService, target: s.service}
+			if g.edges[edge] == nil {
+				g.edges[edge] = &serviceGraphStats{}
+			}
+			g.edges[edge].add(s.span)
+		}
+	}
+}
+
+func (g *serviceGraph) node(service string) *serviceGraphStats {
+	if g.nodes[service] == nil {
+		g.nodes[service] = &serviceGraphStats{}
+	}
+	return g.nodes[service]
+}
+
+// frames returns the nodes and the edges frames of the node graph, with the request rates over the duration in seconds.
+func (g *serviceGraph) frames(refID string, seconds float64) (*data.Frame, *data.Frame) {
+	rate := func(count int) float64 {
+		if seconds <= 0 {
+			return 0
+		}
+		return float64(count) / seconds
+	}
+	errorRatio := func(stats *serviceGraphStats) float64 {
+		if stats.total == 0 {
+			return 0
+		}
+		return float64(stats.failed) / float64(stats.total)
+	}
+
+	services := make([]string, 0, len(g.nodes))
+	for service := range g.nodes {
+		services = append(services, service)
+	}
+	sort.Strings(services)
+
+	nodeIDs := make([]string, 0, len(services))
+	nodeRates := make([]float64, 0, len(services))
+	nodeErrors := make([]float64, 0, len(services))
+	nodeSuccess := make([]float64, 0, len(services))
+	for _, service := range services {
+		stats := g.nodes[service]
+		nodeIDs = append(nodeIDs, service)
+		nodeRates = append(nodeRates, rate(stats.total))
+		nodeErrors = append(nodeErrors, errorRatio(stats))
+		if stats.total == 0 {
+			nodeSuccess = append(nodeSuccess, 0)
+		} else {
+			nodeSuccess = append(nodeSuccess, 1-errorRatio(stats))
+		}
+	}
+
+	nodes := data.NewFrame("Nodes",
+		data.NewField("id", nil, nodeIDs),
+		data.NewField("title", nil, nodeIDs),
+		data.NewField("mainstat", nil, nodeRates).SetConfig(&data.FieldConfig{DisplayName: "Requests per second", Unit: "reqps"}),
+		data.NewField("secondarystat", nil, nodeErrors).SetConfig(&data.FieldConfig{DisplayName: "Error ratio", Unit: "percentunit"}),
+		data.NewField("arc__success", nil, nodeSuccess).SetConfig(&data.FieldConfig{DisplayName: "Success", Color: map[string]any{"mode": "fixed", "fixedColor": "green"}}),
+		data.NewField("arc__failed", nil, nodeErrors).SetConfig(&data.FieldConfig{DisplayName: "Errors", Color: map[string]any{"mode": "fixed", "fixedColor": "red"}}),
+	)
+	nodes.RefID = refID
+	nodes.Meta = &data.FrameMeta{PreferredVisualization: data.VisTypeNodeGraph}
+
+	edgeKeys := make([]serviceGraphEdge, 0, len(g.edges))
+	for edge := range g.edges {
+		edgeKeys = append(edgeKeys, edge)
+	}
+	sort.Slice(edgeKeys, func(i, j int) bool {
+		if edgeKeys[i].source != edgeKeys[j].source {
+			return edgeKeys[i].source < edgeKeys[j].source
+		}
+		return edgeKeys[i].target < edgeKeys[j].target
+	})
+
+	edgeIDs := make([]string, 0, len(edgeKeys))
+	sources := make([]string, 0, len(edgeKeys))
+	targets := make([]string, 0, len(edgeKeys))
+	edgeRates := make([]float64, 0, len(edgeKeys))
+	edgeErrors := make([]float64, 0, len(edgeKeys))
+	for _, edge := range edgeKeys {
+		stats := g.edges[edge]
+		edgeIDs = append(edgeIDs, edge.source+"_"+edge.target)
+		sources = append(sources, edge.source)
+		targets = append(targets, edge.target)
+		edgeRates = append(edgeRates, rate(stats.total))
+		edgeErrors = append(edgeErrors, errorRatio(stats))
+	}
+
+	edges := data.NewFrame("Edges",
+		data.NewField("id", nil, edgeIDs),
+		data.NewField("source", nil, sources),
+		data.NewField("target", nil, targets),
+		data.NewField("mainstat", nil, edgeRates).SetConfig(&data.FieldConfig{DisplayName: "Requests per second", Unit: "reqps"}),
+		data.NewField("secondarystat", nil, edgeErrors).SetConfig(&data.FieldConfig{DisplayName: "Error ratio", Unit: "percentunit"}),
+	)
+	edges.RefID = refID
+	edges.Meta = &data.FrameMeta{PreferredVisualization: data.VisTypeNodeGraph}
+
+	return nodes, edges
+}
@@ -0,0 +1,115 @@
+package tempo
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+type testSpan struct {
+	service string
+	id      byte
+	parent  byte
+	failed  bool
+}
+
+func makeTestTrace(spans ...testSpan) ptrace.Traces {
+	traces := ptrace.NewTraces()
+	for _, s := range spans {
+		rs := traces.ResourceSpans().AppendEmpty()
+		if s.service != "" {
+			rs.Resource().Attributes().PutStr("service.name", s.service)
+		}
+		span := rs.ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+		span.SetSpanID(pcommon.SpanID{s.id})
+		if s.parent != 0 {
+			span.SetParentSpanID(pcommon.SpanID{s.parent})
+		}
+		if s.failed {
+			span.Status().SetCode(ptrace.StatusCodeError)
+		}
+	}
+	return traces
+}
+
+func TestServiceGraph(t *testing.T) {
+	graph := newServiceGraph()
+	graph.addTrace(makeTestTrace(
+		testSpan{service: "frontend", id: 1},
+		testSpan{service: "frontend", id: 2, parent: 1},
+		testSpan{service: "api", id: 3, parent: 2, failed: true},
+		testSpan{service: "api", id: 4, parent: 3},
+		testSpan{id: 5, parent: 4},
+	))
+	graph.addTrace(makeTestTrace(
+		testSpan{service: "frontend", id: 1},
+		testSpan{service: "api", id: 2, parent: 1},
+	))
+
+	nodes, edges := graph.frames("A", 10)
+
+	assert.Equal(t, data.VisTypeNodeGraph, nodes.Meta.PreferredVisualization)
+	require.Equal(t, 3, nodes.Rows())
+	assert.Equal(t, []any{"api", 0.2, 0.5, 0.5, 0.5}, []any{nodes.Fields[0].At(0), nodes.Fields[2].At(0), nodes.Fields[3].At(0), nodes.Fields[4].At(0), nodes.Fields[5].At(0)})
+	assert.Equal(t, []any{"frontend", 0.2, 0.0}, []any{nodes.Fields[0].At(1), nodes.Fields[2].At(1), nodes.Fields[3].At(1)})
+	assert.Equal(t, []any{"unknown", 0.1, 0.0}, []any{nodes.Fields[0].At(2), nodes.Fields[2].At(2), nodes.Fields[3].At(2)})
+
+	require.Equal(t, 2, edges.Rows())
+	assert.Equal(t, []any{"api_unknown", "api", "unknown", 0.1, 0.0}, []any{edges.Fields[0].At(0), edges.Fields[1].At(0), edges.Fields[2].At(0), edges.Fields[3].At(0), edges.Fields[4].At(0)})
+	assert.Equal(t, []any{"frontend_api", "frontend", "api", 0.2, 0.5}, []any{edges.Fields[0].At(1), edges.Fields[1].At(1), edges.Fields[2].At(1), edges.Fields[3].At(1), edges.Fields[4].At(1)})
+}
+
+func TestQueryTraceServiceGraph(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	query := backend.DataQuery{
+		RefID:     "A",
+		QueryType: "traceServiceGraph",
+		TimeRange: backend.TimeRange{From: from, To: from.Add(10 * time.Second)},
+		JSON:      []byte(`{"query": "{ resource.service.name = \"frontend\" }", "limit": 3}`),
+	}
+
+	body, err := (&ptrace.ProtoMarshaler{}).MarshalTraces(makeTestTrace(
+		testSpan{service: "frontend", id: 1},
+		testSpan{service: "api", id: 2, parent: 1},
+	))
+	require.NoError(t, err)
+
+	service := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/search" {
+			assert.Equal(t, `{ resource.service.name = "frontend" }`, r.URL.Query().Get("q"))
+			assert.Equal(t, "3", r.URL.Query().Get("limit"))
+			_, _ = w.Write([]byte(`{"traces": [{"traceID": "1"}, {"traceID": "2"}, {"traceID": "missing"}]}`))
+			return
+		}
+		if strings.HasSuffix(r.URL.Path, "/missing") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(body)
+	})
+
+	res, err := service.query(context.Background(), backend.PluginContext{}, query)
+	require.NoError(t, err)
+	require.NoError(t, res.Error)
+	require.Len(t, res.Frames, 2)
+
+	nodes, edges := res.Frames[0], res.Frames[1]
+	assert.Equal(t, "A", nodes.RefID)
+	require.Equal(t, 2, nodes.Rows())
+	assert.Equal(t, 0.2, nodes.Fields[2].At(0))
+	require.Equal(t, 1, edges.Rows())
+	assert.Equal(t, 0.2, edges.Fields[3].At(0))
+
+	require.Len(t, nodes.Meta.Notices, 2)
+	assert.Equal(t, "The service graph was built from 2 traces.", nodes.Meta.Notices[0].Text)
+	assert.Contains(t, nodes.Meta.Notices[1].Text, "1 of 3 traces couldn't be retrieved")
+}
@@ -3,15 +3,14 @@ package tempo
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"net/http"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/tracing"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/tsdb/tempo/kinds/dataquery"
-	"go.opentelemetry.io/collector/pdata/ptrace"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
@@ -48,50 +47,18 @@ func (s *Service) getTrace(ctx context.Context, pCtx backend.PluginContext, quer
 		return result, err
 	}
 
-	request, err := s.createRequest(ctx, dsInfo, *model.Query, query.TimeRange.From.Unix(), query.TimeRange.To.Unix())
+	otTrace, err := s.fetchTrace(ctx, dsInfo, *model.Query, query.TimeRange.From.Unix(), query.TimeRange.To.Unix())
 	if err != nil {
-		ctxLogger.Error("Failed to create request", "error", err, "function", logEntrypoint())
-		span.RecordError(err)
-		span.SetStatus(codes.Error, err.Error())
-		return result, err
-	}
-
-	resp, err := dsInfo.HTTPClient.Do(request)
-	if err != nil {
-		ctxLogger.Error("Failed to send request to Tempo", "error", err, "function", logEntrypoint())
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
-		return result, fmt.Errorf("failed get to tempo: %w", err)
-	}
-
-	defer func() {
-		if err := resp.Body.Close(); err != nil {
-			ctxLogger.Error("Failed to close response body", "error", err, "function", logEntrypoint())
+		var apiErr *apiError
+		if errors.As(err, &apiErr) {
+			ctxLogger.Error("Failed to get trace", "error", err, "function", logEntrypoint())
+			result.Error = fmt.Errorf("failed to get trace with id: %s Status: %s Body: %s", *model.Query, apiErr.Status, apiErr.Body)
+			result.ErrorSource = backend.ErrorSourceFromHTTPStatus(apiErr.StatusCode)
+			return result, nil
 		}
-	}()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		ctxLogger.Error("Failed to read response body", "error", err, "function", logEntrypoint())
-		return &backend.DataResponse{}, err
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		ctxLogger.Error("Failed to get trace", "error", err, "function", logEntrypoint())
-		result.Error = fmt.Errorf("failed to get trace with id: %s Status: %s Body: %s", *model.Query, resp.Status, string(body))
-		span.RecordError(result.Error)
-		span.SetStatus(codes.Error, result.Error.Error())
-		return result, nil
-	}
-
-	pbUnmarshaler := ptrace.ProtoUnmarshaler{}
-	otTrace, err := pbUnmarshaler.UnmarshalTraces(body)
-
-	if err != nil {
-		ctxLogger.Error("Failed to convert tempo response to Otlp", "error", err, "function", logEntrypoint())
-		span.RecordError(err)
-		span.SetStatus(codes.Error, err.Error())
-		return &backend.DataResponse{}, fmt.Errorf("failed to convert tempo response to Otlp: %w", err)
+		return result, err
 	}
 
 	frame, err := TraceToFrame(otTrace)
@@ -0,0 +1,221 @@
+package tempo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/tracing"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/grafana/grafana/pkg/tsdb/tempo/kinds/dataquery"
+)
+
+// defaultMetricsMaxDataPoints is the maximum number of data points of the series when the query doesn't have one.
+const defaultMetricsMaxDataPoints = 500
+
+// queryRangeResponse is the response of the TraceQL metrics query range API, encoded by protobuf as JSON.
+type queryRangeResponse struct {
+	Series []queryRangeSeries `json:"series"`
+}
+
+type queryRangeSeries struct {
+	Labels []struct {
+		Key   string        `json:"key"`
+		Value queryAnyValue `json:"value"`
+	} `json:"labels"`
+	Samples []struct {
+		// protobuf encodes the 64 bits integers as strings, and omits the zero values
+		TimestampMs json.Number `json:"timestampMs"`
+		// protobuf encodes NaN and infinities as strings
+		Value any `json:"value"`
+	} `json:"samples"`
+	PromLabels string `json:"promLabels"`
+}
+
+type queryAnyValue struct {
+	StringValue *string     `json:"stringValue"`
+	IntValue    json.Number `json:"intValue"`
+	DoubleValue *float64    `json:"doubleValue"`
+	BoolValue   *bool       `json:"boolValue"`
+}
+
+func (v queryAnyValue) String() string {
+	switch {
+	case v.StringValue != nil:
+		return *v.StringValue
+	case v.IntValue != "":
+		return v.IntValue.String()
+	case v.DoubleValue != nil:
+		return strconv.FormatFloat(*v.DoubleValue, 'f', -1, 64)
+	case v.BoolValue != nil:
+		return strconv.FormatBool(*v.BoolValue)
+	default:
+		return ""
+	}
+}
+
+// queryTraceQLMetrics runs a TraceQL metrics query, such as rate() or quantile_over_time(), and returns a
+// time series frame for each series, which can be used by alert rules.
+func (s *Service) queryTraceQLMetrics(ctx context.Context, pCtx backend.PluginContext, query backend.DataQuery) (*backend.DataResponse, error) {
+	ctxLogger := s.logger.FromContext(ctx)
+
+	ctx, span := tracing.DefaultTracer().Start(ctx, "datasource.tempo.queryTraceQLMetrics", trace.WithAttributes(
+		attribute.String("queryType", query.QueryType),
+	))
+	defer span.End()
+
+	model := &dataquery.TempoQuery{}
+	if err := json.Unmarshal(query.JSON, model); err != nil {
+		ctxLogger.Error("Failed to unmarshall Tempo query model", "error", err, "function", logEntrypoint())
+		return &backend.DataResponse{}, err
+	}
+	if model.Query == nil || *model.Query == "" {
+		return &backend.DataResponse{Error: fmt.Errorf("TraceQL query is required"), ErrorSource: backend.ErrorSourcePlugin}, nil
+	}
+
+	step, err := traceQLMetricsStep(query, model.Step)
+	if err != nil {
+		return &backend.DataResponse{Error: err, ErrorSource: backend.ErrorSourcePlugin}, nil
+	}
+
+	dsInfo, err := s.getDSInfo(ctx, pCtx)
+	if err != nil {
+		ctxLogger.Error("Failed to get datasource information", "error", err, "function", logEntrypoint())
+		return nil, err
+	}
+
+	params := url.Values{}
+	params.Set("q", *model.Query)
+	params.Set("start", strconv.FormatInt(query.TimeRange.From.Unix(), 10))
+	params.Set("end", strconv.FormatInt(query.TimeRange.To.Unix(), 10))
+	params.Set("step", step.String())
+
+	var res queryRangeResponse
+	if err := s.getJSON(ctx, dsInfo, "/api/metrics/query_range", params, &res); err != nil {
+		ctxLogger.Error("Failed to run TraceQL metrics query", "error", err, "function", logEntrypoint())
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return errorResponse(err), nil
+	}
+
+	frames, err := queryRangeResponseToFrames(&res, query.RefID, *model.Query, step)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return &backend.DataResponse{}, err
+	}
+	return &backend.DataResponse{Frames: frames}, nil
+}
+
+// traceQLMetricsStep returns the step of the query, or the interval of the query, limited to the maximum
+// number of data points, when it has no step.
+func traceQLMetricsStep(query backend.DataQuery, queryStep *string) (time.Duration, error) {
+	if queryStep != nil && *queryStep != "" {
+		step, err := gtime.ParseInterval(*queryStep)
+		if err != nil {
+			return 0, fmt.Errorf("invalid step %q: %w", *queryStep, err)
+		}
+		if step <= 0 {
+			return 0, fmt.Errorf("invalid step %q: the step must be positive", *queryStep)
+		}
+		return step, nil
+	}
+
+	maxDataPoints := query.MaxDataPoints
+	if maxDataPoints <= 0 {
+		maxDataPoints = defaultMetricsMaxDataPoints
+	}
+	step := query.Interval
+	if minStep := query.TimeRange.Duration() / time.Duration(maxDataPoints); minStep > step {
+		step = minStep
+	}
+	// Tempo doesn't support steps smaller than a second well, and they would return too many data points
+	return time.Duration(math.Max(1, math.Ceil(step.Seconds()))) * time.Second, nil
+}
+
+func queryRangeResponseToFrames(res *queryRangeResponse, refID string, query string, step time.Duration) (data.Frames, error) {
+	frames := make(data.Frames, 0, len(res.Series))
+	for _, series := range res.Series {
+		labels := data.Labels{}
+		for _, label := range series.Labels {
+			labels[label.Key] = label.Value.String()
+		}
+
+		type sample struct {
+			t time.Time
+			v float64
+		}
+		samples := make([]sample, 0, len(series.Samples))
+		for _, s := range series.Samples {
+			var ms int64
+			if s.TimestampMs != "" {
+				var err error
+				if ms, err = s.TimestampMs.Int64(); err != nil {
+					return nil, fmt.Errorf("invalid timestamp %q: %w", s.TimestampMs, err)
+				}
+			}
+			v, err := sampleValue(s.Value)
+			if err != nil {
+				return nil, err
+			}
+			samples = append(samples, sample{t: time.UnixMilli(ms).UTC(), v: v})
+		}
+		sort.Slice(samples, func(i, j int) bool { return samples[i].t.Before(samples[j].t) })
+
+		times := make([]time.Time, len(samples))
+		values := make([]float64, len(samples))
+		for i, s := range samples {
+			times[i] = s.t
+			values[i] = s.v
+		}
+
+		timeField := data.NewField(data.TimeSeriesTimeFieldName, nil, times)
+		timeField.Config = &data.FieldConfig{Interval: float64(step.Milliseconds())}
+		valueField := data.NewField(data.TimeSeriesValueFieldName, labels, values)
+		if series.PromLabels != "" {
+			valueField.Config = &data.FieldConfig{DisplayNameFromDS: series.PromLabels}
+		}
+
+		frame := data.NewFrame("", timeField, valueField)
+		frame.RefID = refID
+		frame.Meta = &data.FrameMeta{
+			Type:                data.FrameTypeTimeSeriesMulti,
+			TypeVersion:         data.FrameTypeVersion{0, 1},
+			ExecutedQueryString: fmt.Sprintf("%s\nStep: %s", query, step),
+		}
+		frames = append(frames, frame)
+	}
+	return frames, nil
+}
+
+func sampleValue(value any) (float64, error) {
+	switch v := value.(type) {
+	case float64:
+		return v, nil
+	case nil:
+		return 0, nil
+	case string:
+		switch v {
+		case "NaN":
+			return math.NaN(), nil
+		case "Infinity":
+			return math.Inf(1), nil
+		case "-Infinity":
+			return math.Inf(-1), nil
+		}
+		return strconv.ParseFloat(v, 64)
+	default:
+		return 0, fmt.Errorf("invalid sample value %v", value)
+	}
+}
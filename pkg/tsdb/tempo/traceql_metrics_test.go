@@ -0,0 +1,155 @@
+package tempo
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeInstanceManager struct {
+	dsInfo *Datasource
+}
+
+func (f *fakeInstanceManager) Get(_ context.Context, _ backend.PluginContext) (instancemgmt.Instance, error) {
+	return f.dsInfo, nil
+}
+
+func (f *fakeInstanceManager) Do(_ context.Context, _ backend.PluginContext, _ instancemgmt.InstanceCallbackFunc) error {
+	return nil
+}
+
+// newTestService returns a service whose data source sends its requests to the handler.
+func newTestService(t *testing.T, handler http.HandlerFunc) *Service {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return &Service{
+		logger: backend.NewLoggerWith("logger", "tempo-test"),
+		im:     &fakeInstanceManager{dsInfo: &Datasource{HTTPClient: srv.Client(), URL: srv.URL}},
+	}
+}
+
+func TestTraceQLMetricsStep(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("uses the step of the query", func(t *testing.T) {
+		step := "30s"
+		res, err := traceQLMetricsStep(backend.DataQuery{Interval: time.Minute}, &step)
+		require.NoError(t, err)
+		assert.Equal(t, 30*time.Second, res)
+	})
+
+	t.Run("rejects invalid steps", func(t *testing.T) {
+		step := "fast"
+		_, err := traceQLMetricsStep(backend.DataQuery{}, &step)
+		require.Error(t, err)
+	})
+
+	t.Run("limits the interval to the maximum number of data points", func(t *testing.T) {
+		query := backend.DataQuery{
+			Interval:      time.Second,
+			MaxDataPoints: 100,
+			TimeRange:     backend.TimeRange{From: from, To: from.Add(time.Hour)},
+		}
+		res, err := traceQLMetricsStep(query, nil)
+		require.NoError(t, err)
+		assert.Equal(t, 36*time.Second, res)
+	})
+
+	t.Run("rounds the interval up to seconds", func(t *testing.T) {
+		query := backend.DataQuery{
+			Interval:  100 * time.Millisecond,
+			TimeRange: backend.TimeRange{From: from, To: from.Add(time.Minute)},
+		}
+		res, err := traceQLMetricsStep(query, nil)
+		require.NoError(t, err)
+		assert.Equal(t, time.Second, res)
+	})
+}
+
+func TestQueryTraceQLMetrics(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	query := backend.DataQuery{
+		RefID:     "A",
+		QueryType: "traceqlMetrics",
+		TimeRange: backend.TimeRange{From: from, To: from.Add(time.Hour)},
+		JSON:      []byte(`{"query": "{ } | rate() by (resource.service.name)", "step": "1m"}`),
+	}
+
+	t.Run("returns a time series frame for each series", func(t *testing.T) {
+		service := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/api/metrics/query_range", r.URL.Path)
+			assert.Equal(t, "{ } | rate() by (resource.service.name)", r.URL.Query().Get("q"))
+			assert.Equal(t, "1704067200", r.URL.Query().Get("start"))
+			assert.Equal(t, "1704070800", r.URL.Query().Get("end"))
+			assert.Equal(t, "1m0s", r.URL.Query().Get("step"))
+			_, _ = w.Write([]byte(`{"series": [
+				{
+					"labels": [{"key": "resource.service.name", "value": {"stringValue": "api"}}],
+					"samples": [{"timestampMs": "1704067260000", "value": 2}, {"timestampMs": "1704067200000", "value": 1.5}],
+					"promLabels": "{resource.service.name=\"api\"}"
+				},
+				{
+					"labels": [{"key": "resource.service.name", "value": {"stringValue": "db"}}],
+					"samples": [{"timestampMs": "1704067200000"}, {"timestampMs": "1704067260000", "value": "NaN"}]
+				}
+			]}`))
+		})
+
+		res, err := service.query(context.Background(), backend.PluginContext{}, query)
+		require.NoError(t, err)
+		require.NoError(t, res.Error)
+		require.Len(t, res.Frames, 2)
+
+		api := res.Frames[0]
+		assert.Equal(t, "A", api.RefID)
+		assert.Equal(t, data.FrameTypeTimeSeriesMulti, api.Meta.Type)
+		assert.Equal(t, data.Labels{"resource.service.name": "api"}, api.Fields[1].Labels)
+		assert.Equal(t, `{resource.service.name="api"}`, api.Fields[1].Config.DisplayNameFromDS)
+		require.Equal(t, 2, api.Rows())
+		assert.Equal(t, from, api.Fields[0].At(0))
+		assert.Equal(t, 1.5, api.Fields[1].At(0))
+		assert.Equal(t, 2.0, api.Fields[1].At(1))
+
+		db := res.Frames[1]
+		assert.Equal(t, 0.0, db.Fields[1].At(0))
+		assert.True(t, math.IsNaN(db.Fields[1].At(1).(float64)))
+	})
+
+	t.Run("returns the errors of Tempo with their source", func(t *testing.T) {
+		service := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte("invalid TraceQL query"))
+		})
+
+		res, err := service.query(context.Background(), backend.PluginContext{}, query)
+		require.NoError(t, err)
+		require.ErrorContains(t, res.Error, "invalid TraceQL query")
+		assert.Equal(t, backend.ErrorSourceDownstream, res.ErrorSource)
+	})
+
+	t.Run("returns a plugin error when the query is missing", func(t *testing.T) {
+		service := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+			assert.Fail(t, "the query must not be sent to Tempo")
+		})
+
+		res, err := service.query(context.Background(), backend.PluginContext{}, backend.DataQuery{
+			RefID:     "A",
+			QueryType: query.QueryType,
+			JSON:      []byte(`{"query": ""}`),
+			TimeRange: query.TimeRange,
+		})
+		require.NoError(t, err)
+		require.EqualError(t, res.Error, "TraceQL query is required")
+		assert.Equal(t, backend.ErrorSourcePlugin, res.ErrorSource)
+	})
+}
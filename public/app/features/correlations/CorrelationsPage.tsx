@@ -28,12 +28,12 @@ import { AddCorrelationForm } from './Forms/AddCorrelationForm';
 import { EditCorrelationForm } from './Forms/EditCorrelationForm';
 import { EmptyCorrelationsCTA } from './components/EmptyCorrelationsCTA';
 import type { RemoveCorrelationParams } from './types';
-import { CorrelationData, useCorrelations } from './useCorrelations';
+import { QueryCorrelationData, isQueryCorrelation, useCorrelations } from './useCorrelations';
 
-const sortDatasource: SortByFn<CorrelationData> = (a, b, column) =>
+const sortDatasource: SortByFn<QueryCorrelationData> = (a, b, column) =>
   a.values[column].name.localeCompare(b.values[column].name);
 
-const isCorrelationsReadOnly = (correlation: CorrelationData) => correlation.provisioned;
+const isCorrelationsReadOnly = (correlation: QueryCorrelationData) => correlation.provisioned;
 
 const loaderWrapper = css`
   display: flex;
@@ -97,7 +97,7 @@ export default function CorrelationsPage() {
           uid,
         },
       },
-    }: CellProps<CorrelationData, void>) => {
+    }: CellProps<QueryCorrelationData, void>) => {
       return (
         !provisioned && (
           <DeleteButton
@@ -114,7 +114,7 @@ export default function CorrelationsPage() {
     [handleDelete]
   );
 
-  const columns = useMemo<Array<Column<CorrelationData>>>(
+  const columns = useMemo<Array<Column<QueryCorrelationData>>>(
     () => [
       {
         id: 'info',
@@ -215,7 +215,8 @@ export default function CorrelationsPage() {
                   />
                 )}
                 columns={columns}
-                data={data.correlations}
+                // external correlations don't have a target data source, they are managed with the API or provisioning
+                data={data.correlations.filter(isQueryCorrelation)}
                 getRowId={(correlation) => `${correlation.source.uid}-${correlation.uid}`}
               />
               <Pagination
@@ -234,7 +235,7 @@ export default function CorrelationsPage() {
 }
 
 interface ExpandedRowProps {
-  correlation: CorrelationData;
+  correlation: QueryCorrelationData;
   readOnly: boolean;
   onUpdated: () => void;
 }
@@ -270,7 +271,7 @@ const getDatasourceCellStyles = (theme: GrafanaTheme2) => ({
 const DataSourceCell = memo(
   function DataSourceCell({
     cell: { value },
-  }: CellProps<CorrelationData, CorrelationData['source'] | CorrelationData['target']>) {
+  }: CellProps<QueryCorrelationData, QueryCorrelationData['source'] | QueryCorrelationData['target']>) {
     const styles = useStyles2(getDatasourceCellStyles);
 
     return (
@@ -290,7 +291,7 @@ const noWrap = css`
 `;
 
 const InfoCell = memo(
-  function InfoCell({ ...props }: CellProps<CorrelationData, void>) {
+  function InfoCell({ ...props }: CellProps<QueryCorrelationData, void>) {
     const readOnly = props.row.original.provisioned;
 
     if (readOnly) {
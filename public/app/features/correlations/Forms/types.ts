@@ -73,6 +73,42 @@ export function getSupportedTransTypeDetails(
           ),
         },
       };
+    case SupportedTransformationType.JSONPath:
+      return {
+        label: t('correlations.trans-details.jsonpath-label', 'JSONPath'),
+        value: SupportedTransformationType.JSONPath,
+        description: t(
+          'correlations.trans-details.jsonpath-description',
+          'Field containing JSON will be parsed, and the value at the path is added to named map value.'
+        ),
+        expressionDetails: {
+          show: true,
+          required: true,
+          helpText: t(
+            'correlations.trans-details.jsonpath-expression',
+            'The path of the value, for example $.user.id.'
+          ),
+        },
+        mapValueDetails: {
+          show: true,
+          required: false,
+          helpText: t(
+            'correlations.trans-details.jsonpath-map-values',
+            'Defines the name of the variable. The name of the field is used by default.'
+          ),
+        },
+      };
+    case SupportedTransformationType.KeyValue:
+      return {
+        label: t('correlations.trans-details.keyvalue-label', 'Key-value'),
+        value: SupportedTransformationType.KeyValue,
+        description: t(
+          'correlations.trans-details.keyvalue-description',
+          'Parse provided field with key=value pairs separated by spaces to get variables'
+        ),
+        expressionDetails: { show: false },
+        mapValueDetails: { show: false },
+      };
     default:
       return {
         label: transType,
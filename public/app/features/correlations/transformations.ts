@@ -1,3 +1,4 @@
+import { get } from 'lodash';
 import logfmt from 'logfmt';
 
 import { ScopedVars, DataLinkTransformationConfig, SupportedTransformationType } from '@grafana/data';
@@ -9,7 +10,7 @@ export const getTransformationVars = (
   fieldName: string
 ): ScopedVars => {
   let transformationScopedVars: ScopedVars = {};
-  let transformVal: { [key: string]: string | boolean | null | undefined } = {};
+  let transformVal: { [key: string]: unknown } = {};
   if (transformation.type === SupportedTransformationType.Regex && transformation.expression) {
     const regexp = new RegExp(transformation.expression, 'gi');
     const stringFieldVal = typeof fieldValue === 'string' ? fieldValue : safeStringifyValue(fieldValue);
@@ -24,6 +25,13 @@ export const getTransformationVars = (
     }
   } else if (transformation.type === SupportedTransformationType.Logfmt) {
     transformVal = logfmt.parse(fieldValue);
+  } else if (transformation.type === SupportedTransformationType.JSONPath && transformation.expression) {
+    const value = getJSONPathValue(fieldValue, transformation.expression);
+    if (value !== undefined) {
+      transformVal[transformation.mapValue || fieldName] = value;
+    }
+  } else if (transformation.type === SupportedTransformationType.KeyValue) {
+    transformVal = parseKeyValue(fieldValue, transformation.delimiter || ' ', transformation.separator || '=');
   }
 
   Object.keys(transformVal).forEach((key) => {
@@ -34,3 +42,37 @@ export const getTransformationVars = (
 
   return transformationScopedVars;
 };
+
+/**
+ * Returns the value at a JSONPath expression like `$.user.ids[0]` in a field value containing JSON,
+ * or undefined when the value is not JSON or the path does not exist.
+ */
+const getJSONPathValue = (fieldValue: unknown, expression: string): unknown => {
+  let json = fieldValue;
+  if (typeof fieldValue === 'string') {
+    try {
+      json = JSON.parse(fieldValue);
+    } catch (e) {
+      return undefined;
+    }
+  }
+
+  const path = expression.replace(/^\$\.?/, '');
+  return path ? get(json, path) : json;
+};
+
+const parseKeyValue = (fieldValue: string, delimiter: string, separator: string): Record<string, string> => {
+  const stringFieldVal = typeof fieldValue === 'string' ? fieldValue : safeStringifyValue(fieldValue);
+  const values: Record<string, string> = {};
+  for (const pair of stringFieldVal.split(delimiter)) {
+    const separatorIndex = pair.indexOf(separator);
+    if (separatorIndex <= 0) {
+      continue;
+    }
+    const key = pair.slice(0, separatorIndex).trim();
+    if (key) {
+      values[key] = pair.slice(separatorIndex + separator.length).trim();
+    }
+  }
+  return values;
+};
@@ -26,7 +26,7 @@ export interface RemoveCorrelationResponse {
   message: string;
 }
 
-type CorrelationConfigType = 'query';
+type CorrelationConfigType = 'query' | 'external';
 
 export interface CorrelationConfig {
   field: string;
@@ -26,9 +26,17 @@ export interface CorrelationsResponse {
 
 export interface CorrelationData extends Omit<Correlation, 'sourceUID' | 'targetUID'> {
   source: DataSourceInstanceSettings;
+  // external correlations link to a URL instead of a target data source
+  target?: DataSourceInstanceSettings;
+}
+
+export interface QueryCorrelationData extends CorrelationData {
   target: DataSourceInstanceSettings;
 }
 
+export const isQueryCorrelation = (correlation: CorrelationData): correlation is QueryCorrelationData =>
+  correlation.target !== undefined;
+
 export interface CorrelationsData {
   correlations: CorrelationData[];
   page: number;
@@ -51,7 +59,12 @@ const toEnrichedCorrelationData = ({
     correlationsLogger.logWarning('Invalid correlation config: Missing org id.');
   }
 
-  if (
+  if (sourceDatasource && sourceDatasource?.uid !== undefined && correlation.config?.type === 'external') {
+    return {
+      ...correlation,
+      source: sourceDatasource,
+    };
+  } else if (
     sourceDatasource &&
     sourceDatasource?.uid !== undefined &&
     targetDatasource &&
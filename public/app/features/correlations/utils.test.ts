@@ -1,4 +1,11 @@
-import { DataFrame, DataSourceInstanceSettings, FieldType, toDataFrame } from '@grafana/data';
+import {
+  DataFrame,
+  DataLinkConfigOrigin,
+  DataSourceInstanceSettings,
+  FieldType,
+  SupportedTransformationType,
+  toDataFrame,
+} from '@grafana/data';
 
 import { CorrelationData } from './useCorrelations';
 import { attachCorrelationsToDataFrames } from './utils';
@@ -56,6 +63,38 @@ describe('correlations utils', () => {
     });
   });
 
+  it('attaches external correlations as links to their URL', () => {
+    const { testDataFrames, refIdMap, loki } = setup();
+    const transformations = [
+      { type: SupportedTransformationType.Regex, expression: 'ticket=(\\w+)', mapValue: 'ticket' },
+    ];
+    const correlations: CorrelationData[] = [
+      {
+        uid: 'loki-to-tickets',
+        label: 'ticket',
+        source: loki,
+        provisioned: false,
+        config: {
+          type: 'external',
+          field: 'line',
+          target: { url: 'https://tickets.example.com/browse/${ticket}' },
+          transformations,
+        },
+      },
+    ];
+    attachCorrelationsToDataFrames(testDataFrames, correlations, refIdMap);
+
+    expect(testDataFrames[0].fields[0].config.links).toEqual([
+      {
+        title: 'ticket',
+        url: 'https://tickets.example.com/browse/${ticket}',
+        targetBlank: true,
+        transformations,
+        origin: DataLinkConfigOrigin.Correlations,
+      },
+    ]);
+  });
+
   it('does not create duplicates when attaching links to the same data frame', () => {
     const { testDataFrames, correlations, refIdMap } = setup();
     attachCorrelationsToDataFrames(testDataFrames, correlations, refIdMap);
@@ -133,5 +172,5 @@ function setup() {
     },
   ];
 
-  return { testDataFrames, correlations, refIdMap, prometheus, elastic };
+  return { testDataFrames, correlations, refIdMap, loki, prometheus, elastic };
 }
@@ -12,6 +12,7 @@ import {
   CorrelationsData,
   CorrelationsResponse,
   getData,
+  isQueryCorrelation,
   toEnrichedCorrelationsData,
 } from './useCorrelations';
 
@@ -53,7 +54,10 @@ const decorateDataFrameWithInternalDataLinks = (dataFrame: DataFrame, correlatio
   dataFrame.fields.forEach((field) => {
     field.config.links = field.config.links?.filter((link) => link.origin !== DataLinkConfigOrigin.Correlations) || [];
     correlations.map((correlation) => {
-      if (correlation.config?.field === field.name) {
+      if (correlation.config?.field !== field.name) {
+        return;
+      }
+      if (isQueryCorrelation(correlation)) {
         const targetQuery = correlation.config?.target || {};
         field.config.links!.push({
           internal: {
@@ -66,6 +70,16 @@ const decorateDataFrameWithInternalDataLinks = (dataFrame: DataFrame, correlatio
           title: correlation.label || correlation.target.name,
           origin: DataLinkConfigOrigin.Correlations,
         });
+      } else {
+        // the variables of external correlations are interpolated in their URL
+        const { url } = correlation.config.target as { url?: string };
+        field.config.links!.push({
+          url: url || '',
+          title: correlation.label || url || '',
+          targetBlank: true,
+          transformations: correlation.config.transformations,
+          origin: DataLinkConfigOrigin.Correlations,
+        });
       }
     });
   });
@@ -510,6 +510,39 @@ describe('explore links utils', () => {
       );
     });
 
+    it('returns internal links with chained jsonpath and keyvalue transformations', () => {
+      const transformationLink: DataLink = {
+        title: '',
+        url: '',
+        internal: {
+          query: { query: 'http_requests{app=${app} env=${env}}' },
+          datasourceUid: 'uid_1',
+          datasourceName: 'test_ds',
+          transformations: [
+            { type: SupportedTransformationType.JSONPath, expression: '$.labels', mapValue: 'labels' },
+            { type: SupportedTransformationType.KeyValue, input: 'labels', delimiter: ',', separator: ':' },
+          ],
+        },
+      };
+
+      const { field, range, dataFrame } = setup(transformationLink, true, {
+        name: 'msg',
+        type: FieldType.string,
+        values: ['{"labels":"app:foo, env:prod"}'],
+        config: {
+          links: [transformationLink],
+        },
+      });
+
+      const links = getFieldLinksForExplore({ field, rowIndex: 0, range, dataFrame });
+      expect(links).toHaveLength(1);
+      expect(links[0].href).toBe(
+        `/explore?left=${encodeURIComponent(
+          '{"range":{"from":"now-1h","to":"now"},"datasource":"uid_1","queries":[{"query":"http_requests{app=foo env=prod}"}]}'
+        )}`
+      );
+    });
+
     it('returns internal links with regex named capture groups', () => {
       const transformationLink: DataLink = {
         title: '',
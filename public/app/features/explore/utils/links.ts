@@ -14,6 +14,7 @@ import {
   DataLink,
   DisplayValue,
   DataLinkConfigOrigin,
+  DataLinkTransformationConfig,
   CoreApp,
   SplitOpenOptions,
   DataLinkPostProcessor,
@@ -89,6 +90,36 @@ export const exploreDataLinkPostProcessorFactory = (
   return exploreDataLinkPostProcessor;
 };
 
+/**
+ * Returns the variables extracted by the transformations of a link from the row of the field.
+ * Transformations with an input use a variable extracted by a previous transformation.
+ */
+const getTransformationsVars = (
+  transformations: DataLinkTransformationConfig[] | undefined,
+  field: Field,
+  rowIndex: number,
+  dataFrame?: DataFrame
+): ScopedVars => {
+  let vars: ScopedVars = {};
+  transformations?.forEach((transformation) => {
+    let fieldValue;
+    if (transformation.input) {
+      fieldValue = vars[transformation.input]?.value;
+    } else if (transformation.field) {
+      const transformField = dataFrame?.fields.find((field) => field.name === transformation.field);
+      fieldValue = transformField?.values[rowIndex];
+    } else {
+      fieldValue = field.values[rowIndex];
+    }
+
+    vars = {
+      ...vars,
+      ...getTransformationVars(transformation, fieldValue, field.name),
+    };
+  });
+  return vars;
+};
+
 /**
  * Get links from the field of a dataframe and in addition check if there is associated
  * metadata with datasource in which case we will add onClick to open the link in new split window. This assumes
@@ -160,8 +191,9 @@ export const getFieldLinksForExplore = (options: {
 
     const fieldLinks = links.map((link) => {
       if (!link.internal) {
+        const transformationVars = getTransformationsVars(link.transformations, field, rowIndex, dataFrame);
         const replace: InterpolateFunction = (value, vars) =>
-          getTemplateSrv().replace(value, { ...vars, ...scopedVars });
+          getTemplateSrv().replace(value, { ...vars, ...transformationVars, ...scopedVars });
 
         const linkModel = getLinkSrv().getDataLinkUIModel(link, replace, field);
         if (!linkModel.title) {
@@ -169,23 +201,12 @@ export const getFieldLinksForExplore = (options: {
         }
         return linkModel;
       } else {
-        let internalLinkSpecificVars: ScopedVars = {};
-        if (link.internal?.transformations) {
-          link.internal?.transformations.forEach((transformation) => {
-            let fieldValue;
-            if (transformation.field) {
-              const transformField = dataFrame?.fields.find((field) => field.name === transformation.field);
-              fieldValue = transformField?.values[rowIndex];
-            } else {
-              fieldValue = field.values[rowIndex];
-            }
-
-            internalLinkSpecificVars = {
-              ...internalLinkSpecificVars,
-              ...getTransformationVars(transformation, fieldValue, field.name),
-            };
-          });
-        }
+        const internalLinkSpecificVars = getTransformationsVars(
+          link.internal?.transformations,
+          field,
+          rowIndex,
+          dataFrame
+        );
 
         const allVars = { ...scopedVars, ...internalLinkSpecificVars };
         const variableData = getVariableUsageInfo(link, allVars);
@@ -0,0 +1,32 @@
+import React from 'react';
+
+import { SelectableValue } from '@grafana/data';
+import { RadioButtonGroup } from '@grafana/ui';
+
+import { useDispatch } from '../../hooks/useStatelessReducer';
+import { QueryLanguage } from '../../types';
+
+import { useQuery } from './ElasticsearchQueryContext';
+import { changeQueryLanguage } from './state';
+
+const OPTIONS: Array<SelectableValue<QueryLanguage>> = [
+  { value: 'lucene', label: 'Lucene', description: 'Build the query with aggregations' },
+  { value: 'esql', label: 'ES|QL', description: 'Write an Elasticsearch Query Language query' },
+  { value: 'sql', label: 'SQL', description: 'Write an Elasticsearch SQL query' },
+];
+
+export const QueryLanguageSelector = () => {
+  const query = useQuery();
+  const dispatch = useDispatch();
+
+  const language: QueryLanguage = query.queryType === 'esql' || query.queryType === 'sql' ? query.queryType : 'lucene';
+
+  return (
+    <RadioButtonGroup<QueryLanguage>
+      fullWidth={false}
+      options={OPTIONS}
+      value={language}
+      onChange={(newLanguage) => dispatch(changeQueryLanguage(newLanguage))}
+    />
+  );
+};
@@ -3,18 +3,20 @@ import React, { useEffect, useId, useState } from 'react';
 import { SemVer } from 'semver';
 
 import { getDefaultTimeRange, GrafanaTheme2, QueryEditorProps } from '@grafana/data';
-import { Alert, InlineField, InlineLabel, Input, QueryField, useStyles2 } from '@grafana/ui';
+import { config } from '@grafana/runtime';
+import { Alert, InlineField, InlineLabel, Input, QueryField, TextArea, useStyles2 } from '@grafana/ui';
 
 import { ElasticDatasource } from '../../datasource';
 import { useNextId } from '../../hooks/useNextId';
 import { useDispatch } from '../../hooks/useStatelessReducer';
 import { ElasticsearchOptions, ElasticsearchQuery } from '../../types';
-import { isSupportedVersion, isTimeSeriesQuery, unsupportedVersionMessage } from '../../utils';
+import { isRawLanguageQuery, isSupportedVersion, isTimeSeriesQuery, unsupportedVersionMessage } from '../../utils';
 
 import { BucketAggregationsEditor } from './BucketAggregationsEditor';
 import { ElasticsearchProvider } from './ElasticsearchQueryContext';
 import { MetricAggregationsEditor } from './MetricAggregationsEditor';
 import { metricAggregationConfig } from './MetricAggregationsEditor/utils';
+import { QueryLanguageSelector } from './QueryLanguageSelector';
 import { QueryTypeSelector } from './QueryTypeSelector';
 import { changeAliasPattern, changeQuery } from './state';
 
@@ -99,8 +101,55 @@ const QueryEditorForm = ({ value }: Props) => {
     (metric) => metricAggregationConfig[metric.type].impliedQueryType === 'metrics'
   );
 
+  // ES|QL and SQL queries are only run by the backend
+  const showQueryLanguageSelector = config.featureToggles.enableElasticsearchBackendQuerying;
+
+  if (isRawLanguageQuery(value)) {
+    return (
+      <>
+        {showQueryLanguageSelector && (
+          <div className={styles.root}>
+            <InlineLabel width={17}>Query language</InlineLabel>
+            <div className={styles.queryItem}>
+              <QueryLanguageSelector />
+            </div>
+          </div>
+        )}
+        <div className={styles.root}>
+          <InlineLabel
+            width={17}
+            tooltip="Use $__timeFilter(field), $__timeFrom(), $__timeTo(), $__interval and $__interval_ms to filter and group by the time range of the query."
+          >
+            {value.queryType === 'esql' ? 'ES|QL Query' : 'SQL Query'}
+          </InlineLabel>
+          <div className={styles.queryItem}>
+            <TextArea
+              key={value.queryType}
+              rows={6}
+              defaultValue={value.query}
+              placeholder={
+                value.queryType === 'esql'
+                  ? 'FROM logs-* | WHERE $__timeFilter() | STATS count = COUNT(*) BY BUCKET(@timestamp, $__interval)'
+                  : 'SELECT HISTOGRAM("@timestamp", $__interval) AS time, COUNT(*) FROM "logs-*" WHERE $__timeFilter() GROUP BY time'
+              }
+              onBlur={(e) => dispatch(changeQuery(e.currentTarget.value))}
+            />
+          </div>
+        </div>
+      </>
+    );
+  }
+
   return (
     <>
+      {showQueryLanguageSelector && (
+        <div className={styles.root}>
+          <InlineLabel width={17}>Query language</InlineLabel>
+          <div className={styles.queryItem}>
+            <QueryLanguageSelector />
+          </div>
+        </div>
+      )}
       <div className={styles.root}>
         <InlineLabel width={17}>Query type</InlineLabel>
         <div className={styles.queryItem}>
@@ -897,12 +897,42 @@ describe('ElasticDatasource', () => {
     expect((interpolatedQuery.bucketAggs![0] as Filters).settings!.filters![0].query).toBe('*');
   });
 
+  it('should interpolate ES|QL queries without lucene formatting and ad hoc filters', () => {
+    const adHocFilters = [{ key: 'bar', operator: '=', value: 'test' }];
+    const { ds } = getTestContext();
+    const query: ElasticsearchQuery = {
+      refId: 'A',
+      queryType: 'esql',
+      metrics: [{ type: 'count', id: '1' }],
+      query: 'FROM logs | WHERE $__timeFilter()',
+    };
+
+    const interpolatedQuery = ds.interpolateVariablesInQueries([query], {}, adHocFilters)[0];
+
+    expect(interpolatedQuery.query).toBe('FROM logs | WHERE $__timeFilter()');
+    expect(interpolatedQuery.queryType).toBe('esql');
+  });
+
   describe('getSupplementaryQuery', () => {
     let ds: ElasticDatasource;
     beforeEach(() => {
       ds = getTestContext().ds;
     });
 
+    it('does not return logs volume query for ES|QL query', () => {
+      expect(
+        ds.getSupplementaryQuery(
+          { type: SupplementaryQueryType.LogsVolume },
+          {
+            refId: 'A',
+            queryType: 'esql',
+            metrics: [{ type: 'logs', id: '1' }],
+            query: 'FROM logs',
+          }
+        )
+      ).toEqual(undefined);
+    });
+
     it('does not return logs volume query for metric query', () => {
       expect(
         ds.getSupplementaryQuery(
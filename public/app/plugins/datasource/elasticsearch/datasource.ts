@@ -81,7 +81,13 @@ import {
   ElasticsearchAnnotationQuery,
   RangeMap,
 } from './types';
-import { getScriptValue, isSupportedVersion, isTimeSeriesQuery, unsupportedVersionMessage } from './utils';
+import {
+  getScriptValue,
+  isRawLanguageQuery,
+  isSupportedVersion,
+  isTimeSeriesQuery,
+  unsupportedVersionMessage,
+} from './utils';
 
 export const REF_ID_STARTER_LOG_VOLUME = 'log-volume-';
 export const REF_ID_STARTER_LOG_SAMPLE = 'log-sample-';
@@ -571,6 +577,11 @@ export class ElasticDatasource
   }
 
   getSupplementaryQuery(options: SupplementaryQueryOptions, query: ElasticsearchQuery): ElasticsearchQuery | undefined {
+    // the supplementary queries are built with the aggregations of lucene queries
+    if (isRawLanguageQuery(query)) {
+      return undefined;
+    }
+
     let isQuerySuitable = false;
 
     switch (options.type) {
@@ -996,6 +1007,17 @@ export class ElasticDatasource
     scopedVars: ScopedVars,
     filters?: AdHocVariableFilter[]
   ): ElasticsearchQuery {
+    // ES|QL and SQL queries are interpolated as they are. The interval variables are left to the backend,
+    // which formats them as the time spans of the query language.
+    if (isRawLanguageQuery(query)) {
+      const { __interval, __interval_ms, ...rawQueryScopedVars } = scopedVars;
+      return {
+        ...query,
+        datasource: this.getRef(),
+        query: this.templateSrv.replace(query.query || '', rawQueryScopedVars),
+      };
+    }
+
     // We need a separate interpolation format for lucene queries, therefore we first interpolate any
     // lucene query string and then everything else
     const interpolateBucketAgg = (bucketAgg: BucketAggregation): BucketAggregation => {
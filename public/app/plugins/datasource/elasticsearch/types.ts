@@ -70,6 +70,12 @@ export interface ElasticsearchOptions extends DataSourceJsonData {
 
 export type QueryType = 'metrics' | 'logs' | 'raw_data' | 'raw_document';
 
+/**
+ * The language of the query, stored as the query type. Lucene queries are built with the aggregations editors
+ * and have no query type, while ES|QL and SQL queries are written directly and run by the backend.
+ */
+export type QueryLanguage = 'lucene' | 'esql' | 'sql';
+
 interface MetricConfiguration<T extends MetricAggregationType> {
   label: string;
   requiresField: boolean;
@@ -102,6 +102,10 @@ export const isSupportedVersion = (version: SemVer): boolean => {
 export const unsupportedVersionMessage =
   'Support for Elasticsearch versions after their end-of-life (currently versions < 7.16) was removed. Using unsupported version of Elasticsearch may lead to unexpected and incorrect results.';
 
+export const isRawLanguageQuery = (query: ElasticsearchQuery): boolean => {
+  return query?.queryType === 'esql' || query?.queryType === 'sql';
+};
+
 // To be considered a time series query, the last bucked aggregation must be a Date Histogram
 export const isTimeSeriesQuery = (query: ElasticsearchQuery): boolean => {
   return query?.bucketAggs?.slice(-1)[0]?.type === 'date_histogram';
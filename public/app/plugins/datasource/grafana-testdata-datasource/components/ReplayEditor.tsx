@@ -0,0 +1,54 @@
+import React, { useState } from 'react';
+
+import { Alert, CodeEditor, InlineField, InlineFieldRow, InlineSwitch } from '@grafana/ui';
+
+import { EditorProps } from '../QueryEditor';
+
+export const ReplayEditor = ({ onChange, query }: EditorProps) => {
+  const [error, setError] = useState<string>();
+
+  const onSaveRecording = (recording: string) => {
+    if (recording.trim() !== '') {
+      try {
+        const json = JSON.parse(recording);
+        if (!json.recordedAt || !Array.isArray(json.frames)) {
+          setError('Enter a recording with recordedAt and frames, as returned by the recordings admin API');
+          return;
+        }
+      } catch (e) {
+        setError('Enter a recording in JSON');
+        return;
+      }
+    }
+    setError(undefined);
+    onChange({ ...query, replay: { ...query.replay, recording } });
+  };
+
+  const onDisableTimeShiftChange = (e: React.FormEvent<HTMLInputElement>) => {
+    onChange({ ...query, replay: { ...query.replay, disableTimeShift: e.currentTarget.checked } });
+  };
+
+  return (
+    <>
+      <InlineFieldRow>
+        <InlineField
+          label="Keep recorded times"
+          labelWidth={20}
+          tooltip="By default, the recorded times are shifted to line up with the end of the time range of the query"
+        >
+          <InlineSwitch value={!!query.replay?.disableTimeShift} onChange={onDisableTimeShiftChange} />
+        </InlineField>
+      </InlineFieldRow>
+      {error && <Alert title={error} severity="error" />}
+      <CodeEditor
+        height={300}
+        language="json"
+        value={query.replay?.recording ?? ''}
+        onBlur={onSaveRecording}
+        onSave={onSaveRecording}
+        showMiniMap={false}
+        showLineNumbers={true}
+      />
+    </>
+  );
+};
@@ -25,6 +25,7 @@ export enum TestDataQueryType {
   RandomWalkTable = 'random_walk_table',
   RandomWalkWithError = 'random_walk_with_error',
   RawFrame = 'raw_frame',
+  Replay = 'replay',
   ServerError500 = 'server_error_500',
   Simulation = 'simulation',
   SlowQuery = 'slow_query',
@@ -69,6 +70,17 @@ export interface NodesQuery {
   type?: 'random' | 'response_small' | 'response_medium' | 'random edges' | 'feature_showcase';
 }
 
+export interface ReplayQuery {
+  /**
+   * Keep the recorded timestamps instead of aligning them with the time range of the query
+   */
+  disableTimeShift?: boolean;
+  /**
+   * The recording of a query response, as returned by the recordings admin API
+   */
+  recording?: string;
+}
+
 export interface USAQuery {
   fields?: string[];
   mode?: string;
@@ -118,6 +130,7 @@ export interface TestDataDataQuery extends common.DataQuery {
   points?: Array<Array<string | number>>;
   pulseWave?: PulseWaveQuery;
   rawFrameContent?: string;
+  replay?: ReplayQuery;
   scenarioId?: TestDataQueryType;
   seriesCount?: number;
   sim?: SimulationQuery;
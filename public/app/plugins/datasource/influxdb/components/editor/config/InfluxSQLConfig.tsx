@@ -76,6 +76,26 @@ export const InfluxSqlConfig = (props: Props) => {
           }}
         />
       </Field>
+      <Field
+        horizontal
+        label={<InlineLabel width={WIDTH_SHORT}>Translate InfluxQL</InlineLabel>}
+        description="Run the InfluxQL builder queries of the dashboards migrated from InfluxDB 1.x as SQL queries"
+        className={styles.horizontalField}
+      >
+        <InlineSwitch
+          id={`${htmlPrefix}-influxql-to-sql`}
+          value={jsonData.influxqlToSql ?? false}
+          onChange={(event) => {
+            onOptionsChange({
+              ...options,
+              jsonData: {
+                ...jsonData,
+                influxqlToSql: event.currentTarget.checked,
+              },
+            });
+          }}
+        />
+      </Field>
     </div>
   );
 };
@@ -24,6 +24,7 @@ export interface InfluxOptions extends DataSourceJsonData {
   // With SQL
   metadata?: Array<Record<string, string>>;
   insecureGrpc?: boolean;
+  influxqlToSql?: boolean;
 }
 
 /**
@@ -17,6 +17,7 @@ import { LokiOptions } from '../types';
 import { AlertingSettings } from './AlertingSettings';
 import { DerivedFields } from './DerivedFields';
 import { QuerySettings } from './QuerySettings';
+import { QuerySplittingSettings } from './QuerySplittingSettings';
 
 export type Props = DataSourcePluginOptionsEditorProps<LokiOptions>;
 
@@ -82,6 +83,7 @@ export const ConfigEditor = (props: Props) => {
             predefinedOperations={options.jsonData.predefinedOperations || ''}
             onPredefinedOperationsChange={updatePredefinedOperations}
           />
+          <QuerySplittingSettings options={options} onOptionsChange={onOptionsChange} />
           <DerivedFields
             fields={options.jsonData.derivedFields}
             onChange={(value) => onOptionsChange(setDerivedFields(options, value))}
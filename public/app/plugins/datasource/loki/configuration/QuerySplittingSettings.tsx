@@ -0,0 +1,78 @@
+import React from 'react';
+
+import { DataSourcePluginOptionsEditorProps } from '@grafana/data';
+import { ConfigDescriptionLink, ConfigSubSection } from '@grafana/experimental';
+import { InlineField, InlineSwitch, Input } from '@grafana/ui';
+
+import { LokiOptions } from '../types';
+
+export function QuerySplittingSettings({
+  options,
+  onOptionsChange,
+}: Pick<DataSourcePluginOptionsEditorProps<LokiOptions>, 'options' | 'onOptionsChange'>) {
+  const updateJsonData = (jsonData: Partial<LokiOptions>) =>
+    onOptionsChange({ ...options, jsonData: { ...options.jsonData, ...jsonData } });
+
+  return (
+    <ConfigSubSection
+      title="Query splitting"
+      description={
+        <ConfigDescriptionLink
+          description="Split the long range queries run by the Grafana server, including the alert rules and the public dashboards, into smaller queries."
+          suffix="loki/configure-loki-data-source/#query-splitting"
+          feature="query splitting"
+        />
+      }
+    >
+      <InlineField
+        label="Split duration"
+        htmlFor="loki_config_querySplittingDuration"
+        labelWidth={29}
+        disabled={options.readOnly}
+        tooltip="The range queries are split into queries of this duration, which are run concurrently and whose results are merged. Leave empty to not split the queries by time."
+      >
+        <Input
+          id="loki_config_querySplittingDuration"
+          value={options.jsonData.querySplittingDuration ?? ''}
+          onChange={(event: React.FormEvent<HTMLInputElement>) =>
+            updateJsonData({ querySplittingDuration: event.currentTarget.value || undefined })
+          }
+          width={16}
+          placeholder="1d"
+          spellCheck={false}
+        />
+      </InlineField>
+      <InlineField
+        label="Maximum concurrent queries"
+        htmlFor="loki_config_querySplittingMaxConcurrency"
+        labelWidth={29}
+        disabled={options.readOnly}
+        tooltip="The maximum number of the split queries of a query running at the same time (default: 5)."
+      >
+        <Input
+          type="number"
+          id="loki_config_querySplittingMaxConcurrency"
+          value={options.jsonData.querySplittingMaxConcurrency ?? ''}
+          onChange={(event: React.FormEvent<HTMLInputElement>) => {
+            const value = parseInt(event.currentTarget.value, 10);
+            updateJsonData({ querySplittingMaxConcurrency: isNaN(value) ? undefined : value });
+          }}
+          width={16}
+          placeholder="5"
+        />
+      </InlineField>
+      <InlineField
+        label="Shard log queries"
+        labelWidth={29}
+        disabled={options.readOnly}
+        tooltip="Split the log queries further by the stream shards of Loki. The streams must be sharded by Loki to use this option."
+      >
+        <InlineSwitch
+          id="loki_config_queryShardingEnabled"
+          value={options.jsonData.queryShardingEnabled ?? false}
+          onChange={(event) => updateJsonData({ queryShardingEnabled: event.currentTarget.checked })}
+        />
+      </InlineField>
+    </ConfigSubSection>
+  );
+}
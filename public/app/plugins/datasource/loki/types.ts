@@ -44,6 +44,9 @@ export interface LokiOptions extends DataSourceJsonData {
   alertmanager?: string;
   keepCookies?: string[];
   predefinedOperations?: string;
+  querySplittingDuration?: string;
+  querySplittingMaxConcurrency?: number;
+  queryShardingEnabled?: boolean;
 }
 
 export interface LokiStreamResult {
@@ -0,0 +1,82 @@
+import { DataSourceInstanceSettings, TimeRange } from '@grafana/data';
+import { LanguageDefinition } from '@grafana/experimental';
+import { SqlDatasource, DB, SQLQuery, formatSQL } from '@grafana/sql';
+
+import { quoteIdentifierIfNecessary, quoteLiteral, toRawSql } from './sqlUtil';
+import { SQLiteOptions } from './types';
+
+// SQLite data sources query a single database file, which is always attached as the main schema.
+const MAIN_SCHEMA = 'main';
+
+export class SqliteDatasource extends SqlDatasource {
+  sqlLanguageDefinition: LanguageDefinition | undefined;
+
+  constructor(instanceSettings: DataSourceInstanceSettings<SQLiteOptions>) {
+    super(instanceSettings);
+  }
+
+  getQueryModel() {
+    return { quoteLiteral };
+  }
+
+  getSqlLanguageDefinition(): LanguageDefinition {
+    if (this.sqlLanguageDefinition !== undefined) {
+      return this.sqlLanguageDefinition;
+    }
+
+    this.sqlLanguageDefinition = {
+      id: 'sql',
+      formatter: formatSQL,
+    };
+
+    return this.sqlLanguageDefinition;
+  }
+
+  async fetchDatasets(): Promise<string[]> {
+    return [MAIN_SCHEMA];
+  }
+
+  async fetchTables(): Promise<string[]> {
+    const tables = await this.runSql<string[]>(
+      `SELECT name FROM sqlite_master WHERE type IN ('table', 'view') AND name NOT LIKE 'sqlite_%' ORDER BY name`,
+      { refId: 'tables' }
+    );
+    return tables.map((t) => quoteIdentifierIfNecessary(t[0]));
+  }
+
+  async fetchFields(query: Partial<SQLQuery>) {
+    if (!query.table) {
+      return [];
+    }
+    // the table info function takes the unquoted name of the table
+    const table = query.table.replace(/^"(.*)"$/, '$1').replace(/""/g, '"');
+    const frame = await this.runSql<string[]>(`SELECT name, type FROM pragma_table_info(${quoteLiteral(table)})`, {
+      refId: 'fields',
+    });
+    return frame.map((f) => ({
+      name: f[0],
+      text: f[0],
+      value: quoteIdentifierIfNecessary(f[0]),
+      type: f[1],
+      label: f[0],
+    }));
+  }
+
+  getDB(): DB {
+    if (this.db !== undefined) {
+      return this.db;
+    }
+
+    return {
+      datasets: () => this.fetchDatasets(),
+      tables: () => this.fetchTables(),
+      fields: (query: SQLQuery) => this.fetchFields(query),
+      validateQuery: (query: SQLQuery, _range?: TimeRange) =>
+        Promise.resolve({ query, error: '', isError: false, isValid: true }),
+      dsID: () => this.id,
+      toRawSql,
+      functions: () => ['AVG', 'COUNT', 'MAX', 'MIN', 'SUM', 'TOTAL'],
+      getEditorLanguageDefinition: () => this.getSqlLanguageDefinition(),
+    };
+  }
+}
@@ -0,0 +1,7 @@
+import { SQLOptions, SQLQuery } from '@grafana/sql';
+
+export interface SQLiteOptions extends SQLOptions {
+  path?: string;
+}
+
+export interface SQLiteQuery extends SQLQuery {}
@@ -46,6 +46,8 @@ composableKinds: DataQuery: {
 					limit?: int64
 					// Defines the maximum number of spans per spanset that are returned from Tempo
 					spss?: int64
+					// Step of the TraceQL metrics queries. Use duration format, for example: 30s, 1m
+					step?: string
 					filters: [...#TraceqlFilter]
 					// Filters that are used to query the metrics summary
 					groupBy?: [...#TraceqlFilter]
@@ -53,7 +55,7 @@ composableKinds: DataQuery: {
 					tableType?: #SearchTableType
 				} @cuetsy(kind="interface") @grafana(TSVeneer="type")
 
-				#TempoQueryType: "traceql" | "traceqlSearch" | "serviceMap" | "upload" | "nativeSearch" | "traceId" | "clear" @cuetsy(kind="type")
+				#TempoQueryType: "traceql" | "traceqlSearch" | "traceqlMetrics" | "serviceMap" | "traceServiceGraph" | "upload" | "nativeSearch" | "traceId" | "clear" @cuetsy(kind="type")
 
 				// The state of the TraceQL streaming search query
 				#SearchStreamingState: "pending" | "streaming" | "done" | "error" @cuetsy(kind="enum")